@@ -2,6 +2,9 @@ package aws
 
 import (
 	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsretry "github.com/aws/aws-sdk-go-v2/aws/retry"
 )
 
 // IpForEc2InstanceNotFound is an error that occurs when the IP for an EC2 instance is not found.
@@ -129,3 +132,10 @@ func (err NoRdsInstanceTypeError) Error() string {
 		err.DatabaseEngineVersion,
 	)
 }
+
+// IsThrottlingError returns true if the given error is an AWS API throttling error, such as Throttling or
+// RequestLimitExceeded, based on its error code rather than its message. It can be passed to
+// retry.DoWithRetryableErrorFuncs to retry the calls rate-limited by AWS.
+func IsThrottlingError(output string, err error) bool {
+	return awsretry.IsErrorThrottles(awsretry.DefaultThrottles).IsErrorThrottle(err) == aws.TrueTernary
+}
@@ -0,0 +1,29 @@
+package aws
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testApiError struct {
+	code string
+}
+
+func (err testApiError) Error() string {
+	return "api error " + err.code
+}
+
+func (err testApiError) ErrorCode() string {
+	return err.code
+}
+
+func TestIsThrottlingError(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsThrottlingError("", fmt.Errorf("operation error: %w", testApiError{code: "Throttling"})))
+	assert.True(t, IsThrottlingError("", testApiError{code: "RequestLimitExceeded"}))
+	assert.False(t, IsThrottlingError("", testApiError{code: "AccessDenied"}))
+	assert.False(t, IsThrottlingError("", fmt.Errorf("Throttling")))
+}
@@ -36,6 +36,27 @@ func CreateAndImportEC2KeyPairE(t testing.TestingT, region string, name string)
 	return ImportEC2KeyPairE(t, region, name, keyPair)
 }
 
+// CreateAndImportEC2ED25519KeyPair generates an ED25519 public/private KeyPair and import it into EC2 in the given
+// region under the given name. Use this for AMIs which refuse RSA keys.
+func CreateAndImportEC2ED25519KeyPair(t testing.TestingT, region string, name string) *Ec2Keypair {
+	keyPair, err := CreateAndImportEC2ED25519KeyPairE(t, region, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyPair
+}
+
+// CreateAndImportEC2ED25519KeyPairE generates an ED25519 public/private KeyPair and import it into EC2 in the given
+// region under the given name. Use this for AMIs which refuse RSA keys. Note that EC2 does not support ECDSA keys.
+func CreateAndImportEC2ED25519KeyPairE(t testing.TestingT, region string, name string) (*Ec2Keypair, error) {
+	keyPair, err := ssh.GenerateED25519KeyPairE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return ImportEC2KeyPairE(t, region, name, keyPair)
+}
+
 // ImportEC2KeyPair creates a Key Pair in EC2 by importing an existing public key.
 func ImportEC2KeyPair(t testing.TestingT, region string, name string, keyPair *ssh.KeyPair) *Ec2Keypair {
 	ec2KeyPair, err := ImportEC2KeyPairE(t, region, name, keyPair)
@@ -8,21 +8,24 @@ import (
 )
 
 // GetManagedClustersClientE is a helper function that will setup an Azure ManagedClusters client on your behalf
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
 func GetManagedClustersClientE(subscriptionID string) (*containerservice.ManagedClustersClient, error) {
-	// Create a cluster client
-	client, err := CreateManagedClustersClientE(subscriptionID)
-	if err != nil {
-		return nil, err
-	}
+	return getCachedClientE("ManagedClustersClient", subscriptionID, func(subscriptionID string) (*containerservice.ManagedClustersClient, error) {
+		// Create a cluster client
+		client, err := CreateManagedClustersClientE(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
 
-	// setup authorizer
-	authorizer, err := NewAuthorizer()
-	if err != nil {
-		return nil, err
-	}
+		// setup authorizer
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
 
-	client.Authorizer = *authorizer
-	return &client, nil
+		client.Authorizer = *authorizer
+		return &client, nil
+	})
 }
 
 // GetManagedClusterE will return ManagedCluster
@@ -0,0 +1,426 @@
+package azure
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/apimanagement/mgmt/2019-12-01/apimanagement"
+	http_helper "github.com/gruntwork-io/terratest/modules/http-helper"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// DefaultApiManagementSubscriptionKeyHeader is the header API Management reads the subscription key from, unless the
+// API overrides it.
+const DefaultApiManagementSubscriptionKeyHeader = "Ocp-Apim-Subscription-Key"
+
+// ApiManagementServiceExists indicates whether the specified API Management service exists.
+// This function would fail the test if there is an error.
+func ApiManagementServiceExists(t testing.TestingT, serviceName string, resourceGroupName string, subscriptionID string) bool {
+	exists, err := ApiManagementServiceExistsE(serviceName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return exists
+}
+
+// ApiManagementServiceExistsE indicates whether the specified API Management service exists.
+func ApiManagementServiceExistsE(serviceName string, resourceGroupName string, subscriptionID string) (bool, error) {
+	_, err := GetApiManagementServiceE(serviceName, resourceGroupName, subscriptionID)
+	if err != nil {
+		if ResourceNotFoundErrorExists(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// GetApiManagementService gets the specified API Management service.
+// This function would fail the test if there is an error.
+func GetApiManagementService(t testing.TestingT, serviceName string, resourceGroupName string, subscriptionID string) *apimanagement.ServiceResource {
+	service, err := GetApiManagementServiceE(serviceName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return service
+}
+
+// GetApiManagementServiceE gets the specified API Management service.
+func GetApiManagementServiceE(serviceName string, resourceGroupName string, subscriptionID string) (*apimanagement.ServiceResource, error) {
+	resourceGroupName, err := getTargetAzureResourceGroupName(resourceGroupName)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := CreateApiManagementServiceClientE(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := client.Get(context.Background(), resourceGroupName, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	return &service, nil
+}
+
+// ApiManagementApiExists indicates whether the specified API exists in the API Management service.
+// This function would fail the test if there is an error.
+func ApiManagementApiExists(t testing.TestingT, apiID string, serviceName string, resourceGroupName string, subscriptionID string) bool {
+	exists, err := ApiManagementApiExistsE(apiID, serviceName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return exists
+}
+
+// ApiManagementApiExistsE indicates whether the specified API exists in the API Management service.
+func ApiManagementApiExistsE(apiID string, serviceName string, resourceGroupName string, subscriptionID string) (bool, error) {
+	_, err := GetApiManagementApiE(apiID, serviceName, resourceGroupName, subscriptionID)
+	if err != nil {
+		if ResourceNotFoundErrorExists(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// GetApiManagementApi gets the specified API of the API Management service.
+// This function would fail the test if there is an error.
+func GetApiManagementApi(t testing.TestingT, apiID string, serviceName string, resourceGroupName string, subscriptionID string) *apimanagement.APIContract {
+	api, err := GetApiManagementApiE(apiID, serviceName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return api
+}
+
+// GetApiManagementApiE gets the specified API of the API Management service.
+func GetApiManagementApiE(apiID string, serviceName string, resourceGroupName string, subscriptionID string) (*apimanagement.APIContract, error) {
+	resourceGroupName, err := getTargetAzureResourceGroupName(resourceGroupName)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := CreateApiManagementApiClientE(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	api, err := client.Get(context.Background(), resourceGroupName, serviceName, apiID)
+	if err != nil {
+		return nil, err
+	}
+	return &api, nil
+}
+
+// ApiManagementProductExists indicates whether the specified product exists in the API Management service.
+// This function would fail the test if there is an error.
+func ApiManagementProductExists(t testing.TestingT, productID string, serviceName string, resourceGroupName string, subscriptionID string) bool {
+	exists, err := ApiManagementProductExistsE(productID, serviceName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return exists
+}
+
+// ApiManagementProductExistsE indicates whether the specified product exists in the API Management service.
+func ApiManagementProductExistsE(productID string, serviceName string, resourceGroupName string, subscriptionID string) (bool, error) {
+	_, err := GetApiManagementProductE(productID, serviceName, resourceGroupName, subscriptionID)
+	if err != nil {
+		if ResourceNotFoundErrorExists(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// GetApiManagementProduct gets the specified product of the API Management service.
+// This function would fail the test if there is an error.
+func GetApiManagementProduct(t testing.TestingT, productID string, serviceName string, resourceGroupName string, subscriptionID string) *apimanagement.ProductContract {
+	product, err := GetApiManagementProductE(productID, serviceName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return product
+}
+
+// GetApiManagementProductE gets the specified product of the API Management service.
+func GetApiManagementProductE(productID string, serviceName string, resourceGroupName string, subscriptionID string) (*apimanagement.ProductContract, error) {
+	resourceGroupName, err := getTargetAzureResourceGroupName(resourceGroupName)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := CreateApiManagementProductClientE(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := client.Get(context.Background(), resourceGroupName, serviceName, productID)
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// ApiManagementSubscriptionExists indicates whether the specified subscription exists in the API Management service.
+// Note that apimSubscriptionID identifies the API Management subscription, not the Azure subscription.
+// This function would fail the test if there is an error.
+func ApiManagementSubscriptionExists(t testing.TestingT, apimSubscriptionID string, serviceName string, resourceGroupName string, subscriptionID string) bool {
+	exists, err := ApiManagementSubscriptionExistsE(apimSubscriptionID, serviceName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return exists
+}
+
+// ApiManagementSubscriptionExistsE indicates whether the specified subscription exists in the API Management service.
+// Note that apimSubscriptionID identifies the API Management subscription, not the Azure subscription.
+func ApiManagementSubscriptionExistsE(apimSubscriptionID string, serviceName string, resourceGroupName string, subscriptionID string) (bool, error) {
+	_, err := GetApiManagementSubscriptionE(apimSubscriptionID, serviceName, resourceGroupName, subscriptionID)
+	if err != nil {
+		if ResourceNotFoundErrorExists(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// GetApiManagementSubscription gets the specified subscription of the API Management service.
+// This function would fail the test if there is an error.
+func GetApiManagementSubscription(t testing.TestingT, apimSubscriptionID string, serviceName string, resourceGroupName string, subscriptionID string) *apimanagement.SubscriptionContract {
+	subscription, err := GetApiManagementSubscriptionE(apimSubscriptionID, serviceName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return subscription
+}
+
+// GetApiManagementSubscriptionE gets the specified subscription of the API Management service.
+func GetApiManagementSubscriptionE(apimSubscriptionID string, serviceName string, resourceGroupName string, subscriptionID string) (*apimanagement.SubscriptionContract, error) {
+	resourceGroupName, err := getTargetAzureResourceGroupName(resourceGroupName)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := CreateApiManagementSubscriptionClientE(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription, err := client.Get(context.Background(), resourceGroupName, serviceName, apimSubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// GetApiManagementSubscriptionPrimaryKey gets the primary key of the specified API Management subscription.
+// This function would fail the test if there is an error.
+func GetApiManagementSubscriptionPrimaryKey(t testing.TestingT, apimSubscriptionID string, serviceName string, resourceGroupName string, subscriptionID string) string {
+	key, err := GetApiManagementSubscriptionPrimaryKeyE(apimSubscriptionID, serviceName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return key
+}
+
+// GetApiManagementSubscriptionPrimaryKeyE gets the primary key of the specified API Management subscription.
+func GetApiManagementSubscriptionPrimaryKeyE(apimSubscriptionID string, serviceName string, resourceGroupName string, subscriptionID string) (string, error) {
+	resourceGroupName, err := getTargetAzureResourceGroupName(resourceGroupName)
+	if err != nil {
+		return "", err
+	}
+
+	client, err := CreateApiManagementSubscriptionClientE(subscriptionID)
+	if err != nil {
+		return "", err
+	}
+
+	keys, err := client.ListSecrets(context.Background(), resourceGroupName, serviceName, apimSubscriptionID)
+	if err != nil {
+		return "", err
+	}
+	if keys.PrimaryKey == nil {
+		return "", NewNotFoundError("API Management subscription key", apimSubscriptionID, serviceName)
+	}
+	return *keys.PrimaryKey, nil
+}
+
+// GetApiManagementServicePolicyXml gets the global (all APIs) policy of the API Management service as raw XML.
+// This function would fail the test if there is an error.
+func GetApiManagementServicePolicyXml(t testing.TestingT, serviceName string, resourceGroupName string, subscriptionID string) string {
+	policy, err := GetApiManagementServicePolicyXmlE(serviceName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return policy
+}
+
+// GetApiManagementServicePolicyXmlE gets the global (all APIs) policy of the API Management service as raw XML.
+func GetApiManagementServicePolicyXmlE(serviceName string, resourceGroupName string, subscriptionID string) (string, error) {
+	resourceGroupName, err := getTargetAzureResourceGroupName(resourceGroupName)
+	if err != nil {
+		return "", err
+	}
+
+	client, err := CreateApiManagementPolicyClientE(subscriptionID)
+	if err != nil {
+		return "", err
+	}
+
+	policy, err := client.Get(context.Background(), resourceGroupName, serviceName, apimanagement.PolicyExportFormatRawxml)
+	if err != nil {
+		return "", err
+	}
+	return getPolicyXml(policy), nil
+}
+
+// GetApiManagementApiPolicyXml gets the policy of the specified API as raw XML.
+// This function would fail the test if there is an error.
+func GetApiManagementApiPolicyXml(t testing.TestingT, apiID string, serviceName string, resourceGroupName string, subscriptionID string) string {
+	policy, err := GetApiManagementApiPolicyXmlE(apiID, serviceName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return policy
+}
+
+// GetApiManagementApiPolicyXmlE gets the policy of the specified API as raw XML.
+func GetApiManagementApiPolicyXmlE(apiID string, serviceName string, resourceGroupName string, subscriptionID string) (string, error) {
+	resourceGroupName, err := getTargetAzureResourceGroupName(resourceGroupName)
+	if err != nil {
+		return "", err
+	}
+
+	client, err := CreateApiManagementApiPolicyClientE(subscriptionID)
+	if err != nil {
+		return "", err
+	}
+
+	policy, err := client.Get(context.Background(), resourceGroupName, serviceName, apiID, apimanagement.PolicyExportFormatRawxml)
+	if err != nil {
+		return "", err
+	}
+	return getPolicyXml(policy), nil
+}
+
+// GetApiManagementProductPolicyXml gets the policy of the specified product as raw XML.
+// This function would fail the test if there is an error.
+func GetApiManagementProductPolicyXml(t testing.TestingT, productID string, serviceName string, resourceGroupName string, subscriptionID string) string {
+	policy, err := GetApiManagementProductPolicyXmlE(productID, serviceName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return policy
+}
+
+// GetApiManagementProductPolicyXmlE gets the policy of the specified product as raw XML.
+func GetApiManagementProductPolicyXmlE(productID string, serviceName string, resourceGroupName string, subscriptionID string) (string, error) {
+	resourceGroupName, err := getTargetAzureResourceGroupName(resourceGroupName)
+	if err != nil {
+		return "", err
+	}
+
+	client, err := CreateApiManagementProductPolicyClientE(subscriptionID)
+	if err != nil {
+		return "", err
+	}
+
+	policy, err := client.Get(context.Background(), resourceGroupName, serviceName, productID, apimanagement.PolicyExportFormatRawxml)
+	if err != nil {
+		return "", err
+	}
+	return getPolicyXml(policy), nil
+}
+
+// ApiManagementPolicyHasElement indicates whether the given policy XML contains an element with the given name, e.g.
+// "rate-limit", "validate-jwt" or "set-header".
+// This function would fail the test if the policy XML cannot be parsed.
+func ApiManagementPolicyHasElement(t testing.TestingT, policyXml string, elementName string) bool {
+	found, err := ApiManagementPolicyHasElementE(policyXml, elementName)
+	require.NoError(t, err)
+	return found
+}
+
+// ApiManagementPolicyHasElementE indicates whether the given policy XML contains an element with the given name, e.g.
+// "rate-limit", "validate-jwt" or "set-header".
+func ApiManagementPolicyHasElementE(policyXml string, elementName string) (bool, error) {
+	decoder := xml.NewDecoder(strings.NewReader(policyXml))
+	// Policy expressions such as @(context.Request...) are not valid XML entities, so don't be strict about them
+	decoder.Strict = false
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, NewFailedToParseError("API Management policy", err.Error())
+		}
+
+		if start, ok := token.(xml.StartElement); ok && start.Name.Local == elementName {
+			return true, nil
+		}
+	}
+}
+
+// InvokeApiManagementApi calls the given path of an API through the gateway of the API Management service, passing the
+// primary key of the given API Management subscription in the subscription key header expected by the API. It
+// returns the HTTP status code and body.
+// This function would fail the test if there is an error.
+func InvokeApiManagementApi(t testing.TestingT, method string, apiID string, path string, apimSubscriptionID string, serviceName string, resourceGroupName string, subscriptionID string) (int, string) {
+	statusCode, body, err := InvokeApiManagementApiE(t, method, apiID, path, apimSubscriptionID, serviceName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return statusCode, body
+}
+
+// InvokeApiManagementApiE calls the given path of an API through the gateway of the API Management service, passing
+// the primary key of the given API Management subscription in the subscription key header expected by the API. It
+// returns the HTTP status code and body. Pass an empty apimSubscriptionID to call an API that doesn't require a
+// subscription.
+func InvokeApiManagementApiE(t testing.TestingT, method string, apiID string, path string, apimSubscriptionID string, serviceName string, resourceGroupName string, subscriptionID string) (int, string, error) {
+	service, err := GetApiManagementServiceE(serviceName, resourceGroupName, subscriptionID)
+	if err != nil {
+		return -1, "", err
+	}
+	if service.ServiceProperties == nil || service.ServiceProperties.GatewayURL == nil {
+		return -1, "", NewNotFoundError("API Management gateway URL", serviceName, resourceGroupName)
+	}
+
+	api, err := GetApiManagementApiE(apiID, serviceName, resourceGroupName, subscriptionID)
+	if err != nil {
+		return -1, "", err
+	}
+
+	headers := map[string]string{}
+	if apimSubscriptionID != "" {
+		key, err := GetApiManagementSubscriptionPrimaryKeyE(apimSubscriptionID, serviceName, resourceGroupName, subscriptionID)
+		if err != nil {
+			return -1, "", err
+		}
+		headers[getApiManagementSubscriptionKeyHeader(api)] = key
+	}
+
+	url := getApiManagementGatewayURL(*service.ServiceProperties.GatewayURL, api, path)
+	return http_helper.HTTPDoE(t, method, url, nil, headers, nil)
+}
+
+// getPolicyXml returns the policy content of the given policy contract, or "" if there is none.
+func getPolicyXml(policy apimanagement.PolicyContract) string {
+	if policy.PolicyContractProperties == nil {
+		return ""
+	}
+	return safePtrToString(policy.PolicyContractProperties.Value)
+}
+
+// getApiManagementSubscriptionKeyHeader returns the header name the given API expects the subscription key in.
+func getApiManagementSubscriptionKeyHeader(api *apimanagement.APIContract) string {
+	if api != nil && api.APIContractProperties != nil && api.SubscriptionKeyParameterNames != nil {
+		if header := safePtrToString(api.SubscriptionKeyParameterNames.Header); header != "" {
+			return header
+		}
+	}
+	return DefaultApiManagementSubscriptionKeyHeader
+}
+
+// getApiManagementGatewayURL builds the gateway URL for the given path of the given API.
+func getApiManagementGatewayURL(gatewayURL string, api *apimanagement.APIContract, path string) string {
+	apiPath := ""
+	if api != nil && api.APIContractProperties != nil {
+		apiPath = strings.Trim(safePtrToString(api.Path), "/")
+	}
+
+	url := strings.TrimSuffix(gatewayURL, "/")
+	if apiPath != "" {
+		url = fmt.Sprintf("%s/%s", url, apiPath)
+	}
+	if path = strings.TrimPrefix(path, "/"); path != "" {
+		url = fmt.Sprintf("%s/%s", url, path)
+	}
+	return url
+}
@@ -0,0 +1,99 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/apimanagement/mgmt/2019-12-01/apimanagement"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/*
+The below tests are currently stubbed out, with the expectation that they will throw errors.
+If/when methods to create and delete API Management resources are added, these tests can be extended.
+*/
+
+func TestApiManagementServiceExistsE(t *testing.T) {
+	t.Parallel()
+
+	_, err := ApiManagementServiceExistsE("", "", "")
+	require.Error(t, err)
+}
+
+func TestGetApiManagementApiE(t *testing.T) {
+	t.Parallel()
+
+	_, err := GetApiManagementApiE("", "", "", "")
+	require.Error(t, err)
+}
+
+func TestGetApiManagementProductE(t *testing.T) {
+	t.Parallel()
+
+	_, err := GetApiManagementProductE("", "", "", "")
+	require.Error(t, err)
+}
+
+func TestGetApiManagementSubscriptionPrimaryKeyE(t *testing.T) {
+	t.Parallel()
+
+	_, err := GetApiManagementSubscriptionPrimaryKeyE("", "", "", "")
+	require.Error(t, err)
+}
+
+func TestGetApiManagementApiPolicyXmlE(t *testing.T) {
+	t.Parallel()
+
+	_, err := GetApiManagementApiPolicyXmlE("", "", "", "")
+	require.Error(t, err)
+}
+
+func TestApiManagementPolicyHasElementE(t *testing.T) {
+	t.Parallel()
+
+	policy := `<policies>
+	<inbound>
+		<base />
+		<rate-limit calls="20" renewal-period="90" />
+		<set-header name="x-request-id" exists-action="override">
+			<value>@(context.RequestId.ToString())</value>
+		</set-header>
+	</inbound>
+	<outbound><base /></outbound>
+</policies>`
+
+	found, err := ApiManagementPolicyHasElementE(policy, "rate-limit")
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	found, err = ApiManagementPolicyHasElementE(policy, "validate-jwt")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestGetApiManagementGatewayURL(t *testing.T) {
+	t.Parallel()
+
+	apiPath := "/orders/"
+	api := &apimanagement.APIContract{
+		APIContractProperties: &apimanagement.APIContractProperties{Path: &apiPath},
+	}
+
+	assert.Equal(t, "https://example.azure-api.net/orders/v1/status", getApiManagementGatewayURL("https://example.azure-api.net/", api, "/v1/status"))
+	assert.Equal(t, "https://example.azure-api.net/orders", getApiManagementGatewayURL("https://example.azure-api.net", api, ""))
+	assert.Equal(t, "https://example.azure-api.net/health", getApiManagementGatewayURL("https://example.azure-api.net", nil, "health"))
+}
+
+func TestGetApiManagementSubscriptionKeyHeader(t *testing.T) {
+	t.Parallel()
+
+	customHeader := "X-Api-Key"
+	api := &apimanagement.APIContract{
+		APIContractProperties: &apimanagement.APIContractProperties{
+			SubscriptionKeyParameterNames: &apimanagement.SubscriptionKeyParameterNamesContract{Header: &customHeader},
+		},
+	}
+
+	assert.Equal(t, "X-Api-Key", getApiManagementSubscriptionKeyHeader(api))
+	assert.Equal(t, DefaultApiManagementSubscriptionKeyHeader, getApiManagementSubscriptionKeyHeader(&apimanagement.APIContract{}))
+}
@@ -58,20 +58,23 @@ func GetAppServiceE(appName string, resGroupName string, subscriptionID string)
 	return &resource, nil
 }
 
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
 func GetAppServiceClientE(subscriptionID string) (*web.AppsClient, error) {
-	// Create an Apps client
-	appsClient, err := CreateAppServiceClientE(subscriptionID)
-	if err != nil {
-		return nil, err
-	}
+	return getCachedClientE("AppServiceClient", subscriptionID, func(subscriptionID string) (*web.AppsClient, error) {
+		// Create an Apps client
+		appsClient, err := CreateAppServiceClientE(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
 
-	// Create an authorizer
-	authorizer, err := NewAuthorizer()
-	if err != nil {
-		return nil, err
-	}
+		// Create an authorizer
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
 
-	// Attach authorizer to the client
-	appsClient.Authorizer = *authorizer
-	return appsClient, nil
+		// Attach authorizer to the client
+		appsClient.Authorizer = *authorizer
+		return appsClient, nil
+	})
 }
@@ -21,7 +21,16 @@ const (
 
 // NewAuthorizer creates an Azure authorizer adhering to standard auth mechanisms provided by the Azure Go SDK
 // See Azure Go Auth docs here: https://docs.microsoft.com/en-us/go/azure/azure-sdk-go-authorization
+// The authorizer is cached and reused across calls, unless the client cache is disabled (see SetClientCacheEnabled). It
+// is cached per credentials: the client ID, tenant, secret or certificate, auth file, and Azure CLI config dir. Call
+// ClearClientCache after switching accounts with 'az login'. Authorizers created from the Azure CLI, whose token is not
+// refreshed, are replaced before their token expires.
 func NewAuthorizer() (*autorest.Authorizer, error) {
+	return getCachedE(newClientCacheKey("Authorizer", ""), newAuthorizer)
+}
+
+// newAuthorizer creates a new Azure authorizer, bypassing the cache.
+func newAuthorizer() (*autorest.Authorizer, error) {
 	// Carry out env var lookups
 	_, clientIDExists := os.LookupEnv(AuthFromEnvClient)
 	_, tenantIDExists := os.LookupEnv(AuthFromEnvTenant)
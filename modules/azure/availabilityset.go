@@ -136,22 +136,25 @@ func GetAvailabilitySetE(t testing.TestingT, avsName string, resGroupName string
 
 // GetAvailabilitySetClientE gets a new Availability Set client in the specified Azure Subscription
 // TODO: remove in next version
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
 func GetAvailabilitySetClientE(subscriptionID string) (*compute.AvailabilitySetsClient, error) {
-	// Validate Azure subscription ID
-	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
-	if err != nil {
-		return nil, err
-	}
+	return getCachedClientE("AvailabilitySetClient", subscriptionID, func(subscriptionID string) (*compute.AvailabilitySetsClient, error) {
+		// Validate Azure subscription ID
+		subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
 
-	// Get the Availability Set client
-	client := compute.NewAvailabilitySetsClient(subscriptionID)
+		// Get the Availability Set client
+		client := compute.NewAvailabilitySetsClient(subscriptionID)
 
-	// Create an authorizer
-	authorizer, err := NewAuthorizer()
-	if err != nil {
-		return nil, err
-	}
-	client.Authorizer = *authorizer
+		// Create an authorizer
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
+		client.Authorizer = *authorizer
 
-	return &client, nil
+		return &client, nil
+	})
 }
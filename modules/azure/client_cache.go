@@ -0,0 +1,221 @@
+package azure
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/adal"
+	"github.com/Azure/go-autorest/autorest/azure/auth"
+)
+
+const (
+	// AzureDisableClientCacheEnvName is the name of the env var that disables caching of Azure clients and authorizers
+	// when set to any non-empty value.
+	AzureDisableClientCacheEnvName = "TERRATEST_AZURE_DISABLE_CLIENT_CACHE"
+
+	// ClientCacheTTL is how long a cached client or authorizer is reused at most. Authorizers created from the Azure
+	// CLI hold a token that is not refreshed, so they and the clients using them are only reused until
+	// ClientCacheTokenExpiryMargin before their token expires.
+	ClientCacheTTL = 30 * time.Minute
+
+	// ClientCacheTokenExpiryMargin is how long before their token expires cached authorizers created from the Azure CLI,
+	// and the clients using them, are replaced, so that calls in progress don't get an expired token.
+	ClientCacheTokenExpiryMargin = 5 * time.Minute
+)
+
+// clientCacheKey identifies a cached client: the same client type can be cached for several subscriptions, Azure
+// environments and credentials.
+type clientCacheKey struct {
+	clientType     string
+	subscriptionID string
+	environment    string
+	credentials    string // a hash of the credentials the authorizer of the client is created from
+}
+
+// credentialEnvVars are the env vars the Azure SDK creates authorizers from. The Azure CLI keeps its credentials in
+// its config dir.
+var credentialEnvVars = []string{
+	auth.TenantID,
+	auth.AuxiliaryTenantIDs,
+	auth.ClientID,
+	auth.ClientSecret,
+	auth.CertificatePath,
+	auth.CertificatePassword,
+	auth.Username,
+	auth.Password,
+	auth.Resource,
+	AuthFromFile,
+	"AZURE_CONFIG_DIR",
+}
+
+type clientCacheEntry struct {
+	client    interface{}
+	createdAt time.Time
+	expiresAt time.Time // when the token of the client expires, if it's not refreshed
+}
+
+// isFresh indicates whether the cached client can still be reused.
+func (entry clientCacheEntry) isFresh() bool {
+	if time.Since(entry.createdAt) >= ClientCacheTTL {
+		return false
+	}
+	return entry.expiresAt.IsZero() || time.Until(entry.expiresAt) > ClientCacheTokenExpiryMargin
+}
+
+// clientCache is a thread-safe cache of Azure clients shared by all the helpers of this package, so that large test
+// suites don't acquire a new token for every call.
+type clientCache struct {
+	mutex    sync.Mutex
+	disabled bool
+	entries  map[clientCacheKey]clientCacheEntry
+}
+
+var defaultClientCache = &clientCache{
+	entries: map[clientCacheKey]clientCacheEntry{},
+}
+
+// SetClientCacheEnabled enables or disables the reuse of Azure clients and authorizers across helper calls. The cache is
+// enabled by default, unless the TERRATEST_AZURE_DISABLE_CLIENT_CACHE env var is set. Disabling the cache also clears it.
+func SetClientCacheEnabled(enabled bool) {
+	defaultClientCache.mutex.Lock()
+	defer defaultClientCache.mutex.Unlock()
+
+	defaultClientCache.disabled = !enabled
+	if !enabled {
+		defaultClientCache.entries = map[clientCacheKey]clientCacheEntry{}
+	}
+}
+
+// ClearClientCache removes all the cached Azure clients and authorizers, e.g. after switching credentials.
+func ClearClientCache() {
+	defaultClientCache.mutex.Lock()
+	defer defaultClientCache.mutex.Unlock()
+
+	defaultClientCache.entries = map[clientCacheKey]clientCacheEntry{}
+}
+
+// isEnabled indicates whether the cache should be used. The caller must hold the mutex.
+func (cache *clientCache) isEnabled() bool {
+	return !cache.disabled && os.Getenv(AzureDisableClientCacheEnvName) == ""
+}
+
+// getOrCreate returns the cached client for the given key if there is a fresh one, or creates, caches and returns a new
+// one otherwise. The mutex is not held during creation, as creating a client may itself use the cache (e.g. for the
+// authorizer).
+func (cache *clientCache) getOrCreate(key clientCacheKey, create func() (interface{}, error)) (interface{}, error) {
+	cache.mutex.Lock()
+	enabled := cache.isEnabled()
+	entry, exists := cache.entries[key]
+	cache.mutex.Unlock()
+
+	if !enabled {
+		return create()
+	}
+	if exists && entry.isFresh() {
+		return entry.client, nil
+	}
+
+	client, err := create()
+	if err != nil {
+		return nil, err
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.entries[key] = clientCacheEntry{client: client, createdAt: time.Now(), expiresAt: getStaticTokenExpiry(client)}
+	return client, nil
+}
+
+// getStaticTokenExpiry returns when the token of the given authorizer, or of the Authorizer of the given client,
+// expires if it's a token that is not refreshed, such as the tokens of the Azure CLI. It returns the zero time
+// otherwise.
+func getStaticTokenExpiry(client interface{}) time.Time {
+	authorizer, ok := client.(*autorest.Authorizer)
+	if ok && authorizer != nil {
+		return getAuthorizerStaticTokenExpiry(*authorizer)
+	}
+
+	value := reflect.ValueOf(client)
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return time.Time{}
+	}
+	field := value.FieldByName("Authorizer")
+	if !field.IsValid() || !field.CanInterface() {
+		return time.Time{}
+	}
+	if authorizer, ok := field.Interface().(autorest.Authorizer); ok {
+		return getAuthorizerStaticTokenExpiry(authorizer)
+	}
+	return time.Time{}
+}
+
+func getAuthorizerStaticTokenExpiry(authorizer autorest.Authorizer) time.Time {
+	bearerAuthorizer, ok := authorizer.(*autorest.BearerAuthorizer)
+	if !ok {
+		return time.Time{}
+	}
+	// Service principal tokens refresh themselves, while the tokens of the Azure CLI are plain tokens
+	if token, ok := bearerAuthorizer.TokenProvider().(*adal.Token); ok {
+		return token.Expires()
+	}
+	return time.Time{}
+}
+
+// newClientCacheKey returns the key of the client of the given type for the given subscription, for the current Azure
+// environment and credentials.
+func newClientCacheKey(clientType string, subscriptionID string) clientCacheKey {
+	return clientCacheKey{
+		clientType:     clientType,
+		subscriptionID: subscriptionID,
+		environment:    getDefaultEnvironmentName(),
+		credentials:    getCredentialsHash(),
+	}
+}
+
+// getCredentialsHash returns a hash of the credential env vars, so that changing credentials yields new clients
+// without keeping the secrets in the cache keys.
+func getCredentialsHash() string {
+	values := make([]string, 0, len(credentialEnvVars))
+	for _, envVar := range credentialEnvVars {
+		values = append(values, envVar+"="+os.Getenv(envVar))
+	}
+	hash := sha256.Sum256([]byte(strings.Join(values, "\n")))
+	return hex.EncodeToString(hash[:])
+}
+
+// getCachedClientE returns a client of the given type for the given subscription from the default cache, using create
+// to build it when there is no fresh cached client.
+func getCachedClientE[T any](clientType string, subscriptionID string, create func(subscriptionID string) (T, error)) (T, error) {
+	// Resolve the subscription so that "" and the ARM_SUBSCRIPTION_ID env var share the same entry
+	targetSubscriptionID, err := getTargetAzureSubscription(subscriptionID)
+	if err != nil {
+		var empty T
+		return empty, err
+	}
+
+	return getCachedE(newClientCacheKey(clientType, targetSubscriptionID), func() (T, error) {
+		return create(targetSubscriptionID)
+	})
+}
+
+// getCachedE returns the client or authorizer with the given key from the default cache, using create to build it when
+// there is no fresh cached one.
+func getCachedE[T any](key clientCacheKey, create func() (T, error)) (T, error) {
+	client, err := defaultClientCache.getOrCreate(key, func() (interface{}, error) {
+		return create()
+	})
+	if err != nil {
+		var empty T
+		return empty, err
+	}
+	return client.(T), nil
+}
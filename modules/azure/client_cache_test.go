@@ -0,0 +1,146 @@
+package azure
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/adal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClientCache() *clientCache {
+	return &clientCache{entries: map[clientCacheKey]clientCacheEntry{}}
+}
+
+func countingCreate(count *int) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		*count++
+		return *count, nil
+	}
+}
+
+func TestClientCacheReusesClients(t *testing.T) {
+	t.Parallel()
+
+	cache := newTestClientCache()
+	created := 0
+	key := clientCacheKey{clientType: "TestClient", subscriptionID: "00000000-0000-0000-0000-000000000000"}
+
+	first, err := cache.getOrCreate(key, countingCreate(&created))
+	require.NoError(t, err)
+	second, err := cache.getOrCreate(key, countingCreate(&created))
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, created)
+
+	// A different subscription gets its own client
+	otherKey := clientCacheKey{clientType: "TestClient", subscriptionID: "11111111-1111-1111-1111-111111111111"}
+	_, err = cache.getOrCreate(otherKey, countingCreate(&created))
+	require.NoError(t, err)
+	assert.Equal(t, 2, created)
+}
+
+func TestClientCacheExpiresAndSkipsErrors(t *testing.T) {
+	t.Parallel()
+
+	cache := newTestClientCache()
+	created := 0
+	key := clientCacheKey{clientType: "TestClient"}
+
+	_, err := cache.getOrCreate(key, func() (interface{}, error) { return nil, errors.New("failed") })
+	require.Error(t, err)
+	assert.Empty(t, cache.entries)
+
+	_, err = cache.getOrCreate(key, countingCreate(&created))
+	require.NoError(t, err)
+
+	cache.entries[key] = clientCacheEntry{client: 0, createdAt: time.Now().Add(-2 * ClientCacheTTL)}
+	client, err := cache.getOrCreate(key, countingCreate(&created))
+	require.NoError(t, err)
+	assert.Equal(t, 2, client)
+}
+
+func TestClientCacheDisabled(t *testing.T) {
+	cache := newTestClientCache()
+	created := 0
+	key := clientCacheKey{clientType: "TestClient"}
+
+	cache.disabled = true
+	_, _ = cache.getOrCreate(key, countingCreate(&created))
+	_, _ = cache.getOrCreate(key, countingCreate(&created))
+	assert.Equal(t, 2, created)
+
+	cache.disabled = false
+	t.Setenv(AzureDisableClientCacheEnvName, "true")
+	_, _ = cache.getOrCreate(key, countingCreate(&created))
+	_, _ = cache.getOrCreate(key, countingCreate(&created))
+	assert.Equal(t, 4, created)
+	assert.Empty(t, cache.entries)
+}
+
+func TestClientCacheKeyChangesWithCredentials(t *testing.T) {
+	t.Setenv(AuthFromEnvClient, "00000000-0000-0000-0000-000000000000")
+	t.Setenv(AuthFromEnvTenant, "11111111-1111-1111-1111-111111111111")
+	t.Setenv("AZURE_CLIENT_SECRET", "first-secret")
+	key := newClientCacheKey("TestClient", "22222222-2222-2222-2222-222222222222")
+	assert.Equal(t, key, newClientCacheKey("TestClient", "22222222-2222-2222-2222-222222222222"))
+	assert.NotContains(t, key.credentials, "first-secret")
+
+	t.Setenv("AZURE_CLIENT_SECRET", "second-secret")
+	assert.NotEqual(t, key, newClientCacheKey("TestClient", "22222222-2222-2222-2222-222222222222"))
+
+	t.Setenv("AZURE_CLIENT_SECRET", "first-secret")
+	t.Setenv(AuthFromEnvTenant, "33333333-3333-3333-3333-333333333333")
+	assert.NotEqual(t, key, newClientCacheKey("TestClient", "22222222-2222-2222-2222-222222222222"))
+}
+
+func TestClientCacheReplacesExpiringCliTokens(t *testing.T) {
+	t.Parallel()
+
+	newCliAuthorizer := func(expiresIn time.Duration) autorest.Authorizer {
+		expiresOn := json.Number(strconv.FormatInt(time.Now().Add(expiresIn).Unix(), 10))
+		return autorest.NewBearerAuthorizer(&adal.Token{AccessToken: "token", ExpiresOn: expiresOn})
+	}
+
+	cache := newTestClientCache()
+	created := 0
+	expiresIn := time.Hour
+	create := func() (interface{}, error) {
+		created++
+		authorizer := newCliAuthorizer(expiresIn)
+		return &authorizer, nil
+	}
+	authorizerKey := clientCacheKey{clientType: "Authorizer"}
+
+	_, err := cache.getOrCreate(authorizerKey, create)
+	require.NoError(t, err)
+	_, err = cache.getOrCreate(authorizerKey, create)
+	require.NoError(t, err)
+	assert.Equal(t, 1, created)
+
+	// An authorizer whose token expires soon is replaced, before the cache TTL
+	expiresIn = ClientCacheTokenExpiryMargin / 2
+	cache.entries = map[clientCacheKey]clientCacheEntry{}
+	_, err = cache.getOrCreate(authorizerKey, create)
+	require.NoError(t, err)
+	_, err = cache.getOrCreate(authorizerKey, create)
+	require.NoError(t, err)
+	assert.Equal(t, 3, created)
+
+	// So are the clients using it
+	clientKey := clientCacheKey{clientType: "TestClient"}
+	client := autorest.Client{Authorizer: newCliAuthorizer(ClientCacheTokenExpiryMargin / 2)}
+	_, err = cache.getOrCreate(clientKey, func() (interface{}, error) { return &client, nil })
+	require.NoError(t, err)
+	assert.False(t, cache.entries[clientKey].isFresh())
+	assert.False(t, getStaticTokenExpiry(&client).IsZero())
+
+	// Tokens that refresh themselves don't limit the lifetime of the clients
+	assert.True(t, getStaticTokenExpiry(&autorest.Client{Authorizer: autorest.NullAuthorizer{}}).IsZero())
+	assert.True(t, getStaticTokenExpiry(1).IsZero())
+}
@@ -30,10 +30,14 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appcontainers/armappcontainers/v3"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/services/apimanagement/mgmt/2019-12-01/apimanagement"
+	"github.com/Azure/azure-sdk-for-go/services/authorization/mgmt/2015-07-01/authorization"
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
+	computev2020 "github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2020-06-01/compute"
 	"github.com/Azure/azure-sdk-for-go/services/containerinstance/mgmt/2018-10-01/containerinstance"
 	"github.com/Azure/azure-sdk-for-go/services/containerregistry/mgmt/2019-05-01/containerregistry"
 	"github.com/Azure/azure-sdk-for-go/services/containerservice/mgmt/2019-11-01/containerservice"
+	"github.com/Azure/azure-sdk-for-go/services/databricks/mgmt/2018-04-01/databricks"
 	"github.com/Azure/azure-sdk-for-go/services/datafactory/mgmt/2018-06-01/datafactory"
 	kvmng "github.com/Azure/azure-sdk-for-go/services/keyvault/mgmt/2016-10-01/keyvault"
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-09-01/network"
@@ -278,6 +282,33 @@ func CreateAvailabilitySetClientE(subscriptionID string) (*compute.AvailabilityS
 	return &client, nil
 }
 
+// CreateSshPublicKeysClientE creates a new SSH Public Keys client in the specified Azure Subscription
+func CreateSshPublicKeysClientE(subscriptionID string) (*computev2020.SSHPublicKeysClient, error) {
+	// Validate Azure subscription ID
+	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup environment URI
+	baseURI, err := getBaseURI()
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the SSH Public Keys client
+	client := computev2020.NewSSHPublicKeysClientWithBaseURI(baseURI, subscriptionID)
+
+	// Create an authorizer
+	authorizer, err := NewAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+	client.Authorizer = *authorizer
+
+	return &client, nil
+}
+
 // CreateResourceGroupClientE gets a resource group client in a subscription
 func CreateResourceGroupClientE(subscriptionID string) (*resources.GroupsClient, error) {
 	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
@@ -698,6 +729,46 @@ func CreateLoadBalancerClientE(subscriptionID string) (*network.LoadBalancersCli
 	return &client, nil
 }
 
+// CreateNatGatewaysClientE returns a NAT Gateway client instance configured with the correct BaseURI depending on
+// the Azure environment that is currently setup (or "Public", if none is setup).
+func CreateNatGatewaysClientE(subscriptionID string) (*network.NatGatewaysClient, error) {
+	// Validate Azure subscription ID
+	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup environment URI
+	baseURI, err := getEnvironmentEndpointE(ResourceManagerEndpointName)
+	if err != nil {
+		return nil, err
+	}
+
+	// create NAT Gateway client
+	client := network.NewNatGatewaysClientWithBaseURI(baseURI, subscriptionID)
+	return &client, nil
+}
+
+// CreatePublicIPPrefixesClientE returns a Public IP Prefix client instance configured with the correct BaseURI
+// depending on the Azure environment that is currently setup (or "Public", if none is setup).
+func CreatePublicIPPrefixesClientE(subscriptionID string) (*network.PublicIPPrefixesClient, error) {
+	// Validate Azure subscription ID
+	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup environment URI
+	baseURI, err := getEnvironmentEndpointE(ResourceManagerEndpointName)
+	if err != nil {
+		return nil, err
+	}
+
+	// create Public IP Prefix client
+	client := network.NewPublicIPPrefixesClientWithBaseURI(baseURI, subscriptionID)
+	return &client, nil
+}
+
 // CreateNewSubnetClientE returns a Subnet client instance configured with the
 // correct BaseURI depending on the Azure environment that is currently setup (or "Public", if none is setup).
 func CreateNewSubnetClientE(subscriptionID string) (*network.SubnetsClient, error) {
@@ -971,6 +1042,14 @@ func CreateResourceGroupClientV2E(subscriptionID string) (*armresources.Resource
 	return clientFactory.NewResourceGroupsClient(), nil
 }
 
+func CreateResourcesClientV2E(subscriptionID string) (*armresources.Client, error) {
+	clientFactory, err := getArmResourcesClientFactory(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	return clientFactory.NewClient(), nil
+}
+
 func CreateContainerAppsClientE(subscriptionID string) (*armappcontainers.ContainerAppsClient, error) {
 	clientFactory, err := getArmAppContainersClientFactory(subscriptionID)
 	if err != nil {
@@ -989,6 +1068,296 @@ func CreateContainerAppJobsClientE(subscriptionID string) (*armappcontainers.Job
 	return client, nil
 }
 
+// CreateApiManagementServiceClientE is a helper function that will setup an API Management service client.
+func CreateApiManagementServiceClientE(subscriptionID string) (*apimanagement.ServiceClient, error) {
+	// Validate Azure subscription ID
+	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup environment URI
+	baseURI, err := getBaseURI()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the client
+	serviceClient := apimanagement.NewServiceClientWithBaseURI(baseURI, subscriptionID)
+
+	// Create an authorizer
+	authorizer, err := NewAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+
+	// Attach authorizer to the client
+	serviceClient.Authorizer = *authorizer
+
+	return &serviceClient, nil
+}
+
+// CreateApiManagementApiClientE is a helper function that will setup an API Management API client.
+func CreateApiManagementApiClientE(subscriptionID string) (*apimanagement.APIClient, error) {
+	// Validate Azure subscription ID
+	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup environment URI
+	baseURI, err := getBaseURI()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the client
+	apiClient := apimanagement.NewAPIClientWithBaseURI(baseURI, subscriptionID)
+
+	// Create an authorizer
+	authorizer, err := NewAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+
+	// Attach authorizer to the client
+	apiClient.Authorizer = *authorizer
+
+	return &apiClient, nil
+}
+
+// CreateApiManagementProductClientE is a helper function that will setup an API Management product client.
+func CreateApiManagementProductClientE(subscriptionID string) (*apimanagement.ProductClient, error) {
+	// Validate Azure subscription ID
+	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup environment URI
+	baseURI, err := getBaseURI()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the client
+	productClient := apimanagement.NewProductClientWithBaseURI(baseURI, subscriptionID)
+
+	// Create an authorizer
+	authorizer, err := NewAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+
+	// Attach authorizer to the client
+	productClient.Authorizer = *authorizer
+
+	return &productClient, nil
+}
+
+// CreateApiManagementSubscriptionClientE is a helper function that will setup an API Management subscription client.
+func CreateApiManagementSubscriptionClientE(subscriptionID string) (*apimanagement.SubscriptionClient, error) {
+	// Validate Azure subscription ID
+	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup environment URI
+	baseURI, err := getBaseURI()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the client
+	subscriptionClient := apimanagement.NewSubscriptionClientWithBaseURI(baseURI, subscriptionID)
+
+	// Create an authorizer
+	authorizer, err := NewAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+
+	// Attach authorizer to the client
+	subscriptionClient.Authorizer = *authorizer
+
+	return &subscriptionClient, nil
+}
+
+// CreateApiManagementPolicyClientE is a helper function that will setup an API Management service policy client.
+func CreateApiManagementPolicyClientE(subscriptionID string) (*apimanagement.PolicyClient, error) {
+	// Validate Azure subscription ID
+	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup environment URI
+	baseURI, err := getBaseURI()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the client
+	policyClient := apimanagement.NewPolicyClientWithBaseURI(baseURI, subscriptionID)
+
+	// Create an authorizer
+	authorizer, err := NewAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+
+	// Attach authorizer to the client
+	policyClient.Authorizer = *authorizer
+
+	return &policyClient, nil
+}
+
+// CreateApiManagementApiPolicyClientE is a helper function that will setup an API Management API policy client.
+func CreateApiManagementApiPolicyClientE(subscriptionID string) (*apimanagement.APIPolicyClient, error) {
+	// Validate Azure subscription ID
+	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup environment URI
+	baseURI, err := getBaseURI()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the client
+	apiPolicyClient := apimanagement.NewAPIPolicyClientWithBaseURI(baseURI, subscriptionID)
+
+	// Create an authorizer
+	authorizer, err := NewAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+
+	// Attach authorizer to the client
+	apiPolicyClient.Authorizer = *authorizer
+
+	return &apiPolicyClient, nil
+}
+
+// CreateApiManagementProductPolicyClientE is a helper function that will setup an API Management product policy client.
+func CreateApiManagementProductPolicyClientE(subscriptionID string) (*apimanagement.ProductPolicyClient, error) {
+	// Validate Azure subscription ID
+	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup environment URI
+	baseURI, err := getBaseURI()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the client
+	productPolicyClient := apimanagement.NewProductPolicyClientWithBaseURI(baseURI, subscriptionID)
+
+	// Create an authorizer
+	authorizer, err := NewAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+
+	// Attach authorizer to the client
+	productPolicyClient.Authorizer = *authorizer
+
+	return &productPolicyClient, nil
+}
+
+// CreateDatabricksWorkspacesClientE is a helper function that will setup a Databricks workspaces client.
+func CreateDatabricksWorkspacesClientE(subscriptionID string) (*databricks.WorkspacesClient, error) {
+	// Validate Azure subscription ID
+	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup environment URI
+	baseURI, err := getBaseURI()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the client
+	workspacesClient := databricks.NewWorkspacesClientWithBaseURI(baseURI, subscriptionID)
+
+	// Create an authorizer
+	authorizer, err := NewAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+
+	// Attach authorizer to the client
+	workspacesClient.Authorizer = *authorizer
+
+	return &workspacesClient, nil
+}
+
+// CreateRoleAssignmentsClientE is a helper function that will setup a role assignments client.
+func CreateRoleAssignmentsClientE(subscriptionID string) (*authorization.RoleAssignmentsClient, error) {
+	// Validate Azure subscription ID
+	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup environment URI
+	baseURI, err := getBaseURI()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the client
+	roleAssignmentsClient := authorization.NewRoleAssignmentsClientWithBaseURI(baseURI, subscriptionID)
+
+	// Create an authorizer
+	authorizer, err := NewAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+
+	// Attach authorizer to the client
+	roleAssignmentsClient.Authorizer = *authorizer
+
+	return &roleAssignmentsClient, nil
+}
+
+// CreateRoleDefinitionsClientE is a helper function that will setup a role definitions client.
+func CreateRoleDefinitionsClientE(subscriptionID string) (*authorization.RoleDefinitionsClient, error) {
+	// Validate Azure subscription ID
+	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup environment URI
+	baseURI, err := getBaseURI()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the client
+	roleDefinitionsClient := authorization.NewRoleDefinitionsClientWithBaseURI(baseURI, subscriptionID)
+
+	// Create an authorizer
+	authorizer, err := NewAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+
+	// Attach authorizer to the client
+	roleDefinitionsClient.Authorizer = *authorizer
+
+	return &roleDefinitionsClient, nil
+}
+
 // GetKeyVaultURISuffixE returns the proper KeyVault URI suffix for the configured Azure environment.
 // This function would fail the test if there is an error.
 func GetKeyVaultURISuffixE() (string, error) {
@@ -72,3 +72,11 @@ func safePtrToList(raw *[]string) []string {
 	}
 	return *raw
 }
+
+// safePtrToBool converts a bool pointer to a non-pointer bool value, or to false if the pointer is nil.
+func safePtrToBool(raw *bool) bool {
+	if raw == nil {
+		return false
+	}
+	return *raw
+}
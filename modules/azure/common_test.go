@@ -100,3 +100,15 @@ func TestSafePtrToInt32(t *testing.T) {
 	intResult := safePtrToInt32(&intPtr)
 	assert.Equal(t, int32(42), intResult)
 }
+
+func TestSafePtrToBool(t *testing.T) {
+	// When given a nil, should always return false
+	var nilPtr *bool = nil
+	nilResult := safePtrToBool(nilPtr)
+	assert.False(t, nilResult)
+
+	// When given a bool, should just de-ref and return
+	boolPtr := true
+	boolResult := safePtrToBool(&boolPtr)
+	assert.True(t, boolResult)
+}
@@ -16,25 +16,28 @@ func GetVirtualMachineClient(t testing.TestingT, subscriptionID string) *compute
 }
 
 // GetVirtualMachineClientE is a helper function that will setup an Azure Virtual Machine client on your behalf.
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
 func GetVirtualMachineClientE(subscriptionID string) (*compute.VirtualMachinesClient, error) {
+	return getCachedClientE("VirtualMachineClient", subscriptionID, func(subscriptionID string) (*compute.VirtualMachinesClient, error) {
 
-	// snippet-tag-start::client_factory_example.helper
-	// Create a VM client
-	vmClient, err := CreateVirtualMachinesClientE(subscriptionID)
-	if err != nil {
-		return nil, err
-	}
-	// snippet-tag-end::client_factory_example.helper
+		// snippet-tag-start::client_factory_example.helper
+		// Create a VM client
+		vmClient, err := CreateVirtualMachinesClientE(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
+		// snippet-tag-end::client_factory_example.helper
 
-	// Create an authorizer
-	authorizer, err := NewAuthorizer()
-	if err != nil {
-		return nil, err
-	}
+		// Create an authorizer
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
 
-	// Attach authorizer to the client
-	vmClient.Authorizer = *authorizer
-	return vmClient, nil
+		// Attach authorizer to the client
+		vmClient.Authorizer = *authorizer
+		return vmClient, nil
+	})
 }
 
 // VirtualMachineExists indicates whether the specifcied Azure Virtual Machine exists.
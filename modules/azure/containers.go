@@ -62,22 +62,25 @@ func GetContainerRegistryE(registryName string, resGroupName string, subscriptio
 }
 
 // GetContainerRegistryClientE is a helper function that will setup an Azure Container Registry client on your behalf
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
 func GetContainerRegistryClientE(subscriptionID string) (*containerregistry.RegistriesClient, error) {
-	// Create an ACR client
-	registryClient, err := CreateContainerRegistryClientE(subscriptionID)
-	if err != nil {
-		return nil, err
-	}
+	return getCachedClientE("ContainerRegistryClient", subscriptionID, func(subscriptionID string) (*containerregistry.RegistriesClient, error) {
+		// Create an ACR client
+		registryClient, err := CreateContainerRegistryClientE(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
 
-	// Create an authorizer
-	authorizer, err := NewAuthorizer()
-	if err != nil {
-		return nil, err
-	}
+		// Create an authorizer
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
 
-	// Attach authorizer to the client
-	registryClient.Authorizer = *authorizer
-	return registryClient, nil
+		// Attach authorizer to the client
+		registryClient.Authorizer = *authorizer
+		return registryClient, nil
+	})
 }
 
 // ContainerInstanceExists indicates whether the specified container instance exists.
@@ -132,20 +135,23 @@ func GetContainerInstanceE(instanceName string, resGroupName string, subscriptio
 }
 
 // GetContainerInstanceClientE is a helper function that will setup an Azure Container Instance client on your behalf
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
 func GetContainerInstanceClientE(subscriptionID string) (*containerinstance.ContainerGroupsClient, error) {
-	// Create an ACI client
-	instanceClient, err := CreateContainerInstanceClientE(subscriptionID)
-	if err != nil {
-		return nil, err
-	}
+	return getCachedClientE("ContainerInstanceClient", subscriptionID, func(subscriptionID string) (*containerinstance.ContainerGroupsClient, error) {
+		// Create an ACI client
+		instanceClient, err := CreateContainerInstanceClientE(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
 
-	// Create an authorizer
-	authorizer, err := NewAuthorizer()
-	if err != nil {
-		return nil, err
-	}
+		// Create an authorizer
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
 
-	// Attach authorizer to the client
-	instanceClient.Authorizer = *authorizer
-	return instanceClient, nil
+		// Attach authorizer to the client
+		instanceClient.Authorizer = *authorizer
+		return instanceClient, nil
+	})
 }
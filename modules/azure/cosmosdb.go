@@ -9,24 +9,27 @@ import (
 )
 
 // GetCosmosDBAccountClientE is a helper function that will setup a CosmosDB account client.
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
 func GetCosmosDBAccountClientE(subscriptionID string) (*documentdb.DatabaseAccountsClient, error) {
+	return getCachedClientE("CosmosDBAccountClient", subscriptionID, func(subscriptionID string) (*documentdb.DatabaseAccountsClient, error) {
 
-	// Create a CosmosDB client
-	cosmosClient, err := CreateCosmosDBAccountClientE(subscriptionID)
-	if err != nil {
-		return nil, err
-	}
+		// Create a CosmosDB client
+		cosmosClient, err := CreateCosmosDBAccountClientE(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
 
-	// Create an authorizer
-	authorizer, err := NewAuthorizer()
-	if err != nil {
-		return nil, err
-	}
+		// Create an authorizer
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
 
-	// Attach authorizer to the client
-	cosmosClient.Authorizer = *authorizer
+		// Attach authorizer to the client
+		cosmosClient.Authorizer = *authorizer
 
-	return cosmosClient, nil
+		return cosmosClient, nil
+	})
 }
 
 // GetCosmosDBAccountClient is a helper function that will setup a CosmosDB account client. This function would fail the test if there is an error.
@@ -64,24 +67,27 @@ func GetCosmosDBAccountE(t testing.TestingT, subscriptionID string, resourceGrou
 }
 
 // GetCosmosDBSQLClientE is a helper function that will setup a CosmosDB SQL client.
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
 func GetCosmosDBSQLClientE(subscriptionID string) (*documentdb.SQLResourcesClient, error) {
+	return getCachedClientE("CosmosDBSQLClient", subscriptionID, func(subscriptionID string) (*documentdb.SQLResourcesClient, error) {
 
-	// Create a CosmosDB client
-	cosmosClient, err := CreateCosmosDBSQLClientE(subscriptionID)
-	if err != nil {
-		return nil, err
-	}
+		// Create a CosmosDB client
+		cosmosClient, err := CreateCosmosDBSQLClientE(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
 
-	// Create an authorizer
-	authorizer, err := NewAuthorizer()
-	if err != nil {
-		return nil, err
-	}
+		// Create an authorizer
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
 
-	// Attach authorizer to the client
-	cosmosClient.Authorizer = *authorizer
+		// Attach authorizer to the client
+		cosmosClient.Authorizer = *authorizer
 
-	return cosmosClient, nil
+		return cosmosClient, nil
+	})
 }
 
 // GetCosmosDBSQLClient is a helper function that will setup a CosmosDB SQL client. This function would fail the test if there is an error.
@@ -0,0 +1,258 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/services/databricks/mgmt/2018-04-01/databricks"
+	http_helper "github.com/gruntwork-io/terratest/modules/http-helper"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// DatabricksResourceID is the well known application ID of Azure Databricks, used as the audience when requesting
+// tokens for the Databricks data plane (REST API).
+const DatabricksResourceID = "2ff814a6-3304-4ab8-85cb-cd0e6f879c1d"
+
+// DatabricksCluster is a summary of a cluster returned by the Databricks Clusters API.
+type DatabricksCluster struct {
+	ClusterID    string `json:"cluster_id"`
+	ClusterName  string `json:"cluster_name"`
+	State        string `json:"state"`
+	SparkVersion string `json:"spark_version"`
+}
+
+// DatabricksWorkspaceExists indicates whether the specified Databricks workspace exists.
+// This function would fail the test if there is an error.
+func DatabricksWorkspaceExists(t testing.TestingT, workspaceName string, resourceGroupName string, subscriptionID string) bool {
+	exists, err := DatabricksWorkspaceExistsE(workspaceName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return exists
+}
+
+// DatabricksWorkspaceExistsE indicates whether the specified Databricks workspace exists.
+func DatabricksWorkspaceExistsE(workspaceName string, resourceGroupName string, subscriptionID string) (bool, error) {
+	_, err := GetDatabricksWorkspaceE(workspaceName, resourceGroupName, subscriptionID)
+	if err != nil {
+		if ResourceNotFoundErrorExists(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// GetDatabricksWorkspace gets the specified Databricks workspace.
+// This function would fail the test if there is an error.
+func GetDatabricksWorkspace(t testing.TestingT, workspaceName string, resourceGroupName string, subscriptionID string) *databricks.Workspace {
+	workspace, err := GetDatabricksWorkspaceE(workspaceName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return workspace
+}
+
+// GetDatabricksWorkspaceE gets the specified Databricks workspace.
+func GetDatabricksWorkspaceE(workspaceName string, resourceGroupName string, subscriptionID string) (*databricks.Workspace, error) {
+	resourceGroupName, err := getTargetAzureResourceGroupName(resourceGroupName)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := CreateDatabricksWorkspacesClientE(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace, err := client.Get(context.Background(), resourceGroupName, workspaceName)
+	if err != nil {
+		return nil, err
+	}
+	return &workspace, nil
+}
+
+// GetDatabricksWorkspaceProvisioningState gets the provisioning state of the specified Databricks workspace, e.g.
+// Succeeded.
+// This function would fail the test if there is an error.
+func GetDatabricksWorkspaceProvisioningState(t testing.TestingT, workspaceName string, resourceGroupName string, subscriptionID string) string {
+	state, err := GetDatabricksWorkspaceProvisioningStateE(workspaceName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return state
+}
+
+// GetDatabricksWorkspaceProvisioningStateE gets the provisioning state of the specified Databricks workspace, e.g.
+// Succeeded.
+func GetDatabricksWorkspaceProvisioningStateE(workspaceName string, resourceGroupName string, subscriptionID string) (string, error) {
+	workspace, err := GetDatabricksWorkspaceE(workspaceName, resourceGroupName, subscriptionID)
+	if err != nil {
+		return "", err
+	}
+	if workspace.WorkspaceProperties == nil {
+		return "", NewNotFoundError("Databricks workspace properties", workspaceName, resourceGroupName)
+	}
+	return string(workspace.WorkspaceProperties.ProvisioningState), nil
+}
+
+// DatabricksWorkspaceSecureClusterConnectivityEnabled indicates whether secure cluster connectivity (no public IP) is
+// enabled for the specified Databricks workspace.
+// This function would fail the test if there is an error.
+func DatabricksWorkspaceSecureClusterConnectivityEnabled(t testing.TestingT, workspaceName string, resourceGroupName string, subscriptionID string) bool {
+	enabled, err := DatabricksWorkspaceSecureClusterConnectivityEnabledE(workspaceName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return enabled
+}
+
+// DatabricksWorkspaceSecureClusterConnectivityEnabledE indicates whether secure cluster connectivity (no public IP) is
+// enabled for the specified Databricks workspace.
+func DatabricksWorkspaceSecureClusterConnectivityEnabledE(workspaceName string, resourceGroupName string, subscriptionID string) (bool, error) {
+	workspace, err := GetDatabricksWorkspaceE(workspaceName, resourceGroupName, subscriptionID)
+	if err != nil {
+		return false, err
+	}
+	return isDatabricksSecureClusterConnectivityEnabled(workspace), nil
+}
+
+// GetDatabricksWorkspaceVirtualNetworkID gets the ID of the virtual network the specified Databricks workspace is
+// injected into, or "" if the workspace uses the managed virtual network.
+// This function would fail the test if there is an error.
+func GetDatabricksWorkspaceVirtualNetworkID(t testing.TestingT, workspaceName string, resourceGroupName string, subscriptionID string) string {
+	vnetID, err := GetDatabricksWorkspaceVirtualNetworkIDE(workspaceName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return vnetID
+}
+
+// GetDatabricksWorkspaceVirtualNetworkIDE gets the ID of the virtual network the specified Databricks workspace is
+// injected into, or "" if the workspace uses the managed virtual network.
+func GetDatabricksWorkspaceVirtualNetworkIDE(workspaceName string, resourceGroupName string, subscriptionID string) (string, error) {
+	workspace, err := GetDatabricksWorkspaceE(workspaceName, resourceGroupName, subscriptionID)
+	if err != nil {
+		return "", err
+	}
+	vnetID, _, _ := getDatabricksVNetInjection(workspace)
+	return vnetID, nil
+}
+
+// DatabricksWorkspaceVNetInjected indicates whether the specified Databricks workspace is deployed into the given
+// virtual network, using the given public (host) and private (container) subnets.
+// This function would fail the test if there is an error.
+func DatabricksWorkspaceVNetInjected(t testing.TestingT, workspaceName string, resourceGroupName string, subscriptionID string, vnetID string, publicSubnetName string, privateSubnetName string) bool {
+	injected, err := DatabricksWorkspaceVNetInjectedE(workspaceName, resourceGroupName, subscriptionID, vnetID, publicSubnetName, privateSubnetName)
+	require.NoError(t, err)
+	return injected
+}
+
+// DatabricksWorkspaceVNetInjectedE indicates whether the specified Databricks workspace is deployed into the given
+// virtual network, using the given public (host) and private (container) subnets.
+func DatabricksWorkspaceVNetInjectedE(workspaceName string, resourceGroupName string, subscriptionID string, vnetID string, publicSubnetName string, privateSubnetName string) (bool, error) {
+	workspace, err := GetDatabricksWorkspaceE(workspaceName, resourceGroupName, subscriptionID)
+	if err != nil {
+		return false, err
+	}
+
+	actualVNetID, actualPublicSubnet, actualPrivateSubnet := getDatabricksVNetInjection(workspace)
+	return actualVNetID != "" &&
+		actualVNetID == vnetID &&
+		actualPublicSubnet == publicSubnetName &&
+		actualPrivateSubnet == privateSubnetName, nil
+}
+
+// GetDatabricksDataPlaneToken gets an Azure AD access token for the Azure Databricks data plane (REST API), using the
+// default Azure credential chain.
+// This function would fail the test if there is an error.
+func GetDatabricksDataPlaneToken(t testing.TestingT) string {
+	token, err := GetDatabricksDataPlaneTokenE()
+	require.NoError(t, err)
+	return token
+}
+
+// GetDatabricksDataPlaneTokenE gets an Azure AD access token for the Azure Databricks data plane (REST API), using the
+// default Azure credential chain.
+func GetDatabricksDataPlaneTokenE() (string, error) {
+	return getAccessTokenE(DatabricksResourceID + "/.default")
+}
+
+// ListDatabricksClusters lists the clusters of the specified Databricks workspace through its data plane REST API.
+// This is a useful smoke test that the workspace is reachable and the caller is authorized to use it.
+// This function would fail the test if there is an error.
+func ListDatabricksClusters(t testing.TestingT, workspaceName string, resourceGroupName string, subscriptionID string) []DatabricksCluster {
+	clusters, err := ListDatabricksClustersE(t, workspaceName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return clusters
+}
+
+// ListDatabricksClustersE lists the clusters of the specified Databricks workspace through its data plane REST API.
+// This is a useful smoke test that the workspace is reachable and the caller is authorized to use it.
+func ListDatabricksClustersE(t testing.TestingT, workspaceName string, resourceGroupName string, subscriptionID string) ([]DatabricksCluster, error) {
+	workspace, err := GetDatabricksWorkspaceE(workspaceName, resourceGroupName, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if workspace.WorkspaceProperties == nil || workspace.WorkspaceProperties.WorkspaceURL == nil {
+		return nil, NewNotFoundError("Databricks workspace URL", workspaceName, resourceGroupName)
+	}
+
+	token, err := GetDatabricksDataPlaneTokenE()
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("https://%s/api/2.0/clusters/list", *workspace.WorkspaceProperties.WorkspaceURL)
+	headers := map[string]string{
+		"Authorization": "Bearer " + token,
+	}
+
+	statusCode, body, err := http_helper.HTTPDoE(t, http.MethodGet, url, nil, headers, nil)
+	if err != nil {
+		return nil, err
+	}
+	if statusCode != http.StatusOK {
+		return nil, fmt.Errorf("listing clusters of Databricks workspace %s returned status %d: %s", workspaceName, statusCode, body)
+	}
+
+	return parseDatabricksClusterList(body)
+}
+
+// parseDatabricksClusterList parses the response body of the Databricks Clusters API list call.
+func parseDatabricksClusterList(body string) ([]DatabricksCluster, error) {
+	var response struct {
+		Clusters []DatabricksCluster `json:"clusters"`
+	}
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		return nil, err
+	}
+	if response.Clusters == nil {
+		return []DatabricksCluster{}, nil
+	}
+	return response.Clusters, nil
+}
+
+// isDatabricksSecureClusterConnectivityEnabled indicates whether the no public IP parameter is set on the workspace.
+func isDatabricksSecureClusterConnectivityEnabled(workspace *databricks.Workspace) bool {
+	if workspace == nil || workspace.WorkspaceProperties == nil || workspace.Parameters == nil {
+		return false
+	}
+	if workspace.Parameters.EnableNoPublicIP == nil {
+		return false
+	}
+	return safePtrToBool(workspace.Parameters.EnableNoPublicIP.Value)
+}
+
+// getDatabricksVNetInjection returns the virtual network ID and the public and private subnet names the workspace is
+// injected into, or empty strings if it uses the managed virtual network.
+func getDatabricksVNetInjection(workspace *databricks.Workspace) (string, string, string) {
+	if workspace == nil || workspace.WorkspaceProperties == nil || workspace.Parameters == nil {
+		return "", "", ""
+	}
+
+	parameters := workspace.Parameters
+	stringParameter := func(parameter *databricks.WorkspaceCustomStringParameter) string {
+		if parameter == nil {
+			return ""
+		}
+		return safePtrToString(parameter.Value)
+	}
+
+	return stringParameter(parameters.CustomVirtualNetworkID),
+		stringParameter(parameters.CustomPublicSubnetName),
+		stringParameter(parameters.CustomPrivateSubnetName)
+}
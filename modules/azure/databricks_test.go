@@ -0,0 +1,74 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/databricks/mgmt/2018-04-01/databricks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/*
+The below tests are currently stubbed out, with the expectation that they will throw errors.
+If/when methods to create and delete Databricks resources are added, these tests can be extended.
+*/
+
+func TestDatabricksWorkspaceExistsE(t *testing.T) {
+	t.Parallel()
+
+	_, err := DatabricksWorkspaceExistsE("", "", "")
+	require.Error(t, err)
+}
+
+func TestGetDatabricksWorkspaceProvisioningStateE(t *testing.T) {
+	t.Parallel()
+
+	_, err := GetDatabricksWorkspaceProvisioningStateE("", "", "")
+	require.Error(t, err)
+}
+
+func TestDatabricksWorkspaceNetworking(t *testing.T) {
+	t.Parallel()
+
+	noPublicIP := true
+	vnetID := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet"
+	publicSubnet := "host"
+	privateSubnet := "container"
+
+	workspace := &databricks.Workspace{
+		WorkspaceProperties: &databricks.WorkspaceProperties{
+			Parameters: &databricks.WorkspaceCustomParameters{
+				EnableNoPublicIP:        &databricks.WorkspaceCustomBooleanParameter{Value: &noPublicIP},
+				CustomVirtualNetworkID:  &databricks.WorkspaceCustomStringParameter{Value: &vnetID},
+				CustomPublicSubnetName:  &databricks.WorkspaceCustomStringParameter{Value: &publicSubnet},
+				CustomPrivateSubnetName: &databricks.WorkspaceCustomStringParameter{Value: &privateSubnet},
+			},
+		},
+	}
+
+	assert.True(t, isDatabricksSecureClusterConnectivityEnabled(workspace))
+	actualVNetID, actualPublicSubnet, actualPrivateSubnet := getDatabricksVNetInjection(workspace)
+	assert.Equal(t, vnetID, actualVNetID)
+	assert.Equal(t, publicSubnet, actualPublicSubnet)
+	assert.Equal(t, privateSubnet, actualPrivateSubnet)
+
+	managed := &databricks.Workspace{WorkspaceProperties: &databricks.WorkspaceProperties{}}
+	assert.False(t, isDatabricksSecureClusterConnectivityEnabled(managed))
+	actualVNetID, _, _ = getDatabricksVNetInjection(managed)
+	assert.Empty(t, actualVNetID)
+}
+
+func TestParseDatabricksClusterList(t *testing.T) {
+	t.Parallel()
+
+	clusters, err := parseDatabricksClusterList(`{"clusters":[{"cluster_id":"0123-abc","cluster_name":"smoke","state":"RUNNING","spark_version":"13.3.x-scala2.12"}]}`)
+	require.NoError(t, err)
+	assert.Equal(t, []DatabricksCluster{{ClusterID: "0123-abc", ClusterName: "smoke", State: "RUNNING", SparkVersion: "13.3.x-scala2.12"}}, clusters)
+
+	clusters, err = parseDatabricksClusterList(`{}`)
+	require.NoError(t, err)
+	assert.Empty(t, clusters)
+
+	_, err = parseDatabricksClusterList(`not json`)
+	require.Error(t, err)
+}
@@ -95,33 +95,39 @@ func GetFrontDoorFrontendEndpointE(endpointName, frontDoorName, resourceGroupNam
 }
 
 // GetFrontDoorClientE return a front door client; otherwise error.
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
 func GetFrontDoorClientE(subscriptionID string) (*frontdoor.FrontDoorsClient, error) {
-	client, err := CreateFrontDoorClientE(subscriptionID)
-	if err != nil {
-		return nil, err
-	}
+	return getCachedClientE("FrontDoorClient", subscriptionID, func(subscriptionID string) (*frontdoor.FrontDoorsClient, error) {
+		client, err := CreateFrontDoorClientE(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
 
-	authorizer, err := NewAuthorizer()
-	if err != nil {
-		return nil, err
-	}
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
 
-	client.Authorizer = *authorizer
-	return client, nil
+		client.Authorizer = *authorizer
+		return client, nil
+	})
 }
 
 // GetFrontDoorFrontendEndpointClientE returns a front door frontend endpoints client; otherwise error.
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
 func GetFrontDoorFrontendEndpointClientE(subscriptionID string) (*frontdoor.FrontendEndpointsClient, error) {
-	client, err := CreateFrontDoorFrontendEndpointClientE(subscriptionID)
-	if err != nil {
-		return nil, err
-	}
+	return getCachedClientE("FrontDoorFrontendEndpointClient", subscriptionID, func(subscriptionID string) (*frontdoor.FrontendEndpointsClient, error) {
+		client, err := CreateFrontDoorFrontendEndpointClientE(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
 
-	authorizer, err := NewAuthorizer()
-	if err != nil {
-		return nil, err
-	}
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
 
-	client.Authorizer = *authorizer
-	return client, nil
+		client.Authorizer = *authorizer
+		return client, nil
+	})
 }
@@ -113,18 +113,27 @@ func KeyVaultSecretExistsE(keyVaultName, secretName string) (bool, error) {
 }
 
 // GetKeyVaultClientE creates a KeyVault client.
+// The client is cached and reused, see SetClientCacheEnabled.
 func GetKeyVaultClientE() (*keyvault.BaseClient, error) {
-	kvClient := keyvault.New()
-	authorizer, err := NewKeyVaultAuthorizerE()
-	if err != nil {
-		return nil, err
-	}
-	kvClient.Authorizer = *authorizer
-	return &kvClient, nil
+	return getCachedE(newClientCacheKey("KeyVaultClient", ""), func() (*keyvault.BaseClient, error) {
+		kvClient := keyvault.New()
+		authorizer, err := NewKeyVaultAuthorizerE()
+		if err != nil {
+			return nil, err
+		}
+		kvClient.Authorizer = *authorizer
+		return &kvClient, nil
+	})
 }
 
 // NewKeyVaultAuthorizerE will return dataplane Authorizer for KeyVault.
+// The authorizer is cached and reused per credentials, like NewAuthorizer.
 func NewKeyVaultAuthorizerE() (*autorest.Authorizer, error) {
+	return getCachedE(newClientCacheKey("KeyVaultAuthorizer", ""), newKeyVaultAuthorizerE)
+}
+
+// newKeyVaultAuthorizerE creates a new dataplane Authorizer for KeyVault, bypassing the cache.
+func newKeyVaultAuthorizerE() (*autorest.Authorizer, error) {
 	// Carry out env var lookups
 	_, clientIDExists := os.LookupEnv(AuthFromEnvClient)
 	_, tenantIDExists := os.LookupEnv(AuthFromEnvTenant)
@@ -171,21 +180,24 @@ func GetKeyVaultE(t *testing.T, resGroupName string, keyVaultName string, subscr
 }
 
 // GetKeyVaultManagementClientE is a helper function that will setup a key vault management client
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
 func GetKeyVaultManagementClientE(subscriptionID string) (*kvmng.VaultsClient, error) {
-	// Create a keyvault management client
-	vaultClient, err := CreateKeyVaultManagementClientE(subscriptionID)
-	if err != nil {
-		return nil, err
-	}
-
-	// Create an authorizer
-	authorizer, err := NewAuthorizer()
-	if err != nil {
-		return nil, err
-	}
-
-	// Attach authorizer to the client
-	vaultClient.Authorizer = *authorizer
-
-	return vaultClient, nil
+	return getCachedClientE("KeyVaultManagementClient", subscriptionID, func(subscriptionID string) (*kvmng.VaultsClient, error) {
+		// Create a keyvault management client
+		vaultClient, err := CreateKeyVaultManagementClientE(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Create an authorizer
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
+
+		// Attach authorizer to the client
+		vaultClient.Authorizer = *authorizer
+
+		return vaultClient, nil
+	})
 }
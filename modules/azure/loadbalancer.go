@@ -2,6 +2,8 @@ package azure
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-09-01/network"
 	"github.com/gruntwork-io/terratest/modules/testing"
@@ -130,24 +132,27 @@ func GetLoadBalancerFrontendIPConfigE(feConfigName string, loadBalancerName stri
 }
 
 // GetLoadBalancerFrontendIPConfigClientE gets a new Load Balancer Frontend IP Configuration client in the specified Azure Subscription.
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
 func GetLoadBalancerFrontendIPConfigClientE(subscriptionID string) (*network.LoadBalancerFrontendIPConfigurationsClient, error) {
-	// Validate Azure subscription ID
-	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
-	if err != nil {
-		return nil, err
-	}
+	return getCachedClientE("LoadBalancerFrontendIPConfigClient", subscriptionID, func(subscriptionID string) (*network.LoadBalancerFrontendIPConfigurationsClient, error) {
+		// Validate Azure subscription ID
+		subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
 
-	// Get the Load Balancer Frontend Configuration client
-	client := network.NewLoadBalancerFrontendIPConfigurationsClient(subscriptionID)
+		// Get the Load Balancer Frontend Configuration client
+		client := network.NewLoadBalancerFrontendIPConfigurationsClient(subscriptionID)
 
-	// Create an authorizer
-	authorizer, err := NewAuthorizer()
-	if err != nil {
-		return nil, err
-	}
-	client.Authorizer = *authorizer
+		// Create an authorizer
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
+		client.Authorizer = *authorizer
 
-	return &client, nil
+		return &client, nil
+	})
 }
 
 // GetLoadBalancer gets a Load Balancer network resource in the specified Azure Resource Group.
@@ -181,20 +186,190 @@ func GetLoadBalancerE(loadBalancerName string, resourceGroupName string, subscri
 	return &lb, nil
 }
 
-// GetLoadBalancerClientE gets a new Load Balancer client in the specified Azure Subscription.
-func GetLoadBalancerClientE(subscriptionID string) (*network.LoadBalancersClient, error) {
-	// Get the Load Balancer client
-	client, err := CreateLoadBalancerClientE(subscriptionID)
+// ListLoadBalancers lists all the Load Balancers in the specified Azure Resource Group.
+// This function would fail the test if there is an error.
+func ListLoadBalancers(t testing.TestingT, resourceGroupName string, subscriptionID string) []network.LoadBalancer {
+	lbs, err := ListLoadBalancersE(resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return lbs
+}
+
+// ListLoadBalancersE lists all the Load Balancers in the specified Azure Resource Group.
+func ListLoadBalancersE(resourceGroupName string, subscriptionID string) ([]network.LoadBalancer, error) {
+	// Validate Azure Resource Group Name
+	resourceGroupName, err := getTargetAzureResourceGroupName(resourceGroupName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the client reference
+	client, err := GetLoadBalancerClientE(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Page through all the Load Balancers of the Resource Group
+	iter, err := client.ListComplete(context.Background(), resourceGroupName)
+	if err != nil {
+		return nil, err
+	}
+	return collectLoadBalancers(iter)
+}
+
+// ListLoadBalancersBySubscription lists all the Load Balancers in the specified Azure Subscription, across all of its
+// Resource Groups.
+// This function would fail the test if there is an error.
+func ListLoadBalancersBySubscription(t testing.TestingT, subscriptionID string) []network.LoadBalancer {
+	lbs, err := ListLoadBalancersBySubscriptionE(subscriptionID)
+	require.NoError(t, err)
+	return lbs
+}
+
+// ListLoadBalancersBySubscriptionE lists all the Load Balancers in the specified Azure Subscription, across all of its
+// Resource Groups.
+func ListLoadBalancersBySubscriptionE(subscriptionID string) ([]network.LoadBalancer, error) {
+	// Get the client reference
+	client, err := GetLoadBalancerClientE(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Page through all the Load Balancers of the Subscription
+	iter, err := client.ListAllComplete(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return collectLoadBalancers(iter)
+}
+
+// GetLoadBalancerForVirtualMachineScaleSet gets the Load Balancer whose backend pools contain the instances of the
+// specified Virtual Machine Scale Set. All the Resource Groups of the Subscription are searched, as Load Balancers
+// created by managed services often live in a different Resource Group than the one of the module under test.
+// This function would fail the test if there is an error.
+func GetLoadBalancerForVirtualMachineScaleSet(t testing.TestingT, vmssName string, subscriptionID string) *network.LoadBalancer {
+	lb, err := GetLoadBalancerForVirtualMachineScaleSetE(vmssName, subscriptionID)
+	require.NoError(t, err)
+	return lb
+}
+
+// GetLoadBalancerForVirtualMachineScaleSetE gets the Load Balancer whose backend pools contain the instances of the
+// specified Virtual Machine Scale Set. All the Resource Groups of the Subscription are searched, as Load Balancers
+// created by managed services often live in a different Resource Group than the one of the module under test.
+func GetLoadBalancerForVirtualMachineScaleSetE(vmssName string, subscriptionID string) (*network.LoadBalancer, error) {
+	lbs, err := ListLoadBalancersBySubscriptionE(subscriptionID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create an authorizer
-	authorizer, err := NewAuthorizer()
+	lb := findLoadBalancerForScaleSet(lbs, func(name string) bool {
+		return strings.EqualFold(name, vmssName)
+	})
+	if lb == nil {
+		return nil, NewNotFoundError("Load Balancer for Virtual Machine Scale Set", vmssName, subscriptionID)
+	}
+	return lb, nil
+}
+
+// GetLoadBalancerForAksNodePool gets the Load Balancer whose backend pools contain the nodes of the specified AKS
+// node pool. The Load Balancer is looked up in the node Resource Group of the cluster.
+// This function would fail the test if there is an error.
+func GetLoadBalancerForAksNodePool(t testing.TestingT, clusterName string, nodePoolName string, resourceGroupName string, subscriptionID string) *network.LoadBalancer {
+	lb, err := GetLoadBalancerForAksNodePoolE(t, clusterName, nodePoolName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return lb
+}
+
+// GetLoadBalancerForAksNodePoolE gets the Load Balancer whose backend pools contain the nodes of the specified AKS
+// node pool. The Load Balancer is looked up in the node Resource Group of the cluster.
+func GetLoadBalancerForAksNodePoolE(t testing.TestingT, clusterName string, nodePoolName string, resourceGroupName string, subscriptionID string) (*network.LoadBalancer, error) {
+	cluster, err := GetManagedClusterE(t, resourceGroupName, clusterName, subscriptionID)
 	if err != nil {
 		return nil, err
 	}
-	client.Authorizer = *authorizer
+	if cluster.ManagedClusterProperties == nil || cluster.NodeResourceGroup == nil {
+		return nil, NewNotFoundError("AKS node resource group", clusterName, resourceGroupName)
+	}
+
+	lbs, err := ListLoadBalancersE(*cluster.NodeResourceGroup, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// AKS names the scale set of a node pool aks-<node pool name>-<hash>-vmss
+	prefix := fmt.Sprintf("aks-%s-", nodePoolName)
+	lb := findLoadBalancerForScaleSet(lbs, func(name string) bool {
+		return strings.HasPrefix(strings.ToLower(name), strings.ToLower(prefix))
+	})
+	if lb == nil {
+		return nil, NewNotFoundError("Load Balancer for AKS node pool", nodePoolName, *cluster.NodeResourceGroup)
+	}
+	return lb, nil
+}
+
+// collectLoadBalancers pages through the given Load Balancer iterator and returns all of its values.
+func collectLoadBalancers(iter network.LoadBalancerListResultIterator) ([]network.LoadBalancer, error) {
+	lbs := []network.LoadBalancer{}
+	for iter.NotDone() {
+		lbs = append(lbs, iter.Value())
+		if err := iter.NextWithContext(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+	return lbs, nil
+}
+
+// findLoadBalancerForScaleSet returns the first Load Balancer with a backend IP configuration belonging to a Virtual
+// Machine Scale Set matching the given predicate, or nil if there is none.
+func findLoadBalancerForScaleSet(lbs []network.LoadBalancer, matchesScaleSet func(vmssName string) bool) *network.LoadBalancer {
+	for i := range lbs {
+		lbProps := lbs[i].LoadBalancerPropertiesFormat
+		if lbProps == nil || lbProps.BackendAddressPools == nil {
+			continue
+		}
+		for _, pool := range *lbProps.BackendAddressPools {
+			if pool.BackendAddressPoolPropertiesFormat == nil || pool.BackendIPConfigurations == nil {
+				continue
+			}
+			for _, ipConfig := range *pool.BackendIPConfigurations {
+				vmssName := getScaleSetNameFromResourceID(safePtrToString(ipConfig.ID))
+				if vmssName != "" && matchesScaleSet(vmssName) {
+					return &lbs[i]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// getScaleSetNameFromResourceID returns the Virtual Machine Scale Set name from the ID of a resource belonging to a
+// scale set instance, e.g. the IP configuration of an instance network interface, or "" if there is none.
+func getScaleSetNameFromResourceID(resourceID string) string {
+	segments := strings.Split(resourceID, "/")
+	for i := 0; i < len(segments)-1; i++ {
+		if strings.EqualFold(segments[i], "virtualMachineScaleSets") {
+			return segments[i+1]
+		}
+	}
+	return ""
+}
+
+// GetLoadBalancerClientE gets a new Load Balancer client in the specified Azure Subscription.
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
+func GetLoadBalancerClientE(subscriptionID string) (*network.LoadBalancersClient, error) {
+	return getCachedClientE("LoadBalancerClient", subscriptionID, func(subscriptionID string) (*network.LoadBalancersClient, error) {
+		// Get the Load Balancer client
+		client, err := CreateLoadBalancerClientE(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Create an authorizer
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
+		client.Authorizer = *authorizer
 
-	return client, nil
+		return client, nil
+	})
 }
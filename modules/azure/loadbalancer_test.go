@@ -9,6 +9,8 @@ package azure
 import (
 	"testing"
 
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-09-01/network"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -40,3 +42,55 @@ func TestGetLoadBalancerE(t *testing.T) {
 
 	require.Error(t, err)
 }
+
+func TestListLoadBalancersE(t *testing.T) {
+	t.Parallel()
+
+	resourceGroupName := ""
+	subscriptionID := ""
+
+	_, err := ListLoadBalancersE(resourceGroupName, subscriptionID)
+
+	require.Error(t, err)
+}
+
+func TestGetLoadBalancerForVirtualMachineScaleSetE(t *testing.T) {
+	t.Parallel()
+
+	vmssName := ""
+	subscriptionID := ""
+
+	_, err := GetLoadBalancerForVirtualMachineScaleSetE(vmssName, subscriptionID)
+
+	require.Error(t, err)
+}
+
+func TestFindLoadBalancerForScaleSet(t *testing.T) {
+	t.Parallel()
+
+	ipConfigID := "/subscriptions/sub/resourceGroups/MC_rg_aks_westus/providers/Microsoft.Compute/virtualMachineScaleSets/aks-nodepool1-12345678-vmss/virtualMachines/0/networkInterfaces/aks-nodepool1-12345678-vmss/ipConfigurations/ipconfig1"
+	lbName := "kubernetes"
+	lbs := []network.LoadBalancer{
+		{},
+		{
+			Name: &lbName,
+			LoadBalancerPropertiesFormat: &network.LoadBalancerPropertiesFormat{
+				BackendAddressPools: &[]network.BackendAddressPool{
+					{
+						BackendAddressPoolPropertiesFormat: &network.BackendAddressPoolPropertiesFormat{
+							BackendIPConfigurations: &[]network.InterfaceIPConfiguration{{ID: &ipConfigID}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, "aks-nodepool1-12345678-vmss", getScaleSetNameFromResourceID(ipConfigID))
+	assert.Empty(t, getScaleSetNameFromResourceID("/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/networkInterfaces/nic"))
+
+	lb := findLoadBalancerForScaleSet(lbs, func(name string) bool { return name == "aks-nodepool1-12345678-vmss" })
+	require.NotNil(t, lb)
+	assert.Equal(t, lbName, *lb.Name)
+	assert.Nil(t, findLoadBalancerForScaleSet(lbs, func(name string) bool { return name == "other-vmss" }))
+}
@@ -53,20 +53,129 @@ func LogAnalyticsWorkspaceExistsE(workspaceName string, resourceGroupName string
 }
 
 // GetLogAnalyticsWorkspacesClientE return workspaces client; otherwise error.
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
 func GetLogAnalyticsWorkspacesClientE(subscriptionID string) (*operationalinsights.WorkspacesClient, error) {
-	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+	return getCachedClientE("LogAnalyticsWorkspacesClient", subscriptionID, func(subscriptionID string) (*operationalinsights.WorkspacesClient, error) {
+		subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+		if err != nil {
+			fmt.Println("Workspace client error getting subscription")
+			return nil, err
+		}
+
+		client := operationalinsights.NewWorkspacesClient(subscriptionID)
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			fmt.Println("authorizer error")
+			return nil, err
+		}
+
+		client.Authorizer = *authorizer
+		return &client, nil
+	})
+}
+
+// GetLogAnalyticsWorkspaceRetentionInDays gets the data retention in days of an operational insights workspace.
+// This function would fail the test if there is an error.
+func GetLogAnalyticsWorkspaceRetentionInDays(t testing.TestingT, workspaceName string, resourceGroupName string, subscriptionID string) int32 {
+	retention, err := GetLogAnalyticsWorkspaceRetentionInDaysE(workspaceName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+
+	return retention
+}
+
+// GetLogAnalyticsWorkspaceRetentionInDaysE gets the data retention in days of an operational insights workspace.
+// A value of -1 means unlimited retention.
+func GetLogAnalyticsWorkspaceRetentionInDaysE(workspaceName string, resourceGroupName string, subscriptionID string) (int32, error) {
+	ws, err := GetLogAnalyticsWorkspaceE(workspaceName, resourceGroupName, subscriptionID)
+	if err != nil {
+		return 0, err
+	}
+	if ws.WorkspaceProperties == nil {
+		return 0, NewNotFoundError("Log Analytics workspace properties", workspaceName, resourceGroupName)
+	}
+
+	return safePtrToInt32(ws.WorkspaceProperties.RetentionInDays), nil
+}
+
+// GetLogAnalyticsWorkspaceSku gets the SKU name of an operational insights workspace, e.g. PerGB2018.
+// This function would fail the test if there is an error.
+func GetLogAnalyticsWorkspaceSku(t testing.TestingT, workspaceName string, resourceGroupName string, subscriptionID string) string {
+	sku, err := GetLogAnalyticsWorkspaceSkuE(workspaceName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+
+	return sku
+}
+
+// GetLogAnalyticsWorkspaceSkuE gets the SKU name of an operational insights workspace, e.g. PerGB2018.
+func GetLogAnalyticsWorkspaceSkuE(workspaceName string, resourceGroupName string, subscriptionID string) (string, error) {
+	ws, err := GetLogAnalyticsWorkspaceE(workspaceName, resourceGroupName, subscriptionID)
+	if err != nil {
+		return "", err
+	}
+	if ws.WorkspaceProperties == nil || ws.WorkspaceProperties.Sku == nil {
+		return "", NewNotFoundError("Log Analytics workspace SKU", workspaceName, resourceGroupName)
+	}
+
+	return string(ws.WorkspaceProperties.Sku.Name), nil
+}
+
+// GetLogAnalyticsWorkspaceLinkedSolutions gets the names of the solutions (intelligence packs) enabled on an
+// operational insights workspace, e.g. Security or VMInsights.
+// This function would fail the test if there is an error.
+func GetLogAnalyticsWorkspaceLinkedSolutions(t testing.TestingT, workspaceName string, resourceGroupName string, subscriptionID string) []string {
+	solutions, err := GetLogAnalyticsWorkspaceLinkedSolutionsE(workspaceName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+
+	return solutions
+}
+
+// GetLogAnalyticsWorkspaceLinkedSolutionsE gets the names of the solutions (intelligence packs) enabled on an
+// operational insights workspace, e.g. Security or VMInsights.
+func GetLogAnalyticsWorkspaceLinkedSolutionsE(workspaceName string, resourceGroupName string, subscriptionID string) ([]string, error) {
+	client, err := GetLogAnalyticsIntelligencePacksClientE(subscriptionID)
 	if err != nil {
-		fmt.Println("Workspace client error getting subscription")
 		return nil, err
 	}
 
-	client := operationalinsights.NewWorkspacesClient(subscriptionID)
-	authorizer, err := NewAuthorizer()
+	packs, err := client.List(context.Background(), resourceGroupName, workspaceName)
 	if err != nil {
-		fmt.Println("authorizer error")
 		return nil, err
 	}
 
-	client.Authorizer = *authorizer
-	return &client, nil
+	return getEnabledIntelligencePackNames(packs.Value), nil
+}
+
+// GetLogAnalyticsIntelligencePacksClientE return intelligence packs client; otherwise error.
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
+func GetLogAnalyticsIntelligencePacksClientE(subscriptionID string) (*operationalinsights.IntelligencePacksClient, error) {
+	return getCachedClientE("LogAnalyticsIntelligencePacksClient", subscriptionID, func(subscriptionID string) (*operationalinsights.IntelligencePacksClient, error) {
+		subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
+
+		client := operationalinsights.NewIntelligencePacksClient(subscriptionID)
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
+
+		client.Authorizer = *authorizer
+		return &client, nil
+	})
+}
+
+// getEnabledIntelligencePackNames returns the names of the enabled intelligence packs in the given list.
+func getEnabledIntelligencePackNames(packs *[]operationalinsights.IntelligencePack) []string {
+	names := []string{}
+	if packs == nil {
+		return names
+	}
+
+	for _, pack := range *packs {
+		if safePtrToBool(pack.Enabled) {
+			names = append(names, safePtrToString(pack.Name))
+		}
+	}
+	return names
 }
@@ -3,6 +3,7 @@ package azure
 import (
 	"testing"
 
+	"github.com/Azure/azure-sdk-for-go/services/preview/operationalinsights/mgmt/2020-03-01-preview/operationalinsights"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -28,3 +29,41 @@ func TestGetLogAnalyticsWorkspaceE(t *testing.T) {
 	_, err := GetLogAnalyticsWorkspaceE(workspaceName, resourceGroupName, subscriptionID)
 	require.Error(t, err)
 }
+
+func TestGetLogAnalyticsWorkspaceRetentionInDaysE(t *testing.T) {
+	t.Parallel()
+
+	_, err := GetLogAnalyticsWorkspaceRetentionInDaysE("", "", "")
+	require.Error(t, err)
+}
+
+func TestGetLogAnalyticsWorkspaceSkuE(t *testing.T) {
+	t.Parallel()
+
+	_, err := GetLogAnalyticsWorkspaceSkuE("", "", "")
+	require.Error(t, err)
+}
+
+func TestGetLogAnalyticsWorkspaceLinkedSolutionsE(t *testing.T) {
+	t.Parallel()
+
+	_, err := GetLogAnalyticsWorkspaceLinkedSolutionsE("", "", "")
+	require.Error(t, err)
+}
+
+func TestGetEnabledIntelligencePackNames(t *testing.T) {
+	t.Parallel()
+
+	enabled := true
+	disabled := false
+	security := "Security"
+	vmInsights := "VMInsights"
+
+	packs := []operationalinsights.IntelligencePack{
+		{Name: &security, Enabled: &enabled},
+		{Name: &vmInsights, Enabled: &disabled},
+	}
+
+	assert.Equal(t, []string{"Security"}, getEnabledIntelligencePackNames(&packs))
+	assert.Empty(t, getEnabledIntelligencePackNames(nil))
+}
@@ -2,6 +2,7 @@ package azure
 
 import (
 	"context"
+	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/profiles/preview/preview/monitor/mgmt/insights"
 	"github.com/gruntwork-io/terratest/modules/testing"
@@ -59,24 +60,138 @@ func GetDiagnosticsSettingsResourceE(name string, resourceURI string, subscripti
 	return &settings, nil
 }
 
-// GetDiagnosticsSettingsClientE returns a diagnostics settings client
-// TODO: delete in next version
-func GetDiagnosticsSettingsClientE(subscriptionID string) (*insights.DiagnosticSettingsClient, error) {
-	// Validate Azure subscription ID
-	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+// GetDiagnosticSettings gets all the diagnostic settings configured for the resource with the given resource ID.
+// This function would fail the test if there is an error.
+func GetDiagnosticSettings(t testing.TestingT, resourceURI string, subscriptionID string) []insights.DiagnosticSettingsResource {
+	settings, err := GetDiagnosticSettingsE(resourceURI, subscriptionID)
+	require.NoError(t, err)
+	return settings
+}
+
+// GetDiagnosticSettingsE gets all the diagnostic settings configured for the resource with the given resource ID.
+func GetDiagnosticSettingsE(resourceURI string, subscriptionID string) ([]insights.DiagnosticSettingsResource, error) {
+	client, err := CreateDiagnosticsSettingsClientE(subscriptionID)
 	if err != nil {
 		return nil, err
 	}
 
-	client := insights.NewDiagnosticSettingsClient(subscriptionID)
-	authorizer, err := NewAuthorizer()
+	collection, err := client.List(context.Background(), resourceURI)
+	if err != nil {
+		return nil, err
+	}
+
+	if collection.Value == nil {
+		return []insights.DiagnosticSettingsResource{}, nil
+	}
+	return *collection.Value, nil
+}
+
+// GetDiagnosticSettingsEnabledCategories gets the names of the log and metric categories that are enabled in the
+// specified diagnostic settings resource.
+// This function would fail the test if there is an error.
+func GetDiagnosticSettingsEnabledCategories(t testing.TestingT, name string, resourceURI string, subscriptionID string) []string {
+	categories, err := GetDiagnosticSettingsEnabledCategoriesE(name, resourceURI, subscriptionID)
+	require.NoError(t, err)
+	return categories
+}
+
+// GetDiagnosticSettingsEnabledCategoriesE gets the names of the log and metric categories that are enabled in the
+// specified diagnostic settings resource.
+func GetDiagnosticSettingsEnabledCategoriesE(name string, resourceURI string, subscriptionID string) ([]string, error) {
+	settings, err := GetDiagnosticsSettingsResourceE(name, resourceURI, subscriptionID)
 	if err != nil {
 		return nil, err
 	}
 
-	client.Authorizer = *authorizer
+	return getDiagnosticSettingsEnabledCategories(settings), nil
+}
 
-	return &client, nil
+// DiagnosticSettingsCategoriesEnabled indicates whether all the given log or metric categories are enabled in the
+// specified diagnostic settings resource.
+// This function would fail the test if there is an error.
+func DiagnosticSettingsCategoriesEnabled(t testing.TestingT, name string, resourceURI string, subscriptionID string, categories ...string) bool {
+	enabled, err := DiagnosticSettingsCategoriesEnabledE(name, resourceURI, subscriptionID, categories...)
+	require.NoError(t, err)
+	return enabled
+}
+
+// DiagnosticSettingsCategoriesEnabledE indicates whether all the given log or metric categories are enabled in the
+// specified diagnostic settings resource.
+func DiagnosticSettingsCategoriesEnabledE(name string, resourceURI string, subscriptionID string, categories ...string) (bool, error) {
+	enabledCategories, err := GetDiagnosticSettingsEnabledCategoriesE(name, resourceURI, subscriptionID)
+	if err != nil {
+		return false, err
+	}
+
+	return containsAllCategories(enabledCategories, categories), nil
+}
+
+// getDiagnosticSettingsEnabledCategories returns the names of the enabled log and metric categories of the given
+// diagnostic settings resource.
+func getDiagnosticSettingsEnabledCategories(settings *insights.DiagnosticSettingsResource) []string {
+	categories := []string{}
+	if settings == nil || settings.DiagnosticSettings == nil {
+		return categories
+	}
+
+	if settings.Logs != nil {
+		for _, log := range *settings.Logs {
+			if safePtrToBool(log.Enabled) {
+				categories = append(categories, safePtrToString(log.Category))
+			}
+		}
+	}
+
+	if settings.Metrics != nil {
+		for _, metric := range *settings.Metrics {
+			if safePtrToBool(metric.Enabled) {
+				categories = append(categories, safePtrToString(metric.Category))
+			}
+		}
+	}
+
+	return categories
+}
+
+// containsAllCategories indicates whether every expected category is present in the list of enabled categories.
+// Category names are compared case-insensitively, as Azure does not guarantee their casing.
+func containsAllCategories(enabledCategories []string, expectedCategories []string) bool {
+	for _, expected := range expectedCategories {
+		found := false
+		for _, enabled := range enabledCategories {
+			if strings.EqualFold(enabled, expected) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// GetDiagnosticsSettingsClientE returns a diagnostics settings client
+// TODO: delete in next version
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
+func GetDiagnosticsSettingsClientE(subscriptionID string) (*insights.DiagnosticSettingsClient, error) {
+	return getCachedClientE("DiagnosticSettingsClient", subscriptionID, func(subscriptionID string) (*insights.DiagnosticSettingsClient, error) {
+		// Validate Azure subscription ID
+		subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
+
+		client := insights.NewDiagnosticSettingsClient(subscriptionID)
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
+
+		client.Authorizer = *authorizer
+
+		return &client, nil
+	})
 }
 
 // GetVMInsightsOnboardingStatus get diagnostics VM onboarding status
@@ -105,23 +220,26 @@ func GetVMInsightsOnboardingStatusE(t testing.TestingT, resourceURI string, subs
 
 // GetVMInsightsClientE gets a VM Insights client
 // TODO: delete in next version
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
 func GetVMInsightsClientE(t testing.TestingT, subscriptionID string) (*insights.VMInsightsClient, error) {
-	// Validate Azure subscription ID
-	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
-	if err != nil {
-		return nil, err
-	}
+	return getCachedClientE("VMInsightsClient", subscriptionID, func(subscriptionID string) (*insights.VMInsightsClient, error) {
+		// Validate Azure subscription ID
+		subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
 
-	client := insights.NewVMInsightsClient(subscriptionID)
+		client := insights.NewVMInsightsClient(subscriptionID)
 
-	authorizer, err := NewAuthorizer()
-	if err != nil {
-		return nil, err
-	}
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
 
-	client.Authorizer = *authorizer
+		client.Authorizer = *authorizer
 
-	return &client, nil
+		return &client, nil
+	})
 }
 
 // GetActivityLogAlertResource gets a Action Group in the specified Azure Resource Group
@@ -158,23 +276,26 @@ func GetActivityLogAlertResourceE(activityLogAlertName string, resGroupName stri
 
 // GetActivityLogAlertsClientE gets an Action Groups client in the specified Azure Subscription
 // TODO: delete in next version
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
 func GetActivityLogAlertsClientE(subscriptionID string) (*insights.ActivityLogAlertsClient, error) {
-	// Validate Azure subscription ID
-	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
-	if err != nil {
-		return nil, err
-	}
+	return getCachedClientE("ActivityLogAlertsClient", subscriptionID, func(subscriptionID string) (*insights.ActivityLogAlertsClient, error) {
+		// Validate Azure subscription ID
+		subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
 
-	// Get the Action Groups client
-	client := insights.NewActivityLogAlertsClient(subscriptionID)
+		// Get the Action Groups client
+		client := insights.NewActivityLogAlertsClient(subscriptionID)
 
-	// Create an authorizer
-	authorizer, err := NewAuthorizer()
-	if err != nil {
-		return nil, err
-	}
+		// Create an authorizer
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
 
-	client.Authorizer = *authorizer
+		client.Authorizer = *authorizer
 
-	return &client, nil
+		return &client, nil
+	})
 }
@@ -6,6 +6,8 @@ package azure
 import (
 	"testing"
 
+	"github.com/Azure/azure-sdk-for-go/profiles/preview/preview/monitor/mgmt/insights"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -19,3 +21,41 @@ func TestDiagnosticsSettingsResourceExists(t *testing.T) {
 	_, err := DiagnosticSettingsResourceExistsE(diagnosticsSettingResourceName, resGroupName, subscriptionID)
 	require.Error(t, err)
 }
+
+func TestGetDiagnosticSettingsE(t *testing.T) {
+	t.Parallel()
+
+	resourceURI := ""
+	subscriptionID := ""
+
+	_, err := GetDiagnosticSettingsE(resourceURI, subscriptionID)
+	require.Error(t, err)
+}
+
+func TestGetDiagnosticSettingsEnabledCategories(t *testing.T) {
+	t.Parallel()
+
+	enabled := true
+	disabled := false
+	auditEvent := "AuditEvent"
+	allMetrics := "AllMetrics"
+	policyEvaluation := "AzurePolicyEvaluationDetails"
+
+	settings := &insights.DiagnosticSettingsResource{
+		DiagnosticSettings: &insights.DiagnosticSettings{
+			Logs: &[]insights.LogSettings{
+				{Category: &auditEvent, Enabled: &enabled},
+				{Category: &policyEvaluation, Enabled: &disabled},
+			},
+			Metrics: &[]insights.MetricSettings{
+				{Category: &allMetrics, Enabled: &enabled},
+			},
+		},
+	}
+
+	categories := getDiagnosticSettingsEnabledCategories(settings)
+	assert.Equal(t, []string{"AuditEvent", "AllMetrics"}, categories)
+	assert.True(t, containsAllCategories(categories, []string{"auditevent", "AllMetrics"}))
+	assert.False(t, containsAllCategories(categories, []string{"AzurePolicyEvaluationDetails"}))
+	assert.Empty(t, getDiagnosticSettingsEnabledCategories(nil))
+}
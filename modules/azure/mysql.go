@@ -10,26 +10,29 @@ import (
 
 // GetMYSQLServerClientE is a helper function that will setup a mysql server client.
 // TODO: remove in next version
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
 func GetMYSQLServerClientE(subscriptionID string) (*mysql.ServersClient, error) {
-	// Validate Azure subscription ID
-	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
-	if err != nil {
-		return nil, err
-	}
-
-	// Create a mysql server client
-	mysqlClient := mysql.NewServersClient(subscriptionID)
-
-	// Create an authorizer
-	authorizer, err := NewAuthorizer()
-	if err != nil {
-		return nil, err
-	}
-
-	// Attach authorizer to the client
-	mysqlClient.Authorizer = *authorizer
-
-	return &mysqlClient, nil
+	return getCachedClientE("MYSQLServerClient", subscriptionID, func(subscriptionID string) (*mysql.ServersClient, error) {
+		// Validate Azure subscription ID
+		subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Create a mysql server client
+		mysqlClient := mysql.NewServersClient(subscriptionID)
+
+		// Create an authorizer
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
+
+		// Attach authorizer to the client
+		mysqlClient.Authorizer = *authorizer
+
+		return &mysqlClient, nil
+	})
 }
 
 // GetMYSQLServer is a helper function that gets the server.
@@ -60,26 +63,29 @@ func GetMYSQLServerE(t testing.TestingT, subscriptionID string, resGroupName str
 }
 
 // GetMYSQLDBClientE is a helper function that will setup a mysql DB client.
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
 func GetMYSQLDBClientE(subscriptionID string) (*mysql.DatabasesClient, error) {
-	// Validate Azure subscription ID
-	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
-	if err != nil {
-		return nil, err
-	}
-
-	// Create a mysql db client
-	mysqlDBClient := mysql.NewDatabasesClient(subscriptionID)
-
-	// Create an authorizer
-	authorizer, err := NewAuthorizer()
-	if err != nil {
-		return nil, err
-	}
-
-	// Attach authorizer to the client
-	mysqlDBClient.Authorizer = *authorizer
-
-	return &mysqlDBClient, nil
+	return getCachedClientE("MYSQLDBClient", subscriptionID, func(subscriptionID string) (*mysql.DatabasesClient, error) {
+		// Validate Azure subscription ID
+		subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Create a mysql db client
+		mysqlDBClient := mysql.NewDatabasesClient(subscriptionID)
+
+		// Create an authorizer
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
+
+		// Attach authorizer to the client
+		mysqlDBClient.Authorizer = *authorizer
+
+		return &mysqlDBClient, nil
+	})
 }
 
 // GetMYSQLDB is a helper function that gets the database.
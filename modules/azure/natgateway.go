@@ -0,0 +1,291 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-09-01/network"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// DefaultEgressIPProbeURL is the URL queried from inside a Virtual Machine to find out the public IP its outbound
+// traffic is sourced from. The service must return the caller IP as plain text.
+const DefaultEgressIPProbeURL = "https://api.ipify.org"
+
+// NatGatewayExists indicates whether the specified NAT Gateway exists.
+// This function would fail the test if there is an error.
+func NatGatewayExists(t testing.TestingT, natGatewayName string, resourceGroupName string, subscriptionID string) bool {
+	exists, err := NatGatewayExistsE(natGatewayName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return exists
+}
+
+// NatGatewayExistsE indicates whether the specified NAT Gateway exists.
+func NatGatewayExistsE(natGatewayName string, resourceGroupName string, subscriptionID string) (bool, error) {
+	_, err := GetNatGatewayE(natGatewayName, resourceGroupName, subscriptionID)
+	if err != nil {
+		if ResourceNotFoundErrorExists(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// GetNatGateway gets the specified NAT Gateway network resource.
+// This function would fail the test if there is an error.
+func GetNatGateway(t testing.TestingT, natGatewayName string, resourceGroupName string, subscriptionID string) *network.NatGateway {
+	natGateway, err := GetNatGatewayE(natGatewayName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return natGateway
+}
+
+// GetNatGatewayE gets the specified NAT Gateway network resource.
+func GetNatGatewayE(natGatewayName string, resourceGroupName string, subscriptionID string) (*network.NatGateway, error) {
+	// Validate Azure Resource Group Name
+	resourceGroupName, err := getTargetAzureResourceGroupName(resourceGroupName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the client reference
+	client, err := GetNatGatewayClientE(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the NAT Gateway
+	natGateway, err := client.Get(context.Background(), resourceGroupName, natGatewayName, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &natGateway, nil
+}
+
+// SubnetHasNatGateway indicates whether the specified subnet is associated with the specified NAT Gateway.
+// This function would fail the test if there is an error.
+func SubnetHasNatGateway(t testing.TestingT, natGatewayName string, subnetName string, vnetName string, resourceGroupName string, subscriptionID string) bool {
+	associated, err := SubnetHasNatGatewayE(natGatewayName, subnetName, vnetName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return associated
+}
+
+// SubnetHasNatGatewayE indicates whether the specified subnet is associated with the specified NAT Gateway.
+func SubnetHasNatGatewayE(natGatewayName string, subnetName string, vnetName string, resourceGroupName string, subscriptionID string) (bool, error) {
+	subnet, err := GetSubnetE(subnetName, vnetName, resourceGroupName, subscriptionID)
+	if err != nil {
+		return false, err
+	}
+
+	if subnet.SubnetPropertiesFormat == nil || subnet.NatGateway == nil {
+		return false, nil
+	}
+	return strings.EqualFold(GetNameFromResourceID(safePtrToString(subnet.NatGateway.ID)), natGatewayName), nil
+}
+
+// GetNatGatewayPublicIPs gets the IP addresses of the Public IP Addresses attached to the specified NAT Gateway.
+// This function would fail the test if there is an error.
+func GetNatGatewayPublicIPs(t testing.TestingT, natGatewayName string, resourceGroupName string, subscriptionID string) []string {
+	ips, err := GetNatGatewayPublicIPsE(natGatewayName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return ips
+}
+
+// GetNatGatewayPublicIPsE gets the IP addresses of the Public IP Addresses attached to the specified NAT Gateway.
+func GetNatGatewayPublicIPsE(natGatewayName string, resourceGroupName string, subscriptionID string) ([]string, error) {
+	natGateway, err := GetNatGatewayE(natGatewayName, resourceGroupName, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	ips := []string{}
+	if natGateway.NatGatewayPropertiesFormat == nil || natGateway.PublicIPAddresses == nil {
+		return ips, nil
+	}
+
+	for _, resource := range *natGateway.PublicIPAddresses {
+		// The Public IP Address can live in a different Resource Group than the NAT Gateway
+		pipID := safePtrToString(resource.ID)
+		pipResourceGroupName, err := GetResourceGroupNameFromResourceIDE(pipID)
+		if err != nil {
+			return nil, err
+		}
+
+		ip, err := GetIPOfPublicIPAddressByNameE(GetNameFromResourceID(pipID), pipResourceGroupName, subscriptionID)
+		if err != nil {
+			return nil, err
+		}
+		ips = append(ips, ip)
+	}
+	return ips, nil
+}
+
+// GetNatGatewayPublicIPPrefixes gets the CIDRs of the Public IP Prefixes attached to the specified NAT Gateway.
+// This function would fail the test if there is an error.
+func GetNatGatewayPublicIPPrefixes(t testing.TestingT, natGatewayName string, resourceGroupName string, subscriptionID string) []string {
+	prefixes, err := GetNatGatewayPublicIPPrefixesE(natGatewayName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return prefixes
+}
+
+// GetNatGatewayPublicIPPrefixesE gets the CIDRs of the Public IP Prefixes attached to the specified NAT Gateway.
+func GetNatGatewayPublicIPPrefixesE(natGatewayName string, resourceGroupName string, subscriptionID string) ([]string, error) {
+	natGateway, err := GetNatGatewayE(natGatewayName, resourceGroupName, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	prefixes := []string{}
+	if natGateway.NatGatewayPropertiesFormat == nil || natGateway.PublicIPPrefixes == nil {
+		return prefixes, nil
+	}
+
+	client, err := GetPublicIPPrefixClientE(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, resource := range *natGateway.PublicIPPrefixes {
+		// The Public IP Prefix can live in a different Resource Group than the NAT Gateway
+		prefixID := safePtrToString(resource.ID)
+		prefixResourceGroupName, err := GetResourceGroupNameFromResourceIDE(prefixID)
+		if err != nil {
+			return nil, err
+		}
+
+		prefix, err := client.Get(context.Background(), prefixResourceGroupName, GetNameFromResourceID(prefixID), "")
+		if err != nil {
+			return nil, err
+		}
+		if prefix.PublicIPPrefixPropertiesFormat == nil || prefix.IPPrefix == nil {
+			return nil, NewNotFoundError("Public IP Prefix allocation", prefixID, prefixResourceGroupName)
+		}
+		prefixes = append(prefixes, *prefix.IPPrefix)
+	}
+	return prefixes, nil
+}
+
+// GetVirtualMachineEgressIP gets the public IP the outbound traffic of the specified Virtual Machine is sourced from,
+// by querying DefaultEgressIPProbeURL from inside the (Linux) Virtual Machine using Run Command.
+// This function would fail the test if there is an error.
+func GetVirtualMachineEgressIP(t testing.TestingT, vmName string, resGroupName string, subscriptionID string) string {
+	ip, err := GetVirtualMachineEgressIPE(vmName, resGroupName, subscriptionID)
+	require.NoError(t, err)
+	return ip
+}
+
+// GetVirtualMachineEgressIPE gets the public IP the outbound traffic of the specified Virtual Machine is sourced from,
+// by querying DefaultEgressIPProbeURL from inside the (Linux) Virtual Machine using Run Command.
+func GetVirtualMachineEgressIPE(vmName string, resGroupName string, subscriptionID string) (string, error) {
+	output, err := RunShellScriptOnVmE(vmName, resGroupName, subscriptionID, fmt.Sprintf("curl -s --max-time 30 %s", DefaultEgressIPProbeURL))
+	if err != nil {
+		return "", err
+	}
+	if output.ExitCode != 0 {
+		return "", fmt.Errorf("egress IP probe on virtual machine %s exited with code %d: %s", vmName, output.ExitCode, output.Stderr)
+	}
+
+	ip := strings.TrimSpace(output.Stdout)
+	if net.ParseIP(ip) == nil {
+		return "", fmt.Errorf("egress IP probe on virtual machine %s returned an invalid IP: %q", vmName, ip)
+	}
+	return ip, nil
+}
+
+// VirtualMachineEgressesThroughNatGateway indicates whether the outbound traffic of the specified Virtual Machine is
+// sourced from one of the Public IP Addresses or Public IP Prefixes of the specified NAT Gateway.
+// This function would fail the test if there is an error.
+func VirtualMachineEgressesThroughNatGateway(t testing.TestingT, vmName string, vmResGroupName string, natGatewayName string, natGatewayResGroupName string, subscriptionID string) bool {
+	egresses, err := VirtualMachineEgressesThroughNatGatewayE(vmName, vmResGroupName, natGatewayName, natGatewayResGroupName, subscriptionID)
+	require.NoError(t, err)
+	return egresses
+}
+
+// VirtualMachineEgressesThroughNatGatewayE indicates whether the outbound traffic of the specified Virtual Machine is
+// sourced from one of the Public IP Addresses or Public IP Prefixes of the specified NAT Gateway.
+func VirtualMachineEgressesThroughNatGatewayE(vmName string, vmResGroupName string, natGatewayName string, natGatewayResGroupName string, subscriptionID string) (bool, error) {
+	ips, err := GetNatGatewayPublicIPsE(natGatewayName, natGatewayResGroupName, subscriptionID)
+	if err != nil {
+		return false, err
+	}
+
+	prefixes, err := GetNatGatewayPublicIPPrefixesE(natGatewayName, natGatewayResGroupName, subscriptionID)
+	if err != nil {
+		return false, err
+	}
+
+	egressIP, err := GetVirtualMachineEgressIPE(vmName, vmResGroupName, subscriptionID)
+	if err != nil {
+		return false, err
+	}
+
+	return isIPInAddressesOrPrefixes(egressIP, ips, prefixes), nil
+}
+
+// GetNatGatewayClientE creates a NAT Gateway client in the specified Azure Subscription.
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
+func GetNatGatewayClientE(subscriptionID string) (*network.NatGatewaysClient, error) {
+	return getCachedClientE("NatGatewayClient", subscriptionID, func(subscriptionID string) (*network.NatGatewaysClient, error) {
+		// Get the NAT Gateway client
+		client, err := CreateNatGatewaysClientE(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Create an authorizer
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
+		client.Authorizer = *authorizer
+
+		return client, nil
+	})
+}
+
+// GetPublicIPPrefixClientE creates a Public IP Prefix client in the specified Azure Subscription.
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
+func GetPublicIPPrefixClientE(subscriptionID string) (*network.PublicIPPrefixesClient, error) {
+	return getCachedClientE("PublicIPPrefixClient", subscriptionID, func(subscriptionID string) (*network.PublicIPPrefixesClient, error) {
+		// Get the Public IP Prefix client
+		client, err := CreatePublicIPPrefixesClientE(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Create an authorizer
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
+		client.Authorizer = *authorizer
+
+		return client, nil
+	})
+}
+
+// isIPInAddressesOrPrefixes indicates whether the given IP is one of the given addresses, or within one of the given
+// CIDR prefixes.
+func isIPInAddressesOrPrefixes(ip string, addresses []string, prefixes []string) bool {
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return false
+	}
+
+	for _, address := range addresses {
+		if parsedIP.Equal(net.ParseIP(address)) {
+			return true
+		}
+	}
+	for _, prefix := range prefixes {
+		_, ipNet, err := net.ParseCIDR(prefix)
+		if err == nil && ipNet.Contains(parsedIP) {
+			return true
+		}
+	}
+	return false
+}
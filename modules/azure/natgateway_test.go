@@ -0,0 +1,47 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/*
+The below tests are currently stubbed out, with the expectation that they will throw errors.
+If/when methods to create and delete NAT Gateway resources are added, these tests can be extended.
+*/
+
+func TestNatGatewayExistsE(t *testing.T) {
+	t.Parallel()
+
+	_, err := NatGatewayExistsE("", "", "")
+	require.Error(t, err)
+}
+
+func TestSubnetHasNatGatewayE(t *testing.T) {
+	t.Parallel()
+
+	_, err := SubnetHasNatGatewayE("", "", "", "", "")
+	require.Error(t, err)
+}
+
+func TestGetNatGatewayPublicIPPrefixesE(t *testing.T) {
+	t.Parallel()
+
+	_, err := GetNatGatewayPublicIPPrefixesE("", "", "")
+	require.Error(t, err)
+}
+
+func TestIsIPInAddressesOrPrefixes(t *testing.T) {
+	t.Parallel()
+
+	addresses := []string{"20.1.2.3"}
+	prefixes := []string{"52.10.20.0/30"}
+
+	assert.True(t, isIPInAddressesOrPrefixes("20.1.2.3", addresses, prefixes))
+	assert.True(t, isIPInAddressesOrPrefixes("52.10.20.2", addresses, prefixes))
+	assert.False(t, isIPInAddressesOrPrefixes("52.10.20.4", addresses, prefixes))
+	assert.False(t, isIPInAddressesOrPrefixes("not-an-ip", addresses, prefixes))
+	assert.False(t, isIPInAddressesOrPrefixes("20.1.2.3", nil, nil))
+}
@@ -117,21 +117,24 @@ func GetNetworkInterfaceConfigurationE(nicName string, nicConfigName string, res
 }
 
 // GetNetworkInterfaceConfigurationClientE creates a new Network Interface Configuration client in the specified Azure Subscription.
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
 func GetNetworkInterfaceConfigurationClientE(subscriptionID string) (*network.InterfaceIPConfigurationsClient, error) {
-	// Create a new client from client factory
-	client, err := CreateNewNetworkInterfaceIPConfigurationClientE(subscriptionID)
-	if err != nil {
-		return nil, err
-	}
+	return getCachedClientE("NetworkInterfaceConfigurationClient", subscriptionID, func(subscriptionID string) (*network.InterfaceIPConfigurationsClient, error) {
+		// Create a new client from client factory
+		client, err := CreateNewNetworkInterfaceIPConfigurationClientE(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
 
-	// Create an authorizer
-	authorizer, err := NewAuthorizer()
-	if err != nil {
-		return nil, err
-	}
-	client.Authorizer = *authorizer
+		// Create an authorizer
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
+		client.Authorizer = *authorizer
 
-	return client, nil
+		return client, nil
+	})
 }
 
 // GetNetworkInterfaceE gets a Network Interface in the specified Azure Resource Group.
@@ -158,19 +161,22 @@ func GetNetworkInterfaceE(nicName string, resGroupName string, subscriptionID st
 }
 
 // GetNetworkInterfaceClientE creates a new Network Interface client in the specified Azure Subscription.
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
 func GetNetworkInterfaceClientE(subscriptionID string) (*network.InterfacesClient, error) {
-	// Create new NIC client from client factory
-	client, err := CreateNewNetworkInterfacesClientE(subscriptionID)
-	if err != nil {
-		return nil, err
-	}
+	return getCachedClientE("NetworkInterfaceClient", subscriptionID, func(subscriptionID string) (*network.InterfacesClient, error) {
+		// Create new NIC client from client factory
+		client, err := CreateNewNetworkInterfacesClientE(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
 
-	// Create an authorizer
-	authorizer, err := NewAuthorizer()
-	if err != nil {
-		return nil, err
-	}
-	client.Authorizer = *authorizer
+		// Create an authorizer
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
+		client.Authorizer = *authorizer
 
-	return client, nil
+		return client, nil
+	})
 }
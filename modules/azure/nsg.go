@@ -49,21 +49,24 @@ func GetDefaultNsgRulesClient(t *testing.T, subscriptionID string) network.Defau
 // GetDefaultNsgRulesClientE returns a rules client which can be used to read the list of *default* security rules
 // defined on an network security group. Note that the "default" rules are those provided implicitly
 // by the Azure platform.
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
 func GetDefaultNsgRulesClientE(subscriptionID string) (network.DefaultSecurityRulesClient, error) {
-	// Get new default client from client factory
-	nsgClient, err := CreateNsgDefaultRulesClientE(subscriptionID)
-	if err != nil {
-		return network.DefaultSecurityRulesClient{}, err
-	}
+	return getCachedClientE("NsgDefaultRulesClient", subscriptionID, func(subscriptionID string) (network.DefaultSecurityRulesClient, error) {
+		// Get new default client from client factory
+		nsgClient, err := CreateNsgDefaultRulesClientE(subscriptionID)
+		if err != nil {
+			return network.DefaultSecurityRulesClient{}, err
+		}
 
-	// Get an authorizer
-	auth, err := NewAuthorizer()
-	if err != nil {
-		return network.DefaultSecurityRulesClient{}, err
-	}
+		// Get an authorizer
+		auth, err := NewAuthorizer()
+		if err != nil {
+			return network.DefaultSecurityRulesClient{}, err
+		}
 
-	nsgClient.Authorizer = *auth
-	return *nsgClient, nil
+		nsgClient.Authorizer = *auth
+		return *nsgClient, nil
+	})
 }
 
 // GetCustomNsgRulesClient returns a rules client which can be used to read the list of *custom* security rules
@@ -79,21 +82,24 @@ func GetCustomNsgRulesClient(t *testing.T, subscriptionID string) network.Securi
 // GetCustomNsgRulesClientE returns a rules client which can be used to read the list of *custom* security rules
 // defined on an network security group. Note that the "custom" rules are those defined by
 // end users.
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
 func GetCustomNsgRulesClientE(subscriptionID string) (network.SecurityRulesClient, error) {
-	// Get new custom rules client from client factory
-	nsgClient, err := CreateNsgCustomRulesClientE(subscriptionID)
-	if err != nil {
-		return network.SecurityRulesClient{}, err
-	}
+	return getCachedClientE("NsgCustomRulesClient", subscriptionID, func(subscriptionID string) (network.SecurityRulesClient, error) {
+		// Get new custom rules client from client factory
+		nsgClient, err := CreateNsgCustomRulesClientE(subscriptionID)
+		if err != nil {
+			return network.SecurityRulesClient{}, err
+		}
 
-	// Get an authorizer
-	auth, err := NewAuthorizer()
-	if err != nil {
-		return network.SecurityRulesClient{}, err
-	}
+		// Get an authorizer
+		auth, err := NewAuthorizer()
+		if err != nil {
+			return network.SecurityRulesClient{}, err
+		}
 
-	nsgClient.Authorizer = *auth
-	return *nsgClient, nil
+		nsgClient.Authorizer = *auth
+		return *nsgClient, nil
+	})
 }
 
 // GetAllNSGRules returns an NsgRuleSummaryList instance containing the combined "default" and "custom" rules from a network
@@ -9,26 +9,29 @@ import (
 )
 
 // GetPostgreSQLServerClientE is a helper function that will setup a postgresql server client.
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
 func GetPostgreSQLServerClientE(subscriptionID string) (*postgresql.ServersClient, error) {
-	// Validate Azure subscription ID
-	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
-	if err != nil {
-		return nil, err
-	}
-
-	// Create a postgresql server client
-	postgresqlClient := postgresql.NewServersClient(subscriptionID)
-
-	// Create an authorizer
-	authorizer, err := NewAuthorizer()
-	if err != nil {
-		return nil, err
-	}
-
-	// Attach authorizer to the client
-	postgresqlClient.Authorizer = *authorizer
-
-	return &postgresqlClient, nil
+	return getCachedClientE("PostgreSQLServerClient", subscriptionID, func(subscriptionID string) (*postgresql.ServersClient, error) {
+		// Validate Azure subscription ID
+		subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Create a postgresql server client
+		postgresqlClient := postgresql.NewServersClient(subscriptionID)
+
+		// Create an authorizer
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
+
+		// Attach authorizer to the client
+		postgresqlClient.Authorizer = *authorizer
+
+		return &postgresqlClient, nil
+	})
 }
 
 // GetPostgreSQLServer is a helper function that gets the server.
@@ -59,26 +62,29 @@ func GetPostgreSQLServerE(t testing.TestingT, subscriptionID string, resGroupNam
 }
 
 // GetPostgreSQLDBClientE is a helper function that will setup a postgresql DB client.
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
 func GetPostgreSQLDBClientE(subscriptionID string) (*postgresql.DatabasesClient, error) {
-	// Validate Azure subscription ID
-	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
-	if err != nil {
-		return nil, err
-	}
-
-	// Create a postgresql db client
-	postgresqlDBClient := postgresql.NewDatabasesClient(subscriptionID)
-
-	// Create an authorizer
-	authorizer, err := NewAuthorizer()
-	if err != nil {
-		return nil, err
-	}
-
-	// Attach authorizer to the client
-	postgresqlDBClient.Authorizer = *authorizer
-
-	return &postgresqlDBClient, nil
+	return getCachedClientE("PostgreSQLDBClient", subscriptionID, func(subscriptionID string) (*postgresql.DatabasesClient, error) {
+		// Validate Azure subscription ID
+		subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Create a postgresql db client
+		postgresqlDBClient := postgresql.NewDatabasesClient(subscriptionID)
+
+		// Create an authorizer
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
+
+		// Attach authorizer to the client
+		postgresqlDBClient.Authorizer = *authorizer
+
+		return &postgresqlDBClient, nil
+	})
 }
 
 // GetPostgreSQLDB is a helper function that gets the database.
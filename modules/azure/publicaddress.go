@@ -96,19 +96,22 @@ func GetPublicIPAddressE(publicIPAddressName string, resGroupName string, subscr
 }
 
 // GetPublicIPAddressClientE creates a Public IP Addresses client in the specified Azure Subscription.
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
 func GetPublicIPAddressClientE(subscriptionID string) (*network.PublicIPAddressesClient, error) {
-	// Get the Public IP Address client from clientfactory
-	client, err := CreatePublicIPAddressesClientE(subscriptionID)
-	if err != nil {
-		return nil, err
-	}
+	return getCachedClientE("PublicIPAddressClient", subscriptionID, func(subscriptionID string) (*network.PublicIPAddressesClient, error) {
+		// Get the Public IP Address client from clientfactory
+		client, err := CreatePublicIPAddressesClientE(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
 
-	// Create an authorizer
-	authorizer, err := NewAuthorizer()
-	if err != nil {
-		return nil, err
-	}
-	client.Authorizer = *authorizer
+		// Create an authorizer
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
+		client.Authorizer = *authorizer
 
-	return client, nil
+		return client, nil
+	})
 }
@@ -0,0 +1,261 @@
+package azure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/services/authorization/mgmt/2015-07-01/authorization"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// ListRoleAssignmentsForScope lists the role assignments that apply at the given scope, including the ones inherited
+// from parent scopes (management groups, subscription, resource group).
+// This function would fail the test if there is an error.
+func ListRoleAssignmentsForScope(t testing.TestingT, scope string, subscriptionID string) []authorization.RoleAssignment {
+	assignments, err := ListRoleAssignmentsForScopeE(scope, subscriptionID)
+	require.NoError(t, err)
+	return assignments
+}
+
+// ListRoleAssignmentsForScopeE lists the role assignments that apply at the given scope, including the ones inherited
+// from parent scopes (management groups, subscription, resource group).
+func ListRoleAssignmentsForScopeE(scope string, subscriptionID string) ([]authorization.RoleAssignment, error) {
+	client, err := CreateRoleAssignmentsClientE(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// atScope() returns the assignments at the scope and above it, but not the ones on child resources
+	iter, err := client.ListForScopeComplete(context.Background(), scope, "atScope()")
+	if err != nil {
+		return nil, err
+	}
+
+	assignments := []authorization.RoleAssignment{}
+	for iter.NotDone() {
+		assignments = append(assignments, iter.Value())
+		if err := iter.NextWithContext(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+	return assignments, nil
+}
+
+// GetRoleDefinitionByName gets the role definition with the given name (e.g. "Reader") that is assignable at the
+// given scope.
+// This function would fail the test if there is an error.
+func GetRoleDefinitionByName(t testing.TestingT, roleName string, scope string, subscriptionID string) *authorization.RoleDefinition {
+	roleDefinition, err := GetRoleDefinitionByNameE(roleName, scope, subscriptionID)
+	require.NoError(t, err)
+	return roleDefinition
+}
+
+// GetRoleDefinitionByNameE gets the role definition with the given name (e.g. "Reader") that is assignable at the
+// given scope.
+func GetRoleDefinitionByNameE(roleName string, scope string, subscriptionID string) (*authorization.RoleDefinition, error) {
+	client, err := CreateRoleDefinitionsClientE(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := fmt.Sprintf("roleName eq '%s'", roleName)
+	iter, err := client.ListComplete(context.Background(), scope, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for iter.NotDone() {
+		roleDefinition := iter.Value()
+		if roleDefinition.RoleDefinitionProperties != nil && strings.EqualFold(safePtrToString(roleDefinition.RoleName), roleName) {
+			return &roleDefinition, nil
+		}
+		if err := iter.NextWithContext(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+	return nil, NewNotFoundError("Role definition", roleName, scope)
+}
+
+// GetPrincipalGroupMemberships gets the IDs of all the Azure AD groups the given principal is a member of, directly or
+// transitively, using Microsoft Graph.
+// This function would fail the test if there is an error.
+func GetPrincipalGroupMemberships(t testing.TestingT, principalID string) []string {
+	groups, err := GetPrincipalGroupMembershipsE(principalID)
+	require.NoError(t, err)
+	return groups
+}
+
+// GetPrincipalGroupMembershipsE gets the IDs of all the Azure AD groups the given principal is a member of, directly
+// or transitively, using Microsoft Graph.
+func GetPrincipalGroupMembershipsE(principalID string) ([]string, error) {
+	graphEndpoint, err := getMicrosoftGraphEndpointE()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := getAccessTokenE(graphEndpoint + "/.default")
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/v1.0/directoryObjects/%s/getMemberGroups", graphEndpoint, principalID)
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(`{"securityEnabledOnly": false}`))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("getting group memberships of principal %s returned status %d: %s", principalID, response.StatusCode, string(body))
+	}
+
+	var result struct {
+		Value []string `json:"value"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+	return result.Value, nil
+}
+
+// PrincipalHasRole indicates whether the given principal has the given role at the given scope, either through a direct
+// assignment, an assignment inherited from a parent scope, or an assignment to a group the principal is a member of.
+// This function would fail the test if there is an error.
+func PrincipalHasRole(t testing.TestingT, principalID string, roleName string, scope string, subscriptionID string) bool {
+	hasRole, err := PrincipalHasRoleE(principalID, roleName, scope, subscriptionID)
+	require.NoError(t, err)
+	return hasRole
+}
+
+// PrincipalHasRoleE indicates whether the given principal has the given role at the given scope, either through a
+// direct assignment, an assignment inherited from a parent scope, or an assignment to a group the principal is a
+// member of.
+func PrincipalHasRoleE(principalID string, roleName string, scope string, subscriptionID string) (bool, error) {
+	roleDefinition, err := GetRoleDefinitionByNameE(roleName, scope, subscriptionID)
+	if err != nil {
+		return false, err
+	}
+
+	assignments, err := ListRoleAssignmentsForScopeE(scope, subscriptionID)
+	if err != nil {
+		return false, err
+	}
+
+	// Try a direct assignment first, so the Microsoft Graph lookup is only needed when the role comes from a group
+	if hasRoleAssignment(assignments, []string{principalID}, safePtrToString(roleDefinition.ID)) {
+		return true, nil
+	}
+
+	groups, err := GetPrincipalGroupMembershipsE(principalID)
+	if err != nil {
+		return false, err
+	}
+	return hasRoleAssignment(assignments, groups, safePtrToString(roleDefinition.ID)), nil
+}
+
+// AssertPrincipalHasRole checks that the given principal has the given role at the given scope, either directly or
+// through group membership, and fails the test if it does not.
+func AssertPrincipalHasRole(t testing.TestingT, principalID string, roleName string, scope string, subscriptionID string) {
+	err := AssertPrincipalHasRoleE(principalID, roleName, scope, subscriptionID)
+	require.NoError(t, err)
+}
+
+// AssertPrincipalHasRoleE checks that the given principal has the given role at the given scope, either directly or
+// through group membership, and returns an error if it does not.
+func AssertPrincipalHasRoleE(principalID string, roleName string, scope string, subscriptionID string) error {
+	hasRole, err := PrincipalHasRoleE(principalID, roleName, scope, subscriptionID)
+	if err != nil {
+		return err
+	}
+	if !hasRole {
+		return fmt.Errorf("principal %s does not have role %s at scope %s", principalID, roleName, scope)
+	}
+	return nil
+}
+
+// hasRoleAssignment indicates whether any of the given assignments grants the role definition with the given ID to any
+// of the given principals.
+func hasRoleAssignment(assignments []authorization.RoleAssignment, principalIDs []string, roleDefinitionID string) bool {
+	roleDefinitionName := getRoleDefinitionName(roleDefinitionID)
+
+	for _, assignment := range assignments {
+		if assignment.Properties == nil {
+			continue
+		}
+		// Assignments reference the role definition through a scope specific ID, so only compare the definition GUID
+		if !strings.EqualFold(getRoleDefinitionName(safePtrToString(assignment.Properties.RoleDefinitionID)), roleDefinitionName) {
+			continue
+		}
+		for _, principalID := range principalIDs {
+			if strings.EqualFold(safePtrToString(assignment.Properties.PrincipalID), principalID) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getRoleDefinitionName returns the last segment (the role definition GUID) of a role definition ID.
+func getRoleDefinitionName(roleDefinitionID string) string {
+	return roleDefinitionID[strings.LastIndex(roleDefinitionID, "/")+1:]
+}
+
+// getMicrosoftGraphEndpointE returns the Microsoft Graph endpoint for the configured Azure environment.
+func getMicrosoftGraphEndpointE() (string, error) {
+	envName := getDefaultEnvironmentName()
+	switch strings.ToUpper(envName) {
+	case "AZUREPUBLICCLOUD":
+		return "https://graph.microsoft.com", nil
+	case "AZURECHINACLOUD":
+		return "https://microsoftgraph.chinacloudapi.cn", nil
+	case "AZUREUSGOVERNMENTCLOUD":
+		return "https://graph.microsoft.us", nil
+	default:
+		return "", fmt.Errorf("microsoft Graph is not supported in the cloud environment: %s", envName)
+	}
+}
+
+// getAccessTokenE gets an access token for the given scope using the default Azure credential chain.
+func getAccessTokenE(scope string) (string, error) {
+	clientCloudConfig, err := getClientCloudConfig()
+	if err != nil {
+		return "", err
+	}
+
+	cred, err := azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{
+		ClientOptions: azcore.ClientOptions{
+			Cloud: clientCloudConfig,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	token, err := cred.GetToken(context.Background(), policy.TokenRequestOptions{
+		Scopes: []string{scope},
+	})
+	if err != nil {
+		return "", err
+	}
+	return token.Token, nil
+}
@@ -0,0 +1,49 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/authorization/mgmt/2015-07-01/authorization"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/*
+The below tests are currently stubbed out, with the expectation that they will throw errors.
+If/when methods to create and delete role assignments are added, these tests can be extended.
+*/
+
+func TestListRoleAssignmentsForScopeE(t *testing.T) {
+	t.Parallel()
+
+	_, err := ListRoleAssignmentsForScopeE("", "")
+	require.Error(t, err)
+}
+
+func TestAssertPrincipalHasRoleE(t *testing.T) {
+	t.Parallel()
+
+	err := AssertPrincipalHasRoleE("", "Reader", "", "")
+	require.Error(t, err)
+}
+
+func TestHasRoleAssignment(t *testing.T) {
+	t.Parallel()
+
+	readerID := "/providers/Microsoft.Authorization/roleDefinitions/acdd72a7-3385-48ef-bd42-f606fba81ae7"
+	scopedReaderID := "/subscriptions/sub/providers/Microsoft.Authorization/roleDefinitions/ACDD72A7-3385-48EF-BD42-F606FBA81AE7"
+	contributorID := "/subscriptions/sub/providers/Microsoft.Authorization/roleDefinitions/b24988ac-6180-42a0-ab88-20f7382dd24c"
+	groupID := "11111111-1111-1111-1111-111111111111"
+	userID := "22222222-2222-2222-2222-222222222222"
+
+	assignments := []authorization.RoleAssignment{
+		{Properties: &authorization.RoleAssignmentPropertiesWithScope{RoleDefinitionID: &scopedReaderID, PrincipalID: &groupID}},
+		{Properties: &authorization.RoleAssignmentPropertiesWithScope{RoleDefinitionID: &contributorID, PrincipalID: &userID}},
+		{},
+	}
+
+	assert.True(t, hasRoleAssignment(assignments, []string{groupID}, readerID))
+	assert.False(t, hasRoleAssignment(assignments, []string{userID}, readerID))
+	assert.True(t, hasRoleAssignment(assignments, []string{userID}, contributorID))
+	assert.False(t, hasRoleAssignment(assignments, nil, readerID))
+}
@@ -3,10 +3,13 @@ package azure
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/services/recoveryservices/mgmt/2016-06-01/recoveryservices"
 	"github.com/Azure/azure-sdk-for-go/services/recoveryservices/mgmt/2020-02-02/backup"
+	"github.com/gruntwork-io/terratest/modules/retry"
 	"github.com/stretchr/testify/require"
 )
 
@@ -149,3 +152,188 @@ func GetRecoveryServicesVaultBackupProtectedVMListE(policyName, vaultName, resou
 	}
 	return vmList, nil
 }
+
+// RecoveryServicesVaultBackupPolicyExists indicates whether a backup policy exists on the given vault; otherwise false.
+// This function would fail the test if there is an error.
+func RecoveryServicesVaultBackupPolicyExists(t *testing.T, policyName, vaultName, resourceGroupName, subscriptionID string) bool {
+	exists, err := RecoveryServicesVaultBackupPolicyExistsE(policyName, vaultName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return exists
+}
+
+// GetRecoveryServicesVaultBackupPolicy returns the given backup policy of the given vault.
+// This function would fail the test if there is an error.
+func GetRecoveryServicesVaultBackupPolicy(t *testing.T, policyName, vaultName, resourceGroupName, subscriptionID string) *backup.ProtectionPolicyResource {
+	policy, err := GetRecoveryServicesVaultBackupPolicyE(policyName, vaultName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return policy
+}
+
+// GetRecoveryServicesVaultProtectedItemList returns all the items protected by the given vault, keyed by the resource
+// ID of the protected resource (e.g. the VM), or by the ID of the protected item if it has none.
+// This function would fail the test if there is an error.
+func GetRecoveryServicesVaultProtectedItemList(t *testing.T, vaultName, resourceGroupName, subscriptionID string) map[string]backup.ProtectedItemResource {
+	list, err := GetRecoveryServicesVaultProtectedItemListE(vaultName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return list
+}
+
+// VirtualMachineBackupConfigured indicates whether the VM with the given resource ID is enrolled into backup on the
+// given vault; otherwise false.
+// This function would fail the test if there is an error.
+func VirtualMachineBackupConfigured(t *testing.T, vmID, vaultName, resourceGroupName, subscriptionID string) bool {
+	configured, err := VirtualMachineBackupConfiguredE(vmID, vaultName, resourceGroupName, subscriptionID)
+	require.NoError(t, err)
+	return configured
+}
+
+// WaitForBackupConfigured waits until the VM with the given resource ID is enrolled into backup on the given vault.
+// This function would fail the test if the VM is not enrolled after the given number of retries.
+func WaitForBackupConfigured(t *testing.T, vmID, vaultName, resourceGroupName, subscriptionID string, maxRetries int, sleepBetweenRetries time.Duration) {
+	err := WaitForBackupConfiguredE(t, vmID, vaultName, resourceGroupName, subscriptionID, maxRetries, sleepBetweenRetries)
+	require.NoError(t, err)
+}
+
+// RecoveryServicesVaultBackupPolicyExistsE indicates whether a backup policy exists on the given vault; otherwise false or error.
+func RecoveryServicesVaultBackupPolicyExistsE(policyName, vaultName, resourceGroupName, subscriptionID string) (bool, error) {
+	_, err := GetRecoveryServicesVaultBackupPolicyE(policyName, vaultName, resourceGroupName, subscriptionID)
+	if err != nil {
+		if ResourceNotFoundErrorExists(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// GetRecoveryServicesVaultBackupPolicyE returns the given backup policy of the given vault.
+func GetRecoveryServicesVaultBackupPolicyE(policyName, vaultName, resourceGroupName, subscriptionID string) (*backup.ProtectionPolicyResource, error) {
+	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceGroupName, err = getTargetAzureResourceGroupName(resourceGroupName)
+	if err != nil {
+		return nil, err
+	}
+
+	client := backup.NewProtectionPoliciesClient(subscriptionID)
+	// setup authorizer
+	authorizer, err := NewAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+
+	client.Authorizer = *authorizer
+	policy, err := client.Get(context.Background(), vaultName, resourceGroupName, policyName)
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// GetRecoveryServicesVaultProtectedItemListE returns all the items protected by the given vault, keyed by the resource
+// ID of the protected resource (e.g. the VM), or by the ID of the protected item if it has none. Unlike friendly
+// names, the IDs of VMs of the same name in different resource groups differ.
+func GetRecoveryServicesVaultProtectedItemListE(vaultName, resourceGroupName, subscriptionID string) (map[string]backup.ProtectedItemResource, error) {
+	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceGroupName, err = getTargetAzureResourceGroupName(resourceGroupName)
+	if err != nil {
+		return nil, err
+	}
+
+	client := backup.NewProtectedItemsGroupClient(subscriptionID)
+	// setup authorizer
+	authorizer, err := NewAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+	client.Authorizer = *authorizer
+	listIter, err := client.ListComplete(context.Background(), vaultName, resourceGroupName, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	itemList := make(map[string]backup.ProtectedItemResource)
+	for listIter.NotDone() {
+		item := listIter.Value()
+		itemList[getProtectedItemKey(item)] = item
+		err := listIter.NextWithContext(context.Background())
+		if err != nil {
+			return nil, err
+		}
+	}
+	return itemList, nil
+}
+
+// VirtualMachineBackupConfiguredE indicates whether the VM with the given resource ID is enrolled into backup on the
+// given vault; otherwise false or error. A VM counts as enrolled once it is protected or pending its initial backup.
+func VirtualMachineBackupConfiguredE(vmID, vaultName, resourceGroupName, subscriptionID string) (bool, error) {
+	items, err := GetRecoveryServicesVaultProtectedItemListE(vaultName, resourceGroupName, subscriptionID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, item := range items {
+		if isVirtualMachineBackupConfigured(item, vmID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// WaitForBackupConfiguredE waits until the VM with the given resource ID is enrolled into backup on the given vault.
+// Backup enrollment through policy assignments or modules can take several minutes after the VM is created.
+func WaitForBackupConfiguredE(t *testing.T, vmID, vaultName, resourceGroupName, subscriptionID string, maxRetries int, sleepBetweenRetries time.Duration) error {
+	description := fmt.Sprintf("Waiting for VM %s to be enrolled into backup on vault %s", vmID, vaultName)
+	_, err := retry.DoWithRetryE(t, description, maxRetries, sleepBetweenRetries, func() (string, error) {
+		configured, err := VirtualMachineBackupConfiguredE(vmID, vaultName, resourceGroupName, subscriptionID)
+		if err != nil {
+			return "", err
+		}
+		if !configured {
+			return "", NewNotFoundError("Backup protected item", vmID, vaultName)
+		}
+		return "VM backup configured", nil
+	})
+	return err
+}
+
+// getProtectedItemKey returns the resource ID of the resource protected by a protected item, falling back to the ID of
+// the protected item.
+func getProtectedItemKey(item backup.ProtectedItemResource) string {
+	if item.Properties != nil {
+		if vm, ok := item.Properties.AsAzureIaaSComputeVMProtectedItem(); ok && vm.SourceResourceID != nil {
+			return *vm.SourceResourceID
+		}
+		if vm, ok := item.Properties.AsAzureIaaSVMProtectedItem(); ok && vm.SourceResourceID != nil {
+			return *vm.SourceResourceID
+		}
+	}
+	return safePtrToString(item.ID)
+}
+
+// isVirtualMachineBackupConfigured indicates whether the protected item backs up the VM with the given resource ID and
+// is protected or pending its initial backup.
+func isVirtualMachineBackupConfigured(item backup.ProtectedItemResource, vmID string) bool {
+	if item.Properties == nil {
+		return false
+	}
+
+	vm, ok := item.Properties.AsAzureIaaSComputeVMProtectedItem()
+	if !ok || vm == nil {
+		return false
+	}
+
+	// Resource IDs are case insensitive in Azure
+	if !strings.EqualFold(safePtrToString(vm.SourceResourceID), vmID) {
+		return false
+	}
+
+	return vm.ProtectionState == backup.ProtectionStateProtected || vm.ProtectionState == backup.ProtectionStateIRPending
+}
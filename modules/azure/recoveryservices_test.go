@@ -3,6 +3,8 @@ package azure
 import (
 	"testing"
 
+	"github.com/Azure/azure-sdk-for-go/services/recoveryservices/mgmt/2020-02-02/backup"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -30,3 +32,59 @@ func TestRecoveryServicesVaultBackupProtectedVMList(t *testing.T) {
 	_, err := GetRecoveryServicesVaultBackupProtectedVMListE("", "", "", "")
 	require.Error(t, err, "Backup policy protected vm list not faulted")
 }
+
+func TestRecoveryServicesVaultBackupPolicy(t *testing.T) {
+	_, err := GetRecoveryServicesVaultBackupPolicyE("", "", "", "")
+	require.Error(t, err, "Backup policy not faulted")
+}
+
+func TestRecoveryServicesVaultProtectedItemList(t *testing.T) {
+	_, err := GetRecoveryServicesVaultProtectedItemListE("", "", "")
+	require.Error(t, err, "Protected item list not faulted")
+}
+
+func TestVirtualMachineBackupConfigured(t *testing.T) {
+	_, err := VirtualMachineBackupConfiguredE("", "", "", "")
+	require.Error(t, err, "VM backup configured not faulted")
+}
+
+func TestIsVirtualMachineBackupConfigured(t *testing.T) {
+	vmID := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm"
+	upperVMID := "/subscriptions/sub/resourceGroups/RG/providers/Microsoft.Compute/virtualMachines/VM"
+	friendlyName := "vm"
+
+	pending := backup.ProtectedItemResource{
+		Properties: &backup.AzureIaaSComputeVMProtectedItem{
+			FriendlyName:     &friendlyName,
+			SourceResourceID: &upperVMID,
+			ProtectionState:  backup.ProtectionStateIRPending,
+		},
+	}
+	stopped := backup.ProtectedItemResource{
+		Properties: &backup.AzureIaaSComputeVMProtectedItem{
+			SourceResourceID: &vmID,
+			ProtectionState:  backup.ProtectionStateProtectionStopped,
+		},
+	}
+
+	assert.True(t, isVirtualMachineBackupConfigured(pending, vmID))
+	assert.False(t, isVirtualMachineBackupConfigured(pending, "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/other"))
+	assert.False(t, isVirtualMachineBackupConfigured(stopped, vmID))
+	assert.False(t, isVirtualMachineBackupConfigured(backup.ProtectedItemResource{}, vmID))
+}
+
+func TestGetProtectedItemKey(t *testing.T) {
+	vmID := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm"
+	otherVMID := "/subscriptions/sub/resourceGroups/other-rg/providers/Microsoft.Compute/virtualMachines/vm"
+	itemID := "/subscriptions/sub/resourceGroups/vault-rg/providers/Microsoft.RecoveryServices/vaults/vault/backupFabrics/Azure/protectionContainers/container/protectedItems/item"
+	friendlyName := "vm"
+
+	// VMs with the same name in different resource groups get different keys
+	vm := backup.ProtectedItemResource{Properties: &backup.AzureIaaSComputeVMProtectedItem{FriendlyName: &friendlyName, SourceResourceID: &vmID}}
+	otherVM := backup.ProtectedItemResource{Properties: &backup.AzureIaaSVMProtectedItem{FriendlyName: &friendlyName, SourceResourceID: &otherVMID}}
+	assert.Equal(t, vmID, getProtectedItemKey(vm))
+	assert.Equal(t, otherVMID, getProtectedItemKey(otherVM))
+
+	assert.Equal(t, itemID, getProtectedItemKey(backup.ProtectedItemResource{ID: &itemID, Properties: &backup.AzureIaaSComputeVMProtectedItem{FriendlyName: &friendlyName}}))
+	assert.Equal(t, itemID, getProtectedItemKey(backup.ProtectedItemResource{ID: &itemID}))
+}
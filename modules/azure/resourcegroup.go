@@ -45,18 +45,21 @@ func GetResourceGroupE(resourceGroupName, subscriptionID string) (bool, error) {
 
 // GetResourceGroupClientE gets a resource group client in a subscription
 // TODO: remove in next version
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
 func GetResourceGroupClientE(subscriptionID string) (*resources.GroupsClient, error) {
-	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
-	if err != nil {
-		return nil, err
-	}
-	resourceGroupClient := resources.NewGroupsClient(subscriptionID)
-	authorizer, err := NewAuthorizer()
-	if err != nil {
-		return nil, err
-	}
-	resourceGroupClient.Authorizer = *authorizer
-	return &resourceGroupClient, nil
+	return getCachedClientE("ResourceGroupClient", subscriptionID, func(subscriptionID string) (*resources.GroupsClient, error) {
+		subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
+		resourceGroupClient := resources.NewGroupsClient(subscriptionID)
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
+		resourceGroupClient.Authorizer = *authorizer
+		return &resourceGroupClient, nil
+	})
 }
 
 // GetAResourceGroup returns a resource group within a subscription
@@ -1,6 +1,10 @@
 package azure
 
-import "github.com/gruntwork-io/terratest/modules/collections"
+import (
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/collections"
+)
 
 // GetNameFromResourceID gets the Name from an Azure Resource ID.
 func GetNameFromResourceID(resourceID string) string {
@@ -20,3 +24,24 @@ func GetNameFromResourceIDE(resourceID string) (string, error) {
 	}
 	return id, nil
 }
+
+// GetResourceGroupNameFromResourceID gets the Resource Group name from an Azure Resource ID.
+func GetResourceGroupNameFromResourceID(resourceID string) string {
+	name, err := GetResourceGroupNameFromResourceIDE(resourceID)
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
+// GetResourceGroupNameFromResourceIDE gets the Resource Group name from an Azure Resource ID.
+func GetResourceGroupNameFromResourceIDE(resourceID string) (string, error) {
+	segments := strings.Split(resourceID, "/")
+	for i := 0; i < len(segments)-1; i++ {
+		// Azure does not guarantee the casing of the resourceGroups segment
+		if strings.EqualFold(segments[i], "resourceGroups") && segments[i+1] != "" {
+			return segments[i+1], nil
+		}
+	}
+	return "", NewFailedToParseError("Resource ID", resourceID)
+}
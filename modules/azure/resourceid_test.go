@@ -28,3 +28,16 @@ func TestGetNameFromResourceID(t *testing.T) {
 	resultBadSeperator := GetNameFromResourceID(sliceNotFound)
 	assert.Equal(t, "", resultBadSeperator)
 }
+
+func TestGetResourceGroupNameFromResourceID(t *testing.T) {
+	t.Parallel()
+
+	resourceID := "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/my-rg/providers/Microsoft.Network/virtualNetworks/my-vnet"
+	assert.Equal(t, "my-rg", GetResourceGroupNameFromResourceID(resourceID))
+
+	lowerCaseID := "/subscriptions/00000000-0000-0000-0000-000000000000/resourcegroups/MY-RG/providers/Microsoft.Compute/disks/disk"
+	assert.Equal(t, "MY-RG", GetResourceGroupNameFromResourceID(lowerCaseID))
+
+	_, err := GetResourceGroupNameFromResourceIDE("/subscriptions/00000000-0000-0000-0000-000000000000")
+	assert.Error(t, err)
+}
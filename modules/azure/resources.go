@@ -0,0 +1,191 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// ResourceSummary is a typed summary of a generic Azure resource, as returned by the resource listing helpers.
+type ResourceSummary struct {
+	ID                string
+	Name              string
+	Type              string
+	Location          string
+	ResourceGroupName string
+	Tags              map[string]string
+}
+
+// ResourceFilter narrows down the resources returned by the resource listing helpers. Empty fields are ignored.
+type ResourceFilter struct {
+	// ResourceType is the full resource type to match, e.g. Microsoft.Network/virtualNetworks.
+	ResourceType string
+
+	// TagName is the name of a tag the resources must have.
+	TagName string
+
+	// TagValue is the value the TagName tag must have. It is ignored if TagName is empty.
+	TagValue string
+}
+
+// ListResourcesInGroup lists all the resources in the given resource group that match the optional filter,
+// following all pages of results.
+// This function would fail the test if there is an error.
+func ListResourcesInGroup(t testing.TestingT, resourceGroupName string, subscriptionID string, filter *ResourceFilter) []ResourceSummary {
+	resources, err := ListResourcesInGroupE(resourceGroupName, subscriptionID, filter)
+	require.NoError(t, err)
+	return resources
+}
+
+// ListResourcesInGroupE lists all the resources in the given resource group that match the optional filter,
+// following all pages of results.
+func ListResourcesInGroupE(resourceGroupName string, subscriptionID string, filter *ResourceFilter) ([]ResourceSummary, error) {
+	resourceGroupName, err := getTargetAzureResourceGroupName(resourceGroupName)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := CreateResourcesClientV2E(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	pager := client.NewListByResourceGroupPager(resourceGroupName, &armresources.ClientListByResourceGroupOptions{
+		Filter: filter.odataFilter(),
+	})
+
+	resources := []ResourceSummary{}
+	ctx := context.Background()
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, filterResourceSummaries(page.Value, filter)...)
+	}
+	return resources, nil
+}
+
+// ListResourcesByTag lists all the resources in the subscription that have the given tag, following all pages of
+// results. Pass an empty tagValue to match any value of the tag.
+// This function would fail the test if there is an error.
+func ListResourcesByTag(t testing.TestingT, tagName string, tagValue string, subscriptionID string) []ResourceSummary {
+	resources, err := ListResourcesByTagE(tagName, tagValue, subscriptionID)
+	require.NoError(t, err)
+	return resources
+}
+
+// ListResourcesByTagE lists all the resources in the subscription that have the given tag, following all pages of
+// results. Pass an empty tagValue to match any value of the tag.
+func ListResourcesByTagE(tagName string, tagValue string, subscriptionID string) ([]ResourceSummary, error) {
+	return ListResourcesE(subscriptionID, &ResourceFilter{TagName: tagName, TagValue: tagValue})
+}
+
+// ListResources lists all the resources in the subscription that match the optional filter, following all pages of
+// results.
+// This function would fail the test if there is an error.
+func ListResources(t testing.TestingT, subscriptionID string, filter *ResourceFilter) []ResourceSummary {
+	resources, err := ListResourcesE(subscriptionID, filter)
+	require.NoError(t, err)
+	return resources
+}
+
+// ListResourcesE lists all the resources in the subscription that match the optional filter, following all pages of
+// results.
+func ListResourcesE(subscriptionID string, filter *ResourceFilter) ([]ResourceSummary, error) {
+	client, err := CreateResourcesClientV2E(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	pager := client.NewListPager(&armresources.ClientListOptions{
+		Filter: filter.odataFilter(),
+	})
+
+	resources := []ResourceSummary{}
+	ctx := context.Background()
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, filterResourceSummaries(page.Value, filter)...)
+	}
+	return resources, nil
+}
+
+// odataFilter returns the $filter expression to send to the Resources API. The API does not allow combining a tag
+// filter with other filters, so only the most selective criteria is sent and the rest is applied client side.
+func (filter *ResourceFilter) odataFilter() *string {
+	if filter == nil {
+		return nil
+	}
+
+	var expression string
+	switch {
+	case filter.TagName != "" && filter.TagValue != "":
+		expression = fmt.Sprintf("tagName eq '%s' and tagValue eq '%s'", filter.TagName, filter.TagValue)
+	case filter.TagName != "":
+		expression = fmt.Sprintf("tagName eq '%s'", filter.TagName)
+	case filter.ResourceType != "":
+		expression = fmt.Sprintf("resourceType eq '%s'", filter.ResourceType)
+	default:
+		return nil
+	}
+	return &expression
+}
+
+// matches indicates whether the given resource summary satisfies all the criteria of the filter.
+func (filter *ResourceFilter) matches(resource ResourceSummary) bool {
+	if filter == nil {
+		return true
+	}
+	// Resource types are case insensitive in Azure
+	if filter.ResourceType != "" && !strings.EqualFold(resource.Type, filter.ResourceType) {
+		return false
+	}
+	if filter.TagName != "" {
+		value, exists := resource.Tags[filter.TagName]
+		if !exists || (filter.TagValue != "" && value != filter.TagValue) {
+			return false
+		}
+	}
+	return true
+}
+
+// filterResourceSummaries converts the given generic resources to summaries, keeping only the ones matching the filter.
+func filterResourceSummaries(resources []*armresources.GenericResourceExpanded, filter *ResourceFilter) []ResourceSummary {
+	summaries := []ResourceSummary{}
+	for _, resource := range resources {
+		if resource == nil {
+			continue
+		}
+		summary := newResourceSummary(resource)
+		if filter.matches(summary) {
+			summaries = append(summaries, summary)
+		}
+	}
+	return summaries
+}
+
+// newResourceSummary converts a generic resource to a ResourceSummary.
+func newResourceSummary(resource *armresources.GenericResourceExpanded) ResourceSummary {
+	summary := ResourceSummary{
+		ID:       safePtrToString(resource.ID),
+		Name:     safePtrToString(resource.Name),
+		Type:     safePtrToString(resource.Type),
+		Location: safePtrToString(resource.Location),
+		Tags:     map[string]string{},
+	}
+
+	for key, value := range resource.Tags {
+		summary.Tags[key] = safePtrToString(value)
+	}
+
+	summary.ResourceGroupName = GetResourceGroupNameFromResourceID(summary.ID)
+	return summary
+}
@@ -0,0 +1,76 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/*
+The below tests are currently stubbed out, with the expectation that they will throw errors.
+If/when methods to create and delete resources are added, these tests can be extended.
+*/
+
+func TestListResourcesInGroupE(t *testing.T) {
+	t.Parallel()
+
+	_, err := ListResourcesInGroupE("", "", nil)
+	require.Error(t, err)
+}
+
+func TestListResourcesByTagE(t *testing.T) {
+	t.Parallel()
+
+	_, err := ListResourcesByTagE("environment", "test", "")
+	require.Error(t, err)
+}
+
+func TestResourceFilterODataFilter(t *testing.T) {
+	t.Parallel()
+
+	var nilFilter *ResourceFilter
+	assert.Nil(t, nilFilter.odataFilter())
+	assert.Nil(t, (&ResourceFilter{}).odataFilter())
+	assert.Equal(t, "tagName eq 'env' and tagValue eq 'test'", *(&ResourceFilter{TagName: "env", TagValue: "test", ResourceType: "Microsoft.Compute/disks"}).odataFilter())
+	assert.Equal(t, "tagName eq 'env'", *(&ResourceFilter{TagName: "env"}).odataFilter())
+	assert.Equal(t, "resourceType eq 'Microsoft.Compute/disks'", *(&ResourceFilter{ResourceType: "Microsoft.Compute/disks"}).odataFilter())
+}
+
+func TestFilterResourceSummaries(t *testing.T) {
+	t.Parallel()
+
+	diskID := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/disks/disk"
+	diskName := "disk"
+	diskType := "Microsoft.Compute/disks"
+	vnetID := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet"
+	vnetName := "vnet"
+	vnetType := "Microsoft.Network/virtualNetworks"
+	location := "westeurope"
+	env := "test"
+
+	resources := []*armresources.GenericResourceExpanded{
+		{ID: &diskID, Name: &diskName, Type: &diskType, Location: &location, Tags: map[string]*string{"env": &env}},
+		{ID: &vnetID, Name: &vnetName, Type: &vnetType, Location: &location},
+		nil,
+	}
+
+	all := filterResourceSummaries(resources, nil)
+	require.Len(t, all, 2)
+	assert.Equal(t, ResourceSummary{
+		ID:                diskID,
+		Name:              "disk",
+		Type:              "Microsoft.Compute/disks",
+		Location:          "westeurope",
+		ResourceGroupName: "rg",
+		Tags:              map[string]string{"env": "test"},
+	}, all[0])
+
+	byType := filterResourceSummaries(resources, &ResourceFilter{ResourceType: "microsoft.network/virtualnetworks"})
+	require.Len(t, byType, 1)
+	assert.Equal(t, "vnet", byType[0].Name)
+
+	byTag := filterResourceSummaries(resources, &ResourceFilter{TagName: "env", TagValue: "prod"})
+	assert.Empty(t, byTag)
+}
@@ -0,0 +1,151 @@
+package azure
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// RunShellScriptCommandID is the Run Command ID used to execute a shell script on a Linux Virtual Machine.
+	RunShellScriptCommandID = "RunShellScript"
+
+	// RunPowerShellScriptCommandID is the Run Command ID used to execute a PowerShell script on a Windows Virtual Machine.
+	RunPowerShellScriptCommandID = "RunPowerShellScript"
+)
+
+// RunCommandOutput contains the result of a command executed through the Azure VM Run Command API.
+type RunCommandOutput struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// RunCommandOnVm runs the given script on the specified Azure Virtual Machine through the VM Run Command API. This
+// works for Virtual Machines with no public IP address, as the command is delivered by the VM agent.
+// This function would fail the test if there is an error.
+func RunCommandOnVm(t testing.TestingT, vmName string, resGroupName string, subscriptionID string, commandID string, script ...string) *RunCommandOutput {
+	output, err := RunCommandOnVmE(vmName, resGroupName, subscriptionID, commandID, script...)
+	require.NoError(t, err)
+	return output
+}
+
+// RunCommandOnVmE runs the given script on the specified Azure Virtual Machine through the VM Run Command API and
+// returns its stdout, stderr and exit code. The commandID is one of RunShellScriptCommandID or
+// RunPowerShellScriptCommandID, or any other Run Command ID supported by the Virtual Machine.
+func RunCommandOnVmE(vmName string, resGroupName string, subscriptionID string, commandID string, script ...string) (*RunCommandOutput, error) {
+	// Validate resource group name and subscription ID
+	resGroupName, err := getTargetAzureResourceGroupName(resGroupName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the client reference
+	client, err := GetVirtualMachineClientE(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	input := compute.RunCommandInput{
+		CommandID: &commandID,
+		Script:    &script,
+	}
+
+	ctx := context.Background()
+	future, err := client.RunCommand(ctx, resGroupName, vmName, input)
+	if err != nil {
+		return nil, err
+	}
+
+	// Run Command is a long running operation, so wait for the VM agent to report back
+	if err := future.WaitForCompletionRef(ctx, client.Client); err != nil {
+		return nil, err
+	}
+
+	result, err := future.Result(*client)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseRunCommandResult(result), nil
+}
+
+// RunShellScriptOnVm runs the given shell script on the specified Azure Linux Virtual Machine.
+// This function would fail the test if there is an error.
+func RunShellScriptOnVm(t testing.TestingT, vmName string, resGroupName string, subscriptionID string, script ...string) *RunCommandOutput {
+	return RunCommandOnVm(t, vmName, resGroupName, subscriptionID, RunShellScriptCommandID, script...)
+}
+
+// RunShellScriptOnVmE runs the given shell script on the specified Azure Linux Virtual Machine.
+func RunShellScriptOnVmE(vmName string, resGroupName string, subscriptionID string, script ...string) (*RunCommandOutput, error) {
+	return RunCommandOnVmE(vmName, resGroupName, subscriptionID, RunShellScriptCommandID, script...)
+}
+
+// RunPowerShellScriptOnVm runs the given PowerShell script on the specified Azure Windows Virtual Machine.
+// This function would fail the test if there is an error.
+func RunPowerShellScriptOnVm(t testing.TestingT, vmName string, resGroupName string, subscriptionID string, script ...string) *RunCommandOutput {
+	return RunCommandOnVm(t, vmName, resGroupName, subscriptionID, RunPowerShellScriptCommandID, script...)
+}
+
+// RunPowerShellScriptOnVmE runs the given PowerShell script on the specified Azure Windows Virtual Machine.
+func RunPowerShellScriptOnVmE(vmName string, resGroupName string, subscriptionID string, script ...string) (*RunCommandOutput, error) {
+	return RunCommandOnVmE(vmName, resGroupName, subscriptionID, RunPowerShellScriptCommandID, script...)
+}
+
+var runCommandExitStatusRegexp = regexp.MustCompile(`exit status=(\d+)`)
+
+// parseRunCommandResult converts the instance view statuses returned by the Run Command API into a RunCommandOutput.
+// Windows agents report stdout and stderr as separate statuses, while Linux agents report a single provisioning
+// status whose message contains [stdout] and [stderr] sections and, on failure, the exit status of the script.
+func parseRunCommandResult(result compute.RunCommandResult) *RunCommandOutput {
+	output := &RunCommandOutput{}
+	if result.Value == nil {
+		return output
+	}
+
+	for _, status := range *result.Value {
+		code := safePtrToString(status.Code)
+		message := safePtrToString(status.Message)
+
+		switch {
+		case strings.HasPrefix(code, "ComponentStatus/StdOut/"):
+			output.Stdout = message
+		case strings.HasPrefix(code, "ComponentStatus/StdErr/"):
+			output.Stderr = message
+		case strings.HasPrefix(code, "ProvisioningState/"):
+			output.Stdout, output.Stderr = splitRunCommandMessage(message)
+			if matches := runCommandExitStatusRegexp.FindStringSubmatch(message); matches != nil {
+				output.ExitCode, _ = strconv.Atoi(matches[1])
+			} else if strings.HasPrefix(code, "ProvisioningState/failed") {
+				output.ExitCode = 1
+			}
+		}
+	}
+
+	return output
+}
+
+// splitRunCommandMessage splits a Linux Run Command message of the form
+// "Enable succeeded: \n[stdout]\n...\n[stderr]\n..." into its stdout and stderr parts.
+func splitRunCommandMessage(message string) (string, string) {
+	const stdoutMarker = "[stdout]\n"
+	const stderrMarker = "[stderr]\n"
+
+	stdoutIndex := strings.Index(message, stdoutMarker)
+	if stdoutIndex < 0 {
+		return message, ""
+	}
+	stdout := message[stdoutIndex+len(stdoutMarker):]
+
+	stderr := ""
+	if stderrIndex := strings.Index(stdout, stderrMarker); stderrIndex >= 0 {
+		stderr = stdout[stderrIndex+len(stderrMarker):]
+		stdout = stdout[:stderrIndex]
+	}
+	return strings.TrimSuffix(stdout, "\n"), strings.TrimSuffix(stderr, "\n")
+}
@@ -0,0 +1,77 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCommandOnVmE(t *testing.T) {
+	t.Parallel()
+
+	vmName := ""
+	rgName := ""
+	subID := ""
+
+	_, err := RunCommandOnVmE(vmName, rgName, subID, RunShellScriptCommandID, "echo hello")
+	require.Error(t, err)
+}
+
+func TestParseRunCommandResult(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		statuses []compute.InstanceViewStatus
+		expected RunCommandOutput
+	}{
+		{
+			"NoStatuses",
+			nil,
+			RunCommandOutput{},
+		},
+		{
+			"LinuxSucceeded",
+			[]compute.InstanceViewStatus{
+				{Code: strPtr("ProvisioningState/succeeded"), Message: strPtr("Enable succeeded: \n[stdout]\nhello\n\n[stderr]\n")},
+			},
+			RunCommandOutput{Stdout: "hello\n", Stderr: "", ExitCode: 0},
+		},
+		{
+			"LinuxFailed",
+			[]compute.InstanceViewStatus{
+				{Code: strPtr("ProvisioningState/failed/3"), Message: strPtr("Enable failed: failed to execute command: command terminated with exit status=3\n[stdout]\n\n[stderr]\nboom\n")},
+			},
+			RunCommandOutput{Stdout: "", Stderr: "boom", ExitCode: 3},
+		},
+		{
+			"Windows",
+			[]compute.InstanceViewStatus{
+				{Code: strPtr("ComponentStatus/StdOut/succeeded"), Message: strPtr("hello")},
+				{Code: strPtr("ComponentStatus/StdErr/succeeded"), Message: strPtr("warning")},
+			},
+			RunCommandOutput{Stdout: "hello", Stderr: "warning", ExitCode: 0},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := compute.RunCommandResult{}
+			if testCase.statuses != nil {
+				result.Value = &testCase.statuses
+			}
+
+			actual := parseRunCommandResult(result)
+			assert.Equal(t, testCase.expected, *actual)
+		})
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,123 @@
+package azure
+
+import (
+	"context"
+
+	computev2020 "github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2020-06-01/compute"
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// CreateSshPublicKey generates an ED25519 public/private KeyPair and creates an Azure SSH Public Key resource with its
+// public key in the specified Azure Resource Group, to be referenced by the VMs under test.
+// This function would fail the test if there is an error.
+func CreateSshPublicKey(t testing.TestingT, sshPublicKeyName string, location string, resGroupName string, subscriptionID string) *ssh.KeyPair {
+	keyPair, err := CreateSshPublicKeyE(t, sshPublicKeyName, location, resGroupName, subscriptionID)
+	require.NoError(t, err)
+	return keyPair
+}
+
+// CreateSshPublicKeyE generates an ED25519 public/private KeyPair and creates an Azure SSH Public Key resource with its
+// public key in the specified Azure Resource Group, to be referenced by the VMs under test.
+func CreateSshPublicKeyE(t testing.TestingT, sshPublicKeyName string, location string, resGroupName string, subscriptionID string) (*ssh.KeyPair, error) {
+	keyPair, err := ssh.GenerateED25519KeyPairE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return keyPair, ImportSshPublicKeyE(sshPublicKeyName, location, resGroupName, subscriptionID, keyPair)
+}
+
+// ImportSshPublicKey creates an Azure SSH Public Key resource with the public key of the given KeyPair, which may be an
+// RSA or ED25519 key, in the specified Azure Resource Group.
+// This function would fail the test if there is an error.
+func ImportSshPublicKey(t testing.TestingT, sshPublicKeyName string, location string, resGroupName string, subscriptionID string, keyPair *ssh.KeyPair) {
+	err := ImportSshPublicKeyE(sshPublicKeyName, location, resGroupName, subscriptionID, keyPair)
+	require.NoError(t, err)
+}
+
+// ImportSshPublicKeyE creates an Azure SSH Public Key resource with the public key of the given KeyPair, which may be
+// an RSA or ED25519 key, in the specified Azure Resource Group.
+func ImportSshPublicKeyE(sshPublicKeyName string, location string, resGroupName string, subscriptionID string, keyPair *ssh.KeyPair) error {
+	// Validate resource group name and subscription ID
+	resGroupName, err := getTargetAzureResourceGroupName(resGroupName)
+	if err != nil {
+		return err
+	}
+
+	// Get the client reference
+	client, err := CreateSshPublicKeysClientE(subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	// Create the SSH Public Key
+	_, err = client.Create(context.Background(), resGroupName, sshPublicKeyName, computev2020.SSHPublicKeyResource{
+		Location: &location,
+		SSHPublicKeyResourceProperties: &computev2020.SSHPublicKeyResourceProperties{
+			PublicKey: &keyPair.PublicKey,
+		},
+	})
+	return err
+}
+
+// GetSshPublicKey gets the public key of an Azure SSH Public Key resource in the specified Azure Resource Group.
+// This function would fail the test if there is an error.
+func GetSshPublicKey(t testing.TestingT, sshPublicKeyName string, resGroupName string, subscriptionID string) string {
+	publicKey, err := GetSshPublicKeyE(sshPublicKeyName, resGroupName, subscriptionID)
+	require.NoError(t, err)
+	return publicKey
+}
+
+// GetSshPublicKeyE gets the public key of an Azure SSH Public Key resource in the specified Azure Resource Group.
+func GetSshPublicKeyE(sshPublicKeyName string, resGroupName string, subscriptionID string) (string, error) {
+	// Validate resource group name and subscription ID
+	resGroupName, err := getTargetAzureResourceGroupName(resGroupName)
+	if err != nil {
+		return "", err
+	}
+
+	// Get the client reference
+	client, err := CreateSshPublicKeysClientE(subscriptionID)
+	if err != nil {
+		return "", err
+	}
+
+	// Get the SSH Public Key
+	sshPublicKey, err := client.Get(context.Background(), resGroupName, sshPublicKeyName)
+	if err != nil {
+		return "", err
+	}
+	if sshPublicKey.SSHPublicKeyResourceProperties == nil || sshPublicKey.PublicKey == nil {
+		return "", nil
+	}
+
+	return *sshPublicKey.PublicKey, nil
+}
+
+// DeleteSshPublicKey deletes an Azure SSH Public Key resource in the specified Azure Resource Group.
+// This function would fail the test if there is an error.
+func DeleteSshPublicKey(t testing.TestingT, sshPublicKeyName string, resGroupName string, subscriptionID string) {
+	err := DeleteSshPublicKeyE(sshPublicKeyName, resGroupName, subscriptionID)
+	require.NoError(t, err)
+}
+
+// DeleteSshPublicKeyE deletes an Azure SSH Public Key resource in the specified Azure Resource Group.
+func DeleteSshPublicKeyE(sshPublicKeyName string, resGroupName string, subscriptionID string) error {
+	// Validate resource group name and subscription ID
+	resGroupName, err := getTargetAzureResourceGroupName(resGroupName)
+	if err != nil {
+		return err
+	}
+
+	// Get the client reference
+	client, err := CreateSshPublicKeysClientE(subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	// Delete the SSH Public Key
+	_, err = client.Delete(context.Background(), resGroupName, sshPublicKeyName)
+	return err
+}
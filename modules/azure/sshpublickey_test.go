@@ -0,0 +1,37 @@
+//go:build azure
+// +build azure
+
+// NOTE: We use build tags to differentiate azure testing because we currently do not have azure access setup for
+// CircleCI.
+
+package azure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSshPublicKeyE(t *testing.T) {
+	t.Parallel()
+
+	sshPublicKeyName := ""
+	rgName := ""
+	subID := ""
+
+	_, err := GetSshPublicKeyE(sshPublicKeyName, rgName, subID)
+
+	require.Error(t, err)
+}
+
+func TestDeleteSshPublicKeyE(t *testing.T) {
+	t.Parallel()
+
+	sshPublicKeyName := ""
+	rgName := ""
+	subID := ""
+
+	err := DeleteSshPublicKeyE(sshPublicKeyName, rgName, subID)
+
+	require.Error(t, err)
+}
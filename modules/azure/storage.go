@@ -220,39 +220,45 @@ func GetStorageFileShareE(fileShareName, storageAccountName, resourceGroupName,
 }
 
 // GetStorageAccountClientE creates a storage account client.
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
 // TODO: remove in next version
 func GetStorageAccountClientE(subscriptionID string) (*storage.AccountsClient, error) {
-	// Validate Azure subscription ID
-	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
-	if err != nil {
-		return nil, err
-	}
+	return getCachedClientE("StorageAccountClient", subscriptionID, func(subscriptionID string) (*storage.AccountsClient, error) {
+		// Validate Azure subscription ID
+		subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
 
-	storageAccountClient := storage.NewAccountsClient(subscriptionID)
-	authorizer, err := NewAuthorizer()
-	if err != nil {
-		return nil, err
-	}
-	storageAccountClient.Authorizer = *authorizer
-	return &storageAccountClient, nil
+		storageAccountClient := storage.NewAccountsClient(subscriptionID)
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
+		storageAccountClient.Authorizer = *authorizer
+		return &storageAccountClient, nil
+	})
 }
 
 // GetStorageBlobContainerClientE creates a storage container client.
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
 // TODO: remove in next version
 func GetStorageBlobContainerClientE(subscriptionID string) (*storage.BlobContainersClient, error) {
-	subscriptionID, err := getTargetAzureSubscription(subscriptionID)
-	if err != nil {
-		return nil, err
-	}
+	return getCachedClientE("StorageBlobContainerClient", subscriptionID, func(subscriptionID string) (*storage.BlobContainersClient, error) {
+		subscriptionID, err := getTargetAzureSubscription(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
 
-	blobContainerClient := storage.NewBlobContainersClient(subscriptionID)
-	authorizer, err := NewAuthorizer()
+		blobContainerClient := storage.NewBlobContainersClient(subscriptionID)
+		authorizer, err := NewAuthorizer()
 
-	if err != nil {
-		return nil, err
-	}
-	blobContainerClient.Authorizer = *authorizer
-	return &blobContainerClient, nil
+		if err != nil {
+			return nil, err
+		}
+		blobContainerClient.Authorizer = *authorizer
+		return &blobContainerClient, nil
+	})
 }
 
 // GetStorageURISuffixE returns the proper storage URI suffix for the configured Azure environment.
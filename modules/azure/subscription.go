@@ -5,20 +5,23 @@ import (
 )
 
 // GetSubscriptionClientE is a helper function that will setup an Azure Subscription client on your behalf
+// The client is cached and reused, see SetClientCacheEnabled.
 func GetSubscriptionClientE() (*subscriptions.Client, error) {
-	// Create a Subscription client
-	client, err := CreateSubscriptionsClientE()
-	if err != nil {
-		return nil, err
-	}
+	return getCachedE(newClientCacheKey("SubscriptionsClient", ""), func() (*subscriptions.Client, error) {
+		// Create a Subscription client
+		client, err := CreateSubscriptionsClientE()
+		if err != nil {
+			return nil, err
+		}
 
-	// Create an authorizer
-	authorizer, err := NewAuthorizer()
-	if err != nil {
-		return nil, err
-	}
+		// Create an authorizer
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
 
-	// Attach authorizer to the client
-	client.Authorizer = *authorizer
-	return &client, nil
+		// Attach authorizer to the client
+		client.Authorizer = *authorizer
+		return &client, nil
+	})
 }
@@ -161,21 +161,24 @@ func GetSubnetE(subnetName string, vnetName string, resGroupName string, subscri
 }
 
 // GetSubnetClientE creates a subnet client.
+// The client is cached and reused per subscription, see SetClientCacheEnabled.
 func GetSubnetClientE(subscriptionID string) (*network.SubnetsClient, error) {
-	// Create a new Subnet client from client factory
-	client, err := CreateNewSubnetClientE(subscriptionID)
-	if err != nil {
-		return nil, err
-	}
+	return getCachedClientE("SubnetClient", subscriptionID, func(subscriptionID string) (*network.SubnetsClient, error) {
+		// Create a new Subnet client from client factory
+		client, err := CreateNewSubnetClientE(subscriptionID)
+		if err != nil {
+			return nil, err
+		}
 
-	// Create an authorizer
-	authorizer, err := NewAuthorizer()
-	if err != nil {
-		return nil, err
-	}
-	client.Authorizer = *authorizer
+		// Create an authorizer
+		authorizer, err := NewAuthorizer()
+		if err != nil {
+			return nil, err
+		}
+		client.Authorizer = *authorizer
 
-	return client, nil
+		return client, nil
+	})
 }
 
 // GetVirtualNetworkE gets Virtual Network in the specified Azure Resource Group.
@@ -0,0 +1,233 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// BuildxOptions defines options that can be passed to the 'docker buildx build' command.
+type BuildxOptions struct {
+	// Platforms to build the image for (e.g. linux/amd64, linux/arm64). Empty means the platform of the builder.
+	Platforms []string
+
+	// Tags for the Docker image
+	Tags []string
+
+	// Build args to pass the 'docker buildx build' command, in the format key=value
+	BuildArgs []string
+
+	// Secrets to expose to the build, in the format of the --secret option (e.g. id=github-token,env=GITHUB_TOKEN)
+	Secrets []string
+
+	// Cache sources and exports, in the format of the --cache-from and --cache-to options (e.g. type=registry,ref=...)
+	CacheFrom []string
+	CacheTo   []string
+
+	// Target build stage to pass to the 'docker buildx build' command
+	Target string
+
+	// Name of the builder instance to use. Empty means the current builder.
+	Builder string
+
+	// Whether or not to push the image to the registry of its tags. The per-platform digests are only known when the
+	// image is pushed.
+	Push bool
+
+	// Whether or not to load the image into the docker daemon. This only works for a single platform.
+	Load bool
+
+	// Custom CLI options that will be passed as-is to the 'docker buildx build' command.
+	OtherOptions []string
+
+	// Additional environment variables to pass in when running docker buildx build command.
+	Env map[string]string
+
+	// Set a logger that should be used. See the logger package for more info.
+	Logger *logger.Logger
+}
+
+// BuildxResult is the result of a 'docker buildx build'.
+type BuildxResult struct {
+	// Digest of the image, which is the digest of the manifest list for multi-platform images
+	Digest string
+
+	// Digests of the image of each platform (e.g. linux/arm64/v8), when the image was pushed
+	PlatformDigests map[string]string
+}
+
+// Buildx runs the 'docker buildx build' command at the given path with the given options and returns the digests of
+// the image. This will fail the test if there are any errors.
+func Buildx(t testing.TestingT, path string, options *BuildxOptions) *BuildxResult {
+	result, err := BuildxE(t, path, options)
+	require.NoError(t, err)
+	return result
+}
+
+// BuildxE runs the 'docker buildx build' command at the given path with the given options and returns the digests of
+// the image. When the image is pushed, the digest of the image of each platform is looked up in the registry.
+func BuildxE(t testing.TestingT, path string, options *BuildxOptions) (*BuildxResult, error) {
+	options.Logger.Logf(t, "Running 'docker buildx build' in %s for platforms %v", path, options.Platforms)
+
+	metadataDir, err := os.MkdirTemp("", "terratest-buildx-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(metadataDir)
+	metadataFile := filepath.Join(metadataDir, "metadata.json")
+
+	cmd := shell.Command{
+		Command: "docker",
+		Args:    formatDockerBuildxArgs(path, metadataFile, options),
+		Logger:  options.Logger,
+		Env:     options.Env,
+	}
+	if err := shell.RunCommandE(t, cmd); err != nil {
+		return nil, err
+	}
+
+	metadata, err := os.ReadFile(metadataFile)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := parseBuildxMetadataDigest(metadata)
+	if err != nil {
+		return nil, err
+	}
+	result := &BuildxResult{Digest: digest, PlatformDigests: map[string]string{}}
+	if !options.Push || len(options.Tags) == 0 {
+		return result, nil
+	}
+
+	// Look up the pushed manifest by digest, so a concurrent push of the same tag doesn't get in the way
+	repository := getImageRepository(options.Tags[0])
+	inspectCmd := shell.Command{
+		Command: "docker",
+		Args:    []string{"buildx", "imagetools", "inspect", "--raw", fmt.Sprintf("%s@%s", repository, digest)},
+		Logger:  options.Logger,
+		Env:     options.Env,
+	}
+	manifest, err := shell.RunCommandAndGetStdOutE(t, inspectCmd)
+	if err != nil {
+		return nil, err
+	}
+	result.PlatformDigests, err = parsePlatformDigests(manifest, digest, options.Platforms)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// formatDockerBuildxArgs formats the arguments for the 'docker buildx build' command.
+func formatDockerBuildxArgs(path string, metadataFile string, options *BuildxOptions) []string {
+	args := []string{"buildx", "build", "--metadata-file", metadataFile}
+
+	if options.Builder != "" {
+		args = append(args, "--builder", options.Builder)
+	}
+	if len(options.Platforms) > 0 {
+		args = append(args, "--platform", strings.Join(options.Platforms, ","))
+	}
+	for _, tag := range options.Tags {
+		args = append(args, "--tag", tag)
+	}
+	for _, arg := range options.BuildArgs {
+		args = append(args, "--build-arg", arg)
+	}
+	for _, secret := range options.Secrets {
+		args = append(args, "--secret", secret)
+	}
+	for _, cache := range options.CacheFrom {
+		args = append(args, "--cache-from", cache)
+	}
+	for _, cache := range options.CacheTo {
+		args = append(args, "--cache-to", cache)
+	}
+	if len(options.Target) > 0 {
+		args = append(args, "--target", options.Target)
+	}
+	if options.Push {
+		args = append(args, "--push")
+	}
+	if options.Load {
+		args = append(args, "--load")
+	}
+
+	args = append(args, options.OtherOptions...)
+
+	args = append(args, path)
+	return args
+}
+
+// parseBuildxMetadataDigest returns the digest of the image from the metadata file written by buildx.
+func parseBuildxMetadataDigest(metadata []byte) (string, error) {
+	var parsed struct {
+		Digest string `json:"containerimage.digest"`
+	}
+	if err := json.Unmarshal(metadata, &parsed); err != nil {
+		return "", err
+	}
+	if parsed.Digest == "" {
+		return "", fmt.Errorf("no image digest in the buildx metadata: %s", string(metadata))
+	}
+	return parsed.Digest, nil
+}
+
+// parsePlatformDigests returns the digests of the image of each platform, from the raw manifest of the image. If the
+// manifest is the manifest of a single platform image, its digest is returned for the only platform built.
+func parsePlatformDigests(manifest string, digest string, platforms []string) (map[string]string, error) {
+	var index struct {
+		Manifests []struct {
+			Digest   string
+			Platform struct {
+				OS           string
+				Architecture string
+				Variant      string
+			}
+			Annotations map[string]string
+		}
+	}
+	if err := json.Unmarshal([]byte(manifest), &index); err != nil {
+		return nil, err
+	}
+
+	digests := map[string]string{}
+	if len(index.Manifests) == 0 {
+		if len(platforms) == 1 {
+			digests[platforms[0]] = digest
+		}
+		return digests, nil
+	}
+
+	for _, descriptor := range index.Manifests {
+		// Skip the provenance and SBOM attestations buildx adds to the index
+		if descriptor.Annotations["vnd.docker.reference.type"] == "attestation-manifest" {
+			continue
+		}
+		platform := descriptor.Platform.OS + "/" + descriptor.Platform.Architecture
+		if descriptor.Platform.Variant != "" {
+			platform += "/" + descriptor.Platform.Variant
+		}
+		digests[platform] = descriptor.Digest
+	}
+	return digests, nil
+}
+
+// getImageRepository returns the repository of the given image reference, without its tag or digest.
+func getImageRepository(image string) string {
+	if index := strings.Index(image, "@"); index >= 0 {
+		image = image[:index]
+	}
+	// The last colon is a tag separator, unless it is part of the registry host (e.g. localhost:5000/app)
+	if index := strings.LastIndex(image, ":"); index > strings.LastIndex(image, "/") {
+		image = image[:index]
+	}
+	return image
+}
@@ -0,0 +1,62 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatDockerBuildxArgs(t *testing.T) {
+	t.Parallel()
+
+	options := &BuildxOptions{
+		Platforms: []string{"linux/amd64", "linux/arm64"},
+		Tags:      []string{"registry.example.com/app:v1"},
+		BuildArgs: []string{"text=Hello"},
+		Secrets:   []string{"id=github-token,env=GITHUB_TOKEN"},
+		CacheFrom: []string{"type=registry,ref=registry.example.com/app:cache"},
+		CacheTo:   []string{"type=registry,ref=registry.example.com/app:cache,mode=max"},
+		Push:      true,
+	}
+	require.Equal(t, []string{
+		"buildx", "build", "--metadata-file", "/tmp/metadata.json",
+		"--platform", "linux/amd64,linux/arm64",
+		"--tag", "registry.example.com/app:v1",
+		"--build-arg", "text=Hello",
+		"--secret", "id=github-token,env=GITHUB_TOKEN",
+		"--cache-from", "type=registry,ref=registry.example.com/app:cache",
+		"--cache-to", "type=registry,ref=registry.example.com/app:cache,mode=max",
+		"--push",
+		".",
+	}, formatDockerBuildxArgs(".", "/tmp/metadata.json", options))
+}
+
+func TestParsePlatformDigests(t *testing.T) {
+	t.Parallel()
+
+	digest, err := parseBuildxMetadataDigest([]byte(`{"containerimage.digest":"sha256:index","image.name":"registry.example.com/app:v1"}`))
+	require.NoError(t, err)
+	require.Equal(t, "sha256:index", digest)
+	_, err = parseBuildxMetadataDigest([]byte(`{"buildx.build.ref":"builder/builder0/abc"}`))
+	require.Error(t, err)
+
+	index := `{"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[
+		{"digest":"sha256:amd64","platform":{"os":"linux","architecture":"amd64"}},
+		{"digest":"sha256:arm64","platform":{"os":"linux","architecture":"arm64","variant":"v8"}},
+		{"digest":"sha256:attestation","platform":{"os":"unknown","architecture":"unknown"},"annotations":{"vnd.docker.reference.type":"attestation-manifest"}}]}`
+	digests, err := parsePlatformDigests(index, digest, []string{"linux/amd64", "linux/arm64"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"linux/amd64": "sha256:amd64", "linux/arm64/v8": "sha256:arm64"}, digests)
+
+	digests, err = parsePlatformDigests(`{"mediaType":"application/vnd.oci.image.manifest.v1+json","layers":[]}`, "sha256:single", []string{"linux/amd64"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"linux/amd64": "sha256:single"}, digests)
+}
+
+func TestGetImageRepository(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "registry.example.com/app", getImageRepository("registry.example.com/app:v1"))
+	require.Equal(t, "localhost:5000/app", getImageRepository("localhost:5000/app"))
+	require.Equal(t, "localhost:5000/app", getImageRepository("localhost:5000/app:v1@sha256:abc"))
+}
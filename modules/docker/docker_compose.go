@@ -45,6 +45,11 @@ func RunDockerComposeE(t testing.TestingT, options *Options, args ...string) (st
 	return runDockerComposeE(t, false, options, args...)
 }
 
+// RunDockerComposeAndGetStdOutE runs docker compose with the given arguments and options and returns only stdout.
+func RunDockerComposeAndGetStdOutE(t testing.TestingT, options *Options, args ...string) (string, error) {
+	return runDockerComposeE(t, true, options, args...)
+}
+
 func runDockerComposeE(t testing.TestingT, stdout bool, options *Options, args ...string) (string, error) {
 	var cmd shell.Command
 
@@ -86,7 +91,7 @@ func runDockerComposeE(t testing.TestingT, stdout bool, options *Options, args .
 	}
 
 	if stdout {
-		return shell.RunCommandAndGetStdOut(t, cmd), nil
+		return shell.RunCommandAndGetStdOutE(t, cmd)
 	}
 
 	return shell.RunCommandAndGetOutputE(t, cmd)
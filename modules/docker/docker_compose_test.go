@@ -1,6 +1,7 @@
 package docker
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -62,3 +63,54 @@ func TestDockerComposeWithCustomProjectName(t *testing.T) {
 		})
 	}
 }
+
+func TestParseComposePs(t *testing.T) {
+	t.Parallel()
+
+	lines := `{"ID":"a1","Name":"app-web-1","Image":"nginx:1.25","Service":"web","State":"running","Health":"healthy","ExitCode":0,"Publishers":[{"URL":"0.0.0.0","TargetPort":80,"PublishedPort":8080,"Protocol":"tcp"}]}
+{"ID":"b2","Name":"app-db-1","Image":"postgres:16","Service":"db","State":"running","Health":"starting","ExitCode":0,"Publishers":[{"URL":"","TargetPort":5432,"PublishedPort":0,"Protocol":"tcp"}]}
+`
+	containers, err := parseComposePs(lines)
+	require.NoError(t, err)
+	require.Len(t, containers, 2)
+	require.Equal(t, "web", containers[0].Service)
+	require.True(t, containers[0].IsHealthy())
+	require.Equal(t, uint16(8080), containers[0].GetPublishedPort(80))
+	require.False(t, containers[1].IsHealthy())
+	require.Equal(t, uint16(0), containers[1].GetPublishedPort(5432))
+
+	// Docker Compose before 2.21 prints a JSON array
+	array, err := parseComposePs("[" + strings.Join(strings.Split(strings.TrimSpace(lines), "\n"), ",") + "]")
+	require.NoError(t, err)
+	require.Equal(t, containers, array)
+
+	empty, err := parseComposePs("\n")
+	require.NoError(t, err)
+	require.Empty(t, empty)
+}
+
+func TestCheckComposeContainersHealthy(t *testing.T) {
+	t.Parallel()
+
+	containers := []ComposeContainer{
+		{Name: "app-web-1", Service: "web", State: "running"},
+		{Name: "app-db-1", Service: "db", State: "running", Health: "healthy"},
+	}
+	require.NoError(t, checkComposeContainersHealthy(containers))
+	require.Error(t, checkComposeContainersHealthy(nil))
+
+	containers[1].Health = "unhealthy"
+	require.Error(t, checkComposeContainersHealthy(containers))
+	require.False(t, hasFailedComposeContainer(containers))
+
+	// One-off services that exited with exit code 0 are done, not unhealthy
+	containers[1] = ComposeContainer{Name: "app-migrate-1", Service: "migrate", State: "exited", ExitCode: 0}
+	require.True(t, containers[1].IsCompleted())
+	require.NoError(t, checkComposeContainersHealthy(containers))
+	require.False(t, hasFailedComposeContainer(containers))
+
+	containers[1] = ComposeContainer{Name: "app-db-1", Service: "db", State: "exited", ExitCode: 1}
+	require.False(t, containers[1].IsCompleted())
+	require.Error(t, checkComposeContainersHealthy(containers))
+	require.True(t, hasFailedComposeContainer(containers))
+}
@@ -0,0 +1,228 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// composeHealthPollInterval is how often the containers are checked while waiting for them to be healthy.
+const composeHealthPollInterval = 2 * time.Second
+
+// ComposeContainer represents a container of a Docker Compose project, as listed by 'docker compose ps'.
+type ComposeContainer struct {
+	ID      string
+	Name    string
+	Image   string
+	Service string
+
+	// State of the container: created, running, exited, ...
+	State string
+
+	// Health of the container: starting, healthy, unhealthy, or empty if it has no healthcheck
+	Health string
+
+	ExitCode int
+
+	// Ports published by the container
+	Publishers []ComposePublisher
+}
+
+// ComposePublisher represents a port published by a container of a Docker Compose project.
+type ComposePublisher struct {
+	URL           string
+	TargetPort    uint16
+	PublishedPort uint16
+	Protocol      string
+}
+
+// IsHealthy returns true if the container is running and, if it has a healthcheck, reports healthy.
+func (container ComposeContainer) IsHealthy() bool {
+	return container.State == "running" && (container.Health == "" || container.Health == "healthy")
+}
+
+// IsCompleted returns true if the container exited with exit code 0, e.g. for one-off services running migrations or
+// seeding data.
+func (container ComposeContainer) IsCompleted() bool {
+	return container.State == "exited" && container.ExitCode == 0
+}
+
+// GetPublishedPort returns the host port the given container port is published on, or 0 if it isn't published.
+func (container ComposeContainer) GetPublishedPort(targetPort uint16) uint16 {
+	for _, publisher := range container.Publishers {
+		if publisher.TargetPort == targetPort && publisher.PublishedPort != 0 {
+			return publisher.PublishedPort
+		}
+	}
+	return 0
+}
+
+// ComposePs runs 'docker compose ps' and returns the containers of the project, including the stopped ones. This
+// requires Docker Compose v2. This will fail the test if there is an error.
+func ComposePs(t testing.TestingT, options *Options, services ...string) []ComposeContainer {
+	containers, err := ComposePsE(t, options, services...)
+	require.NoError(t, err)
+	return containers
+}
+
+// ComposePsE runs 'docker compose ps' and returns the containers of the project, including the stopped ones. If
+// services are given, only their containers are returned. This requires Docker Compose v2.
+func ComposePsE(t testing.TestingT, options *Options, services ...string) ([]ComposeContainer, error) {
+	args := append([]string{"ps", "--all", "--format", "json"}, services...)
+	out, err := RunDockerComposeAndGetStdOutE(t, options, args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseComposePs(out)
+}
+
+// UpAndWaitForHealthy runs 'docker compose up -d' for the given services, or all the services of the project if none
+// is given, and waits until all their containers are running and healthy. This requires Docker Compose v2. This will
+// fail the test if there is an error or if the containers aren't healthy in time.
+func UpAndWaitForHealthy(t testing.TestingT, options *Options, timeout time.Duration, services ...string) []ComposeContainer {
+	containers, err := UpAndWaitForHealthyE(t, options, timeout, services...)
+	require.NoError(t, err)
+	return containers
+}
+
+// UpAndWaitForHealthyE runs 'docker compose up -d' for the given services, or all the services of the project if none
+// is given, and waits until all their containers are running and, for the ones with a healthcheck, report healthy.
+// Containers that exited with exit code 0, such as one-off migration services, are considered done rather than
+// unhealthy. It returns the containers, so the tests can look up their published ports. If a container exits with a
+// non-zero exit code or the containers aren't healthy in time, the error includes the logs of the services that aren't
+// healthy. This requires Docker Compose v2.
+func UpAndWaitForHealthyE(t testing.TestingT, options *Options, timeout time.Duration, services ...string) ([]ComposeContainer, error) {
+	if _, err := RunDockerComposeE(t, options, append([]string{"up", "-d"}, services...)...); err != nil {
+		return nil, err
+	}
+
+	var containers []ComposeContainer
+	var lastErr error
+	retries := int(timeout/composeHealthPollInterval) + 1
+	description := fmt.Sprintf("Wait for the containers of services %v to be healthy", services)
+	_, err := retry.DoWithRetryE(t, description, retries, composeHealthPollInterval, func() (string, error) {
+		var err error
+		containers, err = ComposePsE(t, options, services...)
+		if err != nil {
+			return "", err
+		}
+		lastErr = checkComposeContainersHealthy(containers)
+		if lastErr != nil {
+			// There is no point waiting for containers that failed
+			if hasFailedComposeContainer(containers) {
+				return "", retry.FatalError{Underlying: lastErr}
+			}
+			return "", lastErr
+		}
+		return "All containers are healthy", nil
+	})
+	if err == nil {
+		return containers, nil
+	}
+	if lastErr == nil {
+		return containers, err
+	}
+
+	return containers, fmt.Errorf("%w\n%s", lastErr, getUnhealthyServiceLogs(t, options, containers))
+}
+
+// GetServiceLogs returns the logs of the containers of the given service of the Docker Compose project. This will
+// fail the test if there is an error.
+func GetServiceLogs(t testing.TestingT, options *Options, service string) string {
+	out, err := GetServiceLogsE(t, options, service)
+	require.NoError(t, err)
+	return out
+}
+
+// GetServiceLogsE returns the logs of the containers of the given service of the Docker Compose project, without the
+// service name prefix compose adds to each line.
+func GetServiceLogsE(t testing.TestingT, options *Options, service string) (string, error) {
+	return RunDockerComposeE(t, options, "logs", "--no-color", "--no-log-prefix", service)
+}
+
+// parseComposePs parses the output of 'docker compose ps --format json', which is a JSON array in Docker Compose
+// before 2.21, and a JSON object per line since.
+func parseComposePs(output string) ([]ComposeContainer, error) {
+	output = strings.TrimSpace(output)
+	containers := []ComposeContainer{}
+	if output == "" {
+		return containers, nil
+	}
+
+	if strings.HasPrefix(output, "[") {
+		if err := json.Unmarshal([]byte(output), &containers); err != nil {
+			return nil, err
+		}
+		return containers, nil
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var container ComposeContainer
+		if err := json.Unmarshal([]byte(line), &container); err != nil {
+			return nil, err
+		}
+		containers = append(containers, container)
+	}
+	return containers, nil
+}
+
+// checkComposeContainersHealthy returns an error describing the first container that isn't healthy, if any. Containers
+// that completed are ignored.
+func checkComposeContainersHealthy(containers []ComposeContainer) error {
+	if len(containers) == 0 {
+		return fmt.Errorf("no container found")
+	}
+	for _, container := range containers {
+		switch {
+		case container.IsCompleted():
+			continue
+		case isComposeContainerFailed(container):
+			return fmt.Errorf("container %s of service %s is %s with exit code %d", container.Name, container.Service, container.State, container.ExitCode)
+		case !container.IsHealthy():
+			return fmt.Errorf("container %s of service %s is %s and not healthy yet (health: %q)", container.Name, container.Service, container.State, container.Health)
+		}
+	}
+	return nil
+}
+
+func hasFailedComposeContainer(containers []ComposeContainer) bool {
+	for _, container := range containers {
+		if isComposeContainerFailed(container) {
+			return true
+		}
+	}
+	return false
+}
+
+// isComposeContainerFailed returns true if the container is dead or exited with a non-zero exit code.
+func isComposeContainerFailed(container ComposeContainer) bool {
+	return container.State == "dead" || (container.State == "exited" && container.ExitCode != 0)
+}
+
+// getUnhealthyServiceLogs returns the logs of the services having containers that aren't healthy, to attach to the
+// error of a failed wait.
+func getUnhealthyServiceLogs(t testing.TestingT, options *Options, containers []ComposeContainer) string {
+	logs := []string{}
+	seen := map[string]bool{}
+	for _, container := range containers {
+		if container.IsHealthy() || container.IsCompleted() || seen[container.Service] {
+			continue
+		}
+		seen[container.Service] = true
+		out, err := GetServiceLogsE(t, options, container.Service)
+		if err != nil {
+			out = fmt.Sprintf("failed to get logs: %v", err)
+		}
+		logs = append(logs, fmt.Sprintf("Logs of service %s:\n%s", container.Service, out))
+	}
+	return strings.Join(logs, "\n")
+}
@@ -5,11 +5,11 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
-	"testing"
 	"time"
 
 	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/stretchr/testify/require"
 )
 
@@ -113,7 +113,7 @@ type inspectOutput struct {
 
 // Inspect runs the 'docker inspect {container id}' command and returns a ContainerInspect
 // struct, converted from the output JSON, along with any errors
-func Inspect(t *testing.T, id string) *ContainerInspect {
+func Inspect(t testing.TestingT, id string) *ContainerInspect {
 	out, err := InspectE(t, id)
 	require.NoError(t, err)
 
@@ -122,7 +122,7 @@ func Inspect(t *testing.T, id string) *ContainerInspect {
 
 // InspectE runs the 'docker inspect {container id}' command and returns a ContainerInspect
 // struct, converted from the output JSON, along with any errors
-func InspectE(t *testing.T, id string) (*ContainerInspect, error) {
+func InspectE(t testing.TestingT, id string) (*ContainerInspect, error) {
 	cmd := shell.Command{
 		Command: "docker",
 		Args:    []string{"container", "inspect", id},
@@ -151,7 +151,7 @@ func InspectE(t *testing.T, id string) (*ContainerInspect, error) {
 }
 
 // transformContainerPorts converts 'docker inspect' output JSON into a more friendly and testable format
-func transformContainer(t *testing.T, container inspectOutput) (*ContainerInspect, error) {
+func transformContainer(t testing.TestingT, container inspectOutput) (*ContainerInspect, error) {
 	name := strings.TrimLeft(container.Name, "/")
 
 	ports, err := transformContainerPorts(container)
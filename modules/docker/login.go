@@ -0,0 +1,148 @@
+package docker
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/gruntwork-io/terratest/modules/aws"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2/google"
+	"gotest.tools/v3/icmd"
+)
+
+const (
+	// acrTokenUsername is the username to log in to Azure Container Registry with an access token.
+	acrTokenUsername = "00000000-0000-0000-0000-000000000000"
+
+	// garTokenUsername is the username to log in to Google Artifact Registry with an OAuth2 access token.
+	garTokenUsername = "oauth2accesstoken"
+)
+
+// Login runs the 'docker login' command to log in to the given registry with the given credentials. This will fail
+// the test if there are any errors.
+func Login(t testing.TestingT, logger *logger.Logger, registry string, username string, password string) {
+	require.NoError(t, LoginE(t, logger, registry, username, password))
+}
+
+// LoginE runs the 'docker login' command to log in to the given registry with the given credentials. The password is
+// passed on stdin, so it doesn't show up in the logs or the process list.
+func LoginE(t testing.TestingT, logger *logger.Logger, registry string, username string, password string) error {
+	logger.Logf(t, "Running 'docker login' to registry %s as %s", registry, username)
+
+	result := icmd.RunCmd(icmd.Command("docker", "login", "--username", username, "--password-stdin", registry), icmd.WithStdin(strings.NewReader(password)))
+	if result.Error != nil {
+		return fmt.Errorf("docker login to %s failed: %s: %w", registry, strings.TrimSpace(result.Stderr()), result.Error)
+	}
+	return nil
+}
+
+// Logout runs the 'docker logout' command for the given registry. This will fail the test if there are any errors.
+func Logout(t testing.TestingT, logger *logger.Logger, registry string) {
+	require.NoError(t, LogoutE(t, logger, registry))
+}
+
+// LogoutE runs the 'docker logout' command for the given registry.
+func LogoutE(t testing.TestingT, logger *logger.Logger, registry string) error {
+	cmd := shell.Command{
+		Command: "docker",
+		Args:    []string{"logout", registry},
+		Logger:  logger,
+	}
+	return shell.RunCommandE(t, cmd)
+}
+
+// LoginToEcr logs in to the Elastic Container Registry of the current AWS account in the given region, and returns the
+// registry host. This will fail the test if there are any errors.
+func LoginToEcr(t testing.TestingT, logger *logger.Logger, region string) string {
+	registry, err := LoginToEcrE(t, logger, region)
+	require.NoError(t, err)
+	return registry
+}
+
+// LoginToEcrE logs in to the Elastic Container Registry of the current AWS account in the given region, with an
+// authorization token of the ECR API, and returns the registry host (e.g. 123456789012.dkr.ecr.us-east-1.amazonaws.com).
+func LoginToEcrE(t testing.TestingT, logger *logger.Logger, region string) (string, error) {
+	client, err := aws.NewECRClientE(t, region)
+	if err != nil {
+		return "", err
+	}
+	output, err := client.GetAuthorizationToken(context.Background(), &ecr.GetAuthorizationTokenInput{})
+	if err != nil {
+		return "", err
+	}
+	if len(output.AuthorizationData) == 0 || output.AuthorizationData[0].AuthorizationToken == nil || output.AuthorizationData[0].ProxyEndpoint == nil {
+		return "", fmt.Errorf("no ECR authorization data returned in region %s", region)
+	}
+
+	username, password, err := decodeEcrAuthorizationToken(*output.AuthorizationData[0].AuthorizationToken)
+	if err != nil {
+		return "", err
+	}
+	registry := strings.TrimPrefix(*output.AuthorizationData[0].ProxyEndpoint, "https://")
+	return registry, LoginE(t, logger, registry, username, password)
+}
+
+// LoginToAcr logs in to the given Azure Container Registry (e.g. myregistry), and returns the registry host. This will
+// fail the test if there are any errors.
+func LoginToAcr(t testing.TestingT, logger *logger.Logger, registryName string) string {
+	registry, err := LoginToAcrE(t, logger, registryName)
+	require.NoError(t, err)
+	return registry
+}
+
+// LoginToAcrE logs in to the given Azure Container Registry (e.g. myregistry) with an access token of the Azure CLI,
+// which must be logged in, and returns the registry host (e.g. myregistry.azurecr.io).
+func LoginToAcrE(t testing.TestingT, logger *logger.Logger, registryName string) (string, error) {
+	logger.Logf(t, "Running 'az acr login' for registry %s", registryName)
+
+	// Run without a shell.Command, which would print the token
+	result := icmd.RunCmd(icmd.Command("az", "acr", "login", "--name", registryName, "--expose-token", "--output", "tsv", "--query", "[loginServer, accessToken]"))
+	if result.Error != nil {
+		return "", fmt.Errorf("az acr login for registry %s failed: %s: %w", registryName, strings.TrimSpace(result.Stderr()), result.Error)
+	}
+	out := result.Stdout()
+	fields := strings.Fields(out)
+	if len(fields) != 2 {
+		return "", fmt.Errorf("unexpected output of az acr login for registry %s", registryName)
+	}
+	return fields[0], LoginE(t, logger, fields[0], acrTokenUsername, fields[1])
+}
+
+// LoginToGar logs in to the given Google Artifact Registry host (e.g. us-docker.pkg.dev). This will fail the test if
+// there are any errors.
+func LoginToGar(t testing.TestingT, logger *logger.Logger, host string) {
+	require.NoError(t, LoginToGarE(t, logger, host))
+}
+
+// LoginToGarE logs in to the given Google Artifact Registry host (e.g. us-docker.pkg.dev) with an access token of the
+// application default credentials.
+func LoginToGarE(t testing.TestingT, logger *logger.Logger, host string) error {
+	tokenSource, err := google.DefaultTokenSource(context.Background(), "https://www.googleapis.com/auth/cloud-platform")
+	if err != nil {
+		return err
+	}
+	token, err := tokenSource.Token()
+	if err != nil {
+		return err
+	}
+	return LoginE(t, logger, host, garTokenUsername, token.AccessToken)
+}
+
+// decodeEcrAuthorizationToken decodes an ECR authorization token, which is the base64 encoding of username:password.
+func decodeEcrAuthorizationToken(token string) (string, string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		return "", "", err
+	}
+	username, password, found := strings.Cut(string(decoded), ":")
+	if !found {
+		return "", "", fmt.Errorf("invalid ECR authorization token")
+	}
+	return username, password, nil
+}
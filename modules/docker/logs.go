@@ -0,0 +1,95 @@
+package docker
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// logPollInterval is how often the logs of a container are checked while waiting for a message.
+const logPollInterval = 1 * time.Second
+
+// GetContainerLogsSince runs the 'docker logs' command and returns the stdout and stderr of the given container since
+// the given time, interleaved. A zero time returns all the logs. This will fail the test if there are any errors.
+func GetContainerLogsSince(t testing.TestingT, containerID string, since time.Time) string {
+	out, err := GetContainerLogsSinceE(t, containerID, since)
+	require.NoError(t, err)
+	return out
+}
+
+// GetContainerLogsSinceE runs the 'docker logs' command and returns the stdout and stderr of the given container since
+// the given time, interleaved. A zero time returns all the logs.
+func GetContainerLogsSinceE(t testing.TestingT, containerID string, since time.Time) (string, error) {
+	cmd := shell.Command{
+		Command: "docker",
+		Args:    formatDockerLogsArgs(containerID, since),
+		// The logs are returned to the caller, and polled by WaitForLogMessageE, don't print them.
+		Logger: logger.Discard,
+	}
+	return shell.RunCommandAndGetOutputE(t, cmd)
+}
+
+// WaitForLogMessage waits until a line of the logs of the given container matches the given regex, and returns the
+// line. This will fail the test if there are any errors or if no line matches before the timeout.
+func WaitForLogMessage(t testing.TestingT, containerID string, regex string, timeout time.Duration) string {
+	line, err := WaitForLogMessageE(t, containerID, regex, timeout)
+	require.NoError(t, err)
+	return line
+}
+
+// WaitForLogMessageE waits until a line of the logs of the given container matches the given regex (e.g. a startup
+// message of the application), and returns the line. If no line matches before the timeout, the error includes the
+// logs of the container.
+func WaitForLogMessageE(t testing.TestingT, containerID string, regex string, timeout time.Duration) (string, error) {
+	re, err := regexp.Compile(regex)
+	if err != nil {
+		return "", err
+	}
+
+	logs := ""
+	line := ""
+	retries := int(timeout/logPollInterval) + 1
+	description := fmt.Sprintf("Wait for a log message of container %s matching %s", containerID, regex)
+	_, err = retry.DoWithRetryE(t, description, retries, logPollInterval, func() (string, error) {
+		var err error
+		logs, err = GetContainerLogsSinceE(t, containerID, time.Time{})
+		if err != nil {
+			return "", err
+		}
+		line = findLogLine(logs, re)
+		if line == "" {
+			return "", fmt.Errorf("no log message of container %s matches %s yet", containerID, regex)
+		}
+		return line, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w\nLogs of container %s:\n%s", err, containerID, logs)
+	}
+	return line, nil
+}
+
+// formatDockerLogsArgs formats the arguments for the 'docker logs' command.
+func formatDockerLogsArgs(containerID string, since time.Time) []string {
+	args := []string{"logs"}
+	if !since.IsZero() {
+		args = append(args, "--since", since.UTC().Format(time.RFC3339Nano))
+	}
+	return append(args, containerID)
+}
+
+// findLogLine returns the first line of the logs matching the regex, or an empty string if none does.
+func findLogLine(logs string, re *regexp.Regexp) string {
+	for _, line := range strings.Split(logs, "\n") {
+		if re.MatchString(line) {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,45 @@
+package docker
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForLogMessage(t *testing.T) {
+	t.Parallel()
+
+	options := &RunOptions{
+		Command:    []string{"-c", `sleep 2; echo "Listening on port 8080"; sleep 30`},
+		Entrypoint: "sh",
+		Detach:     true,
+		Name:       "test-wait-for-log-message-" + random.UniqueId(),
+		Remove:     true,
+	}
+	id := RunAndGetID(t, "alpine:3.7", options)
+	defer Stop(t, []string{id}, &StopOptions{Time: 1})
+
+	line := WaitForLogMessage(t, id, `Listening on port \d+`, 30*time.Second)
+	require.Equal(t, "Listening on port 8080", line)
+
+	require.Empty(t, GetContainerLogsSince(t, id, time.Now().Add(time.Hour)))
+}
+
+func TestFindLogLine(t *testing.T) {
+	t.Parallel()
+
+	logs := "Starting server\nListening on port 8080\nReady\n"
+	require.Equal(t, "Listening on port 8080", findLogLine(logs, regexp.MustCompile(`port \d+`)))
+	require.Equal(t, "", findLogLine(logs, regexp.MustCompile(`error`)))
+}
+
+func TestFormatDockerLogsArgs(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []string{"logs", "abc"}, formatDockerLogsArgs("abc", time.Time{}))
+	since := time.Date(2024, 3, 1, 10, 0, 0, 0, time.FixedZone("CET", 3600))
+	require.Equal(t, []string{"logs", "--since", "2024-03-01T09:00:00Z", "abc"}, formatDockerLogsArgs("abc", since))
+}
@@ -0,0 +1,90 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// Pull runs the 'docker pull' command to pull the given image. This will fail the test if there are any errors.
+func Pull(t testing.TestingT, logger *logger.Logger, image string) {
+	require.NoError(t, PullE(t, logger, image))
+}
+
+// PullE runs the 'docker pull' command to pull the given image. Use LoginE, or one of the registry specific login
+// functions, to authenticate first.
+func PullE(t testing.TestingT, logger *logger.Logger, image string) error {
+	logger.Logf(t, "Running 'docker pull' for image %s", image)
+
+	cmd := shell.Command{
+		Command: "docker",
+		Args:    []string{"pull", image},
+		Logger:  logger,
+	}
+	return shell.RunCommandE(t, cmd)
+}
+
+// GetImageDigest returns the digest of the given local image in the registry it was pulled from or pushed to. This
+// will fail the test if there are any errors.
+func GetImageDigest(t testing.TestingT, logger *logger.Logger, image string) string {
+	digest, err := GetImageDigestE(t, logger, image)
+	require.NoError(t, err)
+	return digest
+}
+
+// GetImageDigestE returns the digest of the given local image in the registry it was pulled from or pushed to, from
+// the repo digests of the image.
+func GetImageDigestE(t testing.TestingT, logger *logger.Logger, image string) (string, error) {
+	cmd := shell.Command{
+		Command: "docker",
+		Args:    []string{"image", "inspect", "--format", "{{json .RepoDigests}}", image},
+		Logger:  logger,
+	}
+	out, err := shell.RunCommandAndGetStdOutE(t, cmd)
+	if err != nil {
+		return "", err
+	}
+	return parseRepoDigest(out, getImageRepository(image))
+}
+
+// PullAndVerifyDigest pulls the given image and checks that its digest is the expected one. This will fail the test
+// if there are any errors or if the digest doesn't match.
+func PullAndVerifyDigest(t testing.TestingT, logger *logger.Logger, image string, expectedDigest string) {
+	require.NoError(t, PullAndVerifyDigestE(t, logger, image, expectedDigest))
+}
+
+// PullAndVerifyDigestE pulls the given image and checks that its digest is the expected one, e.g. the digest returned
+// by PushAndGetDigestE or BuildxE, to check that the registry serves the image that was published.
+func PullAndVerifyDigestE(t testing.TestingT, logger *logger.Logger, image string, expectedDigest string) error {
+	if err := PullE(t, logger, image); err != nil {
+		return err
+	}
+	digest, err := GetImageDigestE(t, logger, image)
+	if err != nil {
+		return err
+	}
+	if digest != expectedDigest {
+		return fmt.Errorf("image %s has digest %s, expected %s", image, digest, expectedDigest)
+	}
+	return nil
+}
+
+// parseRepoDigest returns the digest of the given repository in the JSON list of repo digests of an image (e.g.
+// ["registry.example.com/app@sha256:..."]).
+func parseRepoDigest(output string, repository string) (string, error) {
+	var repoDigests []string
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &repoDigests); err != nil {
+		return "", err
+	}
+	for _, repoDigest := range repoDigests {
+		if name, digest, found := strings.Cut(repoDigest, "@"); found && name == repository {
+			return digest, nil
+		}
+	}
+	return "", fmt.Errorf("no digest found for repository %s in %v", repository, repoDigests)
+}
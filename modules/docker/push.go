@@ -1,12 +1,18 @@
 package docker
 
 import (
+	"fmt"
+	"regexp"
+
 	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/stretchr/testify/require"
 )
 
+// pushDigestRegexp matches the digest printed by 'docker push' (e.g. v1: digest: sha256:... size: 1570).
+var pushDigestRegexp = regexp.MustCompile(`digest: (sha256:[a-f0-9]{64})`)
+
 // Push runs the 'docker push' command to push the given tag. This will fail the test if there are any errors.
 func Push(t testing.TestingT, logger *logger.Logger, tag string) {
 	require.NoError(t, PushE(t, logger, tag))
@@ -23,3 +29,39 @@ func PushE(t testing.TestingT, logger *logger.Logger, tag string) error {
 	}
 	return shell.RunCommandE(t, cmd)
 }
+
+// PushAndGetDigest runs the 'docker push' command to push the given tag and returns the digest of the pushed image.
+// This will fail the test if there are any errors.
+func PushAndGetDigest(t testing.TestingT, logger *logger.Logger, tag string) string {
+	digest, err := PushAndGetDigestE(t, logger, tag)
+	require.NoError(t, err)
+	return digest
+}
+
+// PushAndGetDigestE runs the 'docker push' command to push the given tag and returns the digest of the pushed image,
+// as reported by the registry. Use LoginE, or one of the registry specific login functions, to authenticate first. It
+// returns an error if the push succeeds but prints no digest, as with some registries and older docker clients: use
+// PushE if the digest is not needed.
+func PushAndGetDigestE(t testing.TestingT, logger *logger.Logger, tag string) (string, error) {
+	logger.Logf(t, "Running 'docker push' for tag %s", tag)
+
+	cmd := shell.Command{
+		Command: "docker",
+		Args:    []string{"push", tag},
+		Logger:  logger,
+	}
+	out, err := shell.RunCommandAndGetOutputE(t, cmd)
+	if err != nil {
+		return "", err
+	}
+	return parsePushDigest(out)
+}
+
+// parsePushDigest returns the digest printed at the end of the output of 'docker push'.
+func parsePushDigest(output string) (string, error) {
+	matches := pushDigestRegexp.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return "", fmt.Errorf("no digest found in the output of docker push")
+	}
+	return matches[len(matches)-1][1], nil
+}
@@ -0,0 +1,49 @@
+package docker
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePushDigest(t *testing.T) {
+	t.Parallel()
+
+	output := `The push refers to repository [registry.example.com/app]
+5f70bf18a086: Pushed
+v1: digest: sha256:0a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f9 size: 528
+`
+	digest, err := parsePushDigest(output)
+	require.NoError(t, err)
+	require.Equal(t, "sha256:0a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f9", digest)
+
+	_, err = parsePushDigest("denied: requested access to the resource is denied")
+	require.Error(t, err)
+}
+
+func TestParseRepoDigest(t *testing.T) {
+	t.Parallel()
+
+	output := `["docker.io/library/app@sha256:111","registry.example.com/team/app@sha256:222"]`
+	digest, err := parseRepoDigest(output, "registry.example.com/team/app")
+	require.NoError(t, err)
+	require.Equal(t, "sha256:222", digest)
+
+	_, err = parseRepoDigest(output, "registry.example.com/other")
+	require.Error(t, err)
+	_, err = parseRepoDigest("[]", "registry.example.com/team/app")
+	require.Error(t, err)
+}
+
+func TestDecodeEcrAuthorizationToken(t *testing.T) {
+	t.Parallel()
+
+	username, password, err := decodeEcrAuthorizationToken(base64.StdEncoding.EncodeToString([]byte("AWS:secret:with:colons")))
+	require.NoError(t, err)
+	require.Equal(t, "AWS", username)
+	require.Equal(t, "secret:with:colons", password)
+
+	_, _, err = decodeEcrAuthorizationToken(base64.StdEncoding.EncodeToString([]byte("nocolon")))
+	require.Error(t, err)
+}
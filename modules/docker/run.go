@@ -1,7 +1,12 @@
 package docker
 
 import (
+	"fmt"
+	"strconv"
+	"time"
+
 	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/stretchr/testify/require"
@@ -108,6 +113,123 @@ func RunAndGetIDE(t testing.TestingT, image string, options *RunOptions) (string
 	return shell.RunCommandAndGetStdOutE(t, cmd)
 }
 
+// HealthCheckOptions defines the healthcheck to run in a container started by RunAndWaitForHealthy, overriding the
+// HEALTHCHECK of the image.
+type HealthCheckOptions struct {
+	// Command to run to check the health of the container, through the shell of the container (e.g. pg_isready)
+	Command string
+
+	// Time between the checks, how long a check can last, how long the container has to start before the failures
+	// count, and how many consecutive failures make the container unhealthy. Zero means the default of Docker.
+	Interval    time.Duration
+	Timeout     time.Duration
+	StartPeriod time.Duration
+	Retries     int
+}
+
+// healthPollInterval is how often the health of a container is checked while waiting for it to be healthy.
+const healthPollInterval = 1 * time.Second
+
+// RunAndWaitForHealthy runs the 'docker run' command on the given image in the background, waits until the
+// healthcheck of the container reports healthy, and returns the inspected container, with its mapped ports. This
+// method fails the test if there are any errors or if the container isn't healthy in time.
+func RunAndWaitForHealthy(t testing.TestingT, image string, options *RunOptions, healthCheck *HealthCheckOptions, timeout time.Duration) *ContainerInspect {
+	container, err := RunAndWaitForHealthyE(t, image, options, healthCheck, timeout)
+	require.NoError(t, err)
+	return container
+}
+
+// RunAndWaitForHealthyE runs the 'docker run' command on the given image in the background, waits until the
+// healthcheck of the container reports healthy, and returns the inspected container, with its mapped ports (see
+// GetExposedHostPort). The healthcheck is the given one, or the HEALTHCHECK of the image if healthCheck is nil. If the
+// container stops or isn't healthy in time, the error includes the logs of the container, which is then removed.
+func RunAndWaitForHealthyE(t testing.TestingT, image string, options *RunOptions, healthCheck *HealthCheckOptions, timeout time.Duration) (*ContainerInspect, error) {
+	runOptions := *options
+	runOptions.Detach = true
+	runOptions.OtherOptions = append(formatHealthCheckArgs(healthCheck), options.OtherOptions...)
+
+	id, err := RunAndGetIDE(t, image, &runOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	var container *ContainerInspect
+	retries := int(timeout/healthPollInterval) + 1
+	description := fmt.Sprintf("Wait for container %s of image %s to be healthy", id, image)
+	_, err = retry.DoWithRetryE(t, description, retries, healthPollInterval, func() (string, error) {
+		var err error
+		container, err = InspectE(t, id)
+		if err != nil {
+			return "", err
+		}
+		if err := checkContainerHealthy(container); err != nil {
+			// There is no point waiting for a container that stopped or has no healthcheck
+			if !container.Running || container.Health.Status == "" {
+				return "", retry.FatalError{Underlying: err}
+			}
+			return "", err
+		}
+		return "Container is healthy", nil
+	})
+	if err != nil {
+		logs, logsErr := GetContainerLogsSinceE(t, id, time.Time{})
+		if logsErr != nil {
+			logs = fmt.Sprintf("failed to get logs: %v", logsErr)
+		}
+		// Don't leave behind a container that nobody will stop
+		removeCmd := shell.Command{
+			Command: "docker",
+			Args:    []string{"container", "rm", "--force", id},
+			Logger:  options.Logger,
+		}
+		if removeErr := shell.RunCommandE(t, removeCmd); removeErr != nil {
+			options.Logger.Logf(t, "Failed to remove container %s: %v", id, removeErr)
+		}
+		return container, fmt.Errorf("%w\nLogs of container %s:\n%s", err, id, logs)
+	}
+	return container, nil
+}
+
+// checkContainerHealthy returns an error describing why the container isn't healthy, with the output of its last
+// healthcheck, if it isn't.
+func checkContainerHealthy(container *ContainerInspect) error {
+	switch {
+	case !container.Running:
+		return fmt.Errorf("container %s is %s with exit code %d", container.ID, container.Status, container.ExitCode)
+	case container.Health.Status == "":
+		return fmt.Errorf("container %s has no healthcheck", container.ID)
+	case container.Health.Status != "healthy":
+		lastOutput := ""
+		if len(container.Health.Log) > 0 {
+			lastOutput = container.Health.Log[len(container.Health.Log)-1].Output
+		}
+		return fmt.Errorf("container %s is %s, last healthcheck output: %s", container.ID, container.Health.Status, lastOutput)
+	}
+	return nil
+}
+
+// formatHealthCheckArgs formats the arguments for the 'docker run' command to override the healthcheck of the image.
+func formatHealthCheckArgs(healthCheck *HealthCheckOptions) []string {
+	if healthCheck == nil {
+		return []string{}
+	}
+
+	args := []string{"--health-cmd", healthCheck.Command}
+	if healthCheck.Interval != 0 {
+		args = append(args, "--health-interval", healthCheck.Interval.String())
+	}
+	if healthCheck.Timeout != 0 {
+		args = append(args, "--health-timeout", healthCheck.Timeout.String())
+	}
+	if healthCheck.StartPeriod != 0 {
+		args = append(args, "--health-start-period", healthCheck.StartPeriod.String())
+	}
+	if healthCheck.Retries != 0 {
+		args = append(args, "--health-retries", strconv.Itoa(healthCheck.Retries))
+	}
+	return args
+}
+
 // formatDockerRunArgs formats the arguments for the 'docker run' command.
 func formatDockerRunArgs(image string, options *RunOptions) ([]string, error) {
 	args := []string{"run"}
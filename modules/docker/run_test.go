@@ -1,8 +1,11 @@
 package docker
 
 import (
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/gruntwork-io/terratest/modules/random"
 	"github.com/stretchr/testify/require"
 )
 
@@ -19,3 +22,64 @@ func TestRun(t *testing.T) {
 	out := Run(t, "alpine:3.7", options)
 	require.Contains(t, out, "Hello, World!")
 }
+
+func TestRunAndWaitForHealthy(t *testing.T) {
+	t.Parallel()
+
+	options := &RunOptions{
+		Command:      []string{"-c", `sleep 2; touch /tmp/ready; sleep 60`},
+		Entrypoint:   "sh",
+		Remove:       true,
+		OtherOptions: []string{"-p", "8080"},
+	}
+	healthCheck := &HealthCheckOptions{Command: "test -f /tmp/ready", Interval: time.Second}
+
+	container := RunAndWaitForHealthy(t, "alpine:3.7", options, healthCheck, 30*time.Second)
+	defer Stop(t, []string{container.ID}, &StopOptions{Time: 1})
+
+	require.Equal(t, "healthy", container.Health.Status)
+	require.NotZero(t, container.GetExposedHostPort(8080))
+}
+
+func TestRunAndWaitForHealthyRemovesUnhealthyContainer(t *testing.T) {
+	t.Parallel()
+
+	options := &RunOptions{
+		Command:    []string{"-c", `sleep 60`},
+		Entrypoint: "sh",
+		Name:       "terratest-unhealthy-" + strings.ToLower(random.UniqueId()),
+	}
+	healthCheck := &HealthCheckOptions{Command: "false", Interval: time.Second, Retries: 1}
+
+	_, err := RunAndWaitForHealthyE(t, "alpine:3.7", options, healthCheck, 5*time.Second)
+	require.Error(t, err)
+
+	_, err = InspectE(t, options.Name)
+	require.Error(t, err)
+}
+
+func TestFormatHealthCheckArgs(t *testing.T) {
+	t.Parallel()
+
+	require.Empty(t, formatHealthCheckArgs(nil))
+	require.Equal(t,
+		[]string{"--health-cmd", "pg_isready", "--health-interval", "1s", "--health-start-period", "5s", "--health-retries", "3"},
+		formatHealthCheckArgs(&HealthCheckOptions{Command: "pg_isready", Interval: time.Second, StartPeriod: 5 * time.Second, Retries: 3}),
+	)
+}
+
+func TestCheckContainerHealthy(t *testing.T) {
+	t.Parallel()
+
+	container := &ContainerInspect{ID: "abc", Running: true, Health: HealthCheck{Status: "healthy"}}
+	require.NoError(t, checkContainerHealthy(container))
+
+	container.Health = HealthCheck{Status: "starting", Log: []HealthLog{{ExitCode: 1, Output: "no response"}}}
+	require.ErrorContains(t, checkContainerHealthy(container), "no response")
+
+	container.Health = HealthCheck{}
+	require.Error(t, checkContainerHealthy(container))
+
+	container = &ContainerInspect{ID: "abc", Status: "exited", ExitCode: 1}
+	require.Error(t, checkContainerHealthy(container))
+}
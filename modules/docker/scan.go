@@ -0,0 +1,160 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// Severities of the vulnerabilities reported by the scanner.
+const (
+	SeverityUnknown  = "UNKNOWN"
+	SeverityLow      = "LOW"
+	SeverityMedium   = "MEDIUM"
+	SeverityHigh     = "HIGH"
+	SeverityCritical = "CRITICAL"
+)
+
+// ScanOptions defines options that can be passed to the 'trivy image' command.
+type ScanOptions struct {
+	// Severities of the vulnerabilities to report. Empty means all of them.
+	Severities []string
+
+	// If set to true, don't report the vulnerabilities that have no fix yet
+	IgnoreUnfixed bool
+
+	// Custom CLI options that will be passed as-is to the 'trivy image' command (e.g. --ignorefile).
+	OtherOptions []string
+
+	// Set a logger that should be used. See the logger package for more info.
+	Logger *logger.Logger
+}
+
+// Vulnerability is a vulnerability found in a package of an image.
+type Vulnerability struct {
+	ID               string
+	PackageName      string
+	InstalledVersion string
+	FixedVersion     string
+	Severity         string
+	Title            string
+
+	// Target is the part of the image the package was found in, e.g. the OS packages or a lock file of the application
+	Target string
+}
+
+// ScanResult is the result of the scan of an image.
+type ScanResult struct {
+	Vulnerabilities []Vulnerability
+
+	// Number of vulnerabilities of each severity
+	SeverityCounts map[string]int
+}
+
+// ScanImage runs trivy to scan the given image for vulnerabilities and returns the findings. This will fail the test
+// if there are any errors.
+func ScanImage(t testing.TestingT, image string, options *ScanOptions) *ScanResult {
+	result, err := ScanImageE(t, image, options)
+	require.NoError(t, err)
+	return result
+}
+
+// ScanImageE runs trivy to scan the given image for vulnerabilities and returns the findings. trivy must be installed.
+// The image is looked up in the docker daemon first, so images that were just built can be scanned without pushing
+// them.
+func ScanImageE(t testing.TestingT, image string, options *ScanOptions) (*ScanResult, error) {
+	options.Logger.Logf(t, "Running 'trivy image' on image '%s'", image)
+
+	cmd := shell.Command{
+		Command: "trivy",
+		Args:    formatTrivyArgs(image, options),
+		// The report is parsed and returned, don't print it.
+		Logger: logger.Discard,
+	}
+	out, err := shell.RunCommandAndGetStdOutE(t, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return parseTrivyReport(out)
+}
+
+// AssertNoCriticalVulnerabilities checks that the scan found no critical vulnerability. This will fail the test if
+// there is one.
+func AssertNoCriticalVulnerabilities(t testing.TestingT, result *ScanResult) {
+	require.NoError(t, AssertNoCriticalVulnerabilitiesE(result))
+}
+
+// AssertNoCriticalVulnerabilitiesE checks that the scan found no critical vulnerability, and returns an error listing
+// them if it did.
+func AssertNoCriticalVulnerabilitiesE(result *ScanResult) error {
+	critical := []string{}
+	for _, vulnerability := range result.Vulnerabilities {
+		if vulnerability.Severity == SeverityCritical {
+			critical = append(critical, fmt.Sprintf("%s in %s %s (fixed in %q)", vulnerability.ID, vulnerability.PackageName, vulnerability.InstalledVersion, vulnerability.FixedVersion))
+		}
+	}
+	if len(critical) > 0 {
+		return fmt.Errorf("found %d critical vulnerabilities: %s", len(critical), strings.Join(critical, ", "))
+	}
+	return nil
+}
+
+// formatTrivyArgs formats the arguments for the 'trivy image' command.
+func formatTrivyArgs(image string, options *ScanOptions) []string {
+	args := []string{"image", "--format", "json", "--quiet"}
+
+	if len(options.Severities) > 0 {
+		args = append(args, "--severity", strings.Join(options.Severities, ","))
+	}
+
+	if options.IgnoreUnfixed {
+		args = append(args, "--ignore-unfixed")
+	}
+
+	args = append(args, options.OtherOptions...)
+
+	args = append(args, image)
+	return args
+}
+
+// parseTrivyReport parses the JSON report of trivy.
+func parseTrivyReport(output string) (*ScanResult, error) {
+	var report struct {
+		Results []struct {
+			Target          string
+			Vulnerabilities []struct {
+				VulnerabilityID  string
+				PkgName          string
+				InstalledVersion string
+				FixedVersion     string
+				Severity         string
+				Title            string
+			}
+		}
+	}
+	if err := json.Unmarshal([]byte(output), &report); err != nil {
+		return nil, err
+	}
+
+	result := &ScanResult{Vulnerabilities: []Vulnerability{}, SeverityCounts: map[string]int{}}
+	for _, target := range report.Results {
+		for _, vulnerability := range target.Vulnerabilities {
+			result.Vulnerabilities = append(result.Vulnerabilities, Vulnerability{
+				ID:               vulnerability.VulnerabilityID,
+				PackageName:      vulnerability.PkgName,
+				InstalledVersion: vulnerability.InstalledVersion,
+				FixedVersion:     vulnerability.FixedVersion,
+				Severity:         vulnerability.Severity,
+				Title:            vulnerability.Title,
+				Target:           target.Target,
+			})
+			result.SeverityCounts[vulnerability.Severity]++
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,58 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const exampleTrivyReport = `{
+  "SchemaVersion": 2,
+  "ArtifactName": "app:v1",
+  "Results": [
+    {
+      "Target": "app:v1 (alpine 3.7.3)",
+      "Class": "os-pkgs",
+      "Vulnerabilities": [
+        {"VulnerabilityID": "CVE-2019-14697", "PkgName": "musl", "InstalledVersion": "1.1.18-r3", "FixedVersion": "1.1.18-r4", "Severity": "CRITICAL", "Title": "musl: x87 floating-point stack adjustment imbalance"},
+        {"VulnerabilityID": "CVE-2018-0732", "PkgName": "libssl1.0", "InstalledVersion": "1.0.2o-r0", "FixedVersion": "1.0.2p-r0", "Severity": "HIGH"}
+      ]
+    },
+    {"Target": "app/package-lock.json", "Class": "lang-pkgs"},
+    {
+      "Target": "app/go.sum",
+      "Class": "lang-pkgs",
+      "Vulnerabilities": [
+        {"VulnerabilityID": "CVE-2023-39325", "PkgName": "golang.org/x/net", "InstalledVersion": "v0.15.0", "FixedVersion": "0.17.0", "Severity": "HIGH"}
+      ]
+    }
+  ]
+}`
+
+func TestParseTrivyReport(t *testing.T) {
+	t.Parallel()
+
+	result, err := parseTrivyReport(exampleTrivyReport)
+	require.NoError(t, err)
+	require.Len(t, result.Vulnerabilities, 3)
+	require.Equal(t, map[string]int{SeverityCritical: 1, SeverityHigh: 2}, result.SeverityCounts)
+	require.Equal(t, "app/go.sum", result.Vulnerabilities[2].Target)
+	require.Equal(t, "musl", result.Vulnerabilities[0].PackageName)
+
+	require.ErrorContains(t, AssertNoCriticalVulnerabilitiesE(result), "CVE-2019-14697")
+
+	result, err = parseTrivyReport(`{"SchemaVersion": 2, "ArtifactName": "app:v2"}`)
+	require.NoError(t, err)
+	require.Empty(t, result.Vulnerabilities)
+	require.NoError(t, AssertNoCriticalVulnerabilitiesE(result))
+}
+
+func TestFormatTrivyArgs(t *testing.T) {
+	t.Parallel()
+
+	options := &ScanOptions{Severities: []string{SeverityHigh, SeverityCritical}, IgnoreUnfixed: true}
+	require.Equal(t,
+		[]string{"image", "--format", "json", "--quiet", "--severity", "HIGH,CRITICAL", "--ignore-unfixed", "app:v1"},
+		formatTrivyArgs("app:v1", options),
+	)
+}
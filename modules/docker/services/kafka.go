@@ -0,0 +1,68 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	kafkaImage = "apache/kafka:3.7.0"
+	kafkaPort  = 9092
+)
+
+// Kafka is a running single node Kafka cluster.
+type Kafka struct {
+	*Service
+}
+
+// BootstrapServers returns the address of the broker to connect to the cluster from the host.
+func (kafka *Kafka) BootstrapServers() string {
+	return kafka.Address(kafkaPort)
+}
+
+// StartKafka starts a single node Kafka cluster and waits until the broker is started. This will fail the test if
+// there is an error.
+func StartKafka(t testing.TestingT, options *Options) *Kafka {
+	kafka, err := StartKafkaE(t, options)
+	require.NoError(t, err)
+	return kafka
+}
+
+// StartKafkaE starts a single node Kafka cluster in KRaft mode, without ZooKeeper, and waits until the broker is
+// started. The broker advertises its published port on localhost, so it can only be reached from the host.
+func StartKafkaE(t testing.TestingT, options *Options) (*Kafka, error) {
+	// The advertised listener must be the published port, so it is chosen before starting the container
+	hostPort, err := getAvailablePortE()
+	if err != nil {
+		return nil, err
+	}
+	service, err := startE(t, options, containerSpec{
+		image:      kafkaImage,
+		env:        formatKafkaEnv(hostPort),
+		fixedPorts: map[uint16]uint16{kafkaPort: hostPort},
+		logMessage: `Kafka Server started`,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Kafka{Service: service}, nil
+}
+
+// formatKafkaEnv returns the environment of a single node KRaft cluster advertising the given host port.
+func formatKafkaEnv(hostPort uint16) []string {
+	return []string{
+		"KAFKA_NODE_ID=1",
+		"KAFKA_PROCESS_ROLES=broker,controller",
+		fmt.Sprintf("KAFKA_LISTENERS=PLAINTEXT://:%d,CONTROLLER://:9093", kafkaPort),
+		fmt.Sprintf("KAFKA_ADVERTISED_LISTENERS=PLAINTEXT://localhost:%d", hostPort),
+		"KAFKA_CONTROLLER_LISTENER_NAMES=CONTROLLER",
+		"KAFKA_LISTENER_SECURITY_PROTOCOL_MAP=CONTROLLER:PLAINTEXT,PLAINTEXT:PLAINTEXT",
+		"KAFKA_CONTROLLER_QUORUM_VOTERS=1@localhost:9093",
+		"KAFKA_OFFSETS_TOPIC_REPLICATION_FACTOR=1",
+		"KAFKA_TRANSACTION_STATE_LOG_REPLICATION_FACTOR=1",
+		"KAFKA_TRANSACTION_STATE_LOG_MIN_ISR=1",
+		"KAFKA_GROUP_INITIAL_REBALANCE_DELAY_MS=0",
+	}
+}
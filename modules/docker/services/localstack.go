@@ -0,0 +1,52 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	localStackImage = "localstack/localstack:3.4"
+	localStackPort  = 4566
+)
+
+// LocalStack is a running LocalStack emulator of AWS services.
+type LocalStack struct {
+	*Service
+}
+
+// Endpoint returns the URL of the emulated AWS APIs, to use as the endpoint of the AWS SDK clients (e.g.
+// http://localhost:45660).
+func (localStack *LocalStack) Endpoint() string {
+	return fmt.Sprintf("http://%s", localStack.Address(localStackPort))
+}
+
+// StartLocalStack starts a LocalStack emulator of the given AWS services (e.g. s3, sqs), or all of them if none is
+// given, and waits until it is ready. This will fail the test if there is an error.
+func StartLocalStack(t testing.TestingT, options *Options, awsServices ...string) *LocalStack {
+	localStack, err := StartLocalStackE(t, options, awsServices...)
+	require.NoError(t, err)
+	return localStack
+}
+
+// StartLocalStackE starts a LocalStack emulator of the given AWS services (e.g. s3, sqs), or all of them if none is
+// given, and waits until it is ready. Any credentials are accepted by the emulator.
+func StartLocalStackE(t testing.TestingT, options *Options, awsServices ...string) (*LocalStack, error) {
+	env := []string{}
+	if len(awsServices) > 0 {
+		env = append(env, "SERVICES="+strings.Join(awsServices, ","))
+	}
+	service, err := startE(t, options, containerSpec{
+		image:      localStackImage,
+		env:        env,
+		ports:      []uint16{localStackPort},
+		logMessage: `^Ready\.$`,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &LocalStack{Service: service}, nil
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/gruntwork-io/terratest/modules/docker"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	mysqlImage = "mysql:8.4"
+	mysqlPort  = 3306
+)
+
+// MySQL is a running MySQL server.
+type MySQL struct {
+	*Service
+	User         string
+	Password     string
+	RootPassword string
+	Database     string
+}
+
+// DSN returns the data source name to connect to the database of the server from the host with the
+// github.com/go-sql-driver/mysql driver (e.g. terratest:...@tcp(localhost:33061)/terratest).
+func (mysql *MySQL) DSN() string {
+	return fmt.Sprintf("%s:%s@tcp(%s)/%s", mysql.User, mysql.Password, mysql.Address(mysqlPort), mysql.Database)
+}
+
+// StartMySQL starts a MySQL server and waits until it accepts connections. This will fail the test if there is an
+// error.
+func StartMySQL(t testing.TestingT, options *Options) *MySQL {
+	mysql, err := StartMySQLE(t, options)
+	require.NoError(t, err)
+	return mysql
+}
+
+// StartMySQLE starts a MySQL server, with a terratest database and a terratest user with a random password, and waits
+// until it accepts TCP connections.
+func StartMySQLE(t testing.TestingT, options *Options) (*MySQL, error) {
+	mysql := &MySQL{User: "terratest", Password: randomPassword(), RootPassword: randomPassword(), Database: "terratest"}
+	service, err := startE(t, options, containerSpec{
+		image: mysqlImage,
+		env: []string{
+			"MYSQL_USER=" + mysql.User,
+			"MYSQL_PASSWORD=" + mysql.Password,
+			"MYSQL_ROOT_PASSWORD=" + mysql.RootPassword,
+			"MYSQL_DATABASE=" + mysql.Database,
+		},
+		ports: []uint16{mysqlPort},
+		// The initialization server doesn't listen on TCP, so checking over TCP waits for the final server
+		healthCheck: &docker.HealthCheckOptions{
+			Command:  fmt.Sprintf("mysqladmin ping -h 127.0.0.1 -u %s --password=%s", mysql.User, mysql.Password),
+			Interval: healthCheckInterval,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	mysql.Service = service
+	return mysql, nil
+}
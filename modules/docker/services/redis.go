@@ -0,0 +1,42 @@
+package services
+
+import (
+	"github.com/gruntwork-io/terratest/modules/docker"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	redisImage = "redis:7-alpine"
+	redisPort  = 6379
+)
+
+// Redis is a running Redis server.
+type Redis struct {
+	*Service
+}
+
+// Addr returns the host:port address to connect to the server from the host.
+func (redis *Redis) Addr() string {
+	return redis.Address(redisPort)
+}
+
+// StartRedis starts a Redis server and waits until it answers pings. This will fail the test if there is an error.
+func StartRedis(t testing.TestingT, options *Options) *Redis {
+	redis, err := StartRedisE(t, options)
+	require.NoError(t, err)
+	return redis
+}
+
+// StartRedisE starts a Redis server, without authentication, and waits until it answers pings.
+func StartRedisE(t testing.TestingT, options *Options) (*Redis, error) {
+	service, err := startE(t, options, containerSpec{
+		image:       redisImage,
+		ports:       []uint16{redisPort},
+		healthCheck: &docker.HealthCheckOptions{Command: "redis-cli ping | grep PONG", Interval: healthCheckInterval},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Redis{Service: service}, nil
+}
@@ -0,0 +1,199 @@
+// Package services starts throwaway service dependencies, such as databases, message brokers and cloud emulators, in
+// docker containers for the duration of a test, and waits for them to be ready, so test suites can provision their own
+// lightweight dependencies.
+package services
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/docker"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// defaultWaitTimeout is the time to wait for a service to be ready, unless set in the options.
+	defaultWaitTimeout = 2 * time.Minute
+
+	// healthCheckInterval is the time between the healthchecks of the services, shorter than the default of Docker
+	// so the tests don't wait longer than needed.
+	healthCheckInterval = 1 * time.Second
+)
+
+// Options defines the container of a service.
+type Options struct {
+	// The image to run. Defaults to a pinned version of the official image of the service.
+	Image string
+
+	// The name of the container. Defaults to a unique name starting with terratest-.
+	Name string
+
+	// Additional environment variables to set in the container, in the format KEY=value.
+	EnvironmentVariables []string
+
+	// The time to wait for the service to be ready. Defaults to 2 minutes.
+	WaitTimeout time.Duration
+
+	// Custom CLI options that will be passed as-is to the 'docker run' command, e.g. to attach the container to a
+	// network.
+	OtherOptions []string
+
+	// Set a logger that should be used. See the logger package for more info.
+	Logger *logger.Logger
+}
+
+// Service is a running service container. Use defer service.Stop(t) to make sure it is removed at the end of the test.
+type Service struct {
+	ContainerID string
+	Name        string
+
+	// The host the ports of the service are published on.
+	Host string
+
+	// The host ports the ports of the container are published on, by container port.
+	Ports map[uint16]uint16
+
+	logger *logger.Logger
+}
+
+// HostPort returns the host port the given port of the container is published on, or 0 if it isn't published.
+func (service *Service) HostPort(containerPort uint16) uint16 {
+	return service.Ports[containerPort]
+}
+
+// Address returns the host:port address to reach the given port of the container from the host.
+func (service *Service) Address(containerPort uint16) string {
+	return net.JoinHostPort(service.Host, fmt.Sprint(service.HostPort(containerPort)))
+}
+
+// Logs returns the logs of the container. This will fail the test if there is an error.
+func (service *Service) Logs(t testing.TestingT) string {
+	return docker.GetContainerLogsSince(t, service.ContainerID, time.Time{})
+}
+
+// Stop removes the container of the service, along with its anonymous volumes. This will fail the test if there is an
+// error.
+func (service *Service) Stop(t testing.TestingT) {
+	require.NoError(t, service.StopE(t))
+}
+
+// StopE removes the container of the service, along with its anonymous volumes.
+func (service *Service) StopE(t testing.TestingT) error {
+	service.logger.Logf(t, "Removing container %s", service.Name)
+	return shell.RunCommandE(t, shell.Command{
+		Command: "docker",
+		Args:    []string{"rm", "--force", "--volumes", service.ContainerID},
+		Logger:  service.logger,
+	})
+}
+
+// containerSpec defines how to run the container of a service and how to know it is ready.
+type containerSpec struct {
+	image string
+	env   []string
+
+	// The container ports to publish on random host ports, and the ones to publish on fixed host ports.
+	ports      []uint16
+	fixedPorts map[uint16]uint16
+
+	// The service is ready when the healthcheck reports healthy or, if there is none, when a line of the logs matches
+	// the log message regex.
+	healthCheck *docker.HealthCheckOptions
+	logMessage  string
+}
+
+// startE runs the container of a service and waits until it is ready. If the service isn't ready in time, the
+// container is removed.
+func startE(t testing.TestingT, options *Options, spec containerSpec) (*Service, error) {
+	runOptions := formatRunOptions(options, spec)
+	image := spec.image
+	if options.Image != "" {
+		image = options.Image
+	}
+	timeout := options.WaitTimeout
+	if timeout == 0 {
+		timeout = defaultWaitTimeout
+	}
+
+	options.Logger.Logf(t, "Starting container %s of image %s", runOptions.Name, image)
+	service := &Service{Name: runOptions.Name, Host: "localhost", logger: options.Logger}
+
+	var container *docker.ContainerInspect
+	var err error
+	if spec.healthCheck != nil {
+		container, err = docker.RunAndWaitForHealthyE(t, image, runOptions, spec.healthCheck, timeout)
+		if container != nil {
+			service.ContainerID = container.ID
+		}
+	} else {
+		service.ContainerID, err = docker.RunAndGetIDE(t, image, runOptions)
+		if err == nil {
+			_, err = docker.WaitForLogMessageE(t, service.ContainerID, spec.logMessage, timeout)
+		}
+		if err == nil {
+			container, err = docker.InspectE(t, service.ContainerID)
+		}
+	}
+	if err != nil {
+		if service.ContainerID != "" {
+			if stopErr := service.StopE(t); stopErr != nil {
+				options.Logger.Logf(t, "Error removing container %s after failed start: %s", service.Name, stopErr)
+			}
+		}
+		return nil, err
+	}
+
+	service.Ports = map[uint16]uint16{}
+	for _, port := range container.Ports {
+		service.Ports[port.ContainerPort] = port.HostPort
+	}
+	return service, nil
+}
+
+// formatRunOptions returns the options to run the container of a service, with its ports published on the loopback
+// interface only.
+func formatRunOptions(options *Options, spec containerSpec) *docker.RunOptions {
+	name := options.Name
+	if name == "" {
+		name = fmt.Sprintf("terratest-%s", strings.ToLower(random.UniqueId()))
+	}
+
+	otherOptions := []string{}
+	for _, port := range spec.ports {
+		otherOptions = append(otherOptions, "--publish", fmt.Sprintf("127.0.0.1::%d", port))
+	}
+	for containerPort, hostPort := range spec.fixedPorts {
+		otherOptions = append(otherOptions, "--publish", fmt.Sprintf("127.0.0.1:%d:%d", hostPort, containerPort))
+	}
+	otherOptions = append(otherOptions, options.OtherOptions...)
+
+	return &docker.RunOptions{
+		Detach:               true,
+		Name:                 name,
+		EnvironmentVariables: append(append([]string{}, spec.env...), options.EnvironmentVariables...),
+		OtherOptions:         otherOptions,
+		Logger:               options.Logger,
+	}
+}
+
+// getAvailablePortE returns a port of the loopback interface that is free, for the services that must know their
+// host port before they start.
+func getAvailablePortE() (uint16, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return uint16(listener.Addr().(*net.TCPAddr).Port), nil
+}
+
+// randomPassword returns a random alphanumeric password, which doesn't need escaping in connection strings.
+func randomPassword() string {
+	return random.UniqueId() + random.UniqueId()
+}
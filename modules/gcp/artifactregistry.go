@@ -0,0 +1,202 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/artifactregistry/v1"
+)
+
+// GetArtifactRegistryRepository gets the given Artifact Registry repository.
+func GetArtifactRegistryRepository(t testing.TestingT, projectID string, location string, repositoryName string) *artifactregistry.Repository {
+	repository, err := GetArtifactRegistryRepositoryE(t, projectID, location, repositoryName)
+	require.NoError(t, err)
+	return repository
+}
+
+// GetArtifactRegistryRepositoryE gets the given Artifact Registry repository.
+func GetArtifactRegistryRepositoryE(t testing.TestingT, projectID string, location string, repositoryName string) (*artifactregistry.Repository, error) {
+	logger.Default.Logf(t, "Getting Artifact Registry repository %s in %s", repositoryName, location)
+
+	service, err := NewArtifactRegistryServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	name := artifactRegistryRepositoryResourceName(projectID, location, repositoryName)
+	repository, err := service.Projects.Locations.Repositories.Get(name).Context(context.Background()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("Repositories.Get(%s) got error: %w", name, err)
+	}
+
+	return repository, nil
+}
+
+// ArtifactRegistryRepositoryExists checks if the given Artifact Registry repository exists.
+func ArtifactRegistryRepositoryExists(t testing.TestingT, projectID string, location string, repositoryName string) bool {
+	exists, err := ArtifactRegistryRepositoryExistsE(t, projectID, location, repositoryName)
+	require.NoError(t, err)
+	return exists
+}
+
+// ArtifactRegistryRepositoryExistsE checks if the given Artifact Registry repository exists.
+func ArtifactRegistryRepositoryExistsE(t testing.TestingT, projectID string, location string, repositoryName string) (bool, error) {
+	_, err := GetArtifactRegistryRepositoryE(t, projectID, location, repositoryName)
+	if err == nil {
+		return true, nil
+	}
+	if isNotFoundError(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// AssertArtifactRegistryRepositoryFormat checks that the given Artifact Registry repository exists and stores the
+// given format of packages (e.g. DOCKER, MAVEN, NPM, PYTHON).
+func AssertArtifactRegistryRepositoryFormat(t testing.TestingT, projectID string, location string, repositoryName string, format string) {
+	require.NoError(t, AssertArtifactRegistryRepositoryFormatE(t, projectID, location, repositoryName, format))
+}
+
+// AssertArtifactRegistryRepositoryFormatE checks that the given Artifact Registry repository exists and stores the
+// given format of packages (e.g. DOCKER, MAVEN, NPM, PYTHON).
+func AssertArtifactRegistryRepositoryFormatE(t testing.TestingT, projectID string, location string, repositoryName string, format string) error {
+	repository, err := GetArtifactRegistryRepositoryE(t, projectID, location, repositoryName)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(repository.Format, format) {
+		return fmt.Errorf("Artifact Registry repository %s has format %s, expected %s", repositoryName, repository.Format, format)
+	}
+	return nil
+}
+
+// ListDockerImages lists all the images (one per digest) of the given Docker repository of Artifact Registry.
+func ListDockerImages(t testing.TestingT, projectID string, location string, repositoryName string) []*artifactregistry.DockerImage {
+	images, err := ListDockerImagesE(t, projectID, location, repositoryName)
+	require.NoError(t, err)
+	return images
+}
+
+// ListDockerImagesE lists all the images (one per digest) of the given Docker repository of Artifact Registry.
+func ListDockerImagesE(t testing.TestingT, projectID string, location string, repositoryName string) ([]*artifactregistry.DockerImage, error) {
+	logger.Default.Logf(t, "Listing Docker images of Artifact Registry repository %s in %s", repositoryName, location)
+
+	service, err := NewArtifactRegistryServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	parent := artifactRegistryRepositoryResourceName(projectID, location, repositoryName)
+	images := []*artifactregistry.DockerImage{}
+	err = service.Projects.Locations.Repositories.DockerImages.List(parent).Pages(context.Background(), func(page *artifactregistry.ListDockerImagesResponse) error {
+		images = append(images, page.DockerImages...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("DockerImages.List(%s) got error: %v", parent, err)
+	}
+
+	return images, nil
+}
+
+// ListImageTags lists the tags of the given image (e.g. my-app or team/my-app) of the Docker repository of Artifact
+// Registry.
+func ListImageTags(t testing.TestingT, projectID string, location string, repositoryName string, imageName string) []string {
+	tags, err := ListImageTagsE(t, projectID, location, repositoryName, imageName)
+	require.NoError(t, err)
+	return tags
+}
+
+// ListImageTagsE lists the tags of the given image (e.g. my-app or team/my-app) of the Docker repository of Artifact
+// Registry.
+func ListImageTagsE(t testing.TestingT, projectID string, location string, repositoryName string, imageName string) ([]string, error) {
+	images, err := ListDockerImagesE(t, projectID, location, repositoryName)
+	if err != nil {
+		return nil, err
+	}
+	return getDockerImageTags(images, repositoryName, imageName), nil
+}
+
+// AssertImageExists checks that the given image of the Docker repository of Artifact Registry has the given tag.
+func AssertImageExists(t testing.TestingT, projectID string, location string, repositoryName string, imageName string, tag string) {
+	require.NoError(t, AssertImageExistsE(t, projectID, location, repositoryName, imageName, tag))
+}
+
+// AssertImageExistsE checks that the given image of the Docker repository of Artifact Registry has the given tag.
+func AssertImageExistsE(t testing.TestingT, projectID string, location string, repositoryName string, imageName string, tag string) error {
+	tags, err := ListImageTagsE(t, projectID, location, repositoryName, imageName)
+	if err != nil {
+		return err
+	}
+	for _, imageTag := range tags {
+		if imageTag == tag {
+			return nil
+		}
+	}
+	return fmt.Errorf("image %s:%s not found in Artifact Registry repository %s", imageName, tag, repositoryName)
+}
+
+// AssertArtifactRegistryCleanupPolicy checks that the given Artifact Registry repository has a cleanup policy with the
+// given ID and action (DELETE or KEEP), and that cleanup policies are enforced rather than run in dry run mode.
+func AssertArtifactRegistryCleanupPolicy(t testing.TestingT, projectID string, location string, repositoryName string, policyID string, action string) {
+	require.NoError(t, AssertArtifactRegistryCleanupPolicyE(t, projectID, location, repositoryName, policyID, action))
+}
+
+// AssertArtifactRegistryCleanupPolicyE checks that the given Artifact Registry repository has a cleanup policy with the
+// given ID and action (DELETE or KEEP), and that cleanup policies are enforced rather than run in dry run mode.
+func AssertArtifactRegistryCleanupPolicyE(t testing.TestingT, projectID string, location string, repositoryName string, policyID string, action string) error {
+	repository, err := GetArtifactRegistryRepositoryE(t, projectID, location, repositoryName)
+	if err != nil {
+		return err
+	}
+	return checkArtifactRegistryCleanupPolicy(repository, policyID, action)
+}
+
+// NewArtifactRegistryService creates a new Artifact Registry service, which is used to make Artifact Registry API calls.
+func NewArtifactRegistryService(t testing.TestingT) *artifactregistry.Service {
+	service, err := NewArtifactRegistryServiceE(t)
+	require.NoError(t, err)
+	return service
+}
+
+// NewArtifactRegistryServiceE creates a new Artifact Registry service, which is used to make Artifact Registry API
+// calls.
+func NewArtifactRegistryServiceE(t testing.TestingT) (*artifactregistry.Service, error) {
+	return artifactregistry.NewService(context.Background(), withOptions(t)...)
+}
+
+func checkArtifactRegistryCleanupPolicy(repository *artifactregistry.Repository, policyID string, action string) error {
+	policy, ok := repository.CleanupPolicies[policyID]
+	if !ok {
+		return fmt.Errorf("Artifact Registry repository %s has no cleanup policy %s", repository.Name, policyID)
+	}
+	if !strings.EqualFold(policy.Action, action) {
+		return fmt.Errorf("cleanup policy %s of Artifact Registry repository %s has action %s, expected %s", policyID, repository.Name, policy.Action, action)
+	}
+	if repository.CleanupPolicyDryRun {
+		return fmt.Errorf("cleanup policies of Artifact Registry repository %s run in dry run mode", repository.Name)
+	}
+	return nil
+}
+
+// getDockerImageTags returns the tags of all the digests of the image. The image URIs are formatted like
+// LOCATION-docker.pkg.dev/PROJECT_ID/REPOSITORY/IMAGE@sha256:DIGEST.
+func getDockerImageTags(images []*artifactregistry.DockerImage, repositoryName string, imageName string) []string {
+	tags := []string{}
+	for _, image := range images {
+		uri := strings.SplitN(image.Uri, "@", 2)[0]
+		parts := strings.SplitN(uri, "/", 4)
+		if len(parts) == 4 && parts[2] == repositoryName && parts[3] == imageName {
+			tags = append(tags, image.Tags...)
+		}
+	}
+	return tags
+}
+
+func artifactRegistryRepositoryResourceName(projectID string, location string, repositoryName string) string {
+	return fmt.Sprintf("projects/%s/locations/%s/repositories/%s", projectID, location, repositoryName)
+}
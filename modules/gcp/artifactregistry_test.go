@@ -0,0 +1,46 @@
+//go:build gcp
+// +build gcp
+
+// NOTE: We use build tags to differentiate GCP testing for better isolation and parallelism when executing our tests.
+
+package gcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/artifactregistry/v1"
+)
+
+func TestGetDockerImageTags(t *testing.T) {
+	t.Parallel()
+
+	images := []*artifactregistry.DockerImage{
+		{Uri: "us-docker.pkg.dev/my-project/apps/team/web@sha256:aaa", Tags: []string{"v1", "latest"}},
+		{Uri: "us-docker.pkg.dev/my-project/apps/team/web@sha256:bbb", Tags: []string{"v0"}},
+		{Uri: "us-docker.pkg.dev/my-project/apps/team/web-worker@sha256:ccc", Tags: []string{"v2"}},
+		{Uri: "us-docker.pkg.dev/my-project/apps/team/web@sha256:ddd"},
+	}
+
+	assert.ElementsMatch(t, []string{"v1", "latest", "v0"}, getDockerImageTags(images, "apps", "team/web"))
+	assert.Empty(t, getDockerImageTags(images, "other", "team/web"))
+}
+
+func TestCheckArtifactRegistryCleanupPolicy(t *testing.T) {
+	t.Parallel()
+
+	repository := &artifactregistry.Repository{
+		Name: "projects/my-project/locations/us/repositories/apps",
+		CleanupPolicies: map[string]artifactregistry.CleanupPolicy{
+			"delete-untagged": {Id: "delete-untagged", Action: "DELETE"},
+		},
+	}
+
+	require.NoError(t, checkArtifactRegistryCleanupPolicy(repository, "delete-untagged", "DELETE"))
+	require.Error(t, checkArtifactRegistryCleanupPolicy(repository, "delete-untagged", "KEEP"))
+	require.Error(t, checkArtifactRegistryCleanupPolicy(repository, "keep-releases", "KEEP"))
+
+	repository.CleanupPolicyDryRun = true
+	require.Error(t, checkArtifactRegistryCleanupPolicy(repository, "delete-untagged", "DELETE"))
+}
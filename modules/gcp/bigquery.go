@@ -0,0 +1,416 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	bigquery "google.golang.org/api/bigquery/v2"
+)
+
+// BigQueryRow is a row returned by a BigQuery query, as a map of column name to value. Values are converted to the
+// Go type matching the column type: int64 for INTEGER, float64 for FLOAT, bool for BOOLEAN, BigQueryRow for RECORD,
+// []interface{} for REPEATED columns, string for all the other types and nil for NULL.
+type BigQueryRow map[string]interface{}
+
+// bigQueryLegacyRoles maps the legacy dataset access roles to their IAM equivalents.
+var bigQueryLegacyRoles = map[string]string{
+	"READER": "roles/bigquery.dataViewer",
+	"WRITER": "roles/bigquery.dataEditor",
+	"OWNER":  "roles/bigquery.dataOwner",
+}
+
+// GetBigQueryDataset gets the given BigQuery dataset.
+func GetBigQueryDataset(t testing.TestingT, projectID string, datasetID string) *bigquery.Dataset {
+	dataset, err := GetBigQueryDatasetE(t, projectID, datasetID)
+	require.NoError(t, err)
+	return dataset
+}
+
+// GetBigQueryDatasetE gets the given BigQuery dataset.
+func GetBigQueryDatasetE(t testing.TestingT, projectID string, datasetID string) (*bigquery.Dataset, error) {
+	logger.Default.Logf(t, "Getting BigQuery dataset %s", datasetID)
+
+	service, err := NewBigQueryServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	dataset, err := service.Datasets.Get(projectID, datasetID).Context(context.Background()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("Datasets.Get(%s) got error: %v", datasetID, err)
+	}
+
+	return dataset, nil
+}
+
+// GetBigQueryTable gets the given BigQuery table.
+func GetBigQueryTable(t testing.TestingT, projectID string, datasetID string, tableID string) *bigquery.Table {
+	table, err := GetBigQueryTableE(t, projectID, datasetID, tableID)
+	require.NoError(t, err)
+	return table
+}
+
+// GetBigQueryTableE gets the given BigQuery table.
+func GetBigQueryTableE(t testing.TestingT, projectID string, datasetID string, tableID string) (*bigquery.Table, error) {
+	logger.Default.Logf(t, "Getting BigQuery table %s.%s", datasetID, tableID)
+
+	service, err := NewBigQueryServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	table, err := service.Tables.Get(projectID, datasetID, tableID).Context(context.Background()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("Tables.Get(%s.%s) got error: %v", datasetID, tableID, err)
+	}
+
+	return table, nil
+}
+
+// GetBigQueryTableSchema gets the schema of the given BigQuery table, as a map of column name to column type (e.g.
+// STRING or INTEGER). Nested columns are named with their full path, e.g. address.city.
+func GetBigQueryTableSchema(t testing.TestingT, projectID string, datasetID string, tableID string) map[string]string {
+	schema, err := GetBigQueryTableSchemaE(t, projectID, datasetID, tableID)
+	require.NoError(t, err)
+	return schema
+}
+
+// GetBigQueryTableSchemaE gets the schema of the given BigQuery table, as a map of column name to column type (e.g.
+// STRING or INTEGER). Nested columns are named with their full path, e.g. address.city.
+func GetBigQueryTableSchemaE(t testing.TestingT, projectID string, datasetID string, tableID string) (map[string]string, error) {
+	table, err := GetBigQueryTableE(t, projectID, datasetID, tableID)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := map[string]string{}
+	if table.Schema != nil {
+		flattenBigQuerySchema("", table.Schema.Fields, schema)
+	}
+	return schema, nil
+}
+
+// AssertBigQueryTableSchema checks that the given BigQuery table has all the expected columns, with the expected
+// types. Nested columns are named with their full path, e.g. address.city.
+func AssertBigQueryTableSchema(t testing.TestingT, projectID string, datasetID string, tableID string, expectedColumns map[string]string) {
+	require.NoError(t, AssertBigQueryTableSchemaE(t, projectID, datasetID, tableID, expectedColumns))
+}
+
+// AssertBigQueryTableSchemaE checks that the given BigQuery table has all the expected columns, with the expected
+// types. Nested columns are named with their full path, e.g. address.city.
+func AssertBigQueryTableSchemaE(t testing.TestingT, projectID string, datasetID string, tableID string, expectedColumns map[string]string) error {
+	schema, err := GetBigQueryTableSchemaE(t, projectID, datasetID, tableID)
+	if err != nil {
+		return err
+	}
+
+	for name, expectedType := range expectedColumns {
+		actualType, exists := schema[name]
+		if !exists {
+			return fmt.Errorf("table %s.%s has no column %s", datasetID, tableID, name)
+		}
+		if !strings.EqualFold(actualType, expectedType) {
+			return fmt.Errorf("column %s of table %s.%s has type %s, expected %s", name, datasetID, tableID, actualType, expectedType)
+		}
+	}
+	return nil
+}
+
+// AssertBigQueryDatasetAccess checks that the given member (a user or group email, a domain, a special group such as
+// projectReaders, or an IAM member such as serviceAccount:sa@project.iam.gserviceaccount.com) has the given role on
+// the dataset. Legacy roles (READER, WRITER, OWNER) and their IAM equivalents are considered the same.
+func AssertBigQueryDatasetAccess(t testing.TestingT, projectID string, datasetID string, role string, member string) {
+	require.NoError(t, AssertBigQueryDatasetAccessE(t, projectID, datasetID, role, member))
+}
+
+// AssertBigQueryDatasetAccessE checks that the given member (a user or group email, a domain, a special group such as
+// projectReaders, or an IAM member such as serviceAccount:sa@project.iam.gserviceaccount.com) has the given role on
+// the dataset. Legacy roles (READER, WRITER, OWNER) and their IAM equivalents are considered the same.
+func AssertBigQueryDatasetAccessE(t testing.TestingT, projectID string, datasetID string, role string, member string) error {
+	dataset, err := GetBigQueryDatasetE(t, projectID, datasetID)
+	if err != nil {
+		return err
+	}
+	if !hasBigQueryDatasetAccess(dataset.Access, role, member) {
+		return fmt.Errorf("member %s does not have role %s on dataset %s", member, role, datasetID)
+	}
+	return nil
+}
+
+// RunQuery runs the given standard SQL query and returns all the resulting rows.
+func RunQuery(t testing.TestingT, projectID string, query string) []BigQueryRow {
+	rows, err := RunQueryE(t, projectID, query)
+	require.NoError(t, err)
+	return rows
+}
+
+// RunQueryE runs the given standard SQL query and returns all the resulting rows.
+func RunQueryE(t testing.TestingT, projectID string, query string) ([]BigQueryRow, error) {
+	logger.Default.Logf(t, "Running BigQuery query: %s", query)
+
+	service, err := NewBigQueryServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	useLegacySQL := false
+	req := &bigquery.QueryRequest{
+		Query:        query,
+		UseLegacySql: &useLegacySQL,
+	}
+	resp, err := service.Jobs.Query(projectID, req).Context(context.Background()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("Jobs.Query got error: %v", err)
+	}
+	if len(resp.Errors) > 0 {
+		return nil, fmt.Errorf("query failed: %s", resp.Errors[0].Message)
+	}
+
+	schema, tableRows, pageToken, complete := resp.Schema, resp.Rows, resp.PageToken, resp.JobComplete
+
+	// Wait for the query to complete and page through the remaining results
+	for !complete || pageToken != "" {
+		call := service.Jobs.GetQueryResults(projectID, resp.JobReference.JobId).Location(resp.JobReference.Location).Context(context.Background())
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		results, err := call.Do()
+		if err != nil {
+			return nil, fmt.Errorf("Jobs.GetQueryResults(%s) got error: %v", resp.JobReference.JobId, err)
+		}
+		if len(results.Errors) > 0 {
+			return nil, fmt.Errorf("query failed: %s", results.Errors[0].Message)
+		}
+
+		complete = results.JobComplete
+		if complete {
+			schema = results.Schema
+			tableRows = append(tableRows, results.Rows...)
+			pageToken = results.PageToken
+		}
+	}
+
+	if schema == nil {
+		return []BigQueryRow{}, nil
+	}
+	return convertBigQueryRows(schema.Fields, tableRows)
+}
+
+// LoadTableFromGCS loads the given Cloud Storage objects (e.g. gs://bucket/data.csv) into the given table, using the
+// given source format (e.g. CSV or NEWLINE_DELIMITED_JSON) and detecting the schema automatically. Rows are appended
+// to the table if it exists. It blocks until the load job is done.
+func LoadTableFromGCS(t testing.TestingT, projectID string, datasetID string, tableID string, sourceURIs []string, sourceFormat string) {
+	require.NoError(t, LoadTableFromGCSE(t, projectID, datasetID, tableID, sourceURIs, sourceFormat))
+}
+
+// LoadTableFromGCSE loads the given Cloud Storage objects (e.g. gs://bucket/data.csv) into the given table, using the
+// given source format (e.g. CSV or NEWLINE_DELIMITED_JSON) and detecting the schema automatically. Rows are appended
+// to the table if it exists. It blocks until the load job is done.
+func LoadTableFromGCSE(t testing.TestingT, projectID string, datasetID string, tableID string, sourceURIs []string, sourceFormat string) error {
+	logger.Default.Logf(t, "Loading %v into BigQuery table %s.%s", sourceURIs, datasetID, tableID)
+
+	service, err := NewBigQueryServiceE(t)
+	if err != nil {
+		return err
+	}
+
+	job := &bigquery.Job{
+		Configuration: &bigquery.JobConfiguration{
+			Load: &bigquery.JobConfigurationLoad{
+				SourceUris:   sourceURIs,
+				SourceFormat: sourceFormat,
+				Autodetect:   true,
+				DestinationTable: &bigquery.TableReference{
+					ProjectId: projectID,
+					DatasetId: datasetID,
+					TableId:   tableID,
+				},
+				WriteDisposition: "WRITE_APPEND",
+			},
+		},
+	}
+
+	job, err = service.Jobs.Insert(projectID, job).Context(context.Background()).Do()
+	if err != nil {
+		return fmt.Errorf("Jobs.Insert got error: %v", err)
+	}
+
+	return WaitForBigQueryJobE(t, projectID, job.JobReference.JobId, job.JobReference.Location, 60, 5*time.Second)
+}
+
+// WaitForBigQueryJob waits until the given BigQuery job is done, retrying the check for the specified amount of
+// times, sleeping for the provided duration between each try. It fails if the job failed.
+func WaitForBigQueryJob(t testing.TestingT, projectID string, jobID string, location string, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, WaitForBigQueryJobE(t, projectID, jobID, location, retries, sleepBetweenRetries))
+}
+
+// WaitForBigQueryJobE waits until the given BigQuery job is done, retrying the check for the specified amount of
+// times, sleeping for the provided duration between each try. It returns an error if the job failed.
+func WaitForBigQueryJobE(t testing.TestingT, projectID string, jobID string, location string, retries int, sleepBetweenRetries time.Duration) error {
+	service, err := NewBigQueryServiceE(t)
+	if err != nil {
+		return err
+	}
+
+	description := fmt.Sprintf("Waiting for BigQuery job %s to be done", jobID)
+	var jobStatus *bigquery.JobStatus
+	message, err := retry.DoWithRetryE(t, description, retries, sleepBetweenRetries, func() (string, error) {
+		job, err := service.Jobs.Get(projectID, jobID).Location(location).Context(context.Background()).Do()
+		if err != nil {
+			return "", err
+		}
+		if job.Status == nil || job.Status.State != "DONE" {
+			return "", fmt.Errorf("BigQuery job %s is not done yet", jobID)
+		}
+		jobStatus = job.Status
+		return fmt.Sprintf("BigQuery job %s is done", jobID), nil
+	})
+	logger.Default.Logf(t, message)
+	if err != nil {
+		return err
+	}
+
+	if jobStatus.ErrorResult != nil {
+		return fmt.Errorf("BigQuery job %s failed: %s", jobID, jobStatus.ErrorResult.Message)
+	}
+	return nil
+}
+
+// NewBigQueryService creates a new BigQuery service, which is used to make BigQuery API calls.
+func NewBigQueryService(t testing.TestingT) *bigquery.Service {
+	service, err := NewBigQueryServiceE(t)
+	require.NoError(t, err)
+	return service
+}
+
+// NewBigQueryServiceE creates a new BigQuery service, which is used to make BigQuery API calls.
+func NewBigQueryServiceE(t testing.TestingT) (*bigquery.Service, error) {
+	return bigquery.NewService(context.Background(), withOptions(t)...)
+}
+
+func flattenBigQuerySchema(prefix string, fields []*bigquery.TableFieldSchema, schema map[string]string) {
+	for _, field := range fields {
+		name := prefix + field.Name
+		schema[name] = field.Type
+		if len(field.Fields) > 0 {
+			flattenBigQuerySchema(name+".", field.Fields, schema)
+		}
+	}
+}
+
+func hasBigQueryDatasetAccess(access []*bigquery.DatasetAccess, role string, member string) bool {
+	for _, entry := range access {
+		if normalizeBigQueryRole(entry.Role) != normalizeBigQueryRole(role) {
+			continue
+		}
+		for _, entryMember := range []string{entry.UserByEmail, entry.GroupByEmail, entry.Domain, entry.SpecialGroup, entry.IamMember} {
+			if entryMember != "" && strings.EqualFold(entryMember, member) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func normalizeBigQueryRole(role string) string {
+	if iamRole, isLegacy := bigQueryLegacyRoles[strings.ToUpper(role)]; isLegacy {
+		return iamRole
+	}
+	return role
+}
+
+func convertBigQueryRows(fields []*bigquery.TableFieldSchema, tableRows []*bigquery.TableRow) ([]BigQueryRow, error) {
+	rows := []BigQueryRow{}
+	for _, tableRow := range tableRows {
+		row, err := convertBigQueryRecord(fields, tableRow.F)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func convertBigQueryRecord(fields []*bigquery.TableFieldSchema, cells []*bigquery.TableCell) (BigQueryRow, error) {
+	if len(cells) != len(fields) {
+		return nil, fmt.Errorf("row has %d values, but the schema has %d columns", len(cells), len(fields))
+	}
+
+	row := BigQueryRow{}
+	for i, field := range fields {
+		value, err := convertBigQueryValue(field, cells[i].V)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %v", field.Name, err)
+		}
+		row[field.Name] = value
+	}
+	return row, nil
+}
+
+func convertBigQueryValue(field *bigquery.TableFieldSchema, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	if field.Mode == "REPEATED" {
+		// Repeated values are returned as a list of cells: [{"v": ...}, ...]
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected repeated value %v", value)
+		}
+		elementField := *field
+		elementField.Mode = "NULLABLE"
+		values := []interface{}{}
+		for _, item := range items {
+			cell, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("unexpected repeated value %v", item)
+			}
+			converted, err := convertBigQueryValue(&elementField, cell["v"])
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, converted)
+		}
+		return values, nil
+	}
+
+	switch field.Type {
+	case "RECORD", "STRUCT":
+		// Records are returned as a row: {"f": [{"v": ...}, ...]}
+		record, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected record value %v", value)
+		}
+		rawCells, _ := record["f"].([]interface{})
+		cells := []*bigquery.TableCell{}
+		for _, rawCell := range rawCells {
+			cell, _ := rawCell.(map[string]interface{})
+			cells = append(cells, &bigquery.TableCell{V: cell["v"]})
+		}
+		return convertBigQueryRecord(field.Fields, cells)
+	}
+
+	// All the scalar values are returned as strings
+	str, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected scalar value %v", value)
+	}
+	switch field.Type {
+	case "INTEGER", "INT64":
+		return strconv.ParseInt(str, 10, 64)
+	case "FLOAT", "FLOAT64":
+		return strconv.ParseFloat(str, 64)
+	case "BOOLEAN", "BOOL":
+		return strconv.ParseBool(str)
+	default:
+		return str, nil
+	}
+}
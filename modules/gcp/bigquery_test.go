@@ -0,0 +1,69 @@
+//go:build gcp
+// +build gcp
+
+// NOTE: We use build tags to differentiate GCP testing for better isolation and parallelism when executing our tests.
+
+package gcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bigquery "google.golang.org/api/bigquery/v2"
+)
+
+func TestConvertBigQueryRows(t *testing.T) {
+	t.Parallel()
+
+	fields := []*bigquery.TableFieldSchema{
+		{Name: "id", Type: "INTEGER"},
+		{Name: "name", Type: "STRING"},
+		{Name: "score", Type: "FLOAT"},
+		{Name: "active", Type: "BOOLEAN"},
+		{Name: "tags", Type: "STRING", Mode: "REPEATED"},
+		{Name: "address", Type: "RECORD", Fields: []*bigquery.TableFieldSchema{{Name: "city", Type: "STRING"}}},
+		{Name: "deleted_at", Type: "TIMESTAMP"},
+	}
+	tableRows := []*bigquery.TableRow{
+		{F: []*bigquery.TableCell{
+			{V: "1"},
+			{V: "alice"},
+			{V: "9.5"},
+			{V: "true"},
+			{V: []interface{}{map[string]interface{}{"v": "a"}, map[string]interface{}{"v": "b"}}},
+			{V: map[string]interface{}{"f": []interface{}{map[string]interface{}{"v": "Paris"}}}},
+			{V: nil},
+		}},
+	}
+
+	rows, err := convertBigQueryRows(fields, tableRows)
+	require.NoError(t, err)
+	assert.Equal(t, []BigQueryRow{{
+		"id":         int64(1),
+		"name":       "alice",
+		"score":      9.5,
+		"active":     true,
+		"tags":       []interface{}{"a", "b"},
+		"address":    BigQueryRow{"city": "Paris"},
+		"deleted_at": nil,
+	}}, rows)
+
+	schema := map[string]string{}
+	flattenBigQuerySchema("", fields, schema)
+	assert.Equal(t, "STRING", schema["address.city"])
+	assert.Equal(t, "INTEGER", schema["id"])
+}
+
+func TestHasBigQueryDatasetAccess(t *testing.T) {
+	t.Parallel()
+
+	access := []*bigquery.DatasetAccess{
+		{Role: "READER", GroupByEmail: "analysts@example.com"},
+		{Role: "roles/bigquery.dataEditor", IamMember: "serviceAccount:etl@my-project.iam.gserviceaccount.com"},
+	}
+
+	assert.True(t, hasBigQueryDatasetAccess(access, "roles/bigquery.dataViewer", "analysts@example.com"))
+	assert.True(t, hasBigQueryDatasetAccess(access, "WRITER", "serviceAccount:etl@my-project.iam.gserviceaccount.com"))
+	assert.False(t, hasBigQueryDatasetAccess(access, "OWNER", "analysts@example.com"))
+}
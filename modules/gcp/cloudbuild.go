@@ -3,12 +3,20 @@ package gcp
 import (
 	"context"
 	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
 
 	cloudbuild "cloud.google.com/go/cloudbuild/apiv1/v2"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
 	"github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/api/iterator"
 	cloudbuildpb "google.golang.org/genproto/googleapis/devtools/cloudbuild/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // CreateBuild creates a new build blocking until the operation is complete.
@@ -136,6 +144,223 @@ func GetBuildsForTriggerE(t testing.TestingT, projectID string, triggerID string
 	return filteredBuilds, nil
 }
 
+// RunTriggerAndWait runs the given Cloud Build trigger on the given branch, or on the branch of the trigger when empty,
+// blocking until the build is complete, and returns the build and its logs.
+func RunTriggerAndWait(t testing.TestingT, projectID string, triggerID string, branchName string) (*cloudbuildpb.Build, string) {
+	build, logs, err := RunTriggerAndWaitE(t, projectID, triggerID, branchName)
+	require.NoError(t, err)
+	return build, logs
+}
+
+// RunTriggerAndWaitE runs the given Cloud Build trigger on the given branch, or on the branch of the trigger when
+// empty, blocking until the build is complete, and returns the build and its logs. A build that runs but fails is not
+// an error: check the status of the returned build. The logs are read from the logs bucket of the build, so they are
+// empty for builds logging to Cloud Logging only.
+func RunTriggerAndWaitE(t testing.TestingT, projectID string, triggerID string, branchName string) (*cloudbuildpb.Build, string, error) {
+	logger.Default.Logf(t, "Running Cloud Build trigger %s", triggerID)
+
+	ctx := context.Background()
+
+	service, err := NewCloudBuildServiceE(t)
+	if err != nil {
+		return nil, "", err
+	}
+
+	req := &cloudbuildpb.RunBuildTriggerRequest{
+		ProjectId: projectID,
+		TriggerId: triggerID,
+	}
+	if branchName != "" {
+		req.Source = &cloudbuildpb.RepoSource{
+			ProjectId: projectID,
+			Revision:  &cloudbuildpb.RepoSource_BranchName{BranchName: branchName},
+		}
+	}
+
+	op, err := service.RunBuildTrigger(ctx, req)
+	if err != nil {
+		return nil, "", fmt.Errorf("RunTriggerAndWaitE.RunBuildTrigger(%s, %s) got error: %v", projectID, triggerID, err)
+	}
+
+	// The operation fails when the build fails, so get the build from the operation metadata rather than from the
+	// operation result. An error while the operation isn't done is a failure to get its status, e.g. a permission
+	// error, rather than a failed build: retry it when it is transient and return it otherwise.
+	description := fmt.Sprintf("Waiting for the build of trigger %s", triggerID)
+	maxRetries := 6
+	timeBetweenRetries := 10 * time.Second
+
+	_, err = retry.DoWithRetryE(t, description, maxRetries, timeBetweenRetries, func() (string, error) {
+		_, err := op.Wait(ctx)
+		if err == nil {
+			return "", nil
+		}
+		if op.Done() {
+			logger.Default.Logf(t, "Build of trigger %s did not succeed: %v", triggerID, err)
+			return "", nil
+		}
+		if isTransientOperationError(err) {
+			return "", err
+		}
+		return "", retry.FatalError{Underlying: err}
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("RunTriggerAndWaitE.Wait(%s, %s) got error: %v", projectID, triggerID, err)
+	}
+	metadata, err := op.Metadata()
+	if err != nil {
+		return nil, "", fmt.Errorf("RunTriggerAndWaitE.Metadata(%s, %s) got error: %v", projectID, triggerID, err)
+	}
+	if metadata == nil || metadata.GetBuild() == nil {
+		return nil, "", fmt.Errorf("no build found for the run of trigger %s", triggerID)
+	}
+
+	build, err := GetBuildE(t, projectID, metadata.GetBuild().GetId())
+	if err != nil {
+		return nil, "", err
+	}
+	logger.Default.Logf(t, "Build %s of trigger %s completed with status %s", build.GetId(), triggerID, build.GetStatus())
+
+	logs, err := GetBuildLogsE(t, build)
+	if err != nil {
+		return build, "", err
+	}
+	return build, logs, nil
+}
+
+// isTransientOperationError returns true if the given error from polling an operation is worth retrying.
+func isTransientOperationError(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
+
+// GetBuildLogs reads the logs of the given build from its logs bucket.
+func GetBuildLogs(t testing.TestingT, build *cloudbuildpb.Build) string {
+	logs, err := GetBuildLogsE(t, build)
+	require.NoError(t, err)
+	return logs
+}
+
+// GetBuildLogsE reads the logs of the given build from its logs bucket. Returns empty logs for builds logging to Cloud
+// Logging only, which have no logs bucket.
+func GetBuildLogsE(t testing.TestingT, build *cloudbuildpb.Build) (string, error) {
+	bucketName, objectPath := getBuildLogsObject(build)
+	if bucketName == "" {
+		return "", nil
+	}
+
+	reader, err := ReadBucketObjectE(t, bucketName, objectPath)
+	if err != nil {
+		return "", err
+	}
+	logs, err := io.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("GetBuildLogsE.ReadAll(%s) got error: %v", build.GetId(), err)
+	}
+	return string(logs), nil
+}
+
+// GetBuildTrigger gets the given Cloud Build trigger.
+func GetBuildTrigger(t testing.TestingT, projectID string, triggerID string) *cloudbuildpb.BuildTrigger {
+	trigger, err := GetBuildTriggerE(t, projectID, triggerID)
+	require.NoError(t, err)
+	return trigger
+}
+
+// GetBuildTriggerE gets the given Cloud Build trigger.
+func GetBuildTriggerE(t testing.TestingT, projectID string, triggerID string) (*cloudbuildpb.BuildTrigger, error) {
+	ctx := context.Background()
+
+	service, err := NewCloudBuildServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &cloudbuildpb.GetBuildTriggerRequest{
+		ProjectId: projectID,
+		TriggerId: triggerID,
+	}
+
+	resp, err := service.GetBuildTrigger(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("GetBuildTriggerE.GetBuildTrigger(%s, %s) got error: %v", projectID, triggerID, err)
+	}
+
+	return resp, nil
+}
+
+// AssertBuildTriggerConfig checks that the given Cloud Build trigger is enabled, builds the given build config file
+// (e.g. cloudbuild.yaml) and runs as the given service account email. An empty service account skips that check.
+func AssertBuildTriggerConfig(t testing.TestingT, projectID string, triggerID string, buildConfigFile string, serviceAccount string) {
+	require.NoError(t, AssertBuildTriggerConfigE(t, projectID, triggerID, buildConfigFile, serviceAccount))
+}
+
+// AssertBuildTriggerConfigE checks that the given Cloud Build trigger is enabled, builds the given build config file
+// (e.g. cloudbuild.yaml) and runs as the given service account email. An empty service account skips that check.
+func AssertBuildTriggerConfigE(t testing.TestingT, projectID string, triggerID string, buildConfigFile string, serviceAccount string) error {
+	trigger, err := GetBuildTriggerE(t, projectID, triggerID)
+	if err != nil {
+		return err
+	}
+	return checkBuildTriggerConfig(trigger, buildConfigFile, serviceAccount)
+}
+
+// AssertBuildTriggerSubstitution checks that the given Cloud Build trigger sets the substitution (e.g. _ENV) to the
+// expected value.
+func AssertBuildTriggerSubstitution(t testing.TestingT, projectID string, triggerID string, key string, expectedValue string) {
+	require.NoError(t, AssertBuildTriggerSubstitutionE(t, projectID, triggerID, key, expectedValue))
+}
+
+// AssertBuildTriggerSubstitutionE checks that the given Cloud Build trigger sets the substitution (e.g. _ENV) to the
+// expected value.
+func AssertBuildTriggerSubstitutionE(t testing.TestingT, projectID string, triggerID string, key string, expectedValue string) error {
+	trigger, err := GetBuildTriggerE(t, projectID, triggerID)
+	if err != nil {
+		return err
+	}
+	value, ok := trigger.GetSubstitutions()[key]
+	if !ok {
+		return fmt.Errorf("trigger %s has no substitution %s", trigger.GetName(), key)
+	}
+	if value != expectedValue {
+		return fmt.Errorf("substitution %s of trigger %s is %q, expected %q", key, trigger.GetName(), value, expectedValue)
+	}
+	return nil
+}
+
+func checkBuildTriggerConfig(trigger *cloudbuildpb.BuildTrigger, buildConfigFile string, serviceAccount string) error {
+	if trigger.GetDisabled() {
+		return fmt.Errorf("trigger %s is disabled", trigger.GetName())
+	}
+	if trigger.GetFilename() != buildConfigFile {
+		return fmt.Errorf("trigger %s builds %q, expected %q", trigger.GetName(), trigger.GetFilename(), buildConfigFile)
+	}
+	// The service account is formatted like projects/PROJECT_ID/serviceAccounts/EMAIL
+	if serviceAccount != "" && path.Base(trigger.GetServiceAccount()) != serviceAccount {
+		return fmt.Errorf("trigger %s runs as %q, expected %q", trigger.GetName(), trigger.GetServiceAccount(), serviceAccount)
+	}
+	return nil
+}
+
+// getBuildLogsObject returns the bucket and path of the logs of the build. The logs bucket is formatted like
+// gs://BUCKET or gs://BUCKET/PATH, and the logs are in the log-BUILD_ID.txt object.
+func getBuildLogsObject(build *cloudbuildpb.Build) (string, string) {
+	logsBucket := strings.TrimPrefix(build.GetLogsBucket(), "gs://")
+	if logsBucket == "" {
+		return "", ""
+	}
+
+	logsObject := fmt.Sprintf("log-%s.txt", build.GetId())
+	parts := strings.SplitN(logsBucket, "/", 2)
+	if len(parts) == 2 && parts[1] != "" {
+		return parts[0], strings.TrimSuffix(parts[1], "/") + "/" + logsObject
+	}
+	return parts[0], logsObject
+}
+
 // NewCloudBuildService creates a new Cloud Build service, which is used to make Cloud Build API calls.
 func NewCloudBuildService(t testing.TestingT) *cloudbuild.Client {
 	service, err := NewCloudBuildServiceE(t)
@@ -147,7 +372,7 @@ func NewCloudBuildService(t testing.TestingT) *cloudbuild.Client {
 func NewCloudBuildServiceE(t testing.TestingT) (*cloudbuild.Client, error) {
 	ctx := context.Background()
 
-	service, err := cloudbuild.NewClient(ctx, withOptions()...)
+	service, err := cloudbuild.NewClient(ctx, withOptions(t)...)
 	if err != nil {
 		return nil, err
 	}
@@ -17,6 +17,8 @@ import (
 	"github.com/gruntwork-io/terratest/modules/random"
 	"github.com/stretchr/testify/require"
 	cloudbuildpb "google.golang.org/genproto/googleapis/devtools/cloudbuild/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestCreateBuild(t *testing.T) {
@@ -116,3 +118,46 @@ MAINTAINER Rob Morgan (rob@gruntwork.io)
 	// return the compressed buffer
 	return bytes.NewReader(zbuf.Bytes())
 }
+
+func TestGetBuildLogsObject(t *testing.T) {
+	t.Parallel()
+
+	bucket, object := getBuildLogsObject(&cloudbuildpb.Build{Id: "1234", LogsBucket: "gs://123456.cloudbuild-logs.googleusercontent.com"})
+	require.Equal(t, "123456.cloudbuild-logs.googleusercontent.com", bucket)
+	require.Equal(t, "log-1234.txt", object)
+
+	bucket, object = getBuildLogsObject(&cloudbuildpb.Build{Id: "1234", LogsBucket: "gs://my-logs/ci/"})
+	require.Equal(t, "my-logs", bucket)
+	require.Equal(t, "ci/log-1234.txt", object)
+
+	bucket, _ = getBuildLogsObject(&cloudbuildpb.Build{Id: "1234"})
+	require.Empty(t, bucket)
+}
+
+func TestCheckBuildTriggerConfig(t *testing.T) {
+	t.Parallel()
+
+	trigger := &cloudbuildpb.BuildTrigger{
+		Name:           "deploy",
+		BuildTemplate:  &cloudbuildpb.BuildTrigger_Filename{Filename: "cloudbuild.yaml"},
+		ServiceAccount: "projects/my-project/serviceAccounts/builder@my-project.iam.gserviceaccount.com",
+	}
+
+	require.NoError(t, checkBuildTriggerConfig(trigger, "cloudbuild.yaml", "builder@my-project.iam.gserviceaccount.com"))
+	require.NoError(t, checkBuildTriggerConfig(trigger, "cloudbuild.yaml", ""))
+	require.Error(t, checkBuildTriggerConfig(trigger, "deploy.yaml", ""))
+	require.Error(t, checkBuildTriggerConfig(trigger, "cloudbuild.yaml", "other@my-project.iam.gserviceaccount.com"))
+
+	trigger.Disabled = true
+	require.Error(t, checkBuildTriggerConfig(trigger, "cloudbuild.yaml", ""))
+}
+
+func TestIsTransientOperationError(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, isTransientOperationError(status.Error(codes.Unavailable, "service unavailable")))
+	require.True(t, isTransientOperationError(status.Error(codes.DeadlineExceeded, "deadline exceeded")))
+	require.False(t, isTransientOperationError(status.Error(codes.PermissionDenied, "permission denied")))
+	require.False(t, isTransientOperationError(status.Error(codes.NotFound, "operation not found")))
+	require.False(t, isTransientOperationError(fmt.Errorf("not a status error")))
+}
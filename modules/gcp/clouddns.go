@@ -0,0 +1,212 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	dns_helper "github.com/gruntwork-io/terratest/modules/dns-helper"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	clouddns "google.golang.org/api/dns/v1"
+)
+
+// GetManagedZone gets the given Cloud DNS managed zone.
+func GetManagedZone(t testing.TestingT, projectID string, zoneName string) *clouddns.ManagedZone {
+	zone, err := GetManagedZoneE(t, projectID, zoneName)
+	require.NoError(t, err)
+	return zone
+}
+
+// GetManagedZoneE gets the given Cloud DNS managed zone.
+func GetManagedZoneE(t testing.TestingT, projectID string, zoneName string) (*clouddns.ManagedZone, error) {
+	logger.Default.Logf(t, "Getting Cloud DNS managed zone %s", zoneName)
+
+	service, err := NewCloudDNSServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	zone, err := service.ManagedZones.Get(projectID, zoneName).Context(context.Background()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("ManagedZones.Get(%s) got error: %v", zoneName, err)
+	}
+
+	return zone, nil
+}
+
+// GetRecordSet gets the record set with the given name (e.g. www.example.com.) and type (e.g. A) from the given
+// Cloud DNS managed zone.
+func GetRecordSet(t testing.TestingT, projectID string, zoneName string, recordName string, recordType string) *clouddns.ResourceRecordSet {
+	recordSet, err := GetRecordSetE(t, projectID, zoneName, recordName, recordType)
+	require.NoError(t, err)
+	return recordSet
+}
+
+// GetRecordSetE gets the record set with the given name (e.g. www.example.com.) and type (e.g. A) from the given
+// Cloud DNS managed zone.
+func GetRecordSetE(t testing.TestingT, projectID string, zoneName string, recordName string, recordType string) (*clouddns.ResourceRecordSet, error) {
+	logger.Default.Logf(t, "Getting %s record set %s from Cloud DNS managed zone %s", recordType, recordName, zoneName)
+
+	service, err := NewCloudDNSServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	recordSet, err := service.ResourceRecordSets.Get(projectID, zoneName, toFqdn(recordName), recordType).Context(context.Background()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("ResourceRecordSets.Get(%s, %s) got error: %v", recordName, recordType, err)
+	}
+
+	return recordSet, nil
+}
+
+// AssertRecordSet checks that the given record set of the Cloud DNS managed zone holds exactly the expected values,
+// in any order.
+func AssertRecordSet(t testing.TestingT, projectID string, zoneName string, recordName string, recordType string, expectedValues []string) {
+	require.NoError(t, AssertRecordSetE(t, projectID, zoneName, recordName, recordType, expectedValues))
+}
+
+// AssertRecordSetE checks that the given record set of the Cloud DNS managed zone holds exactly the expected values,
+// in any order.
+func AssertRecordSetE(t testing.TestingT, projectID string, zoneName string, recordName string, recordType string, expectedValues []string) error {
+	recordSet, err := GetRecordSetE(t, projectID, zoneName, recordName, recordType)
+	if err != nil {
+		return err
+	}
+	if !sameStringSet(recordSet.Rrdatas, expectedValues) {
+		return fmt.Errorf("%s record set %s holds %v, expected %v", recordType, recordName, recordSet.Rrdatas, expectedValues)
+	}
+	return nil
+}
+
+// WaitForRecordResolution waits until all the name servers of the given (public) Cloud DNS managed zone answer the
+// values of the given record set, retrying for the specified amount of times, sleeping for the provided duration
+// between each try. Supported record types: A, AAAA, CNAME, MX, NS, TXT.
+func WaitForRecordResolution(t testing.TestingT, projectID string, zoneName string, recordName string, recordType string, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, WaitForRecordResolutionE(t, projectID, zoneName, recordName, recordType, retries, sleepBetweenRetries))
+}
+
+// WaitForRecordResolutionE waits until all the name servers of the given (public) Cloud DNS managed zone answer the
+// values of the given record set, retrying for the specified amount of times, sleeping for the provided duration
+// between each try. Supported record types: A, AAAA, CNAME, MX, NS, TXT.
+func WaitForRecordResolutionE(t testing.TestingT, projectID string, zoneName string, recordName string, recordType string, retries int, sleepBetweenRetries time.Duration) error {
+	zone, err := GetManagedZoneE(t, projectID, zoneName)
+	if err != nil {
+		return err
+	}
+	if len(zone.NameServers) == 0 {
+		return fmt.Errorf("Cloud DNS managed zone %s has no name servers", zoneName)
+	}
+
+	recordSet, err := GetRecordSetE(t, projectID, zoneName, recordName, recordType)
+	if err != nil {
+		return err
+	}
+
+	query := dns_helper.DNSQuery{Type: recordType, Name: toFqdn(recordName)}
+	expectedAnswers := dns_helper.DNSAnswers{}
+	for _, value := range recordSet.Rrdatas {
+		expectedAnswers = append(expectedAnswers, dns_helper.DNSAnswer{Type: recordType, Value: value})
+	}
+	expectedAnswers.Sort()
+
+	description := fmt.Sprintf("Waiting for %s record %s to resolve on the name servers of zone %s", recordType, recordName, zoneName)
+	message, err := retry.DoWithRetryE(t, description, retries, sleepBetweenRetries, func() (string, error) {
+		for _, nameServer := range zone.NameServers {
+			answers, err := dns_helper.DNSLookupE(t, query, []string{nameServer})
+			if err != nil {
+				return "", err
+			}
+			if !sameDNSAnswers(answers, expectedAnswers) {
+				return "", fmt.Errorf("name server %s answered %v, expected %v", nameServer, answers, expectedAnswers)
+			}
+		}
+		return fmt.Sprintf("%s record %s resolves on all the name servers", recordType, recordName), nil
+	})
+	logger.Default.Logf(t, message)
+	return err
+}
+
+// AssertPrivateZoneBoundToNetwork checks that the given Cloud DNS managed zone is private and visible from the given
+// VPC network.
+func AssertPrivateZoneBoundToNetwork(t testing.TestingT, projectID string, zoneName string, networkName string) {
+	require.NoError(t, AssertPrivateZoneBoundToNetworkE(t, projectID, zoneName, networkName))
+}
+
+// AssertPrivateZoneBoundToNetworkE checks that the given Cloud DNS managed zone is private and visible from the given
+// VPC network. The network can be given by name, or by URL to match networks of other projects.
+func AssertPrivateZoneBoundToNetworkE(t testing.TestingT, projectID string, zoneName string, networkName string) error {
+	zone, err := GetManagedZoneE(t, projectID, zoneName)
+	if err != nil {
+		return err
+	}
+	return checkPrivateZoneNetwork(zone, networkName)
+}
+
+// NewCloudDNSService creates a new Cloud DNS service, which is used to make Cloud DNS API calls.
+func NewCloudDNSService(t testing.TestingT) *clouddns.Service {
+	service, err := NewCloudDNSServiceE(t)
+	require.NoError(t, err)
+	return service
+}
+
+// NewCloudDNSServiceE creates a new Cloud DNS service, which is used to make Cloud DNS API calls.
+func NewCloudDNSServiceE(t testing.TestingT) (*clouddns.Service, error) {
+	return clouddns.NewService(context.Background(), withOptions(t)...)
+}
+
+func checkPrivateZoneNetwork(zone *clouddns.ManagedZone, networkName string) error {
+	if zone.Visibility != "private" {
+		return fmt.Errorf("Cloud DNS managed zone %s is not private", zone.Name)
+	}
+
+	if zone.PrivateVisibilityConfig != nil {
+		for _, network := range zone.PrivateVisibilityConfig.Networks {
+			if network.NetworkUrl == networkName || strings.HasSuffix(network.NetworkUrl, "/networks/"+networkName) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("Cloud DNS managed zone %s is not visible from network %s", zone.Name, networkName)
+}
+
+func sameDNSAnswers(answers dns_helper.DNSAnswers, expectedAnswers dns_helper.DNSAnswers) bool {
+	actualValues := []string{}
+	for _, answer := range answers {
+		actualValues = append(actualValues, answer.String())
+	}
+	expectedValues := []string{}
+	for _, answer := range expectedAnswers {
+		expectedValues = append(expectedValues, answer.String())
+	}
+	return sameStringSet(actualValues, expectedValues)
+}
+
+func sameStringSet(actual []string, expected []string) bool {
+	if len(actual) != len(expected) {
+		return false
+	}
+
+	sortedActual := append([]string{}, actual...)
+	sortedExpected := append([]string{}, expected...)
+	sort.Strings(sortedActual)
+	sort.Strings(sortedExpected)
+	for i := range sortedActual {
+		if sortedActual[i] != sortedExpected[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func toFqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
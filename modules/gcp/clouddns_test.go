@@ -0,0 +1,45 @@
+//go:build gcp
+// +build gcp
+
+// NOTE: We use build tags to differentiate GCP testing for better isolation and parallelism when executing our tests.
+
+package gcp
+
+import (
+	"testing"
+
+	dns_helper "github.com/gruntwork-io/terratest/modules/dns-helper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	clouddns "google.golang.org/api/dns/v1"
+)
+
+func TestCheckPrivateZoneNetwork(t *testing.T) {
+	t.Parallel()
+
+	zone := &clouddns.ManagedZone{
+		Name:       "internal",
+		Visibility: "private",
+		PrivateVisibilityConfig: &clouddns.ManagedZonePrivateVisibilityConfig{
+			Networks: []*clouddns.ManagedZonePrivateVisibilityConfigNetwork{
+				{NetworkUrl: "https://www.googleapis.com/compute/v1/projects/my-project/global/networks/vpc-main"},
+			},
+		},
+	}
+
+	require.NoError(t, checkPrivateZoneNetwork(zone, "vpc-main"))
+	require.NoError(t, checkPrivateZoneNetwork(zone, "https://www.googleapis.com/compute/v1/projects/my-project/global/networks/vpc-main"))
+	require.Error(t, checkPrivateZoneNetwork(zone, "main"))
+	require.Error(t, checkPrivateZoneNetwork(&clouddns.ManagedZone{Name: "public", Visibility: "public"}, "vpc-main"))
+}
+
+func TestSameDNSAnswers(t *testing.T) {
+	t.Parallel()
+
+	answers := dns_helper.DNSAnswers{{Type: "A", Value: "10.0.0.2"}, {Type: "A", Value: "10.0.0.1"}}
+	assert.True(t, sameDNSAnswers(answers, dns_helper.DNSAnswers{{Type: "A", Value: "10.0.0.1"}, {Type: "A", Value: "10.0.0.2"}}))
+	assert.False(t, sameDNSAnswers(answers, dns_helper.DNSAnswers{{Type: "A", Value: "10.0.0.1"}}))
+
+	assert.Equal(t, "www.example.com.", toFqdn("www.example.com"))
+	assert.Equal(t, "www.example.com.", toFqdn("www.example.com."))
+}
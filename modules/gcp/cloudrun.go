@@ -0,0 +1,249 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	http_helper "github.com/gruntwork-io/terratest/modules/http-helper"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	run "google.golang.org/api/run/v2"
+)
+
+const (
+	cloudRunConditionSucceeded  = "CONDITION_SUCCEEDED"
+	cloudRunTrafficTargetLatest = "TRAFFIC_TARGET_ALLOCATION_TYPE_LATEST"
+)
+
+// GetCloudRunService gets the Cloud Run service with the given name in the given region.
+func GetCloudRunService(t testing.TestingT, projectID string, region string, serviceName string) *run.GoogleCloudRunV2Service {
+	service, err := GetCloudRunServiceE(t, projectID, region, serviceName)
+	require.NoError(t, err)
+	return service
+}
+
+// GetCloudRunServiceE gets the Cloud Run service with the given name in the given region.
+func GetCloudRunServiceE(t testing.TestingT, projectID string, region string, serviceName string) (*run.GoogleCloudRunV2Service, error) {
+	logger.Default.Logf(t, "Getting Cloud Run service %s in %s", serviceName, region)
+
+	runService, err := NewCloudRunServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("projects/%s/locations/%s/services/%s", projectID, region, serviceName)
+	service, err := runService.Projects.Locations.Services.Get(name).Context(context.Background()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("Services.Get(%s) got error: %v", serviceName, err)
+	}
+
+	return service, nil
+}
+
+// GetCloudRunServiceLatestReadyRevision gets the name of the latest revision of the given Cloud Run service that is
+// ready to serve traffic.
+func GetCloudRunServiceLatestReadyRevision(t testing.TestingT, projectID string, region string, serviceName string) string {
+	revision, err := GetCloudRunServiceLatestReadyRevisionE(t, projectID, region, serviceName)
+	require.NoError(t, err)
+	return revision
+}
+
+// GetCloudRunServiceLatestReadyRevisionE gets the name of the latest revision of the given Cloud Run service that is
+// ready to serve traffic.
+func GetCloudRunServiceLatestReadyRevisionE(t testing.TestingT, projectID string, region string, serviceName string) (string, error) {
+	service, err := GetCloudRunServiceE(t, projectID, region, serviceName)
+	if err != nil {
+		return "", err
+	}
+	if service.LatestReadyRevision == "" {
+		return "", fmt.Errorf("Cloud Run service %s has no ready revision", serviceName)
+	}
+	return path.Base(service.LatestReadyRevision), nil
+}
+
+// GetCloudRunServiceTraffic gets the percentage of traffic served by each revision of the given Cloud Run service, as
+// a map of revision name to percent. Traffic sent to the latest revision is reported under the name of the latest
+// ready revision.
+func GetCloudRunServiceTraffic(t testing.TestingT, projectID string, region string, serviceName string) map[string]int64 {
+	traffic, err := GetCloudRunServiceTrafficE(t, projectID, region, serviceName)
+	require.NoError(t, err)
+	return traffic
+}
+
+// GetCloudRunServiceTrafficE gets the percentage of traffic served by each revision of the given Cloud Run service, as
+// a map of revision name to percent. Traffic sent to the latest revision is reported under the name of the latest
+// ready revision.
+func GetCloudRunServiceTrafficE(t testing.TestingT, projectID string, region string, serviceName string) (map[string]int64, error) {
+	service, err := GetCloudRunServiceE(t, projectID, region, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	return getCloudRunTraffic(service), nil
+}
+
+// AssertCloudRunRevisionTraffic checks that the given revision of the Cloud Run service serves the expected percentage
+// of traffic.
+func AssertCloudRunRevisionTraffic(t testing.TestingT, projectID string, region string, serviceName string, revisionName string, expectedPercent int64) {
+	require.NoError(t, AssertCloudRunRevisionTrafficE(t, projectID, region, serviceName, revisionName, expectedPercent))
+}
+
+// AssertCloudRunRevisionTrafficE checks that the given revision of the Cloud Run service serves the expected
+// percentage of traffic.
+func AssertCloudRunRevisionTrafficE(t testing.TestingT, projectID string, region string, serviceName string, revisionName string, expectedPercent int64) error {
+	traffic, err := GetCloudRunServiceTrafficE(t, projectID, region, serviceName)
+	if err != nil {
+		return err
+	}
+	if percent := traffic[revisionName]; percent != expectedPercent {
+		return fmt.Errorf("revision %s of Cloud Run service %s serves %d%% of the traffic, expected %d%%", revisionName, serviceName, percent, expectedPercent)
+	}
+	return nil
+}
+
+// WaitForServiceReady waits until the given Cloud Run service has finished reconciling and is ready to serve traffic,
+// retrying the check for the specified amount of times, sleeping for the provided duration between each try.
+func WaitForServiceReady(t testing.TestingT, projectID string, region string, serviceName string, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, WaitForServiceReadyE(t, projectID, region, serviceName, retries, sleepBetweenRetries))
+}
+
+// WaitForServiceReadyE waits until the given Cloud Run service has finished reconciling and is ready to serve traffic,
+// retrying the check for the specified amount of times, sleeping for the provided duration between each try.
+func WaitForServiceReadyE(t testing.TestingT, projectID string, region string, serviceName string, retries int, sleepBetweenRetries time.Duration) error {
+	description := fmt.Sprintf("Waiting for Cloud Run service %s to be ready", serviceName)
+	message, err := retry.DoWithRetryE(t, description, retries, sleepBetweenRetries, func() (string, error) {
+		service, err := GetCloudRunServiceE(t, projectID, region, serviceName)
+		if err != nil {
+			return "", err
+		}
+		if err := checkCloudRunServiceReady(service); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Cloud Run service %s is ready", serviceName), nil
+	})
+	logger.Default.Logf(t, message)
+	return err
+}
+
+// InvokeCloudRunService sends an HTTP request to the given path of the Cloud Run service, authenticated with an ID
+// token for the service URL, and returns the status code and body of the response.
+func InvokeCloudRunService(t testing.TestingT, projectID string, region string, serviceName string, method string, urlPath string, body io.Reader) (int, string) {
+	statusCode, responseBody, err := InvokeCloudRunServiceE(t, projectID, region, serviceName, method, urlPath, body)
+	require.NoError(t, err)
+	return statusCode, responseBody
+}
+
+// InvokeCloudRunServiceE sends an HTTP request to the given path of the Cloud Run service, authenticated with an ID
+// token for the service URL, and returns the status code and body of the response. This works for services that
+// require authentication (no allUsers invoker). The ID token is minted like with GetIDTokenE, from the credentials set
+// with SetTestOptions or the application default credentials, which can't be user credentials.
+func InvokeCloudRunServiceE(t testing.TestingT, projectID string, region string, serviceName string, method string, urlPath string, body io.Reader) (int, string, error) {
+	service, err := GetCloudRunServiceE(t, projectID, region, serviceName)
+	if err != nil {
+		return -1, "", err
+	}
+	if service.Uri == "" {
+		return -1, "", fmt.Errorf("Cloud Run service %s has no URL", serviceName)
+	}
+
+	token, err := GetIDTokenE(t, service.Uri)
+	if err != nil {
+		return -1, "", err
+	}
+
+	headers := map[string]string{
+		"Authorization": "Bearer " + token,
+	}
+	url := strings.TrimSuffix(service.Uri, "/") + "/" + strings.TrimPrefix(urlPath, "/")
+	return http_helper.HTTPDoE(t, method, url, body, headers, nil)
+}
+
+// GetIDToken mints a Google-signed ID token for the given audience (e.g. the URL of a Cloud Run service) from the
+// credentials set with SetTestOptions for the test, or the application default credentials otherwise.
+func GetIDToken(t testing.TestingT, audience string) string {
+	token, err := GetIDTokenE(t, audience)
+	require.NoError(t, err)
+	return token
+}
+
+// GetIDTokenE mints a Google-signed ID token for the given audience (e.g. the URL of a Cloud Run service) from the
+// credentials set with SetTestOptions for the test, or the application default credentials otherwise. User
+// credentials and GOOGLE_OAUTH_ACCESS_TOKEN can't mint ID tokens.
+func GetIDTokenE(t testing.TestingT, audience string) (string, error) {
+	options, ok := getTestOptions(t)
+	if !ok {
+		options = &Options{}
+	}
+	return GetIDTokenWithOptionsE(t, options, audience)
+}
+
+// GetIDTokenWithOptions mints a Google-signed ID token for the given audience (e.g. the URL of a Cloud Run service)
+// from the credentials of the given options.
+func GetIDTokenWithOptions(t testing.TestingT, options *Options, audience string) string {
+	token, err := GetIDTokenWithOptionsE(t, options, audience)
+	require.NoError(t, err)
+	return token
+}
+
+// GetIDTokenWithOptionsE mints a Google-signed ID token for the given audience (e.g. the URL of a Cloud Run service)
+// from the credentials of the given options, impersonating their ImpersonateServiceAccount if set.
+func GetIDTokenWithOptionsE(t testing.TestingT, options *Options, audience string) (string, error) {
+	tokenSource, err := options.IDTokenSourceE(context.Background(), audience)
+	if err != nil {
+		return "", fmt.Errorf("Failed to create ID token source for %s: %v", audience, err)
+	}
+
+	token, err := tokenSource.Token()
+	if err != nil {
+		return "", fmt.Errorf("Failed to mint ID token for %s: %v", audience, err)
+	}
+
+	return token.AccessToken, nil
+}
+
+// NewCloudRunService creates a new Cloud Run service, which is used to make Cloud Run Admin API calls.
+func NewCloudRunService(t testing.TestingT) *run.Service {
+	service, err := NewCloudRunServiceE(t)
+	require.NoError(t, err)
+	return service
+}
+
+// NewCloudRunServiceE creates a new Cloud Run service, which is used to make Cloud Run Admin API calls.
+func NewCloudRunServiceE(t testing.TestingT) (*run.Service, error) {
+	return run.NewService(context.Background(), withOptions(t)...)
+}
+
+// getCloudRunTraffic returns the traffic percent served by each revision of the service.
+func getCloudRunTraffic(service *run.GoogleCloudRunV2Service) map[string]int64 {
+	traffic := map[string]int64{}
+	for _, status := range service.TrafficStatuses {
+		revision := status.Revision
+		if status.Type == cloudRunTrafficTargetLatest {
+			revision = path.Base(service.LatestReadyRevision)
+		}
+		traffic[revision] += status.Percent
+	}
+	return traffic
+}
+
+// checkCloudRunServiceReady returns an error if the service is still reconciling or its terminal condition is not
+// successful.
+func checkCloudRunServiceReady(service *run.GoogleCloudRunV2Service) error {
+	if service.Reconciling {
+		return fmt.Errorf("Cloud Run service %s is still reconciling", service.Name)
+	}
+	condition := service.TerminalCondition
+	if condition == nil || condition.State != cloudRunConditionSucceeded {
+		message := ""
+		if condition != nil {
+			message = condition.Message
+		}
+		return fmt.Errorf("Cloud Run service %s is not ready: %s", service.Name, message)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+//go:build gcp
+// +build gcp
+
+// NOTE: We use build tags to differentiate GCP testing for better isolation and parallelism when executing our tests.
+
+package gcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	run "google.golang.org/api/run/v2"
+)
+
+func TestGetCloudRunTraffic(t *testing.T) {
+	t.Parallel()
+
+	service := &run.GoogleCloudRunV2Service{
+		LatestReadyRevision: "projects/my-project/locations/us-central1/services/hello/revisions/hello-00002-abc",
+		TrafficStatuses: []*run.GoogleCloudRunV2TrafficTargetStatus{
+			{Type: "TRAFFIC_TARGET_ALLOCATION_TYPE_LATEST", Percent: 90},
+			{Type: "TRAFFIC_TARGET_ALLOCATION_TYPE_REVISION", Revision: "hello-00001-xyz", Percent: 10},
+		},
+	}
+
+	assert.Equal(t, map[string]int64{"hello-00002-abc": 90, "hello-00001-xyz": 10}, getCloudRunTraffic(service))
+}
+
+func TestCheckCloudRunServiceReady(t *testing.T) {
+	t.Parallel()
+
+	ready := &run.GoogleCloudRunV2Service{
+		Name:              "hello",
+		TerminalCondition: &run.GoogleCloudRunV2Condition{Type: "Ready", State: "CONDITION_SUCCEEDED"},
+	}
+	require.NoError(t, checkCloudRunServiceReady(ready))
+
+	reconciling := &run.GoogleCloudRunV2Service{Name: "hello", Reconciling: true, TerminalCondition: ready.TerminalCondition}
+	require.Error(t, checkCloudRunServiceReady(reconciling))
+
+	failed := &run.GoogleCloudRunV2Service{
+		Name:              "hello",
+		TerminalCondition: &run.GoogleCloudRunV2Condition{Type: "Ready", State: "CONDITION_FAILED", Message: "container failed to start"},
+	}
+	require.Error(t, checkCloudRunServiceReady(failed))
+}
@@ -586,7 +586,7 @@ func NewComputeService(t testing.TestingT) *compute.Service {
 func NewComputeServiceE(t testing.TestingT) (*compute.Service, error) {
 	ctx := context.Background()
 
-	if ts, ok := getStaticTokenSource(); ok {
+	if ts, ok := getTokenSource(t); ok {
 		return compute.New(oauth2.NewClient(ctx, ts))
 	}
 
@@ -2,14 +2,25 @@
 package gcp
 
 import (
+	"errors"
+	"net/http"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 )
 
-func withOptions() (opts []option.ClientOption) {
-	v, ok := getStaticTokenSource()
+func withOptions(t testing.TestingT) (opts []option.ClientOption) {
+	v, ok := getTokenSource(t)
 	if ok {
 		opts = append(opts, option.WithTokenSource(v))
 	}
 
 	return
 }
+
+// isNotFoundError indicates whether the error, or an error it wraps, is a 404 error of a Google API.
+func isNotFoundError(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound
+}
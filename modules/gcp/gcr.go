@@ -22,7 +22,7 @@ func DeleteGCRRepo(t testing.TestingT, repo string) {
 // DeleteGCRRepoE deletes a GCR repository including all tagged images
 func DeleteGCRRepoE(t testing.TestingT, repo string) error {
 	// create a new auther for the API calls
-	auther, err := newGCRAuther()
+	auther, err := newGCRAuther(t)
 	if err != nil {
 		return fmt.Errorf("Failed to create auther. Got error: %v", err)
 	}
@@ -72,7 +72,7 @@ func DeleteGCRImageRefE(t testing.TestingT, ref string) error {
 	}
 
 	// create a new auther for the API calls
-	auther, err := newGCRAuther()
+	auther, err := newGCRAuther(t)
 	if err != nil {
 		return fmt.Errorf("Failed to create auther. Got error: %v", err)
 	}
@@ -86,8 +86,8 @@ func DeleteGCRImageRefE(t testing.TestingT, ref string) error {
 	return nil
 }
 
-func newGCRAuther() (authn.Authenticator, error) {
-	if ts, ok := getStaticTokenSource(); ok {
+func newGCRAuther(t testing.TestingT) (authn.Authenticator, error) {
+	if ts, ok := getTokenSource(t); ok {
 		return gcrgoogle.NewTokenSourceAuthenticator(ts), nil
 	}
 
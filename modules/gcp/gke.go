@@ -0,0 +1,298 @@
+package gcp
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/k8s"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/container/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// GkeClusterStatusRunning is the status of a GKE cluster that is ready to be used.
+const GkeClusterStatusRunning = "RUNNING"
+
+// GetGkeCluster gets the GKE cluster with the given name in the given location (region or zone).
+func GetGkeCluster(t testing.TestingT, projectID string, location string, clusterName string) *container.Cluster {
+	cluster, err := GetGkeClusterE(t, projectID, location, clusterName)
+	require.NoError(t, err)
+	return cluster
+}
+
+// GetGkeClusterE gets the GKE cluster with the given name in the given location (region or zone).
+func GetGkeClusterE(t testing.TestingT, projectID string, location string, clusterName string) (*container.Cluster, error) {
+	logger.Default.Logf(t, "Getting GKE cluster %s in %s", clusterName, location)
+
+	service, err := NewContainerServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster, err := service.Projects.Locations.Clusters.Get(gkeClusterResourceName(projectID, location, clusterName)).Context(context.Background()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("Clusters.Get(%s) got error: %v", clusterName, err)
+	}
+
+	return cluster, nil
+}
+
+// WaitForClusterRunning waits until the given GKE cluster is in the RUNNING status, retrying the check for the
+// specified amount of times, sleeping for the provided duration between each try.
+func WaitForClusterRunning(t testing.TestingT, projectID string, location string, clusterName string, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, WaitForClusterRunningE(t, projectID, location, clusterName, retries, sleepBetweenRetries))
+}
+
+// WaitForClusterRunningE waits until the given GKE cluster is in the RUNNING status, retrying the check for the
+// specified amount of times, sleeping for the provided duration between each try.
+func WaitForClusterRunningE(t testing.TestingT, projectID string, location string, clusterName string, retries int, sleepBetweenRetries time.Duration) error {
+	description := fmt.Sprintf("Waiting for GKE cluster %s to be running", clusterName)
+	message, err := retry.DoWithRetryE(t, description, retries, sleepBetweenRetries, func() (string, error) {
+		cluster, err := GetGkeClusterE(t, projectID, location, clusterName)
+		if err != nil {
+			return "", err
+		}
+		if cluster.Status != GkeClusterStatusRunning {
+			return "", fmt.Errorf("GKE cluster %s is %s", clusterName, cluster.Status)
+		}
+		return fmt.Sprintf("GKE cluster %s is running", clusterName), nil
+	})
+	logger.Default.Logf(t, message)
+	return err
+}
+
+// GetGkeKubeconfig writes a kubeconfig file for the given GKE cluster and returns KubectlOptions that use it, so the
+// helpers of the k8s module can be pointed at the cluster. The caller is responsible for removing the kubeconfig file
+// at options.ConfigPath.
+func GetGkeKubeconfig(t testing.TestingT, projectID string, location string, clusterName string) *k8s.KubectlOptions {
+	options, err := GetGkeKubeconfigE(t, projectID, location, clusterName)
+	require.NoError(t, err)
+	return options
+}
+
+// GetGkeKubeconfigE writes a kubeconfig file for the given GKE cluster and returns KubectlOptions that use it, so the
+// helpers of the k8s module can be pointed at the cluster. The caller is responsible for removing the kubeconfig file
+// at options.ConfigPath. The kubeconfig embeds an OAuth2 access token of the current credentials, which is valid for
+// about an hour.
+func GetGkeKubeconfigE(t testing.TestingT, projectID string, location string, clusterName string) (*k8s.KubectlOptions, error) {
+	cluster, err := GetGkeClusterE(t, projectID, location, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenSource, err := newGoogleTokenSourceE(t, context.Background())
+	if err != nil {
+		return nil, err
+	}
+	token, err := tokenSource.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := newGkeKubeconfig(projectID, location, cluster, token.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	configData, err := clientcmd.Write(*config)
+	if err != nil {
+		return nil, err
+	}
+
+	configPath, err := k8s.StoreConfigToTempFileE(t, string(configData))
+	if err != nil {
+		return nil, err
+	}
+
+	return k8s.NewKubectlOptions(config.CurrentContext, configPath, ""), nil
+}
+
+// GetGkeClusterReleaseChannel gets the release channel (e.g. REGULAR) the given GKE cluster is enrolled in, or
+// UNSPECIFIED if it is not enrolled in any.
+func GetGkeClusterReleaseChannel(t testing.TestingT, projectID string, location string, clusterName string) string {
+	channel, err := GetGkeClusterReleaseChannelE(t, projectID, location, clusterName)
+	require.NoError(t, err)
+	return channel
+}
+
+// GetGkeClusterReleaseChannelE gets the release channel (e.g. REGULAR) the given GKE cluster is enrolled in, or
+// UNSPECIFIED if it is not enrolled in any.
+func GetGkeClusterReleaseChannelE(t testing.TestingT, projectID string, location string, clusterName string) (string, error) {
+	cluster, err := GetGkeClusterE(t, projectID, location, clusterName)
+	if err != nil {
+		return "", err
+	}
+	return getGkeReleaseChannel(cluster), nil
+}
+
+// AssertGkeClusterReleaseChannel checks that the given GKE cluster is enrolled in the expected release channel.
+func AssertGkeClusterReleaseChannel(t testing.TestingT, projectID string, location string, clusterName string, expectedChannel string) {
+	require.NoError(t, AssertGkeClusterReleaseChannelE(t, projectID, location, clusterName, expectedChannel))
+}
+
+// AssertGkeClusterReleaseChannelE checks that the given GKE cluster is enrolled in the expected release channel.
+func AssertGkeClusterReleaseChannelE(t testing.TestingT, projectID string, location string, clusterName string, expectedChannel string) error {
+	channel, err := GetGkeClusterReleaseChannelE(t, projectID, location, clusterName)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(channel, expectedChannel) {
+		return fmt.Errorf("GKE cluster %s is in release channel %s, expected %s", clusterName, channel, expectedChannel)
+	}
+	return nil
+}
+
+// GetGkeNodePool gets the node pool with the given name of the given GKE cluster.
+func GetGkeNodePool(t testing.TestingT, projectID string, location string, clusterName string, nodePoolName string) *container.NodePool {
+	nodePool, err := GetGkeNodePoolE(t, projectID, location, clusterName, nodePoolName)
+	require.NoError(t, err)
+	return nodePool
+}
+
+// GetGkeNodePoolE gets the node pool with the given name of the given GKE cluster.
+func GetGkeNodePoolE(t testing.TestingT, projectID string, location string, clusterName string, nodePoolName string) (*container.NodePool, error) {
+	logger.Default.Logf(t, "Getting node pool %s of GKE cluster %s", nodePoolName, clusterName)
+
+	service, err := NewContainerServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("%s/nodePools/%s", gkeClusterResourceName(projectID, location, clusterName), nodePoolName)
+	nodePool, err := service.Projects.Locations.Clusters.NodePools.Get(name).Context(context.Background()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("NodePools.Get(%s) got error: %v", nodePoolName, err)
+	}
+
+	return nodePool, nil
+}
+
+// AssertGkeNodePoolAutoscaling checks that autoscaling is enabled on the given node pool with the expected per zone
+// minimum and maximum node counts.
+func AssertGkeNodePoolAutoscaling(t testing.TestingT, projectID string, location string, clusterName string, nodePoolName string, expectedMinNodeCount int64, expectedMaxNodeCount int64) {
+	require.NoError(t, AssertGkeNodePoolAutoscalingE(t, projectID, location, clusterName, nodePoolName, expectedMinNodeCount, expectedMaxNodeCount))
+}
+
+// AssertGkeNodePoolAutoscalingE checks that autoscaling is enabled on the given node pool with the expected per zone
+// minimum and maximum node counts.
+func AssertGkeNodePoolAutoscalingE(t testing.TestingT, projectID string, location string, clusterName string, nodePoolName string, expectedMinNodeCount int64, expectedMaxNodeCount int64) error {
+	nodePool, err := GetGkeNodePoolE(t, projectID, location, clusterName, nodePoolName)
+	if err != nil {
+		return err
+	}
+	return checkGkeNodePoolAutoscaling(nodePool, expectedMinNodeCount, expectedMaxNodeCount)
+}
+
+// AssertGkeNodePoolVersion checks that the Kubernetes version of the given node pool starts with the expected
+// version prefix (e.g. "1.29" or "1.29.1-gke.1589017").
+func AssertGkeNodePoolVersion(t testing.TestingT, projectID string, location string, clusterName string, nodePoolName string, expectedVersionPrefix string) {
+	require.NoError(t, AssertGkeNodePoolVersionE(t, projectID, location, clusterName, nodePoolName, expectedVersionPrefix))
+}
+
+// AssertGkeNodePoolVersionE checks that the Kubernetes version of the given node pool starts with the expected
+// version prefix (e.g. "1.29" or "1.29.1-gke.1589017").
+func AssertGkeNodePoolVersionE(t testing.TestingT, projectID string, location string, clusterName string, nodePoolName string, expectedVersionPrefix string) error {
+	nodePool, err := GetGkeNodePoolE(t, projectID, location, clusterName, nodePoolName)
+	if err != nil {
+		return err
+	}
+	if !isVersionWithPrefix(nodePool.Version, expectedVersionPrefix) {
+		return fmt.Errorf("node pool %s of GKE cluster %s runs version %s, expected %s", nodePoolName, clusterName, nodePool.Version, expectedVersionPrefix)
+	}
+	return nil
+}
+
+// NewContainerService creates a new Container service, which is used to make GKE API calls.
+func NewContainerService(t testing.TestingT) *container.Service {
+	service, err := NewContainerServiceE(t)
+	require.NoError(t, err)
+	return service
+}
+
+// NewContainerServiceE creates a new Container service, which is used to make GKE API calls.
+func NewContainerServiceE(t testing.TestingT) (*container.Service, error) {
+	return container.NewService(context.Background(), withOptions(t)...)
+}
+
+// newGoogleTokenSourceE returns the token source of the test options or of the static token, if one is set, or of the
+// application default credentials otherwise.
+func newGoogleTokenSourceE(t testing.TestingT, ctx context.Context) (oauth2.TokenSource, error) {
+	if ts, ok := getTokenSource(t); ok {
+		return ts, nil
+	}
+	return google.DefaultTokenSource(ctx, container.CloudPlatformScope)
+}
+
+// newGkeKubeconfig builds a kubeconfig with a single context for the given cluster, authenticating with the given
+// access token. The context is named like the ones created by gcloud.
+func newGkeKubeconfig(projectID string, location string, cluster *container.Cluster, accessToken string) (*clientcmdapi.Config, error) {
+	if cluster.MasterAuth == nil || cluster.Endpoint == "" {
+		return nil, fmt.Errorf("GKE cluster %s has no endpoint yet", cluster.Name)
+	}
+
+	caCertificate, err := base64.StdEncoding.DecodeString(cluster.MasterAuth.ClusterCaCertificate)
+	if err != nil {
+		return nil, err
+	}
+
+	contextName := fmt.Sprintf("gke_%s_%s_%s", projectID, location, cluster.Name)
+	config := clientcmdapi.NewConfig()
+	config.Clusters[contextName] = &clientcmdapi.Cluster{
+		Server:                   "https://" + cluster.Endpoint,
+		CertificateAuthorityData: caCertificate,
+	}
+	config.AuthInfos[contextName] = &clientcmdapi.AuthInfo{
+		Token: accessToken,
+	}
+	config.Contexts[contextName] = &clientcmdapi.Context{
+		Cluster:  contextName,
+		AuthInfo: contextName,
+	}
+	config.CurrentContext = contextName
+	return config, nil
+}
+
+// checkGkeNodePoolAutoscaling returns an error if autoscaling is not enabled on the node pool with the expected
+// minimum and maximum node counts.
+func checkGkeNodePoolAutoscaling(nodePool *container.NodePool, expectedMinNodeCount int64, expectedMaxNodeCount int64) error {
+	autoscaling := nodePool.Autoscaling
+	if autoscaling == nil || !autoscaling.Enabled {
+		return fmt.Errorf("autoscaling is not enabled on node pool %s", nodePool.Name)
+	}
+	if autoscaling.MinNodeCount != expectedMinNodeCount || autoscaling.MaxNodeCount != expectedMaxNodeCount {
+		return fmt.Errorf("node pool %s autoscales between %d and %d nodes, expected %d and %d", nodePool.Name, autoscaling.MinNodeCount, autoscaling.MaxNodeCount, expectedMinNodeCount, expectedMaxNodeCount)
+	}
+	return nil
+}
+
+// getGkeReleaseChannel returns the release channel of the cluster, or UNSPECIFIED if it is not enrolled in any.
+func getGkeReleaseChannel(cluster *container.Cluster) string {
+	if cluster.ReleaseChannel == nil || cluster.ReleaseChannel.Channel == "" {
+		return "UNSPECIFIED"
+	}
+	return cluster.ReleaseChannel.Channel
+}
+
+// isVersionWithPrefix indicates whether the version is equal to the prefix, or starts with the prefix followed by a
+// separator, so that 1.2 matches 1.2.3 but not 1.20.
+func isVersionWithPrefix(version string, prefix string) bool {
+	if !strings.HasPrefix(version, prefix) {
+		return false
+	}
+	rest := version[len(prefix):]
+	return rest == "" || strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, "-") || strings.HasSuffix(prefix, ".")
+}
+
+// gkeClusterResourceName returns the resource name of a GKE cluster, as expected by the Container API.
+func gkeClusterResourceName(projectID string, location string, clusterName string) string {
+	return fmt.Sprintf("projects/%s/locations/%s/clusters/%s", projectID, location, clusterName)
+}
@@ -0,0 +1,62 @@
+//go:build gcp
+// +build gcp
+
+// NOTE: We use build tags to differentiate GCP testing for better isolation and parallelism when executing our tests.
+
+package gcp
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/container/v1"
+)
+
+func TestNewGkeKubeconfig(t *testing.T) {
+	t.Parallel()
+
+	cluster := &container.Cluster{
+		Name:     "my-cluster",
+		Endpoint: "10.0.0.1",
+		MasterAuth: &container.MasterAuth{
+			ClusterCaCertificate: base64.StdEncoding.EncodeToString([]byte("ca")),
+		},
+	}
+
+	config, err := newGkeKubeconfig("my-project", "us-central1", cluster, "token")
+	require.NoError(t, err)
+	assert.Equal(t, "gke_my-project_us-central1_my-cluster", config.CurrentContext)
+	assert.Equal(t, "https://10.0.0.1", config.Clusters[config.CurrentContext].Server)
+	assert.Equal(t, []byte("ca"), config.Clusters[config.CurrentContext].CertificateAuthorityData)
+	assert.Equal(t, "token", config.AuthInfos[config.CurrentContext].Token)
+
+	_, err = newGkeKubeconfig("my-project", "us-central1", &container.Cluster{Name: "provisioning"}, "token")
+	require.Error(t, err)
+}
+
+func TestCheckGkeNodePoolAutoscaling(t *testing.T) {
+	t.Parallel()
+
+	nodePool := &container.NodePool{
+		Name:        "default-pool",
+		Autoscaling: &container.NodePoolAutoscaling{Enabled: true, MinNodeCount: 1, MaxNodeCount: 3},
+	}
+
+	require.NoError(t, checkGkeNodePoolAutoscaling(nodePool, 1, 3))
+	require.Error(t, checkGkeNodePoolAutoscaling(nodePool, 1, 5))
+	require.Error(t, checkGkeNodePoolAutoscaling(&container.NodePool{Name: "fixed-pool"}, 1, 3))
+}
+
+func TestGkeVersionAndReleaseChannel(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, isVersionWithPrefix("1.29.1-gke.1589017", "1.29"))
+	assert.True(t, isVersionWithPrefix("1.29.1-gke.1589017", "1.29.1-gke.1589017"))
+	assert.False(t, isVersionWithPrefix("1.290.1", "1.29"))
+	assert.False(t, isVersionWithPrefix("1.28.5-gke.1", "1.29"))
+
+	assert.Equal(t, "UNSPECIFIED", getGkeReleaseChannel(&container.Cluster{}))
+	assert.Equal(t, "REGULAR", getGkeReleaseChannel(&container.Cluster{ReleaseChannel: &container.ReleaseChannel{Channel: "REGULAR"}}))
+}
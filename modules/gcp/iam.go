@@ -0,0 +1,216 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/cloudresourcemanager/v1"
+	iamv1 "google.golang.org/api/iam/v1"
+)
+
+// IamResourceType is the type of a resource an IAM policy is attached to.
+type IamResourceType string
+
+const (
+	// IamResourceProject is a GCP project, identified by its project ID.
+	IamResourceProject IamResourceType = "project"
+	// IamResourceStorageBucket is a Cloud Storage bucket, identified by its name.
+	IamResourceStorageBucket IamResourceType = "bucket"
+	// IamResourceServiceAccount is a service account, identified by its email or its full resource name
+	// (projects/PROJECT_ID/serviceAccounts/EMAIL).
+	IamResourceServiceAccount IamResourceType = "serviceAccount"
+)
+
+// iamPolicyVersion is the policy version requested, the only one that includes the conditions of the bindings.
+const iamPolicyVersion = 3
+
+// IamPolicy is the IAM policy of a resource, independent of the resource type.
+type IamPolicy struct {
+	Bindings []IamBinding `json:"bindings"`
+}
+
+// IamBinding grants a role to a list of members, optionally only when a condition is met.
+type IamBinding struct {
+	Role      string        `json:"role"`
+	Members   []string      `json:"members"`
+	Condition *IamCondition `json:"condition"`
+}
+
+// IamCondition is the CEL condition of a conditional role binding.
+type IamCondition struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Expression  string `json:"expression"`
+}
+
+// GetIamPolicy gets the IAM policy, including the conditions of the bindings, of the given project, storage bucket or
+// service account.
+func GetIamPolicy(t testing.TestingT, resourceType IamResourceType, resourceID string) *IamPolicy {
+	policy, err := GetIamPolicyE(t, resourceType, resourceID)
+	require.NoError(t, err)
+	return policy
+}
+
+// GetIamPolicyE gets the IAM policy, including the conditions of the bindings, of the given project, storage bucket or
+// service account.
+func GetIamPolicyE(t testing.TestingT, resourceType IamResourceType, resourceID string) (*IamPolicy, error) {
+	logger.Default.Logf(t, "Getting IAM policy of %s %s", resourceType, resourceID)
+
+	switch resourceType {
+	case IamResourceProject:
+		return getProjectIamPolicyE(t, resourceID)
+	case IamResourceStorageBucket:
+		return getStorageBucketIamPolicyV3E(t, resourceID)
+	case IamResourceServiceAccount:
+		return getServiceAccountIamPolicyE(t, resourceID)
+	default:
+		return nil, fmt.Errorf("unsupported IAM resource type %s", resourceType)
+	}
+}
+
+// AssertMemberHasRole checks that the given member (e.g. user:jane@example.com) is granted the given role on the
+// resource by an unconditional binding.
+func AssertMemberHasRole(t testing.TestingT, resourceType IamResourceType, resourceID string, member string, role string) {
+	require.NoError(t, AssertMemberHasRoleE(t, resourceType, resourceID, member, role))
+}
+
+// AssertMemberHasRoleE checks that the given member (e.g. user:jane@example.com) is granted the given role on the
+// resource by an unconditional binding. Conditional bindings are ignored, as they don't always grant the role: use
+// AssertMemberHasConditionalRoleE to check them.
+func AssertMemberHasRoleE(t testing.TestingT, resourceType IamResourceType, resourceID string, member string, role string) error {
+	policy, err := GetIamPolicyE(t, resourceType, resourceID)
+	if err != nil {
+		return err
+	}
+	if !policy.HasRole(member, role) {
+		return fmt.Errorf("member %s does not have role %s on %s %s", member, role, resourceType, resourceID)
+	}
+	return nil
+}
+
+// AssertMemberHasConditionalRole checks that the given member is granted the given role on the resource by a binding
+// with the given condition expression.
+func AssertMemberHasConditionalRole(t testing.TestingT, resourceType IamResourceType, resourceID string, member string, role string, conditionExpression string) {
+	require.NoError(t, AssertMemberHasConditionalRoleE(t, resourceType, resourceID, member, role, conditionExpression))
+}
+
+// AssertMemberHasConditionalRoleE checks that the given member is granted the given role on the resource by a binding
+// with the given condition expression. Leading and trailing whitespace in the expressions is ignored.
+func AssertMemberHasConditionalRoleE(t testing.TestingT, resourceType IamResourceType, resourceID string, member string, role string, conditionExpression string) error {
+	policy, err := GetIamPolicyE(t, resourceType, resourceID)
+	if err != nil {
+		return err
+	}
+	if !policy.HasConditionalRole(member, role, conditionExpression) {
+		return fmt.Errorf("member %s does not have role %s with condition %q on %s %s", member, role, conditionExpression, resourceType, resourceID)
+	}
+	return nil
+}
+
+// HasRole indicates whether the member is granted the role by an unconditional binding of the policy.
+func (policy *IamPolicy) HasRole(member string, role string) bool {
+	for _, binding := range policy.Bindings {
+		if binding.Condition == nil && binding.Role == role && binding.hasMember(member) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasConditionalRole indicates whether the member is granted the role by a binding of the policy with the given
+// condition expression.
+func (policy *IamPolicy) HasConditionalRole(member string, role string, conditionExpression string) bool {
+	for _, binding := range policy.Bindings {
+		if binding.Condition == nil || binding.Role != role || !binding.hasMember(member) {
+			continue
+		}
+		if strings.TrimSpace(binding.Condition.Expression) == strings.TrimSpace(conditionExpression) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasMember indicates whether the binding includes the member. Member types are case insensitive, e.g. user: and
+// User: are the same, and so are email addresses.
+func (binding IamBinding) hasMember(member string) bool {
+	for _, bindingMember := range binding.Members {
+		if strings.EqualFold(bindingMember, member) {
+			return true
+		}
+	}
+	return false
+}
+
+func getProjectIamPolicyE(t testing.TestingT, projectID string) (*IamPolicy, error) {
+	service, err := cloudresourcemanager.NewService(context.Background(), withOptions(t)...)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &cloudresourcemanager.GetIamPolicyRequest{
+		Options: &cloudresourcemanager.GetPolicyOptions{RequestedPolicyVersion: iamPolicyVersion},
+	}
+	policy, err := service.Projects.GetIamPolicy(projectID, req).Context(context.Background()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("Projects.GetIamPolicy(%s) got error: %v", projectID, err)
+	}
+
+	return newIamPolicyE(policy)
+}
+
+// getStorageBucketIamPolicyV3E gets the version 3 IAM policy of the given bucket, which unlike the policy of
+// GetStorageBucketIamPolicyE includes the conditions of the bindings.
+func getStorageBucketIamPolicyV3E(t testing.TestingT, bucketName string) (*IamPolicy, error) {
+	client, err := newStorageClient(t)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := client.Bucket(bucketName).IAM().V3().Policy(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("Bucket.IAM.Policy(%s) got error: %v", bucketName, err)
+	}
+
+	return newIamPolicyE(policy)
+}
+
+func getServiceAccountIamPolicyE(t testing.TestingT, serviceAccount string) (*IamPolicy, error) {
+	service, err := iamv1.NewService(context.Background(), withOptions(t)...)
+	if err != nil {
+		return nil, err
+	}
+
+	resource := serviceAccount
+	if !strings.HasPrefix(resource, "projects/") {
+		// The project is inferred from the service account email
+		resource = fmt.Sprintf("projects/-/serviceAccounts/%s", serviceAccount)
+	}
+
+	policy, err := service.Projects.ServiceAccounts.GetIamPolicy(resource).OptionsRequestedPolicyVersion(iamPolicyVersion).Context(context.Background()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("ServiceAccounts.GetIamPolicy(%s) got error: %v", serviceAccount, err)
+	}
+
+	return newIamPolicyE(policy)
+}
+
+// newIamPolicyE converts the given IAM policy of any of the GCP API clients, which all share the JSON representation of
+// IAM policies, to an IamPolicy.
+func newIamPolicyE(policy interface{}) (*IamPolicy, error) {
+	policyJSON, err := json.Marshal(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &IamPolicy{}
+	if err := json.Unmarshal(policyJSON, result); err != nil {
+		return nil, fmt.Errorf("failed to convert IAM policy: %v", err)
+	}
+	return result, nil
+}
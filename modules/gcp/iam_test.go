@@ -0,0 +1,77 @@
+//go:build gcp
+// +build gcp
+
+// NOTE: We use build tags to differentiate GCP testing for better isolation and parallelism when executing our tests.
+
+package gcp
+
+import (
+	"testing"
+
+	"cloud.google.com/go/iam"
+	"cloud.google.com/go/iam/apiv1/iampb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/cloudresourcemanager/v1"
+	iamv1 "google.golang.org/api/iam/v1"
+	"google.golang.org/genproto/googleapis/type/expr"
+)
+
+func TestIamPolicyHasRole(t *testing.T) {
+	t.Parallel()
+
+	expression := `request.time < timestamp("2030-01-01T00:00:00Z")`
+	policy := &IamPolicy{
+		Bindings: []IamBinding{
+			{Role: "roles/viewer", Members: []string{"user:jane@example.com", "group:devs@example.com"}},
+			{Role: "roles/editor", Members: []string{"user:jane@example.com"}, Condition: &IamCondition{Title: "temporary", Expression: expression}},
+		},
+	}
+
+	assert.True(t, policy.HasRole("user:jane@example.com", "roles/viewer"))
+	assert.True(t, policy.HasRole("group:Devs@example.com", "roles/viewer"))
+	assert.False(t, policy.HasRole("user:jane@example.com", "roles/editor"))
+
+	assert.True(t, policy.HasConditionalRole("user:jane@example.com", "roles/editor", " "+expression+"\n"))
+	assert.False(t, policy.HasConditionalRole("user:jane@example.com", "roles/editor", "true"))
+	assert.False(t, policy.HasConditionalRole("user:jane@example.com", "roles/viewer", expression))
+}
+
+func TestGetIamPolicyUnsupportedResourceType(t *testing.T) {
+	t.Parallel()
+
+	_, err := GetIamPolicyE(t, IamResourceType("folder"), "123")
+	require.Error(t, err)
+}
+
+func TestNewIamPolicy(t *testing.T) {
+	t.Parallel()
+
+	expression := `resource.name.startsWith("projects/_/buckets/logs")`
+	expected := &IamPolicy{
+		Bindings: []IamBinding{
+			{Role: "roles/viewer", Members: []string{"user:jane@example.com"}},
+			{Role: "roles/storage.admin", Members: []string{"group:devs@example.com"}, Condition: &IamCondition{Title: "logs", Expression: expression}},
+		},
+	}
+
+	policies := map[string]interface{}{
+		"project": &cloudresourcemanager.Policy{Bindings: []*cloudresourcemanager.Binding{
+			{Role: "roles/viewer", Members: []string{"user:jane@example.com"}},
+			{Role: "roles/storage.admin", Members: []string{"group:devs@example.com"}, Condition: &cloudresourcemanager.Expr{Title: "logs", Expression: expression}},
+		}},
+		"bucket": &iam.Policy3{Bindings: []*iampb.Binding{
+			{Role: "roles/viewer", Members: []string{"user:jane@example.com"}},
+			{Role: "roles/storage.admin", Members: []string{"group:devs@example.com"}, Condition: &expr.Expr{Title: "logs", Expression: expression}},
+		}},
+		"serviceAccount": &iamv1.Policy{Bindings: []*iamv1.Binding{
+			{Role: "roles/viewer", Members: []string{"user:jane@example.com"}},
+			{Role: "roles/storage.admin", Members: []string{"group:devs@example.com"}, Condition: &iamv1.Expr{Title: "logs", Expression: expression}},
+		}},
+	}
+	for resourceType, policy := range policies {
+		result, err := newIamPolicyE(policy)
+		require.NoError(t, err)
+		assert.Equal(t, expected, result, resourceType)
+	}
+}
@@ -0,0 +1,283 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/iterator"
+)
+
+// TerratestRunLabelKey is the key of the label identifying the resources created by a test run. Resources carrying it
+// are the ones the janitor functions look for.
+const TerratestRunLabelKey = "terratest-run"
+
+// Types of the resources the janitor functions clean up.
+const (
+	TestResourceTypeInstance       = "instance"
+	TestResourceTypeBucket         = "bucket"
+	TestResourceTypeGkeCluster     = "gke-cluster"
+	TestResourceTypeForwardingRule = "forwarding-rule"
+)
+
+// TestResource is a resource created by a test run, as found by FindStaleTestResources.
+type TestResource struct {
+	Type string
+	Name string
+	// Location is the zone of instances, the region of regional forwarding rules, the location of GKE clusters, and
+	// empty for buckets and global forwarding rules.
+	Location  string
+	RunID     string
+	CreatedAt time.Time
+}
+
+// TerratestRunLabels returns the labels to set on all the resources created by a test run, with a new unique run ID,
+// so they can be cleaned up by DeleteStaleTestResources if the test is interrupted.
+func TerratestRunLabels() map[string]string {
+	return map[string]string{TerratestRunLabelKey: strings.ToLower(random.UniqueId())}
+}
+
+// FindStaleTestResources finds the compute instances, buckets, GKE clusters and forwarding rules of the project that
+// carry the terratest run label and were created more than ttl ago.
+func FindStaleTestResources(t testing.TestingT, projectID string, ttl time.Duration) []TestResource {
+	resources, err := FindStaleTestResourcesE(t, projectID, ttl)
+	require.NoError(t, err)
+	return resources
+}
+
+// FindStaleTestResourcesE finds the compute instances, buckets, GKE clusters and forwarding rules of the project that
+// carry the terratest run label and were created more than ttl ago.
+func FindStaleTestResourcesE(t testing.TestingT, projectID string, ttl time.Duration) ([]TestResource, error) {
+	logger.Default.Logf(t, "Finding test resources older than %s in project %s", ttl, projectID)
+
+	resources := []TestResource{}
+	for _, list := range []func(testing.TestingT, string) ([]TestResource, error){
+		listLabeledInstancesE,
+		listLabeledBucketsE,
+		listLabeledGkeClustersE,
+		listLabeledForwardingRulesE,
+	} {
+		found, err := list(t, projectID)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, found...)
+	}
+
+	return filterStaleTestResources(resources, time.Now(), ttl), nil
+}
+
+// DeleteStaleTestResources deletes the compute instances, buckets, GKE clusters and forwarding rules of the project
+// that carry the terratest run label and were created more than ttl ago, and returns them. This is meant to clean up
+// after interrupted CI runs, e.g. in a scheduled job.
+func DeleteStaleTestResources(t testing.TestingT, projectID string, ttl time.Duration) []TestResource {
+	resources, err := DeleteStaleTestResourcesE(t, projectID, ttl)
+	require.NoError(t, err)
+	return resources
+}
+
+// DeleteStaleTestResourcesE deletes the compute instances, buckets, GKE clusters and forwarding rules of the project
+// that carry the terratest run label and were created more than ttl ago, and returns them. This is meant to clean up
+// after interrupted CI runs, e.g. in a scheduled job. Instances, clusters and forwarding rules are deleted
+// asynchronously: the function doesn't wait for the deletions to complete. It goes on when a deletion fails, and
+// returns the resources deleted along with the errors.
+func DeleteStaleTestResourcesE(t testing.TestingT, projectID string, ttl time.Duration) ([]TestResource, error) {
+	resources, err := FindStaleTestResourcesE(t, projectID, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := []TestResource{}
+	failures := []string{}
+	for _, resource := range resources {
+		if err := deleteTestResourceE(t, projectID, resource); err != nil {
+			failures = append(failures, fmt.Sprintf("%s %s: %v", resource.Type, resource.Name, err))
+			continue
+		}
+		deleted = append(deleted, resource)
+	}
+
+	if len(failures) > 0 {
+		return deleted, fmt.Errorf("failed to delete %d test resources: %s", len(failures), strings.Join(failures, "; "))
+	}
+	return deleted, nil
+}
+
+func deleteTestResourceE(t testing.TestingT, projectID string, resource TestResource) error {
+	logger.Default.Logf(t, "Deleting %s %s of test run %s, created at %s", resource.Type, resource.Name, resource.RunID, resource.CreatedAt)
+
+	switch resource.Type {
+	case TestResourceTypeBucket:
+		if err := EmptyStorageBucketE(t, resource.Name); err != nil {
+			return err
+		}
+		return DeleteStorageBucketE(t, resource.Name)
+	case TestResourceTypeGkeCluster:
+		service, err := NewContainerServiceE(t)
+		if err != nil {
+			return err
+		}
+		_, err = service.Projects.Locations.Clusters.Delete(gkeClusterResourceName(projectID, resource.Location, resource.Name)).Context(context.Background()).Do()
+		return err
+	}
+
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		return err
+	}
+	switch {
+	case resource.Type == TestResourceTypeInstance:
+		_, err = service.Instances.Delete(projectID, resource.Location, resource.Name).Context(context.Background()).Do()
+	case resource.Type == TestResourceTypeForwardingRule && resource.Location == "":
+		_, err = service.GlobalForwardingRules.Delete(projectID, resource.Name).Context(context.Background()).Do()
+	case resource.Type == TestResourceTypeForwardingRule:
+		_, err = service.ForwardingRules.Delete(projectID, resource.Location, resource.Name).Context(context.Background()).Do()
+	default:
+		err = fmt.Errorf("unsupported resource type %s", resource.Type)
+	}
+	return err
+}
+
+func listLabeledInstancesE(t testing.TestingT, projectID string) ([]TestResource, error) {
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := []TestResource{}
+	err = service.Instances.AggregatedList(projectID).Filter(labelFilter()).Pages(context.Background(), func(page *compute.InstanceAggregatedList) error {
+		for _, scopedList := range page.Items {
+			for _, instance := range scopedList.Instances {
+				if resource, ok := newTestResource(TestResourceTypeInstance, instance.Name, path.Base(instance.Zone), instance.Labels, instance.CreationTimestamp); ok {
+					resources = append(resources, resource)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Instances.AggregatedList(%s) got error: %v", projectID, err)
+	}
+
+	return resources, nil
+}
+
+func listLabeledBucketsE(t testing.TestingT, projectID string) ([]TestResource, error) {
+	client, err := newStorageClient(t)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := []TestResource{}
+	it := client.Buckets(context.Background(), projectID)
+	for {
+		bucketAttrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Buckets(%s) got error: %v", projectID, err)
+		}
+		if runID, ok := bucketAttrs.Labels[TerratestRunLabelKey]; ok {
+			resources = append(resources, TestResource{Type: TestResourceTypeBucket, Name: bucketAttrs.Name, RunID: runID, CreatedAt: bucketAttrs.Created})
+		}
+	}
+
+	return resources, nil
+}
+
+func listLabeledGkeClustersE(t testing.TestingT, projectID string) ([]TestResource, error) {
+	service, err := NewContainerServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	// The - location lists the clusters of all the locations
+	response, err := service.Projects.Locations.Clusters.List(fmt.Sprintf("projects/%s/locations/-", projectID)).Context(context.Background()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("Clusters.List(%s) got error: %v", projectID, err)
+	}
+
+	resources := []TestResource{}
+	for _, cluster := range response.Clusters {
+		if resource, ok := newTestResource(TestResourceTypeGkeCluster, cluster.Name, cluster.Location, cluster.ResourceLabels, cluster.CreateTime); ok {
+			resources = append(resources, resource)
+		}
+	}
+	return resources, nil
+}
+
+func listLabeledForwardingRulesE(t testing.TestingT, projectID string) ([]TestResource, error) {
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := []TestResource{}
+	err = service.ForwardingRules.AggregatedList(projectID).Filter(labelFilter()).Pages(context.Background(), func(page *compute.ForwardingRuleAggregatedList) error {
+		for scope, scopedList := range page.Items {
+			// Global forwarding rules are listed below
+			if !strings.HasPrefix(scope, "regions/") {
+				continue
+			}
+			for _, rule := range scopedList.ForwardingRules {
+				if resource, ok := newTestResource(TestResourceTypeForwardingRule, rule.Name, path.Base(rule.Region), rule.Labels, rule.CreationTimestamp); ok {
+					resources = append(resources, resource)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ForwardingRules.AggregatedList(%s) got error: %v", projectID, err)
+	}
+
+	err = service.GlobalForwardingRules.List(projectID).Filter(labelFilter()).Pages(context.Background(), func(page *compute.ForwardingRuleList) error {
+		for _, rule := range page.Items {
+			if resource, ok := newTestResource(TestResourceTypeForwardingRule, rule.Name, "", rule.Labels, rule.CreationTimestamp); ok {
+				resources = append(resources, resource)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("GlobalForwardingRules.List(%s) got error: %v", projectID, err)
+	}
+
+	return resources, nil
+}
+
+// newTestResource returns the resource if it carries the terratest run label. Resources with a creation timestamp that
+// can't be parsed are kept with a zero time, so they are never considered stale.
+func newTestResource(resourceType string, name string, location string, labels map[string]string, creationTimestamp string) (TestResource, bool) {
+	runID, ok := labels[TerratestRunLabelKey]
+	if !ok {
+		return TestResource{}, false
+	}
+	createdAt, _ := time.Parse(time.RFC3339, creationTimestamp)
+	return TestResource{Type: resourceType, Name: name, Location: location, RunID: runID, CreatedAt: createdAt}, true
+}
+
+// filterStaleTestResources returns the resources created more than ttl before now. Resources with an unknown creation
+// time are skipped, rather than deleted without knowing if their test is still running.
+func filterStaleTestResources(resources []TestResource, now time.Time, ttl time.Duration) []TestResource {
+	stale := []TestResource{}
+	for _, resource := range resources {
+		if !resource.CreatedAt.IsZero() && now.Sub(resource.CreatedAt) > ttl {
+			stale = append(stale, resource)
+		}
+	}
+	return stale
+}
+
+// labelFilter returns the compute API filter matching the resources carrying the terratest run label.
+func labelFilter() string {
+	return fmt.Sprintf("labels.%s:*", TerratestRunLabelKey)
+}
@@ -0,0 +1,34 @@
+//go:build gcp
+// +build gcp
+
+// NOTE: We use build tags to differentiate GCP testing for better isolation and parallelism when executing our tests.
+
+package gcp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterStaleTestResources(t *testing.T) {
+	t.Parallel()
+
+	labels := TerratestRunLabels()
+	assert.Regexp(t, "^[a-z0-9]+$", labels[TerratestRunLabelKey])
+
+	_, ok := newTestResource(TestResourceTypeInstance, "unlabeled", "us-central1-a", map[string]string{"env": "test"}, "2024-01-01T00:00:00Z")
+	assert.False(t, ok)
+
+	old, ok := newTestResource(TestResourceTypeInstance, "old", "us-central1-a", labels, "2024-01-01T00:00:00.000-07:00")
+	assert.True(t, ok)
+	recent, _ := newTestResource(TestResourceTypeForwardingRule, "recent", "", labels, "2024-01-01T11:00:00Z")
+	unparsable, _ := newTestResource(TestResourceTypeGkeCluster, "unparsable", "us-central1", labels, "")
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	stale := filterStaleTestResources([]TestResource{old, recent, unparsable}, now, 3*time.Hour)
+	assert.Equal(t, []TestResource{old}, stale)
+	assert.True(t, unparsable.CreatedAt.IsZero())
+	assert.Equal(t, labels[TerratestRunLabelKey], old.RunID)
+}
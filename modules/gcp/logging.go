@@ -0,0 +1,110 @@
+package gcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/logging/v2"
+)
+
+// logEntriesPageSize is the maximum number of log entries fetched per call to the Cloud Logging API.
+const logEntriesPageSize = 1000
+
+// QueryLogs returns the most recent log entries of the project matching the given Cloud Logging filter (e.g.
+// resource.type="cloud_run_revision" AND severity>=ERROR), newest first, up to maxEntries.
+func QueryLogs(t testing.TestingT, projectID string, filter string, maxEntries int) []*logging.LogEntry {
+	entries, err := QueryLogsE(t, projectID, filter, maxEntries)
+	require.NoError(t, err)
+	return entries
+}
+
+// QueryLogsE returns the most recent log entries of the project matching the given Cloud Logging filter (e.g.
+// resource.type="cloud_run_revision" AND severity>=ERROR), newest first, up to maxEntries. Note that, unless the filter
+// restricts the timestamp, only the entries of the last 24 hours are searched.
+func QueryLogsE(t testing.TestingT, projectID string, filter string, maxEntries int) ([]*logging.LogEntry, error) {
+	logger.Default.Logf(t, "Querying logs of project %s: %s", projectID, filter)
+
+	service, err := NewLoggingServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	pageSize := int64(maxEntries)
+	if pageSize > logEntriesPageSize {
+		pageSize = logEntriesPageSize
+	}
+	request := &logging.ListLogEntriesRequest{
+		ResourceNames: []string{fmt.Sprintf("projects/%s", projectID)},
+		Filter:        filter,
+		OrderBy:       "timestamp desc",
+		PageSize:      pageSize,
+	}
+
+	entries := []*logging.LogEntry{}
+	errDone := errors.New("done")
+	err = service.Entries.List(request).Pages(context.Background(), func(page *logging.ListLogEntriesResponse) error {
+		for _, entry := range page.Entries {
+			if len(entries) >= maxEntries {
+				return errDone
+			}
+			entries = append(entries, entry)
+		}
+		if len(entries) >= maxEntries {
+			return errDone
+		}
+		return nil
+	})
+	if err != nil && err != errDone {
+		return nil, fmt.Errorf("Entries.List(%s) got error: %v", projectID, err)
+	}
+
+	return entries, nil
+}
+
+// WaitForLogs waits until the project has at least one log entry matching the given Cloud Logging filter, retrying
+// for the specified amount of times, sleeping for the provided duration between each try, and returns the most recent
+// matching entries, up to maxEntries.
+func WaitForLogs(t testing.TestingT, projectID string, filter string, maxEntries int, retries int, sleepBetweenRetries time.Duration) []*logging.LogEntry {
+	entries, err := WaitForLogsE(t, projectID, filter, maxEntries, retries, sleepBetweenRetries)
+	require.NoError(t, err)
+	return entries
+}
+
+// WaitForLogsE waits until the project has at least one log entry matching the given Cloud Logging filter, retrying
+// for the specified amount of times, sleeping for the provided duration between each try, and returns the most recent
+// matching entries, up to maxEntries.
+func WaitForLogsE(t testing.TestingT, projectID string, filter string, maxEntries int, retries int, sleepBetweenRetries time.Duration) ([]*logging.LogEntry, error) {
+	var entries []*logging.LogEntry
+	description := fmt.Sprintf("Waiting for log entries matching %s", filter)
+	message, err := retry.DoWithRetryE(t, description, retries, sleepBetweenRetries, func() (string, error) {
+		var err error
+		entries, err = QueryLogsE(t, projectID, filter, maxEntries)
+		if err != nil {
+			return "", err
+		}
+		if len(entries) == 0 {
+			return "", fmt.Errorf("no log entry matches %s yet", filter)
+		}
+		return fmt.Sprintf("Found %d log entries", len(entries)), nil
+	})
+	logger.Default.Logf(t, message)
+	return entries, err
+}
+
+// NewLoggingService creates a new Cloud Logging service, which is used to make Cloud Logging API calls.
+func NewLoggingService(t testing.TestingT) *logging.Service {
+	service, err := NewLoggingServiceE(t)
+	require.NoError(t, err)
+	return service
+}
+
+// NewLoggingServiceE creates a new Cloud Logging service, which is used to make Cloud Logging API calls.
+func NewLoggingServiceE(t testing.TestingT) (*logging.Service, error) {
+	return logging.NewService(context.Background(), withOptions(t)...)
+}
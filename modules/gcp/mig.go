@@ -0,0 +1,278 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/compute/v1"
+)
+
+// MigInstance is an instance of a Managed Instance Group, with its status in the group.
+type MigInstance struct {
+	Name string
+	Zone string
+	// Status is the status of the instance, e.g. RUNNING or STAGING.
+	Status string
+	// CurrentAction is the action the group is performing on the instance, e.g. NONE, CREATING or RECREATING.
+	CurrentAction string
+	// HealthState is the health of the instance according to the health check of the group, e.g. HEALTHY, or empty if
+	// the group has no health check.
+	HealthState      string
+	InstanceTemplate string
+}
+
+// GetInstanceGroupManager gets the given Managed Instance Group. The location is a zone for zonal groups, and a region
+// for regional groups.
+func GetInstanceGroupManager(t testing.TestingT, projectID string, location string, name string) *compute.InstanceGroupManager {
+	manager, err := GetInstanceGroupManagerE(t, projectID, location, name)
+	require.NoError(t, err)
+	return manager
+}
+
+// GetInstanceGroupManagerE gets the given Managed Instance Group. The location is a zone for zonal groups, and a
+// region for regional groups.
+func GetInstanceGroupManagerE(t testing.TestingT, projectID string, location string, name string) (*compute.InstanceGroupManager, error) {
+	logger.Default.Logf(t, "Getting Managed Instance Group %s in %s", name, location)
+
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	var manager *compute.InstanceGroupManager
+	if isZone(location) {
+		manager, err = service.InstanceGroupManagers.Get(projectID, location, name).Context(ctx).Do()
+	} else {
+		manager, err = service.RegionInstanceGroupManagers.Get(projectID, location, name).Context(ctx).Do()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("InstanceGroupManagers.Get(%s) got error: %v", name, err)
+	}
+
+	return manager, nil
+}
+
+// WaitForMigStable waits until the given Managed Instance Group is stable, with all its instances running the target
+// version, retrying for the specified amount of times, sleeping for the provided duration between each try.
+func WaitForMigStable(t testing.TestingT, projectID string, location string, name string, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, WaitForMigStableE(t, projectID, location, name, retries, sleepBetweenRetries))
+}
+
+// WaitForMigStableE waits until the given Managed Instance Group is stable, with all its instances running the target
+// version, retrying for the specified amount of times, sleeping for the provided duration between each try.
+func WaitForMigStableE(t testing.TestingT, projectID string, location string, name string, retries int, sleepBetweenRetries time.Duration) error {
+	description := fmt.Sprintf("Waiting for Managed Instance Group %s to be stable", name)
+	message, err := retry.DoWithRetryE(t, description, retries, sleepBetweenRetries, func() (string, error) {
+		manager, err := GetInstanceGroupManagerE(t, projectID, location, name)
+		if err != nil {
+			return "", err
+		}
+		if err := checkMigStable(manager); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Managed Instance Group %s is stable", name), nil
+	})
+	logger.Default.Logf(t, message)
+	return err
+}
+
+// ListMigInstances lists the instances of the given Managed Instance Group, with their status and health.
+func ListMigInstances(t testing.TestingT, projectID string, location string, name string) []MigInstance {
+	instances, err := ListMigInstancesE(t, projectID, location, name)
+	require.NoError(t, err)
+	return instances
+}
+
+// ListMigInstancesE lists the instances of the given Managed Instance Group, with their status and health.
+func ListMigInstancesE(t testing.TestingT, projectID string, location string, name string) ([]MigInstance, error) {
+	logger.Default.Logf(t, "Listing instances of Managed Instance Group %s in %s", name, location)
+
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	managedInstances := []*compute.ManagedInstance{}
+	if isZone(location) {
+		err = service.InstanceGroupManagers.ListManagedInstances(projectID, location, name).Pages(ctx, func(page *compute.InstanceGroupManagersListManagedInstancesResponse) error {
+			managedInstances = append(managedInstances, page.ManagedInstances...)
+			return nil
+		})
+	} else {
+		err = service.RegionInstanceGroupManagers.ListManagedInstances(projectID, location, name).Pages(ctx, func(page *compute.RegionInstanceGroupManagersListInstancesResponse) error {
+			managedInstances = append(managedInstances, page.ManagedInstances...)
+			return nil
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("InstanceGroupManagers.ListManagedInstances(%s) got error: %v", name, err)
+	}
+
+	instances := []MigInstance{}
+	for _, managedInstance := range managedInstances {
+		instances = append(instances, newMigInstance(managedInstance))
+	}
+	return instances, nil
+}
+
+// AssertMigInstancesHealthy checks that the given Managed Instance Group has the expected number of instances, all
+// running, with no pending action and, if the group has a health check, healthy.
+func AssertMigInstancesHealthy(t testing.TestingT, projectID string, location string, name string, expectedCount int) {
+	require.NoError(t, AssertMigInstancesHealthyE(t, projectID, location, name, expectedCount))
+}
+
+// AssertMigInstancesHealthyE checks that the given Managed Instance Group has the expected number of instances, all
+// running, with no pending action and, if the group has a health check, healthy.
+func AssertMigInstancesHealthyE(t testing.TestingT, projectID string, location string, name string, expectedCount int) error {
+	instances, err := ListMigInstancesE(t, projectID, location, name)
+	if err != nil {
+		return err
+	}
+	return checkMigInstancesHealthy(instances, expectedCount)
+}
+
+// StartMigRollingUpdate starts a proactive rolling update of the given Managed Instance Group to the given instance
+// template (name or URL), replacing at most maxUnavailable instances at a time, with up to maxSurge extra instances.
+// Use WaitForMigStable to wait for the update to complete.
+func StartMigRollingUpdate(t testing.TestingT, projectID string, location string, name string, instanceTemplate string, maxSurge int64, maxUnavailable int64) {
+	require.NoError(t, StartMigRollingUpdateE(t, projectID, location, name, instanceTemplate, maxSurge, maxUnavailable))
+}
+
+// StartMigRollingUpdateE starts a proactive rolling update of the given Managed Instance Group to the given instance
+// template (name or URL), replacing at most maxUnavailable instances at a time, with up to maxSurge extra instances.
+// Use WaitForMigStableE to wait for the update to complete. Note that GCP requires regional groups to have a maxSurge
+// and maxUnavailable of at least the number of zones of the group, or 0.
+func StartMigRollingUpdateE(t testing.TestingT, projectID string, location string, name string, instanceTemplate string, maxSurge int64, maxUnavailable int64) error {
+	logger.Default.Logf(t, "Starting rolling update of Managed Instance Group %s to %s", name, instanceTemplate)
+
+	if !strings.Contains(instanceTemplate, "/") {
+		instanceTemplate = fmt.Sprintf("projects/%s/global/instanceTemplates/%s", projectID, instanceTemplate)
+	}
+	patch := &compute.InstanceGroupManager{
+		UpdatePolicy: newProactiveUpdatePolicy(maxSurge, maxUnavailable),
+		Versions:     []*compute.InstanceGroupManagerVersion{{InstanceTemplate: instanceTemplate}},
+	}
+	return patchInstanceGroupManagerE(t, projectID, location, name, patch)
+}
+
+// ReplaceMigInstances starts a rolling replacement of all the instances of the given Managed Instance Group, keeping
+// their instance template, replacing at most maxUnavailable instances at a time, with up to maxSurge extra instances.
+func ReplaceMigInstances(t testing.TestingT, projectID string, location string, name string, maxSurge int64, maxUnavailable int64) {
+	require.NoError(t, ReplaceMigInstancesE(t, projectID, location, name, maxSurge, maxUnavailable))
+}
+
+// ReplaceMigInstancesE starts a rolling replacement of all the instances of the given Managed Instance Group, keeping
+// their instance template, replacing at most maxUnavailable instances at a time, with up to maxSurge extra instances.
+// Like `gcloud compute instance-groups managed rolling-action replace`, it renames the versions of the group so that
+// all the instances are out of date.
+func ReplaceMigInstancesE(t testing.TestingT, projectID string, location string, name string, maxSurge int64, maxUnavailable int64) error {
+	logger.Default.Logf(t, "Replacing the instances of Managed Instance Group %s", name)
+
+	manager, err := GetInstanceGroupManagerE(t, projectID, location, name)
+	if err != nil {
+		return err
+	}
+
+	suffix := time.Now().Unix()
+	versions := []*compute.InstanceGroupManagerVersion{}
+	for i, version := range manager.Versions {
+		versions = append(versions, &compute.InstanceGroupManagerVersion{
+			Name:             fmt.Sprintf("%d-%d", i, suffix),
+			InstanceTemplate: version.InstanceTemplate,
+			TargetSize:       version.TargetSize,
+		})
+	}
+	patch := &compute.InstanceGroupManager{
+		UpdatePolicy: newProactiveUpdatePolicy(maxSurge, maxUnavailable),
+		Versions:     versions,
+	}
+	patch.UpdatePolicy.MinimalAction = "REPLACE"
+	return patchInstanceGroupManagerE(t, projectID, location, name, patch)
+}
+
+func patchInstanceGroupManagerE(t testing.TestingT, projectID string, location string, name string, patch *compute.InstanceGroupManager) error {
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if isZone(location) {
+		_, err = service.InstanceGroupManagers.Patch(projectID, location, name, patch).Context(ctx).Do()
+	} else {
+		_, err = service.RegionInstanceGroupManagers.Patch(projectID, location, name, patch).Context(ctx).Do()
+	}
+	if err != nil {
+		return fmt.Errorf("InstanceGroupManagers.Patch(%s) got error: %v", name, err)
+	}
+
+	return nil
+}
+
+func newProactiveUpdatePolicy(maxSurge int64, maxUnavailable int64) *compute.InstanceGroupManagerUpdatePolicy {
+	return &compute.InstanceGroupManagerUpdatePolicy{
+		Type: "PROACTIVE",
+		// Zeros must be sent explicitly, or the API ignores them
+		MaxSurge:       &compute.FixedOrPercent{Fixed: maxSurge, ForceSendFields: []string{"Fixed"}},
+		MaxUnavailable: &compute.FixedOrPercent{Fixed: maxUnavailable, ForceSendFields: []string{"Fixed"}},
+	}
+}
+
+func checkMigStable(manager *compute.InstanceGroupManager) error {
+	if manager.Status == nil || !manager.Status.IsStable {
+		return fmt.Errorf("Managed Instance Group %s is not stable yet", manager.Name)
+	}
+	if manager.Status.VersionTarget != nil && !manager.Status.VersionTarget.IsReached {
+		return fmt.Errorf("instances of Managed Instance Group %s don't all run the target version yet", manager.Name)
+	}
+	return nil
+}
+
+func checkMigInstancesHealthy(instances []MigInstance, expectedCount int) error {
+	if len(instances) != expectedCount {
+		return fmt.Errorf("found %d instances, expected %d", len(instances), expectedCount)
+	}
+	for _, instance := range instances {
+		if instance.Status != "RUNNING" || instance.CurrentAction != "NONE" {
+			return fmt.Errorf("instance %s is %s with action %s", instance.Name, instance.Status, instance.CurrentAction)
+		}
+		if instance.HealthState != "" && instance.HealthState != "HEALTHY" {
+			return fmt.Errorf("instance %s is %s", instance.Name, instance.HealthState)
+		}
+	}
+	return nil
+}
+
+func newMigInstance(managedInstance *compute.ManagedInstance) MigInstance {
+	// The instance is a URL formatted like .../projects/PROJECT_ID/zones/ZONE/instances/NAME
+	instance := MigInstance{
+		Name:          managedInstance.Name,
+		Zone:          path.Base(path.Dir(path.Dir(managedInstance.Instance))),
+		Status:        managedInstance.InstanceStatus,
+		CurrentAction: managedInstance.CurrentAction,
+	}
+	if instance.Name == "" {
+		instance.Name = path.Base(managedInstance.Instance)
+	}
+	if len(managedInstance.InstanceHealth) > 0 {
+		instance.HealthState = managedInstance.InstanceHealth[0].DetailedHealthState
+	}
+	if managedInstance.Version != nil {
+		instance.InstanceTemplate = path.Base(managedInstance.Version.InstanceTemplate)
+	}
+	return instance
+}
+
+// isZone indicates whether the location is a zone (e.g. us-central1-a) rather than a region (e.g. us-central1).
+func isZone(location string) bool {
+	return strings.Count(location, "-") == 2
+}
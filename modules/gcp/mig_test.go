@@ -0,0 +1,71 @@
+//go:build gcp
+// +build gcp
+
+// NOTE: We use build tags to differentiate GCP testing for better isolation and parallelism when executing our tests.
+
+package gcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/compute/v1"
+)
+
+func TestNewMigInstance(t *testing.T) {
+	t.Parallel()
+
+	instance := newMigInstance(&compute.ManagedInstance{
+		Instance:       "https://www.googleapis.com/compute/v1/projects/my-project/zones/us-central1-b/instances/web-x1z2",
+		InstanceStatus: "RUNNING",
+		CurrentAction:  "NONE",
+		InstanceHealth: []*compute.ManagedInstanceInstanceHealth{{DetailedHealthState: "HEALTHY"}},
+		Version:        &compute.ManagedInstanceVersion{InstanceTemplate: "https://www.googleapis.com/compute/v1/projects/my-project/global/instanceTemplates/web-v2"},
+	})
+
+	assert.Equal(t, MigInstance{
+		Name:             "web-x1z2",
+		Zone:             "us-central1-b",
+		Status:           "RUNNING",
+		CurrentAction:    "NONE",
+		HealthState:      "HEALTHY",
+		InstanceTemplate: "web-v2",
+	}, instance)
+
+	assert.True(t, isZone("us-central1-b"))
+	assert.False(t, isZone("us-central1"))
+}
+
+func TestCheckMigInstancesHealthy(t *testing.T) {
+	t.Parallel()
+
+	instances := []MigInstance{
+		{Name: "a", Status: "RUNNING", CurrentAction: "NONE", HealthState: "HEALTHY"},
+		{Name: "b", Status: "RUNNING", CurrentAction: "NONE"},
+	}
+	require.NoError(t, checkMigInstancesHealthy(instances, 2))
+	require.Error(t, checkMigInstancesHealthy(instances, 3))
+
+	instances[1].CurrentAction = "RECREATING"
+	require.Error(t, checkMigInstancesHealthy(instances, 2))
+
+	instances[1] = MigInstance{Name: "b", Status: "RUNNING", CurrentAction: "NONE", HealthState: "UNHEALTHY"}
+	require.Error(t, checkMigInstancesHealthy(instances, 2))
+}
+
+func TestCheckMigStable(t *testing.T) {
+	t.Parallel()
+
+	manager := &compute.InstanceGroupManager{
+		Name:   "web",
+		Status: &compute.InstanceGroupManagerStatus{IsStable: true, VersionTarget: &compute.InstanceGroupManagerStatusVersionTarget{IsReached: true}},
+	}
+	require.NoError(t, checkMigStable(manager))
+
+	manager.Status.VersionTarget.IsReached = false
+	require.Error(t, checkMigStable(manager))
+
+	manager.Status = nil
+	require.Error(t, checkMigStable(manager))
+}
@@ -0,0 +1,186 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/monitoring/v3"
+)
+
+// QueryMetric runs the given Monitoring Query Language (MQL) query on the time series of the project (e.g.
+// fetch cloud_run_revision | metric 'run.googleapis.com/request_count' | within 10m) and returns the matching series.
+func QueryMetric(t testing.TestingT, projectID string, query string) []*monitoring.TimeSeriesData {
+	series, err := QueryMetricE(t, projectID, query)
+	require.NoError(t, err)
+	return series
+}
+
+// QueryMetricE runs the given Monitoring Query Language (MQL) query on the time series of the project (e.g.
+// fetch cloud_run_revision | metric 'run.googleapis.com/request_count' | within 10m) and returns the matching series.
+func QueryMetricE(t testing.TestingT, projectID string, query string) ([]*monitoring.TimeSeriesData, error) {
+	logger.Default.Logf(t, "Querying metrics of project %s: %s", projectID, query)
+
+	service, err := NewMonitoringServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("projects/%s", projectID)
+	series := []*monitoring.TimeSeriesData{}
+	err = service.Projects.TimeSeries.Query(name, &monitoring.QueryTimeSeriesRequest{Query: query}).Pages(context.Background(), func(page *monitoring.QueryTimeSeriesResponse) error {
+		series = append(series, page.TimeSeriesData...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("TimeSeries.Query(%s) got error: %v", projectID, err)
+	}
+
+	return series, nil
+}
+
+// WaitForMetric waits until the given Monitoring Query Language (MQL) query returns at least one data point, retrying
+// for the specified amount of times, sleeping for the provided duration between each try, and returns the series.
+func WaitForMetric(t testing.TestingT, projectID string, query string, retries int, sleepBetweenRetries time.Duration) []*monitoring.TimeSeriesData {
+	series, err := WaitForMetricE(t, projectID, query, retries, sleepBetweenRetries)
+	require.NoError(t, err)
+	return series
+}
+
+// WaitForMetricE waits until the given Monitoring Query Language (MQL) query returns at least one data point, retrying
+// for the specified amount of times, sleeping for the provided duration between each try, and returns the series. Use
+// a condition in the query (e.g. | condition val() > 100) to wait for a given value.
+func WaitForMetricE(t testing.TestingT, projectID string, query string, retries int, sleepBetweenRetries time.Duration) ([]*monitoring.TimeSeriesData, error) {
+	var series []*monitoring.TimeSeriesData
+	description := fmt.Sprintf("Waiting for metric data points of %s", query)
+	message, err := retry.DoWithRetryE(t, description, retries, sleepBetweenRetries, func() (string, error) {
+		var err error
+		series, err = QueryMetricE(t, projectID, query)
+		if err != nil {
+			return "", err
+		}
+		points := countDataPoints(series)
+		if points == 0 {
+			return "", fmt.Errorf("no data point yet for %s", query)
+		}
+		return fmt.Sprintf("Found %d data points in %d time series", points, len(series)), nil
+	})
+	logger.Default.Logf(t, message)
+	return series, err
+}
+
+// GetAlertPolicyByDisplayName gets the alert policy of the project with the given display name.
+func GetAlertPolicyByDisplayName(t testing.TestingT, projectID string, displayName string) *monitoring.AlertPolicy {
+	policy, err := GetAlertPolicyByDisplayNameE(t, projectID, displayName)
+	require.NoError(t, err)
+	return policy
+}
+
+// GetAlertPolicyByDisplayNameE gets the alert policy of the project with the given display name.
+func GetAlertPolicyByDisplayNameE(t testing.TestingT, projectID string, displayName string) (*monitoring.AlertPolicy, error) {
+	logger.Default.Logf(t, "Getting alert policy %s of project %s", displayName, projectID)
+
+	service, err := NewMonitoringServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("projects/%s", projectID)
+	filter := fmt.Sprintf("display_name = %q", displayName)
+	policies := []*monitoring.AlertPolicy{}
+	err = service.Projects.AlertPolicies.List(name).Filter(filter).Pages(context.Background(), func(page *monitoring.ListAlertPoliciesResponse) error {
+		policies = append(policies, page.AlertPolicies...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("AlertPolicies.List(%s) got error: %v", projectID, err)
+	}
+
+	switch len(policies) {
+	case 0:
+		return nil, fmt.Errorf("alert policy %s not found in project %s", displayName, projectID)
+	case 1:
+		return policies[0], nil
+	default:
+		return nil, fmt.Errorf("found %d alert policies named %s in project %s", len(policies), displayName, projectID)
+	}
+}
+
+// AssertAlertPolicyCondition checks that the given alert policy is enabled and has a condition with the given display
+// name, whose filter or query contains the expected string (e.g. metric.type="run.googleapis.com/request_count").
+func AssertAlertPolicyCondition(t testing.TestingT, projectID string, policyDisplayName string, conditionDisplayName string, expectedQuery string) {
+	require.NoError(t, AssertAlertPolicyConditionE(t, projectID, policyDisplayName, conditionDisplayName, expectedQuery))
+}
+
+// AssertAlertPolicyConditionE checks that the given alert policy is enabled and has a condition with the given display
+// name, whose filter or query contains the expected string (e.g. metric.type="run.googleapis.com/request_count").
+func AssertAlertPolicyConditionE(t testing.TestingT, projectID string, policyDisplayName string, conditionDisplayName string, expectedQuery string) error {
+	policy, err := GetAlertPolicyByDisplayNameE(t, projectID, policyDisplayName)
+	if err != nil {
+		return err
+	}
+	return checkAlertPolicyCondition(policy, conditionDisplayName, expectedQuery)
+}
+
+// NewMonitoringService creates a new Cloud Monitoring service, which is used to make Cloud Monitoring API calls.
+func NewMonitoringService(t testing.TestingT) *monitoring.Service {
+	service, err := NewMonitoringServiceE(t)
+	require.NoError(t, err)
+	return service
+}
+
+// NewMonitoringServiceE creates a new Cloud Monitoring service, which is used to make Cloud Monitoring API calls.
+func NewMonitoringServiceE(t testing.TestingT) (*monitoring.Service, error) {
+	return monitoring.NewService(context.Background(), withOptions(t)...)
+}
+
+func countDataPoints(series []*monitoring.TimeSeriesData) int {
+	points := 0
+	for _, data := range series {
+		points += len(data.PointData)
+	}
+	return points
+}
+
+func checkAlertPolicyCondition(policy *monitoring.AlertPolicy, conditionDisplayName string, expectedQuery string) error {
+	if !policy.Enabled {
+		return fmt.Errorf("alert policy %s is disabled", policy.DisplayName)
+	}
+
+	for _, condition := range policy.Conditions {
+		if condition.DisplayName != conditionDisplayName {
+			continue
+		}
+		query := getAlertConditionQuery(condition)
+		if !strings.Contains(query, expectedQuery) {
+			return fmt.Errorf("condition %s of alert policy %s has query %q, expected it to contain %q", conditionDisplayName, policy.DisplayName, query, expectedQuery)
+		}
+		return nil
+	}
+	return fmt.Errorf("alert policy %s has no condition %s", policy.DisplayName, conditionDisplayName)
+}
+
+// getAlertConditionQuery returns the filter or query of the condition, whatever its type.
+func getAlertConditionQuery(condition *monitoring.Condition) string {
+	switch {
+	case condition.ConditionThreshold != nil:
+		return condition.ConditionThreshold.Filter
+	case condition.ConditionAbsent != nil:
+		return condition.ConditionAbsent.Filter
+	case condition.ConditionMatchedLog != nil:
+		return condition.ConditionMatchedLog.Filter
+	case condition.ConditionMonitoringQueryLanguage != nil:
+		return condition.ConditionMonitoringQueryLanguage.Query
+	case condition.ConditionPrometheusQueryLanguage != nil:
+		return condition.ConditionPrometheusQueryLanguage.Query
+	case condition.ConditionSql != nil:
+		return condition.ConditionSql.Query
+	default:
+		return ""
+	}
+}
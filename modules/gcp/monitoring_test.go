@@ -0,0 +1,47 @@
+//go:build gcp
+// +build gcp
+
+// NOTE: We use build tags to differentiate GCP testing for better isolation and parallelism when executing our tests.
+
+package gcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/monitoring/v3"
+)
+
+func TestCheckAlertPolicyCondition(t *testing.T) {
+	t.Parallel()
+
+	policy := &monitoring.AlertPolicy{
+		DisplayName: "api-errors",
+		Enabled:     true,
+		Conditions: []*monitoring.Condition{
+			{DisplayName: "5xx rate", ConditionThreshold: &monitoring.MetricThreshold{Filter: `resource.type = "cloud_run_revision" AND metric.type = "run.googleapis.com/request_count"`}},
+			{DisplayName: "latency", ConditionMonitoringQueryLanguage: &monitoring.MonitoringQueryLanguageCondition{Query: "fetch cloud_run_revision | metric 'run.googleapis.com/request_latencies'"}},
+		},
+	}
+
+	require.NoError(t, checkAlertPolicyCondition(policy, "5xx rate", `metric.type = "run.googleapis.com/request_count"`))
+	require.NoError(t, checkAlertPolicyCondition(policy, "latency", "run.googleapis.com/request_latencies"))
+	require.Error(t, checkAlertPolicyCondition(policy, "latency", "run.googleapis.com/request_count"))
+	require.Error(t, checkAlertPolicyCondition(policy, "missing", ""))
+
+	policy.Enabled = false
+	require.Error(t, checkAlertPolicyCondition(policy, "5xx rate", ""))
+}
+
+func TestCountDataPoints(t *testing.T) {
+	t.Parallel()
+
+	series := []*monitoring.TimeSeriesData{
+		{PointData: []*monitoring.PointData{{}, {}}},
+		{},
+		{PointData: []*monitoring.PointData{{}}},
+	}
+	assert.Equal(t, 3, countDataPoints(series))
+	assert.Equal(t, 0, countDataPoints(nil))
+}
@@ -0,0 +1,405 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/compute/v1"
+)
+
+// firewallProtocolNumbers maps the protocol names of firewall rules to their IP protocol numbers, as rules can use both.
+var firewallProtocolNumbers = map[string]string{
+	"tcp":  "6",
+	"udp":  "17",
+	"icmp": "1",
+	"esp":  "50",
+	"ah":   "51",
+	"sctp": "132",
+}
+
+// GetFirewallRule gets the given VPC firewall rule.
+func GetFirewallRule(t testing.TestingT, projectID string, ruleName string) *compute.Firewall {
+	rule, err := GetFirewallRuleE(t, projectID, ruleName)
+	require.NoError(t, err)
+	return rule
+}
+
+// GetFirewallRuleE gets the given VPC firewall rule.
+func GetFirewallRuleE(t testing.TestingT, projectID string, ruleName string) (*compute.Firewall, error) {
+	logger.Default.Logf(t, "Getting firewall rule %s", ruleName)
+
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := service.Firewalls.Get(projectID, ruleName).Context(context.Background()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("Firewalls.Get(%s) got error: %v", ruleName, err)
+	}
+
+	return rule, nil
+}
+
+// IsIngressAllowed evaluates the VPC firewall rules that apply to the given instance to tell whether ingress traffic
+// from the source IP with the given protocol (e.g. tcp) and port would be allowed.
+func IsIngressAllowed(t testing.TestingT, projectID string, instanceName string, sourceIP string, protocol string, port int) bool {
+	allowed, err := IsIngressAllowedE(t, projectID, instanceName, sourceIP, protocol, port)
+	require.NoError(t, err)
+	return allowed
+}
+
+// IsIngressAllowedE evaluates the VPC firewall rules that apply to the given instance to tell whether ingress traffic
+// from the source IP with the given protocol (e.g. tcp) and port would be allowed. The rules are evaluated the way GCP
+// does: the rule with the highest priority wins, deny rules win over allow rules of the same priority, and ingress is
+// denied when no rule matches. Only the network of the first interface of the instance is considered, and
+// hierarchical and network firewall policies are not.
+func IsIngressAllowedE(t testing.TestingT, projectID string, instanceName string, sourceIP string, protocol string, port int) (bool, error) {
+	ip := net.ParseIP(sourceIP)
+	if ip == nil {
+		return false, fmt.Errorf("%s is not a valid IP address", sourceIP)
+	}
+
+	instance, err := FetchInstanceE(t, projectID, instanceName)
+	if err != nil {
+		return false, err
+	}
+	if len(instance.NetworkInterfaces) == 0 {
+		return false, fmt.Errorf("instance %s has no network interface", instanceName)
+	}
+
+	// Firewall rules belong to the project of the network, which is a different project with Shared VPC
+	network := instance.NetworkInterfaces[0].Network
+	networkProjectID := getProjectFromResourceURL(network)
+	if networkProjectID == "" {
+		networkProjectID = projectID
+	}
+
+	firewalls, err := listFirewallRulesE(t, networkProjectID)
+	if err != nil {
+		return false, err
+	}
+
+	target := firewallTarget{network: network}
+	if instance.Tags != nil {
+		target.tags = instance.Tags.Items
+	}
+	for _, serviceAccount := range instance.ServiceAccounts {
+		target.serviceAccounts = append(target.serviceAccounts, serviceAccount.Email)
+	}
+
+	allowed, rule := evaluateIngress(firewalls, target, ip, protocol, port)
+	if rule != nil {
+		logger.Default.Logf(t, "Ingress from %s to instance %s on %s/%d matches firewall rule %s", sourceIP, instanceName, protocol, port, rule.Name)
+	} else {
+		logger.Default.Logf(t, "Ingress from %s to instance %s on %s/%d matches no firewall rule", sourceIP, instanceName, protocol, port)
+	}
+	return allowed, nil
+}
+
+// GetSubnetwork gets the given VPC subnetwork.
+func GetSubnetwork(t testing.TestingT, projectID string, region string, subnetworkName string) *compute.Subnetwork {
+	subnetwork, err := GetSubnetworkE(t, projectID, region, subnetworkName)
+	require.NoError(t, err)
+	return subnetwork
+}
+
+// GetSubnetworkE gets the given VPC subnetwork.
+func GetSubnetworkE(t testing.TestingT, projectID string, region string, subnetworkName string) (*compute.Subnetwork, error) {
+	logger.Default.Logf(t, "Getting subnetwork %s in %s", subnetworkName, region)
+
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	subnetwork, err := service.Subnetworks.Get(projectID, region, subnetworkName).Context(context.Background()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("Subnetworks.Get(%s) got error: %v", subnetworkName, err)
+	}
+
+	return subnetwork, nil
+}
+
+// AssertSubnetworkCidr checks that the primary IP range of the given subnetwork is the expected CIDR block.
+func AssertSubnetworkCidr(t testing.TestingT, projectID string, region string, subnetworkName string, expectedCidr string) {
+	require.NoError(t, AssertSubnetworkCidrE(t, projectID, region, subnetworkName, expectedCidr))
+}
+
+// AssertSubnetworkCidrE checks that the primary IP range of the given subnetwork is the expected CIDR block.
+func AssertSubnetworkCidrE(t testing.TestingT, projectID string, region string, subnetworkName string, expectedCidr string) error {
+	subnetwork, err := GetSubnetworkE(t, projectID, region, subnetworkName)
+	if err != nil {
+		return err
+	}
+	if subnetwork.IpCidrRange != expectedCidr {
+		return fmt.Errorf("subnetwork %s has IP range %s, expected %s", subnetworkName, subnetwork.IpCidrRange, expectedCidr)
+	}
+	return nil
+}
+
+// AssertSubnetworkSecondaryRange checks that the given subnetwork has a secondary IP range with the given name (e.g.
+// the pods or services range of a GKE cluster) and CIDR block.
+func AssertSubnetworkSecondaryRange(t testing.TestingT, projectID string, region string, subnetworkName string, rangeName string, expectedCidr string) {
+	require.NoError(t, AssertSubnetworkSecondaryRangeE(t, projectID, region, subnetworkName, rangeName, expectedCidr))
+}
+
+// AssertSubnetworkSecondaryRangeE checks that the given subnetwork has a secondary IP range with the given name (e.g.
+// the pods or services range of a GKE cluster) and CIDR block.
+func AssertSubnetworkSecondaryRangeE(t testing.TestingT, projectID string, region string, subnetworkName string, rangeName string, expectedCidr string) error {
+	subnetwork, err := GetSubnetworkE(t, projectID, region, subnetworkName)
+	if err != nil {
+		return err
+	}
+	for _, secondaryRange := range subnetwork.SecondaryIpRanges {
+		if secondaryRange.RangeName != rangeName {
+			continue
+		}
+		if secondaryRange.IpCidrRange != expectedCidr {
+			return fmt.Errorf("secondary range %s of subnetwork %s has IP range %s, expected %s", rangeName, subnetworkName, secondaryRange.IpCidrRange, expectedCidr)
+		}
+		return nil
+	}
+	return fmt.Errorf("subnetwork %s has no secondary range %s", subnetworkName, rangeName)
+}
+
+// GetRouterNat gets the given Cloud NAT gateway of the Cloud Router.
+func GetRouterNat(t testing.TestingT, projectID string, region string, routerName string, natName string) *compute.RouterNat {
+	nat, err := GetRouterNatE(t, projectID, region, routerName, natName)
+	require.NoError(t, err)
+	return nat
+}
+
+// GetRouterNatE gets the given Cloud NAT gateway of the Cloud Router.
+func GetRouterNatE(t testing.TestingT, projectID string, region string, routerName string, natName string) (*compute.RouterNat, error) {
+	router, err := getRouterE(t, projectID, region, routerName)
+	if err != nil {
+		return nil, err
+	}
+	for _, nat := range router.Nats {
+		if nat.Name == natName {
+			return nat, nil
+		}
+	}
+	return nil, fmt.Errorf("Cloud Router %s has no Cloud NAT %s", routerName, natName)
+}
+
+// AssertSubnetworkUsesCloudNat checks that the given Cloud NAT gateway of the Cloud Router translates the traffic of
+// the given subnetwork, which must be in the same region.
+func AssertSubnetworkUsesCloudNat(t testing.TestingT, projectID string, region string, routerName string, natName string, subnetworkName string) {
+	require.NoError(t, AssertSubnetworkUsesCloudNatE(t, projectID, region, routerName, natName, subnetworkName))
+}
+
+// AssertSubnetworkUsesCloudNatE checks that the given Cloud NAT gateway of the Cloud Router translates the traffic of
+// the given subnetwork, which must be in the same region.
+func AssertSubnetworkUsesCloudNatE(t testing.TestingT, projectID string, region string, routerName string, natName string, subnetworkName string) error {
+	router, err := getRouterE(t, projectID, region, routerName)
+	if err != nil {
+		return err
+	}
+	subnetwork, err := GetSubnetworkE(t, projectID, region, subnetworkName)
+	if err != nil {
+		return err
+	}
+	return checkSubnetworkUsesNat(router, natName, subnetwork)
+}
+
+func getRouterE(t testing.TestingT, projectID string, region string, routerName string) (*compute.Router, error) {
+	logger.Default.Logf(t, "Getting Cloud Router %s in %s", routerName, region)
+
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	router, err := service.Routers.Get(projectID, region, routerName).Context(context.Background()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("Routers.Get(%s) got error: %v", routerName, err)
+	}
+
+	return router, nil
+}
+
+func listFirewallRulesE(t testing.TestingT, projectID string) ([]*compute.Firewall, error) {
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	firewalls := []*compute.Firewall{}
+	err = service.Firewalls.List(projectID).Pages(context.Background(), func(page *compute.FirewallList) error {
+		firewalls = append(firewalls, page.Items...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Firewalls.List(%s) got error: %v", projectID, err)
+	}
+
+	return firewalls, nil
+}
+
+// firewallTarget is what firewall rules are matched against on the receiving side of the traffic.
+type firewallTarget struct {
+	network         string
+	tags            []string
+	serviceAccounts []string
+}
+
+// evaluateIngress returns whether the ingress traffic is allowed by the firewall rules, and the rule deciding it, if
+// any.
+func evaluateIngress(firewalls []*compute.Firewall, target firewallTarget, sourceIP net.IP, protocol string, port int) (bool, *compute.Firewall) {
+	var decidingRule *compute.Firewall
+	allowed := false
+
+	for _, rule := range firewalls {
+		if rule.Disabled || (rule.Direction != "" && rule.Direction != "INGRESS") || rule.Network != target.network {
+			continue
+		}
+		if !firewallRuleAppliesToTarget(rule, target) || !ipInRanges(sourceIP, rule.SourceRanges) {
+			continue
+		}
+
+		ruleAllows := false
+		matches := false
+		for _, denied := range rule.Denied {
+			if firewallProtocolMatches(denied.IPProtocol, protocol) && firewallPortsMatch(denied.Ports, port) {
+				matches = true
+			}
+		}
+		if !matches {
+			for _, allowedTraffic := range rule.Allowed {
+				if firewallProtocolMatches(allowedTraffic.IPProtocol, protocol) && firewallPortsMatch(allowedTraffic.Ports, port) {
+					matches = true
+					ruleAllows = true
+				}
+			}
+		}
+		if !matches {
+			continue
+		}
+
+		// Lower numbers mean higher priorities, and deny rules win at the same priority
+		if decidingRule == nil || rule.Priority < decidingRule.Priority || (rule.Priority == decidingRule.Priority && allowed && !ruleAllows) {
+			decidingRule = rule
+			allowed = ruleAllows
+		}
+	}
+
+	return allowed, decidingRule
+}
+
+func firewallRuleAppliesToTarget(rule *compute.Firewall, target firewallTarget) bool {
+	if len(rule.TargetTags) == 0 && len(rule.TargetServiceAccounts) == 0 {
+		return true
+	}
+	for _, tag := range rule.TargetTags {
+		for _, targetTag := range target.tags {
+			if tag == targetTag {
+				return true
+			}
+		}
+	}
+	for _, serviceAccount := range rule.TargetServiceAccounts {
+		for _, targetServiceAccount := range target.serviceAccounts {
+			if serviceAccount == targetServiceAccount {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func firewallProtocolMatches(ruleProtocol string, protocol string) bool {
+	ruleProtocol = strings.ToLower(ruleProtocol)
+	protocol = strings.ToLower(protocol)
+	if ruleProtocol == "all" || ruleProtocol == protocol {
+		return true
+	}
+	if number, ok := firewallProtocolNumbers[ruleProtocol]; ok && number == protocol {
+		return true
+	}
+	number, ok := firewallProtocolNumbers[protocol]
+	return ok && number == ruleProtocol
+}
+
+// firewallPortsMatch checks the port against the ports of a rule, which are single ports (80) or ranges (8000-8080).
+// Rules without ports match all the ports.
+func firewallPortsMatch(ports []string, port int) bool {
+	if len(ports) == 0 {
+		return true
+	}
+	for _, portRange := range ports {
+		bounds := strings.SplitN(portRange, "-", 2)
+		low, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			continue
+		}
+		high := low
+		if len(bounds) == 2 {
+			if high, err = strconv.Atoi(bounds[1]); err != nil {
+				continue
+			}
+		}
+		if port >= low && port <= high {
+			return true
+		}
+	}
+	return false
+}
+
+func ipInRanges(ip net.IP, cidrs []string) bool {
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			// Source ranges can also be single IP addresses
+			if rangeIP := net.ParseIP(cidr); rangeIP != nil && rangeIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkSubnetworkUsesNat(router *compute.Router, natName string, subnetwork *compute.Subnetwork) error {
+	for _, nat := range router.Nats {
+		if nat.Name != natName {
+			continue
+		}
+		switch nat.SourceSubnetworkIpRangesToNat {
+		case "ALL_SUBNETWORKS_ALL_IP_RANGES", "ALL_SUBNETWORKS_ALL_PRIMARY_IP_RANGES":
+			if subnetwork.Network == router.Network {
+				return nil
+			}
+		default:
+			for _, natSubnetwork := range nat.Subnetworks {
+				if natSubnetwork.Name == subnetwork.SelfLink {
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("Cloud NAT %s does not translate the traffic of subnetwork %s", natName, subnetwork.Name)
+	}
+	return fmt.Errorf("Cloud Router %s has no Cloud NAT %s", router.Name, natName)
+}
+
+// getProjectFromResourceURL returns the project of a resource URL formatted like
+// https://www.googleapis.com/compute/v1/projects/PROJECT_ID/global/networks/NETWORK.
+func getProjectFromResourceURL(resourceURL string) string {
+	parts := strings.Split(resourceURL, "/")
+	for i := 0; i < len(parts)-1; i++ {
+		if parts[i] == "projects" {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,119 @@
+//go:build gcp
+// +build gcp
+
+// NOTE: We use build tags to differentiate GCP testing for better isolation and parallelism when executing our tests.
+
+package gcp
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/compute/v1"
+)
+
+const testNetworkURL = "https://www.googleapis.com/compute/v1/projects/host-project/global/networks/vpc-main"
+
+func TestEvaluateIngress(t *testing.T) {
+	t.Parallel()
+
+	firewalls := []*compute.Firewall{
+		{
+			Name:         "allow-web",
+			Network:      testNetworkURL,
+			Direction:    "INGRESS",
+			Priority:     1000,
+			SourceRanges: []string{"0.0.0.0/0"},
+			TargetTags:   []string{"web"},
+			Allowed:      []*compute.FirewallAllowed{{IPProtocol: "tcp", Ports: []string{"80", "8000-8080"}}},
+		},
+		{
+			Name:         "deny-bad-actor",
+			Network:      testNetworkURL,
+			Direction:    "INGRESS",
+			Priority:     1000,
+			SourceRanges: []string{"203.0.113.0/24"},
+			Denied:       []*compute.FirewallDenied{{IPProtocol: "all"}},
+		},
+		{
+			Name:         "allow-ssh-from-iap",
+			Network:      testNetworkURL,
+			Direction:    "INGRESS",
+			Priority:     900,
+			SourceRanges: []string{"35.235.240.0/20"},
+			Allowed:      []*compute.FirewallAllowed{{IPProtocol: "6", Ports: []string{"22"}}},
+		},
+		{
+			Name:         "disabled",
+			Network:      testNetworkURL,
+			Direction:    "INGRESS",
+			Priority:     1,
+			Disabled:     true,
+			SourceRanges: []string{"0.0.0.0/0"},
+			Allowed:      []*compute.FirewallAllowed{{IPProtocol: "all"}},
+		},
+	}
+	web := firewallTarget{network: testNetworkURL, tags: []string{"web"}}
+
+	testCases := []struct {
+		name         string
+		target       firewallTarget
+		sourceIP     string
+		protocol     string
+		port         int
+		allowed      bool
+		decidingRule string
+	}{
+		{"allowed port", web, "198.51.100.1", "tcp", 80, true, "allow-web"},
+		{"allowed port range", web, "198.51.100.1", "TCP", 8080, true, "allow-web"},
+		{"port not allowed", web, "198.51.100.1", "tcp", 443, false, ""},
+		{"deny wins at same priority", web, "203.0.113.7", "tcp", 80, false, "deny-bad-actor"},
+		{"protocol number", web, "35.235.240.10", "tcp", 22, true, "allow-ssh-from-iap"},
+		{"target tag missing", firewallTarget{network: testNetworkURL}, "198.51.100.1", "tcp", 80, false, ""},
+		{"other network", firewallTarget{network: "other", tags: []string{"web"}}, "198.51.100.1", "tcp", 80, false, ""},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			allowed, rule := evaluateIngress(firewalls, testCase.target, net.ParseIP(testCase.sourceIP), testCase.protocol, testCase.port)
+			assert.Equal(t, testCase.allowed, allowed)
+			if testCase.decidingRule == "" {
+				assert.Nil(t, rule)
+			} else {
+				require.NotNil(t, rule)
+				assert.Equal(t, testCase.decidingRule, rule.Name)
+			}
+		})
+	}
+}
+
+func TestCheckSubnetworkUsesNat(t *testing.T) {
+	t.Parallel()
+
+	subnetwork := &compute.Subnetwork{
+		Name:     "private",
+		Network:  testNetworkURL,
+		SelfLink: "https://www.googleapis.com/compute/v1/projects/host-project/regions/us-central1/subnetworks/private",
+	}
+	router := &compute.Router{
+		Name:    "router",
+		Network: testNetworkURL,
+		Nats: []*compute.RouterNat{
+			{Name: "all", SourceSubnetworkIpRangesToNat: "ALL_SUBNETWORKS_ALL_IP_RANGES"},
+			{Name: "listed", SourceSubnetworkIpRangesToNat: "LIST_OF_SUBNETWORKS", Subnetworks: []*compute.RouterNatSubnetworkToNat{{Name: subnetwork.SelfLink}}},
+			{Name: "other", SourceSubnetworkIpRangesToNat: "LIST_OF_SUBNETWORKS"},
+		},
+	}
+
+	require.NoError(t, checkSubnetworkUsesNat(router, "all", subnetwork))
+	require.NoError(t, checkSubnetworkUsesNat(router, "listed", subnetwork))
+	require.Error(t, checkSubnetworkUsesNat(router, "other", subnetwork))
+	require.Error(t, checkSubnetworkUsesNat(router, "missing", subnetwork))
+
+	assert.Equal(t, "host-project", getProjectFromResourceURL(testNetworkURL))
+}
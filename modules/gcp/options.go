@@ -0,0 +1,218 @@
+package gcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/idtoken"
+	"google.golang.org/api/impersonate"
+	"google.golang.org/api/option"
+)
+
+// cloudPlatformScope is the OAuth scope requested when Options doesn't set any.
+const cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// Options configures how the helpers of this package authenticate to GCP for a given test. By default, the helpers use
+// the GOOGLE_OAUTH_ACCESS_TOKEN environment variable if set, or the application default credentials otherwise.
+type Options struct {
+	// CredentialsFile is the path of a credential configuration file: either a service account key, or an external
+	// account configuration for workload identity federation (e.g. GitHub OIDC), as generated by
+	// `gcloud iam workload-identity-pools create-cred-config`.
+	CredentialsFile string
+
+	// CredentialsJSON is the content of a credential configuration file, used instead of CredentialsFile.
+	CredentialsJSON []byte
+
+	// ImpersonateServiceAccount is the email of a service account to impersonate with the credentials above, or with
+	// the application default credentials if none are set. The caller needs the Service Account Token Creator role on
+	// it, or on the last of the Delegates.
+	ImpersonateServiceAccount string
+
+	// Delegates is the chain of service accounts to go through to impersonate ImpersonateServiceAccount, each one
+	// having the Service Account Token Creator role on the next.
+	Delegates []string
+
+	// Scopes are the OAuth scopes requested. Defaults to the cloud-platform scope.
+	Scopes []string
+}
+
+// testTokenSources holds the token sources configured with SetTestOptions, along with their options to mint ID
+// tokens with, by test name.
+var testTokenSources = struct {
+	sync.Mutex
+	sources map[string]oauth2.TokenSource
+	options map[string]*Options
+}{sources: map[string]oauth2.TokenSource{}, options: map[string]*Options{}}
+
+// SetTestOptions configures the credentials used by the helpers of this package for the given test and its subtests.
+// Call ClearTestOptions when the test is done.
+func SetTestOptions(t testing.TestingT, options *Options) {
+	require.NoError(t, SetTestOptionsE(t, options))
+}
+
+// SetTestOptionsE configures the credentials used by the helpers of this package for the given test and its subtests.
+// Call ClearTestOptions when the test is done.
+func SetTestOptionsE(t testing.TestingT, options *Options) error {
+	tokenSource, err := options.TokenSourceE(context.Background())
+	if err != nil {
+		return err
+	}
+
+	logger.Default.Logf(t, "Setting GCP credentials for test %s", t.Name())
+
+	testTokenSources.Lock()
+	defer testTokenSources.Unlock()
+	testTokenSources.sources[t.Name()] = tokenSource
+	testTokenSources.options[t.Name()] = options
+	return nil
+}
+
+// ClearTestOptions removes the credentials configured with SetTestOptions for the given test, so its helpers go back
+// to using the default credentials.
+func ClearTestOptions(t testing.TestingT) {
+	testTokenSources.Lock()
+	defer testTokenSources.Unlock()
+	delete(testTokenSources.sources, t.Name())
+	delete(testTokenSources.options, t.Name())
+}
+
+// TokenSourceE returns a token source for the credentials of the options. The tokens are fetched when first used, and
+// are refreshed when they expire.
+func (options *Options) TokenSourceE(ctx context.Context) (oauth2.TokenSource, error) {
+	if options == nil {
+		return nil, errors.New("no GCP options given")
+	}
+
+	scopes := options.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{cloudPlatformScope}
+	}
+
+	credentialsJSON, err := options.readCredentialsJSONE()
+	if err != nil {
+		return nil, err
+	}
+
+	var credentials *google.Credentials
+	if len(credentialsJSON) > 0 {
+		credentials, err = google.CredentialsFromJSON(ctx, credentialsJSON, scopes...)
+	} else {
+		credentials, err = google.FindDefaultCredentials(ctx, scopes...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load GCP credentials: %v", err)
+	}
+
+	if options.ImpersonateServiceAccount == "" {
+		return credentials.TokenSource, nil
+	}
+
+	config := impersonate.CredentialsConfig{
+		TargetPrincipal: options.ImpersonateServiceAccount,
+		Delegates:       options.Delegates,
+		Scopes:          scopes,
+	}
+	tokenSource, err := impersonate.CredentialsTokenSource(ctx, config, option.WithTokenSource(credentials.TokenSource))
+	if err != nil {
+		return nil, fmt.Errorf("failed to impersonate service account %s: %v", options.ImpersonateServiceAccount, err)
+	}
+	return tokenSource, nil
+}
+
+// IDTokenSourceE returns a token source of Google-signed ID tokens for the given audience (e.g. the URL of a Cloud Run
+// service) for the credentials of the options. User credentials can't mint ID tokens: use a service account key, a
+// workload identity federation configuration impersonating a service account, or ImpersonateServiceAccount.
+func (options *Options) IDTokenSourceE(ctx context.Context, audience string) (oauth2.TokenSource, error) {
+	if options == nil {
+		return nil, errors.New("no GCP options given")
+	}
+
+	if options.ImpersonateServiceAccount != "" {
+		// The credentials impersonating the service account only need access tokens
+		sourceOptions := *options
+		sourceOptions.ImpersonateServiceAccount = ""
+		sourceOptions.Delegates = nil
+		sourceTokenSource, err := sourceOptions.TokenSourceE(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		config := impersonate.IDTokenConfig{
+			Audience:        audience,
+			TargetPrincipal: options.ImpersonateServiceAccount,
+			Delegates:       options.Delegates,
+			IncludeEmail:    true,
+		}
+		tokenSource, err := impersonate.IDTokenSource(ctx, config, option.WithTokenSource(sourceTokenSource))
+		if err != nil {
+			return nil, fmt.Errorf("failed to impersonate service account %s: %v", options.ImpersonateServiceAccount, err)
+		}
+		return tokenSource, nil
+	}
+
+	credentialsJSON, err := options.readCredentialsJSONE()
+	if err != nil {
+		return nil, err
+	}
+	var idTokenOptions []idtoken.ClientOption
+	if len(credentialsJSON) > 0 {
+		idTokenOptions = append(idTokenOptions, idtoken.WithCredentialsJSON(credentialsJSON))
+	}
+	return idtoken.NewTokenSource(ctx, audience, idTokenOptions...)
+}
+
+// readCredentialsJSONE returns CredentialsJSON, or the content of CredentialsFile, or nil if neither is set.
+func (options *Options) readCredentialsJSONE() ([]byte, error) {
+	if len(options.CredentialsJSON) > 0 || options.CredentialsFile == "" {
+		return options.CredentialsJSON, nil
+	}
+	content, err := os.ReadFile(options.CredentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GCP credentials file %s: %v", options.CredentialsFile, err)
+	}
+	return content, nil
+}
+
+// getTokenSource returns the token source configured for the test or, failing that, for the static token. Subtests
+// use the token source of their parent test if they don't have their own.
+func getTokenSource(t testing.TestingT) (oauth2.TokenSource, bool) {
+	testTokenSources.Lock()
+	defer testTokenSources.Unlock()
+
+	if tokenSource, ok := lookupForTest(testTokenSources.sources, t.Name()); ok {
+		return tokenSource, true
+	}
+	return getStaticTokenSource()
+}
+
+// getTestOptions returns the options configured with SetTestOptions for the test, or its closest parent test.
+func getTestOptions(t testing.TestingT) (*Options, bool) {
+	testTokenSources.Lock()
+	defer testTokenSources.Unlock()
+
+	return lookupForTest(testTokenSources.options, t.Name())
+}
+
+// lookupForTest returns the value for the test with the given name or, failing that, for its closest parent test.
+func lookupForTest[T any](values map[string]T, name string) (T, bool) {
+	for {
+		if value, ok := values[name]; ok {
+			return value, true
+		}
+		index := strings.LastIndex(name, "/")
+		if index < 0 {
+			var empty T
+			return empty, false
+		}
+		name = name[:index]
+	}
+}
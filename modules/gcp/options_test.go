@@ -0,0 +1,85 @@
+//go:build gcp
+// +build gcp
+
+// NOTE: We use build tags to differentiate GCP testing for better isolation and parallelism when executing our tests.
+
+package gcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAuthorizedUserJSON = `{
+  "type": "authorized_user",
+  "client_id": "client-id.apps.googleusercontent.com",
+  "client_secret": "client-secret",
+  "refresh_token": "refresh-token"
+}`
+
+func TestSetTestOptions(t *testing.T) {
+	t.Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", "static-token")
+
+	staticTokenSource, ok := getTokenSource(t)
+	require.True(t, ok)
+
+	options := &Options{
+		CredentialsJSON:           []byte(testAuthorizedUserJSON),
+		ImpersonateServiceAccount: "deployer@my-project.iam.gserviceaccount.com",
+	}
+	SetTestOptions(t, options)
+	tokenSource, ok := getTokenSource(t)
+	require.True(t, ok)
+	assert.NotEqual(t, staticTokenSource, tokenSource)
+
+	t.Run("subtest", func(t *testing.T) {
+		subtestTokenSource, ok := getTokenSource(t)
+		require.True(t, ok)
+		assert.Equal(t, tokenSource, subtestTokenSource)
+
+		subtestOptions, ok := getTestOptions(t)
+		require.True(t, ok)
+		assert.Same(t, options, subtestOptions)
+	})
+
+	ClearTestOptions(t)
+	tokenSource, ok = getTokenSource(t)
+	require.True(t, ok)
+	assert.Equal(t, staticTokenSource, tokenSource)
+	_, ok = getTestOptions(t)
+	assert.False(t, ok)
+}
+
+func TestSetTestOptionsInvalidCredentials(t *testing.T) {
+	t.Parallel()
+
+	require.Error(t, SetTestOptionsE(t, &Options{CredentialsFile: "non-existent-credentials.json"}))
+	require.Error(t, SetTestOptionsE(t, &Options{CredentialsJSON: []byte("{}")}))
+	require.Error(t, SetTestOptionsE(t, nil))
+}
+
+func TestIDTokenSource(t *testing.T) {
+	t.Parallel()
+
+	audience := "https://my-service-abc123-uc.a.run.app"
+
+	// The ID tokens of the impersonated service account are only minted when the first token is requested
+	_, err := (&Options{
+		CredentialsJSON:           []byte(testAuthorizedUserJSON),
+		ImpersonateServiceAccount: "invoker@my-project.iam.gserviceaccount.com",
+	}).IDTokenSourceE(context.Background(), audience)
+	require.NoError(t, err)
+
+	// User credentials can't mint ID tokens themselves
+	_, err = (&Options{CredentialsJSON: []byte(testAuthorizedUserJSON)}).IDTokenSourceE(context.Background(), audience)
+	require.Error(t, err)
+
+	_, err = GetIDTokenWithOptionsE(t, &Options{CredentialsFile: "non-existent-credentials.json"}, audience)
+	require.ErrorContains(t, err, "non-existent-credentials.json")
+
+	_, err = GetIDTokenWithOptionsE(t, nil, audience)
+	require.Error(t, err)
+}
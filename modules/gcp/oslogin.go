@@ -146,7 +146,7 @@ func GetLoginProfileE(t testing.TestingT, user string) (*oslogin.LoginProfile, e
 func NewOSLoginServiceE(t testing.TestingT) (*oslogin.Service, error) {
 	ctx := context.Background()
 
-	if ts, ok := getStaticTokenSource(); ok {
+	if ts, ok := getTokenSource(t); ok {
 		return oslogin.New(oauth2.NewClient(ctx, ts))
 	}
 
@@ -0,0 +1,377 @@
+package gcp
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	pubsub "google.golang.org/api/pubsub/v1"
+)
+
+// PubSubMessage is a message received from a Pub/Sub subscription, with its data decoded.
+type PubSubMessage struct {
+	ID              string
+	Data            []byte
+	Attributes      map[string]string
+	DeliveryAttempt int64
+}
+
+// PublishMessage publishes a message with the given data and attributes to the given Pub/Sub topic, and returns the
+// ID of the published message.
+func PublishMessage(t testing.TestingT, projectID string, topicName string, data []byte, attributes map[string]string) string {
+	messageID, err := PublishMessageE(t, projectID, topicName, data, attributes)
+	require.NoError(t, err)
+	return messageID
+}
+
+// PublishMessageE publishes a message with the given data and attributes to the given Pub/Sub topic, and returns the
+// ID of the published message.
+func PublishMessageE(t testing.TestingT, projectID string, topicName string, data []byte, attributes map[string]string) (string, error) {
+	logger.Default.Logf(t, "Publishing message to Pub/Sub topic %s", topicName)
+
+	service, err := NewPubSubServiceE(t)
+	if err != nil {
+		return "", err
+	}
+
+	req := &pubsub.PublishRequest{
+		Messages: []*pubsub.PubsubMessage{
+			{
+				Data:       base64.StdEncoding.EncodeToString(data),
+				Attributes: attributes,
+			},
+		},
+	}
+
+	resp, err := service.Projects.Topics.Publish(pubSubTopicResourceName(projectID, topicName), req).Context(context.Background()).Do()
+	if err != nil {
+		return "", fmt.Errorf("Topics.Publish(%s) got error: %v", topicName, err)
+	}
+	if len(resp.MessageIds) != 1 {
+		return "", fmt.Errorf("Topics.Publish(%s) returned %d message IDs, expected 1", topicName, len(resp.MessageIds))
+	}
+
+	return resp.MessageIds[0], nil
+}
+
+// PullMessages pulls up to maxMessages messages from the given Pub/Sub subscription. If acknowledge is true, the
+// pulled messages are acknowledged so they won't be redelivered.
+func PullMessages(t testing.TestingT, projectID string, subscriptionName string, maxMessages int64, acknowledge bool) []*PubSubMessage {
+	messages, err := PullMessagesE(t, projectID, subscriptionName, maxMessages, acknowledge)
+	require.NoError(t, err)
+	return messages
+}
+
+// PullMessagesE pulls up to maxMessages messages from the given Pub/Sub subscription. If acknowledge is true, the
+// pulled messages are acknowledged so they won't be redelivered.
+func PullMessagesE(t testing.TestingT, projectID string, subscriptionName string, maxMessages int64, acknowledge bool) ([]*PubSubMessage, error) {
+	received, err := pullMessagesE(t, projectID, subscriptionName, maxMessages)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := decodePubSubMessages(received)
+	if err != nil {
+		return nil, err
+	}
+
+	if acknowledge {
+		if err := acknowledgeMessagesE(t, projectID, subscriptionName, getAckIDs(received)); err != nil {
+			return nil, err
+		}
+	}
+	return messages, nil
+}
+
+// PublishAndAssertDelivery publishes a message to the given topic and checks that it is delivered to the given
+// subscription, retrying the pull for the specified amount of times, sleeping for the provided duration between each
+// try. The delivered message is acknowledged and returned.
+func PublishAndAssertDelivery(t testing.TestingT, projectID string, topicName string, subscriptionName string, data []byte, attributes map[string]string, retries int, sleepBetweenRetries time.Duration) *PubSubMessage {
+	message, err := PublishAndAssertDeliveryE(t, projectID, topicName, subscriptionName, data, attributes, retries, sleepBetweenRetries)
+	require.NoError(t, err)
+	return message
+}
+
+// PublishAndAssertDeliveryE publishes a message to the given topic and checks that it is delivered to the given
+// subscription, retrying the pull for the specified amount of times, sleeping for the provided duration between each
+// try. The delivered message is acknowledged and returned. Other messages pulled in the meantime are not
+// acknowledged, so they are redelivered.
+func PublishAndAssertDeliveryE(t testing.TestingT, projectID string, topicName string, subscriptionName string, data []byte, attributes map[string]string, retries int, sleepBetweenRetries time.Duration) (*PubSubMessage, error) {
+	messageID, err := PublishMessageE(t, projectID, topicName, data, attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	var delivered *PubSubMessage
+	description := fmt.Sprintf("Waiting for message %s to be delivered to Pub/Sub subscription %s", messageID, subscriptionName)
+	_, err = retry.DoWithRetryE(t, description, retries, sleepBetweenRetries, func() (string, error) {
+		received, err := pullMessagesE(t, projectID, subscriptionName, 100)
+		if err != nil {
+			return "", err
+		}
+
+		ackIDs, otherAckIDs := splitAckIDsByMessageID(received, messageID)
+		if len(otherAckIDs) > 0 {
+			// Make the other messages available for redelivery right away
+			if err := nackMessagesE(t, projectID, subscriptionName, otherAckIDs); err != nil {
+				return "", err
+			}
+		}
+		if len(ackIDs) == 0 {
+			return "", fmt.Errorf("message %s not delivered yet", messageID)
+		}
+
+		messages, err := decodePubSubMessages(received)
+		if err != nil {
+			return "", err
+		}
+		for _, message := range messages {
+			if message.ID == messageID {
+				delivered = message
+			}
+		}
+		if err := acknowledgeMessagesE(t, projectID, subscriptionName, ackIDs); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Message %s delivered", messageID), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return delivered, nil
+}
+
+// GetPubSubSubscription gets the configuration of the given Pub/Sub subscription.
+func GetPubSubSubscription(t testing.TestingT, projectID string, subscriptionName string) *pubsub.Subscription {
+	subscription, err := GetPubSubSubscriptionE(t, projectID, subscriptionName)
+	require.NoError(t, err)
+	return subscription
+}
+
+// GetPubSubSubscriptionE gets the configuration of the given Pub/Sub subscription.
+func GetPubSubSubscriptionE(t testing.TestingT, projectID string, subscriptionName string) (*pubsub.Subscription, error) {
+	logger.Default.Logf(t, "Getting Pub/Sub subscription %s", subscriptionName)
+
+	service, err := NewPubSubServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription, err := service.Projects.Subscriptions.Get(pubSubSubscriptionResourceName(projectID, subscriptionName)).Context(context.Background()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("Subscriptions.Get(%s) got error: %v", subscriptionName, err)
+	}
+
+	return subscription, nil
+}
+
+// AssertSubscriptionDeadLetterPolicy checks that the given Pub/Sub subscription forwards undeliverable messages to
+// the expected dead letter topic after the expected number of delivery attempts.
+func AssertSubscriptionDeadLetterPolicy(t testing.TestingT, projectID string, subscriptionName string, expectedTopicName string, expectedMaxDeliveryAttempts int64) {
+	require.NoError(t, AssertSubscriptionDeadLetterPolicyE(t, projectID, subscriptionName, expectedTopicName, expectedMaxDeliveryAttempts))
+}
+
+// AssertSubscriptionDeadLetterPolicyE checks that the given Pub/Sub subscription forwards undeliverable messages to
+// the expected dead letter topic after the expected number of delivery attempts.
+func AssertSubscriptionDeadLetterPolicyE(t testing.TestingT, projectID string, subscriptionName string, expectedTopicName string, expectedMaxDeliveryAttempts int64) error {
+	subscription, err := GetPubSubSubscriptionE(t, projectID, subscriptionName)
+	if err != nil {
+		return err
+	}
+	return checkDeadLetterPolicy(subscription, pubSubTopicResourceName(projectID, expectedTopicName), expectedMaxDeliveryAttempts)
+}
+
+// AssertSubscriptionRetryPolicy checks that the given Pub/Sub subscription retries deliveries with the expected
+// minimum and maximum exponential backoff.
+func AssertSubscriptionRetryPolicy(t testing.TestingT, projectID string, subscriptionName string, expectedMinimumBackoff time.Duration, expectedMaximumBackoff time.Duration) {
+	require.NoError(t, AssertSubscriptionRetryPolicyE(t, projectID, subscriptionName, expectedMinimumBackoff, expectedMaximumBackoff))
+}
+
+// AssertSubscriptionRetryPolicyE checks that the given Pub/Sub subscription retries deliveries with the expected
+// minimum and maximum exponential backoff.
+func AssertSubscriptionRetryPolicyE(t testing.TestingT, projectID string, subscriptionName string, expectedMinimumBackoff time.Duration, expectedMaximumBackoff time.Duration) error {
+	subscription, err := GetPubSubSubscriptionE(t, projectID, subscriptionName)
+	if err != nil {
+		return err
+	}
+	return checkRetryPolicy(subscription, expectedMinimumBackoff, expectedMaximumBackoff)
+}
+
+// AssertSubscriptionFilter checks that the given Pub/Sub subscription only receives the messages matching the
+// expected filter.
+func AssertSubscriptionFilter(t testing.TestingT, projectID string, subscriptionName string, expectedFilter string) {
+	require.NoError(t, AssertSubscriptionFilterE(t, projectID, subscriptionName, expectedFilter))
+}
+
+// AssertSubscriptionFilterE checks that the given Pub/Sub subscription only receives the messages matching the
+// expected filter.
+func AssertSubscriptionFilterE(t testing.TestingT, projectID string, subscriptionName string, expectedFilter string) error {
+	subscription, err := GetPubSubSubscriptionE(t, projectID, subscriptionName)
+	if err != nil {
+		return err
+	}
+	if subscription.Filter != expectedFilter {
+		return fmt.Errorf("subscription %s has filter %q, expected %q", subscriptionName, subscription.Filter, expectedFilter)
+	}
+	return nil
+}
+
+// NewPubSubService creates a new Pub/Sub service, which is used to make Pub/Sub API calls.
+func NewPubSubService(t testing.TestingT) *pubsub.Service {
+	service, err := NewPubSubServiceE(t)
+	require.NoError(t, err)
+	return service
+}
+
+// NewPubSubServiceE creates a new Pub/Sub service, which is used to make Pub/Sub API calls.
+func NewPubSubServiceE(t testing.TestingT) (*pubsub.Service, error) {
+	return pubsub.NewService(context.Background(), withOptions(t)...)
+}
+
+func pullMessagesE(t testing.TestingT, projectID string, subscriptionName string, maxMessages int64) ([]*pubsub.ReceivedMessage, error) {
+	service, err := NewPubSubServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &pubsub.PullRequest{MaxMessages: maxMessages}
+	resp, err := service.Projects.Subscriptions.Pull(pubSubSubscriptionResourceName(projectID, subscriptionName), req).Context(context.Background()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("Subscriptions.Pull(%s) got error: %v", subscriptionName, err)
+	}
+
+	return resp.ReceivedMessages, nil
+}
+
+func acknowledgeMessagesE(t testing.TestingT, projectID string, subscriptionName string, ackIDs []string) error {
+	if len(ackIDs) == 0 {
+		return nil
+	}
+
+	service, err := NewPubSubServiceE(t)
+	if err != nil {
+		return err
+	}
+
+	req := &pubsub.AcknowledgeRequest{AckIds: ackIDs}
+	if _, err := service.Projects.Subscriptions.Acknowledge(pubSubSubscriptionResourceName(projectID, subscriptionName), req).Context(context.Background()).Do(); err != nil {
+		return fmt.Errorf("Subscriptions.Acknowledge(%s) got error: %v", subscriptionName, err)
+	}
+	return nil
+}
+
+// nackMessagesE sets the ack deadline of the given messages to zero, so they are redelivered right away.
+func nackMessagesE(t testing.TestingT, projectID string, subscriptionName string, ackIDs []string) error {
+	service, err := NewPubSubServiceE(t)
+	if err != nil {
+		return err
+	}
+
+	req := &pubsub.ModifyAckDeadlineRequest{
+		AckIds:             ackIDs,
+		AckDeadlineSeconds: 0,
+		// A zero deadline would otherwise be omitted from the request
+		ForceSendFields: []string{"AckDeadlineSeconds"},
+	}
+	if _, err := service.Projects.Subscriptions.ModifyAckDeadline(pubSubSubscriptionResourceName(projectID, subscriptionName), req).Context(context.Background()).Do(); err != nil {
+		return fmt.Errorf("Subscriptions.ModifyAckDeadline(%s) got error: %v", subscriptionName, err)
+	}
+	return nil
+}
+
+func decodePubSubMessages(received []*pubsub.ReceivedMessage) ([]*PubSubMessage, error) {
+	messages := []*PubSubMessage{}
+	for _, receivedMessage := range received {
+		if receivedMessage.Message == nil {
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(receivedMessage.Message.Data)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, &PubSubMessage{
+			ID:              receivedMessage.Message.MessageId,
+			Data:            data,
+			Attributes:      receivedMessage.Message.Attributes,
+			DeliveryAttempt: receivedMessage.DeliveryAttempt,
+		})
+	}
+	return messages, nil
+}
+
+func getAckIDs(received []*pubsub.ReceivedMessage) []string {
+	ackIDs := []string{}
+	for _, receivedMessage := range received {
+		ackIDs = append(ackIDs, receivedMessage.AckId)
+	}
+	return ackIDs
+}
+
+// splitAckIDsByMessageID returns the ack IDs of the received messages with the given message ID, and the ack IDs of
+// all the other messages.
+func splitAckIDsByMessageID(received []*pubsub.ReceivedMessage, messageID string) ([]string, []string) {
+	matching := []string{}
+	others := []string{}
+	for _, receivedMessage := range received {
+		if receivedMessage.Message != nil && receivedMessage.Message.MessageId == messageID {
+			matching = append(matching, receivedMessage.AckId)
+		} else {
+			others = append(others, receivedMessage.AckId)
+		}
+	}
+	return matching, others
+}
+
+func checkDeadLetterPolicy(subscription *pubsub.Subscription, expectedTopic string, expectedMaxDeliveryAttempts int64) error {
+	policy := subscription.DeadLetterPolicy
+	if policy == nil {
+		return fmt.Errorf("subscription %s has no dead letter policy", subscription.Name)
+	}
+	if policy.DeadLetterTopic != expectedTopic {
+		return fmt.Errorf("subscription %s forwards dead letters to %s, expected %s", subscription.Name, policy.DeadLetterTopic, expectedTopic)
+	}
+	// The API reports 0 when the default of 5 attempts is used
+	maxDeliveryAttempts := policy.MaxDeliveryAttempts
+	if maxDeliveryAttempts == 0 {
+		maxDeliveryAttempts = 5
+	}
+	if maxDeliveryAttempts != expectedMaxDeliveryAttempts {
+		return fmt.Errorf("subscription %s allows %d delivery attempts, expected %d", subscription.Name, maxDeliveryAttempts, expectedMaxDeliveryAttempts)
+	}
+	return nil
+}
+
+func checkRetryPolicy(subscription *pubsub.Subscription, expectedMinimumBackoff time.Duration, expectedMaximumBackoff time.Duration) error {
+	policy := subscription.RetryPolicy
+	if policy == nil {
+		return fmt.Errorf("subscription %s has no retry policy", subscription.Name)
+	}
+
+	// Durations are returned in seconds with up to nine fractional digits, e.g. "10s" or "0.500s"
+	minimumBackoff, err := time.ParseDuration(policy.MinimumBackoff)
+	if err != nil {
+		return err
+	}
+	maximumBackoff, err := time.ParseDuration(policy.MaximumBackoff)
+	if err != nil {
+		return err
+	}
+
+	if minimumBackoff != expectedMinimumBackoff || maximumBackoff != expectedMaximumBackoff {
+		return fmt.Errorf("subscription %s backs off between %s and %s, expected %s and %s", subscription.Name, minimumBackoff, maximumBackoff, expectedMinimumBackoff, expectedMaximumBackoff)
+	}
+	return nil
+}
+
+func pubSubTopicResourceName(projectID string, topicName string) string {
+	return fmt.Sprintf("projects/%s/topics/%s", projectID, topicName)
+}
+
+func pubSubSubscriptionResourceName(projectID string, subscriptionName string) string {
+	return fmt.Sprintf("projects/%s/subscriptions/%s", projectID, subscriptionName)
+}
@@ -0,0 +1,52 @@
+//go:build gcp
+// +build gcp
+
+// NOTE: We use build tags to differentiate GCP testing for better isolation and parallelism when executing our tests.
+
+package gcp
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pubsub "google.golang.org/api/pubsub/v1"
+)
+
+func TestDecodePubSubMessages(t *testing.T) {
+	t.Parallel()
+
+	received := []*pubsub.ReceivedMessage{
+		{AckId: "ack-1", DeliveryAttempt: 2, Message: &pubsub.PubsubMessage{MessageId: "1", Data: base64.StdEncoding.EncodeToString([]byte("hello")), Attributes: map[string]string{"k": "v"}}},
+		{AckId: "ack-2", Message: &pubsub.PubsubMessage{MessageId: "2"}},
+	}
+
+	messages, err := decodePubSubMessages(received)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, &PubSubMessage{ID: "1", Data: []byte("hello"), Attributes: map[string]string{"k": "v"}, DeliveryAttempt: 2}, messages[0])
+
+	matching, others := splitAckIDsByMessageID(received, "2")
+	assert.Equal(t, []string{"ack-2"}, matching)
+	assert.Equal(t, []string{"ack-1"}, others)
+}
+
+func TestCheckSubscriptionPolicies(t *testing.T) {
+	t.Parallel()
+
+	subscription := &pubsub.Subscription{
+		Name:             "projects/my-project/subscriptions/orders",
+		DeadLetterPolicy: &pubsub.DeadLetterPolicy{DeadLetterTopic: "projects/my-project/topics/orders-dlq"},
+		RetryPolicy:      &pubsub.RetryPolicy{MinimumBackoff: "10s", MaximumBackoff: "600s"},
+	}
+
+	require.NoError(t, checkDeadLetterPolicy(subscription, "projects/my-project/topics/orders-dlq", 5))
+	require.Error(t, checkDeadLetterPolicy(subscription, "projects/my-project/topics/orders-dlq", 10))
+	require.Error(t, checkDeadLetterPolicy(subscription, "projects/my-project/topics/other", 5))
+
+	require.NoError(t, checkRetryPolicy(subscription, 10*time.Second, 10*time.Minute))
+	require.Error(t, checkRetryPolicy(subscription, time.Second, 10*time.Minute))
+	require.Error(t, checkRetryPolicy(&pubsub.Subscription{}, 10*time.Second, 10*time.Minute))
+}
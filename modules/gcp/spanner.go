@@ -0,0 +1,537 @@
+package gcp
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/spanner/v1"
+)
+
+// SpannerRow is a row of a Cloud Spanner table or query result, by column name. Values are typed after the column
+// type: INT64 as int64, FLOAT64 as float64, BOOL as bool, BYTES as []byte, TIMESTAMP as time.Time, ARRAY as
+// []interface{}, STRUCT as SpannerRow, and other types (STRING, DATE, NUMERIC, JSON) as string. NULL values are nil.
+type SpannerRow map[string]interface{}
+
+// GetSpannerInstance gets the given Cloud Spanner instance.
+func GetSpannerInstance(t testing.TestingT, projectID string, instanceID string) *spanner.Instance {
+	instance, err := GetSpannerInstanceE(t, projectID, instanceID)
+	require.NoError(t, err)
+	return instance
+}
+
+// GetSpannerInstanceE gets the given Cloud Spanner instance.
+func GetSpannerInstanceE(t testing.TestingT, projectID string, instanceID string) (*spanner.Instance, error) {
+	logger.Default.Logf(t, "Getting Cloud Spanner instance %s", instanceID)
+
+	service, err := NewSpannerServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	name := spannerInstanceResourceName(projectID, instanceID)
+	instance, err := service.Projects.Instances.Get(name).Context(context.Background()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("Instances.Get(%s) got error: %w", name, err)
+	}
+
+	return instance, nil
+}
+
+// SpannerInstanceExists checks if the given Cloud Spanner instance exists.
+func SpannerInstanceExists(t testing.TestingT, projectID string, instanceID string) bool {
+	exists, err := SpannerInstanceExistsE(t, projectID, instanceID)
+	require.NoError(t, err)
+	return exists
+}
+
+// SpannerInstanceExistsE checks if the given Cloud Spanner instance exists.
+func SpannerInstanceExistsE(t testing.TestingT, projectID string, instanceID string) (bool, error) {
+	_, err := GetSpannerInstanceE(t, projectID, instanceID)
+	if err == nil {
+		return true, nil
+	}
+	if isNotFoundError(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// AssertSpannerInstanceConfig checks that the given Cloud Spanner instance uses the given instance configuration (e.g.
+// regional-us-central1 or nam3) and compute capacity, in processing units (1000 per node).
+func AssertSpannerInstanceConfig(t testing.TestingT, projectID string, instanceID string, instanceConfig string, processingUnits int64) {
+	require.NoError(t, AssertSpannerInstanceConfigE(t, projectID, instanceID, instanceConfig, processingUnits))
+}
+
+// AssertSpannerInstanceConfigE checks that the given Cloud Spanner instance uses the given instance configuration (e.g.
+// regional-us-central1 or nam3) and compute capacity, in processing units (1000 per node).
+func AssertSpannerInstanceConfigE(t testing.TestingT, projectID string, instanceID string, instanceConfig string, processingUnits int64) error {
+	instance, err := GetSpannerInstanceE(t, projectID, instanceID)
+	if err != nil {
+		return err
+	}
+	return checkSpannerInstanceConfig(instance, instanceConfig, processingUnits)
+}
+
+// GetSpannerDatabase gets the given Cloud Spanner database.
+func GetSpannerDatabase(t testing.TestingT, projectID string, instanceID string, databaseID string) *spanner.Database {
+	database, err := GetSpannerDatabaseE(t, projectID, instanceID, databaseID)
+	require.NoError(t, err)
+	return database
+}
+
+// GetSpannerDatabaseE gets the given Cloud Spanner database.
+func GetSpannerDatabaseE(t testing.TestingT, projectID string, instanceID string, databaseID string) (*spanner.Database, error) {
+	logger.Default.Logf(t, "Getting Cloud Spanner database %s of instance %s", databaseID, instanceID)
+
+	service, err := NewSpannerServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	name := spannerDatabaseResourceName(projectID, instanceID, databaseID)
+	database, err := service.Projects.Instances.Databases.Get(name).Context(context.Background()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("Databases.Get(%s) got error: %w", name, err)
+	}
+
+	return database, nil
+}
+
+// SpannerDatabaseExists checks if the given Cloud Spanner database exists.
+func SpannerDatabaseExists(t testing.TestingT, projectID string, instanceID string, databaseID string) bool {
+	exists, err := SpannerDatabaseExistsE(t, projectID, instanceID, databaseID)
+	require.NoError(t, err)
+	return exists
+}
+
+// SpannerDatabaseExistsE checks if the given Cloud Spanner database exists.
+func SpannerDatabaseExistsE(t testing.TestingT, projectID string, instanceID string, databaseID string) (bool, error) {
+	_, err := GetSpannerDatabaseE(t, projectID, instanceID, databaseID)
+	if err == nil {
+		return true, nil
+	}
+	if isNotFoundError(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// AssertSpannerDatabaseConfig checks that the given Cloud Spanner database is ready, uses the given dialect
+// (GOOGLE_STANDARD_SQL or POSTGRESQL) and has drop protection enabled or not.
+func AssertSpannerDatabaseConfig(t testing.TestingT, projectID string, instanceID string, databaseID string, dialect string, dropProtection bool) {
+	require.NoError(t, AssertSpannerDatabaseConfigE(t, projectID, instanceID, databaseID, dialect, dropProtection))
+}
+
+// AssertSpannerDatabaseConfigE checks that the given Cloud Spanner database is ready, uses the given dialect
+// (GOOGLE_STANDARD_SQL or POSTGRESQL) and has drop protection enabled or not.
+func AssertSpannerDatabaseConfigE(t testing.TestingT, projectID string, instanceID string, databaseID string, dialect string, dropProtection bool) error {
+	database, err := GetSpannerDatabaseE(t, projectID, instanceID, databaseID)
+	if err != nil {
+		return err
+	}
+	if database.State != "READY" {
+		return fmt.Errorf("Cloud Spanner database %s is %s, expected READY", databaseID, database.State)
+	}
+	if !strings.EqualFold(database.DatabaseDialect, dialect) {
+		return fmt.Errorf("Cloud Spanner database %s uses dialect %s, expected %s", databaseID, database.DatabaseDialect, dialect)
+	}
+	if database.EnableDropProtection != dropProtection {
+		return fmt.Errorf("Cloud Spanner database %s has drop protection %t, expected %t", databaseID, database.EnableDropProtection, dropProtection)
+	}
+	return nil
+}
+
+// GetSpannerDatabaseDdl gets the DDL statements of the schema of the given Cloud Spanner database.
+func GetSpannerDatabaseDdl(t testing.TestingT, projectID string, instanceID string, databaseID string) []string {
+	statements, err := GetSpannerDatabaseDdlE(t, projectID, instanceID, databaseID)
+	require.NoError(t, err)
+	return statements
+}
+
+// GetSpannerDatabaseDdlE gets the DDL statements of the schema of the given Cloud Spanner database.
+func GetSpannerDatabaseDdlE(t testing.TestingT, projectID string, instanceID string, databaseID string) ([]string, error) {
+	logger.Default.Logf(t, "Getting DDL of Cloud Spanner database %s of instance %s", databaseID, instanceID)
+
+	service, err := NewSpannerServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	name := spannerDatabaseResourceName(projectID, instanceID, databaseID)
+	ddl, err := service.Projects.Instances.Databases.GetDdl(name).Context(context.Background()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("Databases.GetDdl(%s) got error: %v", name, err)
+	}
+
+	return ddl.Statements, nil
+}
+
+// ExecuteSpannerQuery runs the given SQL query on the Cloud Spanner database, in a read-only transaction, and returns
+// the typed rows of the result.
+func ExecuteSpannerQuery(t testing.TestingT, projectID string, instanceID string, databaseID string, query string) []SpannerRow {
+	rows, err := ExecuteSpannerQueryE(t, projectID, instanceID, databaseID, query)
+	require.NoError(t, err)
+	return rows
+}
+
+// ExecuteSpannerQueryE runs the given SQL query on the Cloud Spanner database, in a read-only transaction, and returns
+// the typed rows of the result.
+func ExecuteSpannerQueryE(t testing.TestingT, projectID string, instanceID string, databaseID string, query string) ([]SpannerRow, error) {
+	logger.Default.Logf(t, "Running query on Cloud Spanner database %s: %s", databaseID, query)
+
+	var rows []SpannerRow
+	err := withSpannerSessionE(t, projectID, instanceID, databaseID, func(service *spanner.Service, session string) error {
+		resultSet, err := service.Projects.Instances.Databases.Sessions.ExecuteSql(session, &spanner.ExecuteSqlRequest{Sql: query}).Context(context.Background()).Do()
+		if err != nil {
+			return fmt.Errorf("Sessions.ExecuteSql(%s) got error: %v", databaseID, err)
+		}
+		rows, err = convertSpannerResultSet(resultSet)
+		return err
+	})
+	return rows, err
+}
+
+// WriteSpannerRow inserts the given row in the table of the Cloud Spanner database, or updates it if a row with the
+// same key already exists.
+func WriteSpannerRow(t testing.TestingT, projectID string, instanceID string, databaseID string, table string, row SpannerRow) {
+	require.NoError(t, WriteSpannerRowE(t, projectID, instanceID, databaseID, table, row))
+}
+
+// WriteSpannerRowE inserts the given row in the table of the Cloud Spanner database, or updates it if a row with the
+// same key already exists.
+func WriteSpannerRowE(t testing.TestingT, projectID string, instanceID string, databaseID string, table string, row SpannerRow) error {
+	logger.Default.Logf(t, "Writing row to table %s of Cloud Spanner database %s", table, databaseID)
+
+	columns, values := encodeSpannerRow(row)
+	mutation := &spanner.Mutation{InsertOrUpdate: &spanner.Write{Table: table, Columns: columns, Values: [][]interface{}{values}}}
+	return commitSpannerMutationE(t, projectID, instanceID, databaseID, mutation)
+}
+
+// ReadSpannerRow reads the given columns of the row with the given key (the values of the primary key columns, in
+// order) from the table of the Cloud Spanner database.
+func ReadSpannerRow(t testing.TestingT, projectID string, instanceID string, databaseID string, table string, key []interface{}, columns []string) SpannerRow {
+	row, err := ReadSpannerRowE(t, projectID, instanceID, databaseID, table, key, columns)
+	require.NoError(t, err)
+	return row
+}
+
+// ReadSpannerRowE reads the given columns of the row with the given key (the values of the primary key columns, in
+// order) from the table of the Cloud Spanner database.
+func ReadSpannerRowE(t testing.TestingT, projectID string, instanceID string, databaseID string, table string, key []interface{}, columns []string) (SpannerRow, error) {
+	logger.Default.Logf(t, "Reading row %v from table %s of Cloud Spanner database %s", key, table, databaseID)
+
+	var row SpannerRow
+	err := withSpannerSessionE(t, projectID, instanceID, databaseID, func(service *spanner.Service, session string) error {
+		request := &spanner.ReadRequest{
+			Table:   table,
+			Columns: columns,
+			KeySet:  &spanner.KeySet{Keys: [][]interface{}{encodeSpannerKey(key)}},
+		}
+		resultSet, err := service.Projects.Instances.Databases.Sessions.Read(session, request).Context(context.Background()).Do()
+		if err != nil {
+			return fmt.Errorf("Sessions.Read(%s) got error: %v", table, err)
+		}
+		rows, err := convertSpannerResultSet(resultSet)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return fmt.Errorf("row %v not found in table %s", key, table)
+		}
+		row = rows[0]
+		return nil
+	})
+	return row, err
+}
+
+// DeleteSpannerRow deletes the row with the given key (the values of the primary key columns, in order) from the table
+// of the Cloud Spanner database.
+func DeleteSpannerRow(t testing.TestingT, projectID string, instanceID string, databaseID string, table string, key []interface{}) {
+	require.NoError(t, DeleteSpannerRowE(t, projectID, instanceID, databaseID, table, key))
+}
+
+// DeleteSpannerRowE deletes the row with the given key (the values of the primary key columns, in order) from the table
+// of the Cloud Spanner database.
+func DeleteSpannerRowE(t testing.TestingT, projectID string, instanceID string, databaseID string, table string, key []interface{}) error {
+	logger.Default.Logf(t, "Deleting row %v from table %s of Cloud Spanner database %s", key, table, databaseID)
+
+	mutation := &spanner.Mutation{Delete: &spanner.Delete{Table: table, KeySet: &spanner.KeySet{Keys: [][]interface{}{encodeSpannerKey(key)}}}}
+	return commitSpannerMutationE(t, projectID, instanceID, databaseID, mutation)
+}
+
+// AssertSpannerReadWrite is a smoke test of the Cloud Spanner database: it writes the given row to the table, reads it
+// back by its primary key columns and checks its values, then deletes it.
+func AssertSpannerReadWrite(t testing.TestingT, projectID string, instanceID string, databaseID string, table string, keyColumns []string, row SpannerRow) {
+	require.NoError(t, AssertSpannerReadWriteE(t, projectID, instanceID, databaseID, table, keyColumns, row))
+}
+
+// AssertSpannerReadWriteE is a smoke test of the Cloud Spanner database: it writes the given row to the table, reads it
+// back by its primary key columns and checks its values, then deletes it, even if reading or checking it fails.
+func AssertSpannerReadWriteE(t testing.TestingT, projectID string, instanceID string, databaseID string, table string, keyColumns []string, row SpannerRow) (err error) {
+	key := []interface{}{}
+	for _, column := range keyColumns {
+		value, ok := row[column]
+		if !ok {
+			return fmt.Errorf("row has no value for key column %s", column)
+		}
+		key = append(key, value)
+	}
+
+	if err := WriteSpannerRowE(t, projectID, instanceID, databaseID, table, row); err != nil {
+		return err
+	}
+	defer func() {
+		if deleteErr := DeleteSpannerRowE(t, projectID, instanceID, databaseID, table, key); deleteErr != nil && err == nil {
+			err = deleteErr
+		}
+	}()
+
+	columns, _ := encodeSpannerRow(row)
+	actual, err := ReadSpannerRowE(t, projectID, instanceID, databaseID, table, key, columns)
+	if err != nil {
+		return err
+	}
+	return checkSpannerRow(row, actual)
+}
+
+// NewSpannerService creates a new Cloud Spanner service, which is used to make Cloud Spanner API calls.
+func NewSpannerService(t testing.TestingT) *spanner.Service {
+	service, err := NewSpannerServiceE(t)
+	require.NoError(t, err)
+	return service
+}
+
+// NewSpannerServiceE creates a new Cloud Spanner service, which is used to make Cloud Spanner API calls.
+func NewSpannerServiceE(t testing.TestingT) (*spanner.Service, error) {
+	return spanner.NewService(context.Background(), withOptions(t)...)
+}
+
+// withSpannerSessionE runs the given function with a new session on the database, deleting the session afterwards.
+func withSpannerSessionE(t testing.TestingT, projectID string, instanceID string, databaseID string, f func(service *spanner.Service, session string) error) error {
+	service, err := NewSpannerServiceE(t)
+	if err != nil {
+		return err
+	}
+
+	database := spannerDatabaseResourceName(projectID, instanceID, databaseID)
+	session, err := service.Projects.Instances.Databases.Sessions.Create(database, &spanner.CreateSessionRequest{}).Context(context.Background()).Do()
+	if err != nil {
+		return fmt.Errorf("Sessions.Create(%s) got error: %v", database, err)
+	}
+	defer func() {
+		if _, err := service.Projects.Instances.Databases.Sessions.Delete(session.Name).Context(context.Background()).Do(); err != nil {
+			logger.Default.Logf(t, "Failed to delete Cloud Spanner session %s: %v", session.Name, err)
+		}
+	}()
+
+	return f(service, session.Name)
+}
+
+func commitSpannerMutationE(t testing.TestingT, projectID string, instanceID string, databaseID string, mutation *spanner.Mutation) error {
+	return withSpannerSessionE(t, projectID, instanceID, databaseID, func(service *spanner.Service, session string) error {
+		request := &spanner.CommitRequest{
+			SingleUseTransaction: &spanner.TransactionOptions{ReadWrite: &spanner.ReadWrite{}},
+			Mutations:            []*spanner.Mutation{mutation},
+		}
+		if _, err := service.Projects.Instances.Databases.Sessions.Commit(session, request).Context(context.Background()).Do(); err != nil {
+			return fmt.Errorf("Sessions.Commit(%s) got error: %v", databaseID, err)
+		}
+		return nil
+	})
+}
+
+func checkSpannerInstanceConfig(instance *spanner.Instance, instanceConfig string, processingUnits int64) error {
+	if instance.Config != instanceConfig && !strings.HasSuffix(instance.Config, "/instanceConfigs/"+instanceConfig) {
+		return fmt.Errorf("Cloud Spanner instance %s uses configuration %s, expected %s", instance.Name, instance.Config, instanceConfig)
+	}
+
+	actualProcessingUnits := instance.ProcessingUnits
+	if actualProcessingUnits == 0 {
+		actualProcessingUnits = instance.NodeCount * 1000
+	}
+	if actualProcessingUnits != processingUnits {
+		return fmt.Errorf("Cloud Spanner instance %s has %d processing units, expected %d", instance.Name, actualProcessingUnits, processingUnits)
+	}
+	return nil
+}
+
+// checkSpannerRow checks the columns of the expected row against the row read back, comparing their wire encodings so
+// that e.g. an int written to an INT64 column matches the int64 read back.
+func checkSpannerRow(expected SpannerRow, actual SpannerRow) error {
+	for column, value := range expected {
+		if !reflect.DeepEqual(encodeSpannerValue(value), encodeSpannerValue(actual[column])) {
+			return fmt.Errorf("column %s holds %v, expected %v", column, actual[column], value)
+		}
+	}
+	return nil
+}
+
+func convertSpannerResultSet(resultSet *spanner.ResultSet) ([]SpannerRow, error) {
+	if resultSet.Metadata == nil || resultSet.Metadata.RowType == nil {
+		return nil, nil
+	}
+
+	rows := []SpannerRow{}
+	for _, values := range resultSet.Rows {
+		row, err := decodeSpannerStruct(resultSet.Metadata.RowType.Fields, values)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func decodeSpannerStruct(fields []*spanner.Field, values []interface{}) (SpannerRow, error) {
+	if len(fields) != len(values) {
+		return nil, fmt.Errorf("got %d values for %d fields", len(values), len(fields))
+	}
+
+	row := SpannerRow{}
+	for i, field := range fields {
+		value, err := decodeSpannerValue(field.Type, values[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode field %s: %v", field.Name, err)
+		}
+		row[field.Name] = value
+	}
+	return row, nil
+}
+
+// decodeSpannerValue converts a value of the JSON encoding of the Spanner API to the Go type of SpannerRow.
+func decodeSpannerValue(valueType *spanner.Type, value interface{}) (interface{}, error) {
+	if value == nil || valueType == nil {
+		return value, nil
+	}
+
+	switch valueType.Code {
+	case "INT64":
+		return strconv.ParseInt(fmt.Sprint(value), 10, 64)
+	case "FLOAT64", "FLOAT32":
+		if number, ok := value.(float64); ok {
+			return number, nil
+		}
+		// NaN and infinities are encoded as strings
+		switch value {
+		case "NaN":
+			return math.NaN(), nil
+		case "Infinity":
+			return math.Inf(1), nil
+		case "-Infinity":
+			return math.Inf(-1), nil
+		}
+		return nil, fmt.Errorf("invalid float %v", value)
+	case "BOOL":
+		return value, nil
+	case "BYTES":
+		return base64.StdEncoding.DecodeString(fmt.Sprint(value))
+	case "TIMESTAMP":
+		return time.Parse(time.RFC3339Nano, fmt.Sprint(value))
+	case "ARRAY":
+		elements, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid array %v", value)
+		}
+		result := []interface{}{}
+		for _, element := range elements {
+			decoded, err := decodeSpannerValue(valueType.ArrayElementType, element)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, decoded)
+		}
+		return result, nil
+	case "STRUCT":
+		values, ok := value.([]interface{})
+		if !ok || valueType.StructType == nil {
+			return nil, fmt.Errorf("invalid struct %v", value)
+		}
+		return decodeSpannerStruct(valueType.StructType.Fields, values)
+	default:
+		return value, nil
+	}
+}
+
+// encodeSpannerRow returns the columns of the row, sorted, and their encoded values.
+func encodeSpannerRow(row SpannerRow) ([]string, []interface{}) {
+	columns := []string{}
+	for column := range row {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	values := []interface{}{}
+	for _, column := range columns {
+		values = append(values, encodeSpannerValue(row[column]))
+	}
+	return columns, values
+}
+
+func encodeSpannerKey(key []interface{}) []interface{} {
+	encoded := []interface{}{}
+	for _, value := range key {
+		encoded = append(encoded, encodeSpannerValue(value))
+	}
+	return encoded
+}
+
+// encodeSpannerValue converts a Go value to the JSON encoding of the Spanner API: INT64 values are strings, BYTES are
+// base64 strings and TIMESTAMP values are RFC 3339 strings in UTC.
+func encodeSpannerValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case nil:
+		return nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(typed)
+	case float32:
+		return encodeSpannerValue(float64(typed))
+	case float64:
+		switch {
+		case math.IsNaN(typed):
+			return "NaN"
+		case math.IsInf(typed, 1):
+			return "Infinity"
+		case math.IsInf(typed, -1):
+			return "-Infinity"
+		}
+		return typed
+	case []byte:
+		return base64.StdEncoding.EncodeToString(typed)
+	case time.Time:
+		return typed.UTC().Format(time.RFC3339Nano)
+	case SpannerRow:
+		columns, values := encodeSpannerRow(typed)
+		encoded := map[string]interface{}{}
+		for i, column := range columns {
+			encoded[column] = values[i]
+		}
+		return encoded
+	}
+
+	if reflected := reflect.ValueOf(value); reflected.Kind() == reflect.Slice {
+		encoded := []interface{}{}
+		for i := 0; i < reflected.Len(); i++ {
+			encoded = append(encoded, encodeSpannerValue(reflected.Index(i).Interface()))
+		}
+		return encoded
+	}
+	return value
+}
+
+func spannerInstanceResourceName(projectID string, instanceID string) string {
+	return fmt.Sprintf("projects/%s/instances/%s", projectID, instanceID)
+}
+
+func spannerDatabaseResourceName(projectID string, instanceID string, databaseID string) string {
+	return fmt.Sprintf("%s/databases/%s", spannerInstanceResourceName(projectID, instanceID), databaseID)
+}
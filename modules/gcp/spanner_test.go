@@ -0,0 +1,74 @@
+//go:build gcp
+// +build gcp
+
+// NOTE: We use build tags to differentiate GCP testing for better isolation and parallelism when executing our tests.
+
+package gcp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/spanner/v1"
+)
+
+func TestConvertSpannerResultSet(t *testing.T) {
+	t.Parallel()
+
+	resultSet := &spanner.ResultSet{
+		Metadata: &spanner.ResultSetMetadata{RowType: &spanner.StructType{Fields: []*spanner.Field{
+			{Name: "id", Type: &spanner.Type{Code: "INT64"}},
+			{Name: "name", Type: &spanner.Type{Code: "STRING"}},
+			{Name: "score", Type: &spanner.Type{Code: "FLOAT64"}},
+			{Name: "active", Type: &spanner.Type{Code: "BOOL"}},
+			{Name: "payload", Type: &spanner.Type{Code: "BYTES"}},
+			{Name: "created_at", Type: &spanner.Type{Code: "TIMESTAMP"}},
+			{Name: "tags", Type: &spanner.Type{Code: "ARRAY", ArrayElementType: &spanner.Type{Code: "INT64"}}},
+			{Name: "deleted_at", Type: &spanner.Type{Code: "TIMESTAMP"}},
+		}}},
+		Rows: [][]interface{}{
+			{"42", "alice", 9.5, true, "aGVsbG8=", "2024-01-02T03:04:05.5Z", []interface{}{"1", "2"}, nil},
+		},
+	}
+
+	rows, err := convertSpannerResultSet(resultSet)
+	require.NoError(t, err)
+	assert.Equal(t, []SpannerRow{{
+		"id":         int64(42),
+		"name":       "alice",
+		"score":      9.5,
+		"active":     true,
+		"payload":    []byte("hello"),
+		"created_at": time.Date(2024, 1, 2, 3, 4, 5, 500000000, time.UTC),
+		"tags":       []interface{}{int64(1), int64(2)},
+		"deleted_at": nil,
+	}}, rows)
+}
+
+func TestEncodeSpannerRow(t *testing.T) {
+	t.Parallel()
+
+	columns, values := encodeSpannerRow(SpannerRow{
+		"id":      42,
+		"payload": []byte("hello"),
+		"tags":    []string{"a", "b"},
+	})
+	assert.Equal(t, []string{"id", "payload", "tags"}, columns)
+	assert.Equal(t, []interface{}{"42", "aGVsbG8=", []interface{}{"a", "b"}}, values)
+
+	expected := SpannerRow{"id": 42, "created_at": time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600))}
+	require.NoError(t, checkSpannerRow(expected, SpannerRow{"id": int64(42), "created_at": time.Date(2024, 1, 2, 2, 4, 5, 0, time.UTC)}))
+	require.Error(t, checkSpannerRow(expected, SpannerRow{"id": int64(43), "created_at": time.Date(2024, 1, 2, 2, 4, 5, 0, time.UTC)}))
+}
+
+func TestCheckSpannerInstanceConfig(t *testing.T) {
+	t.Parallel()
+
+	instance := &spanner.Instance{Name: "projects/my-project/instances/main", Config: "projects/my-project/instanceConfigs/regional-us-central1", NodeCount: 2}
+
+	require.NoError(t, checkSpannerInstanceConfig(instance, "regional-us-central1", 2000))
+	require.Error(t, checkSpannerInstanceConfig(instance, "nam3", 2000))
+	require.Error(t, checkSpannerInstanceConfig(instance, "regional-us-central1", 100))
+}
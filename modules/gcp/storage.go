@@ -1,10 +1,15 @@
 package gcp
 
 import (
+	"bytes"
 	"context"
+	"crypto/md5"
 	"fmt"
+	"hash/crc32"
 	"io"
+	"time"
 
+	"cloud.google.com/go/iam"
 	"cloud.google.com/go/storage"
 	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/testing"
@@ -26,7 +31,7 @@ func CreateStorageBucketE(t testing.TestingT, projectID string, name string, att
 	ctx := context.Background()
 
 	// Creates a client.
-	client, err := newStorageClient()
+	client, err := newStorageClient(t)
 	if err != nil {
 		return err
 	}
@@ -52,7 +57,7 @@ func DeleteStorageBucketE(t testing.TestingT, name string) error {
 
 	ctx := context.Background()
 
-	client, err := newStorageClient()
+	client, err := newStorageClient(t)
 	if err != nil {
 		return err
 	}
@@ -75,7 +80,7 @@ func ReadBucketObjectE(t testing.TestingT, bucketName string, filePath string) (
 
 	ctx := context.Background()
 
-	client, err := newStorageClient()
+	client, err := newStorageClient(t)
 	if err != nil {
 		return nil, err
 	}
@@ -109,7 +114,7 @@ func WriteBucketObjectE(t testing.TestingT, bucketName string, filePath string,
 
 	ctx := context.Background()
 
-	client, err := newStorageClient()
+	client, err := newStorageClient(t)
 	if err != nil {
 		return "", err
 	}
@@ -147,7 +152,7 @@ func EmptyStorageBucketE(t testing.TestingT, name string) error {
 
 	ctx := context.Background()
 
-	client, err := newStorageClient()
+	client, err := newStorageClient(t)
 	if err != nil {
 		return err
 	}
@@ -192,7 +197,7 @@ func AssertStorageBucketExistsE(t testing.TestingT, name string) error {
 	ctx := context.Background()
 
 	// Creates a client.
-	client, err := newStorageClient()
+	client, err := newStorageClient(t)
 	if err != nil {
 		return err
 	}
@@ -218,9 +223,278 @@ func AssertStorageBucketExistsE(t testing.TestingT, name string) error {
 	return nil
 }
 
-func newStorageClient() (*storage.Client, error) {
+// GetStorageBucketAttrs gets the attributes (lifecycle, retention, access configuration, etc) of the given storage
+// bucket.
+func GetStorageBucketAttrs(t testing.TestingT, name string) *storage.BucketAttrs {
+	attrs, err := GetStorageBucketAttrsE(t, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return attrs
+}
+
+// GetStorageBucketAttrsE gets the attributes (lifecycle, retention, access configuration, etc) of the given storage
+// bucket.
+func GetStorageBucketAttrsE(t testing.TestingT, name string) (*storage.BucketAttrs, error) {
+	logger.Default.Logf(t, "Getting attributes of bucket %s", name)
+
+	ctx := context.Background()
+
+	client, err := newStorageClient(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Bucket(name).Attrs(ctx)
+}
+
+// AssertStorageBucketUniformAccessEnabled checks if uniform bucket-level access is enabled on the given storage
+// bucket and fails the test if it is not.
+func AssertStorageBucketUniformAccessEnabled(t testing.TestingT, name string) {
+	err := AssertStorageBucketUniformAccessEnabledE(t, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// AssertStorageBucketUniformAccessEnabledE checks if uniform bucket-level access is enabled on the given storage
+// bucket and returns an error if it is not.
+func AssertStorageBucketUniformAccessEnabledE(t testing.TestingT, name string) error {
+	attrs, err := GetStorageBucketAttrsE(t, name)
+	if err != nil {
+		return err
+	}
+
+	if !attrs.UniformBucketLevelAccess.Enabled {
+		return fmt.Errorf("uniform bucket-level access is not enabled on bucket %s", name)
+	}
+	return nil
+}
+
+// AssertStorageBucketRetentionPolicy checks if the given storage bucket retains objects for the expected period, and
+// if the retention policy is locked as expected, and fails the test if it does not.
+func AssertStorageBucketRetentionPolicy(t testing.TestingT, name string, expectedPeriod time.Duration, expectedLocked bool) {
+	err := AssertStorageBucketRetentionPolicyE(t, name, expectedPeriod, expectedLocked)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// AssertStorageBucketRetentionPolicyE checks if the given storage bucket retains objects for the expected period, and
+// if the retention policy is locked as expected, and returns an error if it does not.
+func AssertStorageBucketRetentionPolicyE(t testing.TestingT, name string, expectedPeriod time.Duration, expectedLocked bool) error {
+	attrs, err := GetStorageBucketAttrsE(t, name)
+	if err != nil {
+		return err
+	}
+	return checkRetentionPolicy(name, attrs.RetentionPolicy, expectedPeriod, expectedLocked)
+}
+
+// AssertStorageBucketLifecycleRule checks if the given storage bucket has a lifecycle rule running the given action
+// (e.g. Delete or SetStorageClass) on objects older than the given number of days, and fails the test if it does not.
+func AssertStorageBucketLifecycleRule(t testing.TestingT, name string, actionType string, ageInDays int64) {
+	err := AssertStorageBucketLifecycleRuleE(t, name, actionType, ageInDays)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// AssertStorageBucketLifecycleRuleE checks if the given storage bucket has a lifecycle rule running the given action
+// (e.g. Delete or SetStorageClass) on objects older than the given number of days, and returns an error if it does
+// not.
+func AssertStorageBucketLifecycleRuleE(t testing.TestingT, name string, actionType string, ageInDays int64) error {
+	attrs, err := GetStorageBucketAttrsE(t, name)
+	if err != nil {
+		return err
+	}
+
+	if !hasLifecycleRule(attrs.Lifecycle, actionType, ageInDays) {
+		return fmt.Errorf("bucket %s has no lifecycle rule running %s on objects older than %d days", name, actionType, ageInDays)
+	}
+	return nil
+}
+
+// GetStorageBucketIamPolicy gets the IAM policy of the given storage bucket.
+func GetStorageBucketIamPolicy(t testing.TestingT, name string) *iam.Policy {
+	policy, err := GetStorageBucketIamPolicyE(t, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return policy
+}
+
+// GetStorageBucketIamPolicyE gets the IAM policy of the given storage bucket.
+func GetStorageBucketIamPolicyE(t testing.TestingT, name string) (*iam.Policy, error) {
+	logger.Default.Logf(t, "Getting IAM policy of bucket %s", name)
+
+	ctx := context.Background()
+
+	client, err := newStorageClient(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Bucket(name).IAM().Policy(ctx)
+}
+
+// AssertStorageBucketMemberHasRole checks if the given member (e.g. user:jane@example.com or
+// serviceAccount:sa@project.iam.gserviceaccount.com) has the given role on the storage bucket and fails the test if it
+// does not.
+func AssertStorageBucketMemberHasRole(t testing.TestingT, name string, member string, role string) {
+	err := AssertStorageBucketMemberHasRoleE(t, name, member, role)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// AssertStorageBucketMemberHasRoleE checks if the given member (e.g. user:jane@example.com or
+// serviceAccount:sa@project.iam.gserviceaccount.com) has the given role on the storage bucket and returns an error if
+// it does not.
+func AssertStorageBucketMemberHasRoleE(t testing.TestingT, name string, member string, role string) error {
+	policy, err := GetStorageBucketIamPolicyE(t, name)
+	if err != nil {
+		return err
+	}
+
+	if !policy.HasRole(member, iam.RoleName(role)) {
+		return fmt.Errorf("member %s does not have role %s on bucket %s", member, role, name)
+	}
+	return nil
+}
+
+// GenerateSignedURL generates a V4 signed URL granting access to the given object with the given HTTP method (e.g.
+// GET or PUT) until it expires.
+func GenerateSignedURL(t testing.TestingT, bucketName string, filePath string, method string, expiresIn time.Duration) string {
+	url, err := GenerateSignedURLE(t, bucketName, filePath, method, expiresIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return url
+}
+
+// GenerateSignedURLE generates a V4 signed URL granting access to the given object with the given HTTP method (e.g.
+// GET or PUT) until it expires. The URL is signed with the service account key of the credentials if there is one,
+// or else with the IAM signBlob API, which requires the iam.serviceAccountTokenCreator role.
+func GenerateSignedURLE(t testing.TestingT, bucketName string, filePath string, method string, expiresIn time.Duration) (string, error) {
+	logger.Default.Logf(t, "Generating signed URL for %s on object %s of bucket %s", method, filePath, bucketName)
+
+	client, err := newStorageClient(t)
+	if err != nil {
+		return "", err
+	}
+
+	return client.Bucket(bucketName).SignedURL(filePath, &storage.SignedURLOptions{
+		Method:  method,
+		Expires: time.Now().Add(expiresIn),
+		Scheme:  storage.SigningSchemeV4,
+	})
+}
+
+// GetBucketObjectAttrs gets the attributes (size, checksums, metadata, etc) of the given object.
+func GetBucketObjectAttrs(t testing.TestingT, bucketName string, filePath string) *storage.ObjectAttrs {
+	attrs, err := GetBucketObjectAttrsE(t, bucketName, filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return attrs
+}
+
+// GetBucketObjectAttrsE gets the attributes (size, checksums, metadata, etc) of the given object.
+func GetBucketObjectAttrsE(t testing.TestingT, bucketName string, filePath string) (*storage.ObjectAttrs, error) {
+	logger.Default.Logf(t, "Getting attributes of object %s in bucket %s", filePath, bucketName)
+
+	ctx := context.Background()
+
+	client, err := newStorageClient(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Bucket(bucketName).Object(filePath).Attrs(ctx)
+}
+
+// DeleteBucketObject deletes the given object from the Storage Bucket.
+func DeleteBucketObject(t testing.TestingT, bucketName string, filePath string) {
+	err := DeleteBucketObjectE(t, bucketName, filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// DeleteBucketObjectE deletes the given object from the Storage Bucket.
+func DeleteBucketObjectE(t testing.TestingT, bucketName string, filePath string) error {
+	logger.Default.Logf(t, "Deleting object %s from bucket %s", filePath, bucketName)
+
+	ctx := context.Background()
+
+	client, err := newStorageClient(t)
+	if err != nil {
+		return err
+	}
+
+	return client.Bucket(bucketName).Object(filePath).Delete(ctx)
+}
+
+// AssertBucketObjectChecksum checks if the checksums stored by Cloud Storage for the given object match the expected
+// content, and fails the test if they do not.
+func AssertBucketObjectChecksum(t testing.TestingT, bucketName string, filePath string, expectedContent []byte) {
+	err := AssertBucketObjectChecksumE(t, bucketName, filePath, expectedContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// AssertBucketObjectChecksumE checks if the checksums stored by Cloud Storage for the given object match the expected
+// content, and returns an error if they do not. The CRC32C checksum is always verified, and the MD5 hash as well for
+// objects that have one (composite objects don't).
+func AssertBucketObjectChecksumE(t testing.TestingT, bucketName string, filePath string, expectedContent []byte) error {
+	attrs, err := GetBucketObjectAttrsE(t, bucketName, filePath)
+	if err != nil {
+		return err
+	}
+	return checkObjectChecksums(attrs, expectedContent)
+}
+
+func checkRetentionPolicy(name string, policy *storage.RetentionPolicy, expectedPeriod time.Duration, expectedLocked bool) error {
+	if policy == nil {
+		return fmt.Errorf("bucket %s has no retention policy", name)
+	}
+	if policy.RetentionPeriod != expectedPeriod {
+		return fmt.Errorf("bucket %s retains objects for %s, expected %s", name, policy.RetentionPeriod, expectedPeriod)
+	}
+	if policy.IsLocked != expectedLocked {
+		return fmt.Errorf("retention policy of bucket %s is locked: %t, expected %t", name, policy.IsLocked, expectedLocked)
+	}
+	return nil
+}
+
+func hasLifecycleRule(lifecycle storage.Lifecycle, actionType string, ageInDays int64) bool {
+	for _, rule := range lifecycle.Rules {
+		if rule.Action.Type == actionType && rule.Condition.AgeInDays == ageInDays {
+			return true
+		}
+	}
+	return false
+}
+
+func checkObjectChecksums(attrs *storage.ObjectAttrs, expectedContent []byte) error {
+	expectedCRC32C := crc32.Checksum(expectedContent, crc32.MakeTable(crc32.Castagnoli))
+	if attrs.CRC32C != expectedCRC32C {
+		return fmt.Errorf("object %s has CRC32C checksum %d, expected %d", attrs.Name, attrs.CRC32C, expectedCRC32C)
+	}
+
+	if len(attrs.MD5) > 0 {
+		expectedMD5 := md5.Sum(expectedContent)
+		if !bytes.Equal(attrs.MD5, expectedMD5[:]) {
+			return fmt.Errorf("object %s has MD5 hash %x, expected %x", attrs.Name, attrs.MD5, expectedMD5)
+		}
+	}
+	return nil
+}
+
+func newStorageClient(t testing.TestingT) (*storage.Client, error) {
 	ctx := context.Background()
-	client, err := storage.NewClient(ctx, withOptions()...)
+	client, err := storage.NewClient(ctx, withOptions(t)...)
 	if err != nil {
 		return nil, err
 	}
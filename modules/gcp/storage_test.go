@@ -7,9 +7,14 @@ package gcp
 
 import (
 	"bytes"
+	"crypto/md5"
 	"fmt"
+	"hash/crc32"
 	"strings"
 	"testing"
+	"time"
+
+	"cloud.google.com/go/storage"
 
 	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/random"
@@ -75,3 +80,39 @@ func TestAssertStorageBucketExistsNoFalsePositive(t *testing.T) {
 		t.Fatalf("Function claimed that the Storage Bucket '%s' exists, but in fact it does not.", gsBucketName)
 	}
 }
+
+func TestCheckObjectChecksums(t *testing.T) {
+	t.Parallel()
+
+	content := []byte("test file text")
+	md5Sum := md5.Sum(content)
+	attrs := &storage.ObjectAttrs{
+		Name:   "test-file.txt",
+		CRC32C: crc32.Checksum(content, crc32.MakeTable(crc32.Castagnoli)),
+		MD5:    md5Sum[:],
+	}
+
+	require.NoError(t, checkObjectChecksums(attrs, content))
+	require.Error(t, checkObjectChecksums(attrs, []byte("other text")))
+
+	// Composite objects have no MD5 hash, so only the CRC32C checksum is verified
+	attrs.MD5 = nil
+	require.NoError(t, checkObjectChecksums(attrs, content))
+}
+
+func TestCheckBucketPolicies(t *testing.T) {
+	t.Parallel()
+
+	lifecycle := storage.Lifecycle{
+		Rules: []storage.LifecycleRule{
+			{Action: storage.LifecycleAction{Type: storage.DeleteAction}, Condition: storage.LifecycleCondition{AgeInDays: 30}},
+		},
+	}
+	require.True(t, hasLifecycleRule(lifecycle, storage.DeleteAction, 30))
+	require.False(t, hasLifecycleRule(lifecycle, storage.SetStorageClassAction, 30))
+
+	policy := &storage.RetentionPolicy{RetentionPeriod: 24 * time.Hour}
+	require.NoError(t, checkRetentionPolicy("bucket", policy, 24*time.Hour, false))
+	require.Error(t, checkRetentionPolicy("bucket", policy, 24*time.Hour, true))
+	require.Error(t, checkRetentionPolicy("bucket", nil, 24*time.Hour, false))
+}
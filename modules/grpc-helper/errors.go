@@ -0,0 +1,39 @@
+package grpc_helper
+
+import (
+	"fmt"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// NotServingError is an error that occurs if a gRPC service is not healthy
+type NotServingError struct {
+	Address string
+	Service string
+	Status  healthpb.HealthCheckResponse_ServingStatus
+}
+
+func (err NotServingError) Error() string {
+	return fmt.Sprintf("gRPC service %q at %s is %s, not SERVING", err.Service, err.Address, err.Status)
+}
+
+// ServiceNotFoundError is an error that occurs if a gRPC server does not describe a service through reflection
+type ServiceNotFoundError struct {
+	Address string
+	Service string
+}
+
+func (err ServiceNotFoundError) Error() string {
+	return fmt.Sprintf("gRPC service %s not found at %s", err.Service, err.Address)
+}
+
+// ReflectionError is an error that occurs if the reflection service of a gRPC server responds with an error
+type ReflectionError struct {
+	Address string
+	Code    int32
+	Message string
+}
+
+func (err ReflectionError) Error() string {
+	return fmt.Sprintf("gRPC reflection at %s failed with code %d: %s", err.Address, err.Code, err.Message)
+}
@@ -0,0 +1,208 @@
+// Package grpc_helper contains helpers to interact with deployed resources through gRPC.
+package grpc_helper
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	http_helper "github.com/gruntwork-io/terratest/modules/http-helper"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// defaultCallTimeout is the timeout of each gRPC call, which includes connecting to the server.
+const defaultCallTimeout = 10 * time.Second
+
+// GetGrpcHealthStatus returns the serving status of the given service on the gRPC server at the given address
+// (host:port), using the standard gRPC health checking protocol. An empty service checks the server as a whole. If
+// tlsOptions is nil, the connection is not encrypted. If there's any error, fail the test.
+func GetGrpcHealthStatus(t testing.TestingT, address string, service string, tlsOptions *http_helper.TlsOptions) healthpb.HealthCheckResponse_ServingStatus {
+	status, err := GetGrpcHealthStatusE(t, address, service, tlsOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return status
+}
+
+// GetGrpcHealthStatusE returns the serving status of the given service on the gRPC server at the given address
+// (host:port), using the standard gRPC health checking protocol. An empty service checks the server as a whole. If
+// tlsOptions is nil, the connection is not encrypted.
+func GetGrpcHealthStatusE(t testing.TestingT, address string, service string, tlsOptions *http_helper.TlsOptions) (healthpb.HealthCheckResponse_ServingStatus, error) {
+	logger.Default.Logf(t, "Checking the health of gRPC service %q at %s", service, address)
+
+	conn, err := newGrpcClientConnE(address, tlsOptions)
+	if err != nil {
+		return healthpb.HealthCheckResponse_UNKNOWN, err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultCallTimeout)
+	defer cancel()
+
+	response, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		return healthpb.HealthCheckResponse_UNKNOWN, err
+	}
+	return response.GetStatus(), nil
+}
+
+// WaitForGrpcHealthy repeatedly checks the health of the given service on the gRPC server at the given address until
+// it is SERVING or until max retries has been exceeded. If the service never becomes healthy, fail the test.
+func WaitForGrpcHealthy(t testing.TestingT, address string, service string, tlsOptions *http_helper.TlsOptions, retries int, sleepBetweenRetries time.Duration) {
+	if err := WaitForGrpcHealthyE(t, address, service, tlsOptions, retries, sleepBetweenRetries); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// WaitForGrpcHealthyE repeatedly checks the health of the given service on the gRPC server at the given address until
+// it is SERVING or until max retries has been exceeded. An empty service checks the server as a whole. If tlsOptions
+// is nil, the connection is not encrypted.
+func WaitForGrpcHealthyE(t testing.TestingT, address string, service string, tlsOptions *http_helper.TlsOptions, retries int, sleepBetweenRetries time.Duration) error {
+	_, err := retry.DoWithRetryE(t, fmt.Sprintf("Waiting for gRPC service %q at %s to be healthy", service, address), retries, sleepBetweenRetries, func() (string, error) {
+		status, err := GetGrpcHealthStatusE(t, address, service, tlsOptions)
+		if err != nil {
+			return "", err
+		}
+		if status != healthpb.HealthCheckResponse_SERVING {
+			return "", NotServingError{Address: address, Service: service, Status: status}
+		}
+		return status.String(), nil
+	})
+	return err
+}
+
+// ListGrpcServices returns the fully qualified names of the services of the gRPC server at the given address, sorted,
+// using server reflection. If there's any error, fail the test.
+func ListGrpcServices(t testing.TestingT, address string, tlsOptions *http_helper.TlsOptions) []string {
+	services, err := ListGrpcServicesE(t, address, tlsOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return services
+}
+
+// ListGrpcServicesE returns the fully qualified names (e.g. grpc.health.v1.Health) of the services of the gRPC server
+// at the given address, sorted, using server reflection. The server must register the v1 reflection service. If
+// tlsOptions is nil, the connection is not encrypted.
+func ListGrpcServicesE(t testing.TestingT, address string, tlsOptions *http_helper.TlsOptions) ([]string, error) {
+	logger.Default.Logf(t, "Listing the services of the gRPC server at %s", address)
+
+	response, err := callGrpcReflectionE(address, tlsOptions, &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	services := []string{}
+	for _, service := range response.GetListServicesResponse().GetService() {
+		services = append(services, service.GetName())
+	}
+	sort.Strings(services)
+	return services, nil
+}
+
+// ListGrpcMethods returns the names of the methods of the given service, by fully qualified name, of the gRPC server at
+// the given address, using server reflection. If there's any error, fail the test.
+func ListGrpcMethods(t testing.TestingT, address string, service string, tlsOptions *http_helper.TlsOptions) []string {
+	methods, err := ListGrpcMethodsE(t, address, service, tlsOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return methods
+}
+
+// ListGrpcMethodsE returns the names (e.g. Check) of the methods of the given service, by fully qualified name (e.g.
+// grpc.health.v1.Health), of the gRPC server at the given address, in the order they are declared, using server
+// reflection. The server must register the v1 reflection service. If tlsOptions is nil, the connection is not
+// encrypted.
+func ListGrpcMethodsE(t testing.TestingT, address string, service string, tlsOptions *http_helper.TlsOptions) ([]string, error) {
+	logger.Default.Logf(t, "Listing the methods of gRPC service %s at %s", service, address)
+
+	response, err := callGrpcReflectionE(address, tlsOptions, &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: service},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, encodedFile := range response.GetFileDescriptorResponse().GetFileDescriptorProto() {
+		file := &descriptorpb.FileDescriptorProto{}
+		if err := proto.Unmarshal(encodedFile, file); err != nil {
+			return nil, err
+		}
+
+		for _, fileService := range file.GetService() {
+			name := fileService.GetName()
+			if file.GetPackage() != "" {
+				name = file.GetPackage() + "." + name
+			}
+			if name != service {
+				continue
+			}
+
+			methods := []string{}
+			for _, method := range fileService.GetMethod() {
+				methods = append(methods, method.GetName())
+			}
+			return methods, nil
+		}
+	}
+
+	return nil, ServiceNotFoundError{Address: address, Service: service}
+}
+
+// callGrpcReflectionE sends the given request to the reflection service of the gRPC server at the given address and
+// returns its response, or the error it responds with.
+func callGrpcReflectionE(address string, tlsOptions *http_helper.TlsOptions, request *reflectionpb.ServerReflectionRequest) (*reflectionpb.ServerReflectionResponse, error) {
+	conn, err := newGrpcClientConnE(address, tlsOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultCallTimeout)
+	defer cancel()
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Send(request); err != nil {
+		return nil, err
+	}
+	response, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	stream.CloseSend()
+
+	if errorResponse := response.GetErrorResponse(); errorResponse != nil {
+		return nil, ReflectionError{Address: address, Code: errorResponse.GetErrorCode(), Message: errorResponse.GetErrorMessage()}
+	}
+	return response, nil
+}
+
+// newGrpcClientConnE returns a client connection to the gRPC server at the given address, with TLS if tlsOptions is
+// set. The connection is only established on the first call.
+func newGrpcClientConnE(address string, tlsOptions *http_helper.TlsOptions) (*grpc.ClientConn, error) {
+	transportCredentials := insecure.NewCredentials()
+	if tlsOptions != nil {
+		tlsConfig, err := http_helper.NewTlsConfigE(*tlsOptions)
+		if err != nil {
+			return nil, err
+		}
+		transportCredentials = credentials.NewTLS(tlsConfig)
+	}
+	return grpc.NewClient(address, grpc.WithTransportCredentials(transportCredentials))
+}
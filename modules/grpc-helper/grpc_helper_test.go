@@ -0,0 +1,88 @@
+package grpc_helper
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// startTestGrpcServer starts a gRPC server with the health and reflection services on a random local port, and returns
+// its address and health server.
+func startTestGrpcServer(t *testing.T) (string, *health.Server) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	return listener.Addr().String(), healthServer
+}
+
+func TestWaitForGrpcHealthy(t *testing.T) {
+	t.Parallel()
+	address, healthServer := startTestGrpcServer(t)
+	healthServer.SetServingStatus("test.Service", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		healthServer.SetServingStatus("test.Service", healthpb.HealthCheckResponse_SERVING)
+	}()
+
+	WaitForGrpcHealthy(t, address, "test.Service", nil, 10, 50*time.Millisecond)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, GetGrpcHealthStatus(t, address, "", nil))
+}
+
+func TestWaitForGrpcHealthyENotServing(t *testing.T) {
+	t.Parallel()
+	address, healthServer := startTestGrpcServer(t)
+	healthServer.SetServingStatus("test.Service", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	err := WaitForGrpcHealthyE(t, address, "test.Service", nil, 2, 10*time.Millisecond)
+	assert.Error(t, err)
+}
+
+func TestGetGrpcHealthStatusEUnknownService(t *testing.T) {
+	t.Parallel()
+	address, _ := startTestGrpcServer(t)
+
+	_, err := GetGrpcHealthStatusE(t, address, "unknown.Service", nil)
+	assert.Error(t, err)
+}
+
+func TestListGrpcServices(t *testing.T) {
+	t.Parallel()
+	address, _ := startTestGrpcServer(t)
+
+	services := ListGrpcServices(t, address, nil)
+	assert.Contains(t, services, "grpc.health.v1.Health")
+	assert.Contains(t, services, "grpc.reflection.v1.ServerReflection")
+}
+
+func TestListGrpcMethods(t *testing.T) {
+	t.Parallel()
+	address, _ := startTestGrpcServer(t)
+
+	methods := ListGrpcMethods(t, address, "grpc.health.v1.Health", nil)
+	assert.Contains(t, methods, "Check")
+	assert.Contains(t, methods, "Watch")
+}
+
+func TestListGrpcMethodsEUnknownService(t *testing.T) {
+	t.Parallel()
+	address, _ := startTestGrpcServer(t)
+
+	_, err := ListGrpcMethodsE(t, address, "unknown.Service", nil)
+	assert.Error(t, err)
+}
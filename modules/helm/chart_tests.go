@@ -0,0 +1,113 @@
+package helm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// ChartTestResult is the result of a test hook of a chart, as run by `helm test`.
+type ChartTestResult struct {
+	Name        string // The name of the test hook, which is the name of its pod.
+	Phase       string // The phase of the last run of the hook: Succeeded, Failed or Running.
+	StartedAt   string // When the last run started, as printed by helm.
+	CompletedAt string // When the last run completed, as printed by helm.
+	Logs        string // The logs of the pod of the hook.
+}
+
+// Passed returns true if the last run of the test hook succeeded.
+func (result ChartTestResult) Passed() bool {
+	return result.Phase == "Succeeded"
+}
+
+// RunChartTests runs `helm test` on the release, whose test hooks pods log as they run, and returns the results of the
+// test hooks. This will fail the test if there is an error, or if any of the test hooks fails.
+func RunChartTests(t testing.TestingT, options *Options, releaseName string) []ChartTestResult {
+	results, err := RunChartTestsE(t, options, releaseName)
+	require.NoError(t, err)
+	return results
+}
+
+// RunChartTestsE runs `helm test` on the release, whose test hooks pods log as they run, and returns the results of
+// the test hooks, with the logs of their pods. If any of the test hooks fails, the results are returned along with the
+// error, so the failed hooks and their logs can be reported.
+func RunChartTestsE(t testing.TestingT, options *Options, releaseName string) ([]ChartTestResult, error) {
+	args := []string{}
+	if options.ExtraArgs != nil {
+		if testArgs, ok := options.ExtraArgs["test"]; ok {
+			args = append(args, testArgs...)
+		}
+	}
+	args = append(args, releaseName, "--logs")
+	stdout, stderr, err := RunHelmCommandAndGetStdOutErrE(t, options, "test", args...)
+	results := parseChartTestResults(stdout)
+	if err != nil {
+		return results, fmt.Errorf("helm test of release %s failed: %s: %w", releaseName, strings.TrimSpace(stderr), err)
+	}
+
+	for _, result := range results {
+		if !result.Passed() {
+			return results, fmt.Errorf("test %s of release %s is %s", result.Name, releaseName, result.Phase)
+		}
+	}
+	return results, nil
+}
+
+// parseChartTestResults parses the test suites and the pod logs printed by `helm test --logs`, formatted as:
+//
+// TEST SUITE:     web-test-connection
+// Last Started:   Mon Mar  4 10:00:00 2024
+// Last Completed: Mon Mar  4 10:00:05 2024
+// Phase:          Succeeded
+// ...
+// POD LOGS: web-test-connection
+// <logs>
+func parseChartTestResults(output string) []ChartTestResult {
+	results := []ChartTestResult{}
+	indexes := map[string]int{}
+	logs := map[string][]string{}
+	podLogs := ""
+	for _, line := range strings.Split(output, "\n") {
+		if name, found := strings.CutPrefix(line, "POD LOGS: "); found {
+			podLogs = strings.TrimSpace(name)
+			continue
+		}
+		if podLogs != "" {
+			logs[podLogs] = append(logs[podLogs], line)
+			continue
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if key == "TEST SUITE" {
+			if value != "None" {
+				indexes[value] = len(results)
+				results = append(results, ChartTestResult{Name: value})
+			}
+			continue
+		}
+		if len(results) == 0 {
+			continue
+		}
+		switch key {
+		case "Last Started":
+			results[len(results)-1].StartedAt = value
+		case "Last Completed":
+			results[len(results)-1].CompletedAt = value
+		case "Phase":
+			results[len(results)-1].Phase = value
+		}
+	}
+
+	for name, lines := range logs {
+		if index, ok := indexes[name]; ok {
+			results[index].Logs = strings.TrimSpace(strings.Join(lines, "\n"))
+		}
+	}
+	return results
+}
@@ -0,0 +1,56 @@
+package helm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseChartTestResults(t *testing.T) {
+	t.Parallel()
+
+	output := `NAME: web
+LAST DEPLOYED: Mon Mar  4 09:59:00 2024
+NAMESPACE: default
+STATUS: deployed
+REVISION: 1
+TEST SUITE:     web-test-connection
+Last Started:   Mon Mar  4 10:00:00 2024
+Last Completed: Mon Mar  4 10:00:05 2024
+Phase:          Succeeded
+TEST SUITE:     web-test-api
+Last Started:   Mon Mar  4 10:00:05 2024
+Last Completed: Mon Mar  4 10:00:09 2024
+Phase:          Failed
+NOTES:
+Visit http://web.example.com
+
+POD LOGS: web-test-connection
+Connecting to web:80 (10.96.0.12:80)
+saving to 'index.html'
+
+POD LOGS: web-test-api
+GET /api/health: 503 Service Unavailable
+`
+	results := parseChartTestResults(output)
+	assert.Equal(t, []ChartTestResult{
+		{
+			Name:        "web-test-connection",
+			Phase:       "Succeeded",
+			StartedAt:   "Mon Mar  4 10:00:00 2024",
+			CompletedAt: "Mon Mar  4 10:00:05 2024",
+			Logs:        "Connecting to web:80 (10.96.0.12:80)\nsaving to 'index.html'",
+		},
+		{
+			Name:        "web-test-api",
+			Phase:       "Failed",
+			StartedAt:   "Mon Mar  4 10:00:05 2024",
+			CompletedAt: "Mon Mar  4 10:00:09 2024",
+			Logs:        "GET /api/health: 503 Service Unavailable",
+		},
+	}, results)
+	assert.True(t, results[0].Passed())
+	assert.False(t, results[1].Passed())
+
+	assert.Empty(t, parseChartTestResults("NAME: web\nTEST SUITE: None\n"))
+}
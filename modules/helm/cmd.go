@@ -33,24 +33,32 @@ func getNamespaceArgs(options *Options) []string {
 	return []string{}
 }
 
-// getValuesArgsE computes the args to pass in for setting values
-func getValuesArgsE(t testing.TestingT, options *Options, args ...string) ([]string, error) {
+// getValuesArgsE computes the args to pass in for setting values. The returned function removes the temporary files of
+// the args, and should be called once the helm command ran.
+func getValuesArgsE(t testing.TestingT, options *Options, args ...string) ([]string, func(), error) {
 	args = append(args, formatSetValuesAsArgs(options.SetValues, "--set")...)
 	args = append(args, formatSetValuesAsArgs(options.SetStrValues, "--set-string")...)
 	args = append(args, formatSetValuesAsArgs(options.SetJsonValues, "--set-json")...)
 
 	valuesFilesArgs, err := formatValuesFilesAsArgsE(t, options.ValuesFiles)
 	if err != nil {
-		return args, errors.WithStackTrace(err)
+		return args, nil, errors.WithStackTrace(err)
 	}
 	args = append(args, valuesFilesArgs...)
 
+	valuesArgs, cleanup, err := formatValuesAsArgsE(options.Values)
+	if err != nil {
+		return args, nil, errors.WithStackTrace(err)
+	}
+	args = append(args, valuesArgs...)
+
 	setFilesArgs, err := formatSetFilesAsArgsE(t, options.SetFiles)
 	if err != nil {
-		return args, errors.WithStackTrace(err)
+		cleanup()
+		return args, nil, errors.WithStackTrace(err)
 	}
 	args = append(args, setFilesArgs...)
-	return args, nil
+	return args, cleanup, nil
 }
 
 // RunHelmCommandAndGetOutputE runs helm with the given arguments and options and returns combined, interleaved stdout/stderr.
@@ -0,0 +1,261 @@
+package helm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/require"
+	goyaml "gopkg.in/yaml.v3"
+
+	"github.com/gruntwork-io/terratest/modules/files"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// DependencyOptions represents the options of helm to fetch the subchart dependencies of a chart.
+type DependencyOptions struct {
+	Repositories []DependencyRepository // Chart repositories the dependencies are fetched from, along with their credentials.
+	SkipRefresh  bool                   // If true, don't refresh the local cache of the repository indexes.
+}
+
+// DependencyRepository represents a chart repository the dependencies of a chart are fetched from. The credentials are
+// passed to helm through a temporary repositories config file, so they don't show up in the logs of the commands.
+type DependencyRepository struct {
+	Name     string
+	URL      string
+	Username string
+	Password string
+}
+
+// ChartDependency represents a dependency of a chart, as declared in the Chart.yaml file or locked in the Chart.lock
+// file.
+type ChartDependency struct {
+	Name       string `yaml:"name"`
+	Version    string `yaml:"version"`
+	Repository string `yaml:"repository"`
+}
+
+// DependencyBuild fetches the dependencies of the chart at the given path into its charts directory, at the versions
+// locked in its Chart.lock file, and verifies they are all there. This will fail the test if there is an error.
+func DependencyBuild(t testing.TestingT, options *Options, dependencyOptions *DependencyOptions, chartDir string) {
+	require.NoError(t, DependencyBuildE(t, options, dependencyOptions, chartDir))
+}
+
+// DependencyBuildE fetches the dependencies of the chart at the given path into its charts directory, at the versions
+// locked in its Chart.lock file, and verifies they are all there. This returns an error if the chart has dependencies
+// but no Chart.lock file, or if the lock file doesn't match the dependencies declared in Chart.yaml.
+func DependencyBuildE(t testing.TestingT, options *Options, dependencyOptions *DependencyOptions, chartDir string) error {
+	if err := VerifyDependencyLockE(chartDir); err != nil {
+		return err
+	}
+	if err := runDependencyCommandE(t, options, dependencyOptions, "build", "dependencyBuild", chartDir); err != nil {
+		return err
+	}
+	return verifyDependencyArchivesE(chartDir)
+}
+
+// DependencyUpdate resolves the dependencies of the chart at the given path to their latest matching versions, updates
+// its Chart.lock file and fetches them into its charts directory. This will fail the test if there is an error.
+func DependencyUpdate(t testing.TestingT, options *Options, dependencyOptions *DependencyOptions, chartDir string) {
+	require.NoError(t, DependencyUpdateE(t, options, dependencyOptions, chartDir))
+}
+
+// DependencyUpdateE resolves the dependencies of the chart at the given path to their latest matching versions, updates
+// its Chart.lock file and fetches them into its charts directory. The updated lock file is verified against the
+// dependencies declared in Chart.yaml and the fetched archives.
+func DependencyUpdateE(t testing.TestingT, options *Options, dependencyOptions *DependencyOptions, chartDir string) error {
+	if err := runDependencyCommandE(t, options, dependencyOptions, "update", "dependencyUpdate", chartDir); err != nil {
+		return err
+	}
+	if err := VerifyDependencyLockE(chartDir); err != nil {
+		return err
+	}
+	return verifyDependencyArchivesE(chartDir)
+}
+
+// VerifyDependencyLock verifies that every dependency declared in the Chart.yaml file of the chart at the given path is
+// locked in its Chart.lock file, from the same repository. This will fail the test if there is an error.
+func VerifyDependencyLock(t testing.TestingT, chartDir string) {
+	require.NoError(t, VerifyDependencyLockE(chartDir))
+}
+
+// VerifyDependencyLockE verifies that every dependency declared in the Chart.yaml file of the chart at the given path is
+// locked in its Chart.lock file, from the same repository.
+func VerifyDependencyLockE(chartDir string) error {
+	declared, err := readChartDependenciesE(filepath.Join(chartDir, "Chart.yaml"))
+	if err != nil {
+		return err
+	}
+	if len(declared) == 0 {
+		return nil
+	}
+
+	lockPath := filepath.Join(chartDir, "Chart.lock")
+	if !files.FileExists(lockPath) {
+		return errors.WithStackTrace(fmt.Errorf("chart %s has %d dependencies but no Chart.lock file", chartDir, len(declared)))
+	}
+	locked, err := readChartDependenciesE(lockPath)
+	if err != nil {
+		return err
+	}
+	return checkDependencyLock(declared, locked)
+}
+
+// runDependencyCommandE runs the given helm dependency subcommand on the chart, with the repositories of the options
+// added to a temporary copy of the helm repositories config. The extra args of the options under the given key are
+// appended.
+func runDependencyCommandE(t testing.TestingT, options *Options, dependencyOptions *DependencyOptions, subcommand string, extraArgsKey string, chartDir string) error {
+	args := []string{subcommand, chartDir}
+	if dependencyOptions != nil && dependencyOptions.SkipRefresh {
+		args = append(args, "--skip-refresh")
+	}
+	if options.ExtraArgs != nil {
+		if dependencyArgs, ok := options.ExtraArgs[extraArgsKey]; ok {
+			args = append(args, dependencyArgs...)
+		}
+	}
+
+	if dependencyOptions != nil && len(dependencyOptions.Repositories) > 0 {
+		// Start from the repositories config helm would use, so the repositories already configured stay available
+		existingConfig, err := RunHelmCommandAndGetStdOutE(t, options, "env", "HELM_REPOSITORY_CONFIG")
+		if err != nil {
+			return err
+		}
+		repositoryConfig, err := writeRepositoryConfigE(strings.TrimSpace(existingConfig), dependencyOptions.Repositories)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(repositoryConfig)
+
+		// Copy the options so the repositories config is only used for this command
+		optionsCopy := *options
+		optionsCopy.EnvVars = map[string]string{}
+		for key, value := range options.EnvVars {
+			optionsCopy.EnvVars[key] = value
+		}
+		optionsCopy.EnvVars["HELM_REPOSITORY_CONFIG"] = repositoryConfig
+		options = &optionsCopy
+	}
+
+	_, err := RunHelmCommandAndGetOutputE(t, options, "dependency", args...)
+	return err
+}
+
+// writeRepositoryConfigE writes the repositories of the given helm repositories config, if it exists, along with the
+// given repositories, which replace the existing ones of the same name, in a temporary file in the format of the helm
+// repositories config, and returns its path.
+func writeRepositoryConfigE(existingConfig string, repositories []DependencyRepository) (string, error) {
+	// The existing entries are kept as maps so the settings not managed here, such as the TLS files, are preserved
+	config := struct {
+		APIVersion   string                   `yaml:"apiVersion"`
+		Repositories []map[string]interface{} `yaml:"repositories"`
+	}{}
+	if existingConfig != "" && files.FileExists(existingConfig) {
+		data, err := os.ReadFile(existingConfig)
+		if err != nil {
+			return "", errors.WithStackTrace(err)
+		}
+		if err := goyaml.Unmarshal(data, &config); err != nil {
+			return "", errors.WithStackTrace(fmt.Errorf("failed to parse %s: %w", existingConfig, err))
+		}
+	}
+	if config.APIVersion == "" {
+		config.APIVersion = "v1"
+	}
+
+	for _, repository := range repositories {
+		entry := map[string]interface{}{"name": repository.Name, "url": repository.URL}
+		if repository.Username != "" {
+			entry["username"] = repository.Username
+		}
+		if repository.Password != "" {
+			entry["password"] = repository.Password
+		}
+		config.Repositories = slices.DeleteFunc(config.Repositories, func(existing map[string]interface{}) bool {
+			return existing["name"] == repository.Name
+		})
+		config.Repositories = append(config.Repositories, entry)
+	}
+
+	data, err := goyaml.Marshal(config)
+	if err != nil {
+		return "", errors.WithStackTrace(err)
+	}
+	configFile, err := os.CreateTemp("", "terratest-helm-repositories-*.yaml")
+	if err != nil {
+		return "", errors.WithStackTrace(err)
+	}
+	defer configFile.Close()
+	if _, err := configFile.Write(data); err != nil {
+		return "", errors.WithStackTrace(err)
+	}
+	return configFile.Name(), nil
+}
+
+// readChartDependenciesE reads the dependencies listed in the given Chart.yaml or Chart.lock file.
+func readChartDependenciesE(path string) ([]ChartDependency, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	var chart struct {
+		Dependencies []ChartDependency `yaml:"dependencies"`
+	}
+	if err := goyaml.Unmarshal(data, &chart); err != nil {
+		return nil, errors.WithStackTrace(fmt.Errorf("failed to parse %s: %w", path, err))
+	}
+	return chart.Dependencies, nil
+}
+
+// checkDependencyLock checks that the declared dependencies are all locked, from the same repository, and that the
+// lock doesn't have dependencies that are not declared anymore.
+func checkDependencyLock(declared []ChartDependency, locked []ChartDependency) error {
+	lockedByName := map[string]ChartDependency{}
+	for _, dependency := range locked {
+		lockedByName[dependency.Name] = dependency
+	}
+
+	for _, dependency := range declared {
+		lockedDependency, ok := lockedByName[dependency.Name]
+		if !ok {
+			return errors.WithStackTrace(fmt.Errorf("dependency %s is not locked in Chart.lock", dependency.Name))
+		}
+		if lockedDependency.Repository != dependency.Repository {
+			return errors.WithStackTrace(fmt.Errorf("dependency %s is locked from repository %s, but declared from %s", dependency.Name, lockedDependency.Repository, dependency.Repository))
+		}
+	}
+
+	declaredNames := map[string]bool{}
+	for _, dependency := range declared {
+		declaredNames[dependency.Name] = true
+	}
+	for _, dependency := range locked {
+		if !declaredNames[dependency.Name] {
+			return errors.WithStackTrace(fmt.Errorf("dependency %s is locked in Chart.lock but not declared in Chart.yaml", dependency.Name))
+		}
+	}
+	return nil
+}
+
+// verifyDependencyArchivesE verifies that the charts directory of the chart has the archive of every dependency at the
+// version locked in Chart.lock.
+func verifyDependencyArchivesE(chartDir string) error {
+	lockPath := filepath.Join(chartDir, "Chart.lock")
+	if !files.FileExists(lockPath) {
+		return nil
+	}
+	locked, err := readChartDependenciesE(lockPath)
+	if err != nil {
+		return err
+	}
+	for _, dependency := range locked {
+		archive := filepath.Join(chartDir, "charts", fmt.Sprintf("%s-%s.tgz", dependency.Name, dependency.Version))
+		if !files.FileExists(archive) {
+			return errors.WithStackTrace(fmt.Errorf("archive of dependency %s %s not found at %s", dependency.Name, dependency.Version, archive))
+		}
+	}
+	return nil
+}
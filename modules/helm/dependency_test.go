@@ -0,0 +1,126 @@
+package helm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	goyaml "gopkg.in/yaml.v3"
+)
+
+const dependencyChartYAML = `apiVersion: v2
+name: app
+version: 0.1.0
+dependencies:
+  - name: redis
+    version: ~18.0.0
+    repository: https://charts.bitnami.com/bitnami
+  - name: common
+    version: 0.1.0
+    repository: file://../common
+`
+
+func TestVerifyDependencyLock(t *testing.T) {
+	t.Parallel()
+
+	chartDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(chartDir, "Chart.yaml"), []byte(dependencyChartYAML), 0644))
+	require.Error(t, VerifyDependencyLockE(chartDir))
+
+	writeLock := func(lock string) {
+		require.NoError(t, os.WriteFile(filepath.Join(chartDir, "Chart.lock"), []byte(lock), 0644))
+	}
+	writeLock(`dependencies:
+- name: redis
+  repository: https://charts.bitnami.com/bitnami
+  version: 18.0.4
+- name: common
+  repository: file://../common
+  version: 0.1.0
+digest: sha256:abc
+generated: "2024-03-01T10:00:00Z"
+`)
+	require.NoError(t, VerifyDependencyLockE(chartDir))
+
+	// The archives are not fetched yet
+	require.Error(t, verifyDependencyArchivesE(chartDir))
+	require.NoError(t, os.MkdirAll(filepath.Join(chartDir, "charts"), 0755))
+	for _, archive := range []string{"redis-18.0.4.tgz", "common-0.1.0.tgz"} {
+		require.NoError(t, os.WriteFile(filepath.Join(chartDir, "charts", archive), []byte{}, 0644))
+	}
+	require.NoError(t, verifyDependencyArchivesE(chartDir))
+
+	writeLock(`dependencies:
+- name: redis
+  repository: https://charts.example.com
+  version: 18.0.4
+`)
+	require.Error(t, VerifyDependencyLockE(chartDir))
+}
+
+func TestCheckDependencyLock(t *testing.T) {
+	t.Parallel()
+
+	declared := []ChartDependency{{Name: "redis", Version: "~18.0.0", Repository: "oci://registry.example.com/charts"}}
+	require.NoError(t, checkDependencyLock(declared, []ChartDependency{{Name: "redis", Version: "18.0.4", Repository: "oci://registry.example.com/charts"}}))
+	require.Error(t, checkDependencyLock(declared, []ChartDependency{}))
+	require.Error(t, checkDependencyLock(declared, []ChartDependency{
+		{Name: "redis", Version: "18.0.4", Repository: "oci://registry.example.com/charts"},
+		{Name: "postgresql", Version: "13.0.0", Repository: "oci://registry.example.com/charts"},
+	}))
+}
+
+func TestWriteRepositoryConfig(t *testing.T) {
+	t.Parallel()
+
+	path, err := writeRepositoryConfigE("", []DependencyRepository{
+		{Name: "private", URL: "https://charts.example.com", Username: "ci", Password: "secret"},
+		{Name: "public", URL: "https://charts.bitnami.com/bitnami"},
+	})
+	require.NoError(t, err)
+	defer os.Remove(path)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var config struct {
+		Repositories []map[string]string `yaml:"repositories"`
+	}
+	require.NoError(t, goyaml.Unmarshal(data, &config))
+	assert.Equal(t, []map[string]string{
+		{"name": "private", "url": "https://charts.example.com", "username": "ci", "password": "secret"},
+		{"name": "public", "url": "https://charts.bitnami.com/bitnami"},
+	}, config.Repositories)
+}
+
+func TestWriteRepositoryConfigKeepsExistingRepositories(t *testing.T) {
+	t.Parallel()
+
+	existingConfig := filepath.Join(t.TempDir(), "repositories.yaml")
+	require.NoError(t, os.WriteFile(existingConfig, []byte(`apiVersion: v1
+repositories:
+  - name: internal
+    url: https://charts.internal.example.com
+    caFile: /etc/ssl/internal.pem
+  - name: private
+    url: https://old.example.com
+`), 0644))
+
+	path, err := writeRepositoryConfigE(existingConfig, []DependencyRepository{
+		{Name: "private", URL: "https://charts.example.com", Username: "ci", Password: "secret"},
+	})
+	require.NoError(t, err)
+	defer os.Remove(path)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var config struct {
+		Repositories []map[string]string `yaml:"repositories"`
+	}
+	require.NoError(t, goyaml.Unmarshal(data, &config))
+	assert.Equal(t, []map[string]string{
+		{"name": "internal", "url": "https://charts.internal.example.com", "caFile": "/etc/ssl/internal.pem"},
+		{"name": "private", "url": "https://charts.example.com", "username": "ci", "password": "secret"},
+	}, config.Repositories)
+}
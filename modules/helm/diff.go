@@ -0,0 +1,225 @@
+package helm
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/terratest/modules/files"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+	goyaml "gopkg.in/yaml.v3"
+)
+
+// Actions of the changes of the resources of a release an upgrade would make.
+const (
+	ResourceAdded   = "added"
+	ResourceRemoved = "removed"
+	ResourceChanged = "changed"
+)
+
+// ResourceChange is a change an upgrade would make to a resource of a release.
+type ResourceChange struct {
+	Kind   string
+	Name   string
+	Action string   // One of ResourceAdded, ResourceRemoved or ResourceChanged.
+	Paths  []string // The paths of the changed fields, e.g. spec.replicas, for changed resources.
+}
+
+// ReleaseDiff is the set of changes an upgrade would make to the resources of a release, sorted by kind and name.
+type ReleaseDiff struct {
+	Changes []ResourceChange
+}
+
+// ChangedKinds returns the kinds of the resources the upgrade would change, in alphabetical order.
+func (diff ReleaseDiff) ChangedKinds() []string {
+	kinds := []string{}
+	for _, change := range diff.Changes {
+		if len(kinds) == 0 || kinds[len(kinds)-1] != change.Kind {
+			kinds = append(kinds, change.Kind)
+		}
+	}
+	return kinds
+}
+
+// UpgradeDiff renders the chart with the values of the options and compares the manifests with the ones of the
+// deployed release, to return the changes an upgrade would make. This will fail the test if there is an error.
+func UpgradeDiff(t testing.TestingT, options *Options, chart string, releaseName string) ReleaseDiff {
+	diff, err := UpgradeDiffE(t, options, chart, releaseName)
+	require.NoError(t, err)
+	return diff
+}
+
+// UpgradeDiffE renders the chart with the values of the options and compares the manifests with the ones of the
+// deployed release, to return the changes an upgrade would make. This compares the manifests helm applies, rather than
+// the live resources, so changes made to the resources by controllers are not reported. Hooks are left out on both
+// sides. The extra args of the "template" command in the options are passed to helm template.
+func UpgradeDiffE(t testing.TestingT, options *Options, chart string, releaseName string) (ReleaseDiff, error) {
+	currentManifests, err := RunHelmCommandAndGetStdOutE(t, options, "get", "manifest", releaseName)
+	if err != nil {
+		return ReleaseDiff{}, err
+	}
+
+	// If the chart refers to a path, convert to absolute path. Otherwise, pass straight through as it may be a remote
+	// chart.
+	if files.FileExists(chart) {
+		absChartDir, err := filepath.Abs(chart)
+		if err != nil {
+			return ReleaseDiff{}, errors.WithStackTrace(err)
+		}
+		chart = absChartDir
+	}
+	args := []string{}
+	if options.KubectlOptions != nil && options.KubectlOptions.Namespace != "" {
+		args = append(args, "--namespace", options.KubectlOptions.Namespace)
+	}
+	if options.ExtraArgs != nil {
+		if templateArgs, ok := options.ExtraArgs["template"]; ok {
+			args = append(args, templateArgs...)
+		}
+	}
+	args, cleanup, err := getValuesArgsE(t, options, args...)
+	if err != nil {
+		return ReleaseDiff{}, err
+	}
+	defer cleanup()
+	// helm get manifest leaves out the hooks of the release, such as test pods, so leave them out of the upgraded
+	// manifests too, not to report them as added
+	args = append(args, "--no-hooks", releaseName, chart)
+	if options.Version != "" {
+		args = append(args, "--version", options.Version)
+	}
+	upgradedManifests, err := RunHelmCommandAndGetStdOutE(t, options, "template", args...)
+	if err != nil {
+		return ReleaseDiff{}, err
+	}
+
+	return diffManifestsE(currentManifests, upgradedManifests)
+}
+
+// AssertOnlyChangesKinds checks that the diff only changes resources of the given kinds. This will fail the test if
+// the diff changes other kinds of resources.
+func AssertOnlyChangesKinds(t testing.TestingT, diff ReleaseDiff, kinds ...string) {
+	require.NoError(t, AssertOnlyChangesKindsE(diff, kinds...))
+}
+
+// AssertOnlyChangesKindsE checks that the diff only changes resources of the given kinds, e.g. that a chart bump only
+// changes ConfigMaps and Deployments, and returns an error listing the other changes.
+func AssertOnlyChangesKindsE(diff ReleaseDiff, kinds ...string) error {
+	unexpected := []string{}
+	for _, change := range diff.Changes {
+		if !slices.Contains(kinds, change.Kind) {
+			unexpected = append(unexpected, fmt.Sprintf("%s %s/%s", change.Action, change.Kind, change.Name))
+		}
+	}
+	if len(unexpected) > 0 {
+		return fmt.Errorf("upgrade changes resources of other kinds than %s: %s", strings.Join(kinds, ", "), strings.Join(unexpected, ", "))
+	}
+	return nil
+}
+
+// manifestResource is a resource of the manifests of a release, decoded generically.
+type manifestResource struct {
+	kind    string
+	name    string
+	content map[string]interface{}
+}
+
+// diffManifestsE returns the changes between the resources of the given manifests, identified by kind and name.
+func diffManifestsE(currentManifests string, upgradedManifests string) (ReleaseDiff, error) {
+	current, err := parseManifestResourcesE(currentManifests)
+	if err != nil {
+		return ReleaseDiff{}, err
+	}
+	upgraded, err := parseManifestResourcesE(upgradedManifests)
+	if err != nil {
+		return ReleaseDiff{}, err
+	}
+
+	diff := ReleaseDiff{Changes: []ResourceChange{}}
+	for key, resource := range upgraded {
+		currentResource, ok := current[key]
+		if !ok {
+			diff.Changes = append(diff.Changes, ResourceChange{Kind: resource.kind, Name: resource.name, Action: ResourceAdded})
+			continue
+		}
+		if paths := diffFieldPaths(currentResource.content, resource.content, ""); len(paths) > 0 {
+			sort.Strings(paths)
+			diff.Changes = append(diff.Changes, ResourceChange{Kind: resource.kind, Name: resource.name, Action: ResourceChanged, Paths: paths})
+		}
+	}
+	for key, resource := range current {
+		if _, ok := upgraded[key]; !ok {
+			diff.Changes = append(diff.Changes, ResourceChange{Kind: resource.kind, Name: resource.name, Action: ResourceRemoved})
+		}
+	}
+
+	sort.Slice(diff.Changes, func(i, j int) bool {
+		if diff.Changes[i].Kind != diff.Changes[j].Kind {
+			return diff.Changes[i].Kind < diff.Changes[j].Kind
+		}
+		return diff.Changes[i].Name < diff.Changes[j].Name
+	})
+	return diff, nil
+}
+
+// parseManifestResourcesE parses the resources of the given multi-document manifests, keyed by kind and name.
+func parseManifestResourcesE(manifests string) (map[string]manifestResource, error) {
+	resources := map[string]manifestResource{}
+	decoder := goyaml.NewDecoder(strings.NewReader(manifests))
+	for {
+		var content map[string]interface{}
+		if err := decoder.Decode(&content); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, errors.WithStackTrace(err)
+		}
+		// Templates rendered empty leave empty documents
+		if content == nil {
+			continue
+		}
+
+		kind, _ := content["kind"].(string)
+		metadata, _ := content["metadata"].(map[string]interface{})
+		name, _ := metadata["name"].(string)
+		resources[kind+"/"+name] = manifestResource{kind: kind, name: name, content: content}
+	}
+	return resources, nil
+}
+
+// diffFieldPaths returns the dot separated paths of the fields that differ between the given values, recursing into
+// the objects, and comparing the other values, including lists, as a whole.
+func diffFieldPaths(current interface{}, upgraded interface{}, path string) []string {
+	currentMap, currentIsMap := current.(map[string]interface{})
+	upgradedMap, upgradedIsMap := upgraded.(map[string]interface{})
+	if !currentIsMap || !upgradedIsMap {
+		if reflect.DeepEqual(current, upgraded) {
+			return nil
+		}
+		return []string{path}
+	}
+
+	paths := []string{}
+	for key, value := range upgradedMap {
+		paths = append(paths, diffFieldPaths(currentMap[key], value, joinFieldPath(path, key))...)
+	}
+	for key, value := range currentMap {
+		if _, ok := upgradedMap[key]; !ok {
+			paths = append(paths, diffFieldPaths(value, nil, joinFieldPath(path, key))...)
+		}
+	}
+	return paths
+}
+
+func joinFieldPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,149 @@
+package helm
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffManifests(t *testing.T) {
+	t.Parallel()
+
+	current := `---
+# Source: web/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web
+data:
+  level: info
+---
+# Source: web/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    chart: web-1.0.0
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.24
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web-legacy
+`
+	upgraded := `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web
+data:
+  level: info
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    chart: web-1.1.0
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.25
+---
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: web
+`
+	diff, err := diffManifestsE(current, upgraded)
+	require.NoError(t, err)
+	assert.Equal(t, []ResourceChange{
+		{Kind: "Deployment", Name: "web", Action: ResourceChanged, Paths: []string{"metadata.labels.chart", "spec.template.spec.containers"}},
+		{Kind: "PodDisruptionBudget", Name: "web", Action: ResourceAdded},
+		{Kind: "Service", Name: "web-legacy", Action: ResourceRemoved},
+	}, diff.Changes)
+	assert.Equal(t, []string{"Deployment", "PodDisruptionBudget", "Service"}, diff.ChangedKinds())
+
+	require.NoError(t, AssertOnlyChangesKindsE(diff, "Deployment", "PodDisruptionBudget", "Service"))
+	err = AssertOnlyChangesKindsE(diff, "Deployment")
+	assert.EqualError(t, err, "upgrade changes resources of other kinds than Deployment: added PodDisruptionBudget/web, removed Service/web-legacy")
+
+	diff, err = diffManifestsE(current, current)
+	require.NoError(t, err)
+	assert.Empty(t, diff.Changes)
+}
+
+func TestUpgradeDiffLeavesOutHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake helm binary is a shell script")
+	}
+
+	// A fake helm binary returning the manifests of the release for helm get manifest, and the rendered chart for helm
+	// template, which includes a test hook unless --no-hooks is passed, as helm does
+	binDir := t.TempDir()
+	helmScript := `#!/bin/sh
+echo "$@" >> "$HELM_ARGS_FILE"
+case "$1 $*" in
+get*) cat "$HELM_MANIFEST_FILE" ;;
+*--no-hooks*) cat "$HELM_MANIFEST_FILE" ;;
+*) cat "$HELM_MANIFEST_FILE" "$HELM_HOOKS_FILE" ;;
+esac
+`
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "helm"), []byte(helmScript), 0755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	manifest := `---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`
+	hooks := `---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web-test-connection
+  annotations:
+    helm.sh/hook: test
+`
+	dataDir := t.TempDir()
+	argsFile := filepath.Join(dataDir, "args")
+	manifestFile := filepath.Join(dataDir, "manifest.yaml")
+	hooksFile := filepath.Join(dataDir, "hooks.yaml")
+	require.NoError(t, os.WriteFile(manifestFile, []byte(manifest), 0644))
+	require.NoError(t, os.WriteFile(hooksFile, []byte(hooks), 0644))
+
+	options := &Options{
+		EnvVars: map[string]string{
+			"HELM_ARGS_FILE":     argsFile,
+			"HELM_MANIFEST_FILE": manifestFile,
+			"HELM_HOOKS_FILE":    hooksFile,
+		},
+		ExtraArgs: map[string][]string{"template": {"--kube-version", "1.30"}},
+	}
+	diff, err := UpgradeDiffE(t, options, "example/web", "web")
+	require.NoError(t, err)
+	assert.Empty(t, diff.Changes)
+
+	args, err := os.ReadFile(argsFile)
+	require.NoError(t, err)
+	commands := strings.Split(strings.TrimSpace(string(args)), "\n")
+	require.Len(t, commands, 2)
+	assert.Equal(t, "get manifest web", commands[0])
+	assert.Equal(t, "template --kube-version 1.30 --no-hooks web example/web", commands[1])
+}
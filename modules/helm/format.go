@@ -2,16 +2,37 @@ package helm
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 
 	"github.com/gruntwork-io/go-commons/collections"
 	"github.com/gruntwork-io/go-commons/errors"
 	"github.com/stretchr/testify/require"
+	goyaml "gopkg.in/yaml.v3"
 
 	"github.com/gruntwork-io/terratest/modules/files"
 	"github.com/gruntwork-io/terratest/modules/testing"
 )
 
+// formatWaitArgs formats the given wait options as command line args for helm (e.g. --wait --timeout 5m0s). The
+// atomic option is only passed if the command supports it.
+func formatWaitArgs(waitOptions *WaitOptions, supportsAtomic bool) []string {
+	args := []string{"--wait"}
+	if waitOptions == nil {
+		return args
+	}
+	if waitOptions.Timeout > 0 {
+		args = append(args, "--timeout", waitOptions.Timeout.String())
+	}
+	if waitOptions.WaitForJobs {
+		args = append(args, "--wait-for-jobs")
+	}
+	if waitOptions.Atomic && supportsAtomic {
+		args = append(args, "--atomic")
+	}
+	return args
+}
+
 // formatSetValuesAsArgs formats the given values as command line args for helm using the given flag (e.g flags of
 // the format "--set"/"--set-string"/"--set-json" resulting in args like --set/set-string/set-json key=value...)
 func formatSetValuesAsArgs(setValues map[string]string, flag string) []string {
@@ -56,6 +77,32 @@ func formatValuesFilesAsArgsE(t testing.TestingT, valuesFiles []string) ([]strin
 	return args, nil
 }
 
+// formatValuesAsArgsE marshals the given values, a Go struct with yaml tags or a map, into a temporary values file and
+// formats it as command line args for helm (e.g of the format -f path), so the values don't need to be escaped as with
+// --set. There are no args if the values are nil. The returned function removes the values file, and should be called
+// once the helm command ran.
+func formatValuesAsArgsE(values interface{}) ([]string, func(), error) {
+	if values == nil {
+		return []string{}, func() {}, nil
+	}
+
+	data, err := goyaml.Marshal(values)
+	if err != nil {
+		return nil, nil, errors.WithStackTrace(err)
+	}
+	valuesFile, err := os.CreateTemp("", "terratest-helm-values-*.yaml")
+	if err != nil {
+		return nil, nil, errors.WithStackTrace(err)
+	}
+	cleanup := func() { os.Remove(valuesFile.Name()) }
+	defer valuesFile.Close()
+	if _, err := valuesFile.Write(data); err != nil {
+		cleanup()
+		return nil, nil, errors.WithStackTrace(err)
+	}
+	return []string{"-f", valuesFile.Name()}, cleanup, nil
+}
+
 // formatSetFilesAsArgs formats the given list of keys and file paths as command line args for helm to set from file
 // (e.g of the format --set-file key=path). This will fail the test if one of the paths do not exist or the absolute
 // path can not be determined.
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -217,3 +218,54 @@ func absPaths(t *testing.T, paths []string) []string {
 	}
 	return out
 }
+
+func TestFormatWaitArgs(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"--wait"}, formatWaitArgs(nil, true))
+	waitOptions := &WaitOptions{Timeout: 2 * time.Minute, WaitForJobs: true, Atomic: true}
+	assert.Equal(t, []string{"--wait", "--timeout", "2m0s", "--wait-for-jobs", "--atomic"}, formatWaitArgs(waitOptions, true))
+	assert.Equal(t, []string{"--wait", "--timeout", "2m0s", "--wait-for-jobs"}, formatWaitArgs(waitOptions, false))
+}
+
+func TestFormatValuesAsArgs(t *testing.T) {
+	t.Parallel()
+
+	type ingressValues struct {
+		Enabled bool     `yaml:"enabled"`
+		Hosts   []string `yaml:"hosts,omitempty"`
+	}
+	type chartValues struct {
+		ReplicaCount int               `yaml:"replicaCount"`
+		Annotations  map[string]string `yaml:"annotations"`
+		Ingress      ingressValues     `yaml:"ingress"`
+	}
+
+	args, cleanup, err := formatValuesAsArgsE(chartValues{
+		ReplicaCount: 2,
+		Annotations:  map[string]string{"example.com/escaped": "a,b=c"},
+		Ingress:      ingressValues{Enabled: true, Hosts: []string{"web.example.com"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, args, 2)
+	assert.Equal(t, "-f", args[0])
+
+	data, err := os.ReadFile(args[1])
+	require.NoError(t, err)
+	assert.Equal(t, `replicaCount: 2
+annotations:
+    example.com/escaped: a,b=c
+ingress:
+    enabled: true
+    hosts:
+        - web.example.com
+`, string(data))
+
+	cleanup()
+	assert.NoFileExists(t, args[1])
+
+	args, cleanup, err = formatValuesAsArgsE(nil)
+	require.NoError(t, err)
+	assert.Empty(t, args)
+	cleanup()
+}
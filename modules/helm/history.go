@@ -0,0 +1,57 @@
+package helm
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// Statuses of the revisions of a release, as reported by helm.
+const (
+	ReleaseStatusDeployed        = "deployed"
+	ReleaseStatusSuperseded      = "superseded"
+	ReleaseStatusFailed          = "failed"
+	ReleaseStatusPendingUpgrade  = "pending-upgrade"
+	ReleaseStatusPendingRollback = "pending-rollback"
+)
+
+// ReleaseRevision is a revision of a release, as listed by `helm history`.
+type ReleaseRevision struct {
+	Revision    int       `json:"revision"`
+	Updated     time.Time `json:"updated"`
+	Status      string    `json:"status"`
+	Chart       string    `json:"chart"`
+	AppVersion  string    `json:"app_version"`
+	Description string    `json:"description"`
+}
+
+// GetReleaseHistory returns the revisions of the release, from the oldest to the latest. This will fail the test if
+// there is an error.
+func GetReleaseHistory(t testing.TestingT, options *Options, releaseName string) []ReleaseRevision {
+	history, err := GetReleaseHistoryE(t, options, releaseName)
+	require.NoError(t, err)
+	return history
+}
+
+// GetReleaseHistoryE returns the revisions of the release, from the oldest to the latest, e.g. to check that a failed
+// atomic upgrade was rolled back: the latest revision is then deployed with a description starting with
+// "Rollback to".
+func GetReleaseHistoryE(t testing.TestingT, options *Options, releaseName string) ([]ReleaseRevision, error) {
+	output, err := RunHelmCommandAndGetStdOutE(t, options, "history", releaseName, "--output", "json")
+	if err != nil {
+		return nil, err
+	}
+	return parseReleaseHistory(output)
+}
+
+// parseReleaseHistory parses the JSON output of `helm history`.
+func parseReleaseHistory(output string) ([]ReleaseRevision, error) {
+	history := []ReleaseRevision{}
+	if err := json.Unmarshal([]byte(output), &history); err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	return history, nil
+}
@@ -0,0 +1,29 @@
+package helm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReleaseHistory(t *testing.T) {
+	t.Parallel()
+
+	history, err := parseReleaseHistory(`[{"revision":1,"updated":"2024-03-01T10:00:00.123456+01:00","status":"superseded","chart":"nginx-15.0.0","app_version":"1.25.0","description":"Install complete"},` +
+		`{"revision":2,"updated":"2024-03-01T10:05:00.654321+01:00","status":"failed","chart":"nginx-15.1.0","app_version":"1.25.1","description":"Upgrade \"web\" failed: context deadline exceeded"},` +
+		`{"revision":3,"updated":"2024-03-01T10:06:00.5+01:00","status":"deployed","chart":"nginx-15.0.0","app_version":"1.25.0","description":"Rollback to 1"}]`)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+
+	assert.Equal(t, 1, history[0].Revision)
+	assert.Equal(t, ReleaseStatusFailed, history[1].Status)
+	assert.Equal(t, "nginx-15.1.0", history[1].Chart)
+	assert.Equal(t, ReleaseStatusDeployed, history[2].Status)
+	assert.Equal(t, "Rollback to 1", history[2].Description)
+	assert.Equal(t, time.Date(2024, 3, 1, 9, 6, 0, 500000000, time.UTC), history[2].Updated.UTC())
+
+	_, err = parseReleaseHistory("Error: release: not found")
+	require.Error(t, err)
+}
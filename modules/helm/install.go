@@ -46,10 +46,11 @@ func InstallE(t testing.TestingT, options *Options, chart string, releaseName st
 	if options.Version != "" {
 		args = append(args, "--version", options.Version)
 	}
-	args, err = getValuesArgsE(t, options, args...)
+	args, cleanup, err := getValuesArgsE(t, options, args...)
 	if err != nil {
 		return err
 	}
+	defer cleanup()
 	args = append(args, releaseName, chart)
 	_, err = RunHelmCommandAndGetOutputE(t, options, "install", args...)
 	return err
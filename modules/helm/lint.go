@@ -0,0 +1,152 @@
+package helm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// Severities of the messages of `helm lint`.
+const (
+	LintSeverityInfo    = "INFO"
+	LintSeverityWarning = "WARNING"
+	LintSeverityError   = "ERROR"
+)
+
+// LintMessage is a message reported by `helm lint` on a chart.
+type LintMessage struct {
+	Chart    string // The path of the linted chart, as passed to helm.
+	Severity string // The severity of the message: INFO, WARNING or ERROR.
+	Path     string // The path of the file the message is about, relative to the chart (e.g. templates/deployment.yaml).
+	Message  string
+}
+
+// String returns the message formatted as printed by helm.
+func (message LintMessage) String() string {
+	if message.Path == "" {
+		return fmt.Sprintf("[%s] %s", message.Severity, message.Message)
+	}
+	return fmt.Sprintf("[%s] %s: %s", message.Severity, message.Path, message.Message)
+}
+
+// Lint runs `helm lint` on the chart at the given path, with the values of the options, and returns the messages it
+// reports. This will fail the test if helm can't lint the chart, but not if it reports errors: use AssertNoErrors for
+// that.
+func Lint(t testing.TestingT, options *Options, chartDir string) []LintMessage {
+	messages, err := LintE(t, options, chartDir)
+	require.NoError(t, err)
+	return messages
+}
+
+// LintE runs `helm lint` on the chart at the given path, with the values of the options, and returns the messages it
+// reports. helm exits with an error when it reports errors on the chart: that is not considered a failure of LintE,
+// which only returns an error if helm failed without reporting any error message.
+func LintE(t testing.TestingT, options *Options, chartDir string) ([]LintMessage, error) {
+	args := []string{}
+	if options.ExtraArgs != nil {
+		if lintArgs, ok := options.ExtraArgs["lint"]; ok {
+			args = append(args, lintArgs...)
+		}
+	}
+	args, cleanup, err := getValuesArgsE(t, options, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	args = append(args, chartDir)
+
+	output, err := RunHelmCommandAndGetOutputE(t, options, "lint", args...)
+	messages := parseLintOutput(output)
+	if err != nil && len(filterLintMessages(messages, LintSeverityError)) == 0 {
+		return messages, errors.WithStackTrace(err)
+	}
+	return messages, nil
+}
+
+// AssertNoErrors checks that none of the given lint messages is an error. This will fail the test if there is one.
+func AssertNoErrors(t testing.TestingT, messages []LintMessage) {
+	require.NoError(t, AssertNoErrorsE(messages))
+}
+
+// AssertNoErrorsE checks that none of the given lint messages is an error.
+func AssertNoErrorsE(messages []LintMessage) error {
+	return checkNoLintMessages(messages, LintSeverityError)
+}
+
+// AssertNoWarnings checks that none of the given lint messages is an error or a warning. This will fail the test if
+// there is one.
+func AssertNoWarnings(t testing.TestingT, messages []LintMessage) {
+	require.NoError(t, AssertNoWarningsE(messages))
+}
+
+// AssertNoWarningsE checks that none of the given lint messages is an error or a warning.
+func AssertNoWarningsE(messages []LintMessage) error {
+	return checkNoLintMessages(messages, LintSeverityError, LintSeverityWarning)
+}
+
+func checkNoLintMessages(messages []LintMessage, severities ...string) error {
+	found := filterLintMessages(messages, severities...)
+	if len(found) == 0 {
+		return nil
+	}
+	lines := []string{}
+	for _, message := range found {
+		lines = append(lines, message.String())
+	}
+	return fmt.Errorf("helm lint reported %d messages of severity %s:\n%s", len(found), strings.Join(severities, " or "), strings.Join(lines, "\n"))
+}
+
+func filterLintMessages(messages []LintMessage, severities ...string) []LintMessage {
+	found := []LintMessage{}
+	for _, message := range messages {
+		for _, severity := range severities {
+			if message.Severity == severity {
+				found = append(found, message)
+			}
+		}
+	}
+	return found
+}
+
+// parseLintOutput parses the messages printed by `helm lint`, formatted as:
+//
+// ==> Linting ./charts/web
+// [INFO] Chart.yaml: icon is recommended
+// [ERROR] templates/: template: web/templates/deployment.yaml:5:3: executing ...
+//
+// Error: 1 chart(s) linted, 1 chart(s) failed
+func parseLintOutput(output string) []LintMessage {
+	messages := []LintMessage{}
+	chart := ""
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if linted, found := strings.CutPrefix(line, "==> Linting "); found {
+			chart = strings.TrimSpace(linted)
+			continue
+		}
+		if !strings.HasPrefix(line, "[") {
+			continue
+		}
+		severity, rest, found := strings.Cut(line[1:], "] ")
+		if !found || !isLintSeverity(severity) {
+			continue
+		}
+
+		message := LintMessage{Chart: chart, Severity: severity, Message: rest}
+		// Paths have no spaces, unlike messages which may contain colons
+		if path, text, found := strings.Cut(rest, ": "); found && !strings.Contains(path, " ") {
+			message.Path = path
+			message.Message = text
+		}
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+func isLintSeverity(severity string) bool {
+	return severity == LintSeverityInfo || severity == LintSeverityWarning || severity == LintSeverityError
+}
@@ -0,0 +1,47 @@
+package helm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const exampleLintOutput = `==> Linting ./charts/web
+[INFO] Chart.yaml: icon is recommended
+[WARNING] templates/deployment.yaml: object name does not conform to Kubernetes naming requirements: "Web"
+[ERROR] templates/: template: web/templates/service.yaml:5:3: executing "web/templates/service.yaml" at <.Values.port>: nil pointer
+[ERROR] chart metadata is missing these dependencies: redis
+
+Error: 1 chart(s) linted, 1 chart(s) failed
+`
+
+func TestParseLintOutput(t *testing.T) {
+	t.Parallel()
+
+	messages := parseLintOutput(exampleLintOutput)
+	require.Len(t, messages, 4)
+
+	assert.Equal(t, LintMessage{Chart: "./charts/web", Severity: LintSeverityInfo, Path: "Chart.yaml", Message: "icon is recommended"}, messages[0])
+	assert.Equal(t, "templates/deployment.yaml", messages[1].Path)
+	assert.Equal(t, `object name does not conform to Kubernetes naming requirements: "Web"`, messages[1].Message)
+	assert.Equal(t, "templates/", messages[2].Path)
+	assert.Equal(t, LintMessage{Chart: "./charts/web", Severity: LintSeverityError, Message: "chart metadata is missing these dependencies: redis"}, messages[3])
+	assert.Equal(t, "[ERROR] chart metadata is missing these dependencies: redis", messages[3].String())
+}
+
+func TestAssertNoLintMessages(t *testing.T) {
+	t.Parallel()
+
+	messages := parseLintOutput(exampleLintOutput)
+	require.Error(t, AssertNoErrorsE(messages))
+	require.Error(t, AssertNoWarningsE(messages))
+
+	messages = parseLintOutput("==> Linting ./charts/web\n[INFO] Chart.yaml: icon is recommended\n\n1 chart(s) linted, 0 chart(s) failed")
+	require.NoError(t, AssertNoErrorsE(messages))
+	require.NoError(t, AssertNoWarningsE(messages))
+
+	messages = append(messages, LintMessage{Severity: LintSeverityWarning, Path: "values.yaml", Message: "file does not exist"})
+	require.NoError(t, AssertNoErrorsE(messages))
+	require.Error(t, AssertNoWarningsE(messages))
+}
@@ -1,12 +1,15 @@
 package helm
 
 import (
+	"time"
+
 	"github.com/gruntwork-io/terratest/modules/k8s"
 	"github.com/gruntwork-io/terratest/modules/logger"
 )
 
 type Options struct {
 	ValuesFiles       []string            // List of values files to render.
+	Values            interface{}         // Values as a Go struct or map, marshaled with its yaml tags into a values file passed after the ValuesFiles.
 	SetValues         map[string]string   // Values that should be set via the command line.
 	SetStrValues      map[string]string   // Values that should be set via the command line explicitly as `string` types.
 	SetJsonValues     map[string]string   // Values that should be set via the command line in JSON format.
@@ -20,3 +23,11 @@ type Options struct {
 	BuildDependencies bool                // If true, helm dependencies will be built before rendering template, installing or upgrade the chart.
 	SnapshotPath      string              // The path to the snapshot directory when using snapshot based testing. Empty string means use default ($PWD/__snapshot__).
 }
+
+// WaitOptions represents the options of helm to wait for the resources of a release to be ready after an upgrade or a
+// rollback.
+type WaitOptions struct {
+	Timeout     time.Duration // How long to wait for the resources to be ready. Empty means the default of helm (5m).
+	WaitForJobs bool          // If true, also wait for the jobs of the release to complete.
+	Atomic      bool          // If true, roll the release back to its previous revision if the upgrade fails.
+}
@@ -13,6 +13,23 @@ func Rollback(t testing.TestingT, options *Options, releaseName string, revision
 
 // RollbackE will downgrade the release to the specified version
 func RollbackE(t testing.TestingT, options *Options, releaseName string, revision string) error {
+	return rollbackE(t, options, releaseName, revision)
+}
+
+// RollbackAndWait will downgrade the release to the specified version, and wait until the resources of the release
+// are ready according to the wait options. This will fail the test if there is an error, or if the resources are not
+// ready in time.
+func RollbackAndWait(t testing.TestingT, options *Options, waitOptions *WaitOptions, releaseName string, revision string) {
+	require.NoError(t, RollbackAndWaitE(t, options, waitOptions, releaseName, revision))
+}
+
+// RollbackAndWaitE will downgrade the release to the specified version, and wait until the resources of the release
+// are ready according to the wait options. The atomic option doesn't apply to rollbacks.
+func RollbackAndWaitE(t testing.TestingT, options *Options, waitOptions *WaitOptions, releaseName string, revision string) error {
+	return rollbackE(t, options, releaseName, revision, formatWaitArgs(waitOptions, false)...)
+}
+
+func rollbackE(t testing.TestingT, options *Options, releaseName string, revision string, waitArgs ...string) error {
 	var err error
 	args := []string{}
 	if options.ExtraArgs != nil {
@@ -20,6 +37,7 @@ func RollbackE(t testing.TestingT, options *Options, releaseName string, revisio
 			args = append(args, rollbackArgs...)
 		}
 	}
+	args = append(args, waitArgs...)
 	args = append(args, releaseName)
 	if revision != "" {
 		args = append(args, revision)
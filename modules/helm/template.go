@@ -16,6 +16,10 @@ import (
 	"github.com/homeport/dyff/pkg/dyff"
 	"github.com/stretchr/testify/require"
 	goyaml "gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
 )
 
 // RenderTemplate runs `helm template` to render the template given the provided options and returns stdout/stderr from
@@ -31,10 +35,11 @@ func RenderTemplate(t testing.TestingT, options *Options, chartDir string, relea
 // the template command. If you pass in templateFiles, this will only render those templates.
 func RenderTemplateE(t testing.TestingT, options *Options, chartDir string, releaseName string, templateFiles []string, extraHelmArgs ...string) (string, error) {
 	// Get render arguments
-	args, err := getRenderArgs(t, options, chartDir, releaseName, templateFiles, extraHelmArgs...)
+	args, cleanup, err := getRenderArgs(t, options, chartDir, releaseName, templateFiles, extraHelmArgs...)
 	if err != nil {
 		return "", err
 	}
+	defer cleanup()
 
 	// Finally, call out to helm template command
 	return RunHelmCommandAndGetStdOutE(t, options, "template", args...)
@@ -43,29 +48,32 @@ func RenderTemplateE(t testing.TestingT, options *Options, chartDir string, rele
 // RenderTemplateAndGetStdOutErrE runs `helm template` to render the template given the provided options and returns stdout and stderr separately from
 // the template command. If you pass in templateFiles, this will only render those templates.
 func RenderTemplateAndGetStdOutErrE(t testing.TestingT, options *Options, chartDir string, releaseName string, templateFiles []string, extraHelmArgs ...string) (string, string, error) {
-	args, err := getRenderArgs(t, options, chartDir, releaseName, templateFiles, extraHelmArgs...)
+	args, cleanup, err := getRenderArgs(t, options, chartDir, releaseName, templateFiles, extraHelmArgs...)
 	if err != nil {
 		return "", "", err
 	}
+	defer cleanup()
 
 	// Finally, call out to helm template command
 	return RunHelmCommandAndGetStdOutErrE(t, options, "template", args...)
 }
 
-func getRenderArgs(t testing.TestingT, options *Options, chartDir string, releaseName string, templateFiles []string, extraHelmArgs ...string) ([]string, error) {
+// getRenderArgs returns the args of helm template to render the given chart. The returned function removes the
+// temporary files of the args, and should be called once helm template ran.
+func getRenderArgs(t testing.TestingT, options *Options, chartDir string, releaseName string, templateFiles []string, extraHelmArgs ...string) ([]string, func(), error) {
 	// First, verify the charts dir exists
 	absChartDir, err := filepath.Abs(chartDir)
 	if err != nil {
-		return nil, errors.WithStackTrace(err)
+		return nil, nil, errors.WithStackTrace(err)
 	}
 	if !files.FileExists(chartDir) {
-		return nil, errors.WithStackTrace(ChartNotFoundError{chartDir})
+		return nil, nil, errors.WithStackTrace(ChartNotFoundError{chartDir})
 	}
 
 	// check chart dependencies
 	if options.BuildDependencies {
 		if _, err := RunHelmCommandAndGetOutputE(t, options, "dependency", "build", chartDir); err != nil {
-			return nil, errors.WithStackTrace(err)
+			return nil, nil, errors.WithStackTrace(err)
 		}
 	}
 
@@ -75,15 +83,16 @@ func getRenderArgs(t testing.TestingT, options *Options, chartDir string, releas
 	if options.KubectlOptions != nil && options.KubectlOptions.Namespace != "" {
 		args = append(args, "--namespace", options.KubectlOptions.Namespace)
 	}
-	args, err = getValuesArgsE(t, options, args...)
+	args, cleanup, err := getValuesArgsE(t, options, args...)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	for _, templateFile := range templateFiles {
 		// validate this is a valid template file
 		absTemplateFile := filepath.Join(absChartDir, templateFile)
 		if !strings.HasPrefix(templateFile, "charts") && !files.FileExists(absTemplateFile) {
-			return nil, errors.WithStackTrace(TemplateFileNotFoundError{Path: templateFile, ChartDir: absChartDir})
+			cleanup()
+			return nil, nil, errors.WithStackTrace(TemplateFileNotFoundError{Path: templateFile, ChartDir: absChartDir})
 		}
 
 		// Note: we only get the abs template file path to check it actually exists, but the `helm template` command
@@ -95,7 +104,7 @@ func getRenderArgs(t testing.TestingT, options *Options, chartDir string, releas
 
 	// ... and add the name and chart at the end as the command expects
 	args = append(args, releaseName, chartDir)
-	return args, nil
+	return args, cleanup, nil
 }
 
 // RenderRemoteTemplate runs `helm template` to render a *remote* chart  given the provided options and returns stdout/stderr from
@@ -116,10 +125,11 @@ func RenderRemoteTemplateE(t testing.TestingT, options *Options, chartURL string
 	if options.KubectlOptions != nil && options.KubectlOptions.Namespace != "" {
 		args = append(args, "--namespace", options.KubectlOptions.Namespace)
 	}
-	args, err := getValuesArgsE(t, options, args...)
+	args, cleanup, err := getValuesArgsE(t, options, args...)
 	if err != nil {
 		return "", err
 	}
+	defer cleanup()
 	for _, templateFile := range templateFiles {
 		// As the helm command fails if a non valid template is given as input
 		// we do not check if the template file exists or not as we do for local charts
@@ -139,6 +149,125 @@ func RenderRemoteTemplateE(t testing.TestingT, options *Options, chartURL string
 	return RunHelmCommandAndGetStdOutE(t, options, "template", args...)
 }
 
+// RenderedObjects are the Kubernetes objects rendered from a chart, grouped by kind (e.g. Deployment) and then by
+// namespace/name, or by name only for the objects rendered without a namespace.
+type RenderedObjects map[string]map[string]runtime.Object
+
+// Get returns the rendered object of the given kind and name, or nil if there is none. The name can be given as
+// namespace/name to pick among objects of the same name in different namespaces. A name without a namespace also
+// matches an object rendered with a namespace, as long as it is the only object of the kind with that name.
+func (objects RenderedObjects) Get(kind string, name string) runtime.Object {
+	if object, ok := objects[kind][name]; ok {
+		return object
+	}
+	if strings.Contains(name, "/") {
+		return nil
+	}
+
+	var found runtime.Object
+	for key, object := range objects[kind] {
+		if _, objectName, ok := strings.Cut(key, "/"); ok && objectName == name {
+			if found != nil {
+				return nil
+			}
+			found = object
+		}
+	}
+	return found
+}
+
+// renderedObjectKey returns the key of an object of the given namespace and name in RenderedObjects.
+func renderedObjectKey(namespace string, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "/" + name
+}
+
+// RenderTemplateToObjects runs `helm template` to render the template given the provided options, like
+// RenderTemplate, and decodes the rendered manifests into Kubernetes objects. This function will fail the test if there
+// is an error rendering the template or decoding the manifests.
+func RenderTemplateToObjects(t testing.TestingT, options *Options, chartDir string, releaseName string, templateFiles []string, extraHelmArgs ...string) RenderedObjects {
+	objects, err := RenderTemplateToObjectsE(t, options, chartDir, releaseName, templateFiles, extraHelmArgs...)
+	require.NoError(t, err)
+	return objects
+}
+
+// RenderTemplateToObjectsE runs `helm template` to render the template given the provided options, like
+// RenderTemplateE, and decodes the rendered manifests into Kubernetes objects. The objects of the kinds known to
+// client-go are decoded into their client-go struct (e.g. *appsv1.Deployment), and the other ones, such as custom
+// resources, into *unstructured.Unstructured. For example:
+//
+// objects, err := RenderTemplateToObjectsE(t, options, chartDir, "release", nil)
+// deployment := objects.Get("Deployment", "release-nginx").(*appsv1.Deployment)
+// worker := objects.Get("Deployment", "jobs/release-worker").(*appsv1.Deployment)
+func RenderTemplateToObjectsE(t testing.TestingT, options *Options, chartDir string, releaseName string, templateFiles []string, extraHelmArgs ...string) (RenderedObjects, error) {
+	yamlData, err := RenderTemplateE(t, options, chartDir, releaseName, templateFiles, extraHelmArgs...)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeK8SYamlsE(yamlData)
+}
+
+// DecodeK8SYamlsE decodes the given manifests, which can contain multiple YAML documents, into Kubernetes objects
+// grouped by kind and namespace/name. The objects of the kinds known to client-go are decoded into their client-go struct, and
+// the other ones into *unstructured.Unstructured. Empty documents are skipped.
+func DecodeK8SYamlsE(yamlData string) (RenderedObjects, error) {
+	objects := RenderedObjects{}
+	decoder := goyaml.NewDecoder(strings.NewReader(yamlData))
+	for {
+		var rawYaml interface{}
+		if err := decoder.Decode(&rawYaml); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, errors.WithStackTrace(err)
+		}
+		// Templates rendered empty leave empty documents
+		if rawYaml == nil {
+			continue
+		}
+
+		jsonData, err := json.Marshal(rawYaml)
+		if err != nil {
+			return nil, errors.WithStackTrace(err)
+		}
+		object, err := decodeK8SObject(jsonData)
+		if err != nil {
+			return nil, err
+		}
+
+		metadata, err := meta.Accessor(object)
+		if err != nil {
+			return nil, errors.WithStackTrace(err)
+		}
+		kind := object.GetObjectKind().GroupVersionKind().Kind
+		if objects[kind] == nil {
+			objects[kind] = map[string]runtime.Object{}
+		}
+		objects[kind][renderedObjectKey(metadata.GetNamespace(), metadata.GetName())] = object
+	}
+	return objects, nil
+}
+
+// decodeK8SObject decodes the given JSON object into its client-go struct if its kind is known, and into an
+// unstructured object otherwise.
+func decodeK8SObject(jsonData []byte) (runtime.Object, error) {
+	object, _, err := scheme.Codecs.UniversalDeserializer().Decode(jsonData, nil, nil)
+	if err == nil {
+		return object, nil
+	}
+	if !runtime.IsNotRegisteredError(err) {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	unstructuredObject := &unstructured.Unstructured{}
+	if err := unstructuredObject.UnmarshalJSON(jsonData); err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	return unstructuredObject, nil
+}
+
 // UnmarshalK8SYamls is the same as UnmarshalK8SYamlsE, but will fail the test if there is an error.
 func UnmarshalK8SYamls[T any](t testing.TestingT, yamlData string, destinationObj *[]T, check func(v T) bool) {
 	require.NoError(t, UnmarshalK8SYamlsE(t, yamlData, destinationObj, check))
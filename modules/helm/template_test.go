@@ -21,6 +21,7 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"github.com/gruntwork-io/terratest/modules/k8s"
 	"github.com/gruntwork-io/terratest/modules/logger"
@@ -221,3 +222,90 @@ func TestRenderMultipleManifests(t *testing.T) {
 	require.Len(t, deploys, 1)
 	assert.Equal(t, deploys[0].Name, "test-deployment")
 }
+
+func TestRenderTemplateToObjects(t *testing.T) {
+	chart, err := filepath.Abs("testdata/multiple-manifests")
+	require.NoError(t, err)
+
+	objects := RenderTemplateToObjects(t, &Options{}, chart, "test", []string{})
+
+	configMap, ok := objects.Get("ConfigMap", "test-configmap").(*corev1.ConfigMap)
+	require.True(t, ok)
+	assert.Equal(t, "test-configmap", configMap.Name)
+	deployment, ok := objects.Get("Deployment", "test-deployment").(*appsv1.Deployment)
+	require.True(t, ok)
+	assert.Equal(t, "test-deployment", deployment.Name)
+	assert.Nil(t, objects.Get("Service", "test-service"))
+}
+
+func TestDecodeK8SYamls(t *testing.T) {
+	t.Parallel()
+
+	objects, err := DecodeK8SYamlsE(`---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+---
+# Source: chart/templates/disabled.yaml
+---
+apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  name: web
+spec:
+  endpoints:
+  - port: http
+`)
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+
+	service, ok := objects.Get("Service", "web").(*corev1.Service)
+	require.True(t, ok)
+	assert.Equal(t, int32(80), service.Spec.Ports[0].Port)
+
+	serviceMonitor, ok := objects.Get("ServiceMonitor", "web").(*unstructured.Unstructured)
+	require.True(t, ok)
+	assert.Equal(t, "monitoring.coreos.com/v1", serviceMonitor.GetAPIVersion())
+
+	objects, err = DecodeK8SYamlsE(`---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: apps
+data:
+  env: apps
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: jobs
+data:
+  env: jobs
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: token
+  namespace: apps
+`)
+	require.NoError(t, err)
+	require.Len(t, objects["ConfigMap"], 2)
+	appsConfigMap, ok := objects.Get("ConfigMap", "apps/settings").(*corev1.ConfigMap)
+	require.True(t, ok)
+	assert.Equal(t, "apps", appsConfigMap.Data["env"])
+	jobsConfigMap, ok := objects.Get("ConfigMap", "jobs/settings").(*corev1.ConfigMap)
+	require.True(t, ok)
+	assert.Equal(t, "jobs", jobsConfigMap.Data["env"])
+	assert.Nil(t, objects.Get("ConfigMap", "settings"))
+	_, ok = objects.Get("Secret", "token").(*corev1.Secret)
+	assert.True(t, ok)
+
+	_, err = DecodeK8SYamlsE("apiVersion: v1\nkind: Service\nspec: []\n")
+	require.Error(t, err)
+}
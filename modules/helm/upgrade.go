@@ -17,6 +17,24 @@ func Upgrade(t testing.TestingT, options *Options, chart string, releaseName str
 
 // UpgradeE will upgrade the release and chart will be deployed with the lastest configuration.
 func UpgradeE(t testing.TestingT, options *Options, chart string, releaseName string) error {
+	return upgradeE(t, options, chart, releaseName)
+}
+
+// UpgradeAndWait will upgrade the release like Upgrade, and wait until the resources of the release are ready
+// according to the wait options. This will fail the test if there is an error, or if the resources are not ready in
+// time.
+func UpgradeAndWait(t testing.TestingT, options *Options, waitOptions *WaitOptions, chart string, releaseName string) {
+	require.NoError(t, UpgradeAndWaitE(t, options, waitOptions, chart, releaseName))
+}
+
+// UpgradeAndWaitE will upgrade the release like UpgradeE, and wait until the resources of the release are ready
+// according to the wait options. With the atomic option, helm rolls the release back to its previous revision if the
+// upgrade fails, which can be checked with GetReleaseHistoryE.
+func UpgradeAndWaitE(t testing.TestingT, options *Options, waitOptions *WaitOptions, chart string, releaseName string) error {
+	return upgradeE(t, options, chart, releaseName, formatWaitArgs(waitOptions, true)...)
+}
+
+func upgradeE(t testing.TestingT, options *Options, chart string, releaseName string, waitArgs ...string) error {
 	// If the chart refers to a path, convert to absolute path. Otherwise, pass straight through as it may be a remote
 	// chart.
 	if files.FileExists(chart) {
@@ -40,11 +58,13 @@ func UpgradeE(t testing.TestingT, options *Options, chart string, releaseName st
 			args = append(args, upgradeArgs...)
 		}
 	}
-	args, err = getValuesArgsE(t, options, args...)
+	args, cleanup, err := getValuesArgsE(t, options, args...)
 	if err != nil {
 		return err
 	}
+	defer cleanup()
 
+	args = append(args, waitArgs...)
 	args = append(args, "--install", releaseName, chart)
 	if options.Version != "" {
 		args = append(args, "--version", options.Version)
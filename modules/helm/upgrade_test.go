@@ -99,6 +99,16 @@ func TestRemoteChartInstallUpgradeRollback(t *testing.T) {
 	// Finally, test rollback functionality. When rolling back, we should see the pods go back down to 1.
 	Rollback(t, options, releaseName, "")
 	waitForRemoteChartPods(t, kubectlOptions, releaseName, 1)
+
+	// The history lists the install, upgrade and rollback revisions, the rollback being the deployed one
+	history := GetReleaseHistory(t, options, releaseName)
+	require.Len(t, history, 3)
+	assert.Equal(t, ReleaseStatusSuperseded, history[1].Status)
+	assert.Equal(t, ReleaseStatusDeployed, history[2].Status)
+	assert.Equal(t, "Rollback to 1", history[2].Description)
+
+	UpgradeAndWait(t, options, &WaitOptions{Timeout: 5 * time.Minute, Atomic: true}, helmChart, releaseName)
+	assert.Equal(t, ReleaseStatusDeployed, GetReleaseHistory(t, options, releaseName)[3].Status)
 }
 
 // Test deployment of helm chart with dependencies.
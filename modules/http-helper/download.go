@@ -0,0 +1,296 @@
+package http_helper
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+const (
+	// defaultDownloadRetries is the number of retries of DownloadFile.
+	defaultDownloadRetries = 3
+
+	// defaultDownloadSleepBetweenRetries is the sleep between the retries of DownloadFile.
+	defaultDownloadSleepBetweenRetries = 5 * time.Second
+
+	// defaultDownloadTimeout is the timeout of each attempt of DownloadFile, so a stalled server can't hang the test.
+	defaultDownloadTimeout = 10 * time.Minute
+
+	// downloadProgressInterval is how often the progress of a download is logged.
+	downloadProgressInterval = 10 * time.Second
+)
+
+// DownloadFileOptions are the options of DownloadFileWithOptions.
+type DownloadFileOptions struct {
+	Url                 string
+	DestPath            string
+	ExpectedSha256      string // the hex SHA-256 checksum the file must have, if set
+	ExpectedSize        int64  // the size in bytes the file must have, if set
+	Headers             map[string]string
+	TlsConfig           *tls.Config
+	TlsOptions          *TlsOptions // used to build the TLS configuration, if TlsConfig is not set
+	ProxyUrl            string
+	Timeout             int // the timeout of each attempt, in seconds, or zero for no timeout
+	Retries             int
+	SleepBetweenRetries time.Duration
+}
+
+// DownloadFile downloads the file at the given URL to the given path, retrying and resuming the download on errors, and
+// verifies its SHA-256 checksum if expectedSha256 is set. If the download fails, fail the test.
+func DownloadFile(t testing.TestingT, url string, destPath string, expectedSha256 string) {
+	if err := DownloadFileE(t, url, destPath, expectedSha256); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// DownloadFileE downloads the file at the given URL to the given path, retrying and resuming the download on errors
+// up to 3 times, each attempt timing out after 10 minutes, and verifies its SHA-256 checksum if expectedSha256 is set.
+func DownloadFileE(t testing.TestingT, url string, destPath string, expectedSha256 string) error {
+	return DownloadFileWithOptionsE(t, DownloadFileOptions{
+		Url:                 url,
+		DestPath:            destPath,
+		ExpectedSha256:      expectedSha256,
+		Timeout:             int(defaultDownloadTimeout / time.Second),
+		Retries:             defaultDownloadRetries,
+		SleepBetweenRetries: defaultDownloadSleepBetweenRetries,
+	})
+}
+
+// DownloadFileWithOptions downloads the file at the given URL to the given path, retrying and resuming the download on
+// errors, and verifies its checksum and size if they're set. If the download fails, fail the test.
+func DownloadFileWithOptions(t testing.TestingT, options DownloadFileOptions) {
+	if err := DownloadFileWithOptionsE(t, options); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// DownloadFileWithOptionsE downloads the file at the given URL to the given path, retrying and resuming the download on
+// errors, and verifies its checksum and size if they're set. The file is downloaded to a .part file next to the
+// destination, which is resumed with a Range request on retries if the server supports it, and only moved to the
+// destination once verified. A retry only resumes the part file if the file on the server is still the same: its ETag
+// or Last-Modified date is sent with If-Range, and the size in the Content-Range of the response must match. Otherwise,
+// the download starts over. A part file left over by an earlier call can't be checked, so it is discarded. A client
+// error status code, other than 408 and 429, is not retried. A 416 is only expected when resuming, and means the part
+// file is already complete, unless the size of the file on the server doesn't match, in which case the download starts
+// over.
+func DownloadFileWithOptionsE(t testing.TestingT, options DownloadFileOptions) error {
+	tlsConfig, err := getTlsConfigE(options.TlsConfig, options.TlsOptions)
+	if err != nil {
+		return err
+	}
+	tr, err := newHttpTransportE(tlsConfig, options.ProxyUrl, HttpProtocolAuto)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: time.Duration(options.Timeout) * time.Second, Transport: tr}
+
+	if err := os.MkdirAll(filepath.Dir(options.DestPath), 0755); err != nil {
+		return err
+	}
+	partPath := options.DestPath + ".part"
+	if err := os.Remove(partPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	download := &partialDownload{path: partPath}
+	_, err = retry.DoWithRetryE(t, fmt.Sprintf("Downloading URL %s to %s", options.Url, options.DestPath), options.Retries, options.SleepBetweenRetries, func() (string, error) {
+		if err := downloadToPartFileE(t, client, options, download); err != nil {
+			return "", err
+		}
+		if err := verifyDownloadedFileE(options, partPath); err != nil {
+			// Start over on the next attempt, in case a resumed download got corrupted
+			download.restart()
+			return "", err
+		}
+		return "", os.Rename(partPath, options.DestPath)
+	})
+	return err
+}
+
+// partialDownload is the part file of a download, along with what identifies the file on the server it was started
+// from, to check that a resumed download continues the same file.
+type partialDownload struct {
+	path      string
+	validator string // the strong ETag or the Last-Modified date of the file, to send with If-Range
+	size      int64  // the size of the file, or -1 if unknown
+}
+
+// resumeOffset returns the offset to resume the download from, or 0 to download the whole file if the part file is
+// missing or the file on the server can't be identified.
+func (download *partialDownload) resumeOffset() int64 {
+	if download.validator == "" && download.size <= 0 {
+		return 0
+	}
+	info, err := os.Stat(download.path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// start records what identifies the file on the server from the full response of a download.
+func (download *partialDownload) start(resp *http.Response) {
+	download.validator = resp.Header.Get("ETag")
+	if download.validator == "" || strings.HasPrefix(download.validator, "W/") {
+		// Weak ETags can't be used with If-Range
+		download.validator = resp.Header.Get("Last-Modified")
+	}
+	download.size = resp.ContentLength
+}
+
+// restart removes the part file, so that the next attempt downloads the whole file.
+func (download *partialDownload) restart() {
+	os.Remove(download.path)
+	download.validator = ""
+	download.size = -1
+}
+
+// contentRangeSize returns the size of the file in the given Content-Range header (e.g. bytes 100-199/1000 or
+// bytes */1000), or -1 if it's unknown.
+func contentRangeSize(contentRange string) int64 {
+	index := strings.LastIndex(contentRange, "/")
+	if index < 0 {
+		return -1
+	}
+	size, err := strconv.ParseInt(contentRange[index+1:], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return size
+}
+
+// downloadToPartFileE downloads the file at the URL of the given options to the part file of the given download,
+// resuming from the end of the part file if the file on the server is still the same.
+func downloadToPartFileE(t testing.TestingT, client *http.Client, options DownloadFileOptions, download *partialDownload) error {
+	offset := download.resumeOffset()
+
+	req, err := http.NewRequest(http.MethodGet, options.Url, nil)
+	if err != nil {
+		return retry.FatalError{Underlying: err}
+	}
+	for key, value := range options.Headers {
+		req.Header.Set(key, value)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		if download.validator != "" {
+			// The server sends the whole file instead of the range if it changed
+			req.Header.Set("If-Range", download.validator)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	total := resp.ContentLength
+	contentRange := resp.Header.Get("Content-Range")
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		if !strings.HasPrefix(contentRange, fmt.Sprintf("bytes %d-", offset)) || (download.size > 0 && contentRangeSize(contentRange) != download.size) {
+			download.restart()
+			return fmt.Errorf("the range %q of URL %s doesn't continue the partial download of %d bytes, starting over", contentRange, options.Url, offset)
+		}
+		logger.Default.Logf(t, "Resuming the download of URL %s from byte %d", options.Url, offset)
+		flags |= os.O_APPEND
+		if total >= 0 {
+			total += offset
+		}
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		if size := contentRangeSize(contentRange); size >= 0 && size != offset {
+			download.restart()
+			return fmt.Errorf("the partial download of URL %s has %d bytes, but the file has %d, starting over", options.Url, offset, size)
+		}
+		// The part file is already complete
+		return nil
+	case resp.StatusCode == http.StatusOK:
+		// The server doesn't support ranges, the file changed, or it's the first attempt
+		download.start(resp)
+		flags |= os.O_TRUNC
+		offset = 0
+	case resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests:
+		return retry.FatalError{Underlying: fmt.Errorf("downloading URL %s failed with status %d", options.Url, resp.StatusCode)}
+	default:
+		return fmt.Errorf("downloading URL %s failed with status %d", options.Url, resp.StatusCode)
+	}
+
+	file, err := os.OpenFile(download.path, flags, 0644)
+	if err != nil {
+		return retry.FatalError{Underlying: err}
+	}
+	defer file.Close()
+
+	progress := &downloadProgressWriter{t: t, url: options.Url, downloaded: offset, total: total, lastLog: time.Now()}
+	if _, err := io.Copy(io.MultiWriter(file, progress), resp.Body); err != nil {
+		return err
+	}
+	logger.Default.Logf(t, "Downloaded %d bytes of URL %s", progress.downloaded, options.Url)
+	return nil
+}
+
+// verifyDownloadedFileE returns an error if the file at the given path doesn't have the expected size or checksum.
+func verifyDownloadedFileE(options DownloadFileOptions, path string) error {
+	if options.ExpectedSize > 0 {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if info.Size() != options.ExpectedSize {
+			return fmt.Errorf("file downloaded from URL %s is %d bytes, expected %d", options.Url, info.Size(), options.ExpectedSize)
+		}
+	}
+
+	if options.ExpectedSha256 != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		hash := sha256.New()
+		if _, err := io.Copy(hash, file); err != nil {
+			return err
+		}
+		if checksum := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(checksum, options.ExpectedSha256) {
+			return fmt.Errorf("file downloaded from URL %s has SHA-256 checksum %s, expected %s", options.Url, checksum, options.ExpectedSha256)
+		}
+	}
+	return nil
+}
+
+// downloadProgressWriter logs the progress of a download periodically, as its bytes are written.
+type downloadProgressWriter struct {
+	t          testing.TestingT
+	url        string
+	downloaded int64
+	total      int64 // the size of the file, or -1 if unknown
+	lastLog    time.Time
+}
+
+func (writer *downloadProgressWriter) Write(data []byte) (int, error) {
+	writer.downloaded += int64(len(data))
+	if time.Since(writer.lastLog) >= downloadProgressInterval {
+		writer.lastLog = time.Now()
+		if writer.total > 0 {
+			logger.Default.Logf(writer.t, "Downloaded %d of %d bytes (%d%%) of URL %s", writer.downloaded, writer.total, 100*writer.downloaded/writer.total, writer.url)
+		} else {
+			logger.Default.Logf(writer.t, "Downloaded %d bytes of URL %s", writer.downloaded, writer.url)
+		}
+	}
+	return len(data), nil
+}
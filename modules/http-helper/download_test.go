@@ -0,0 +1,147 @@
+package http_helper
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testDownloadContent = bytes.Repeat([]byte("terratest download "), 1000)
+
+func testDownloadSha256() string {
+	checksum := sha256.Sum256(testDownloadContent)
+	return hex.EncodeToString(checksum[:])
+}
+
+func TestDownloadFile(t *testing.T) {
+	t.Parallel()
+	ts := getTestServerForFunction(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "fixture", time.Time{}, bytes.NewReader(testDownloadContent))
+	})
+	defer ts.Close()
+
+	destPath := filepath.Join(t.TempDir(), "fixtures", "fixture.bin")
+	DownloadFile(t, ts.URL, destPath, testDownloadSha256())
+
+	content, err := os.ReadFile(destPath)
+	require.NoError(t, err)
+	assert.Equal(t, testDownloadContent, content)
+	assert.NoFileExists(t, destPath+".part")
+}
+
+func TestDownloadFileResumes(t *testing.T) {
+	t.Parallel()
+	var counter int32
+	var rangeHeader atomic.Value
+	ts := getTestServerForFunction(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&counter, 1) == 1 {
+			// Drop the connection in the middle of the body
+			w.Header().Set("Content-Length", strconv.Itoa(len(testDownloadContent)))
+			w.Write(testDownloadContent[:len(testDownloadContent)/2])
+			return
+		}
+		rangeHeader.Store(r.Header.Get("Range"))
+		http.ServeContent(w, r, "fixture", time.Time{}, bytes.NewReader(testDownloadContent))
+	})
+	defer ts.Close()
+
+	destPath := filepath.Join(t.TempDir(), "fixture.bin")
+	DownloadFileWithOptions(t, DownloadFileOptions{
+		Url:                 ts.URL,
+		DestPath:            destPath,
+		ExpectedSha256:      testDownloadSha256(),
+		ExpectedSize:        int64(len(testDownloadContent)),
+		Retries:             3,
+		SleepBetweenRetries: 10 * time.Millisecond,
+	})
+
+	assert.Equal(t, "bytes="+strconv.Itoa(len(testDownloadContent)/2)+"-", rangeHeader.Load())
+	content, err := os.ReadFile(destPath)
+	require.NoError(t, err)
+	assert.Equal(t, testDownloadContent, content)
+}
+
+func TestDownloadFileRestartsWhenTheFileChanged(t *testing.T) {
+	t.Parallel()
+	changedContent := bytes.Repeat([]byte("changed download "), 1000)
+	var counter int32
+	var ifRangeHeader atomic.Value
+	ts := getTestServerForFunction(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&counter, 1) == 1 {
+			// Drop the connection in the middle of the body of the first version of the file
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Content-Length", strconv.Itoa(len(testDownloadContent)))
+			w.Write(testDownloadContent[:len(testDownloadContent)/2])
+			return
+		}
+		ifRangeHeader.Store(r.Header.Get("If-Range"))
+		w.Header().Set("ETag", `"v2"`)
+		http.ServeContent(w, r, "fixture", time.Time{}, bytes.NewReader(changedContent))
+	})
+	defer ts.Close()
+
+	destPath := filepath.Join(t.TempDir(), "fixture.bin")
+	DownloadFileWithOptions(t, DownloadFileOptions{Url: ts.URL, DestPath: destPath, Retries: 3, SleepBetweenRetries: 10 * time.Millisecond})
+
+	assert.Equal(t, `"v1"`, ifRangeHeader.Load())
+	content, err := os.ReadFile(destPath)
+	require.NoError(t, err)
+	assert.Equal(t, changedContent, content)
+}
+
+func TestDownloadFileDiscardsLeftoverPartFile(t *testing.T) {
+	t.Parallel()
+	var rangeHeader atomic.Value
+	ts := getTestServerForFunction(func(w http.ResponseWriter, r *http.Request) {
+		rangeHeader.Store(r.Header.Get("Range"))
+		http.ServeContent(w, r, "fixture", time.Time{}, bytes.NewReader(testDownloadContent))
+	})
+	defer ts.Close()
+
+	destPath := filepath.Join(t.TempDir(), "fixture.bin")
+	require.NoError(t, os.WriteFile(destPath+".part", []byte("an older version of the file"), 0644))
+	DownloadFile(t, ts.URL, destPath, testDownloadSha256())
+
+	assert.Equal(t, "", rangeHeader.Load())
+	content, err := os.ReadFile(destPath)
+	require.NoError(t, err)
+	assert.Equal(t, testDownloadContent, content)
+}
+
+func TestDownloadFileEChecksumMismatch(t *testing.T) {
+	t.Parallel()
+	ts := getTestServerForFunction(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("unexpected content"))
+	})
+	defer ts.Close()
+
+	destPath := filepath.Join(t.TempDir(), "fixture.bin")
+	err := DownloadFileWithOptionsE(t, DownloadFileOptions{Url: ts.URL, DestPath: destPath, ExpectedSha256: testDownloadSha256(), Retries: 1})
+	assert.Error(t, err)
+	assert.NoFileExists(t, destPath)
+	assert.NoFileExists(t, destPath+".part")
+}
+
+func TestDownloadFileENotFound(t *testing.T) {
+	t.Parallel()
+	var counter int32
+	ts := getTestServerForFunction(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&counter, 1)
+		http.NotFound(w, r)
+	})
+	defer ts.Close()
+
+	err := DownloadFileWithOptionsE(t, DownloadFileOptions{Url: ts.URL, DestPath: filepath.Join(t.TempDir(), "fixture.bin"), Retries: 3})
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&counter))
+}
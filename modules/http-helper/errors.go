@@ -12,3 +12,14 @@ type ValidationFunctionFailed struct {
 func (err ValidationFunctionFailed) Error() string {
 	return fmt.Sprintf("Validation failed for URL %s. Response status: %d. Response body:\n%s", err.Url, err.Status, err.Body)
 }
+
+// UnexpectedProtocolError is an error that occurs if a server responds with another HTTP protocol than the one forced.
+type UnexpectedProtocolError struct {
+	Url      string
+	Expected HttpProtocol
+	Actual   string
+}
+
+func (err UnexpectedProtocolError) Error() string {
+	return fmt.Sprintf("Expected protocol %s for URL %s, but the response is %s", err.Expected, err.Url, err.Actual)
+}
@@ -16,18 +16,24 @@ import (
 )
 
 type HttpGetOptions struct {
-	Url       string
-	TlsConfig *tls.Config
-	Timeout   int
+	Url        string
+	TlsConfig  *tls.Config
+	TlsOptions *TlsOptions // used to build the TLS configuration, if TlsConfig is not set
+	Timeout    int
+	ProxyUrl   string       // the URL of an http, https or socks5 proxy, instead of the proxy of the environment
+	Protocol   HttpProtocol // the HTTP protocol to force, e.g. HttpProtocolHttp2 (negotiated by default)
 }
 
 type HttpDoOptions struct {
-	Method    string
-	Url       string
-	Body      io.Reader
-	Headers   map[string]string
-	TlsConfig *tls.Config
-	Timeout   int
+	Method     string
+	Url        string
+	Body       io.Reader
+	Headers    map[string]string
+	TlsConfig  *tls.Config
+	TlsOptions *TlsOptions // used to build the TLS configuration, if TlsConfig is not set
+	Timeout    int
+	ProxyUrl   string       // the URL of an http, https or socks5 proxy, instead of the proxy of the environment
+	Protocol   HttpProtocol // the HTTP protocol to force, e.g. HttpProtocolHttp2 (negotiated by default)
 }
 
 // HttpGet performs an HTTP GET, with an optional pointer to a custom TLS configuration, on the given URL and
@@ -57,9 +63,16 @@ func HttpGetE(t testing.TestingT, url string, tlsConfig *tls.Config) (int, strin
 func HttpGetWithOptionsE(t testing.TestingT, options HttpGetOptions) (int, string, error) {
 	logger.Default.Logf(t, "Making an HTTP GET call to URL %s", options.Url)
 
+	tlsConfig, err := getTlsConfigE(options.TlsConfig, options.TlsOptions)
+	if err != nil {
+		return -1, "", err
+	}
+
 	// Set HTTP client transport config
-	tr := http.DefaultTransport.(*http.Transport).Clone()
-	tr.TLSClientConfig = options.TlsConfig
+	tr, err := newHttpTransportE(tlsConfig, options.ProxyUrl, options.Protocol)
+	if err != nil {
+		return -1, "", err
+	}
 
 	client := http.Client{
 		// By default, Go does not impose a timeout, so an HTTP connection attempt can hang for a LONG time.
@@ -262,8 +275,15 @@ func HTTPDoWithOptionsE(
 ) (int, string, error) {
 	logger.Default.Logf(t, "Making an HTTP %s call to URL %s", options.Method, options.Url)
 
-	tr := http.DefaultTransport.(*http.Transport).Clone()
-	tr.TLSClientConfig = options.TlsConfig
+	tlsConfig, err := getTlsConfigE(options.TlsConfig, options.TlsOptions)
+	if err != nil {
+		return -1, "", err
+	}
+
+	tr, err := newHttpTransportE(tlsConfig, options.ProxyUrl, options.Protocol)
+	if err != nil {
+		return -1, "", err
+	}
 
 	client := http.Client{
 		// By default, Go does not impose a timeout, so an HTTP connection attempt can hang for a LONG time.
@@ -0,0 +1,150 @@
+package http_helper
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// HttpGetUntilJson repeatedly performs an HTTP GET on the given URL until the body parses as JSON and the given
+// predicate returns true for it, or until max retries has been exceeded, and returns the body. If the predicate never
+// matches, fail the test.
+func HttpGetUntilJson(t testing.TestingT, url string, tlsConfig *tls.Config, predicate func(body interface{}) bool, retries int, sleepBetweenRetries time.Duration) string {
+	body, err := HttpGetUntilJsonE(t, url, tlsConfig, predicate, retries, sleepBetweenRetries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+// HttpGetUntilJsonE repeatedly performs an HTTP GET on the given URL until the body parses as JSON and the given
+// predicate returns true for it, or until max retries has been exceeded, and returns the body. The predicate gets the
+// body as decoded by encoding/json into an interface{}, e.g. a map[string]interface{} for a JSON object.
+func HttpGetUntilJsonE(t testing.TestingT, url string, tlsConfig *tls.Config, predicate func(body interface{}) bool, retries int, sleepBetweenRetries time.Duration) (string, error) {
+	options := HttpGetOptions{Url: url, TlsConfig: tlsConfig, Timeout: 10}
+	return HttpGetUntilJsonWithOptionsE(t, options, predicate, retries, sleepBetweenRetries)
+}
+
+// HttpGetUntilJsonWithOptions repeatedly performs an HTTP GET on the given URL until the body parses as JSON and the
+// given predicate returns true for it, or until max retries has been exceeded, and returns the body. If the predicate
+// never matches, fail the test.
+func HttpGetUntilJsonWithOptions(t testing.TestingT, options HttpGetOptions, predicate func(body interface{}) bool, retries int, sleepBetweenRetries time.Duration) string {
+	body, err := HttpGetUntilJsonWithOptionsE(t, options, predicate, retries, sleepBetweenRetries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+// HttpGetUntilJsonWithOptionsE repeatedly performs an HTTP GET on the given URL until the body parses as JSON and the
+// given predicate returns true for it, or until max retries has been exceeded, and returns the body. The predicate
+// gets the body as decoded by encoding/json into an interface{}, e.g. a map[string]interface{} for a JSON object.
+func HttpGetUntilJsonWithOptionsE(t testing.TestingT, options HttpGetOptions, predicate func(body interface{}) bool, retries int, sleepBetweenRetries time.Duration) (string, error) {
+	return retry.DoWithRetryE(t, fmt.Sprintf("HTTP GET to URL %s", options.Url), retries, sleepBetweenRetries, func() (string, error) {
+		statusCode, body, err := HttpGetWithOptionsE(t, options)
+		if err != nil {
+			return "", err
+		}
+
+		var parsed interface{}
+		if err := json.Unmarshal([]byte(body), &parsed); err != nil {
+			return "", fmt.Errorf("body of URL %s is not valid JSON: %w. Response status: %d. Response body:\n%s", options.Url, err, statusCode, body)
+		}
+		if !predicate(parsed) {
+			return "", ValidationFunctionFailed{Url: options.Url, Status: statusCode, Body: body}
+		}
+		return body, nil
+	})
+}
+
+// HttpGetUntilJsonPath repeatedly performs an HTTP GET on the given URL until the result of the given JSONPath
+// expression on the JSON body equals the expected value, or until max retries has been exceeded, and returns the body.
+// If the result never matches, fail the test.
+func HttpGetUntilJsonPath(t testing.TestingT, url string, tlsConfig *tls.Config, jsonPath string, expected interface{}, retries int, sleepBetweenRetries time.Duration) string {
+	body, err := HttpGetUntilJsonPathE(t, url, tlsConfig, jsonPath, expected, retries, sleepBetweenRetries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+// HttpGetUntilJsonPathE repeatedly performs an HTTP GET on the given URL until the result of the given JSONPath
+// expression on the JSON body equals the expected value, or until max retries has been exceeded, and returns the body.
+// The JSONPath expression uses the kubectl syntax (e.g. {.status.ready} or {.items[*].name}). A single result is
+// compared to the expected value, and multiple results are compared as a list. Numbers compare equal whatever their Go
+// type.
+func HttpGetUntilJsonPathE(t testing.TestingT, url string, tlsConfig *tls.Config, jsonPath string, expected interface{}, retries int, sleepBetweenRetries time.Duration) (string, error) {
+	options := HttpGetOptions{Url: url, TlsConfig: tlsConfig, Timeout: 10}
+	return HttpGetUntilJsonPathWithOptionsE(t, options, jsonPath, expected, retries, sleepBetweenRetries)
+}
+
+// HttpGetUntilJsonPathWithOptions repeatedly performs an HTTP GET on the given URL until the result of the given
+// JSONPath expression on the JSON body equals the expected value, or until max retries has been exceeded, and returns
+// the body. If the result never matches, fail the test.
+func HttpGetUntilJsonPathWithOptions(t testing.TestingT, options HttpGetOptions, jsonPath string, expected interface{}, retries int, sleepBetweenRetries time.Duration) string {
+	body, err := HttpGetUntilJsonPathWithOptionsE(t, options, jsonPath, expected, retries, sleepBetweenRetries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+// HttpGetUntilJsonPathWithOptionsE repeatedly performs an HTTP GET on the given URL until the result of the given
+// JSONPath expression on the JSON body equals the expected value, or until max retries has been exceeded, and returns
+// the body. See HttpGetUntilJsonPathE for the comparison.
+func HttpGetUntilJsonPathWithOptionsE(t testing.TestingT, options HttpGetOptions, jsonPath string, expected interface{}, retries int, sleepBetweenRetries time.Duration) (string, error) {
+	parser := jsonpath.New("HttpGetUntilJsonPath")
+	parser.EnableJSONOutput(true)
+	if err := parser.Parse(jsonPath); err != nil {
+		return "", fmt.Errorf("invalid JSONPath expression %s: %w", jsonPath, err)
+	}
+
+	normalizedExpected, err := normalizeJson(expected)
+	if err != nil {
+		return "", err
+	}
+
+	return HttpGetUntilJsonWithOptionsE(t, options, func(body interface{}) bool {
+		actual, err := evaluateJsonPath(parser, body)
+		return err == nil && reflect.DeepEqual(actual, normalizedExpected)
+	}, retries, sleepBetweenRetries)
+}
+
+// evaluateJsonPath returns the result of the given JSONPath expression on the given JSON value: the only result, or
+// the list of results if there are none or many.
+func evaluateJsonPath(parser *jsonpath.JSONPath, value interface{}) (interface{}, error) {
+	var out bytes.Buffer
+	if err := parser.Execute(&out, value); err != nil {
+		return nil, err
+	}
+
+	var results []interface{}
+	if err := json.Unmarshal(out.Bytes(), &results); err != nil {
+		return nil, err
+	}
+	if len(results) == 1 {
+		return results[0], nil
+	}
+	return results, nil
+}
+
+// normalizeJson returns the given value as decoded by encoding/json after encoding it, so it compares equal to the
+// values of parsed JSON bodies (e.g. all numbers are float64).
+func normalizeJson(value interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(encoded, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
@@ -0,0 +1,78 @@
+package http_helper
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// getJsonTestServer returns a server whose deployment is ready from the third request.
+func getJsonTestServer(t *testing.T) (string, func()) {
+	var counter int32
+	ts := getTestServerForFunction(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&counter, 1) {
+		case 1:
+			fmt.Fprint(w, "<html>starting</html>")
+		case 2:
+			fmt.Fprint(w, `{"status": {"ready": false, "replicas": 1}, "items": [{"name": "a"}]}`)
+		default:
+			fmt.Fprint(w, `{"status": {"ready": true, "replicas": 3}, "items": [{"name": "a"}, {"name": "b"}]}`)
+		}
+	})
+	return ts.URL, ts.Close
+}
+
+func TestHttpGetUntilJsonPath(t *testing.T) {
+	t.Parallel()
+
+	for _, testCase := range []struct {
+		name     string
+		jsonPath string
+		expected interface{}
+	}{
+		{"bool", "{.status.ready}", true},
+		{"number", "{.status.replicas}", 3},
+		{"list", "{.items[*].name}", []string{"a", "b"}},
+	} {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			url, closeServer := getJsonTestServer(t)
+			defer closeServer()
+
+			body := HttpGetUntilJsonPath(t, url, nil, testCase.jsonPath, testCase.expected, 5, 10*time.Millisecond)
+			assert.Contains(t, body, `"ready": true`)
+		})
+	}
+}
+
+func TestHttpGetUntilJson(t *testing.T) {
+	t.Parallel()
+	url, closeServer := getJsonTestServer(t)
+	defer closeServer()
+
+	HttpGetUntilJson(t, url, nil, func(body interface{}) bool {
+		status := body.(map[string]interface{})["status"].(map[string]interface{})
+		return status["replicas"] == float64(3)
+	}, 5, 10*time.Millisecond)
+}
+
+func TestHttpGetUntilJsonPathEDoesNotMatch(t *testing.T) {
+	t.Parallel()
+	url, closeServer := getJsonTestServer(t)
+	defer closeServer()
+
+	_, err := HttpGetUntilJsonPathE(t, url, nil, "{.status.replicas}", 5, 3, 10*time.Millisecond)
+	assert.Error(t, err)
+}
+
+func TestHttpGetUntilJsonPathEInvalidJsonPath(t *testing.T) {
+	t.Parallel()
+
+	_, err := HttpGetUntilJsonPathE(t, "http://localhost", nil, "{.status", true, 3, 10*time.Millisecond)
+	assert.ErrorContains(t, err, "invalid JSONPath expression {.status")
+}
@@ -0,0 +1,265 @@
+package http_helper
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// LoadTestOptions are the options of HttpLoadTest. The load test stops after Requests requests or after Duration,
+// whichever comes first, so at least one of them must be set.
+type LoadTestOptions struct {
+	Method      string // GET if unset
+	Url         string
+	Body        []byte
+	Headers     map[string]string
+	TlsConfig   *tls.Config
+	TlsOptions  *TlsOptions // used to build the TLS configuration, if TlsConfig is not set
+	ProxyUrl    string
+	Protocol    HttpProtocol
+	Timeout     int // the timeout of each request, in seconds (10 if unset)
+	Concurrency int // the number of requests in flight at once (1 if unset)
+	Requests    int // the total number of requests
+	Duration    time.Duration
+}
+
+// LoadTestResult is the result of a load test run by HttpLoadTest.
+type LoadTestResult struct {
+	Url            string
+	Requests       int           // the number of requests performed
+	FailedRequests int           // the number of requests that got no response, or a status code of 400 or more
+	Duration       time.Duration // how long the load test took
+	StatusCodes    map[int]int   // the number of responses by status code
+	Latencies      []time.Duration
+	LastError      error // the last error of a request that got no response, if any
+}
+
+// HttpLoadTest performs requests on the given URL from many goroutines at once, and returns the latencies, status
+// codes and errors of the responses. If the options are invalid, fail the test. Use the assertions of the result to
+// gate on the latency or the error rate, e.g.:
+//
+//	result := http_helper.HttpLoadTest(t, http_helper.LoadTestOptions{Url: url, Concurrency: 10, Duration: 30 * time.Second})
+//	result.AssertP99Below(t, 500*time.Millisecond)
+//	result.AssertErrorRateBelow(t, 0.01)
+func HttpLoadTest(t testing.TestingT, options LoadTestOptions) *LoadTestResult {
+	result, err := HttpLoadTestE(t, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+// HttpLoadTestE performs requests on the given URL from many goroutines at once, and returns the latencies, status
+// codes and errors of the responses. Failing requests don't stop the load test: they are counted in the result. This
+// only returns an error if the options are invalid.
+func HttpLoadTestE(t testing.TestingT, options LoadTestOptions) (*LoadTestResult, error) {
+	if options.Requests <= 0 && options.Duration <= 0 {
+		return nil, errors.New("the number of requests or the duration of the load test must be set")
+	}
+
+	method := options.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = 10
+	}
+
+	tlsConfig, err := getTlsConfigE(options.TlsConfig, options.TlsOptions)
+	if err != nil {
+		return nil, err
+	}
+	tr, err := newHttpTransportE(tlsConfig, options.ProxyUrl, options.Protocol)
+	if err != nil {
+		return nil, err
+	}
+	// Keep a connection per goroutine open, rather than reconnecting for most requests
+	if httpTransport, ok := unwrapHttpTransport(tr); ok {
+		httpTransport.MaxIdleConnsPerHost = concurrency
+	}
+	client := &http.Client{Timeout: time.Duration(timeout) * time.Second, Transport: tr}
+
+	logger.Default.Logf(t, "Running a load test of HTTP %s calls to URL %s with %d concurrent requests", method, options.Url, concurrency)
+
+	result := &LoadTestResult{Url: options.Url, StatusCodes: map[int]int{}}
+	var mutex sync.Mutex
+	var deadline time.Time
+	start := time.Now()
+	if options.Duration > 0 {
+		deadline = start.Add(options.Duration)
+	}
+
+	// nextRequest reserves the next request, if the load test isn't over yet
+	nextRequest := func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if options.Requests > 0 && result.Requests >= options.Requests {
+			return false
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return false
+		}
+		result.Requests++
+		return true
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for nextRequest() {
+				statusCode, latency, err := performLoadTestRequest(client, method, options)
+
+				mutex.Lock()
+				if err != nil {
+					result.FailedRequests++
+					result.LastError = err
+				} else {
+					result.StatusCodes[statusCode]++
+					result.Latencies = append(result.Latencies, latency)
+					if statusCode >= 400 {
+						result.FailedRequests++
+					}
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	result.Duration = time.Since(start)
+	sort.Slice(result.Latencies, func(i, j int) bool { return result.Latencies[i] < result.Latencies[j] })
+
+	logger.Default.Logf(t, "Load test of URL %s: %d requests in %s (%.1f/s), error rate %.2f%%, p50 %s, p90 %s, p99 %s, status codes %v",
+		options.Url, result.Requests, result.Duration, result.RequestsPerSecond(), 100*result.ErrorRate(),
+		result.Percentile(50), result.Percentile(90), result.Percentile(99), result.StatusCodes)
+	return result, nil
+}
+
+// performLoadTestRequest performs one request of a load test and returns its status code and latency, which includes
+// reading the body.
+func performLoadTestRequest(client *http.Client, method string, options LoadTestOptions) (int, time.Duration, error) {
+	var body io.Reader
+	if options.Body != nil {
+		body = bytes.NewReader(options.Body)
+	}
+
+	req, err := http.NewRequest(method, options.Url, body)
+	if err != nil {
+		return 0, 0, err
+	}
+	for key, value := range options.Headers {
+		req.Header.Set(key, value)
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return 0, 0, err
+	}
+	return resp.StatusCode, time.Since(start), nil
+}
+
+// unwrapHttpTransport returns the net/http transport that the given transport performs requests with, if any.
+func unwrapHttpTransport(tr http.RoundTripper) (*http.Transport, bool) {
+	if checking, ok := tr.(*protocolCheckingTransport); ok {
+		tr = checking.transport
+	}
+	httpTransport, ok := tr.(*http.Transport)
+	return httpTransport, ok
+}
+
+// RequestsPerSecond returns the number of requests performed per second.
+func (result *LoadTestResult) RequestsPerSecond() float64 {
+	if result.Duration <= 0 {
+		return 0
+	}
+	return float64(result.Requests) / result.Duration.Seconds()
+}
+
+// ErrorRate returns the ratio, between 0 and 1, of the requests that failed.
+func (result *LoadTestResult) ErrorRate() float64 {
+	if result.Requests == 0 {
+		return 0
+	}
+	return float64(result.FailedRequests) / float64(result.Requests)
+}
+
+// Percentile returns the given percentile (e.g. 99), between 0 and 100, of the latencies of the responses, or zero if
+// there are none.
+func (result *LoadTestResult) Percentile(percentile float64) time.Duration {
+	if len(result.Latencies) == 0 {
+		return 0
+	}
+	// The nearest-rank method: the smallest latency that at least percentile % of the latencies are below or equal to
+	rank := int(math.Ceil(percentile / 100 * float64(len(result.Latencies))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(result.Latencies) {
+		rank = len(result.Latencies)
+	}
+	return result.Latencies[rank-1]
+}
+
+// AssertP99Below fails the test if the 99th percentile of the latencies is above the given latency.
+func (result *LoadTestResult) AssertP99Below(t testing.TestingT, maxLatency time.Duration) {
+	result.AssertPercentileBelow(t, 99, maxLatency)
+}
+
+// AssertPercentileBelow fails the test if the given percentile of the latencies is above the given latency.
+func (result *LoadTestResult) AssertPercentileBelow(t testing.TestingT, percentile float64, maxLatency time.Duration) {
+	if err := result.PercentileBelowE(percentile, maxLatency); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// PercentileBelowE returns an error if the given percentile of the latencies is above the given latency, or if there
+// are no responses. A percentile equal to the given latency passes.
+func (result *LoadTestResult) PercentileBelowE(percentile float64, maxLatency time.Duration) error {
+	if len(result.Latencies) == 0 {
+		return fmt.Errorf("no responses from URL %s to compute the latency percentiles with: %v", result.Url, result.LastError)
+	}
+	if latency := result.Percentile(percentile); latency > maxLatency {
+		return fmt.Errorf("p%g latency of URL %s is %s, above %s", percentile, result.Url, latency, maxLatency)
+	}
+	return nil
+}
+
+// AssertErrorRateBelow fails the test if the ratio, between 0 and 1, of the requests that failed is above the given
+// ratio.
+func (result *LoadTestResult) AssertErrorRateBelow(t testing.TestingT, maxErrorRate float64) {
+	if err := result.ErrorRateBelowE(maxErrorRate); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// ErrorRateBelowE returns an error if the ratio, between 0 and 1, of the requests that failed is above the given ratio.
+// An error rate equal to the given ratio passes, so a ratio of 0 passes when no request failed.
+func (result *LoadTestResult) ErrorRateBelowE(maxErrorRate float64) error {
+	if errorRate := result.ErrorRate(); errorRate > maxErrorRate {
+		return fmt.Errorf("error rate of URL %s is %.2f%% (%d of %d requests, status codes %v, last error: %v), above %.2f%%",
+			result.Url, 100*errorRate, result.FailedRequests, result.Requests, result.StatusCodes, result.LastError, 100*maxErrorRate)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package http_helper
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpLoadTestRequests(t *testing.T) {
+	t.Parallel()
+	var counter int32
+	ts := getTestServerForFunction(func(w http.ResponseWriter, r *http.Request) {
+		// One request in ten fails
+		if atomic.AddInt32(&counter, 1)%10 == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+	defer ts.Close()
+
+	result := HttpLoadTest(t, LoadTestOptions{Url: ts.URL, Concurrency: 5, Requests: 100})
+	assert.Equal(t, 100, result.Requests)
+	assert.Equal(t, 10, result.FailedRequests)
+	assert.Equal(t, map[int]int{http.StatusOK: 90, http.StatusServiceUnavailable: 10}, result.StatusCodes)
+	assert.Len(t, result.Latencies, 100)
+	assert.InDelta(t, 0.1, result.ErrorRate(), 0.0001)
+
+	result.AssertP99Below(t, 5*time.Second)
+	assert.Error(t, result.PercentileBelowE(50, 0))
+	result.AssertErrorRateBelow(t, 0.2)
+	assert.NoError(t, result.ErrorRateBelowE(0.1))
+	assert.Error(t, result.ErrorRateBelowE(0.05))
+}
+
+func TestHttpLoadTestDuration(t *testing.T) {
+	t.Parallel()
+	ts := getTestServerForFunction(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+	})
+	defer ts.Close()
+
+	result := HttpLoadTest(t, LoadTestOptions{Url: ts.URL, Concurrency: 2, Duration: 200 * time.Millisecond})
+	assert.Greater(t, result.Requests, 2)
+	assert.Zero(t, result.FailedRequests)
+	assert.GreaterOrEqual(t, result.Percentile(50), 10*time.Millisecond)
+	assert.NoError(t, result.ErrorRateBelowE(0))
+	assert.NoError(t, result.PercentileBelowE(100, result.Percentile(100)))
+}
+
+func TestHttpLoadTestEConnectionErrors(t *testing.T) {
+	t.Parallel()
+	ts := getTestServerForFunction(bodyCopyHandler)
+	url := ts.URL
+	ts.Close()
+
+	result, err := HttpLoadTestE(t, LoadTestOptions{Url: url, Requests: 3})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.FailedRequests)
+	assert.Error(t, result.LastError)
+	assert.Error(t, result.PercentileBelowE(99, time.Second))
+}
+
+func TestHttpLoadTestEWithoutLimit(t *testing.T) {
+	t.Parallel()
+
+	_, err := HttpLoadTestE(t, LoadTestOptions{Url: "http://localhost"})
+	assert.Error(t, err)
+}
+
+func TestLoadTestResultPercentile(t *testing.T) {
+	t.Parallel()
+	result := &LoadTestResult{}
+	for i := 1; i <= 100; i++ {
+		result.Latencies = append(result.Latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	assert.Equal(t, 1*time.Millisecond, result.Percentile(0))
+	assert.Equal(t, 50*time.Millisecond, result.Percentile(50))
+	assert.Equal(t, 99*time.Millisecond, result.Percentile(99))
+	assert.Equal(t, 100*time.Millisecond, result.Percentile(100))
+}
@@ -0,0 +1,176 @@
+package http_helper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// OpenApiSpec is an OpenAPI 3 specification to validate the responses of an API against, loaded with LoadOpenApiSpec.
+type OpenApiSpec struct {
+	router routers.Router
+}
+
+// LoadOpenApiSpec loads the OpenAPI 3 specification, in JSON or YAML, at the given path or http(s) URL. If there's any
+// error, or if the specification is invalid, fail the test.
+func LoadOpenApiSpec(t testing.TestingT, pathOrUrl string) *OpenApiSpec {
+	spec, err := LoadOpenApiSpecE(t, pathOrUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return spec
+}
+
+// LoadOpenApiSpecE loads the OpenAPI 3 specification, in JSON or YAML, at the given path or http(s) URL, and returns
+// any error loading it or validating it. Operations are matched on the path of the responses only, relative to the
+// base paths of the servers of the specification, so the responses of a deployment at any host can be validated.
+func LoadOpenApiSpecE(t testing.TestingT, pathOrUrl string) (*OpenApiSpec, error) {
+	logger.Default.Logf(t, "Loading the OpenAPI specification %s", pathOrUrl)
+
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+
+	var doc *openapi3.T
+	var err error
+	if strings.HasPrefix(pathOrUrl, "http://") || strings.HasPrefix(pathOrUrl, "https://") {
+		var location *url.URL
+		location, err = url.Parse(pathOrUrl)
+		if err != nil {
+			return nil, err
+		}
+		doc, err = loader.LoadFromURI(location)
+	} else {
+		doc, err = loader.LoadFromFile(pathOrUrl)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error loading the OpenAPI specification %s: %w", pathOrUrl, err)
+	}
+	if err := doc.Validate(loader.Context); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI specification %s: %w", pathOrUrl, err)
+	}
+
+	doc.Servers = serverBasePaths(doc.Servers)
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &OpenApiSpec{router: router}, nil
+}
+
+// ValidateResponse validates the given response to the request with the given method on the given URL against the
+// specification. If the response doesn't conform to it, fail the test.
+func (spec *OpenApiSpec) ValidateResponse(t testing.TestingT, method string, url string, response *HttpResponse) {
+	if err := spec.ValidateResponseE(method, url, response); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// ValidateResponseE validates the given response to the request with the given method on the given URL against the
+// specification, and returns an error if the operation is not in the specification, or if the status code, content
+// type, headers or body of the response don't conform to it. Status codes that the operation doesn't document are
+// errors, unless it has a default response.
+func (spec *OpenApiSpec) ValidateResponseE(method string, url string, response *HttpResponse) error {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return err
+	}
+
+	// Match the operation on the path only, like the servers of the specification
+	routeReq := req.Clone(context.Background())
+	routeReq.URL.Scheme = ""
+	routeReq.URL.Host = ""
+	route, pathParams, err := spec.router.FindRoute(routeReq)
+	if err != nil {
+		return fmt.Errorf("no operation for %s %s in the OpenAPI specification: %w", method, req.URL.Path, err)
+	}
+
+	input := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: pathParams,
+			Route:      route,
+		},
+		Status: response.StatusCode,
+		Header: response.Header,
+		Body:   io.NopCloser(bytes.NewReader([]byte(response.Body))),
+		Options: &openapi3filter.Options{
+			IncludeResponseStatus: true,
+			MultiError:            true,
+		},
+	}
+	if err := openapi3filter.ValidateResponse(context.Background(), input); err != nil {
+		return fmt.Errorf("response %d to %s %s does not conform to the OpenAPI specification: %w", response.StatusCode, method, url, err)
+	}
+	return nil
+}
+
+// DoWithOpenApiValidation performs the request and validates its response against the given OpenAPI specification.
+// If there's any error, or if the response doesn't conform to the specification, fail the test.
+func (request *HttpRequest) DoWithOpenApiValidation(t testing.TestingT, spec *OpenApiSpec) *HttpResponse {
+	response, err := request.DoWithOpenApiValidationE(t, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return response
+}
+
+// DoWithOpenApiValidationE performs the request and validates its response against the given OpenAPI specification,
+// and returns the response, and any error performing the request or validating the response.
+func (request *HttpRequest) DoWithOpenApiValidationE(t testing.TestingT, spec *OpenApiSpec) (*HttpResponse, error) {
+	response, err := request.DoE(t)
+	if err != nil {
+		return nil, err
+	}
+	return response, spec.ValidateResponseE(request.method, request.url, response)
+}
+
+// serverBasePaths returns the given servers with only the paths of their URLs (e.g. /v1 for
+// https://{region}.example.com/v1), or no servers if none has a path.
+func serverBasePaths(servers openapi3.Servers) openapi3.Servers {
+	basePaths := openapi3.Servers{}
+	seen := map[string]bool{}
+	for _, server := range servers {
+		basePath := server.URL
+		if i := strings.Index(basePath, "://"); i >= 0 {
+			basePath = basePath[i+len("://"):]
+			if j := strings.IndexByte(basePath, '/'); j >= 0 {
+				basePath = basePath[j:]
+			} else {
+				basePath = ""
+			}
+		}
+		if basePath = strings.TrimSuffix(basePath, "/"); basePath == "" {
+			basePath = "/"
+		}
+		if seen[basePath] {
+			continue
+		}
+		seen[basePath] = true
+		// Keep the variables of the path only, as the router rejects unused variables
+		basePathServer := &openapi3.Server{URL: basePath}
+		for name, variable := range server.Variables {
+			if strings.Contains(basePath, "{"+name+"}") {
+				if basePathServer.Variables == nil {
+					basePathServer.Variables = map[string]*openapi3.ServerVariable{}
+				}
+				basePathServer.Variables[name] = variable
+			}
+		}
+		basePaths = append(basePaths, basePathServer)
+	}
+	if len(basePaths) == 0 || (len(basePaths) == 1 && basePaths[0].URL == "/") {
+		return nil
+	}
+	return basePaths
+}
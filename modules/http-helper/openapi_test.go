@@ -0,0 +1,62 @@
+package http_helper
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// petsHandler responds like the pets API of testdata/openapi.yaml, with a body that breaks the contract for pet 2.
+func petsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/v1/pets/1":
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id": 1, "name": "Rex"}`)
+	case "/v1/pets/2":
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id": "two"}`)
+	case "/v1/pets/3":
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, "Rex")
+	case "/v1/pets/4":
+		w.WriteHeader(http.StatusInternalServerError)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestOpenApiSpecValidateResponse(t *testing.T) {
+	t.Parallel()
+	ts := getTestServerForFunction(petsHandler)
+	defer ts.Close()
+	spec := LoadOpenApiSpec(t, "testdata/openapi.yaml")
+
+	NewHttpRequest("GET", ts.URL+"/v1/pets/1").DoWithOpenApiValidation(t, spec)
+	NewHttpRequest("GET", ts.URL+"/v1/pets/5").DoWithOpenApiValidation(t, spec)
+
+	for _, testCase := range []struct {
+		name          string
+		path          string
+		expectedError string
+	}{
+		{"wrong schema", "/v1/pets/2", "does not conform"},
+		{"wrong content type", "/v1/pets/3", "does not conform"},
+		{"undocumented status", "/v1/pets/4", "does not conform"},
+		{"unknown operation", "/v1/owners/1", "no operation for GET /v1/owners/1"},
+	} {
+		response, err := NewHttpRequest("GET", ts.URL+testCase.path).DoWithOpenApiValidationE(t, spec)
+		require.Error(t, err, testCase.name)
+		assert.Contains(t, err.Error(), testCase.expectedError, testCase.name)
+		assert.NotNil(t, response, testCase.name)
+	}
+}
+
+func TestLoadOpenApiSpecEMissingFile(t *testing.T) {
+	t.Parallel()
+
+	_, err := LoadOpenApiSpecE(t, "testdata/missing.yaml")
+	assert.Error(t, err)
+}
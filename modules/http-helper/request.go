@@ -0,0 +1,316 @@
+package http_helper
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+const (
+	// defaultRequestTimeout is the timeout of an HttpRequest, unless set with WithTimeout.
+	defaultRequestTimeout = 10 * time.Second
+
+	// defaultMaxRedirects is the number of redirects an HttpRequest follows, unless set with WithMaxRedirects.
+	defaultMaxRedirects = 10
+)
+
+// HttpRequest is a builder for an HTTP request with any method, headers, cookies, authentication, redirect policy,
+// timeout, proxy and protocol. Create one with NewHttpRequest, configure it with the With methods, and perform it with
+// Do or one of its retry variants. For example:
+//
+//	response := http_helper.NewHttpRequest("PUT", url).
+//		WithBearerToken(token).
+//		WithJsonBody(map[string]string{"name": "test"}).
+//		DoWithRetry(t, 200, 10, 3*time.Second)
+type HttpRequest struct {
+	method          string
+	url             string
+	body            []byte
+	headers         http.Header
+	cookies         []*http.Cookie
+	username        string
+	password        string
+	bearerToken     string
+	followRedirects bool
+	maxRedirects    int
+	timeout         time.Duration
+	tlsConfig       *tls.Config
+	proxyUrl        string
+	protocol        HttpProtocol
+	// Error building the request, e.g. encoding the JSON body, returned when the request is performed
+	err error
+}
+
+// HttpResponse is the response to an HttpRequest.
+type HttpResponse struct {
+	StatusCode int
+	Proto      string // the protocol of the response, e.g. HTTP/2.0
+	Header     http.Header
+	Cookies    []*http.Cookie
+	Body       string // the body, with leading and trailing white space removed
+}
+
+// NewHttpRequest returns a request with the given method on the given URL. By default, the request follows up to 10
+// redirects and times out after 10 seconds.
+func NewHttpRequest(method string, url string) *HttpRequest {
+	return &HttpRequest{
+		method:          method,
+		url:             url,
+		headers:         http.Header{},
+		followRedirects: true,
+		maxRedirects:    defaultMaxRedirects,
+		timeout:         defaultRequestTimeout,
+	}
+}
+
+// WithHeader adds a header to the request. Use the Host header to override the host of the request.
+func (request *HttpRequest) WithHeader(key string, value string) *HttpRequest {
+	request.headers.Add(key, value)
+	return request
+}
+
+// WithCookie adds a cookie to the request.
+func (request *HttpRequest) WithCookie(cookie *http.Cookie) *HttpRequest {
+	request.cookies = append(request.cookies, cookie)
+	return request
+}
+
+// WithBasicAuth sets the username and password of the request for HTTP basic authentication.
+func (request *HttpRequest) WithBasicAuth(username string, password string) *HttpRequest {
+	request.username = username
+	request.password = password
+	return request
+}
+
+// WithBearerToken sets the token of the request for bearer authentication.
+func (request *HttpRequest) WithBearerToken(token string) *HttpRequest {
+	request.bearerToken = token
+	return request
+}
+
+// WithBody sets the body of the request.
+func (request *HttpRequest) WithBody(body []byte) *HttpRequest {
+	request.body = body
+	return request
+}
+
+// WithJsonBody sets the body of the request to the JSON encoding of the given value, and its Content-Type header to
+// application/json.
+func (request *HttpRequest) WithJsonBody(value interface{}) *HttpRequest {
+	body, err := json.Marshal(value)
+	if err != nil {
+		request.err = fmt.Errorf("error encoding the JSON body of the request: %w", err)
+		return request
+	}
+	request.body = body
+	request.headers.Set("Content-Type", "application/json")
+	return request
+}
+
+// WithoutRedirects makes the request return redirect responses instead of following them, e.g. to verify the redirect
+// itself.
+func (request *HttpRequest) WithoutRedirects() *HttpRequest {
+	request.followRedirects = false
+	return request
+}
+
+// WithMaxRedirects sets the maximum number of redirects the request follows before failing.
+func (request *HttpRequest) WithMaxRedirects(maxRedirects int) *HttpRequest {
+	request.followRedirects = true
+	request.maxRedirects = maxRedirects
+	return request
+}
+
+// WithTimeout sets the timeout of the request, which includes connecting, redirects and reading the body.
+func (request *HttpRequest) WithTimeout(timeout time.Duration) *HttpRequest {
+	request.timeout = timeout
+	return request
+}
+
+// WithTlsConfig sets a custom TLS configuration for the request.
+func (request *HttpRequest) WithTlsConfig(tlsConfig *tls.Config) *HttpRequest {
+	request.tlsConfig = tlsConfig
+	return request
+}
+
+// WithTlsOptions sets the TLS configuration of the request from the given options, e.g. for mutual TLS.
+func (request *HttpRequest) WithTlsOptions(options TlsOptions) *HttpRequest {
+	tlsConfig, err := NewTlsConfigE(options)
+	if err != nil {
+		request.err = err
+		return request
+	}
+	request.tlsConfig = tlsConfig
+	return request
+}
+
+// WithProxy makes the request go through the proxy at the given URL, with the http, https or socks5 scheme, instead of
+// the proxy of the environment (HTTP_PROXY, HTTPS_PROXY and NO_PROXY).
+func (request *HttpRequest) WithProxy(proxyUrl string) *HttpRequest {
+	request.proxyUrl = proxyUrl
+	return request
+}
+
+// WithProtocol forces the HTTP protocol of the request, e.g. HttpProtocolHttp2 to fail unless the server negotiates
+// HTTP/2, or HttpProtocolH2c for HTTP/2 without TLS.
+func (request *HttpRequest) WithProtocol(protocol HttpProtocol) *HttpRequest {
+	request.protocol = protocol
+	return request
+}
+
+// Do performs the request and returns the response. If there's any error, fail the test.
+func (request *HttpRequest) Do(t testing.TestingT) *HttpResponse {
+	response, err := request.DoE(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return response
+}
+
+// DoE performs the request and returns the response, and any error.
+func (request *HttpRequest) DoE(t testing.TestingT) (*HttpResponse, error) {
+	if request.err != nil {
+		return nil, request.err
+	}
+
+	logger.Default.Logf(t, "Making an HTTP %s call to URL %s", request.method, request.url)
+
+	req, err := request.newHttpRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := request.newHttpClientE()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HttpResponse{
+		StatusCode: resp.StatusCode,
+		Proto:      resp.Proto,
+		Header:     resp.Header,
+		Cookies:    resp.Cookies(),
+		Body:       strings.TrimSpace(string(body)),
+	}, nil
+}
+
+// DoWithRetry repeatedly performs the request until the given status code is returned or until max retries has been
+// exceeded, and returns the last response. If the status code never matches, fail the test.
+func (request *HttpRequest) DoWithRetry(t testing.TestingT, expectedStatus int, retries int, sleepBetweenRetries time.Duration) *HttpResponse {
+	response, err := request.DoWithRetryE(t, expectedStatus, retries, sleepBetweenRetries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return response
+}
+
+// DoWithRetryE repeatedly performs the request until the given status code is returned or until max retries has been
+// exceeded, and returns the last response, and any error.
+func (request *HttpRequest) DoWithRetryE(t testing.TestingT, expectedStatus int, retries int, sleepBetweenRetries time.Duration) (*HttpResponse, error) {
+	return request.DoWithRetryWithCustomValidationE(t, retries, sleepBetweenRetries, func(response *HttpResponse) bool {
+		return response.StatusCode == expectedStatus
+	})
+}
+
+// DoWithRetryWithCustomValidation repeatedly performs the request until the given validation function returns true or
+// until max retries has been exceeded, and returns the last response. If the validation never succeeds, fail the test.
+func (request *HttpRequest) DoWithRetryWithCustomValidation(t testing.TestingT, retries int, sleepBetweenRetries time.Duration, validateResponse func(*HttpResponse) bool) *HttpResponse {
+	response, err := request.DoWithRetryWithCustomValidationE(t, retries, sleepBetweenRetries, validateResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return response
+}
+
+// DoWithRetryWithCustomValidationE repeatedly performs the request until the given validation function returns true
+// or until max retries has been exceeded, and returns the last response, and any error.
+func (request *HttpRequest) DoWithRetryWithCustomValidationE(t testing.TestingT, retries int, sleepBetweenRetries time.Duration, validateResponse func(*HttpResponse) bool) (*HttpResponse, error) {
+	var response *HttpResponse
+	_, err := retry.DoWithRetryE(t, fmt.Sprintf("HTTP %s to URL %s", request.method, request.url), retries, sleepBetweenRetries, func() (string, error) {
+		var err error
+		response, err = request.DoE(t)
+		if err != nil {
+			return "", err
+		}
+		if !validateResponse(response) {
+			return "", ValidationFunctionFailed{Url: request.url, Status: response.StatusCode, Body: response.Body}
+		}
+		return response.Body, nil
+	})
+	return response, err
+}
+
+// newHttpRequest returns the net/http request to perform. A new one is needed for each retry, to read the body again.
+func (request *HttpRequest) newHttpRequest() (*http.Request, error) {
+	var body io.Reader
+	if request.body != nil {
+		body = bytes.NewReader(request.body)
+	}
+
+	req, err := http.NewRequest(request.method, request.url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range request.headers {
+		if key == "Host" {
+			req.Host = values[0]
+			continue
+		}
+		req.Header[key] = values
+	}
+	for _, cookie := range request.cookies {
+		req.AddCookie(cookie)
+	}
+	if request.username != "" || request.password != "" {
+		req.SetBasicAuth(request.username, request.password)
+	}
+	if request.bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+request.bearerToken)
+	}
+	return req, nil
+}
+
+// newHttpClientE returns the client to perform the request with, with its timeout, TLS configuration, proxy, protocol
+// and redirect policy.
+func (request *HttpRequest) newHttpClientE() (*http.Client, error) {
+	tr, err := newHttpTransportE(request.tlsConfig, request.proxyUrl, request.protocol)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		// By default, Go does not impose a timeout, so an HTTP connection attempt can hang for a LONG time.
+		Timeout:   request.timeout,
+		Transport: tr,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if !request.followRedirects {
+				return http.ErrUseLastResponse
+			}
+			if len(via) > request.maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", request.maxRedirects)
+			}
+			return nil
+		},
+	}, nil
+}
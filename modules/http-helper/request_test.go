@@ -0,0 +1,111 @@
+package http_helper
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpRequestWithHeadersCookiesAndAuth(t *testing.T) {
+	t.Parallel()
+	ts := getTestServerForFunction(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		username, password, _ := r.BasicAuth()
+		cookie, _ := r.Cookie("session")
+		http.SetCookie(w, &http.Cookie{Name: "seen", Value: "true"})
+		w.Header().Set("X-Method", r.Method)
+		fmt.Fprintf(w, "%s %s %s:%s %s %s", r.Header.Get("X-Request-Id"), r.Header.Get("Content-Type"), username, password, cookie.Value, body)
+	})
+	defer ts.Close()
+
+	response := NewHttpRequest("PATCH", ts.URL).
+		WithHeader("X-Request-Id", "42").
+		WithCookie(&http.Cookie{Name: "session", Value: "abc"}).
+		WithBasicAuth("admin", "secret").
+		WithJsonBody(map[string]string{"name": "test"}).
+		Do(t)
+
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, `42 application/json admin:secret abc {"name":"test"}`, response.Body)
+	assert.Equal(t, "PATCH", response.Header.Get("X-Method"))
+	require.Len(t, response.Cookies, 1)
+	assert.Equal(t, "seen", response.Cookies[0].Name)
+}
+
+func TestHttpRequestWithBearerToken(t *testing.T) {
+	t.Parallel()
+	ts := getTestServerForFunction(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Header.Get("Authorization"))
+	})
+	defer ts.Close()
+
+	response := NewHttpRequest("DELETE", ts.URL).WithBearerToken("token").Do(t)
+	assert.Equal(t, "Bearer token", response.Body)
+}
+
+func TestHttpRequestRedirectPolicy(t *testing.T) {
+	t.Parallel()
+	ts := getTestServerForFunction(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/final":
+			fmt.Fprint(w, "final")
+		case "/second":
+			http.Redirect(w, r, "/final", http.StatusFound)
+		default:
+			http.Redirect(w, r, "/second", http.StatusMovedPermanently)
+		}
+	})
+	defer ts.Close()
+
+	response := NewHttpRequest("GET", ts.URL).Do(t)
+	assert.Equal(t, "final", response.Body)
+
+	response = NewHttpRequest("GET", ts.URL).WithoutRedirects().Do(t)
+	assert.Equal(t, http.StatusMovedPermanently, response.StatusCode)
+	assert.Equal(t, "/second", response.Header.Get("Location"))
+
+	_, err := NewHttpRequest("GET", ts.URL).WithMaxRedirects(1).DoE(t)
+	assert.ErrorContains(t, err, "stopped after 1 redirects")
+}
+
+func TestHttpRequestWithTimeout(t *testing.T) {
+	t.Parallel()
+	ts := getTestServerForFunction(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Second)
+	})
+	defer ts.Close()
+
+	_, err := NewHttpRequest("GET", ts.URL).WithTimeout(100 * time.Millisecond).DoE(t)
+	assert.Error(t, err)
+}
+
+func TestHttpRequestDoWithRetry(t *testing.T) {
+	t.Parallel()
+	var counter int32
+	ts := getTestServerForFunction(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if atomic.AddInt32(&counter, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		w.Write(body)
+	})
+	defer ts.Close()
+
+	// The body is sent again on each retry
+	response := NewHttpRequest("POST", ts.URL).WithBody([]byte("payload")).DoWithRetry(t, 200, 5, 10*time.Millisecond)
+	assert.Equal(t, "payload", response.Body)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&counter))
+}
+
+func TestHttpRequestWithInvalidJsonBody(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewHttpRequest("POST", "http://localhost").WithJsonBody(make(chan int)).DoE(t)
+	assert.ErrorContains(t, err, "error encoding the JSON body of the request")
+}
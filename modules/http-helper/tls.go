@@ -0,0 +1,108 @@
+package http_helper
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// TlsOptions are the options to build the TLS configuration of the http-helper functions, e.g. to verify endpoints
+// behind mutual TLS or with certificates of a private CA. The certificates and keys can be given as PEM contents (e.g.
+// Terraform outputs) or as paths to PEM files.
+type TlsOptions struct {
+	// The client certificate and key, for mutual TLS
+	ClientCertPem  string
+	ClientKeyPem   string
+	ClientCertFile string
+	ClientKeyFile  string
+
+	// The certificates of the CAs to verify the server with. If set, only these CAs are trusted, otherwise the CAs of
+	// the system are.
+	CaCertPems  []string
+	CaCertFiles []string
+
+	// The name to verify the certificate of the server with, if it differs from the host of the URL
+	ServerName string
+
+	// The minimum TLS version (e.g. tls.VersionTLS12). Zero means the default of the Go TLS package.
+	MinVersion uint16
+
+	// Skip the verification of the certificate of the server. Only use this for self-signed certificates in tests.
+	InsecureSkipVerify bool
+}
+
+// NewTlsConfig returns the TLS configuration for the given options, to pass to the http-helper functions. If there's
+// any error, fail the test.
+func NewTlsConfig(t testing.TestingT, options TlsOptions) *tls.Config {
+	tlsConfig, err := NewTlsConfigE(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tlsConfig
+}
+
+// NewTlsConfigE returns the TLS configuration for the given options, to pass to the http-helper functions, and any
+// error loading the certificates.
+func NewTlsConfigE(options TlsOptions) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         options.ServerName,
+		MinVersion:         options.MinVersion,
+		InsecureSkipVerify: options.InsecureSkipVerify,
+	}
+
+	clientCertPem, err := readPem(options.ClientCertPem, options.ClientCertFile)
+	if err != nil {
+		return nil, err
+	}
+	clientKeyPem, err := readPem(options.ClientKeyPem, options.ClientKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if len(clientCertPem) > 0 || len(clientKeyPem) > 0 {
+		clientCert, err := tls.X509KeyPair(clientCertPem, clientKeyPem)
+		if err != nil {
+			return nil, fmt.Errorf("error loading the client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{clientCert}
+	}
+
+	caCertPems := []string{}
+	caCertPems = append(caCertPems, options.CaCertPems...)
+	for _, caCertFile := range options.CaCertFiles {
+		caCertPem, err := os.ReadFile(caCertFile)
+		if err != nil {
+			return nil, err
+		}
+		caCertPems = append(caCertPems, string(caCertPem))
+	}
+	if len(caCertPems) > 0 {
+		tlsConfig.RootCAs = x509.NewCertPool()
+		for _, caCertPem := range caCertPems {
+			if !tlsConfig.RootCAs.AppendCertsFromPEM([]byte(caCertPem)) {
+				return nil, errors.New("error loading a CA certificate: no valid PEM certificate found")
+			}
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// getTlsConfigE returns the given TLS configuration, or the TLS configuration for the given options if there's none.
+func getTlsConfigE(tlsConfig *tls.Config, options *TlsOptions) (*tls.Config, error) {
+	if tlsConfig != nil || options == nil {
+		return tlsConfig, nil
+	}
+	return NewTlsConfigE(*options)
+}
+
+// readPem returns the given PEM contents, or the contents of the given file if there are none.
+func readPem(pem string, file string) ([]byte, error) {
+	if pem != "" || file == "" {
+		return []byte(pem), nil
+	}
+	return os.ReadFile(file)
+}
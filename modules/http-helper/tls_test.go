@@ -0,0 +1,113 @@
+package http_helper
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTlsConfigWithMutualTls(t *testing.T) {
+	t.Parallel()
+
+	caCert, caKey := generateTestCertificate(t, nil, nil)
+	serverCertPem, serverKeyPem := generateTestCertificatePem(t, caCert, caKey)
+	clientCertPem, clientKeyPem := generateTestCertificatePem(t, caCert, caKey)
+	serverCert, err := tls.X509KeyPair([]byte(serverCertPem), []byte(serverKeyPem))
+	require.NoError(t, err)
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "Hello, %s!", r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	caPool := x509.NewCertPool()
+	caPool.AddCert(caCert)
+	ts.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    caPool,
+	}
+	ts.StartTLS()
+	defer ts.Close()
+
+	caCertFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caCertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw}), 0644))
+
+	options := TlsOptions{
+		ClientCertPem: clientCertPem,
+		ClientKeyPem:  clientKeyPem,
+		CaCertFiles:   []string{caCertFile},
+		MinVersion:    tls.VersionTLS12,
+	}
+
+	statusCode, body := HttpGetWithOptions(t, HttpGetOptions{Url: ts.URL, TlsOptions: &options, Timeout: 10})
+	assert.Equal(t, 200, statusCode)
+	assert.Equal(t, "Hello, terratest!", body)
+
+	response := NewHttpRequest("GET", ts.URL).WithTlsOptions(options).Do(t)
+	assert.Equal(t, "Hello, terratest!", response.Body)
+
+	// Without the client certificate, the server refuses the connection
+	_, _, err = HttpGetWithOptionsE(t, HttpGetOptions{Url: ts.URL, TlsOptions: &TlsOptions{CaCertFiles: []string{caCertFile}}, Timeout: 10})
+	assert.Error(t, err)
+}
+
+func TestNewTlsConfigEInvalidCaCert(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewTlsConfigE(TlsOptions{CaCertPems: []string{"not a certificate"}})
+	assert.EqualError(t, err, "error loading a CA certificate: no valid PEM certificate found")
+}
+
+// generateTestCertificate generates a certificate for 127.0.0.1 signed by the given CA, or a CA certificate if there is
+// no CA.
+func generateTestCertificate(t *testing.T, caCert *x509.Certificate, caKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "terratest"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if caCert == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+		caCert, caKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert, key
+}
+
+// generateTestCertificatePem generates a certificate for 127.0.0.1 signed by the given CA, and returns the certificate
+// and its key in the PEM format.
+func generateTestCertificatePem(t *testing.T, caCert *x509.Certificate, caKey *ecdsa.PrivateKey) (string, string) {
+	cert, key := generateTestCertificate(t, caCert, caKey)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	return string(certPem), string(keyPem)
+}
@@ -0,0 +1,133 @@
+package http_helper
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/proxy"
+)
+
+// HttpProtocol is the HTTP protocol version the http-helper functions use.
+type HttpProtocol string
+
+const (
+	// HttpProtocolAuto negotiates HTTP/2 over TLS when the server supports it, and uses HTTP/1.1 otherwise.
+	HttpProtocolAuto HttpProtocol = ""
+
+	// HttpProtocolHttp1 only uses HTTP/1.1.
+	HttpProtocolHttp1 HttpProtocol = "http/1.1"
+
+	// HttpProtocolHttp2 only uses HTTP/2 over TLS, and fails if the server negotiates another protocol.
+	HttpProtocolHttp2 HttpProtocol = "h2"
+
+	// HttpProtocolH2c only uses HTTP/2 without TLS (h2c with prior knowledge), e.g. for gRPC or services behind a
+	// load balancer that terminates TLS. The requests to https:// URLs fail.
+	HttpProtocolH2c HttpProtocol = "h2c"
+)
+
+// newHttpTransportE returns the transport to perform requests with, with the given TLS configuration, proxy and
+// protocol. If proxyUrl is empty, the proxy of the environment (HTTP_PROXY, HTTPS_PROXY and NO_PROXY) is used.
+func newHttpTransportE(tlsConfig *tls.Config, proxyUrl string, protocol HttpProtocol) (http.RoundTripper, error) {
+	var parsedProxyUrl *url.URL
+	if proxyUrl != "" {
+		var err error
+		parsedProxyUrl, err = url.Parse(proxyUrl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL %s: %w", proxyUrl, err)
+		}
+	}
+
+	if protocol == HttpProtocolH2c {
+		return newH2cTransportE(parsedProxyUrl)
+	}
+
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.TLSClientConfig = tlsConfig
+	if parsedProxyUrl != nil {
+		// net/http supports http, https and socks5 proxies, and tunnels HTTPS requests with CONNECT
+		tr.Proxy = http.ProxyURL(parsedProxyUrl)
+	}
+
+	switch protocol {
+	case HttpProtocolAuto:
+		return tr, nil
+	case HttpProtocolHttp1:
+		tr.ForceAttemptHTTP2 = false
+		// A non-nil empty map disables HTTP/2, and the client must not offer it in the TLS handshake either
+		tr.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+		if tr.TLSClientConfig == nil {
+			tr.TLSClientConfig = &tls.Config{}
+		} else {
+			tr.TLSClientConfig = tr.TLSClientConfig.Clone()
+		}
+		tr.TLSClientConfig.NextProtos = []string{"http/1.1"}
+		return &protocolCheckingTransport{transport: tr, protocol: protocol, protoMajor: 1}, nil
+	case HttpProtocolHttp2:
+		tr.ForceAttemptHTTP2 = true
+		return &protocolCheckingTransport{transport: tr, protocol: protocol, protoMajor: 2}, nil
+	default:
+		return nil, fmt.Errorf("unsupported HTTP protocol %s", protocol)
+	}
+}
+
+// newH2cTransportE returns a transport for HTTP/2 without TLS, connecting directly or through the given SOCKS5 proxy.
+func newH2cTransportE(proxyUrl *url.URL) (http.RoundTripper, error) {
+	var dialer proxy.ContextDialer = &net.Dialer{}
+	if proxyUrl != nil {
+		if proxyUrl.Scheme != "socks5" && proxyUrl.Scheme != "socks5h" {
+			return nil, fmt.Errorf("h2c can only be used through a SOCKS5 proxy, not %s", proxyUrl.Redacted())
+		}
+		proxyDialer, err := proxy.FromURL(proxyUrl, proxy.Direct)
+		if err != nil {
+			return nil, err
+		}
+		dialer = proxyDialer.(proxy.ContextDialer)
+	}
+
+	return &h2cTransport{transport: &http2.Transport{
+		AllowHTTP: true,
+		// With AllowHTTP, http:// URLs are dialed with DialTLSContext too, so dial them without TLS
+		DialTLSContext: func(ctx context.Context, network string, address string, _ *tls.Config) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, address)
+		},
+	}}, nil
+}
+
+// h2cTransport is a transport for HTTP/2 without TLS that fails the requests to https:// URLs, which its dialer would
+// otherwise connect to in plaintext.
+type h2cTransport struct {
+	transport http.RoundTripper
+}
+
+// RoundTrip performs the request, unless its URL, e.g. the one it's redirected to, isn't an http:// URL.
+func (transport *h2cTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "http" {
+		return nil, fmt.Errorf("h2c can only be used with http:// URLs, not %s", req.URL.Redacted())
+	}
+	return transport.transport.RoundTrip(req)
+}
+
+// protocolCheckingTransport is a transport that fails requests whose response isn't of the given protocol version.
+type protocolCheckingTransport struct {
+	transport  http.RoundTripper
+	protocol   HttpProtocol
+	protoMajor int
+}
+
+// RoundTrip performs the request and returns an UnexpectedProtocolError if the response isn't of the protocol version.
+func (transport *protocolCheckingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := transport.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.ProtoMajor != transport.protoMajor {
+		resp.Body.Close()
+		return nil, UnexpectedProtocolError{Url: req.URL.String(), Expected: transport.protocol, Actual: resp.Proto}
+	}
+	return resp, nil
+}
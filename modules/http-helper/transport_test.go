@@ -0,0 +1,98 @@
+package http_helper
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// protoHandler responds with the protocol of the request.
+func protoHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprint(w, r.Proto)
+}
+
+// getTestHttp2Server returns a TLS server that supports HTTP/2 and HTTP/1.1.
+func getTestHttp2Server() *httptest.Server {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(protoHandler))
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	return ts
+}
+
+var insecureTlsConfig = &tls.Config{InsecureSkipVerify: true}
+
+func TestHttpGetWithOptionsProtocol(t *testing.T) {
+	t.Parallel()
+	ts := getTestHttp2Server()
+	defer ts.Close()
+
+	for _, testCase := range []struct {
+		protocol      HttpProtocol
+		expectedProto string
+	}{
+		{HttpProtocolAuto, "HTTP/2.0"},
+		{HttpProtocolHttp1, "HTTP/1.1"},
+		{HttpProtocolHttp2, "HTTP/2.0"},
+	} {
+		options := HttpGetOptions{Url: ts.URL, TlsConfig: insecureTlsConfig, Timeout: 10, Protocol: testCase.protocol}
+		_, body := HttpGetWithOptions(t, options)
+		assert.Equal(t, testCase.expectedProto, body, "protocol %q", testCase.protocol)
+	}
+}
+
+func TestHttpGetWithOptionsEHttp2Unsupported(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(protoHandler))
+	defer ts.Close()
+
+	options := HttpGetOptions{Url: ts.URL, TlsConfig: insecureTlsConfig, Timeout: 10, Protocol: HttpProtocolHttp2}
+	_, _, err := HttpGetWithOptionsE(t, options)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Expected protocol h2")
+}
+
+func TestHttpRequestH2c(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(protoHandler), &http2.Server{}))
+	defer ts.Close()
+
+	response := NewHttpRequest("GET", ts.URL).WithProtocol(HttpProtocolH2c).Do(t)
+	assert.Equal(t, "HTTP/2.0", response.Proto)
+	assert.Equal(t, "HTTP/2.0", response.Body)
+}
+
+func TestHttpRequestWithProxy(t *testing.T) {
+	t.Parallel()
+	proxy := getTestServerForFunction(func(w http.ResponseWriter, r *http.Request) {
+		// A proxy receives the absolute URL of the request
+		fmt.Fprintf(w, "proxied %s", r.URL.String())
+	})
+	defer proxy.Close()
+
+	response := NewHttpRequest("GET", "http://service.internal/health").WithProxy(proxy.URL).Do(t)
+	assert.Equal(t, "proxied http://service.internal/health", response.Body)
+
+	_, body := HttpGetWithOptions(t, HttpGetOptions{Url: "http://service.internal/health", Timeout: 10, ProxyUrl: proxy.URL})
+	assert.Equal(t, "proxied http://service.internal/health", body)
+}
+
+func TestHttpRequestH2cWithHttpProxy(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewHttpRequest("GET", "http://localhost").WithProtocol(HttpProtocolH2c).WithProxy("http://localhost:3128").DoE(t)
+	assert.ErrorContains(t, err, "h2c can only be used through a SOCKS5 proxy")
+}
+
+func TestHttpRequestH2cWithHttpsUrl(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewHttpRequest("GET", "https://localhost").WithProtocol(HttpProtocolH2c).DoE(t)
+	assert.ErrorContains(t, err, "h2c can only be used with http:// URLs, not https://localhost")
+}
@@ -0,0 +1,200 @@
+package k8s
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// certificateResource is the group, version and resource of the cert-manager Certificates, which are read with the
+// dynamic client.
+var certificateResource = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+
+// Certificate is a cert-manager.io Certificate, with the fields used to check it.
+type Certificate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CertificateSpec   `json:"spec"`
+	Status CertificateStatus `json:"status,omitempty"`
+}
+
+// CertificateSpec is the specification of a Certificate.
+type CertificateSpec struct {
+	SecretName  string               `json:"secretName"`
+	CommonName  string               `json:"commonName,omitempty"`
+	DNSNames    []string             `json:"dnsNames,omitempty"`
+	IPAddresses []string             `json:"ipAddresses,omitempty"`
+	IssuerRef   CertificateIssuerRef `json:"issuerRef"`
+}
+
+// CertificateIssuerRef is the reference to the Issuer, or ClusterIssuer, of a Certificate.
+type CertificateIssuerRef struct {
+	Name  string `json:"name"`
+	Kind  string `json:"kind,omitempty"`
+	Group string `json:"group,omitempty"`
+}
+
+// CertificateStatus is the status of a Certificate.
+type CertificateStatus struct {
+	Conditions  []metav1.Condition `json:"conditions,omitempty"`
+	NotBefore   *metav1.Time       `json:"notBefore,omitempty"`
+	NotAfter    *metav1.Time       `json:"notAfter,omitempty"`
+	RenewalTime *metav1.Time       `json:"renewalTime,omitempty"`
+}
+
+// CertificateSecretCheck defines the expected content of the certificate stored in a TLS secret.
+type CertificateSecretCheck struct {
+	// If set, the DNS names the subject alternative names of the certificate must contain.
+	ExpectedDNSNames []string
+
+	// If set, the common name of the issuer of the certificate, e.g. to distinguish staging certificates.
+	ExpectedIssuerCommonName string
+
+	// If set, the minimum duration the certificate must remain valid for, e.g. to check it is renewed in time.
+	MinRemainingValidity time.Duration
+
+	// If set, the maximum duration the certificate can remain valid for, e.g. to check the duration set on the
+	// Certificate is honored.
+	MaxRemainingValidity time.Duration
+}
+
+// GetCertificate returns the cert-manager Certificate resource in the namespace of the options with the given name.
+// This will fail the test if there is an error.
+func GetCertificate(t testing.TestingT, options *KubectlOptions, certificateName string) *Certificate {
+	certificate, err := GetCertificateE(t, options, certificateName)
+	require.NoError(t, err)
+	return certificate
+}
+
+// GetCertificateE returns the cert-manager Certificate resource in the namespace of the options with the given name.
+func GetCertificateE(t testing.TestingT, options *KubectlOptions, certificateName string) (*Certificate, error) {
+	resource, err := GetCustomResourceE(t, options, certificateResource, certificateName)
+	if err != nil {
+		return nil, err
+	}
+	certificate := &Certificate{}
+	if err := fromUnstructured(resource, certificate); err != nil {
+		return nil, err
+	}
+	return certificate, nil
+}
+
+// IsCertificateReady returns true if the Certificate has the Ready condition for its current generation, meaning its
+// secret holds an up to date certificate.
+func IsCertificateReady(certificate *Certificate) bool {
+	return isConditionTrueForGeneration(certificate.Status.Conditions, "Ready", certificate.Generation)
+}
+
+// WaitUntilCertificateReady waits until the cert-manager Certificate is ready, retrying the check for the specified
+// amount of times, sleeping for the provided duration between each try. This will fail the test if there is an error.
+func WaitUntilCertificateReady(t testing.TestingT, options *KubectlOptions, certificateName string, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, WaitUntilCertificateReadyE(t, options, certificateName, retries, sleepBetweenRetries))
+}
+
+// WaitUntilCertificateReadyE waits until the cert-manager Certificate is ready, retrying the check for the specified
+// amount of times, sleeping for the provided duration between each try.
+func WaitUntilCertificateReadyE(t testing.TestingT, options *KubectlOptions, certificateName string, retries int, sleepBetweenRetries time.Duration) error {
+	statusMsg := fmt.Sprintf("Wait for certificate %s to be ready.", certificateName)
+	message, err := retry.DoWithRetryE(
+		t,
+		statusMsg,
+		retries,
+		sleepBetweenRetries,
+		func() (string, error) {
+			certificate, err := GetCertificateE(t, options, certificateName)
+			if err != nil {
+				return "", err
+			}
+			if !IsCertificateReady(certificate) {
+				return "", NewCertificateNotReadyError(certificate)
+			}
+			return "Certificate is now ready", nil
+		},
+	)
+	if err != nil {
+		options.Logger.Logf(t, "Timedout waiting for Certificate to be ready: %s", err)
+		return err
+	}
+	options.Logger.Logf(t, message)
+	return nil
+}
+
+// AssertCertificateSecret checks that the TLS secret in the namespace of the options with the given name holds a
+// certificate matching the check. This will fail the test if there is an error.
+func AssertCertificateSecret(t testing.TestingT, options *KubectlOptions, secretName string, check CertificateSecretCheck) {
+	require.NoError(t, AssertCertificateSecretE(t, options, secretName, check))
+}
+
+// AssertCertificateSecretE checks that the TLS secret in the namespace of the options with the given name holds a
+// certificate matching the check: the first certificate of its tls.crt key is checked, the others being the chain of
+// its issuer.
+func AssertCertificateSecretE(t testing.TestingT, options *KubectlOptions, secretName string, check CertificateSecretCheck) error {
+	secret, err := GetSecretE(t, options, secretName)
+	if err != nil {
+		return err
+	}
+	certificate, err := parseSecretCertificate(secret)
+	if err != nil {
+		return err
+	}
+	if err := checkCertificate(certificate, check, time.Now()); err != nil {
+		return fmt.Errorf("certificate of secret %s: %w", secretName, err)
+	}
+	options.Logger.Logf(t, "Certificate of secret %s for %v is valid until %s", secretName, certificate.DNSNames, certificate.NotAfter)
+	return nil
+}
+
+// parseSecretCertificate returns the first certificate of the tls.crt key of the secret.
+func parseSecretCertificate(secret *corev1.Secret) (*x509.Certificate, error) {
+	block, _ := pem.Decode(secret.Data[corev1.TLSCertKey])
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("secret %s has no PEM encoded certificate in its %s key", secret.Name, corev1.TLSCertKey)
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// checkCertificate checks the certificate against the check, at the given time.
+func checkCertificate(certificate *x509.Certificate, check CertificateSecretCheck, now time.Time) error {
+	missing := []string{}
+	for _, dnsName := range check.ExpectedDNSNames {
+		if !slices.Contains(certificate.DNSNames, dnsName) {
+			missing = append(missing, dnsName)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("missing DNS names %s, got %s", strings.Join(missing, ", "), strings.Join(certificate.DNSNames, ", "))
+	}
+
+	if check.ExpectedIssuerCommonName != "" && certificate.Issuer.CommonName != check.ExpectedIssuerCommonName {
+		return fmt.Errorf("issued by %q, expected %q", certificate.Issuer.CommonName, check.ExpectedIssuerCommonName)
+	}
+
+	if now.Before(certificate.NotBefore) {
+		return fmt.Errorf("not valid before %s", certificate.NotBefore)
+	}
+	remaining := certificate.NotAfter.Sub(now)
+	if remaining <= 0 {
+		return fmt.Errorf("expired at %s", certificate.NotAfter)
+	}
+	if check.MinRemainingValidity > 0 && remaining < check.MinRemainingValidity {
+		return fmt.Errorf("expires at %s, in less than %s", certificate.NotAfter, check.MinRemainingValidity)
+	}
+	if check.MaxRemainingValidity > 0 && remaining > check.MaxRemainingValidity {
+		return fmt.Errorf("expires at %s, in more than %s", certificate.NotAfter, check.MaxRemainingValidity)
+	}
+	return nil
+}
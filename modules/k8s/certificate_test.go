@@ -0,0 +1,89 @@
+//go:build kubeall || kubernetes
+// +build kubeall kubernetes
+
+// NOTE: we have build tags to differentiate kubernetes tests from non-kubernetes tests. This is done because minikube
+// is heavy and can interfere with docker related tests in terratest. Specifically, many of the tests start to fail with
+// `connection refused` errors from `minikube`. To avoid overloading the system, we run the kubernetes tests and helm
+// tests separately from the others. This may not be necessary if you have a sufficiently powerful machine.  We
+// recommend at least 4 cores and 16GB of RAM if you want to run all the tests together.
+
+package k8s
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckCertificate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-tls"},
+		Data:       map[string][]byte{corev1.TLSCertKey: createTestCertificatePEM(t, now, 90*24*time.Hour)},
+	}
+	certificate, err := parseSecretCertificate(secret)
+	require.NoError(t, err)
+
+	require.NoError(t, checkCertificate(certificate, CertificateSecretCheck{
+		ExpectedDNSNames:         []string{"example.com", "www.example.com"},
+		ExpectedIssuerCommonName: "Test CA",
+		MinRemainingValidity:     30 * 24 * time.Hour,
+		MaxRemainingValidity:     90 * 24 * time.Hour,
+	}, now))
+
+	err = checkCertificate(certificate, CertificateSecretCheck{ExpectedDNSNames: []string{"api.example.com"}}, now)
+	assert.EqualError(t, err, "missing DNS names api.example.com, got example.com, www.example.com")
+	require.Error(t, checkCertificate(certificate, CertificateSecretCheck{ExpectedIssuerCommonName: "Other CA"}, now))
+	require.Error(t, checkCertificate(certificate, CertificateSecretCheck{MinRemainingValidity: 100 * 24 * time.Hour}, now))
+	require.Error(t, checkCertificate(certificate, CertificateSecretCheck{MaxRemainingValidity: 24 * time.Hour}, now))
+	require.Error(t, checkCertificate(certificate, CertificateSecretCheck{}, now.Add(91*24*time.Hour)))
+
+	_, err = parseSecretCertificate(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "empty"}})
+	require.Error(t, err)
+}
+
+func TestIsCertificateReady(t *testing.T) {
+	t.Parallel()
+
+	certificate := &Certificate{ObjectMeta: metav1.ObjectMeta{Name: "web", Generation: 1}}
+	assert.False(t, IsCertificateReady(certificate))
+	assert.Equal(t, "Certificate web is not ready yet", NewCertificateNotReadyError(certificate).Error())
+
+	certificate.Status.Conditions = []metav1.Condition{{Type: "Ready", Status: metav1.ConditionFalse, Reason: "Issuing", ObservedGeneration: 1}}
+	assert.False(t, IsCertificateReady(certificate))
+
+	certificate.Status.Conditions[0].Status = metav1.ConditionTrue
+	assert.True(t, IsCertificateReady(certificate))
+}
+
+// createTestCertificatePEM returns a PEM encoded certificate for example.com, issued by a test CA, valid for the given
+// duration from the given time.
+func createTestCertificatePEM(t *testing.T, notBefore time.Time, validity time.Duration) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		DNSNames:     []string{"example.com", "www.example.com"},
+		NotBefore:    notBefore.Add(-time.Minute),
+		NotAfter:     notBefore.Add(validity - time.Minute),
+	}
+	issuer := &x509.Certificate{SerialNumber: big.NewInt(2), Subject: pkix.Name{CommonName: "Test CA"}}
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
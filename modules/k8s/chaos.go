@@ -0,0 +1,159 @@
+package k8s
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// availabilityCheckInterval is the time between the requests sent to measure the availability of a service.
+const availabilityCheckInterval = 200 * time.Millisecond
+
+// AvailabilityReport is the result of the requests sent to a service while a disruption happened.
+type AvailabilityReport struct {
+	Requests int
+	Failures int
+}
+
+// ErrorRate returns the ratio of failed requests, between 0 and 1.
+func (report AvailabilityReport) ErrorRate() float64 {
+	if report.Requests == 0 {
+		return 0
+	}
+	return float64(report.Failures) / float64(report.Requests)
+}
+
+// DeleteRandomPodInDeployment deletes one of the pods of the deployment, chosen at random, and returns its name. This
+// will fail the test if there is an error.
+func DeleteRandomPodInDeployment(t testing.TestingT, options *KubectlOptions, deploymentName string) string {
+	podName, err := DeleteRandomPodInDeploymentE(t, options, deploymentName)
+	require.NoError(t, err)
+	return podName
+}
+
+// DeleteRandomPodInDeploymentE deletes one of the pods of the deployment, chosen at random, and returns its name.
+func DeleteRandomPodInDeploymentE(t testing.TestingT, options *KubectlOptions, deploymentName string) (string, error) {
+	deployment, err := GetDeploymentE(t, options, deploymentName)
+	if err != nil {
+		return "", err
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return "", err
+	}
+	pods, err := ListPodsE(t, options, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return "", err
+	}
+	if len(pods) == 0 {
+		return "", NewDeploymentNotAvailableError(deployment)
+	}
+
+	pod := pods[random.Random(0, len(pods)-1)]
+	options.Logger.Logf(t, "Deleting pod %s of deployment %s", pod.Name, deploymentName)
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return "", err
+	}
+	return pod.Name, clientset.CoreV1().Pods(options.Namespace).Delete(context.Background(), pod.Name, metav1.DeleteOptions{})
+}
+
+// CordonNode marks the node as unschedulable, so no new pods are scheduled on it. This will fail the test if there is
+// an error.
+func CordonNode(t testing.TestingT, options *KubectlOptions, nodeName string) {
+	require.NoError(t, CordonNodeE(t, options, nodeName))
+}
+
+// CordonNodeE marks the node as unschedulable, so no new pods are scheduled on it.
+func CordonNodeE(t testing.TestingT, options *KubectlOptions, nodeName string) error {
+	return RunKubectlE(t, options, "cordon", nodeName)
+}
+
+// UncordonNode marks the node as schedulable again, e.g. to restore it at the end of a test that cordoned or drained
+// it. This will fail the test if there is an error.
+func UncordonNode(t testing.TestingT, options *KubectlOptions, nodeName string) {
+	require.NoError(t, UncordonNodeE(t, options, nodeName))
+}
+
+// UncordonNodeE marks the node as schedulable again, e.g. to restore it at the end of a test that cordoned or drained
+// it.
+func UncordonNodeE(t testing.TestingT, options *KubectlOptions, nodeName string) error {
+	return RunKubectlE(t, options, "uncordon", nodeName)
+}
+
+// DrainNode cordons the node and evicts its pods, respecting their PodDisruptionBudgets, waiting up to the timeout for
+// the evictions to complete. This will fail the test if there is an error.
+func DrainNode(t testing.TestingT, options *KubectlOptions, nodeName string, timeout time.Duration) {
+	require.NoError(t, DrainNodeE(t, options, nodeName, timeout))
+}
+
+// DrainNodeE cordons the node and evicts its pods, respecting their PodDisruptionBudgets, waiting up to the timeout for
+// the evictions to complete. Pods of daemonsets are left in place, and the data of emptyDir volumes is deleted.
+func DrainNodeE(t testing.TestingT, options *KubectlOptions, nodeName string, timeout time.Duration) error {
+	return RunKubectlE(t, options, "drain", nodeName, "--ignore-daemonsets", "--delete-emptydir-data", "--timeout", timeout.String())
+}
+
+// AssertServiceAvailabilityDuring sends requests to the URL while running the disruptive action (e.g. deleting a pod
+// or draining a node), and checks that the ratio of failed requests is at most maxErrorRate, between 0 and 1. This will
+// fail the test if the error rate is higher or if the action fails.
+func AssertServiceAvailabilityDuring(t testing.TestingT, url string, tlsConfig *tls.Config, maxErrorRate float64, action func() error) {
+	require.NoError(t, AssertServiceAvailabilityDuringE(t, url, tlsConfig, maxErrorRate, action))
+}
+
+// AssertServiceAvailabilityDuringE sends requests to the URL while running the disruptive action (e.g. deleting a pod
+// or draining a node), and checks that the ratio of failed requests is at most maxErrorRate, between 0 and 1.
+// Requests fail on errors and on responses with a 5xx status code.
+func AssertServiceAvailabilityDuringE(t testing.TestingT, url string, tlsConfig *tls.Config, maxErrorRate float64, action func() error) error {
+	report, err := MeasureServiceAvailabilityDuringE(t, url, tlsConfig, action)
+	if err != nil {
+		return err
+	}
+	if report.ErrorRate() > maxErrorRate {
+		return fmt.Errorf("%d of %d requests to %s failed during the disruption, error rate %.3f above %.3f", report.Failures, report.Requests, url, report.ErrorRate(), maxErrorRate)
+	}
+	return nil
+}
+
+// MeasureServiceAvailabilityDuringE sends requests to the URL while running the disruptive action (e.g. deleting a pod
+// or draining a node), and returns how many of them failed. Requests fail on errors and on responses with a 5xx status
+// code.
+func MeasureServiceAvailabilityDuringE(t testing.TestingT, url string, tlsConfig *tls.Config, action func() error) (AvailabilityReport, error) {
+	client := &http.Client{Timeout: 5 * time.Second, Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	done := make(chan struct{})
+	reports := make(chan AvailabilityReport, 1)
+	go func() {
+		report := AvailabilityReport{}
+		for {
+			report.Requests++
+			resp, err := client.Get(url)
+			if err != nil || resp.StatusCode >= http.StatusInternalServerError {
+				report.Failures++
+			}
+			if err == nil {
+				resp.Body.Close()
+			}
+
+			select {
+			case <-done:
+				reports <- report
+				return
+			case <-time.After(availabilityCheckInterval):
+			}
+		}
+	}()
+
+	err := action()
+	close(done)
+	report := <-reports
+	logger.Default.Logf(t, "%d of %d requests to %s failed during the disruption", report.Failures, report.Requests, url)
+	return report, err
+}
@@ -0,0 +1,66 @@
+//go:build kubeall || kubernetes
+// +build kubeall kubernetes
+
+// NOTE: we have build tags to differentiate kubernetes tests from non-kubernetes tests. This is done because minikube
+// is heavy and can interfere with docker related tests in terratest. Specifically, many of the tests start to fail with
+// `connection refused` errors from `minikube`. To avoid overloading the system, we run the kubernetes tests and helm
+// tests separately from the others. This may not be necessary if you have a sufficiently powerful machine.  We
+// recommend at least 4 cores and 16GB of RAM if you want to run all the tests together.
+
+package k8s
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteRandomPodInDeployment(t *testing.T) {
+	t.Parallel()
+
+	uniqueID := strings.ToLower(random.UniqueId())
+	options := NewKubectlOptions("", "", uniqueID)
+	configData := fmt.Sprintf(ExampleDeploymentYAMLTemplate, uniqueID)
+	KubectlApplyFromString(t, options, configData)
+	defer KubectlDeleteFromString(t, options, configData)
+	WaitUntilDeploymentAvailable(t, options, "nginx-deployment", 60, 1*time.Second)
+
+	podName := DeleteRandomPodInDeployment(t, options, "nginx-deployment")
+	require.True(t, strings.HasPrefix(podName, "nginx-deployment-"))
+	WaitUntilDeploymentAvailable(t, options, "nginx-deployment", 60, 1*time.Second)
+}
+
+func TestAssertServiceAvailabilityDuring(t *testing.T) {
+	t.Parallel()
+
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	sleep := func() error {
+		time.Sleep(time.Second)
+		return nil
+	}
+	require.NoError(t, AssertServiceAvailabilityDuringE(t, server.URL, nil, 0, sleep))
+
+	failing.Store(true)
+	report, err := MeasureServiceAvailabilityDuringE(t, server.URL, nil, sleep)
+	require.NoError(t, err)
+	require.Greater(t, report.Requests, 1)
+	require.Equal(t, 1.0, report.ErrorRate())
+	require.Error(t, AssertServiceAvailabilityDuringE(t, server.URL, nil, 0.5, sleep))
+
+	require.Error(t, AssertServiceAvailabilityDuringE(t, server.URL, nil, 1, func() error { return errors.New("drain failed") }))
+}
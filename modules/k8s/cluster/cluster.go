@@ -0,0 +1,175 @@
+// Package cluster allows to create throwaway Kubernetes clusters, running in docker with kind or k3d, for the duration
+// of a test, so tests of charts and manifests don't require a pre-existing cluster.
+package cluster
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/k8s"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// Provider is the tool used to run the cluster.
+type Provider string
+
+const (
+	// ProviderKind runs the cluster with kind (https://kind.sigs.k8s.io).
+	ProviderKind Provider = "kind"
+	// ProviderK3d runs the cluster with k3d (https://k3d.io).
+	ProviderK3d Provider = "k3d"
+)
+
+// defaultWaitTimeout is the time to wait for the control plane to be ready, unless set in the options.
+const defaultWaitTimeout = 5 * time.Minute
+
+// Options defines the cluster to create.
+type Options struct {
+	// The tool to run the cluster with. Defaults to kind.
+	Provider Provider
+
+	// The name of the cluster. Defaults to a unique name starting with terratest-.
+	Name string
+
+	// The node image to use (e.g. kindest/node:v1.28.0 or rancher/k3s:v1.28.4-k3s2). Defaults to the one of the
+	// provider.
+	Image string
+
+	// The number of worker nodes (agents with k3d) in addition to the control plane node.
+	Workers int
+
+	// The mirrors to pull images from, by registry (e.g. docker.io: http://registry-mirror:5000), so tests don't hit the
+	// rate limits of public registries.
+	RegistryMirrors map[string]string
+
+	// The time to wait for the control plane to be ready. Defaults to 5 minutes.
+	WaitTimeout time.Duration
+
+	// Custom CLI options that will be passed as-is to the command creating the cluster, e.g. to forward ports.
+	OtherOptions []string
+
+	// Set a logger that should be used. See the logger package for more info.
+	Logger *logger.Logger
+}
+
+// Cluster is a throwaway cluster created with Create.
+type Cluster struct {
+	Provider Provider
+	Name     string
+
+	// The path to the kubeconfig giving access to the cluster, which isn't merged into the default kubeconfig.
+	KubeconfigPath string
+
+	logger *logger.Logger
+	tmpDir string
+}
+
+// Create creates a new cluster and writes its kubeconfig to a temporary file. Use defer cluster.Destroy(t) to make
+// sure it is deleted at the end of the test. This will fail the test if there is an error.
+func Create(t testing.TestingT, options *Options) *Cluster {
+	cluster, err := CreateE(t, options)
+	require.NoError(t, err)
+	return cluster
+}
+
+// CreateE creates a new cluster and writes its kubeconfig to a temporary file. Use defer cluster.Destroy(t) to make
+// sure it is deleted at the end of the test. If the creation fails, whatever was created is deleted.
+func CreateE(t testing.TestingT, options *Options) (*Cluster, error) {
+	provider := options.Provider
+	if provider == "" {
+		provider = ProviderKind
+	}
+	name := options.Name
+	if name == "" {
+		name = fmt.Sprintf("terratest-%s", strings.ToLower(random.UniqueId()))
+	}
+
+	tmpDir, err := os.MkdirTemp("", name)
+	if err != nil {
+		return nil, err
+	}
+	cluster := &Cluster{
+		Provider:       provider,
+		Name:           name,
+		KubeconfigPath: filepath.Join(tmpDir, "kubeconfig"),
+		logger:         options.Logger,
+		tmpDir:         tmpDir,
+	}
+
+	options.Logger.Logf(t, "Creating %s cluster %s", provider, name)
+	switch provider {
+	case ProviderKind:
+		err = createKindClusterE(t, cluster, options)
+	case ProviderK3d:
+		err = createK3dClusterE(t, cluster, options)
+	default:
+		err = fmt.Errorf("unknown cluster provider %s", provider)
+	}
+	if err != nil {
+		if destroyErr := cluster.DestroyE(t); destroyErr != nil {
+			options.Logger.Logf(t, "Error deleting cluster %s after failed creation: %s", name, destroyErr)
+		}
+		return nil, err
+	}
+	return cluster, nil
+}
+
+// KubectlOptions returns the options to access the given namespace of the cluster with the k8s module.
+func (cluster *Cluster) KubectlOptions(namespace string) *k8s.KubectlOptions {
+	return k8s.NewKubectlOptions(cluster.ContextName(), cluster.KubeconfigPath, namespace)
+}
+
+// ContextName returns the name of the context of the cluster in its kubeconfig.
+func (cluster *Cluster) ContextName() string {
+	return fmt.Sprintf("%s-%s", cluster.Provider, cluster.Name)
+}
+
+// Destroy deletes the cluster and its kubeconfig. This will fail the test if there is an error.
+func (cluster *Cluster) Destroy(t testing.TestingT) {
+	require.NoError(t, cluster.DestroyE(t))
+}
+
+// DestroyE deletes the cluster and its kubeconfig.
+func (cluster *Cluster) DestroyE(t testing.TestingT) error {
+	cluster.logger.Logf(t, "Deleting %s cluster %s", cluster.Provider, cluster.Name)
+
+	var err error
+	switch cluster.Provider {
+	case ProviderKind:
+		err = cluster.runE(t, "kind", "delete", "cluster", "--name", cluster.Name, "--kubeconfig", cluster.KubeconfigPath)
+	case ProviderK3d:
+		err = cluster.runE(t, "k3d", "cluster", "delete", cluster.Name)
+	}
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(cluster.tmpDir)
+}
+
+func (cluster *Cluster) runE(t testing.TestingT, command string, args ...string) error {
+	_, err := cluster.runAndGetStdOutE(t, command, args...)
+	return err
+}
+
+func (cluster *Cluster) runAndGetStdOutE(t testing.TestingT, command string, args ...string) (string, error) {
+	return shell.RunCommandAndGetStdOutE(t, shell.Command{
+		Command: command,
+		Args:    args,
+		Logger:  cluster.logger,
+	})
+}
+
+// getWaitTimeout returns the time to wait for the control plane, formatted for the command line.
+func getWaitTimeout(options *Options) string {
+	if options.WaitTimeout == 0 {
+		return defaultWaitTimeout.String()
+	}
+	return options.WaitTimeout.String()
+}
@@ -0,0 +1,83 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatKindConfig(t *testing.T) {
+	t.Parallel()
+
+	options := &Options{
+		Workers: 1,
+		RegistryMirrors: map[string]string{
+			"quay.io":   "http://quay-mirror:5000",
+			"docker.io": "http://docker-mirror:5000",
+		},
+	}
+
+	expected := `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+nodes:
+- role: control-plane
+- role: worker
+containerdConfigPatches:
+- |-
+  [plugins."io.containerd.grpc.v1.cri".registry.mirrors."docker.io"]
+    endpoint = ["http://docker-mirror:5000"]
+  [plugins."io.containerd.grpc.v1.cri".registry.mirrors."quay.io"]
+    endpoint = ["http://quay-mirror:5000"]
+`
+	assert.Equal(t, expected, formatKindConfig(options))
+	assert.NotContains(t, formatKindConfig(&Options{}), "containerdConfigPatches")
+}
+
+func TestFormatKindCreateArgs(t *testing.T) {
+	t.Parallel()
+
+	cluster := &Cluster{Provider: ProviderKind, Name: "test", KubeconfigPath: "/tmp/test/kubeconfig"}
+	options := &Options{Image: "kindest/node:v1.28.0", OtherOptions: []string{"--retain"}}
+
+	assert.Equal(t, []string{
+		"create", "cluster",
+		"--name", "test",
+		"--kubeconfig", "/tmp/test/kubeconfig",
+		"--config", "/tmp/test/kind.yaml",
+		"--wait", "5m0s",
+		"--image", "kindest/node:v1.28.0",
+		"--retain",
+	}, formatKindCreateArgs(cluster, options, "/tmp/test/kind.yaml"))
+	assert.Equal(t, "kind-test", cluster.ContextName())
+}
+
+func TestFormatK3dRegistryConfig(t *testing.T) {
+	t.Parallel()
+
+	options := &Options{RegistryMirrors: map[string]string{"docker.io": "http://docker-mirror:5000"}}
+
+	expected := `mirrors:
+  "docker.io":
+    endpoint:
+      - "http://docker-mirror:5000"
+`
+	assert.Equal(t, expected, formatK3dRegistryConfig(options))
+}
+
+func TestFormatK3dCreateArgs(t *testing.T) {
+	t.Parallel()
+
+	cluster := &Cluster{Provider: ProviderK3d, Name: "test", KubeconfigPath: "/tmp/test/kubeconfig"}
+	options := &Options{Workers: 2}
+
+	assert.Equal(t, []string{
+		"cluster", "create", "test",
+		"--agents", "2",
+		"--kubeconfig-update-default=false",
+		"--kubeconfig-switch-context=false",
+		"--wait",
+		"--timeout", "5m0s",
+		"--registry-config", "/tmp/test/registries.yaml",
+	}, formatK3dCreateArgs(cluster, options, "/tmp/test/registries.yaml"))
+	assert.Equal(t, "k3d-test", cluster.ContextName())
+}
@@ -0,0 +1,56 @@
+package cluster
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+func createK3dClusterE(t testing.TestingT, cluster *Cluster, options *Options) error {
+	registryConfigPath := ""
+	if len(options.RegistryMirrors) > 0 {
+		registryConfigPath = filepath.Join(cluster.tmpDir, "registries.yaml")
+		if err := os.WriteFile(registryConfigPath, []byte(formatK3dRegistryConfig(options)), 0o600); err != nil {
+			return err
+		}
+	}
+	if err := cluster.runE(t, "k3d", formatK3dCreateArgs(cluster, options, registryConfigPath)...); err != nil {
+		return err
+	}
+
+	// k3d doesn't support writing the kubeconfig of a new cluster to a given path, so write it once created. Writing it
+	// rather than getting it keeps its client certificate and key out of the logs.
+	return cluster.runE(t, "k3d", "kubeconfig", "write", cluster.Name, "--output", cluster.KubeconfigPath)
+}
+
+func formatK3dCreateArgs(cluster *Cluster, options *Options, registryConfigPath string) []string {
+	args := []string{
+		"cluster", "create", cluster.Name,
+		"--agents", strconv.Itoa(options.Workers),
+		"--kubeconfig-update-default=false",
+		"--kubeconfig-switch-context=false",
+		"--wait",
+		"--timeout", getWaitTimeout(options),
+	}
+	if options.Image != "" {
+		args = append(args, "--image", options.Image)
+	}
+	if registryConfigPath != "" {
+		args = append(args, "--registry-config", registryConfigPath)
+	}
+	return append(args, options.OtherOptions...)
+}
+
+// formatK3dRegistryConfig returns the k3s registries.yaml configuring the registry mirrors.
+func formatK3dRegistryConfig(options *Options) string {
+	var config strings.Builder
+	config.WriteString("mirrors:\n")
+	for _, registry := range sortedRegistries(options.RegistryMirrors) {
+		fmt.Fprintf(&config, "  %q:\n    endpoint:\n      - %q\n", registry, options.RegistryMirrors[registry])
+	}
+	return config.String()
+}
@@ -0,0 +1,63 @@
+package cluster
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+func createKindClusterE(t testing.TestingT, cluster *Cluster, options *Options) error {
+	configPath := filepath.Join(cluster.tmpDir, "kind.yaml")
+	if err := os.WriteFile(configPath, []byte(formatKindConfig(options)), 0o600); err != nil {
+		return err
+	}
+	return cluster.runE(t, "kind", formatKindCreateArgs(cluster, options, configPath)...)
+}
+
+func formatKindCreateArgs(cluster *Cluster, options *Options, configPath string) []string {
+	args := []string{
+		"create", "cluster",
+		"--name", cluster.Name,
+		"--kubeconfig", cluster.KubeconfigPath,
+		"--config", configPath,
+		"--wait", getWaitTimeout(options),
+	}
+	if options.Image != "" {
+		args = append(args, "--image", options.Image)
+	}
+	return append(args, options.OtherOptions...)
+}
+
+// formatKindConfig returns the kind configuration of the cluster, with the worker nodes and the containerd
+// configuration of the registry mirrors.
+func formatKindConfig(options *Options) string {
+	var config strings.Builder
+	config.WriteString("kind: Cluster\napiVersion: kind.x-k8s.io/v1alpha4\nnodes:\n- role: control-plane\n")
+	for i := 0; i < options.Workers; i++ {
+		config.WriteString("- role: worker\n")
+	}
+	if len(options.RegistryMirrors) == 0 {
+		return config.String()
+	}
+
+	config.WriteString("containerdConfigPatches:\n- |-\n")
+	for _, registry := range sortedRegistries(options.RegistryMirrors) {
+		fmt.Fprintf(&config, "  [plugins.\"io.containerd.grpc.v1.cri\".registry.mirrors.%q]\n", registry)
+		fmt.Fprintf(&config, "    endpoint = [%q]\n", options.RegistryMirrors[registry])
+	}
+	return config.String()
+}
+
+// sortedRegistries returns the registries of the mirrors in a stable order.
+func sortedRegistries(mirrors map[string]string) []string {
+	registries := make([]string, 0, len(mirrors))
+	for registry := range mirrors {
+		registries = append(registries, registry)
+	}
+	sort.Strings(registries)
+	return registries
+}
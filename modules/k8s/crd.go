@@ -0,0 +1,133 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// crdResource is the group, version and resource of the CustomResourceDefinitions, which are read with the dynamic
+// client.
+var crdResource = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+// GetCrd returns the CustomResourceDefinition with the given name (e.g. applications.argoproj.io). This will fail the
+// test if there is an error.
+func GetCrd(t testing.TestingT, options *KubectlOptions, name string) *unstructured.Unstructured {
+	crd, err := GetCrdE(t, options, name)
+	require.NoError(t, err)
+	return crd
+}
+
+// GetCrdE returns the CustomResourceDefinition with the given name (e.g. applications.argoproj.io).
+func GetCrdE(t testing.TestingT, options *KubectlOptions, name string) (*unstructured.Unstructured, error) {
+	client, err := GetDynamicClientFromOptionsE(t, options)
+	if err != nil {
+		return nil, err
+	}
+	// CustomResourceDefinitions are cluster wide, whatever the namespace of the options
+	return client.Resource(crdResource).Get(context.Background(), name, metav1.GetOptions{})
+}
+
+// WaitUntilCrdEstablished waits until the CustomResourceDefinition with the given name (e.g.
+// applications.argoproj.io) is established and its names are accepted, retrying the check for the specified amount of
+// times, sleeping for the provided duration between each try. This will fail the test if there is an error.
+func WaitUntilCrdEstablished(t testing.TestingT, options *KubectlOptions, name string, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, WaitUntilCrdEstablishedE(t, options, name, retries, sleepBetweenRetries))
+}
+
+// WaitUntilCrdEstablishedE waits until the CustomResourceDefinition with the given name (e.g.
+// applications.argoproj.io) is established and its names are accepted, retrying the check for the specified amount of
+// times, sleeping for the provided duration between each try. Once established, the API server serves the custom
+// resources, so they can be created.
+func WaitUntilCrdEstablishedE(t testing.TestingT, options *KubectlOptions, name string, retries int, sleepBetweenRetries time.Duration) error {
+	statusMsg := fmt.Sprintf("Wait for CustomResourceDefinition %s to be established.", name)
+	message, err := retry.DoWithRetryE(
+		t,
+		statusMsg,
+		retries,
+		sleepBetweenRetries,
+		func() (string, error) {
+			crd, err := GetCrdE(t, options, name)
+			if err != nil {
+				return "", err
+			}
+			for _, conditionType := range []string{"NamesAccepted", "Established"} {
+				if !IsCustomResourceConditionStatus(crd, conditionType, "True") {
+					return "", NewCustomResourceConditionNotMetError(crd, conditionType, "True")
+				}
+			}
+			return fmt.Sprintf("CustomResourceDefinition %s is now established", name), nil
+		},
+	)
+	if err != nil {
+		options.Logger.Logf(t, "Timedout waiting for CustomResourceDefinition to be established: %s", err)
+		return err
+	}
+	options.Logger.Logf(t, message)
+	return nil
+}
+
+// WaitUntilApiResourceAvailable waits until the API server serves the resources of the given kind (e.g. Application)
+// in the given group version (e.g. argoproj.io/v1alpha1, or v1 for the core group) according to its discovery API,
+// retrying the check for the specified amount of times, sleeping for the provided duration between each try. This will
+// fail the test if there is an error.
+func WaitUntilApiResourceAvailable(t testing.TestingT, options *KubectlOptions, groupVersion string, kind string, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, WaitUntilApiResourceAvailableE(t, options, groupVersion, kind, retries, sleepBetweenRetries))
+}
+
+// WaitUntilApiResourceAvailableE waits until the API server serves the resources of the given kind (e.g. Application)
+// in the given group version (e.g. argoproj.io/v1alpha1, or v1 for the core group) according to its discovery API,
+// retrying the check for the specified amount of times, sleeping for the provided duration between each try. This is
+// useful to wait for API services, such as metrics.k8s.io, and for the discovery cache of the clients, such as kubectl,
+// to pick up new CustomResourceDefinitions.
+func WaitUntilApiResourceAvailableE(t testing.TestingT, options *KubectlOptions, groupVersion string, kind string, retries int, sleepBetweenRetries time.Duration) error {
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return err
+	}
+
+	statusMsg := fmt.Sprintf("Wait for API resource %s in %s to be available.", kind, groupVersion)
+	message, err := retry.DoWithRetryE(
+		t,
+		statusMsg,
+		retries,
+		sleepBetweenRetries,
+		func() (string, error) {
+			resources, err := clientset.Discovery().ServerResourcesForGroupVersion(groupVersion)
+			if err != nil {
+				return "", err
+			}
+			resourceName, found := findApiResourceName(resources, kind)
+			if !found {
+				return "", NewApiResourceNotAvailableError(groupVersion, kind)
+			}
+			return fmt.Sprintf("API resource %s (%s) in %s is now available", kind, resourceName, groupVersion), nil
+		},
+	)
+	if err != nil {
+		options.Logger.Logf(t, "Timedout waiting for API resource to be available: %s", err)
+		return err
+	}
+	options.Logger.Logf(t, message)
+	return nil
+}
+
+// findApiResourceName returns the name of the resource of the given kind among the discovered resources, ignoring the
+// subresources (e.g. deployments/scale) which may share the kind of another resource.
+func findApiResourceName(resources *metav1.APIResourceList, kind string) (string, bool) {
+	for _, resource := range resources.APIResources {
+		if resource.Kind == kind && !strings.Contains(resource.Name, "/") {
+			return resource.Name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,83 @@
+//go:build kubeall || kubernetes
+// +build kubeall kubernetes
+
+// NOTE: we have build tags to differentiate kubernetes tests from non-kubernetes tests. This is done because minikube
+// is heavy and can interfere with docker related tests in terratest. Specifically, many of the tests start to fail with
+// `connection refused` errors from `minikube`. To avoid overloading the system, we run the kubernetes tests and helm
+// tests separately from the others. This may not be necessary if you have a sufficiently powerful machine.  We
+// recommend at least 4 cores and 16GB of RAM if you want to run all the tests together.
+
+package k8s
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/gruntwork-io/terratest/modules/random"
+)
+
+func TestWaitUntilCrdEstablishedAndApiResourceAvailable(t *testing.T) {
+	t.Parallel()
+
+	group := fmt.Sprintf("%s.terratest.gruntwork.io", strings.ToLower(random.UniqueId()))
+	crdName := "widgets." + group
+	options := NewKubectlOptions("", "", "")
+	configData := fmt.Sprintf(exampleCrdYAMLTemplate, crdName, group)
+	defer KubectlDeleteFromString(t, options, configData)
+	KubectlApplyFromString(t, options, configData)
+
+	WaitUntilCrdEstablished(t, options, crdName, 30, 1*time.Second)
+	WaitUntilApiResourceAvailable(t, options, group+"/v1", "Widget", 30, 1*time.Second)
+
+	err := WaitUntilApiResourceAvailableE(t, options, group+"/v1", "Gadget", 2, 1*time.Second)
+	require.Error(t, err)
+}
+
+func TestFindApiResourceName(t *testing.T) {
+	t.Parallel()
+
+	resources := &metav1.APIResourceList{
+		GroupVersion: "apps/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "deployments/scale", Kind: "Scale"},
+			{Name: "deployments", Kind: "Deployment"},
+			{Name: "deployments/status", Kind: "Deployment"},
+		},
+	}
+
+	name, found := findApiResourceName(resources, "Deployment")
+	assert.True(t, found)
+	assert.Equal(t, "deployments", name)
+
+	_, found = findApiResourceName(resources, "Scale")
+	assert.False(t, found)
+	assert.Equal(t, "API resource Scale is not available in apps/v1", NewApiResourceNotAvailableError("apps/v1", "Scale").Error())
+}
+
+const exampleCrdYAMLTemplate = `---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: %s
+spec:
+  group: %s
+  scope: Namespaced
+  names:
+    plural: widgets
+    singular: widget
+    kind: Widget
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        x-kubernetes-preserve-unknown-fields: true
+`
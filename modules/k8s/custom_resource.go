@@ -0,0 +1,211 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// GetCustomResource returns the custom resource of the given group, version and resource (e.g. argoproj.io, v1alpha1,
+// applications) with the given name, in the namespace of the options, or cluster wide if the namespace is empty. This
+// will fail the test if there is an error.
+func GetCustomResource(t testing.TestingT, options *KubectlOptions, gvr schema.GroupVersionResource, name string) *unstructured.Unstructured {
+	resource, err := GetCustomResourceE(t, options, gvr, name)
+	require.NoError(t, err)
+	return resource
+}
+
+// GetCustomResourceE returns the custom resource of the given group, version and resource (e.g. argoproj.io, v1alpha1,
+// applications) with the given name, in the namespace of the options, or cluster wide if the namespace is empty.
+func GetCustomResourceE(t testing.TestingT, options *KubectlOptions, gvr schema.GroupVersionResource, name string) (*unstructured.Unstructured, error) {
+	client, err := getCustomResourceClientE(t, options, gvr)
+	if err != nil {
+		return nil, err
+	}
+	return client.Get(context.Background(), name, metav1.GetOptions{})
+}
+
+// ListCustomResources will look for custom resources of the given group, version and resource that match the given
+// filters in the namespace of the options, or cluster wide if the namespace is empty, and return them. This will fail
+// the test if there is an error.
+func ListCustomResources(t testing.TestingT, options *KubectlOptions, gvr schema.GroupVersionResource, filters metav1.ListOptions) []unstructured.Unstructured {
+	resources, err := ListCustomResourcesE(t, options, gvr, filters)
+	require.NoError(t, err)
+	return resources
+}
+
+// ListCustomResourcesE will look for custom resources of the given group, version and resource that match the given
+// filters in the namespace of the options, or cluster wide if the namespace is empty, and return them.
+func ListCustomResourcesE(t testing.TestingT, options *KubectlOptions, gvr schema.GroupVersionResource, filters metav1.ListOptions) ([]unstructured.Unstructured, error) {
+	client, err := getCustomResourceClientE(t, options, gvr)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := client.List(context.Background(), filters)
+	if err != nil {
+		return nil, err
+	}
+	return resources.Items, nil
+}
+
+// WaitForCustomResourceCondition waits until the custom resource has a condition of the given type (e.g. Ready) with
+// the given status (e.g. True) in its status.conditions, retrying the check for the specified amount of times,
+// sleeping for the provided duration between each try. This will fail the test if there is an error.
+func WaitForCustomResourceCondition(
+	t testing.TestingT,
+	options *KubectlOptions,
+	gvr schema.GroupVersionResource,
+	name string,
+	conditionType string,
+	conditionStatus string,
+	retries int,
+	sleepBetweenRetries time.Duration,
+) {
+	require.NoError(t, WaitForCustomResourceConditionE(t, options, gvr, name, conditionType, conditionStatus, retries, sleepBetweenRetries))
+}
+
+// WaitForCustomResourceConditionE waits until the custom resource has a condition of the given type (e.g. Ready) with
+// the given status (e.g. True) in its status.conditions, retrying the check for the specified amount of times,
+// sleeping for the provided duration between each try.
+func WaitForCustomResourceConditionE(
+	t testing.TestingT,
+	options *KubectlOptions,
+	gvr schema.GroupVersionResource,
+	name string,
+	conditionType string,
+	conditionStatus string,
+	retries int,
+	sleepBetweenRetries time.Duration,
+) error {
+	statusMsg := fmt.Sprintf("Wait for %s %s to have condition %s=%s.", gvr.Resource, name, conditionType, conditionStatus)
+	message, err := retry.DoWithRetryE(
+		t,
+		statusMsg,
+		retries,
+		sleepBetweenRetries,
+		func() (string, error) {
+			resource, err := GetCustomResourceE(t, options, gvr, name)
+			if err != nil {
+				return "", err
+			}
+			if !IsCustomResourceConditionStatus(resource, conditionType, conditionStatus) {
+				return "", NewCustomResourceConditionNotMetError(resource, conditionType, conditionStatus)
+			}
+			return fmt.Sprintf("%s %s now has condition %s=%s", gvr.Resource, name, conditionType, conditionStatus), nil
+		},
+	)
+	if err != nil {
+		options.Logger.Logf(t, "Timedout waiting for %s %s condition: %s", gvr.Resource, name, err)
+		return err
+	}
+	options.Logger.Logf(t, message)
+	return nil
+}
+
+// WaitForCustomResourceField waits until the field of the custom resource at the given dot separated path (e.g.
+// status.health.status for an ArgoCD application) has the expected value, retrying the check for the specified amount
+// of times, sleeping for the provided duration between each try. This will fail the test if there is an error.
+func WaitForCustomResourceField(
+	t testing.TestingT,
+	options *KubectlOptions,
+	gvr schema.GroupVersionResource,
+	name string,
+	fieldPath string,
+	expectedValue string,
+	retries int,
+	sleepBetweenRetries time.Duration,
+) {
+	require.NoError(t, WaitForCustomResourceFieldE(t, options, gvr, name, fieldPath, expectedValue, retries, sleepBetweenRetries))
+}
+
+// WaitForCustomResourceFieldE waits until the field of the custom resource at the given dot separated path (e.g.
+// status.health.status for an ArgoCD application) has the expected value, retrying the check for the specified amount
+// of times, sleeping for the provided duration between each try. Values that aren't strings are compared using their
+// default format (e.g. true, 3).
+func WaitForCustomResourceFieldE(
+	t testing.TestingT,
+	options *KubectlOptions,
+	gvr schema.GroupVersionResource,
+	name string,
+	fieldPath string,
+	expectedValue string,
+	retries int,
+	sleepBetweenRetries time.Duration,
+) error {
+	statusMsg := fmt.Sprintf("Wait for %s %s to have %s=%s.", gvr.Resource, name, fieldPath, expectedValue)
+	message, err := retry.DoWithRetryE(
+		t,
+		statusMsg,
+		retries,
+		sleepBetweenRetries,
+		func() (string, error) {
+			resource, err := GetCustomResourceE(t, options, gvr, name)
+			if err != nil {
+				return "", err
+			}
+			value, found, err := getCustomResourceField(resource, fieldPath)
+			if err != nil {
+				return "", err
+			}
+			if !found || value != expectedValue {
+				return "", NewCustomResourceFieldNotMatchedError(resource, fieldPath, expectedValue, value, found)
+			}
+			return fmt.Sprintf("%s %s now has %s=%s", gvr.Resource, name, fieldPath, expectedValue), nil
+		},
+	)
+	if err != nil {
+		options.Logger.Logf(t, "Timedout waiting for %s %s field: %s", gvr.Resource, name, err)
+		return err
+	}
+	options.Logger.Logf(t, message)
+	return nil
+}
+
+// IsCustomResourceConditionStatus returns true if the custom resource has a condition of the given type with the given
+// status in its status.conditions, which is the convention followed by most operators.
+func IsCustomResourceConditionStatus(resource *unstructured.Unstructured, conditionType string, conditionStatus string) bool {
+	condition := getCustomResourceCondition(resource, conditionType)
+	return condition != nil && fmt.Sprint(condition["status"]) == conditionStatus
+}
+
+func getCustomResourceClientE(t testing.TestingT, options *KubectlOptions, gvr schema.GroupVersionResource) (dynamic.ResourceInterface, error) {
+	client, err := GetDynamicClientFromOptionsE(t, options)
+	if err != nil {
+		return nil, err
+	}
+	if options.Namespace == "" {
+		return client.Resource(gvr), nil
+	}
+	return client.Resource(gvr).Namespace(options.Namespace), nil
+}
+
+func getCustomResourceCondition(resource *unstructured.Unstructured, conditionType string) map[string]interface{} {
+	conditions, _, _ := unstructured.NestedSlice(resource.Object, "status", "conditions")
+	for _, item := range conditions {
+		condition, ok := item.(map[string]interface{})
+		if ok && condition["type"] == conditionType {
+			return condition
+		}
+	}
+	return nil
+}
+
+// getCustomResourceField returns the value of the field at the given dot separated path, formatted as a string, and
+// whether it was found.
+func getCustomResourceField(resource *unstructured.Unstructured, fieldPath string) (string, bool, error) {
+	value, found, err := unstructured.NestedFieldNoCopy(resource.Object, strings.Split(fieldPath, ".")...)
+	if err != nil || !found {
+		return "", found, err
+	}
+	return fmt.Sprint(value), true, nil
+}
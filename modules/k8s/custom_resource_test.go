@@ -0,0 +1,77 @@
+//go:build kubeall || kubernetes
+// +build kubeall kubernetes
+
+// NOTE: we have build tags to differentiate kubernetes tests from non-kubernetes tests. This is done because minikube
+// is heavy and can interfere with docker related tests in terratest. Specifically, many of the tests start to fail with
+// `connection refused` errors from `minikube`. To avoid overloading the system, we run the kubernetes tests and helm
+// tests separately from the others. This may not be necessary if you have a sufficiently powerful machine.  We
+// recommend at least 4 cores and 16GB of RAM if you want to run all the tests together.
+
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestGetCustomResourceEReturnsError(t *testing.T) {
+	t.Parallel()
+
+	options := NewKubectlOptions("", "", "")
+	gvr := schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "applications"}
+	_, err := GetCustomResourceE(t, options, gvr, "guestbook")
+	require.Error(t, err)
+}
+
+func TestIsCustomResourceConditionStatus(t *testing.T) {
+	t.Parallel()
+
+	resource := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind":     "PostgreSQLInstance",
+		"metadata": map[string]interface{}{"name": "db"},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Synced", "status": "True"},
+				map[string]interface{}{"type": "Ready", "status": "False", "reason": "Creating"},
+			},
+		},
+	}}
+
+	assert.True(t, IsCustomResourceConditionStatus(resource, "Synced", "True"))
+	assert.False(t, IsCustomResourceConditionStatus(resource, "Ready", "True"))
+	assert.True(t, IsCustomResourceConditionStatus(resource, "Ready", "False"))
+	assert.False(t, IsCustomResourceConditionStatus(resource, "Missing", "True"))
+	assert.Contains(t, NewCustomResourceConditionNotMetError(resource, "Ready", "True").Error(), "reason: Creating")
+}
+
+func TestGetCustomResourceField(t *testing.T) {
+	t.Parallel()
+
+	resource := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"health":   map[string]interface{}{"status": "Healthy"},
+			"replicas": int64(3),
+		},
+	}}
+
+	value, found, err := getCustomResourceField(resource, "status.health.status")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "Healthy", value)
+
+	value, found, err = getCustomResourceField(resource, "status.replicas")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "3", value)
+
+	_, found, err = getCustomResourceField(resource, "status.sync.status")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	_, _, err = getCustomResourceField(resource, "status.replicas.value")
+	require.Error(t, err)
+}
@@ -2,11 +2,14 @@ package k8s
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/gruntwork-io/terratest/modules/retry"
 	"github.com/gruntwork-io/terratest/modules/testing"
 )
 
@@ -47,3 +50,63 @@ func GetDaemonSetE(t testing.TestingT, options *KubectlOptions, daemonSetName st
 	}
 	return clientset.AppsV1().DaemonSets(options.Namespace).Get(context.Background(), daemonSetName, metav1.GetOptions{})
 }
+
+// WaitUntilDaemonSetReady waits until the pods of the daemonset are scheduled on all the eligible nodes, ready and
+// running the updated template, retrying the check for the specified amount of times, sleeping for the provided
+// duration between each try. This will fail the test if there is an error.
+func WaitUntilDaemonSetReady(t testing.TestingT, options *KubectlOptions, daemonSetName string, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, WaitUntilDaemonSetReadyE(t, options, daemonSetName, retries, sleepBetweenRetries))
+}
+
+// WaitUntilDaemonSetReadyE waits until the pods of the daemonset are scheduled on all the eligible nodes, ready and
+// running the updated template, retrying the check for the specified amount of times, sleeping for the provided
+// duration between each try.
+func WaitUntilDaemonSetReadyE(
+	t testing.TestingT,
+	options *KubectlOptions,
+	daemonSetName string,
+	retries int,
+	sleepBetweenRetries time.Duration,
+) error {
+	statusMsg := fmt.Sprintf("Wait for daemonset %s to be provisioned.", daemonSetName)
+	message, err := retry.DoWithRetryE(
+		t,
+		statusMsg,
+		retries,
+		sleepBetweenRetries,
+		func() (string, error) {
+			daemonSet, err := GetDaemonSetE(t, options, daemonSetName)
+			if err != nil {
+				return "", err
+			}
+			if !IsDaemonSetReady(daemonSet) {
+				return "", NewDaemonSetNotReadyError(daemonSet)
+			}
+			return "DaemonSet is now ready", nil
+		},
+	)
+	if err != nil {
+		options.Logger.Logf(t, "Timedout waiting for DaemonSet to be provisioned: %s", err)
+		return err
+	}
+	options.Logger.Logf(t, message)
+	return nil
+}
+
+// IsDaemonSetReady returns true if the controller observed the latest spec of the daemonset, and a ready pod running
+// the updated template is scheduled on each of the eligible nodes, and on no other node. With the OnDelete update
+// strategy, the template of the pods isn't checked.
+func IsDaemonSetReady(daemonSet *appsv1.DaemonSet) bool {
+	status := daemonSet.Status
+	if status.ObservedGeneration < daemonSet.Generation ||
+		status.CurrentNumberScheduled != status.DesiredNumberScheduled ||
+		status.NumberMisscheduled != 0 ||
+		status.NumberReady != status.DesiredNumberScheduled ||
+		status.NumberAvailable != status.DesiredNumberScheduled {
+		return false
+	}
+	if daemonSet.Spec.UpdateStrategy.Type == appsv1.OnDeleteDaemonSetStrategyType {
+		return true
+	}
+	return status.UpdatedNumberScheduled == status.DesiredNumberScheduled
+}
@@ -15,9 +15,11 @@ import (
 
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gruntwork-io/terratest/modules/random"
 	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
 )
 
 func TestGetDaemonSetEReturnsErrorForNonExistantDaemonSet(t *testing.T) {
@@ -59,6 +61,81 @@ func TestListDaemonSetsReturnsCorrectServiceInCorrectNamespace(t *testing.T) {
 	require.Equal(t, daemonSet.Namespace, uniqueID)
 }
 
+func TestWaitUntilDaemonSetReady(t *testing.T) {
+	t.Parallel()
+
+	uniqueID := strings.ToLower(random.UniqueId())
+	options := NewKubectlOptions("", "", uniqueID)
+	configData := fmt.Sprintf(EXAMPLE_DAEMONSET_YAML_TEMPLATE, uniqueID, uniqueID)
+	KubectlApplyFromString(t, options, configData)
+	defer KubectlDeleteFromString(t, options, configData)
+
+	WaitUntilDaemonSetReady(t, options, "sample-ds", 60, 1*time.Second)
+}
+
+func TestIsDaemonSetReady(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title          string
+		daemonSet      *appsv1.DaemonSet
+		expectedResult bool
+	}{
+		{
+			title: "ReadyOnAllNodes",
+			daemonSet: &appsv1.DaemonSet{
+				Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, CurrentNumberScheduled: 3, NumberReady: 3, NumberAvailable: 3, UpdatedNumberScheduled: 3},
+			},
+			expectedResult: true,
+		},
+		{
+			title: "NotScheduledOnAllNodes",
+			daemonSet: &appsv1.DaemonSet{
+				Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, CurrentNumberScheduled: 2, NumberReady: 2, NumberAvailable: 2, UpdatedNumberScheduled: 2},
+			},
+			expectedResult: false,
+		},
+		{
+			title: "Misscheduled",
+			daemonSet: &appsv1.DaemonSet{
+				Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, CurrentNumberScheduled: 3, NumberMisscheduled: 1, NumberReady: 3, NumberAvailable: 3, UpdatedNumberScheduled: 3},
+			},
+			expectedResult: false,
+		},
+		{
+			title: "RollingUpdateInProgress",
+			daemonSet: &appsv1.DaemonSet{
+				Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, CurrentNumberScheduled: 3, NumberReady: 3, NumberAvailable: 3, UpdatedNumberScheduled: 1},
+			},
+			expectedResult: false,
+		},
+		{
+			title: "OnDeleteStrategy",
+			daemonSet: &appsv1.DaemonSet{
+				Spec:   appsv1.DaemonSetSpec{UpdateStrategy: appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType}},
+				Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, CurrentNumberScheduled: 3, NumberReady: 3, NumberAvailable: 3, UpdatedNumberScheduled: 1},
+			},
+			expectedResult: true,
+		},
+		{
+			title: "SpecNotObserved",
+			daemonSet: &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Status:     appsv1.DaemonSetStatus{ObservedGeneration: 1, DesiredNumberScheduled: 3, CurrentNumberScheduled: 3, NumberReady: 3, NumberAvailable: 3, UpdatedNumberScheduled: 3},
+			},
+			expectedResult: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.expectedResult, IsDaemonSetReady(tc.daemonSet))
+		})
+	}
+}
+
 const EXAMPLE_DAEMONSET_YAML_TEMPLATE = `---
 apiVersion: v1
 kind: Namespace
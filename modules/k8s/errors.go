@@ -2,13 +2,18 @@ package k8s
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 // IngressNotAvailable is returned when a Kubernetes service is not yet available to accept traffic.
@@ -92,6 +97,60 @@ func NewDeploymentNotAvailableError(deploy *appsv1.Deployment) DeploymentNotAvai
 	return DeploymentNotAvailable{deploy}
 }
 
+// StatefulSetNotAvailable is returned when the pods of a Kubernetes statefulset are not yet all ready and updated.
+type StatefulSetNotAvailable struct {
+	statefulSet *appsv1.StatefulSet
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err StatefulSetNotAvailable) Error() string {
+	status := err.statefulSet.Status
+	return fmt.Sprintf(
+		"StatefulSet %s is not available, desired replicas: %d, ready replicas: %d, updated replicas: %d, current revision: %s, update revision: %s, observed generation: %d of %d",
+		err.statefulSet.Name,
+		getStatefulSetDesiredReplicas(err.statefulSet),
+		status.ReadyReplicas,
+		status.UpdatedReplicas,
+		status.CurrentRevision,
+		status.UpdateRevision,
+		status.ObservedGeneration,
+		err.statefulSet.Generation,
+	)
+}
+
+// NewStatefulSetNotAvailableError returns a StatefulSetNotAvailable struct when Kubernetes deems a statefulset is not available
+func NewStatefulSetNotAvailableError(statefulSet *appsv1.StatefulSet) StatefulSetNotAvailable {
+	return StatefulSetNotAvailable{statefulSet}
+}
+
+// DaemonSetNotReady is returned when the pods of a Kubernetes daemonset are not yet ready and updated on all the
+// eligible nodes.
+type DaemonSetNotReady struct {
+	daemonSet *appsv1.DaemonSet
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err DaemonSetNotReady) Error() string {
+	status := err.daemonSet.Status
+	return fmt.Sprintf(
+		"DaemonSet %s is not ready, desired: %d, scheduled: %d, misscheduled: %d, ready: %d, available: %d, updated: %d, observed generation: %d of %d",
+		err.daemonSet.Name,
+		status.DesiredNumberScheduled,
+		status.CurrentNumberScheduled,
+		status.NumberMisscheduled,
+		status.NumberReady,
+		status.NumberAvailable,
+		status.UpdatedNumberScheduled,
+		status.ObservedGeneration,
+		err.daemonSet.Generation,
+	)
+}
+
+// NewDaemonSetNotReadyError returns a DaemonSetNotReady struct when Kubernetes deems a daemonset is not ready
+func NewDaemonSetNotReadyError(daemonSet *appsv1.DaemonSet) DaemonSetNotReady {
+	return DaemonSetNotReady{daemonSet}
+}
+
 // PodNotAvailable is returned when a Kubernetes service is not yet available to accept traffic.
 type PodNotAvailable struct {
 	pod *corev1.Pod
@@ -296,3 +355,244 @@ func (err CronJobNotSucceeded) Error() string {
 func NewCronJobNotSucceeded(cronJob *batchv1.CronJob) CronJobNotSucceeded {
 	return CronJobNotSucceeded{cronJob}
 }
+
+// CustomResourceConditionNotMet is returned when a custom resource doesn't have the expected condition.
+type CustomResourceConditionNotMet struct {
+	resource        *unstructured.Unstructured
+	conditionType   string
+	conditionStatus string
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err CustomResourceConditionNotMet) Error() string {
+	condition := getCustomResourceCondition(err.resource, err.conditionType)
+	if condition == nil {
+		return fmt.Sprintf("%s %s is missing condition %s", err.resource.GetKind(), err.resource.GetName(), err.conditionType)
+	}
+	return fmt.Sprintf(
+		"%s %s has condition %s with status %v, expected %s, reason: %v, message: %v",
+		err.resource.GetKind(),
+		err.resource.GetName(),
+		err.conditionType,
+		condition["status"],
+		err.conditionStatus,
+		condition["reason"],
+		condition["message"],
+	)
+}
+
+// NewCustomResourceConditionNotMetError returns a CustomResourceConditionNotMet when a custom resource doesn't have the
+// given condition with the given status
+func NewCustomResourceConditionNotMetError(resource *unstructured.Unstructured, conditionType string, conditionStatus string) CustomResourceConditionNotMet {
+	return CustomResourceConditionNotMet{resource, conditionType, conditionStatus}
+}
+
+// CustomResourceFieldNotMatched is returned when a field of a custom resource doesn't have the expected value.
+type CustomResourceFieldNotMatched struct {
+	resource      *unstructured.Unstructured
+	fieldPath     string
+	expectedValue string
+	actualValue   string
+	found         bool
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err CustomResourceFieldNotMatched) Error() string {
+	if !err.found {
+		return fmt.Sprintf("%s %s has no field %s", err.resource.GetKind(), err.resource.GetName(), err.fieldPath)
+	}
+	return fmt.Sprintf("%s %s has %s=%s, expected %s", err.resource.GetKind(), err.resource.GetName(), err.fieldPath, err.actualValue, err.expectedValue)
+}
+
+// NewCustomResourceFieldNotMatchedError returns a CustomResourceFieldNotMatched when a field of a custom resource
+// doesn't have the expected value
+func NewCustomResourceFieldNotMatchedError(resource *unstructured.Unstructured, fieldPath string, expectedValue string, actualValue string, found bool) CustomResourceFieldNotMatched {
+	return CustomResourceFieldNotMatched{resource, fieldPath, expectedValue, actualValue, found}
+}
+
+// EventNotFound is returned when there is no Kubernetes event about an object with the expected reason.
+type EventNotFound struct {
+	involvedObject corev1.ObjectReference
+	reasonRegex    string
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err EventNotFound) Error() string {
+	return fmt.Sprintf("No event with reason matching %s about %s %s", err.reasonRegex, err.involvedObject.Kind, err.involvedObject.Name)
+}
+
+// NewEventNotFoundError returns an EventNotFound when there is no event about the object with a reason matching the
+// regular expression
+func NewEventNotFoundError(involvedObject corev1.ObjectReference, reasonRegex string) EventNotFound {
+	return EventNotFound{involvedObject, reasonRegex}
+}
+
+// PodLogMessageNotFound is returned when no line of the logs of the Pods matches the expected message before the timeout.
+type PodLogMessageNotFound struct {
+	podSelector  string
+	messageRegex string
+	timeout      time.Duration
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err PodLogMessageNotFound) Error() string {
+	return fmt.Sprintf("No log line matching %s found in pods %s after %s", err.messageRegex, err.podSelector, err.timeout)
+}
+
+// NewPodLogMessageNotFoundError returns a PodLogMessageNotFound when no line of the logs of the Pods matches the
+// message before the timeout
+func NewPodLogMessageNotFoundError(podSelector string, messageRegex string, timeout time.Duration) PodLogMessageNotFound {
+	return PodLogMessageNotFound{podSelector, messageRegex, timeout}
+}
+
+// ServerSideApplyConflicts is returned when a server-side apply fails because some of the applied fields are owned by
+// other field managers.
+type ServerSideApplyConflicts struct {
+	Conflicts []string
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err ServerSideApplyConflicts) Error() string {
+	return fmt.Sprintf("Server-side apply failed with %d conflicts: %s", len(err.Conflicts), strings.Join(err.Conflicts, "; "))
+}
+
+// NewServerSideApplyConflictsError returns a ServerSideApplyConflicts when a server-side apply fails with conflicts
+func NewServerSideApplyConflictsError(conflicts []string) ServerSideApplyConflicts {
+	return ServerSideApplyConflicts{conflicts}
+}
+
+// HpaNotScaled is returned when a Kubernetes HorizontalPodAutoscaler didn't scale its target to the expected number of
+// replicas.
+type HpaNotScaled struct {
+	hpa            *autoscalingv2.HorizontalPodAutoscaler
+	targetReplicas int32
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err HpaNotScaled) Error() string {
+	return fmt.Sprintf(
+		"HorizontalPodAutoscaler %s has %d replicas and wants %d, expected %d",
+		err.hpa.Name,
+		err.hpa.Status.CurrentReplicas,
+		err.hpa.Status.DesiredReplicas,
+		err.targetReplicas,
+	)
+}
+
+// NewHpaNotScaledError returns a HpaNotScaled when a HorizontalPodAutoscaler didn't scale to the target replicas
+func NewHpaNotScaledError(hpa *autoscalingv2.HorizontalPodAutoscaler, targetReplicas int32) HpaNotScaled {
+	return HpaNotScaled{hpa, targetReplicas}
+}
+
+// ApiResourceNotAvailable is returned when the Kubernetes API server doesn't serve the resources of a kind.
+type ApiResourceNotAvailable struct {
+	groupVersion string
+	kind         string
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err ApiResourceNotAvailable) Error() string {
+	return fmt.Sprintf("API resource %s is not available in %s", err.kind, err.groupVersion)
+}
+
+// NewApiResourceNotAvailableError returns a ApiResourceNotAvailable when the API resource of a kind is not served
+func NewApiResourceNotAvailableError(groupVersion string, kind string) ApiResourceNotAvailable {
+	return ApiResourceNotAvailable{groupVersion, kind}
+}
+
+// GatewayNotProgrammed is returned when a Gateway API Gateway is not programmed, or has no address.
+type GatewayNotProgrammed struct {
+	gateway *Gateway
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err GatewayNotProgrammed) Error() string {
+	condition := meta.FindStatusCondition(err.gateway.Status.Conditions, "Programmed")
+	if condition == nil {
+		return fmt.Sprintf("Gateway %s is not programmed yet", err.gateway.Name)
+	}
+	return fmt.Sprintf(
+		"Gateway %s is not programmed (addresses: %d), status: %s, reason: %s, message: %s",
+		err.gateway.Name,
+		len(err.gateway.Status.Addresses),
+		condition.Status,
+		condition.Reason,
+		condition.Message,
+	)
+}
+
+// NewGatewayNotProgrammedError returns a GatewayNotProgrammed when a Gateway is not programmed
+func NewGatewayNotProgrammedError(gateway *Gateway) GatewayNotProgrammed {
+	return GatewayNotProgrammed{gateway}
+}
+
+// HTTPRouteNotAccepted is returned when a Gateway API HTTPRoute is not accepted by its parents, or its backends are not
+// resolved.
+type HTTPRouteNotAccepted struct {
+	route  *HTTPRoute
+	reason string
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err HTTPRouteNotAccepted) Error() string {
+	return fmt.Sprintf("HTTPRoute %s is not accepted: %s", err.route.Name, err.reason)
+}
+
+// NewHTTPRouteNotAcceptedError returns a HTTPRouteNotAccepted when a HTTPRoute is not accepted for the given reason
+func NewHTTPRouteNotAcceptedError(route *HTTPRoute, reason string) HTTPRouteNotAccepted {
+	return HTTPRouteNotAccepted{route, reason}
+}
+
+// CertificateNotReady is returned when a cert-manager Certificate is not ready.
+type CertificateNotReady struct {
+	certificate *Certificate
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err CertificateNotReady) Error() string {
+	condition := meta.FindStatusCondition(err.certificate.Status.Conditions, "Ready")
+	if condition == nil {
+		return fmt.Sprintf("Certificate %s is not ready yet", err.certificate.Name)
+	}
+	return fmt.Sprintf(
+		"Certificate %s is not ready, status: %s, reason: %s, message: %s",
+		err.certificate.Name,
+		condition.Status,
+		condition.Reason,
+		condition.Message,
+	)
+}
+
+// NewCertificateNotReadyError returns a CertificateNotReady when a Certificate is not ready
+func NewCertificateNotReadyError(certificate *Certificate) CertificateNotReady {
+	return CertificateNotReady{certificate}
+}
+
+// NamespaceNotDeleted is returned when a Kubernetes namespace is not deleted in time, with the resources blocking its
+// deletion.
+type NamespaceNotDeleted struct {
+	namespace      *corev1.Namespace
+	stuckResources []string
+	listErrors     []string
+}
+
+// Error is a simple function to return a formatted error message as a string
+func (err NamespaceNotDeleted) Error() string {
+	message := fmt.Sprintf("Namespace %s is not deleted, phase: %s", err.namespace.Name, err.namespace.Status.Phase)
+	if blockers := getNamespaceDeletionBlockers(err.namespace); len(blockers) > 0 {
+		message = fmt.Sprintf("%s, conditions: %s", message, strings.Join(blockers, "; "))
+	}
+	if len(err.stuckResources) > 0 {
+		message = fmt.Sprintf("%s, resources with finalizers: %s", message, strings.Join(err.stuckResources, "; "))
+	}
+	if len(err.listErrors) > 0 {
+		message = fmt.Sprintf("%s, resources that could not be listed: %s", message, strings.Join(err.listErrors, "; "))
+	}
+	return message
+}
+
+// NewNamespaceNotDeletedError returns a NamespaceNotDeleted when a namespace is not deleted, with the given resources
+// stuck on finalizers and the errors listing the resource types that could not be checked
+func NewNamespaceNotDeletedError(namespace *corev1.Namespace, stuckResources []string, listErrors []string) NamespaceNotDeleted {
+	return NamespaceNotDeleted{namespace, stuckResources, listErrors}
+}
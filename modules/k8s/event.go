@@ -2,15 +2,23 @@ package k8s
 
 import (
 	"context"
+	"fmt"
+	"regexp"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 
+	"github.com/gruntwork-io/terratest/modules/retry"
 	"github.com/gruntwork-io/terratest/modules/testing"
 )
 
+// eventPollInterval is the time between the checks for new events when waiting for an event.
+const eventPollInterval = 2 * time.Second
+
 // ListEvents will retrieve the Events in the given namespace that match the given filters and return them. This will fail the
 // test if there is an error.
 func ListEvents(t testing.TestingT, options *KubectlOptions, filters metav1.ListOptions) []corev1.Event {
@@ -32,3 +40,84 @@ func ListEventsE(t testing.TestingT, options *KubectlOptions, filters metav1.Lis
 	}
 	return resp.Items, nil
 }
+
+// ListEventsForObject will retrieve the Events in the given namespace about the given object, identified by its kind
+// and name, and its UID if set, and return them. This will fail the test if there is an error.
+func ListEventsForObject(t testing.TestingT, options *KubectlOptions, involvedObject corev1.ObjectReference) []corev1.Event {
+	events, err := ListEventsForObjectE(t, options, involvedObject)
+	require.NoError(t, err)
+	return events
+}
+
+// ListEventsForObjectE will retrieve the Events in the given namespace about the given object, identified by its kind
+// and name, and its UID if set, and return them.
+func ListEventsForObjectE(t testing.TestingT, options *KubectlOptions, involvedObject corev1.ObjectReference) ([]corev1.Event, error) {
+	return ListEventsE(t, options, metav1.ListOptions{FieldSelector: getInvolvedObjectFieldSelector(involvedObject)})
+}
+
+// WaitForEvent waits until an Event about the given object has a reason matching the given regular expression (e.g.
+// FailedScheduling or ^(Unhealthy|BackOff)$), checking the events until the timeout expires, and returns it. This will
+// fail the test if there is an error.
+func WaitForEvent(t testing.TestingT, options *KubectlOptions, involvedObject corev1.ObjectReference, reasonRegex string, timeout time.Duration) *corev1.Event {
+	event, err := WaitForEventE(t, options, involvedObject, reasonRegex, timeout)
+	require.NoError(t, err)
+	return event
+}
+
+// WaitForEventE waits until an Event about the given object has a reason matching the given regular expression (e.g.
+// FailedScheduling or ^(Unhealthy|BackOff)$), checking the events until the timeout expires, and returns it.
+func WaitForEventE(t testing.TestingT, options *KubectlOptions, involvedObject corev1.ObjectReference, reasonRegex string, timeout time.Duration) (*corev1.Event, error) {
+	reason, err := regexp.Compile(reasonRegex)
+	if err != nil {
+		return nil, err
+	}
+
+	var event *corev1.Event
+	statusMsg := fmt.Sprintf("Wait for event with reason %s about %s %s.", reasonRegex, involvedObject.Kind, involvedObject.Name)
+	retries := int(timeout/eventPollInterval) + 1
+	message, err := retry.DoWithRetryE(
+		t,
+		statusMsg,
+		retries,
+		eventPollInterval,
+		func() (string, error) {
+			events, err := ListEventsForObjectE(t, options, involvedObject)
+			if err != nil {
+				return "", err
+			}
+			event = findEventByReason(events, reason)
+			if event == nil {
+				return "", NewEventNotFoundError(involvedObject, reasonRegex)
+			}
+			return fmt.Sprintf("Found event %s: %s", event.Reason, event.Message), nil
+		},
+	)
+	if err != nil {
+		options.Logger.Logf(t, "Timedout waiting for event: %s", err)
+		return nil, err
+	}
+	options.Logger.Logf(t, message)
+	return event, nil
+}
+
+// getInvolvedObjectFieldSelector returns the field selector matching the events about the given object.
+func getInvolvedObjectFieldSelector(involvedObject corev1.ObjectReference) string {
+	selector := fields.Set{
+		"involvedObject.kind": involvedObject.Kind,
+		"involvedObject.name": involvedObject.Name,
+	}
+	if involvedObject.UID != "" {
+		selector["involvedObject.uid"] = string(involvedObject.UID)
+	}
+	return selector.AsSelector().String()
+}
+
+// findEventByReason returns the first of the events with a reason matching the given regular expression, or nil.
+func findEventByReason(events []corev1.Event, reason *regexp.Regexp) *corev1.Event {
+	for i := range events {
+		if reason.MatchString(events[i].Reason) {
+			return &events[i]
+		}
+	}
+	return nil
+}
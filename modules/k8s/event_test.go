@@ -10,10 +10,16 @@
 package k8s
 
 import (
+	"fmt"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/gruntwork-io/terratest/modules/random"
 	"github.com/stretchr/testify/require"
 
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 )
@@ -48,3 +54,49 @@ func TestListEventsReturnsZeroEventsIfNoneCreated(t *testing.T) {
 	events := ListEvents(t, options, v1.ListOptions{})
 	require.Equal(t, 0, len(events))
 }
+
+func TestWaitForEventAboutPod(t *testing.T) {
+	t.Parallel()
+
+	uniqueID := strings.ToLower(random.UniqueId())
+	options := NewKubectlOptions("", "", uniqueID)
+	configData := fmt.Sprintf(EXAMPLE_POD_YAML_TEMPLATE, uniqueID, uniqueID)
+	defer KubectlDeleteFromString(t, options, configData)
+	KubectlApplyFromString(t, options, configData)
+
+	pod := corev1.ObjectReference{Kind: "Pod", Name: "nginx-pod"}
+	event := WaitForEvent(t, options, pod, "^(Pulled|Started)$", 60*time.Second)
+	require.Equal(t, "nginx-pod", event.InvolvedObject.Name)
+
+	events := ListEventsForObject(t, options, pod)
+	require.Greater(t, len(events), 0)
+
+	_, err := WaitForEventE(t, options, pod, "^FailedScheduling$", 5*time.Second)
+	require.Error(t, err)
+}
+
+func TestFindEventByReason(t *testing.T) {
+	t.Parallel()
+
+	events := []corev1.Event{
+		{Reason: "Scheduled"},
+		{Reason: "Unhealthy", Message: "Readiness probe failed"},
+		{Reason: "BackOff"},
+	}
+
+	event := findEventByReason(events, regexp.MustCompile("^(Unhealthy|BackOff)$"))
+	require.NotNil(t, event)
+	require.Equal(t, "Readiness probe failed", event.Message)
+	require.Nil(t, findEventByReason(events, regexp.MustCompile("FailedScheduling")))
+}
+
+func TestGetInvolvedObjectFieldSelector(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "involvedObject.kind=Pod,involvedObject.name=web", getInvolvedObjectFieldSelector(corev1.ObjectReference{Kind: "Pod", Name: "web"}))
+	require.Equal(
+		t,
+		"involvedObject.kind=Deployment,involvedObject.name=web,involvedObject.uid=1234",
+		getInvolvedObjectFieldSelector(corev1.ObjectReference{Kind: "Deployment", Name: "web", UID: "1234"}),
+	)
+}
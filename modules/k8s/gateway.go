@@ -0,0 +1,476 @@
+package k8s
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// The group, version and resources of the Gateway API, which are read with the dynamic client.
+var (
+	gatewayResource   = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "gateways"}
+	httpRouteResource = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"}
+)
+
+// Gateway is a gateway.networking.k8s.io Gateway, with the fields used to check it.
+type Gateway struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GatewaySpec   `json:"spec"`
+	Status GatewayStatus `json:"status,omitempty"`
+}
+
+// GatewaySpec is the specification of a Gateway.
+type GatewaySpec struct {
+	GatewayClassName string            `json:"gatewayClassName"`
+	Listeners        []GatewayListener `json:"listeners"`
+}
+
+// GatewayListener is a listener of a Gateway. The hostname is empty if the listener matches all the hosts.
+type GatewayListener struct {
+	Name     string `json:"name"`
+	Hostname string `json:"hostname,omitempty"`
+	Port     int32  `json:"port"`
+	Protocol string `json:"protocol"`
+}
+
+// GatewayStatus is the status of a Gateway.
+type GatewayStatus struct {
+	Addresses  []GatewayStatusAddress  `json:"addresses,omitempty"`
+	Conditions []metav1.Condition      `json:"conditions,omitempty"`
+	Listeners  []GatewayListenerStatus `json:"listeners,omitempty"`
+}
+
+// GatewayStatusAddress is an address provisioned for a Gateway, whose type is IPAddress or Hostname.
+type GatewayStatusAddress struct {
+	Type  string `json:"type,omitempty"`
+	Value string `json:"value"`
+}
+
+// GatewayListenerStatus is the status of a listener of a Gateway.
+type GatewayListenerStatus struct {
+	Name           string             `json:"name"`
+	AttachedRoutes int32              `json:"attachedRoutes"`
+	Conditions     []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// HTTPRoute is a gateway.networking.k8s.io HTTPRoute, with the fields used to check it.
+type HTTPRoute struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HTTPRouteSpec   `json:"spec"`
+	Status HTTPRouteStatus `json:"status,omitempty"`
+}
+
+// HTTPRouteSpec is the specification of an HTTPRoute.
+type HTTPRouteSpec struct {
+	ParentRefs []GatewayParentReference `json:"parentRefs,omitempty"`
+	Hostnames  []string                 `json:"hostnames,omitempty"`
+	Rules      []HTTPRouteRule          `json:"rules,omitempty"`
+}
+
+// GatewayParentReference is a reference from a route to the Gateway, or the listener of a Gateway, it attaches to. An
+// empty namespace is the namespace of the route.
+type GatewayParentReference struct {
+	Group       string `json:"group,omitempty"`
+	Kind        string `json:"kind,omitempty"`
+	Namespace   string `json:"namespace,omitempty"`
+	Name        string `json:"name"`
+	SectionName string `json:"sectionName,omitempty"`
+}
+
+// HTTPRouteRule is a rule of an HTTPRoute, routing the matching requests to its backends. A rule without match
+// matches all the requests.
+type HTTPRouteRule struct {
+	Matches     []HTTPRouteMatch `json:"matches,omitempty"`
+	BackendRefs []HTTPBackendRef `json:"backendRefs,omitempty"`
+}
+
+// HTTPRouteMatch is a match of a rule of an HTTPRoute.
+type HTTPRouteMatch struct {
+	Path *HTTPPathMatch `json:"path,omitempty"`
+}
+
+// HTTPPathMatch is a path match of an HTTPRoute, whose type is Exact, PathPrefix or RegularExpression.
+type HTTPPathMatch struct {
+	Type  string `json:"type,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+// HTTPBackendRef is a backend of a rule of an HTTPRoute, usually a Service.
+type HTTPBackendRef struct {
+	Kind      string `json:"kind,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Port      int32  `json:"port,omitempty"`
+	Weight    *int32 `json:"weight,omitempty"`
+}
+
+// HTTPRouteStatus is the status of an HTTPRoute, with the status of the route for each of its parents.
+type HTTPRouteStatus struct {
+	Parents []RouteParentStatus `json:"parents,omitempty"`
+}
+
+// RouteParentStatus is the status of a route for one of its parents, as reported by the controller of the Gateway.
+type RouteParentStatus struct {
+	ParentRef      GatewayParentReference `json:"parentRef"`
+	ControllerName string                 `json:"controllerName"`
+	Conditions     []metav1.Condition     `json:"conditions,omitempty"`
+}
+
+// GetGateway returns the Gateway resource in the namespace of the options with the given name. This will fail the test
+// if there is an error.
+func GetGateway(t testing.TestingT, options *KubectlOptions, gatewayName string) *Gateway {
+	gateway, err := GetGatewayE(t, options, gatewayName)
+	require.NoError(t, err)
+	return gateway
+}
+
+// GetGatewayE returns the Gateway resource in the namespace of the options with the given name.
+func GetGatewayE(t testing.TestingT, options *KubectlOptions, gatewayName string) (*Gateway, error) {
+	resource, err := GetCustomResourceE(t, options, gatewayResource, gatewayName)
+	if err != nil {
+		return nil, err
+	}
+	gateway := &Gateway{}
+	if err := fromUnstructured(resource, gateway); err != nil {
+		return nil, err
+	}
+	return gateway, nil
+}
+
+// GetHTTPRoute returns the HTTPRoute resource in the namespace of the options with the given name. This will fail the
+// test if there is an error.
+func GetHTTPRoute(t testing.TestingT, options *KubectlOptions, routeName string) *HTTPRoute {
+	route, err := GetHTTPRouteE(t, options, routeName)
+	require.NoError(t, err)
+	return route
+}
+
+// GetHTTPRouteE returns the HTTPRoute resource in the namespace of the options with the given name.
+func GetHTTPRouteE(t testing.TestingT, options *KubectlOptions, routeName string) (*HTTPRoute, error) {
+	resource, err := GetCustomResourceE(t, options, httpRouteResource, routeName)
+	if err != nil {
+		return nil, err
+	}
+	route := &HTTPRoute{}
+	if err := fromUnstructured(resource, route); err != nil {
+		return nil, err
+	}
+	return route, nil
+}
+
+// ListHTTPRoutes will look for HTTPRoute resources in the namespace of the options that match the given filters and
+// return them. This will fail the test if there is an error.
+func ListHTTPRoutes(t testing.TestingT, options *KubectlOptions, filters metav1.ListOptions) []HTTPRoute {
+	routes, err := ListHTTPRoutesE(t, options, filters)
+	require.NoError(t, err)
+	return routes
+}
+
+// ListHTTPRoutesE will look for HTTPRoute resources in the namespace of the options that match the given filters and
+// return them.
+func ListHTTPRoutesE(t testing.TestingT, options *KubectlOptions, filters metav1.ListOptions) ([]HTTPRoute, error) {
+	resources, err := ListCustomResourcesE(t, options, httpRouteResource, filters)
+	if err != nil {
+		return nil, err
+	}
+	routes := make([]HTTPRoute, len(resources))
+	for i := range resources {
+		if err := fromUnstructured(&resources[i], &routes[i]); err != nil {
+			return nil, err
+		}
+	}
+	return routes, nil
+}
+
+// IsGatewayProgrammed returns true if the Gateway has the Programmed condition, for its current generation, meaning
+// its controller configured the data plane for its listeners.
+func IsGatewayProgrammed(gateway *Gateway) bool {
+	return isConditionTrueForGeneration(gateway.Status.Conditions, "Programmed", gateway.Generation)
+}
+
+// WaitUntilGatewayProgrammed waits until the Gateway is programmed, retrying the check for the specified amount of
+// times, sleeping for the provided duration between each try. This will fail the test if there is an error.
+func WaitUntilGatewayProgrammed(t testing.TestingT, options *KubectlOptions, gatewayName string, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, WaitUntilGatewayProgrammedE(t, options, gatewayName, retries, sleepBetweenRetries))
+}
+
+// WaitUntilGatewayProgrammedE waits until the Gateway is programmed, retrying the check for the specified amount of
+// times, sleeping for the provided duration between each try.
+func WaitUntilGatewayProgrammedE(t testing.TestingT, options *KubectlOptions, gatewayName string, retries int, sleepBetweenRetries time.Duration) error {
+	statusMsg := fmt.Sprintf("Wait for gateway %s to be programmed.", gatewayName)
+	message, err := retry.DoWithRetryE(
+		t,
+		statusMsg,
+		retries,
+		sleepBetweenRetries,
+		func() (string, error) {
+			gateway, err := GetGatewayE(t, options, gatewayName)
+			if err != nil {
+				return "", err
+			}
+			if !IsGatewayProgrammed(gateway) {
+				return "", NewGatewayNotProgrammedError(gateway)
+			}
+			return "Gateway is now programmed", nil
+		},
+	)
+	if err != nil {
+		options.Logger.Logf(t, "Timedout waiting for Gateway to be programmed: %s", err)
+		return err
+	}
+	options.Logger.Logf(t, message)
+	return nil
+}
+
+// IsHTTPRouteAccepted returns true if the HTTPRoute is accepted by all its parents, and all its backends are resolved,
+// for its current generation.
+func IsHTTPRouteAccepted(route *HTTPRoute) bool {
+	return getHTTPRouteNotAcceptedReason(route) == ""
+}
+
+// WaitUntilHTTPRouteAccepted waits until the HTTPRoute is accepted by all its parents and its backends are resolved,
+// retrying the check for the specified amount of times, sleeping for the provided duration between each try. This will
+// fail the test if there is an error.
+func WaitUntilHTTPRouteAccepted(t testing.TestingT, options *KubectlOptions, routeName string, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, WaitUntilHTTPRouteAcceptedE(t, options, routeName, retries, sleepBetweenRetries))
+}
+
+// WaitUntilHTTPRouteAcceptedE waits until the HTTPRoute is accepted by all its parents and its backends are resolved,
+// retrying the check for the specified amount of times, sleeping for the provided duration between each try.
+func WaitUntilHTTPRouteAcceptedE(t testing.TestingT, options *KubectlOptions, routeName string, retries int, sleepBetweenRetries time.Duration) error {
+	statusMsg := fmt.Sprintf("Wait for HTTPRoute %s to be accepted.", routeName)
+	message, err := retry.DoWithRetryE(
+		t,
+		statusMsg,
+		retries,
+		sleepBetweenRetries,
+		func() (string, error) {
+			route, err := GetHTTPRouteE(t, options, routeName)
+			if err != nil {
+				return "", err
+			}
+			if reason := getHTTPRouteNotAcceptedReason(route); reason != "" {
+				return "", NewHTTPRouteNotAcceptedError(route, reason)
+			}
+			return "HTTPRoute is now accepted", nil
+		},
+	)
+	if err != nil {
+		options.Logger.Logf(t, "Timedout waiting for HTTPRoute to be accepted: %s", err)
+		return err
+	}
+	options.Logger.Logf(t, message)
+	return nil
+}
+
+// AssertGatewayRoute sends a request for the host and path of the check to the address of the Gateway, over HTTPS if
+// the listener of the Gateway for the host uses HTTPS, and checks the response, retrying for the specified amount of
+// times, sleeping for the provided duration between each try. This will fail the test if there is an error.
+func AssertGatewayRoute(t testing.TestingT, options *KubectlOptions, gatewayName string, check IngressRouteCheck, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, AssertGatewayRouteE(t, options, gatewayName, check, retries, sleepBetweenRetries))
+}
+
+// AssertGatewayRouteE sends a request for the host and path of the check to the address of the Gateway, over HTTPS if
+// the listener of the Gateway for the host uses HTTPS, and checks the response, retrying for the specified amount of
+// times, sleeping for the provided duration between each try. The request is sent to the port of the listener, unless
+// the check sets one. It also checks that the HTTPRoutes attached to the Gateway, in the namespace of the options,
+// route the request to the expected backend Service, and that the served certificate chain is valid for the host.
+func AssertGatewayRouteE(t testing.TestingT, options *KubectlOptions, gatewayName string, check IngressRouteCheck, retries int, sleepBetweenRetries time.Duration) error {
+	path := check.Path
+	if path == "" {
+		path = "/"
+	}
+
+	statusMsg := fmt.Sprintf("Check route %s%s of gateway %s.", check.Host, path, gatewayName)
+	message, err := retry.DoWithRetryE(
+		t,
+		statusMsg,
+		retries,
+		sleepBetweenRetries,
+		func() (string, error) {
+			gateway, err := GetGatewayE(t, options, gatewayName)
+			if err != nil {
+				return "", err
+			}
+			if !IsGatewayProgrammed(gateway) || len(gateway.Status.Addresses) == 0 {
+				return "", NewGatewayNotProgrammedError(gateway)
+			}
+			listener := getGatewayListener(gateway, check.Host, check.Port)
+			if listener == nil {
+				return "", retry.FatalError{Underlying: fmt.Errorf("gateway %s has no HTTP listener for %s", gatewayName, check.Host)}
+			}
+
+			if check.ExpectedBackendService != "" {
+				routes, err := ListHTTPRoutesE(t, options, metav1.ListOptions{})
+				if err != nil {
+					return "", err
+				}
+				backend := getHTTPRouteBackendService(routes, gateway, check.Host, path)
+				if backend != check.ExpectedBackendService {
+					return "", retry.FatalError{Underlying: fmt.Errorf("gateway %s routes %s%s to service %q, expected %q", gatewayName, check.Host, path, backend, check.ExpectedBackendService)}
+				}
+			}
+
+			listenerCheck := check
+			listenerCheck.Port = int(listener.Port)
+			return checkIngressRouteE(gateway.Status.Addresses[0].Value, listener.Protocol == "HTTPS", listenerCheck, path)
+		},
+	)
+	if err != nil {
+		options.Logger.Logf(t, "Failed checking route of Gateway: %s", err)
+		return err
+	}
+	options.Logger.Logf(t, message)
+	return nil
+}
+
+// fromUnstructured converts the given resource read with the dynamic client to the typed object.
+func fromUnstructured(resource *unstructured.Unstructured, object interface{}) error {
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(resource.Object, object)
+}
+
+// isConditionTrueForGeneration returns true if the condition of the given type is true, and was observed for the
+// given generation of the resource, or any generation if the controller doesn't report it.
+func isConditionTrueForGeneration(conditions []metav1.Condition, conditionType string, generation int64) bool {
+	condition := meta.FindStatusCondition(conditions, conditionType)
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		return false
+	}
+	return condition.ObservedGeneration == 0 || condition.ObservedGeneration >= generation
+}
+
+// getHTTPRouteNotAcceptedReason returns why the HTTPRoute is not accepted, or an empty string if it is.
+func getHTTPRouteNotAcceptedReason(route *HTTPRoute) string {
+	if len(route.Status.Parents) == 0 {
+		return "no parent reported a status yet"
+	}
+	for _, parent := range route.Status.Parents {
+		for _, conditionType := range []string{"Accepted", "ResolvedRefs"} {
+			if isConditionTrueForGeneration(parent.Conditions, conditionType, route.Generation) {
+				continue
+			}
+			reason := fmt.Sprintf("condition %s is not true for parent %s", conditionType, parent.ParentRef.Name)
+			if condition := meta.FindStatusCondition(parent.Conditions, conditionType); condition != nil && condition.Message != "" {
+				reason = fmt.Sprintf("%s: %s", reason, condition.Message)
+			}
+			return reason
+		}
+	}
+	return ""
+}
+
+// getGatewayListener returns the first HTTP or HTTPS listener of the Gateway matching the host, and the port if not 0.
+func getGatewayListener(gateway *Gateway, host string, port int) *GatewayListener {
+	for i, listener := range gateway.Spec.Listeners {
+		if listener.Protocol != "HTTP" && listener.Protocol != "HTTPS" {
+			continue
+		}
+		if port != 0 && int(listener.Port) != port {
+			continue
+		}
+		if gatewayHostnameMatches(listener.Hostname, host) {
+			return &gateway.Spec.Listeners[i]
+		}
+	}
+	return nil
+}
+
+// getHTTPRouteBackendService returns the name of the first backend of the rule of the routes attached to the Gateway
+// that matches the host and path, with the precedence of Gateway controllers: an Exact path match over any PathPrefix
+// match, and otherwise the longest matching path.
+func getHTTPRouteBackendService(routes []HTTPRoute, gateway *Gateway, host string, path string) string {
+	backend := ""
+	longestMatch := -1
+	exactMatch := false
+	for _, route := range routes {
+		if !httpRouteAttachesTo(route, gateway) || !httpRouteHostnameMatches(route, host) {
+			continue
+		}
+		for _, rule := range route.Spec.Rules {
+			if len(rule.BackendRefs) == 0 {
+				continue
+			}
+			matches := rule.Matches
+			if len(matches) == 0 {
+				matches = []HTTPRouteMatch{{}}
+			}
+			for _, match := range matches {
+				matchLength, ok := httpPathMatchLength(match.Path, path)
+				exact := match.Path != nil && match.Path.Type == "Exact"
+				if ok && ((exact && !exactMatch) || (exact == exactMatch && matchLength > longestMatch)) {
+					backend = rule.BackendRefs[0].Name
+					longestMatch = matchLength
+					exactMatch = exact
+				}
+			}
+		}
+	}
+	return backend
+}
+
+// httpRouteAttachesTo returns true if one of the parents of the route is the Gateway.
+func httpRouteAttachesTo(route HTTPRoute, gateway *Gateway) bool {
+	for _, parentRef := range route.Spec.ParentRefs {
+		namespace := parentRef.Namespace
+		if namespace == "" {
+			namespace = route.Namespace
+		}
+		if (parentRef.Kind == "" || parentRef.Kind == "Gateway") && parentRef.Name == gateway.Name && namespace == gateway.Namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// httpRouteHostnameMatches returns true if the route has no hostname, or one of its hostnames matches the host.
+func httpRouteHostnameMatches(route HTTPRoute, host string) bool {
+	if len(route.Spec.Hostnames) == 0 {
+		return true
+	}
+	for _, hostname := range route.Spec.Hostnames {
+		if gatewayHostnameMatches(hostname, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// gatewayHostnameMatches returns true if the hostname of a listener or route, which can be empty or start with a
+// wildcard label, matches the given host. Unlike in Ingress rules, the wildcard matches one or more labels.
+func gatewayHostnameMatches(hostname string, host string) bool {
+	if hostname == "" || hostname == host {
+		return true
+	}
+	return strings.HasPrefix(hostname, "*.") && strings.HasSuffix(host, hostname[1:]) && len(host) > len(hostname)-1
+}
+
+// httpPathMatchLength returns the length of the path of the match if it matches the given path, a missing path
+// matching all the paths as the / prefix. Regular expressions are implementation specific, so they never match.
+func httpPathMatchLength(pathMatch *HTTPPathMatch, path string) (int, bool) {
+	if pathMatch == nil {
+		return len("/"), true
+	}
+	switch pathMatch.Type {
+	case "Exact":
+		return len(pathMatch.Value), pathMatch.Value == path
+	case "", "PathPrefix":
+		prefix := strings.TrimSuffix(pathMatch.Value, "/")
+		return len(pathMatch.Value), prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,120 @@
+//go:build kubeall || kubernetes
+// +build kubeall kubernetes
+
+// NOTE: we have build tags to differentiate kubernetes tests from non-kubernetes tests. This is done because minikube
+// is heavy and can interfere with docker related tests in terratest. Specifically, many of the tests start to fail with
+// `connection refused` errors from `minikube`. To avoid overloading the system, we run the kubernetes tests and helm
+// tests separately from the others. This may not be necessary if you have a sufficiently powerful machine.  We
+// recommend at least 4 cores and 16GB of RAM if you want to run all the tests together.
+
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestGatewayFromUnstructured(t *testing.T) {
+	t.Parallel()
+
+	resource := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "Gateway",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "default", "generation": int64(2)},
+		"spec": map[string]interface{}{
+			"gatewayClassName": "envoy",
+			"listeners": []interface{}{
+				map[string]interface{}{"name": "https", "hostname": "*.example.com", "port": int64(443), "protocol": "HTTPS"},
+			},
+		},
+		"status": map[string]interface{}{
+			"addresses": []interface{}{map[string]interface{}{"type": "IPAddress", "value": "10.0.0.1"}},
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Programmed", "status": "True", "observedGeneration": int64(1), "reason": "Programmed", "lastTransitionTime": "2024-01-01T00:00:00Z"},
+			},
+		},
+	}}
+
+	gateway := &Gateway{}
+	require.NoError(t, fromUnstructured(resource, gateway))
+	assert.Equal(t, "envoy", gateway.Spec.GatewayClassName)
+	assert.Equal(t, int32(443), gateway.Spec.Listeners[0].Port)
+	assert.Equal(t, "10.0.0.1", gateway.Status.Addresses[0].Value)
+
+	// The condition is stale until the controller observes the current generation
+	assert.False(t, IsGatewayProgrammed(gateway))
+	gateway.Status.Conditions[0].ObservedGeneration = 2
+	assert.True(t, IsGatewayProgrammed(gateway))
+
+	assert.NotNil(t, getGatewayListener(gateway, "app.example.com", 0))
+	assert.Nil(t, getGatewayListener(gateway, "app.example.com", 80))
+	assert.Nil(t, getGatewayListener(gateway, "example.com", 0))
+}
+
+func TestGetHTTPRouteNotAcceptedReason(t *testing.T) {
+	t.Parallel()
+
+	route := &HTTPRoute{ObjectMeta: metav1.ObjectMeta{Name: "app"}}
+	assert.False(t, IsHTTPRouteAccepted(route))
+
+	route.Status.Parents = []RouteParentStatus{{
+		ParentRef: GatewayParentReference{Name: "web"},
+		Conditions: []metav1.Condition{
+			{Type: "Accepted", Status: metav1.ConditionTrue},
+			{Type: "ResolvedRefs", Status: metav1.ConditionFalse, Message: "service missing not found"},
+		},
+	}}
+	assert.Equal(t, "condition ResolvedRefs is not true for parent web: service missing not found", getHTTPRouteNotAcceptedReason(route))
+
+	route.Status.Parents[0].Conditions[1].Status = metav1.ConditionTrue
+	assert.True(t, IsHTTPRouteAccepted(route))
+}
+
+func TestGetHTTPRouteBackendService(t *testing.T) {
+	t.Parallel()
+
+	gateway := &Gateway{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "infra"}}
+	routes := []HTTPRoute{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "apps"},
+			Spec: HTTPRouteSpec{
+				ParentRefs: []GatewayParentReference{{Name: "web", Namespace: "infra"}},
+				Hostnames:  []string{"*.example.com"},
+				Rules: []HTTPRouteRule{
+					{BackendRefs: []HTTPBackendRef{{Name: "frontend"}}},
+					{
+						Matches:     []HTTPRouteMatch{{Path: &HTTPPathMatch{Type: "PathPrefix", Value: "/api"}}},
+						BackendRefs: []HTTPBackendRef{{Name: "api"}},
+					},
+				},
+			},
+		},
+		{
+			// Attached to another gateway, as the namespace of the parent defaults to the namespace of the route
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "apps"},
+			Spec: HTTPRouteSpec{
+				ParentRefs: []GatewayParentReference{{Name: "web"}},
+				Rules: []HTTPRouteRule{{
+					Matches:     []HTTPRouteMatch{{Path: &HTTPPathMatch{Type: "Exact", Value: "/api/v1/users"}}},
+					BackendRefs: []HTTPBackendRef{{Name: "users"}},
+				}},
+			},
+		},
+	}
+
+	assert.Equal(t, "frontend", getHTTPRouteBackendService(routes, gateway, "shop.eu.example.com", "/"))
+	assert.Equal(t, "api", getHTTPRouteBackendService(routes, gateway, "shop.example.com", "/api/v1/users"))
+	assert.Equal(t, "", getHTTPRouteBackendService(routes, gateway, "example.com", "/api"))
+
+	// An Exact match takes precedence over a PathPrefix match of the same path
+	routes[0].Spec.Rules = append(routes[0].Spec.Rules, HTTPRouteRule{
+		Matches:     []HTTPRouteMatch{{Path: &HTTPPathMatch{Type: "Exact", Value: "/api"}}},
+		BackendRefs: []HTTPBackendRef{{Name: "api-root"}},
+	})
+	assert.Equal(t, "api-root", getHTTPRouteBackendService(routes, gateway, "shop.example.com", "/api"))
+	assert.Equal(t, "api", getHTTPRouteBackendService(routes, gateway, "shop.example.com", "/api/v1"))
+}
@@ -0,0 +1,143 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// hpaPollInterval is the time between the checks of the replicas of a HorizontalPodAutoscaler when waiting for it to
+// scale.
+const hpaPollInterval = 5 * time.Second
+
+// ListHorizontalPodAutoscalers will look for HorizontalPodAutoscalers in the given namespace that match the given
+// filters and return them. This will fail the test if there is an error.
+func ListHorizontalPodAutoscalers(t testing.TestingT, options *KubectlOptions, filters metav1.ListOptions) []autoscalingv2.HorizontalPodAutoscaler {
+	hpas, err := ListHorizontalPodAutoscalersE(t, options, filters)
+	require.NoError(t, err)
+	return hpas
+}
+
+// ListHorizontalPodAutoscalersE will look for HorizontalPodAutoscalers in the given namespace that match the given
+// filters and return them.
+func ListHorizontalPodAutoscalersE(t testing.TestingT, options *KubectlOptions, filters metav1.ListOptions) ([]autoscalingv2.HorizontalPodAutoscaler, error) {
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := clientset.AutoscalingV2().HorizontalPodAutoscalers(options.Namespace).List(context.Background(), filters)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+// GetHorizontalPodAutoscaler returns a Kubernetes HorizontalPodAutoscaler resource in the provided namespace with the
+// given name. This will fail the test if there is an error.
+func GetHorizontalPodAutoscaler(t testing.TestingT, options *KubectlOptions, hpaName string) *autoscalingv2.HorizontalPodAutoscaler {
+	hpa, err := GetHorizontalPodAutoscalerE(t, options, hpaName)
+	require.NoError(t, err)
+	return hpa
+}
+
+// GetHorizontalPodAutoscalerE returns a Kubernetes HorizontalPodAutoscaler resource in the provided namespace with the
+// given name.
+func GetHorizontalPodAutoscalerE(t testing.TestingT, options *KubectlOptions, hpaName string) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return nil, err
+	}
+	return clientset.AutoscalingV2().HorizontalPodAutoscalers(options.Namespace).Get(context.Background(), hpaName, metav1.GetOptions{})
+}
+
+// WaitForHpaScale waits until the HorizontalPodAutoscaler scaled its target to the given number of replicas, checking
+// until the timeout expires. This will fail the test if there is an error.
+func WaitForHpaScale(t testing.TestingT, options *KubectlOptions, hpaName string, targetReplicas int32, timeout time.Duration) {
+	require.NoError(t, WaitForHpaScaleE(t, options, hpaName, targetReplicas, timeout))
+}
+
+// WaitForHpaScaleE waits until the HorizontalPodAutoscaler scaled its target to the given number of replicas, checking
+// until the timeout expires. Use GenerateCPULoadInPods to make it scale up.
+func WaitForHpaScaleE(t testing.TestingT, options *KubectlOptions, hpaName string, targetReplicas int32, timeout time.Duration) error {
+	statusMsg := fmt.Sprintf("Wait for HorizontalPodAutoscaler %s to scale to %d replicas.", hpaName, targetReplicas)
+	message, err := retry.DoWithRetryE(
+		t,
+		statusMsg,
+		int(timeout/hpaPollInterval)+1,
+		hpaPollInterval,
+		func() (string, error) {
+			hpa, err := GetHorizontalPodAutoscalerE(t, options, hpaName)
+			if err != nil {
+				return "", err
+			}
+			if !IsHpaScaledTo(hpa, targetReplicas) {
+				return "", NewHpaNotScaledError(hpa, targetReplicas)
+			}
+			return fmt.Sprintf("HorizontalPodAutoscaler scaled to %d replicas", targetReplicas), nil
+		},
+	)
+	if err != nil {
+		options.Logger.Logf(t, "Timedout waiting for HorizontalPodAutoscaler to scale: %s", err)
+		return err
+	}
+	options.Logger.Logf(t, message)
+	return nil
+}
+
+// IsHpaScaledTo returns true if the HorizontalPodAutoscaler wants and has the given number of replicas.
+func IsHpaScaledTo(hpa *autoscalingv2.HorizontalPodAutoscaler, targetReplicas int32) bool {
+	return hpa.Status.DesiredReplicas == targetReplicas && hpa.Status.CurrentReplicas == targetReplicas
+}
+
+// GenerateCPULoadInPods keeps a CPU core busy in the given container of each of the Pods matching the label selector
+// (e.g. app=nginx) for the given duration, to make a HorizontalPodAutoscaler scale on CPU usage. Set container name to
+// "" if there is only one container in the Pods. The load runs in the background, so this returns immediately. This
+// will fail the test if there is an error.
+func GenerateCPULoadInPods(t testing.TestingT, options *KubectlOptions, podSelector string, containerName string, duration time.Duration) {
+	require.NoError(t, GenerateCPULoadInPodsE(t, options, podSelector, containerName, duration))
+}
+
+// GenerateCPULoadInPodsE keeps a CPU core busy in the given container of each of the Pods matching the label selector
+// (e.g. app=nginx) for the given duration, to make a HorizontalPodAutoscaler scale on CPU usage. Set container name to
+// "" if there is only one container in the Pods. The load runs in the background, so this returns immediately. The
+// containers must have a shell and the yes command, as most base images do.
+func GenerateCPULoadInPodsE(t testing.TestingT, options *KubectlOptions, podSelector string, containerName string, duration time.Duration) error {
+	pods, err := ListPodsE(t, options, metav1.ListOptions{LabelSelector: podSelector})
+	if err != nil {
+		return err
+	}
+	if len(pods) == 0 {
+		return fmt.Errorf("no pods matching %s to generate load in", podSelector)
+	}
+
+	command := formatCPULoadCommand(duration)
+	for _, pod := range pods {
+		options.Logger.Logf(t, "Generating CPU load in pod %s for %s", pod.Name, duration)
+		_, stderr, exitCode, err := ExecInPodE(t, options, pod.Name, containerName, command, nil)
+		if err != nil {
+			return err
+		}
+		if exitCode != 0 {
+			return fmt.Errorf("failed to generate CPU load in pod %s, exit code %d: %s", pod.Name, exitCode, stderr)
+		}
+	}
+	return nil
+}
+
+// formatCPULoadCommand returns the command running a busy loop in the background for the given duration. The output
+// of the loop is detached from the exec streams, so the command returns immediately.
+func formatCPULoadCommand(duration time.Duration) []string {
+	seconds := int(duration.Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+	script := fmt.Sprintf("(yes > /dev/null & pid=$!; sleep %d; kill $pid) > /dev/null 2>&1 &", seconds)
+	return []string{"sh", "-c", script}
+}
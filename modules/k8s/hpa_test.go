@@ -0,0 +1,44 @@
+//go:build kubeall || kubernetes
+// +build kubeall kubernetes
+
+// NOTE: we have build tags to differentiate kubernetes tests from non-kubernetes tests. This is done because minikube
+// is heavy and can interfere with docker related tests in terratest. Specifically, many of the tests start to fail with
+// `connection refused` errors from `minikube`. To avoid overloading the system, we run the kubernetes tests and helm
+// tests separately from the others. This may not be necessary if you have a sufficiently powerful machine.  We
+// recommend at least 4 cores and 16GB of RAM if you want to run all the tests together.
+
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+)
+
+func TestGetHorizontalPodAutoscalerEReturnsError(t *testing.T) {
+	t.Parallel()
+
+	options := NewKubectlOptions("", "", "")
+	_, err := GetHorizontalPodAutoscalerE(t, options, "nginx-hpa")
+	require.Error(t, err)
+}
+
+func TestIsHpaScaledTo(t *testing.T) {
+	t.Parallel()
+
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{Status: autoscalingv2.HorizontalPodAutoscalerStatus{CurrentReplicas: 2, DesiredReplicas: 4}}
+	require.False(t, IsHpaScaledTo(hpa, 4))
+	require.False(t, IsHpaScaledTo(hpa, 2))
+
+	hpa.Status.CurrentReplicas = 4
+	require.True(t, IsHpaScaledTo(hpa, 4))
+}
+
+func TestFormatCPULoadCommand(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []string{"sh", "-c", "(yes > /dev/null & pid=$!; sleep 90; kill $pid) > /dev/null 2>&1 &"}, formatCPULoadCommand(90*time.Second))
+	require.Equal(t, []string{"sh", "-c", "(yes > /dev/null & pid=$!; sleep 1; kill $pid) > /dev/null 2>&1 &"}, formatCPULoadCommand(0))
+}
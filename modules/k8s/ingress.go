@@ -2,7 +2,14 @@ package k8s
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/stretchr/testify/require"
@@ -82,6 +89,267 @@ func WaitUntilIngressAvailable(t testing.TestingT, options *KubectlOptions, ingr
 	options.Logger.Logf(t, message)
 }
 
+// IngressRouteCheck defines the request to send to an Ingress, and the response to expect.
+type IngressRouteCheck struct {
+	// The host to request, sent in the Host header and as the TLS server name, so the request is routed by host without
+	// DNS records.
+	Host string
+
+	// The path to request. Defaults to /.
+	Path string
+
+	// The port to send the request to. Defaults to 443 if the Ingress has a TLS configuration for the host, and 80
+	// otherwise.
+	Port int
+
+	// If set, the name of the Service the Ingress rules must route the host and path to.
+	ExpectedBackendService string
+
+	// The expected status code of the response. Defaults to 200.
+	ExpectedStatusCode int
+
+	// If set, a string the body of the response must contain.
+	ExpectedBodySubstring string
+
+	// The PEM encoded CA certificates to validate the certificate chain served for the host against. Defaults to the
+	// system roots.
+	CACertPEM []byte
+
+	// If set, the common name of the issuer of the served certificate, e.g. to distinguish staging certificates.
+	ExpectedIssuerCommonName string
+}
+
+// WaitUntilIngressHasAddress waits until the Ingress resource has an IP or hostname provisioned for it, retrying the
+// check for the specified amount of times, sleeping for the provided duration between each try, and returns it. This
+// will fail the test if there is an error.
+func WaitUntilIngressHasAddress(t testing.TestingT, options *KubectlOptions, ingressName string, retries int, sleepBetweenRetries time.Duration) string {
+	address, err := WaitUntilIngressHasAddressE(t, options, ingressName, retries, sleepBetweenRetries)
+	require.NoError(t, err)
+	return address
+}
+
+// WaitUntilIngressHasAddressE waits until the Ingress resource has an IP or hostname provisioned for it, retrying the
+// check for the specified amount of times, sleeping for the provided duration between each try, and returns it.
+func WaitUntilIngressHasAddressE(t testing.TestingT, options *KubectlOptions, ingressName string, retries int, sleepBetweenRetries time.Duration) (string, error) {
+	statusMsg := fmt.Sprintf("Wait for ingress %s to have an address.", ingressName)
+	address, err := retry.DoWithRetryE(
+		t,
+		statusMsg,
+		retries,
+		sleepBetweenRetries,
+		func() (string, error) {
+			ingress, err := GetIngressE(t, options, ingressName)
+			if err != nil {
+				return "", err
+			}
+			address := getIngressAddress(ingress)
+			if address == "" {
+				return "", IngressNotAvailable{ingress: ingress}
+			}
+			return address, nil
+		},
+	)
+	if err != nil {
+		options.Logger.Logf(t, "Timedout waiting for Ingress to have an address: %s", err)
+		return "", err
+	}
+	options.Logger.Logf(t, "Ingress %s has address %s", ingressName, address)
+	return address, nil
+}
+
+// AssertIngressRoute sends a request for the host and path of the check to the address of the Ingress, over HTTPS if
+// the Ingress has a TLS configuration for the host, and checks the response, retrying for the specified amount of
+// times, sleeping for the provided duration between each try. This will fail the test if there is an error.
+func AssertIngressRoute(t testing.TestingT, options *KubectlOptions, ingressName string, check IngressRouteCheck, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, AssertIngressRouteE(t, options, ingressName, check, retries, sleepBetweenRetries))
+}
+
+// AssertIngressRouteE sends a request for the host and path of the check to the address of the Ingress, over HTTPS if
+// the Ingress has a TLS configuration for the host, and checks the response, retrying for the specified amount of
+// times, sleeping for the provided duration between each try. It also checks that the rules of the Ingress route the
+// request to the expected backend Service, and that the served certificate chain is valid for the host.
+func AssertIngressRouteE(t testing.TestingT, options *KubectlOptions, ingressName string, check IngressRouteCheck, retries int, sleepBetweenRetries time.Duration) error {
+	path := check.Path
+	if path == "" {
+		path = "/"
+	}
+
+	statusMsg := fmt.Sprintf("Check route %s%s of ingress %s.", check.Host, path, ingressName)
+	message, err := retry.DoWithRetryE(
+		t,
+		statusMsg,
+		retries,
+		sleepBetweenRetries,
+		func() (string, error) {
+			ingress, err := GetIngressE(t, options, ingressName)
+			if err != nil {
+				return "", err
+			}
+			address := getIngressAddress(ingress)
+			if address == "" {
+				return "", IngressNotAvailable{ingress: ingress}
+			}
+
+			if check.ExpectedBackendService != "" {
+				backend := getIngressBackendService(ingress, check.Host, path)
+				if backend != check.ExpectedBackendService {
+					return "", retry.FatalError{Underlying: fmt.Errorf("ingress %s routes %s%s to service %q, expected %q", ingressName, check.Host, path, backend, check.ExpectedBackendService)}
+				}
+			}
+
+			return checkIngressRouteE(address, ingressHasTLSHost(ingress, check.Host), check, path)
+		},
+	)
+	if err != nil {
+		options.Logger.Logf(t, "Failed checking route of Ingress: %s", err)
+		return err
+	}
+	options.Logger.Logf(t, message)
+	return nil
+}
+
+// checkIngressRouteE sends the request of the check to the given address of an Ingress and checks the response.
+func checkIngressRouteE(address string, useTLS bool, check IngressRouteCheck, path string) (string, error) {
+	scheme := "http"
+	port := 80
+	if useTLS {
+		scheme = "https"
+		port = 443
+	}
+	if check.Port != 0 {
+		port = check.Port
+	}
+
+	tlsConfig := &tls.Config{ServerName: check.Host}
+	if len(check.CACertPEM) > 0 {
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(check.CACertPEM) {
+			return "", retry.FatalError{Underlying: errors.New("no valid certificate found in the CA certificates")}
+		}
+	}
+
+	// Connect to the Ingress whatever the host resolves to, so the request is routed by host without DNS records
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: tlsConfig,
+			DialContext: func(ctx context.Context, network string, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, net.JoinHostPort(address, fmt.Sprint(port)))
+			},
+		},
+	}
+
+	resp, err := client.Get(fmt.Sprintf("%s://%s%s", scheme, check.Host, path))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	expectedStatusCode := check.ExpectedStatusCode
+	if expectedStatusCode == 0 {
+		expectedStatusCode = http.StatusOK
+	}
+	if resp.StatusCode != expectedStatusCode {
+		return "", fmt.Errorf("got status %d for %s%s, expected %d", resp.StatusCode, check.Host, path, expectedStatusCode)
+	}
+	if !strings.Contains(string(body), check.ExpectedBodySubstring) {
+		return "", fmt.Errorf("body of %s%s does not contain %q", check.Host, path, check.ExpectedBodySubstring)
+	}
+	if useTLS && check.ExpectedIssuerCommonName != "" {
+		issuer := resp.TLS.PeerCertificates[0].Issuer.CommonName
+		if issuer != check.ExpectedIssuerCommonName {
+			return "", fmt.Errorf("certificate served for %s is issued by %q, expected %q", check.Host, issuer, check.ExpectedIssuerCommonName)
+		}
+	}
+	return fmt.Sprintf("Got status %d for %s://%s%s", resp.StatusCode, scheme, check.Host, path), nil
+}
+
+// getIngressAddress returns the first IP or hostname of the Ingress, or an empty string if none is provisioned.
+func getIngressAddress(ingress *networkingv1.Ingress) string {
+	for _, endpoint := range ingress.Status.LoadBalancer.Ingress {
+		if endpoint.IP != "" {
+			return endpoint.IP
+		}
+		if endpoint.Hostname != "" {
+			return endpoint.Hostname
+		}
+	}
+	return ""
+}
+
+// ingressHasTLSHost returns true if the Ingress has a TLS configuration for the given host.
+func ingressHasTLSHost(ingress *networkingv1.Ingress, host string) bool {
+	for _, ingressTLS := range ingress.Spec.TLS {
+		for _, tlsHost := range ingressTLS.Hosts {
+			if ingressHostMatches(tlsHost, host) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getIngressBackendService returns the name of the Service the rules of the Ingress route the host and path to, or the
+// default backend if no rule matches. As in the Ingress spec, the longest matching path takes precedence, and Exact
+// paths take precedence over Prefix paths that match as much of the path.
+func getIngressBackendService(ingress *networkingv1.Ingress, host string, path string) string {
+	backend := ""
+	longestMatch := -1
+	exactMatch := false
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil || !ingressHostMatches(rule.Host, host) {
+			continue
+		}
+		for _, rulePath := range rule.HTTP.Paths {
+			if rulePath.Backend.Service == nil {
+				continue
+			}
+			matchLength, ok := ingressPathMatchLength(rulePath, path)
+			exact := rulePath.PathType != nil && *rulePath.PathType == networkingv1.PathTypeExact
+			if ok && (matchLength > longestMatch || (matchLength == longestMatch && exact && !exactMatch)) {
+				backend = rulePath.Backend.Service.Name
+				longestMatch = matchLength
+				exactMatch = exact
+			}
+		}
+	}
+	if longestMatch < 0 && ingress.Spec.DefaultBackend != nil && ingress.Spec.DefaultBackend.Service != nil {
+		return ingress.Spec.DefaultBackend.Service.Name
+	}
+	return backend
+}
+
+// ingressHostMatches returns true if the host of an Ingress rule, which can be empty or start with a wildcard label,
+// matches the given host.
+func ingressHostMatches(ruleHost string, host string) bool {
+	if ruleHost == "" || ruleHost == host {
+		return true
+	}
+	if !strings.HasPrefix(ruleHost, "*.") {
+		return false
+	}
+	// The wildcard only matches a single label
+	label, domain, found := strings.Cut(host, ".")
+	return found && label != "" && "*."+domain == ruleHost
+}
+
+// ingressPathMatchLength returns the length of the given path that the path of an Ingress rule matches, and false if
+// it doesn't match, implementation specific paths being matched as prefixes. The trailing slash of a prefix doesn't
+// count, so /foo and /foo/ match as much of the path.
+func ingressPathMatchLength(rulePath networkingv1.HTTPIngressPath, path string) (int, bool) {
+	if rulePath.PathType != nil && *rulePath.PathType == networkingv1.PathTypeExact {
+		return len(path), rulePath.Path == path
+	}
+	prefix := strings.TrimSuffix(rulePath.Path, "/")
+	return len(prefix), prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
 // ListIngressesV1Beta1 will look for Ingress resources in the given namespace that match the given filters and return
 // them, using networking.k8s.io/v1beta1 API. This will fail the test if there is an error.
 func ListIngressesV1Beta1(t testing.TestingT, options *KubectlOptions, filters metav1.ListOptions) []networkingv1beta1.Ingress {
@@ -10,12 +10,18 @@
 package k8s
 
 import (
+	"encoding/pem"
 	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/gruntwork-io/terratest/modules/random"
@@ -74,6 +80,96 @@ func TestWaitUntilIngressAvailableReturnsSuccessfully(t *testing.T) {
 	WaitUntilIngressAvailableV1Beta1(t, options, ExampleIngressName, 60, 5*time.Second)
 }
 
+func TestGetIngressBackendService(t *testing.T) {
+	t.Parallel()
+
+	exact := networkingv1.PathTypeExact
+	prefix := networkingv1.PathTypePrefix
+	ingress := &networkingv1.Ingress{
+		Spec: networkingv1.IngressSpec{
+			DefaultBackend: &networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "default"}},
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: "app.example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{Paths: []networkingv1.HTTPIngressPath{
+						{Path: "/", PathType: &prefix, Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "web"}}},
+						{Path: "/api", PathType: &prefix, Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "api"}}},
+						{Path: "/health", PathType: &exact, Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "health"}}},
+						{Path: "/docs/", PathType: &prefix, Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "docs"}}},
+						{Path: "/docs", PathType: &exact, Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "docs-index"}}},
+						{Path: "/api/v1", PathType: &prefix, Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "api-v1"}}},
+					}}},
+				},
+				{
+					Host: "*.preview.example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{Paths: []networkingv1.HTTPIngressPath{
+						{Path: "/", PathType: &prefix, Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "preview"}}},
+					}}},
+				},
+			},
+		},
+	}
+
+	require.Equal(t, "web", getIngressBackendService(ingress, "app.example.com", "/"))
+	require.Equal(t, "api", getIngressBackendService(ingress, "app.example.com", "/api/users"))
+	require.Equal(t, "web", getIngressBackendService(ingress, "app.example.com", "/apis"))
+	require.Equal(t, "health", getIngressBackendService(ingress, "app.example.com", "/health"))
+	require.Equal(t, "web", getIngressBackendService(ingress, "app.example.com", "/health/live"))
+	// The Exact path takes precedence over the Prefix path of the same length, listed first
+	require.Equal(t, "docs-index", getIngressBackendService(ingress, "app.example.com", "/docs"))
+	require.Equal(t, "docs", getIngressBackendService(ingress, "app.example.com", "/docs/intro"))
+	// The longest matching prefix takes precedence, whatever the order of the paths
+	require.Equal(t, "api-v1", getIngressBackendService(ingress, "app.example.com", "/api/v1/users"))
+	require.Equal(t, "preview", getIngressBackendService(ingress, "pr-1.preview.example.com", "/"))
+	require.Equal(t, "default", getIngressBackendService(ingress, "a.b.preview.example.com", "/"))
+	require.Equal(t, "default", getIngressBackendService(ingress, "other.example.com", "/"))
+}
+
+func TestIngressHasTLSHost(t *testing.T) {
+	t.Parallel()
+
+	ingress := &networkingv1.Ingress{
+		Spec: networkingv1.IngressSpec{TLS: []networkingv1.IngressTLS{{Hosts: []string{"app.example.com", "*.preview.example.com"}}}},
+		Status: networkingv1.IngressStatus{LoadBalancer: networkingv1.IngressLoadBalancerStatus{
+			Ingress: []networkingv1.IngressLoadBalancerIngress{{Hostname: "lb.example.com"}},
+		}},
+	}
+
+	require.True(t, ingressHasTLSHost(ingress, "app.example.com"))
+	require.True(t, ingressHasTLSHost(ingress, "pr-1.preview.example.com"))
+	require.False(t, ingressHasTLSHost(ingress, "other.example.com"))
+	require.Equal(t, "lb.example.com", getIngressAddress(ingress))
+}
+
+func TestCheckIngressRoute(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "host %s path %s", r.Host, r.URL.Path)
+	}))
+	defer server.Close()
+
+	host, portString, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portString)
+	require.NoError(t, err)
+	caCertPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	// The certificate of the test server is valid for example.com
+	check := IngressRouteCheck{Host: "example.com", Path: "/app", Port: port, ExpectedBodySubstring: "host example.com path /app", CACertPEM: caCertPEM}
+	_, err = checkIngressRouteE(host, true, check, check.Path)
+	require.NoError(t, err)
+
+	check.CACertPEM = nil
+	_, err = checkIngressRouteE(host, true, check, check.Path)
+	require.Error(t, err)
+
+	check.CACertPEM = caCertPEM
+	check.ExpectedStatusCode = http.StatusNotFound
+	_, err = checkIngressRouteE(host, true, check, check.Path)
+	require.Error(t, err)
+}
+
 const exampleIngressDeploymentYamlTemplate = `---
 apiVersion: v1
 kind: Namespace
@@ -1,10 +1,15 @@
 package k8s
 
 import (
+	"errors"
+	"fmt"
 	"net/url"
 	"os"
+	"strings"
 
 	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	yamlutil "k8s.io/apimachinery/pkg/util/yaml"
 
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/testing"
@@ -24,6 +29,11 @@ func RunKubectlE(t testing.TestingT, options *KubectlOptions, args ...string) er
 // RunKubectlAndGetOutputE will call kubectl using the provided options and args, returning the output of stdout and
 // stderr.
 func RunKubectlAndGetOutputE(t testing.TestingT, options *KubectlOptions, args ...string) (string, error) {
+	return shell.RunCommandAndGetOutputE(t, getKubectlCommand(options, args...))
+}
+
+// getKubectlCommand returns the command calling kubectl using the provided options and args.
+func getKubectlCommand(options *KubectlOptions, args ...string) shell.Command {
 	cmdArgs := []string{}
 	if options.ContextName != "" {
 		cmdArgs = append(cmdArgs, "--context", options.ContextName)
@@ -38,13 +48,12 @@ func RunKubectlAndGetOutputE(t testing.TestingT, options *KubectlOptions, args .
 		cmdArgs = append(cmdArgs, "--request-timeout", options.RequestTimeout.String())
 	}
 	cmdArgs = append(cmdArgs, args...)
-	command := shell.Command{
+	return shell.Command{
 		Command: "kubectl",
 		Args:    cmdArgs,
 		Env:     options.Env,
-		Logger:  options.Logger,
+		Logger:  options.Logger.WithMinLevel(options.LogLevel),
 	}
-	return shell.RunCommandAndGetOutputE(t, command)
 }
 
 // KubectlDelete will take in a file path and delete it from the cluster targeted by KubectlOptions. If there are any
@@ -125,6 +134,167 @@ func KubectlApplyFromStringE(t testing.TestingT, options *KubectlOptions, config
 	return KubectlApplyE(t, options, tmpfile)
 }
 
+// KubectlApplyOptions represents the options of 'kubectl apply', to apply manifests the way controllers do.
+type KubectlApplyOptions struct {
+	// If set to true, pass the --server-side flag to apply the manifests on the server rather than in kubectl.
+	ServerSide bool
+
+	// The name of the field manager the applied fields are owned by. Defaults to the one of kubectl.
+	FieldManager string
+
+	// If set to true, take ownership of the fields owned by other field managers instead of failing the server-side
+	// apply with conflicts.
+	ForceConflicts bool
+
+	// If set to true, pass the --dry-run=server flag to validate the manifests and submit them to admission control
+	// without persisting them.
+	DryRun bool
+}
+
+// KubectlApplyWithOptions will take in a file path and apply it to the cluster targeted by KubectlOptions, with the
+// given apply options. If there are any errors, fail the test immediately.
+func KubectlApplyWithOptions(t testing.TestingT, options *KubectlOptions, applyOptions *KubectlApplyOptions, configPath string) {
+	require.NoError(t, KubectlApplyWithOptionsE(t, options, applyOptions, configPath))
+}
+
+// KubectlApplyWithOptionsE will take in a file path and apply it to the cluster targeted by KubectlOptions, with the
+// given apply options. When a server-side apply fails because of fields owned by other field managers, the error is a
+// ServerSideApplyConflicts listing them.
+func KubectlApplyWithOptionsE(t testing.TestingT, options *KubectlOptions, applyOptions *KubectlApplyOptions, configPath string) error {
+	output, err := RunKubectlAndGetOutputE(t, options, formatKubectlApplyArgs(applyOptions, configPath)...)
+	if err != nil {
+		if conflicts := parseServerSideApplyConflicts(output); len(conflicts) > 0 {
+			return NewServerSideApplyConflictsError(conflicts)
+		}
+	}
+	return err
+}
+
+// KubectlApplyFromStringWithOptions will take in a kubernetes resource config as a string and apply it on the cluster
+// specified by the provided kubectl options, with the given apply options. If there are any errors, fail the test
+// immediately.
+func KubectlApplyFromStringWithOptions(t testing.TestingT, options *KubectlOptions, applyOptions *KubectlApplyOptions, configData string) {
+	require.NoError(t, KubectlApplyFromStringWithOptionsE(t, options, applyOptions, configData))
+}
+
+// KubectlApplyFromStringWithOptionsE will take in a kubernetes resource config as a string and apply it on the cluster
+// specified by the provided kubectl options, with the given apply options. If it fails, this will return the error.
+func KubectlApplyFromStringWithOptionsE(t testing.TestingT, options *KubectlOptions, applyOptions *KubectlApplyOptions, configData string) error {
+	tmpfile, err := StoreConfigToTempFileE(t, configData)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpfile)
+	return KubectlApplyWithOptionsE(t, options, applyOptions, tmpfile)
+}
+
+// ApplyAndAssertNoConflicts will take in a file path and apply it server-side to the cluster targeted by
+// KubectlOptions with the given field manager, without forcing conflicts, and fail the test if any of the applied
+// fields is owned by another field manager, or if there is any other error.
+func ApplyAndAssertNoConflicts(t testing.TestingT, options *KubectlOptions, fieldManager string, configPath string) {
+	require.NoError(t, ApplyAndAssertNoConflictsE(t, options, fieldManager, configPath))
+}
+
+// ApplyAndAssertNoConflictsE will take in a file path and apply it server-side to the cluster targeted by
+// KubectlOptions with the given field manager, without forcing conflicts. If any of the applied fields is owned by
+// another field manager, e.g. a controller scaling a deployment whose replicas are set in the manifest, the error is
+// a ServerSideApplyConflicts listing them.
+func ApplyAndAssertNoConflictsE(t testing.TestingT, options *KubectlOptions, fieldManager string, configPath string) error {
+	return KubectlApplyWithOptionsE(t, options, &KubectlApplyOptions{ServerSide: true, FieldManager: fieldManager}, configPath)
+}
+
+// AssertApplyRejected will take in a kubernetes resource config as a string and apply it with a server-side dry run to
+// the cluster targeted by KubectlOptions, and fail the test if it is not rejected with a message matching the given
+// regular expression.
+func AssertApplyRejected(t testing.TestingT, options *KubectlOptions, configData string, expectedReasonRegex string) {
+	require.NoError(t, AssertApplyRejectedE(t, options, configData, expectedReasonRegex))
+}
+
+// AssertApplyRejectedE will take in a kubernetes resource config as a string and apply it with a server-side dry run to
+// the cluster targeted by KubectlOptions, and check that it is rejected with a message matching the given regular
+// expression, e.g. by an admission webhook, such as the ones of OPA Gatekeeper or Kyverno, or a
+// ValidatingAdmissionPolicy. Webhooks with side effects are not called on dry runs: use
+// AssertApplyRejectedWithOptionsE without dry run to test them.
+func AssertApplyRejectedE(t testing.TestingT, options *KubectlOptions, configData string, expectedReasonRegex string) error {
+	return AssertApplyRejectedWithOptionsE(t, options, &KubectlApplyOptions{DryRun: true}, configData, expectedReasonRegex)
+}
+
+// AssertApplyRejectedWithOptions will take in a kubernetes resource config as a string and apply it to the cluster
+// targeted by KubectlOptions with the given apply options, and fail the test if it is not rejected with a message
+// matching the given regular expression.
+func AssertApplyRejectedWithOptions(t testing.TestingT, options *KubectlOptions, applyOptions *KubectlApplyOptions, configData string, expectedReasonRegex string) {
+	require.NoError(t, AssertApplyRejectedWithOptionsE(t, options, applyOptions, configData, expectedReasonRegex))
+}
+
+// AssertApplyRejectedWithOptionsE will take in a kubernetes resource config as a string and apply it to the cluster
+// targeted by KubectlOptions with the given apply options, and check that it is rejected with a message matching the
+// given regular expression. If the config is unexpectedly applied without dry run, it is deleted.
+func AssertApplyRejectedWithOptionsE(t testing.TestingT, options *KubectlOptions, applyOptions *KubectlApplyOptions, configData string, expectedReasonRegex string) error {
+	tmpfile, err := StoreConfigToTempFileE(t, configData)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpfile)
+
+	output, err := RunKubectlAndGetOutputE(t, options, formatKubectlApplyArgs(applyOptions, tmpfile)...)
+	var rejection error
+	if err != nil {
+		rejection = errors.New(strings.TrimSpace(output))
+	} else if !applyOptions.DryRun {
+		if deleteErr := KubectlDeleteE(t, options, tmpfile); deleteErr != nil {
+			options.Logger.Logf(t, "Failed to delete the config that was applied: %s", deleteErr)
+		}
+	}
+	if err := checkRejection(rejection, expectedReasonRegex); err != nil {
+		return fmt.Errorf("config of %s %w", describeConfigResources(configData), err)
+	}
+	options.Logger.Logf(t, "Config was rejected: %s", rejection)
+	return nil
+}
+
+func formatKubectlApplyArgs(applyOptions *KubectlApplyOptions, configPath string) []string {
+	args := []string{"apply"}
+	if applyOptions.ServerSide {
+		args = append(args, "--server-side")
+	}
+	if applyOptions.FieldManager != "" {
+		args = append(args, "--field-manager", applyOptions.FieldManager)
+	}
+	if applyOptions.ForceConflicts {
+		args = append(args, "--force-conflicts")
+	}
+	if applyOptions.DryRun {
+		args = append(args, "--dry-run=server")
+	}
+	return append(args, "-f", configPath)
+}
+
+// parseServerSideApplyConflicts returns the conflicts reported in the output of a failed server-side apply, formatted
+// as the field manager followed by the field, e.g. "kube-controller-manager" using apps/v1: .spec.replicas. A single
+// conflict is reported on one line, while multiple ones are grouped by field manager, with one field per line.
+func parseServerSideApplyConflicts(output string) []string {
+	conflicts := []string{}
+	manager := ""
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if index := strings.Index(line, "conflicts with "); index >= 0 && strings.HasSuffix(line, ":") {
+			manager = strings.TrimSuffix(line[index+len("conflicts with "):], ":")
+			continue
+		}
+		if index := strings.Index(line, "conflict with "); index >= 0 {
+			conflicts = append(conflicts, line[index+len("conflict with "):])
+			manager = ""
+			continue
+		}
+		if manager != "" && strings.HasPrefix(line, "- ") {
+			conflicts = append(conflicts, fmt.Sprintf("%s: %s", manager, strings.TrimPrefix(line, "- ")))
+			continue
+		}
+		manager = ""
+	}
+	return conflicts
+}
+
 // StoreConfigToTempFile will store the provided config data to a temporary file created on the os and return the
 // filename.
 func StoreConfigToTempFile(t testing.TestingT, configData string) string {
@@ -146,3 +316,24 @@ func StoreConfigToTempFileE(t testing.TestingT, configData string) (string, erro
 	_, err = tmpfile.WriteString(configData)
 	return tmpfile.Name(), err
 }
+
+// describeConfigResources returns the kinds and names of the resources of the given config, e.g. ConfigMap/settings,
+// to identify the config in errors.
+func describeConfigResources(configData string) string {
+	decoder := yamlutil.NewYAMLOrJSONDecoder(strings.NewReader(configData), 4096)
+	resources := []string{}
+	for {
+		resource := unstructured.Unstructured{}
+		if err := decoder.Decode(&resource.Object); err != nil {
+			break
+		}
+		if resource.Object == nil {
+			continue
+		}
+		resources = append(resources, resource.GetKind()+"/"+resource.GetName())
+	}
+	if len(resources) == 0 {
+		return "unknown resources"
+	}
+	return strings.Join(resources, ", ")
+}
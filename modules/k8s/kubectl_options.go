@@ -17,6 +17,7 @@ type KubectlOptions struct {
 	InClusterAuth  bool
 	RestConfig     *rest.Config
 	Logger         *logger.Logger
+	LogLevel       logger.Level // the minimum level of the messages of kubectl commands to log, e.g. logger.LevelInfo to not log their output
 	RequestTimeout time.Duration
 }
 
@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -89,3 +90,104 @@ current-context: dummy-context
 	})
 
 }
+
+func TestKubectlApplyWithOptionsReportsServerSideApplyConflicts(t *testing.T) {
+	t.Parallel()
+
+	uniqueID := strings.ToLower(random.UniqueId())
+	options := NewKubectlOptions("", "", uniqueID)
+	configData := fmt.Sprintf(ExampleDeploymentYAMLTemplate, uniqueID)
+	KubectlApplyFromString(t, options, configData)
+	defer KubectlDeleteFromString(t, options, configData)
+
+	// The replicas of the deployment are now owned by the client-side apply, so applying them with another field
+	// manager conflicts
+	tmpfile := StoreConfigToTempFile(t, strings.Replace(configData, "replicas: 2", "replicas: 3", 1))
+	defer os.Remove(tmpfile)
+	err := ApplyAndAssertNoConflictsE(t, options, "terratest", tmpfile)
+	require.Error(t, err)
+	conflicts, ok := err.(ServerSideApplyConflicts)
+	require.True(t, ok, "unexpected error: %v", err)
+	require.Contains(t, strings.Join(conflicts.Conflicts, "\n"), ".spec.replicas")
+
+	KubectlApplyWithOptions(t, options, &KubectlApplyOptions{ServerSide: true, FieldManager: "terratest", ForceConflicts: true}, tmpfile)
+	ApplyAndAssertNoConflicts(t, options, "terratest", tmpfile)
+}
+
+func TestParseServerSideApplyConflicts(t *testing.T) {
+	t.Parallel()
+
+	single := `error: Apply failed with 1 conflict: conflict with "kube-controller-manager" using apps/v1 at 2024-01-02T03:04:05Z: .spec.replicas
+Please review the fields above--they currently have other managers.`
+	assert.Equal(
+		t,
+		[]string{`"kube-controller-manager" using apps/v1 at 2024-01-02T03:04:05Z: .spec.replicas`},
+		parseServerSideApplyConflicts(single),
+	)
+
+	multiple := `error: Apply failed with 3 conflicts: conflicts with "helm":
+- .spec.replicas
+- .spec.template.spec.containers[name="nginx"].image
+conflicts with "kubectl-client-side-apply" using apps/v1:
+- .metadata.labels.app
+
+Please review the fields above--they currently have other managers. Here are the ways you can resolve this warning:
+- If you intend to manage all of these fields, please re-run the apply command with the --force-conflicts flag.`
+	assert.Equal(t, []string{
+		`"helm": .spec.replicas`,
+		`"helm": .spec.template.spec.containers[name="nginx"].image`,
+		`"kubectl-client-side-apply" using apps/v1: .metadata.labels.app`,
+	}, parseServerSideApplyConflicts(multiple))
+
+	assert.Empty(t, parseServerSideApplyConflicts("error: the server doesn't have a resource type \"foo\""))
+}
+
+func TestFormatKubectlApplyArgs(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"apply", "-f", "manifest.yaml"}, formatKubectlApplyArgs(&KubectlApplyOptions{}, "manifest.yaml"))
+	assert.Equal(
+		t,
+		[]string{"apply", "--server-side", "--field-manager", "argocd-controller", "--force-conflicts", "-f", "manifest.yaml"},
+		formatKubectlApplyArgs(&KubectlApplyOptions{ServerSide: true, FieldManager: "argocd-controller", ForceConflicts: true}, "manifest.yaml"),
+	)
+	assert.Equal(t, []string{"apply", "--dry-run=server", "-f", "manifest.yaml"}, formatKubectlApplyArgs(&KubectlApplyOptions{DryRun: true}, "manifest.yaml"))
+}
+
+func TestDescribeConfigResources(t *testing.T) {
+	t.Parallel()
+
+	config := fmt.Sprintf(exampleConfigMapForRejectionYAMLTemplate, "settings", "default") + "---\n" +
+		fmt.Sprintf(exampleConfigMapForRejectionYAMLTemplate, "other", "default")
+	assert.Equal(t, "ConfigMap/settings, ConfigMap/other", describeConfigResources(config))
+	assert.Equal(t, "unknown resources", describeConfigResources("not: [valid"))
+}
+
+func TestAssertApplyRejected(t *testing.T) {
+	t.Parallel()
+
+	namespaceName := strings.ToLower(random.UniqueId())
+	options := NewKubectlOptions("", "", namespaceName)
+	CreateNamespace(t, options, namespaceName)
+	defer DeleteNamespace(t, options, namespaceName)
+
+	invalid := fmt.Sprintf(exampleConfigMapForRejectionYAMLTemplate, "Invalid_Name", namespaceName)
+	AssertApplyRejected(t, options, invalid, "metadata.name: Invalid value")
+	AssertApplyRejectedWithOptions(t, options, &KubectlApplyOptions{}, invalid, "Invalid value")
+
+	valid := fmt.Sprintf(exampleConfigMapForRejectionYAMLTemplate, "valid", namespaceName)
+	require.EqualError(t, AssertApplyRejectedE(t, options, valid, "Invalid value"), `config of ConfigMap/valid was accepted, expected it to be rejected with "Invalid value"`)
+	require.Error(t, AssertApplyRejectedWithOptionsE(t, options, &KubectlApplyOptions{}, valid, "Invalid value"))
+	_, err := RunKubectlAndGetOutputE(t, options, "get", "configmap", "valid")
+	require.Error(t, err)
+}
+
+const exampleConfigMapForRejectionYAMLTemplate = `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s
+  namespace: %s
+data:
+  key: value
+`
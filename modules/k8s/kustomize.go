@@ -0,0 +1,183 @@
+package k8s
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// KustomizeOptions represents the customizations to apply when building a kustomization, on top of the ones of its
+// kustomization file.
+type KustomizeOptions struct {
+	// The path of the overlay to build, relative to the kustomization directory (e.g. overlays/staging). Defaults to
+	// the directory itself.
+	Overlay string
+
+	// If set, the namespace to set on all the resources.
+	Namespace string
+
+	// If set, the prefix to add to the names of all the resources.
+	NamePrefix string
+
+	// The patches to apply to the resources, e.g. to point a deployment to the image under test.
+	Patches []KustomizePatch
+
+	// The images to replace in the resources.
+	Images []KustomizeImage
+}
+
+// KustomizePatch is a strategic merge or JSON 6902 patch to apply to the resources of a kustomization.
+type KustomizePatch struct {
+	// The patch, in YAML. Strategic merge patches identify the resource to patch with their kind and name, unless the
+	// target is set, while JSON 6902 patches require a target.
+	Patch string
+
+	// If set, the kind and name of the resources to patch.
+	TargetKind string
+	TargetName string
+}
+
+// KustomizeImage is the replacement of an image of the resources of a kustomization. The new name or tag are kept
+// from the image if empty.
+type KustomizeImage struct {
+	Name    string
+	NewName string
+	NewTag  string
+}
+
+// KustomizeBuild renders the kustomization in the given directory, with the customizations of the kustomize options
+// if not nil, and returns the rendered manifests. This will fail the test if there is an error.
+func KustomizeBuild(t testing.TestingT, options *KubectlOptions, kustomizeOptions *KustomizeOptions, dir string) string {
+	manifests, err := KustomizeBuildE(t, options, kustomizeOptions, dir)
+	require.NoError(t, err)
+	return manifests
+}
+
+// KustomizeBuildE renders the kustomization in the given directory, with the customizations of the kustomize options
+// if not nil, and returns the rendered manifests. This uses the kustomize version embedded in kubectl.
+func KustomizeBuildE(t testing.TestingT, options *KubectlOptions, kustomizeOptions *KustomizeOptions, dir string) (string, error) {
+	if kustomizeOptions == nil {
+		kustomizeOptions = &KustomizeOptions{}
+	}
+	dir = filepath.Join(dir, kustomizeOptions.Overlay)
+
+	if len(kustomizeOptions.Patches) > 0 || len(kustomizeOptions.Images) > 0 || kustomizeOptions.Namespace != "" || kustomizeOptions.NamePrefix != "" {
+		// Add the customizations in a kustomization wrapping the directory, so its own files are left untouched
+		tmpDir, err := os.MkdirTemp("", "kustomize")
+		if err != nil {
+			return "", err
+		}
+		defer os.RemoveAll(tmpDir)
+
+		kustomization, err := formatKustomization(kustomizeOptions, tmpDir, dir)
+		if err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, "kustomization.yaml"), kustomization, 0644); err != nil {
+			return "", err
+		}
+		dir = tmpDir
+	}
+
+	// Only keep stdout, so the warnings of kustomize are not mixed with the manifests
+	return shell.RunCommandAndGetStdOutE(t, getKubectlCommand(options, "kustomize", dir))
+}
+
+// KubectlApplyKustomize renders the kustomization in the given directory, with the customizations of the kustomize
+// options if not nil, and applies it to the cluster targeted by KubectlOptions. This will fail the test if there is
+// an error.
+func KubectlApplyKustomize(t testing.TestingT, options *KubectlOptions, kustomizeOptions *KustomizeOptions, dir string) {
+	require.NoError(t, KubectlApplyKustomizeE(t, options, kustomizeOptions, dir))
+}
+
+// KubectlApplyKustomizeE renders the kustomization in the given directory, with the customizations of the kustomize
+// options if not nil, and applies it to the cluster targeted by KubectlOptions.
+func KubectlApplyKustomizeE(t testing.TestingT, options *KubectlOptions, kustomizeOptions *KustomizeOptions, dir string) error {
+	manifests, err := KustomizeBuildE(t, options, kustomizeOptions, dir)
+	if err != nil {
+		return err
+	}
+	return KubectlApplyFromStringE(t, options, manifests)
+}
+
+// KubectlDeleteKustomize renders the kustomization in the given directory, with the customizations of the kustomize
+// options if not nil, and deletes it from the cluster targeted by KubectlOptions. This will fail the test if there is
+// an error.
+func KubectlDeleteKustomize(t testing.TestingT, options *KubectlOptions, kustomizeOptions *KustomizeOptions, dir string) {
+	require.NoError(t, KubectlDeleteKustomizeE(t, options, kustomizeOptions, dir))
+}
+
+// KubectlDeleteKustomizeE renders the kustomization in the given directory, with the customizations of the kustomize
+// options if not nil, and deletes it from the cluster targeted by KubectlOptions.
+func KubectlDeleteKustomizeE(t testing.TestingT, options *KubectlOptions, kustomizeOptions *KustomizeOptions, dir string) error {
+	manifests, err := KustomizeBuildE(t, options, kustomizeOptions, dir)
+	if err != nil {
+		return err
+	}
+	return KubectlDeleteFromStringE(t, options, manifests)
+}
+
+// kustomization is the content of the kustomization file wrapping a directory to customize it.
+type kustomization struct {
+	APIVersion string               `yaml:"apiVersion"`
+	Kind       string               `yaml:"kind"`
+	Resources  []string             `yaml:"resources"`
+	Namespace  string               `yaml:"namespace,omitempty"`
+	NamePrefix string               `yaml:"namePrefix,omitempty"`
+	Patches    []kustomizationPatch `yaml:"patches,omitempty"`
+	Images     []kustomizationImage `yaml:"images,omitempty"`
+}
+
+type kustomizationPatch struct {
+	Patch  string                    `yaml:"patch"`
+	Target *kustomizationPatchTarget `yaml:"target,omitempty"`
+}
+
+type kustomizationPatchTarget struct {
+	Kind string `yaml:"kind,omitempty"`
+	Name string `yaml:"name,omitempty"`
+}
+
+type kustomizationImage struct {
+	Name    string `yaml:"name"`
+	NewName string `yaml:"newName,omitempty"`
+	NewTag  string `yaml:"newTag,omitempty"`
+}
+
+// formatKustomization returns the kustomization file, in the given directory, applying the kustomize options to the
+// kustomization of the other given directory.
+func formatKustomization(kustomizeOptions *KustomizeOptions, kustomizationDir string, dir string) ([]byte, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	// Kustomize only loads the directories of the resources by relative path
+	resource, err := filepath.Rel(kustomizationDir, absDir)
+	if err != nil {
+		return nil, err
+	}
+
+	config := kustomization{
+		APIVersion: "kustomize.config.k8s.io/v1beta1",
+		Kind:       "Kustomization",
+		Resources:  []string{filepath.ToSlash(resource)},
+		Namespace:  kustomizeOptions.Namespace,
+		NamePrefix: kustomizeOptions.NamePrefix,
+	}
+	for _, patch := range kustomizeOptions.Patches {
+		configPatch := kustomizationPatch{Patch: patch.Patch}
+		if patch.TargetKind != "" || patch.TargetName != "" {
+			configPatch.Target = &kustomizationPatchTarget{Kind: patch.TargetKind, Name: patch.TargetName}
+		}
+		config.Patches = append(config.Patches, configPatch)
+	}
+	for _, image := range kustomizeOptions.Images {
+		config.Images = append(config.Images, kustomizationImage(image))
+	}
+	return yaml.Marshal(config)
+}
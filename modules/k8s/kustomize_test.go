@@ -0,0 +1,73 @@
+//go:build kubeall || kubernetes
+// +build kubeall kubernetes
+
+// NOTE: we have build tags to differentiate kubernetes tests from non-kubernetes tests. This is done because minikube
+// is heavy and can interfere with docker related tests in terratest. Specifically, many of the tests start to fail with
+// `connection refused` errors from `minikube`. To avoid overloading the system, we run the kubernetes tests and helm
+// tests separately from the others. This may not be necessary if you have a sufficiently powerful machine.  We
+// recommend at least 4 cores and 16GB of RAM if you want to run all the tests together.
+
+package k8s
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKustomizeBuildWithOptions(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte("resources:\n- configmap.yaml\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "configmap.yaml"), []byte(exampleKustomizeConfigMapYAML), 0644))
+
+	options := NewKubectlOptions("", "", "")
+	manifests := KustomizeBuild(t, options, nil, dir)
+	assert.Contains(t, manifests, "name: settings")
+
+	manifests = KustomizeBuild(t, options, &KustomizeOptions{
+		Namespace:  "staging",
+		NamePrefix: "staging-",
+		Patches:    []KustomizePatch{{Patch: "- op: replace\n  path: /data/level\n  value: debug\n", TargetKind: "ConfigMap", TargetName: "settings"}},
+	}, dir)
+	assert.Contains(t, manifests, "name: staging-settings")
+	assert.Contains(t, manifests, "namespace: staging")
+	assert.Contains(t, manifests, "level: debug")
+}
+
+func TestFormatKustomization(t *testing.T) {
+	t.Parallel()
+
+	kustomization, err := formatKustomization(&KustomizeOptions{
+		Namespace: "staging",
+		Patches:   []KustomizePatch{{Patch: "kind: Deployment\n"}},
+		Images:    []KustomizeImage{{Name: "nginx", NewTag: "1.25"}},
+	}, "/tmp/kustomize", "/tmp/app/overlays/staging")
+	require.NoError(t, err)
+
+	assert.Equal(t, `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+    - ../app/overlays/staging
+namespace: staging
+patches:
+    - patch: |
+        kind: Deployment
+images:
+    - name: nginx
+      newTag: "1.25"
+`, string(kustomization))
+}
+
+const exampleKustomizeConfigMapYAML = `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  level: info
+`
@@ -0,0 +1,186 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// metricsAPIPath is the path of the resource metrics API, served by the metrics server.
+const metricsAPIPath = "/apis/metrics.k8s.io/v1beta1"
+
+// PodMetrics is the resource usage of the containers of a Pod, as reported by the metrics.k8s.io API.
+type PodMetrics struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// The time the usage was measured at, and the window it was averaged over.
+	Timestamp metav1.Time     `json:"timestamp"`
+	Window    metav1.Duration `json:"window"`
+
+	Containers []ContainerMetrics `json:"containers"`
+}
+
+// ContainerMetrics is the resource usage of a container, as reported by the metrics.k8s.io API.
+type ContainerMetrics struct {
+	Name  string              `json:"name"`
+	Usage corev1.ResourceList `json:"usage"`
+}
+
+// NodeMetrics is the resource usage of a Node, as reported by the metrics.k8s.io API.
+type NodeMetrics struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// The time the usage was measured at, and the window it was averaged over.
+	Timestamp metav1.Time     `json:"timestamp"`
+	Window    metav1.Duration `json:"window"`
+
+	Usage corev1.ResourceList `json:"usage"`
+}
+
+// GetPodMetrics returns the resource usage of the Pod in the provided namespace with the given name, from the metrics
+// server. This will fail the test if there is an error.
+func GetPodMetrics(t testing.TestingT, options *KubectlOptions, podName string) *PodMetrics {
+	metrics, err := GetPodMetricsE(t, options, podName)
+	require.NoError(t, err)
+	return metrics
+}
+
+// GetPodMetricsE returns the resource usage of the Pod in the provided namespace with the given name, from the metrics
+// server.
+func GetPodMetricsE(t testing.TestingT, options *KubectlOptions, podName string) (*PodMetrics, error) {
+	metrics := &PodMetrics{}
+	path := fmt.Sprintf("%s/namespaces/%s/pods/%s", metricsAPIPath, options.Namespace, podName)
+	if err := getMetricsE(t, options, path, metrics); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// GetNodeMetrics returns the resource usage of the Node with the given name, from the metrics server. This will fail
+// the test if there is an error.
+func GetNodeMetrics(t testing.TestingT, options *KubectlOptions, nodeName string) *NodeMetrics {
+	metrics, err := GetNodeMetricsE(t, options, nodeName)
+	require.NoError(t, err)
+	return metrics
+}
+
+// GetNodeMetricsE returns the resource usage of the Node with the given name, from the metrics server.
+func GetNodeMetricsE(t testing.TestingT, options *KubectlOptions, nodeName string) (*NodeMetrics, error) {
+	metrics := &NodeMetrics{}
+	if err := getMetricsE(t, options, fmt.Sprintf("%s/nodes/%s", metricsAPIPath, nodeName), metrics); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// AssertPodMemoryBelow checks that the total memory usage of the containers of the Pod is below the given quantity
+// (e.g. resource.MustParse("256Mi")). This will fail the test if the usage is higher or if there is an error.
+func AssertPodMemoryBelow(t testing.TestingT, options *KubectlOptions, podName string, max resource.Quantity) {
+	require.NoError(t, AssertPodMemoryBelowE(t, options, podName, max))
+}
+
+// AssertPodMemoryBelowE checks that the total memory usage of the containers of the Pod is below the given quantity
+// (e.g. resource.MustParse("256Mi")).
+func AssertPodMemoryBelowE(t testing.TestingT, options *KubectlOptions, podName string, max resource.Quantity) error {
+	metrics, err := GetPodMetricsE(t, options, podName)
+	if err != nil {
+		return err
+	}
+	return checkPodUsageBelow(metrics, corev1.ResourceMemory, max)
+}
+
+// AssertPodCPUBelow checks that the total CPU usage of the containers of the Pod is below the given quantity (e.g.
+// resource.MustParse("500m")). This will fail the test if the usage is higher or if there is an error.
+func AssertPodCPUBelow(t testing.TestingT, options *KubectlOptions, podName string, max resource.Quantity) {
+	require.NoError(t, AssertPodCPUBelowE(t, options, podName, max))
+}
+
+// AssertPodCPUBelowE checks that the total CPU usage of the containers of the Pod is below the given quantity (e.g.
+// resource.MustParse("500m")).
+func AssertPodCPUBelowE(t testing.TestingT, options *KubectlOptions, podName string, max resource.Quantity) error {
+	metrics, err := GetPodMetricsE(t, options, podName)
+	if err != nil {
+		return err
+	}
+	return checkPodUsageBelow(metrics, corev1.ResourceCPU, max)
+}
+
+// AssertPodUsageWithinLimits checks that the CPU and memory usage of each container of the Pod is below its limits,
+// and if useRequests is true, below its requests. This will fail the test if the usage is higher or if there is an
+// error.
+func AssertPodUsageWithinLimits(t testing.TestingT, options *KubectlOptions, podName string, useRequests bool) {
+	require.NoError(t, AssertPodUsageWithinLimitsE(t, options, podName, useRequests))
+}
+
+// AssertPodUsageWithinLimitsE checks that the CPU and memory usage of each container of the Pod is below its limits,
+// and if useRequests is true, below its requests. Resources without a limit or a request are not checked against it.
+func AssertPodUsageWithinLimitsE(t testing.TestingT, options *KubectlOptions, podName string, useRequests bool) error {
+	pod, err := GetPodE(t, options, podName)
+	if err != nil {
+		return err
+	}
+	metrics, err := GetPodMetricsE(t, options, podName)
+	if err != nil {
+		return err
+	}
+	return checkPodUsageWithinLimits(pod, metrics, useRequests)
+}
+
+func getMetricsE(t testing.TestingT, options *KubectlOptions, path string, metrics interface{}) error {
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return err
+	}
+	body, err := clientset.CoreV1().RESTClient().Get().AbsPath(path).DoRaw(context.Background())
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, metrics)
+}
+
+// checkPodUsageBelow checks that the total usage of the given resource by the containers is below the given quantity.
+func checkPodUsageBelow(metrics *PodMetrics, resourceName corev1.ResourceName, max resource.Quantity) error {
+	total := resource.Quantity{}
+	for _, container := range metrics.Containers {
+		total.Add(container.Usage[resourceName])
+	}
+	if total.Cmp(max) > 0 {
+		return fmt.Errorf("pod %s uses %s of %s, expected below %s", metrics.Name, total.String(), resourceName, max.String())
+	}
+	return nil
+}
+
+// checkPodUsageWithinLimits checks that the CPU and memory usage of each container is below its limits, and if
+// useRequests is true, below its requests.
+func checkPodUsageWithinLimits(pod *corev1.Pod, metrics *PodMetrics, useRequests bool) error {
+	for _, container := range pod.Spec.Containers {
+		bounds := map[string]corev1.ResourceList{"limit": container.Resources.Limits}
+		if useRequests {
+			bounds["request"] = container.Resources.Requests
+		}
+		for _, containerMetrics := range metrics.Containers {
+			if containerMetrics.Name != container.Name {
+				continue
+			}
+			for _, resourceName := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+				usage := containerMetrics.Usage[resourceName]
+				for _, boundName := range []string{"limit", "request"} {
+					bound, hasBound := bounds[boundName][resourceName]
+					if hasBound && usage.Cmp(bound) > 0 {
+						return fmt.Errorf("container %s of pod %s uses %s of %s, above its %s of %s", container.Name, pod.Name, usage.String(), resourceName, boundName, bound.String())
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
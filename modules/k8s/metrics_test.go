@@ -0,0 +1,71 @@
+//go:build kubeall || kubernetes
+// +build kubeall kubernetes
+
+// NOTE: we have build tags to differentiate kubernetes tests from non-kubernetes tests. This is done because minikube
+// is heavy and can interfere with docker related tests in terratest. Specifically, many of the tests start to fail with
+// `connection refused` errors from `minikube`. To avoid overloading the system, we run the kubernetes tests and helm
+// tests separately from the others. This may not be necessary if you have a sufficiently powerful machine.  We
+// recommend at least 4 cores and 16GB of RAM if you want to run all the tests together.
+
+package k8s
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetPodMetricsEReturnsErrorForNonExistantPod(t *testing.T) {
+	t.Parallel()
+
+	options := NewKubectlOptions("", "", "default")
+	_, err := GetPodMetricsE(t, options, "i-dont-exist")
+	require.Error(t, err)
+}
+
+func TestCheckPodUsage(t *testing.T) {
+	t.Parallel()
+
+	metrics := &PodMetrics{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+  "kind": "PodMetrics",
+  "apiVersion": "metrics.k8s.io/v1beta1",
+  "metadata": {"name": "web", "namespace": "default"},
+  "timestamp": "2024-01-02T03:04:05Z",
+  "window": "15s",
+  "containers": [
+    {"name": "app", "usage": {"cpu": "150m", "memory": "200Mi"}},
+    {"name": "sidecar", "usage": {"cpu": "20m", "memory": "30Mi"}}
+  ]
+}`), metrics))
+
+	require.NoError(t, checkPodUsageBelow(metrics, corev1.ResourceMemory, resource.MustParse("256Mi")))
+	require.Error(t, checkPodUsageBelow(metrics, corev1.ResourceMemory, resource.MustParse("200Mi")))
+	require.NoError(t, checkPodUsageBelow(metrics, corev1.ResourceCPU, resource.MustParse("200m")))
+	require.Error(t, checkPodUsageBelow(metrics, corev1.ResourceCPU, resource.MustParse("0.1")))
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{
+				Name: "app",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+					Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("256Mi")},
+				},
+			},
+			{Name: "sidecar"},
+		}},
+	}
+	require.NoError(t, checkPodUsageWithinLimits(pod, metrics, false))
+	require.EqualError(t, checkPodUsageWithinLimits(pod, metrics, true), "container app of pod web uses 150m of cpu, above its request of 100m")
+
+	// The limits of containers without requests are still checked with useRequests
+	pod.Spec.Containers[1].Resources.Limits = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("16Mi")}
+	pod.Spec.Containers[0].Resources.Requests = nil
+	require.EqualError(t, checkPodUsageWithinLimits(pod, metrics, true), "container sidecar of pod web uses 30Mi of memory, above its limit of 16Mi")
+}
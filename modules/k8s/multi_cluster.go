@@ -0,0 +1,144 @@
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	go_test "testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// Clusters holds the KubectlOptions of the clusters a test targets by name (e.g. hub and spoke, or blue and green), so
+// the same assertions can be run against each of them and resources copied between them.
+type Clusters struct {
+	options map[string]*KubectlOptions
+}
+
+// NewClusters returns an empty set of clusters, to add the clusters to with Add or AddContext.
+func NewClusters() *Clusters {
+	return &Clusters{options: map[string]*KubectlOptions{}}
+}
+
+// Add adds the cluster targeted by the given options with the given name, replacing any cluster with the same name,
+// and returns the clusters to chain the calls.
+func (clusters *Clusters) Add(name string, options *KubectlOptions) *Clusters {
+	clusters.options[name] = options
+	return clusters
+}
+
+// AddContext adds the cluster of the given context of the kubeconfig at the given path, which defaults to the one of
+// kubectl if empty, with the given name, targeting the given namespace, and returns the clusters to chain the calls.
+func (clusters *Clusters) AddContext(name string, contextName string, configPath string, namespace string) *Clusters {
+	return clusters.Add(name, NewKubectlOptions(contextName, configPath, namespace))
+}
+
+// Names returns the names of the clusters, in alphabetical order.
+func (clusters *Clusters) Names() []string {
+	names := make([]string, 0, len(clusters.options))
+	for name := range clusters.options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Options returns the KubectlOptions of the cluster with the given name. This will fail the test if there is no such
+// cluster.
+func (clusters *Clusters) Options(t testing.TestingT, name string) *KubectlOptions {
+	options, err := clusters.OptionsE(name)
+	require.NoError(t, err)
+	return options
+}
+
+// OptionsE returns the KubectlOptions of the cluster with the given name.
+func (clusters *Clusters) OptionsE(name string) (*KubectlOptions, error) {
+	options, ok := clusters.options[name]
+	if !ok {
+		return nil, fmt.Errorf("no cluster named %s, got %v", name, clusters.Names())
+	}
+	return options, nil
+}
+
+// WithNamespace returns a copy of the clusters targeting the given namespace in each cluster.
+func (clusters *Clusters) WithNamespace(namespace string) *Clusters {
+	copied := NewClusters()
+	for name, options := range clusters.options {
+		namespacedOptions := *options
+		namespacedOptions.Namespace = namespace
+		copied.Add(name, &namespacedOptions)
+	}
+	return copied
+}
+
+// RunInEachCluster runs the given function in a subtest for each cluster, in alphabetical order of their names, so the
+// same assertions are checked against all of them and the failures reported per cluster. Call t.Parallel in the
+// function to check the clusters in parallel. Note that go_test is an alias to Golang's native testing package created
+// to avoid naming conflicts with Terratest's testing package.
+func (clusters *Clusters) RunInEachCluster(t *go_test.T, assertions func(t *go_test.T, name string, options *KubectlOptions)) {
+	for _, name := range clusters.Names() {
+		name := name
+		options := clusters.options[name]
+		t.Run(name, func(t *go_test.T) {
+			assertions(t, name, options)
+		})
+	}
+}
+
+// CopyResource copies the resource of the given type (e.g. configmap) and name from the namespace of the source
+// options to the namespace of the destination options, which can target another cluster. This will fail the test if
+// there is an error.
+func CopyResource(t testing.TestingT, sourceOptions *KubectlOptions, destinationOptions *KubectlOptions, resourceType string, name string) {
+	require.NoError(t, CopyResourceE(t, sourceOptions, destinationOptions, resourceType, name))
+}
+
+// CopyResourceE copies the resource of the given type (e.g. configmap) and name from the namespace of the source
+// options to the namespace of the destination options, which can target another cluster. The fields set by the source
+// cluster, such as the status, UID and owner references, are not copied, and the resource is applied in the
+// destination, so it is updated if it already exists.
+func CopyResourceE(t testing.TestingT, sourceOptions *KubectlOptions, destinationOptions *KubectlOptions, resourceType string, name string) error {
+	// The resource is not logged, as it holds the data of Secrets
+	cmd := getKubectlCommand(sourceOptions, "get", resourceType, name, "-o", "json")
+	cmd.Logger = logger.Discard
+	output, err := shell.RunCommandAndGetStdOutE(t, cmd)
+	if err != nil {
+		return err
+	}
+	configData, err := prepareResourceForCopy([]byte(output), destinationOptions.Namespace)
+	if err != nil {
+		return err
+	}
+	sourceOptions.Logger.Logf(t, "Copying %s from namespace %q to namespace %q", describeConfigResources(configData), sourceOptions.Namespace, destinationOptions.Namespace)
+	return KubectlApplyFromStringE(t, destinationOptions, configData)
+}
+
+// prepareResourceForCopy returns the given JSON resource without the fields set by its cluster, in the given namespace
+// if the resource is namespaced and the namespace is not empty.
+func prepareResourceForCopy(data []byte, namespace string) (string, error) {
+	resource := &unstructured.Unstructured{}
+	if err := resource.UnmarshalJSON(data); err != nil {
+		return "", err
+	}
+
+	unstructured.RemoveNestedField(resource.Object, "status")
+	for _, field := range []string{"uid", "resourceVersion", "generation", "creationTimestamp", "managedFields", "ownerReferences", "selfLink"} {
+		unstructured.RemoveNestedField(resource.Object, "metadata", field)
+	}
+	annotations := resource.GetAnnotations()
+	delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	resource.SetAnnotations(annotations)
+	if namespace != "" && resource.GetNamespace() != "" {
+		resource.SetNamespace(namespace)
+	}
+
+	copied, err := json.Marshal(resource.Object)
+	return string(copied), err
+}
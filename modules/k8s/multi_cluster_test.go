@@ -0,0 +1,103 @@
+//go:build kubeall || kubernetes
+// +build kubeall kubernetes
+
+// NOTE: we have build tags to differentiate kubernetes tests from non-kubernetes tests. This is done because minikube
+// is heavy and can interfere with docker related tests in terratest. Specifically, many of the tests start to fail with
+// `connection refused` errors from `minikube`. To avoid overloading the system, we run the kubernetes tests and helm
+// tests separately from the others. This may not be necessary if you have a sufficiently powerful machine.  We
+// recommend at least 4 cores and 16GB of RAM if you want to run all the tests together.
+
+package k8s
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terratest/modules/random"
+)
+
+func TestClusters(t *testing.T) {
+	t.Parallel()
+
+	clusters := NewClusters().
+		AddContext("hub", "kind-hub", "", "default").
+		AddContext("spoke", "kind-spoke", "/tmp/spoke.kubeconfig", "default")
+	assert.Equal(t, []string{"hub", "spoke"}, clusters.Names())
+	assert.Equal(t, "/tmp/spoke.kubeconfig", clusters.Options(t, "spoke").ConfigPath)
+	_, err := clusters.OptionsE("edge")
+	require.Error(t, err)
+
+	namespaced := clusters.WithNamespace("apps")
+	assert.Equal(t, "apps", namespaced.Options(t, "hub").Namespace)
+	assert.Equal(t, "default", clusters.Options(t, "hub").Namespace)
+
+	visited := []string{}
+	clusters.RunInEachCluster(t, func(t *testing.T, name string, options *KubectlOptions) {
+		visited = append(visited, name+"/"+options.ContextName)
+	})
+	assert.Equal(t, []string{"hub/kind-hub", "spoke/kind-spoke"}, visited)
+}
+
+func TestCopyResourceBetweenNamespaces(t *testing.T) {
+	t.Parallel()
+
+	sourceNamespace := strings.ToLower(random.UniqueId())
+	destinationNamespace := strings.ToLower(random.UniqueId())
+	sourceOptions := NewKubectlOptions("", "", sourceNamespace)
+	destinationOptions := NewKubectlOptions("", "", destinationNamespace)
+	for _, options := range []*KubectlOptions{sourceOptions, destinationOptions} {
+		CreateNamespace(t, options, options.Namespace)
+		defer DeleteNamespace(t, options, options.Namespace)
+	}
+	KubectlApplyFromString(t, sourceOptions, fmt.Sprintf(exampleCopiedConfigMapYAMLTemplate, sourceNamespace))
+
+	CopyResource(t, sourceOptions, destinationOptions, "configmap", "settings")
+	output, err := RunKubectlAndGetOutputE(t, destinationOptions, "get", "configmap", "settings", "-o", "jsonpath={.data.level}")
+	require.NoError(t, err)
+	assert.Equal(t, "info", output)
+}
+
+func TestPrepareResourceForCopy(t *testing.T) {
+	t.Parallel()
+
+	resource := `{
+  "apiVersion": "v1",
+  "kind": "ConfigMap",
+  "metadata": {
+    "name": "settings",
+    "namespace": "source",
+    "uid": "4c0d3b8e",
+    "resourceVersion": "1234",
+    "creationTimestamp": "2024-01-01T00:00:00Z",
+    "annotations": {"kubectl.kubernetes.io/last-applied-configuration": "{}"}
+  },
+  "data": {"level": "info"}
+}`
+	copied, err := prepareResourceForCopy([]byte(resource), "destination")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+  "apiVersion": "v1",
+  "kind": "ConfigMap",
+  "metadata": {"name": "settings", "namespace": "destination"},
+  "data": {"level": "info"}
+}`, copied)
+
+	// Cluster wide resources stay cluster wide
+	copied, err = prepareResourceForCopy([]byte(`{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "apps"}, "status": {"phase": "Active"}}`), "destination")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "apps"}}`, copied)
+}
+
+const exampleCopiedConfigMapYAMLTemplate = `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: %s
+data:
+  level: info
+`
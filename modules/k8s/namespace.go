@@ -2,14 +2,28 @@ package k8s
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/gruntwork-io/terratest/modules/retry"
 	"github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
 )
 
+// namespaceDeletionPollInterval is the interval between the checks of the deletion of a namespace.
+const namespaceDeletionPollInterval = 2 * time.Second
+
 // CreateNamespace will create a new Kubernetes namespace on the cluster targeted by the provided options. This will
 // fail the test if there is an error in creating the namespace.
 func CreateNamespace(t testing.TestingT, options *KubectlOptions, namespaceName string) {
@@ -114,3 +128,160 @@ func ListNamespacesE(t testing.TestingT, options *KubectlOptions, filters metav1
 
 	return namespaceList.Items, nil
 }
+
+// DeleteNamespaceAndWait deletes the requested namespace from the Kubernetes cluster targeted by the provided options
+// and waits until it is actually deleted, clearing the given finalizers from the resources stuck in deletion. This will
+// fail the test if there is an error, or if the namespace is not deleted within the timeout.
+func DeleteNamespaceAndWait(t testing.TestingT, options *KubectlOptions, namespaceName string, clearFinalizers []string, timeout time.Duration) {
+	require.NoError(t, DeleteNamespaceAndWaitE(t, options, namespaceName, clearFinalizers, timeout))
+}
+
+// DeleteNamespaceAndWaitE deletes the requested namespace from the Kubernetes cluster targeted by the provided options
+// and waits until it is actually deleted. While the namespace is terminating, the resources left in it with finalizers
+// are looked up, and the given finalizers, which must be known to be safe to skip (e.g. the ones of a controller that
+// was uninstalled before the namespace was deleted), are cleared from the resources being deleted. If the namespace is
+// not deleted within the timeout, the returned NamespaceNotDeleted error reports the conditions of the namespace and
+// the resources blocking its deletion.
+func DeleteNamespaceAndWaitE(t testing.TestingT, options *KubectlOptions, namespaceName string, clearFinalizers []string, timeout time.Duration) error {
+	if err := DeleteNamespaceE(t, options, namespaceName); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	var notDeletedErr error
+	retries := int(timeout/namespaceDeletionPollInterval) + 1
+	statusMsg := fmt.Sprintf("Wait for namespace %s to be deleted.", namespaceName)
+	message, err := retry.DoWithRetryE(
+		t,
+		statusMsg,
+		retries,
+		namespaceDeletionPollInterval,
+		func() (string, error) {
+			namespace, err := GetNamespaceE(t, options, namespaceName)
+			if errors.IsNotFound(err) {
+				return fmt.Sprintf("Namespace %s is now deleted", namespaceName), nil
+			}
+			if err != nil {
+				return "", err
+			}
+
+			stuckResources := []string{}
+			listErrors := []string{}
+			if len(getNamespaceDeletionBlockers(namespace)) > 0 {
+				stuckResources, listErrors, err = clearStuckNamespaceResourcesE(t, options, namespaceName, clearFinalizers)
+				if err != nil {
+					return "", err
+				}
+			}
+			notDeletedErr = NewNamespaceNotDeletedError(namespace, stuckResources, listErrors)
+			return "", notDeletedErr
+		},
+	)
+	if err != nil {
+		options.Logger.Logf(t, "Timedout waiting for Namespace to be deleted: %s", err)
+		if _, isTimeout := err.(retry.MaxRetriesExceeded); isTimeout && notDeletedErr != nil {
+			return notDeletedErr
+		}
+		return err
+	}
+	options.Logger.Logf(t, message)
+	return nil
+}
+
+// getNamespaceDeletionBlockers returns the messages of the conditions of the namespace reporting why its deletion is
+// not complete, such as the resources or finalizers remaining in it.
+func getNamespaceDeletionBlockers(namespace *corev1.Namespace) []string {
+	blockers := []string{}
+	for _, condition := range namespace.Status.Conditions {
+		if condition.Status == corev1.ConditionTrue {
+			blockers = append(blockers, fmt.Sprintf("%s: %s", condition.Type, condition.Message))
+		}
+	}
+	return blockers
+}
+
+// clearStuckNamespaceResourcesE looks up the resources with finalizers in the namespace, removes the given finalizers
+// from the ones being deleted, and returns the resources still having finalizers, formatted as kind/name followed by
+// their finalizers. The resource types that can't be listed, e.g. the ones of an aggregated API that is unavailable or
+// forbidden, are skipped, and the errors listing them are returned alongside.
+func clearStuckNamespaceResourcesE(t testing.TestingT, options *KubectlOptions, namespaceName string, clearFinalizers []string) ([]string, []string, error) {
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := GetDynamicClientFromOptionsE(t, options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The API services that are unavailable, which may also block the deletion, are reported in the conditions
+	resourceLists, err := clientset.Discovery().ServerPreferredNamespacedResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, nil, err
+	}
+
+	stuckResources := []string{}
+	listErrors := []string{}
+	for _, resourceList := range resourceLists {
+		groupVersion, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, apiResource := range resourceList.APIResources {
+			if !slices.Contains(apiResource.Verbs, "list") {
+				continue
+			}
+			resourceClient := client.Resource(groupVersion.WithResource(apiResource.Name)).Namespace(namespaceName)
+			resources, err := resourceClient.List(context.Background(), metav1.ListOptions{})
+			if err != nil {
+				listErrors = append(listErrors, fmt.Sprintf("%s: %s", groupVersion.WithResource(apiResource.Name).GroupResource(), err))
+				continue
+			}
+			for _, resource := range resources.Items {
+				finalizers := resource.GetFinalizers()
+				if len(finalizers) == 0 {
+					continue
+				}
+				if resource.GetDeletionTimestamp() != nil {
+					finalizers, err = removeResourceFinalizersE(resourceClient, resource, clearFinalizers)
+					if err != nil {
+						return nil, nil, err
+					}
+				}
+				if len(finalizers) > 0 {
+					stuckResources = append(stuckResources, fmt.Sprintf("%s/%s (finalizers: %s)", resource.GetKind(), resource.GetName(), strings.Join(finalizers, ", ")))
+				}
+			}
+		}
+	}
+	return stuckResources, listErrors, nil
+}
+
+// removeResourceFinalizersE removes the given finalizers from the resource, if it has any of them, and returns the
+// remaining ones.
+func removeResourceFinalizersE(resourceClient dynamic.ResourceInterface, resource unstructured.Unstructured, clearFinalizers []string) ([]string, error) {
+	finalizers := resource.GetFinalizers()
+	remaining := removeFinalizers(finalizers, clearFinalizers)
+	if len(remaining) == len(finalizers) {
+		return finalizers, nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"finalizers": remaining},
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = resourceClient.Patch(context.Background(), resource.GetName(), types.MergePatchType, patch, metav1.PatchOptions{})
+	return remaining, err
+}
+
+// removeFinalizers returns the finalizers that are not in the finalizers to clear.
+func removeFinalizers(finalizers []string, clearFinalizers []string) []string {
+	remaining := []string{}
+	for _, finalizer := range finalizers {
+		if !slices.Contains(clearFinalizers, finalizer) {
+			remaining = append(remaining, finalizer)
+		}
+	}
+	return remaining
+}
@@ -10,9 +10,12 @@
 package k8s
 
 import (
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -85,3 +88,61 @@ func TestListNamespaces(t *testing.T) {
 		require.True(t, found, "Should find the created namespace in the list")
 	})
 }
+
+func TestDeleteNamespaceAndWaitClearsFinalizers(t *testing.T) {
+	t.Parallel()
+
+	namespaceName := strings.ToLower(random.UniqueId())
+	options := NewKubectlOptions("", "", namespaceName)
+	CreateNamespace(t, options, namespaceName)
+	KubectlApplyFromString(t, options, fmt.Sprintf(exampleConfigMapWithFinalizerYAMLTemplate, namespaceName))
+
+	err := DeleteNamespaceAndWaitE(t, options, namespaceName, nil, 10*time.Second)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ConfigMap/stuck (finalizers: terratest.gruntwork.io/stuck)")
+
+	DeleteNamespaceAndWait(t, options, namespaceName, []string{"terratest.gruntwork.io/stuck"}, 1*time.Minute)
+	_, err = GetNamespaceE(t, options, namespaceName)
+	require.Error(t, err)
+}
+
+func TestNamespaceDeletionBlockers(t *testing.T) {
+	t.Parallel()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "apps"},
+		Status: corev1.NamespaceStatus{
+			Phase: corev1.NamespaceTerminating,
+			Conditions: []corev1.NamespaceCondition{
+				{Type: corev1.NamespaceDeletionDiscoveryFailure, Status: corev1.ConditionFalse},
+				{Type: corev1.NamespaceFinalizersRemaining, Status: corev1.ConditionTrue, Message: "Some content in the namespace has finalizers remaining: example.com/cleanup in 1 resource instances"},
+			},
+		},
+	}
+	assert.Equal(t, []string{"NamespaceFinalizersRemaining: Some content in the namespace has finalizers remaining: example.com/cleanup in 1 resource instances"}, getNamespaceDeletionBlockers(namespace))
+	assert.Equal(
+		t,
+		"Namespace apps is not deleted, phase: Terminating, conditions: NamespaceFinalizersRemaining: Some content in the namespace has finalizers remaining: example.com/cleanup in 1 resource instances, resources with finalizers: Widget/w (finalizers: example.com/cleanup)",
+		NewNamespaceNotDeletedError(namespace, []string{"Widget/w (finalizers: example.com/cleanup)"}, []string{}).Error(),
+	)
+	assert.Equal(
+		t,
+		"Namespace apps is not deleted, phase: Terminating, conditions: NamespaceFinalizersRemaining: Some content in the namespace has finalizers remaining: example.com/cleanup in 1 resource instances, resources that could not be listed: pods.metrics.k8s.io: the server is currently unable to handle the request",
+		NewNamespaceNotDeletedError(namespace, []string{}, []string{"pods.metrics.k8s.io: the server is currently unable to handle the request"}).Error(),
+	)
+
+	assert.Equal(t, []string{"example.com/keep"}, removeFinalizers([]string{"example.com/cleanup", "example.com/keep"}, []string{"example.com/cleanup"}))
+	assert.Equal(t, []string{}, removeFinalizers([]string{"example.com/cleanup"}, []string{"example.com/cleanup"}))
+}
+
+const exampleConfigMapWithFinalizerYAMLTemplate = `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: stuck
+  namespace: %s
+  finalizers:
+  - terratest.gruntwork.io/stuck
+data:
+  key: value
+`
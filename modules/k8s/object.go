@@ -0,0 +1,65 @@
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// ApplyObject applies the given Kubernetes object, either a typed client-go object (e.g. *appsv1.Deployment) or an
+// *unstructured.Unstructured, to the cluster targeted by the provided kubectl options. This will fail the test if
+// there is an error.
+func ApplyObject(t testing.TestingT, options *KubectlOptions, object runtime.Object) {
+	require.NoError(t, ApplyObjectE(t, options, object))
+}
+
+// ApplyObjectE applies the given Kubernetes object, either a typed client-go object (e.g. *appsv1.Deployment) or an
+// *unstructured.Unstructured, to the cluster targeted by the provided kubectl options. The kind and API version of
+// typed objects can be left empty, as they are looked up from their type.
+func ApplyObjectE(t testing.TestingT, options *KubectlOptions, object runtime.Object) error {
+	configData, err := formatObjectConfig(object)
+	if err != nil {
+		return err
+	}
+	return KubectlApplyFromStringE(t, options, configData)
+}
+
+// DeleteObject deletes the given Kubernetes object, either a typed client-go object (e.g. *appsv1.Deployment) or an
+// *unstructured.Unstructured, from the cluster targeted by the provided kubectl options. This will fail the test if
+// there is an error.
+func DeleteObject(t testing.TestingT, options *KubectlOptions, object runtime.Object) {
+	require.NoError(t, DeleteObjectE(t, options, object))
+}
+
+// DeleteObjectE deletes the given Kubernetes object, either a typed client-go object (e.g. *appsv1.Deployment) or an
+// *unstructured.Unstructured, from the cluster targeted by the provided kubectl options. The kind and API version of
+// typed objects can be left empty, as they are looked up from their type.
+func DeleteObjectE(t testing.TestingT, options *KubectlOptions, object runtime.Object) error {
+	configData, err := formatObjectConfig(object)
+	if err != nil {
+		return err
+	}
+	return KubectlDeleteFromStringE(t, options, configData)
+}
+
+// formatObjectConfig returns the object as a JSON config kubectl can apply, setting the kind and API version of typed
+// objects registered in the client-go scheme if they are empty.
+func formatObjectConfig(object runtime.Object) (string, error) {
+	if object.GetObjectKind().GroupVersionKind().Kind == "" {
+		kinds, _, err := scheme.Scheme.ObjectKinds(object)
+		if err != nil {
+			return "", fmt.Errorf("could not find the kind of the object, set its apiVersion and kind: %w", err)
+		}
+		// Don't modify the object of the caller
+		object = object.DeepCopyObject()
+		object.GetObjectKind().SetGroupVersionKind(kinds[0])
+	}
+
+	configData, err := json.Marshal(object)
+	return string(configData), err
+}
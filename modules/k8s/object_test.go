@@ -0,0 +1,70 @@
+//go:build kubeall || kubernetes
+// +build kubeall kubernetes
+
+// NOTE: we have build tags to differentiate kubernetes tests from non-kubernetes tests. This is done because minikube
+// is heavy and can interfere with docker related tests in terratest. Specifically, many of the tests start to fail with
+// `connection refused` errors from `minikube`. To avoid overloading the system, we run the kubernetes tests and helm
+// tests separately from the others. This may not be necessary if you have a sufficiently powerful machine.  We
+// recommend at least 4 cores and 16GB of RAM if you want to run all the tests together.
+
+package k8s
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/gruntwork-io/terratest/modules/random"
+)
+
+func TestApplyAndDeleteObject(t *testing.T) {
+	t.Parallel()
+
+	namespaceName := strings.ToLower(random.UniqueId())
+	options := NewKubectlOptions("", "", namespaceName)
+	CreateNamespace(t, options, namespaceName)
+	defer DeleteNamespace(t, options, namespaceName)
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: namespaceName},
+		Data:       map[string]string{"level": "info"},
+	}
+	ApplyObject(t, options, configMap)
+	output, err := RunKubectlAndGetOutputE(t, options, "get", "configmap", "settings", "-o", "jsonpath={.data.level}")
+	require.NoError(t, err)
+	assert.Equal(t, "info", output)
+
+	DeleteObject(t, options, configMap)
+	_, err = RunKubectlAndGetOutputE(t, options, "get", "configmap", "settings")
+	require.Error(t, err)
+}
+
+func TestFormatObjectConfig(t *testing.T) {
+	t.Parallel()
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "settings"},
+		Data:       map[string]string{"level": "info"},
+	}
+	configData, err := formatObjectConfig(configMap)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "settings", "creationTimestamp": null}, "data": {"level": "info"}}`, configData)
+	assert.Empty(t, configMap.Kind)
+
+	widget := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]interface{}{"name": "w"},
+	}}
+	configData, err = formatObjectConfig(widget)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"apiVersion": "example.com/v1", "kind": "Widget", "metadata": {"name": "w"}}`, configData)
+
+	_, err = formatObjectConfig(&unstructured.Unstructured{Object: map[string]interface{}{}})
+	require.Error(t, err)
+}
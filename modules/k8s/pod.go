@@ -1,13 +1,22 @@
 package k8s
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"regexp"
+	"sync"
 	"time"
 
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/util/exec"
 
 	"github.com/gruntwork-io/terratest/modules/retry"
 	"github.com/gruntwork-io/terratest/modules/testing"
@@ -172,3 +181,161 @@ func GetPodLogs(t testing.TestingT, options *KubectlOptions, pod *corev1.Pod, co
 	require.NoError(t, err)
 	return logs
 }
+
+// podLogPollInterval is the time between the checks for new pods, and between the attempts to follow the logs of a
+// container, when waiting for a log message.
+const podLogPollInterval = 2 * time.Second
+
+// WaitForLogMessage follows the logs of the given container of the Pods matching the label selector (e.g. app=nginx)
+// until a line matches the given regular expression, or the timeout expires, and returns the line. Set container name
+// to "" if there is only one container in the Pods. This will fail the test if there is an error.
+func WaitForLogMessage(t testing.TestingT, options *KubectlOptions, podSelector string, containerName string, messageRegex string, timeout time.Duration) string {
+	line, err := WaitForLogMessageE(t, options, podSelector, containerName, messageRegex, timeout)
+	require.NoError(t, err)
+	return line
+}
+
+// WaitForLogMessageE follows the logs of the given container of the Pods matching the label selector (e.g. app=nginx)
+// until a line matches the given regular expression, or the timeout expires, and returns the line. Set container name
+// to "" if there is only one container in the Pods. Pods created while waiting are followed too, and the logs of a
+// container are followed again when it restarts.
+func WaitForLogMessageE(t testing.TestingT, options *KubectlOptions, podSelector string, containerName string, messageRegex string, timeout time.Duration) (string, error) {
+	message, err := regexp.Compile(messageRegex)
+	if err != nil {
+		return "", err
+	}
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return "", err
+	}
+	options.Logger.Logf(t, "Waiting for log message %s in pods %s", messageRegex, podSelector)
+
+	// Stop following the logs before waiting for the goroutines to return
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	matches := make(chan string, 1)
+	followed := map[string]bool{}
+	for {
+		pods, err := ListPodsE(t, options, metav1.ListOptions{LabelSelector: podSelector})
+		if err != nil {
+			return "", err
+		}
+		for _, pod := range pods {
+			if followed[pod.Name] {
+				continue
+			}
+			followed[pod.Name] = true
+			wg.Add(1)
+			go func(podName string) {
+				defer wg.Done()
+				followPodLogs(ctx, clientset.CoreV1().Pods(options.Namespace).GetLogs(podName, &corev1.PodLogOptions{Container: containerName, Follow: true}).Stream, message, matches)
+			}(pod.Name)
+		}
+
+		select {
+		case line := <-matches:
+			options.Logger.Logf(t, "Found log message: %s", line)
+			return line, nil
+		case <-ctx.Done():
+			return "", NewPodLogMessageNotFoundError(podSelector, messageRegex, timeout)
+		case <-time.After(podLogPollInterval):
+		}
+	}
+}
+
+// followPodLogs reads the log streams opened with openStream, opening a new one when a stream ends, e.g. because the
+// container restarted, until the context is done or a line matches the regular expression, which is sent to matches.
+func followPodLogs(ctx context.Context, openStream func(context.Context) (io.ReadCloser, error), message *regexp.Regexp, matches chan<- string) {
+	for {
+		// Streams can't be opened before the container starts, so retry until then
+		stream, err := openStream(ctx)
+		if err == nil {
+			line, found := findLogMessage(stream, message)
+			stream.Close()
+			if found {
+				select {
+				case matches <- line:
+				default:
+				}
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(podLogPollInterval):
+		}
+	}
+}
+
+// findLogMessage returns the first line of the logs matching the regular expression, and whether there is one.
+func findLogMessage(logs io.Reader, message *regexp.Regexp) (string, bool) {
+	scanner := bufio.NewScanner(logs)
+	for scanner.Scan() {
+		if message.MatchString(scanner.Text()) {
+			return scanner.Text(), true
+		}
+	}
+	return "", false
+}
+
+// ExecInPod runs the given command in a container of the Pod, feeding it the content of stdin if not nil, and returns
+// its stdout, stderr and exit code. Pass container name if there are more containers in the Pod or set to "" if there
+// is only one. This will fail the test if the command could not be run, but not if it exits with a non-zero code.
+func ExecInPod(t testing.TestingT, options *KubectlOptions, podName string, containerName string, command []string, stdin io.Reader) (string, string, int) {
+	stdout, stderr, exitCode, err := ExecInPodE(t, options, podName, containerName, command, stdin)
+	require.NoError(t, err)
+	return stdout, stderr, exitCode
+}
+
+// ExecInPodE runs the given command in a container of the Pod, feeding it the content of stdin if not nil, and returns
+// its stdout, stderr and exit code. Pass container name if there are more containers in the Pod or set to "" if there
+// is only one. The command is run through the Kubernetes API instead of kubectl, so the streams are captured
+// separately. An error is returned if the command could not be run, but not if it exits with a non-zero code.
+func ExecInPodE(t testing.TestingT, options *KubectlOptions, podName string, containerName string, command []string, stdin io.Reader) (string, string, int, error) {
+	options.Logger.Logf(t, "Running command %v in pod %s", command, podName)
+
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return "", "", 0, err
+	}
+	config, err := getRestConfigFromOptionsE(t, options)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	request := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(options.Namespace).
+		Name(podName).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: containerName,
+			Command:   command,
+			Stdin:     stdin != nil,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(config, "POST", request.URL())
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	err = executor.StreamWithContext(context.Background(), remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+
+	var exitErr exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.Exited() {
+		return stdout.String(), stderr.String(), exitErr.ExitStatus(), nil
+	}
+	return stdout.String(), stderr.String(), 0, err
+}
@@ -11,6 +11,7 @@ package k8s
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 	"testing"
 	"time"
@@ -121,6 +122,74 @@ func TestWaitUntilPodAvailableWithFailingReadinessProbe(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestExecInPod(t *testing.T) {
+	t.Parallel()
+
+	uniqueID := strings.ToLower(random.UniqueId())
+	options := NewKubectlOptions("", "", uniqueID)
+	configData := fmt.Sprintf(EXAMPLE_POD_YAML_TEMPLATE, uniqueID, uniqueID)
+	defer KubectlDeleteFromString(t, options, configData)
+	KubectlApplyFromString(t, options, configData)
+	WaitUntilPodAvailable(t, options, "nginx-pod", 60, 1*time.Second)
+
+	stdout, stderr, exitCode := ExecInPod(t, options, "nginx-pod", "", []string{"sh", "-c", "cat; echo error >&2"}, strings.NewReader("hello"))
+	require.Equal(t, "hello", stdout)
+	require.Equal(t, "error\n", stderr)
+	require.Equal(t, 0, exitCode)
+
+	_, _, exitCode = ExecInPod(t, options, "nginx-pod", "nginx", []string{"sh", "-c", "exit 3"}, nil)
+	require.Equal(t, 3, exitCode)
+}
+
+func TestWaitForLogMessage(t *testing.T) {
+	t.Parallel()
+
+	uniqueID := strings.ToLower(random.UniqueId())
+	options := NewKubectlOptions("", "", uniqueID)
+	configData := fmt.Sprintf(examplePodWithLogsYAMLTemplate, uniqueID, uniqueID)
+	defer KubectlDeleteFromString(t, options, configData)
+	KubectlApplyFromString(t, options, configData)
+
+	line := WaitForLogMessage(t, options, "app=logger", "", "^Server started on port [0-9]+$", 2*time.Minute)
+	require.Equal(t, "Server started on port 8080", line)
+
+	_, err := WaitForLogMessageE(t, options, "app=logger", "", "panic", 5*time.Second)
+	require.Error(t, err)
+}
+
+func TestFindLogMessage(t *testing.T) {
+	t.Parallel()
+
+	logs := "Starting\nListening on 0.0.0.0:8080\nReady to accept connections\n"
+
+	line, found := findLogMessage(strings.NewReader(logs), regexp.MustCompile("^Listening on .*:[0-9]+$"))
+	require.True(t, found)
+	require.Equal(t, "Listening on 0.0.0.0:8080", line)
+
+	_, found = findLogMessage(strings.NewReader(logs), regexp.MustCompile("error"))
+	require.False(t, found)
+}
+
+const examplePodWithLogsYAMLTemplate = `---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: %s
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: logger-pod
+  namespace: %s
+  labels:
+    app: logger
+spec:
+  containers:
+  - name: alpine
+    image: alpine:3.8
+    command: ['sh', '-c', 'sleep 5 && echo Server started on port 8080 && sleep 99999']
+`
+
 const EXAMPLE_POD_YAML_TEMPLATE = `---
 apiVersion: v1
 kind: Namespace
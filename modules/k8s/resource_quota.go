@@ -0,0 +1,136 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// ListResourceQuotas will look for ResourceQuotas in the given namespace that match the given filters and return them.
+// This will fail the test if there is an error.
+func ListResourceQuotas(t testing.TestingT, options *KubectlOptions, filters metav1.ListOptions) []corev1.ResourceQuota {
+	quotas, err := ListResourceQuotasE(t, options, filters)
+	require.NoError(t, err)
+	return quotas
+}
+
+// ListResourceQuotasE will look for ResourceQuotas in the given namespace that match the given filters and return
+// them.
+func ListResourceQuotasE(t testing.TestingT, options *KubectlOptions, filters metav1.ListOptions) ([]corev1.ResourceQuota, error) {
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := clientset.CoreV1().ResourceQuotas(options.Namespace).List(context.Background(), filters)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+// GetResourceQuota returns a Kubernetes ResourceQuota resource in the provided namespace with the given name, with
+// its usage in its status. This will fail the test if there is an error.
+func GetResourceQuota(t testing.TestingT, options *KubectlOptions, quotaName string) *corev1.ResourceQuota {
+	quota, err := GetResourceQuotaE(t, options, quotaName)
+	require.NoError(t, err)
+	return quota
+}
+
+// GetResourceQuotaE returns a Kubernetes ResourceQuota resource in the provided namespace with the given name, with
+// its usage in its status.
+func GetResourceQuotaE(t testing.TestingT, options *KubectlOptions, quotaName string) (*corev1.ResourceQuota, error) {
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return nil, err
+	}
+	return clientset.CoreV1().ResourceQuotas(options.Namespace).Get(context.Background(), quotaName, metav1.GetOptions{})
+}
+
+// ListLimitRanges will look for LimitRanges in the given namespace that match the given filters and return them. This
+// will fail the test if there is an error.
+func ListLimitRanges(t testing.TestingT, options *KubectlOptions, filters metav1.ListOptions) []corev1.LimitRange {
+	limitRanges, err := ListLimitRangesE(t, options, filters)
+	require.NoError(t, err)
+	return limitRanges
+}
+
+// ListLimitRangesE will look for LimitRanges in the given namespace that match the given filters and return them.
+func ListLimitRangesE(t testing.TestingT, options *KubectlOptions, filters metav1.ListOptions) ([]corev1.LimitRange, error) {
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := clientset.CoreV1().LimitRanges(options.Namespace).List(context.Background(), filters)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+// GetLimitRange returns a Kubernetes LimitRange resource in the provided namespace with the given name. This will
+// fail the test if there is an error.
+func GetLimitRange(t testing.TestingT, options *KubectlOptions, limitRangeName string) *corev1.LimitRange {
+	limitRange, err := GetLimitRangeE(t, options, limitRangeName)
+	require.NoError(t, err)
+	return limitRange
+}
+
+// GetLimitRangeE returns a Kubernetes LimitRange resource in the provided namespace with the given name.
+func GetLimitRangeE(t testing.TestingT, options *KubectlOptions, limitRangeName string) (*corev1.LimitRange, error) {
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return nil, err
+	}
+	return clientset.CoreV1().LimitRanges(options.Namespace).Get(context.Background(), limitRangeName, metav1.GetOptions{})
+}
+
+// AssertPodRejected checks that creating the given Pod in the provided namespace is rejected with an error matching
+// the given regular expression (e.g. "exceeded quota" or "maximum memory usage per Container"). This will fail the test
+// if there is an error, or if the Pod is not rejected.
+func AssertPodRejected(t testing.TestingT, options *KubectlOptions, pod *corev1.Pod, expectedReasonRegex string) {
+	require.NoError(t, AssertPodRejectedE(t, options, pod, expectedReasonRegex))
+}
+
+// AssertPodRejectedE checks that creating the given Pod in the provided namespace is rejected with an error matching
+// the given regular expression (e.g. "exceeded quota" or "maximum memory usage per Container"), as the ResourceQuotas
+// and LimitRanges of the namespace do. The Pod is deleted if it is unexpectedly created.
+func AssertPodRejectedE(t testing.TestingT, options *KubectlOptions, pod *corev1.Pod, expectedReasonRegex string) error {
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return err
+	}
+
+	created, err := clientset.CoreV1().Pods(options.Namespace).Create(context.Background(), pod, metav1.CreateOptions{})
+	if err == nil {
+		if deleteErr := clientset.CoreV1().Pods(options.Namespace).Delete(context.Background(), created.Name, metav1.DeleteOptions{}); deleteErr != nil {
+			options.Logger.Logf(t, "Failed to delete pod %s: %s", created.Name, deleteErr)
+		}
+	}
+	if err := checkRejection(err, expectedReasonRegex); err != nil {
+		return fmt.Errorf("pod %s: %w", pod.Name, err)
+	}
+	options.Logger.Logf(t, "Pod %s was rejected: %s", pod.Name, err)
+	return nil
+}
+
+// checkRejection checks that the given error, returned by a request to the API server, is a rejection with a message
+// matching the given regular expression.
+func checkRejection(err error, expectedReasonRegex string) error {
+	reason, regexErr := regexp.Compile(expectedReasonRegex)
+	if regexErr != nil {
+		return regexErr
+	}
+	if err == nil {
+		return fmt.Errorf("was accepted, expected it to be rejected with %q", expectedReasonRegex)
+	}
+	if !reason.MatchString(err.Error()) {
+		return fmt.Errorf("was rejected with %q, expected %q", err.Error(), expectedReasonRegex)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+//go:build kubeall || kubernetes
+// +build kubeall kubernetes
+
+// NOTE: we have build tags to differentiate kubernetes tests from non-kubernetes tests. This is done because minikube
+// is heavy and can interfere with docker related tests in terratest. Specifically, many of the tests start to fail with
+// `connection refused` errors from `minikube`. To avoid overloading the system, we run the kubernetes tests and helm
+// tests separately from the others. This may not be necessary if you have a sufficiently powerful machine.  We
+// recommend at least 4 cores and 16GB of RAM if you want to run all the tests together.
+
+package k8s
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/gruntwork-io/terratest/modules/random"
+)
+
+func TestAssertPodRejectedByQuotaAndLimitRange(t *testing.T) {
+	t.Parallel()
+
+	namespaceName := strings.ToLower(random.UniqueId())
+	options := NewKubectlOptions("", "", namespaceName)
+	configData := fmt.Sprintf(exampleQuotaAndLimitRangeYAMLTemplate, namespaceName, namespaceName, namespaceName)
+	defer KubectlDeleteFromString(t, options, configData)
+	KubectlApplyFromString(t, options, configData)
+
+	quota := GetResourceQuota(t, options, "compute")
+	assert.Equal(t, "1Gi", quota.Spec.Hard.Name(corev1.ResourceLimitsMemory, resource.BinarySI).String())
+	limitRange := GetLimitRange(t, options, "containers")
+	require.Len(t, limitRange.Spec.Limits, 1)
+	assert.Len(t, ListResourceQuotas(t, options, metav1.ListOptions{}), 1)
+	assert.Len(t, ListLimitRanges(t, options, metav1.ListOptions{}), 1)
+
+	AssertPodRejected(t, options, newExamplePodRequestingMemory("too-big-for-container", "768Mi"), "maximum memory usage per Container")
+
+	err := AssertPodRejectedE(t, options, newExamplePodRequestingMemory("fits", "128Mi"), "exceeded quota")
+	require.Error(t, err)
+}
+
+func TestCheckRejection(t *testing.T) {
+	t.Parallel()
+
+	rejection := errors.New(`pods "web" is forbidden: exceeded quota: compute, requested: limits.memory=2Gi, used: limits.memory=0, limited: limits.memory=1Gi`)
+	require.NoError(t, checkRejection(rejection, "exceeded quota: compute"))
+	require.Error(t, checkRejection(rejection, "maximum memory usage"))
+	require.Error(t, checkRejection(nil, "exceeded quota"))
+	require.Error(t, checkRejection(rejection, "("))
+}
+
+func newExamplePodRequestingMemory(name string, memory string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "nginx",
+				Image: "nginx:1.15.7",
+				Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(memory), corev1.ResourceCPU: resource.MustParse("100m")},
+				},
+			}},
+		},
+	}
+}
+
+const exampleQuotaAndLimitRangeYAMLTemplate = `---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: %s
+---
+apiVersion: v1
+kind: ResourceQuota
+metadata:
+  name: compute
+  namespace: %s
+spec:
+  hard:
+    limits.memory: 1Gi
+---
+apiVersion: v1
+kind: LimitRange
+metadata:
+  name: containers
+  namespace: %s
+spec:
+  limits:
+  - type: Container
+    max:
+      memory: 512Mi
+`
@@ -0,0 +1,119 @@
+package k8s
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// RolloutRevision is a revision of a deployment, statefulset or daemonset, as listed by 'kubectl rollout history'.
+type RolloutRevision struct {
+	Revision int64
+	// The kubernetes.io/change-cause annotation of the revision, empty if not set.
+	ChangeCause string
+}
+
+// RolloutStatus waits until the rollout of the deployment, statefulset or daemonset is complete, or the timeout
+// expires, and returns the output of 'kubectl rollout status'. This will fail the test if there is an error.
+func RolloutStatus(t testing.TestingT, options *KubectlOptions, resourceType KubeResourceType, name string, timeout time.Duration) string {
+	out, err := RolloutStatusE(t, options, resourceType, name, timeout)
+	require.NoError(t, err)
+	return out
+}
+
+// RolloutStatusE waits until the rollout of the deployment, statefulset or daemonset is complete, or the timeout
+// expires, and returns the output of 'kubectl rollout status'.
+func RolloutStatusE(t testing.TestingT, options *KubectlOptions, resourceType KubeResourceType, name string, timeout time.Duration) (string, error) {
+	resource, err := getRolloutResourceE(resourceType, name)
+	if err != nil {
+		return "", err
+	}
+	return RunKubectlAndGetOutputE(t, options, "rollout", "status", resource, "--timeout", timeout.String())
+}
+
+// RolloutHistory returns the revisions of the deployment, statefulset or daemonset, oldest first. This will fail the
+// test if there is an error.
+func RolloutHistory(t testing.TestingT, options *KubectlOptions, resourceType KubeResourceType, name string) []RolloutRevision {
+	revisions, err := RolloutHistoryE(t, options, resourceType, name)
+	require.NoError(t, err)
+	return revisions
+}
+
+// RolloutHistoryE returns the revisions of the deployment, statefulset or daemonset, oldest first.
+func RolloutHistoryE(t testing.TestingT, options *KubectlOptions, resourceType KubeResourceType, name string) ([]RolloutRevision, error) {
+	resource, err := getRolloutResourceE(resourceType, name)
+	if err != nil {
+		return nil, err
+	}
+	out, err := RunKubectlAndGetOutputE(t, options, "rollout", "history", resource)
+	if err != nil {
+		return nil, err
+	}
+	return parseRolloutHistory(out)
+}
+
+// RolloutUndo rolls the deployment, statefulset or daemonset back to the given revision, or to the previous one if
+// toRevision is 0. This will fail the test if there is an error.
+func RolloutUndo(t testing.TestingT, options *KubectlOptions, resourceType KubeResourceType, name string, toRevision int64) {
+	require.NoError(t, RolloutUndoE(t, options, resourceType, name, toRevision))
+}
+
+// RolloutUndoE rolls the deployment, statefulset or daemonset back to the given revision, or to the previous one if
+// toRevision is 0. Use RolloutStatusE to wait for the rollback to complete.
+func RolloutUndoE(t testing.TestingT, options *KubectlOptions, resourceType KubeResourceType, name string, toRevision int64) error {
+	resource, err := getRolloutResourceE(resourceType, name)
+	if err != nil {
+		return err
+	}
+	args := []string{"rollout", "undo", resource}
+	if toRevision != 0 {
+		args = append(args, "--to-revision", strconv.FormatInt(toRevision, 10))
+	}
+	return RunKubectlE(t, options, args...)
+}
+
+// getRolloutResourceE returns the kubectl identifier of the resource, that must support rollouts.
+func getRolloutResourceE(resourceType KubeResourceType, name string) (string, error) {
+	switch resourceType {
+	case ResourceTypeDeployment, ResourceTypeStatefulSet, ResourceTypeDaemonSet:
+		return fmt.Sprintf("%s/%s", resourceType.String(), name), nil
+	default:
+		return "", UnknownKubeResourceType{resourceType}
+	}
+}
+
+// parseRolloutHistory parses the table of revisions printed by 'kubectl rollout history', e.g.
+// REVISION  CHANGE-CAUSE
+// 1         <none>
+func parseRolloutHistory(out string) ([]RolloutRevision, error) {
+	revisions := []RolloutRevision{}
+	inTable := false
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "REVISION" {
+			inTable = true
+			continue
+		}
+		if !inTable {
+			continue
+		}
+		revision, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected line in rollout history: %s", line)
+		}
+		changeCause := strings.Join(fields[1:], " ")
+		if changeCause == "<none>" {
+			changeCause = ""
+		}
+		revisions = append(revisions, RolloutRevision{Revision: revision, ChangeCause: changeCause})
+	}
+	return revisions, nil
+}
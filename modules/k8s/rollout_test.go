@@ -0,0 +1,67 @@
+//go:build kubeall || kubernetes
+// +build kubeall kubernetes
+
+// NOTE: we have build tags to differentiate kubernetes tests from non-kubernetes tests. This is done because minikube
+// is heavy and can interfere with docker related tests in terratest. Specifically, many of the tests start to fail with
+// `connection refused` errors from `minikube`. To avoid overloading the system, we run the kubernetes tests and helm
+// tests separately from the others. This may not be necessary if you have a sufficiently powerful machine.  We
+// recommend at least 4 cores and 16GB of RAM if you want to run all the tests together.
+
+package k8s
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRolloutUpgradeAndUndo(t *testing.T) {
+	t.Parallel()
+
+	uniqueID := strings.ToLower(random.UniqueId())
+	options := NewKubectlOptions("", "", uniqueID)
+	configData := fmt.Sprintf(ExampleDeploymentYAMLTemplate, uniqueID)
+	KubectlApplyFromString(t, options, configData)
+	defer KubectlDeleteFromString(t, options, configData)
+	RolloutStatus(t, options, ResourceTypeDeployment, "nginx-deployment", 2*time.Minute)
+
+	KubectlApplyFromString(t, options, strings.Replace(configData, "nginx:1.15.7", "nginx:1.15.8", 1))
+	RolloutStatus(t, options, ResourceTypeDeployment, "nginx-deployment", 2*time.Minute)
+	require.Equal(t, 2, len(RolloutHistory(t, options, ResourceTypeDeployment, "nginx-deployment")))
+
+	RolloutUndo(t, options, ResourceTypeDeployment, "nginx-deployment", 1)
+	RolloutStatus(t, options, ResourceTypeDeployment, "nginx-deployment", 2*time.Minute)
+	deployment := GetDeployment(t, options, "nginx-deployment")
+	require.Equal(t, "nginx:1.15.7", deployment.Spec.Template.Spec.Containers[0].Image)
+}
+
+func TestRolloutRejectsUnsupportedResourceType(t *testing.T) {
+	t.Parallel()
+
+	options := NewKubectlOptions("", "", "default")
+	_, err := RolloutHistoryE(t, options, ResourceTypeService, "nginx-service")
+	require.Error(t, err)
+}
+
+func TestParseRolloutHistory(t *testing.T) {
+	t.Parallel()
+
+	out := `deployment.apps/nginx-deployment
+REVISION  CHANGE-CAUSE
+2         <none>
+3         kubectl set image deployment/nginx-deployment nginx=nginx:1.16.1
+`
+	revisions, err := parseRolloutHistory(out)
+	require.NoError(t, err)
+	require.Equal(t, []RolloutRevision{
+		{Revision: 2},
+		{Revision: 3, ChangeCause: "kubectl set image deployment/nginx-deployment nginx=nginx:1.16.1"},
+	}, revisions)
+
+	_, err = parseRolloutHistory("REVISION  CHANGE-CAUSE\nfoo bar\n")
+	require.Error(t, err)
+}
@@ -2,6 +2,8 @@ package k8s
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/gruntwork-io/go-commons/errors"
 	"github.com/stretchr/testify/require"
@@ -38,3 +40,79 @@ func CanIDoE(t testing.TestingT, options *KubectlOptions, action authv1.Resource
 	}
 	return resp.Status.Allowed, nil
 }
+
+// CanI returns whether or not the given user, or the client configured by the provided kubectl option if the user is
+// empty, is allowed to perform the verb on the resource in the namespace, like 'kubectl auth can-i --as'. The resource
+// is formatted as in kubectl, e.g. pods, pods/log or deployments.apps. Use ServiceAccountUsername to check the actions
+// of a service account. This will fail if there are any errors accessing the kubernetes API (but not if the action is
+// denied).
+func CanI(t testing.TestingT, options *KubectlOptions, verb string, resource string, namespace string, asUserOrSA string) bool {
+	allowed, err := CanIE(t, options, verb, resource, namespace, asUserOrSA)
+	require.NoError(t, err)
+	return allowed
+}
+
+// CanIE returns whether or not the given user, or the client configured by the provided kubectl option if the user is
+// empty, is allowed to perform the verb on the resource in the namespace, like 'kubectl auth can-i --as'. The resource
+// is formatted as in kubectl, e.g. pods, pods/log or deployments.apps. Use ServiceAccountUsername to check the actions
+// of a service account. This will return an error if there are problems accessing the kubernetes API (but not if the
+// action is simply denied).
+func CanIE(t testing.TestingT, options *KubectlOptions, verb string, resource string, namespace string, asUserOrSA string) (bool, error) {
+	action := parseResourceAttributes(verb, resource, namespace)
+	if asUserOrSA == "" {
+		return CanIDoE(t, options, action)
+	}
+
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return false, err
+	}
+	check := authv1.SubjectAccessReview{
+		Spec: authv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &action,
+			User:               asUserOrSA,
+			Groups:             getUserGroups(asUserOrSA),
+		},
+	}
+	resp, err := clientset.AuthorizationV1().SubjectAccessReviews().Create(context.Background(), &check, metav1.CreateOptions{})
+	if err != nil {
+		return false, errors.WithStackTrace(err)
+	}
+	if !resp.Status.Allowed {
+		options.Logger.Logf(t, "Denied action %s on resource %s for user %s for reason %s", action.Verb, resource, asUserOrSA, resp.Status.Reason)
+	}
+	return resp.Status.Allowed, nil
+}
+
+// ServiceAccountUsername returns the name of the user the given service account authenticates as, to use with CanI.
+func ServiceAccountUsername(namespace string, serviceAccountName string) string {
+	return fmt.Sprintf("%s%s:%s", serviceAccountUserPrefix, namespace, serviceAccountName)
+}
+
+// serviceAccountUserPrefix is the prefix of the names of the users service accounts authenticate as.
+const serviceAccountUserPrefix = "system:serviceaccount:"
+
+// getUserGroups returns the groups the user is a member of when authenticated: the groups of service accounts, which
+// RBAC bindings often refer to, are implied by their user name.
+func getUserGroups(user string) []string {
+	groups := []string{"system:authenticated"}
+	if strings.HasPrefix(user, serviceAccountUserPrefix) {
+		namespace := strings.SplitN(strings.TrimPrefix(user, serviceAccountUserPrefix), ":", 2)[0]
+		groups = append(groups, "system:serviceaccounts", "system:serviceaccounts:"+namespace)
+	}
+	return groups
+}
+
+// parseResourceAttributes returns the attributes of the action on the resource formatted as in kubectl, e.g. pods,
+// pods/log or deployments.apps.
+func parseResourceAttributes(verb string, resource string, namespace string) authv1.ResourceAttributes {
+	resource, subresource, _ := strings.Cut(resource, "/")
+	resource, group, _ := strings.Cut(resource, ".")
+	return authv1.ResourceAttributes{
+		Namespace:   namespace,
+		Verb:        verb,
+		Group:       group,
+		Resource:    resource,
+		Subresource: subresource,
+	}
+}
@@ -30,3 +30,36 @@ func TestCanIDoReturnsTrueForAllowedAction(t *testing.T) {
 	options := NewKubectlOptions("", "", "kube-system")
 	assert.True(t, CanIDo(t, options, action))
 }
+
+func TestCanIForServiceAccount(t *testing.T) {
+	t.Parallel()
+
+	options := NewKubectlOptions("", "", "kube-system")
+	assert.True(t, CanI(t, options, "list", "pods", "kube-system", ""))
+
+	// The default service account has no permission on the cluster
+	user := ServiceAccountUsername("default", "default")
+	assert.False(t, CanI(t, options, "delete", "deployments.apps", "kube-system", user))
+	assert.False(t, CanI(t, options, "get", "pods/log", "kube-system", user))
+}
+
+func TestParseResourceAttributes(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(
+		t,
+		authv1.ResourceAttributes{Namespace: "default", Verb: "get", Resource: "pods", Subresource: "log"},
+		parseResourceAttributes("get", "pods/log", "default"),
+	)
+	assert.Equal(
+		t,
+		authv1.ResourceAttributes{Namespace: "default", Verb: "patch", Group: "apps", Resource: "deployments", Subresource: "scale"},
+		parseResourceAttributes("patch", "deployments.apps/scale", "default"),
+	)
+	assert.Equal(
+		t,
+		[]string{"system:authenticated", "system:serviceaccounts", "system:serviceaccounts:ci"},
+		getUserGroups(ServiceAccountUsername("ci", "deployer")),
+	)
+	assert.Equal(t, []string{"system:authenticated"}, getUserGroups("jane@example.com"))
+}
@@ -0,0 +1,121 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// ListStatefulSets will look for statefulsets in the given namespace that match the given filters and return them.
+// This will fail the test if there is an error.
+func ListStatefulSets(t testing.TestingT, options *KubectlOptions, filters metav1.ListOptions) []appsv1.StatefulSet {
+	statefulSets, err := ListStatefulSetsE(t, options, filters)
+	require.NoError(t, err)
+	return statefulSets
+}
+
+// ListStatefulSetsE will look for statefulsets in the given namespace that match the given filters and return them.
+func ListStatefulSetsE(t testing.TestingT, options *KubectlOptions, filters metav1.ListOptions) ([]appsv1.StatefulSet, error) {
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := clientset.AppsV1().StatefulSets(options.Namespace).List(context.Background(), filters)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+// GetStatefulSet returns a Kubernetes statefulset resource in the provided namespace with the given name. This will
+// fail the test if there is an error.
+func GetStatefulSet(t testing.TestingT, options *KubectlOptions, statefulSetName string) *appsv1.StatefulSet {
+	statefulSet, err := GetStatefulSetE(t, options, statefulSetName)
+	require.NoError(t, err)
+	return statefulSet
+}
+
+// GetStatefulSetE returns a Kubernetes statefulset resource in the provided namespace with the given name.
+func GetStatefulSetE(t testing.TestingT, options *KubectlOptions, statefulSetName string) (*appsv1.StatefulSet, error) {
+	clientset, err := GetKubernetesClientFromOptionsE(t, options)
+	if err != nil {
+		return nil, err
+	}
+	return clientset.AppsV1().StatefulSets(options.Namespace).Get(context.Background(), statefulSetName, metav1.GetOptions{})
+}
+
+// WaitUntilStatefulSetAvailable waits until all pods within the statefulset are ready and running the updated
+// revision, retrying the check for the specified amount of times, sleeping for the provided duration between each try.
+// This will fail the test if there is an error.
+func WaitUntilStatefulSetAvailable(t testing.TestingT, options *KubectlOptions, statefulSetName string, retries int, sleepBetweenRetries time.Duration) {
+	require.NoError(t, WaitUntilStatefulSetAvailableE(t, options, statefulSetName, retries, sleepBetweenRetries))
+}
+
+// WaitUntilStatefulSetAvailableE waits until all pods within the statefulset are ready and running the updated
+// revision, retrying the check for the specified amount of times, sleeping for the provided duration between each try.
+func WaitUntilStatefulSetAvailableE(
+	t testing.TestingT,
+	options *KubectlOptions,
+	statefulSetName string,
+	retries int,
+	sleepBetweenRetries time.Duration,
+) error {
+	statusMsg := fmt.Sprintf("Wait for statefulset %s to be provisioned.", statefulSetName)
+	message, err := retry.DoWithRetryE(
+		t,
+		statusMsg,
+		retries,
+		sleepBetweenRetries,
+		func() (string, error) {
+			statefulSet, err := GetStatefulSetE(t, options, statefulSetName)
+			if err != nil {
+				return "", err
+			}
+			if !IsStatefulSetAvailable(statefulSet) {
+				return "", NewStatefulSetNotAvailableError(statefulSet)
+			}
+			return "StatefulSet is now available", nil
+		},
+	)
+	if err != nil {
+		options.Logger.Logf(t, "Timedout waiting for StatefulSet to be provisioned: %s", err)
+		return err
+	}
+	options.Logger.Logf(t, message)
+	return nil
+}
+
+// IsStatefulSetAvailable returns true if the controller observed the latest spec of the statefulset, and all its pods
+// are ready and running the updated revision. With a partitioned rolling update, only the pods above the partition are
+// expected to be updated. With the OnDelete update strategy, the revision of the pods isn't checked.
+func IsStatefulSetAvailable(statefulSet *appsv1.StatefulSet) bool {
+	desired := getStatefulSetDesiredReplicas(statefulSet)
+	status := statefulSet.Status
+	if status.ObservedGeneration < statefulSet.Generation || status.ReadyReplicas != desired {
+		return false
+	}
+
+	strategy := statefulSet.Spec.UpdateStrategy
+	if strategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+		return true
+	}
+	if strategy.RollingUpdate != nil && strategy.RollingUpdate.Partition != nil && *strategy.RollingUpdate.Partition > 0 {
+		return status.UpdatedReplicas >= desired-*strategy.RollingUpdate.Partition
+	}
+	return status.UpdatedReplicas == desired && status.CurrentRevision == status.UpdateRevision
+}
+
+// getStatefulSetDesiredReplicas returns the number of replicas of the statefulset, which defaults to 1.
+func getStatefulSetDesiredReplicas(statefulSet *appsv1.StatefulSet) int32 {
+	if statefulSet.Spec.Replicas == nil {
+		return 1
+	}
+	return *statefulSet.Spec.Replicas
+}
@@ -0,0 +1,160 @@
+//go:build kubeall || kubernetes
+// +build kubeall kubernetes
+
+// NOTE: we have build tags to differentiate kubernetes tests from non-kubernetes tests. This is done because minikube
+// is heavy and can interfere with docker related tests in terratest. Specifically, many of the tests start to fail with
+// `connection refused` errors from `minikube`. To avoid overloading the system, we run the kubernetes tests and helm
+// tests separately from the others. This may not be necessary if you have a sufficiently powerful machine.  We
+// recommend at least 4 cores and 16GB of RAM if you want to run all the tests together.
+
+package k8s
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetStatefulSetEReturnsError(t *testing.T) {
+	t.Parallel()
+
+	options := NewKubectlOptions("", "", "")
+	_, err := GetStatefulSetE(t, options, "sample-sts")
+	require.Error(t, err)
+}
+
+func TestWaitUntilStatefulSetAvailable(t *testing.T) {
+	t.Parallel()
+
+	uniqueID := strings.ToLower(random.UniqueId())
+	options := NewKubectlOptions("", "", uniqueID)
+	configData := fmt.Sprintf(exampleStatefulSetYAMLTemplate, uniqueID, uniqueID)
+	KubectlApplyFromString(t, options, configData)
+	defer KubectlDeleteFromString(t, options, configData)
+
+	WaitUntilStatefulSetAvailable(t, options, "sample-sts", 60, 1*time.Second)
+
+	statefulSets := ListStatefulSets(t, options, metav1.ListOptions{})
+	require.Equal(t, 1, len(statefulSets))
+	require.Equal(t, "sample-sts", statefulSets[0].Name)
+}
+
+func TestIsStatefulSetAvailable(t *testing.T) {
+	t.Parallel()
+
+	replicas := int32(3)
+	partition := int32(2)
+	testCases := []struct {
+		title          string
+		statefulSet    *appsv1.StatefulSet
+		expectedResult bool
+	}{
+		{
+			title: "AllReplicasReadyAndUpdated",
+			statefulSet: &appsv1.StatefulSet{
+				Spec:   appsv1.StatefulSetSpec{Replicas: &replicas},
+				Status: appsv1.StatefulSetStatus{ReadyReplicas: 3, UpdatedReplicas: 3, CurrentRevision: "sts-1", UpdateRevision: "sts-1"},
+			},
+			expectedResult: true,
+		},
+		{
+			title: "RollingUpdateInProgress",
+			statefulSet: &appsv1.StatefulSet{
+				Spec:   appsv1.StatefulSetSpec{Replicas: &replicas},
+				Status: appsv1.StatefulSetStatus{ReadyReplicas: 3, UpdatedReplicas: 1, CurrentRevision: "sts-1", UpdateRevision: "sts-2"},
+			},
+			expectedResult: false,
+		},
+		{
+			title: "ReplicasNotReady",
+			statefulSet: &appsv1.StatefulSet{
+				Spec:   appsv1.StatefulSetSpec{Replicas: &replicas},
+				Status: appsv1.StatefulSetStatus{ReadyReplicas: 2, UpdatedReplicas: 3, CurrentRevision: "sts-1", UpdateRevision: "sts-1"},
+			},
+			expectedResult: false,
+		},
+		{
+			title: "SpecNotObserved",
+			statefulSet: &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+				Status:     appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 3, UpdatedReplicas: 3, CurrentRevision: "sts-1", UpdateRevision: "sts-1"},
+			},
+			expectedResult: false,
+		},
+		{
+			title: "PartitionedReplicasUpdated",
+			statefulSet: &appsv1.StatefulSet{
+				Spec: appsv1.StatefulSetSpec{
+					Replicas: &replicas,
+					UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+						Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+						RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: &partition},
+					},
+				},
+				Status: appsv1.StatefulSetStatus{ReadyReplicas: 3, UpdatedReplicas: 1, CurrentRevision: "sts-1", UpdateRevision: "sts-2"},
+			},
+			expectedResult: true,
+		},
+		{
+			title: "OnDeleteStrategy",
+			statefulSet: &appsv1.StatefulSet{
+				Spec: appsv1.StatefulSetSpec{
+					Replicas:       &replicas,
+					UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType},
+				},
+				Status: appsv1.StatefulSetStatus{ReadyReplicas: 3, CurrentRevision: "sts-1", UpdateRevision: "sts-2"},
+			},
+			expectedResult: true,
+		},
+		{
+			title: "DefaultsToOneReplica",
+			statefulSet: &appsv1.StatefulSet{
+				Status: appsv1.StatefulSetStatus{ReadyReplicas: 1, UpdatedReplicas: 1, CurrentRevision: "sts-1", UpdateRevision: "sts-1"},
+			},
+			expectedResult: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.expectedResult, IsStatefulSetAvailable(tc.statefulSet))
+		})
+	}
+}
+
+const exampleStatefulSetYAMLTemplate = `---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: %s
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: sample-sts
+  namespace: %s
+spec:
+  serviceName: sample-sts
+  replicas: 2
+  selector:
+    matchLabels:
+      app: sample-sts
+  template:
+    metadata:
+      labels:
+        app: sample-sts
+    spec:
+      containers:
+      - name: alpine
+        image: alpine:3.8
+        command: ['sh', '-c', 'echo Hello Terratest! && sleep 99999']
+`
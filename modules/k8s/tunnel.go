@@ -14,11 +14,14 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/portforward"
 	"k8s.io/client-go/transport/spdy"
 
@@ -29,7 +32,15 @@ import (
 // Global lock to synchronize port selections
 var globalMutex sync.Mutex
 
-// KubeResourceType is an enum representing known resource types that can support port forwarding
+const (
+	// tunnelReconnectRetries is the number of attempts to reopen a tunnel to another pod when the connection is lost.
+	tunnelReconnectRetries = 30
+	// tunnelReconnectSleep is the time between the attempts to reopen a tunnel.
+	tunnelReconnectSleep = 2 * time.Second
+)
+
+// KubeResourceType is an enum representing known resource types that can support port forwarding (pods, deployments
+// and services) or rollouts (deployments, statefulsets and daemonsets)
 type KubeResourceType int
 
 const (
@@ -39,6 +50,10 @@ const (
 	ResourceTypeDeployment
 	// ResourceTypeService is a k8s service kind identifier
 	ResourceTypeService
+	// ResourceTypeStatefulSet is a k8s statefulset kind identifier
+	ResourceTypeStatefulSet
+	// ResourceTypeDaemonSet is a k8s daemonset kind identifier
+	ResourceTypeDaemonSet
 )
 
 func (resourceType KubeResourceType) String() string {
@@ -49,6 +64,10 @@ func (resourceType KubeResourceType) String() string {
 		return "pod"
 	case ResourceTypeService:
 		return "svc"
+	case ResourceTypeStatefulSet:
+		return "sts"
+	case ResourceTypeDaemonSet:
+		return "ds"
 	default:
 		// This should not happen
 		return "UNKNOWN_RESOURCE_TYPE"
@@ -74,7 +93,9 @@ type Tunnel struct {
 	resourceName   string
 	logger         logger.TestLogger
 	stopChan       chan struct{}
-	readyChan      chan struct{}
+
+	errMutex sync.Mutex
+	err      error // the error that stopped the tunnel from being reopened
 }
 
 // NewTunnel creates a new tunnel with NewTunnelWithLogger, setting logger.Terratest as the logger.
@@ -85,6 +106,9 @@ func NewTunnel(kubectlOptions *KubectlOptions, resourceType KubeResourceType, re
 // NewTunnelWithLogger will create a new Tunnel struct with the provided logger.
 // Note that if you use 0 for the local port, an open port on the host system
 // will be selected automatically, and the Tunnel struct will be updated with the selected port.
+// Tunnels to services and deployments are opened to an available pod of the resource, and reopened to another one if
+// the connection to the pod is lost (e.g. the pod is deleted during a rolling update) until the tunnel is closed. If
+// the tunnel can't be reopened, Err returns why.
 func NewTunnelWithLogger(
 	kubectlOptions *KubectlOptions,
 	resourceType KubeResourceType,
@@ -102,7 +126,6 @@ func NewTunnelWithLogger(
 		resourceName:   resourceName,
 		logger:         logger,
 		stopChan:       make(chan struct{}, 1),
-		readyChan:      make(chan struct{}, 1),
 	}
 }
 
@@ -111,6 +134,14 @@ func (tunnel *Tunnel) Endpoint() string {
 	return fmt.Sprintf("localhost:%d", tunnel.localPort)
 }
 
+// Err returns the error that stopped the tunnel from being reopened to another pod once the connection was lost, if
+// any. Requests through a tunnel that couldn't be reopened fail with connection refused errors.
+func (tunnel *Tunnel) Err() error {
+	tunnel.errMutex.Lock()
+	defer tunnel.errMutex.Unlock()
+	return tunnel.err
+}
+
 // Close disconnects a tunnel connection by closing the StopChan, thereby stopping the goroutine.
 func (tunnel *Tunnel) Close() {
 	close(tunnel.stopChan)
@@ -131,7 +162,7 @@ func (tunnel *Tunnel) getAttachablePodForResourceE(t testing.TestingT) (string,
 	}
 }
 
-// getAttachablePodForDeploymentE will find an active pod associated with the Deployment and return the pod name.
+// getAttachablePodForDeploymentE will find an active pod, that is not terminating, associated with the Deployment and return the pod name.
 func (tunnel *Tunnel) getAttachablePodForDeploymentE(t testing.TestingT) (string, error) {
 	deploy, err := GetDeploymentE(t, tunnel.kubectlOptions, tunnel.resourceName)
 	if err != nil {
@@ -143,14 +174,14 @@ func (tunnel *Tunnel) getAttachablePodForDeploymentE(t testing.TestingT) (string
 		return "", err
 	}
 	for _, pod := range deploymentPods {
-		if IsPodAvailable(&pod) {
+		if pod.DeletionTimestamp == nil && IsPodAvailable(&pod) {
 			return pod.Name, nil
 		}
 	}
 	return "", DeploymentNotAvailable{deploy}
 }
 
-// getAttachablePodForServiceE will find an active pod associated with the Service and return the pod name.
+// getAttachablePodForServiceE will find an active pod, that is not terminating, associated with the Service and return the pod name.
 func (tunnel *Tunnel) getAttachablePodForServiceE(t testing.TestingT) (string, error) {
 	service, err := GetServiceE(t, tunnel.kubectlOptions, tunnel.resourceName)
 	if err != nil {
@@ -162,7 +193,7 @@ func (tunnel *Tunnel) getAttachablePodForServiceE(t testing.TestingT) (string, e
 		return "", err
 	}
 	for _, pod := range servicePods {
-		if IsPodAvailable(&pod) {
+		if pod.DeletionTimestamp == nil && IsPodAvailable(&pod) {
 			return pod.Name, nil
 		}
 	}
@@ -206,11 +237,44 @@ func (tunnel *Tunnel) ForwardPortE(t testing.TestingT) error {
 		}
 	}
 
+	// If the localport is 0, get an available port before continuing. We do this here instead of relying on the
+	// underlying portforwarder library, because the portforwarder library does not expose the selected local port in a
+	// machine readable manner.
+	// Synchronize on the global lock to avoid race conditions with concurrently selecting the same available port,
+	// since there is a brief moment between `GetAvailablePort` and `portforwader.ForwardPorts` where the selected port
+	// is available for selection again.
+	if tunnel.localPort == 0 {
+		tunnel.logger.Logf(t, "Requested local port is 0. Selecting an open port on host system")
+		tunnel.localPort, err = GetAvailablePortE(t)
+		if err != nil {
+			tunnel.logger.Logf(t, "Error getting available port: %s", err)
+			return err
+		}
+		tunnel.logger.Logf(t, "Selected port %d", tunnel.localPort)
+		globalMutex.Lock()
+		defer globalMutex.Unlock()
+	}
+
+	errChan, err := tunnel.startPortForwarderE(t, clientset, config)
+	if err != nil {
+		return err
+	}
+
+	// Pods of services and deployments can be replaced, so reopen the tunnel to another one when the connection is lost
+	if tunnel.resourceType != ResourceTypePod {
+		go tunnel.keepPortForwardAlive(t, clientset, config, errChan)
+	}
+	return nil
+}
+
+// startPortForwarderE opens the tunnel to an available pod of the resource in the background, and returns the channel
+// receiving the result of the port forwarding once it stops.
+func (tunnel *Tunnel) startPortForwarderE(t testing.TestingT, clientset *kubernetes.Clientset, config *rest.Config) (<-chan error, error) {
 	// Find the pod to port forward to
 	podName, err := tunnel.getAttachablePodForResourceE(t)
 	if err != nil {
 		tunnel.logger.Logf(t, "Error finding available pod: %s", err)
-		return err
+		return nil, err
 	}
 	tunnel.logger.Logf(t, "Selected pod %s to open port forward to", podName)
 
@@ -218,19 +282,22 @@ func (tunnel *Tunnel) ForwardPortE(t testing.TestingT) error {
 
 	// in case of services, find target port on pod based on service definition
 	if tunnel.resourceType == ResourceTypeService {
-		service := GetService(t, tunnel.kubectlOptions, tunnel.resourceName)
+		service, err := GetServiceE(t, tunnel.kubectlOptions, tunnel.resourceName)
+		if err != nil {
+			return nil, err
+		}
 		var portFound = false
 		for _, portSpec := range service.Spec.Ports {
 			if portSpec.Port == int32(targetPort) {
 				if portSpec.TargetPort.Type == intstr.String {
 					pod, err := GetPodE(t, tunnel.kubectlOptions, podName)
 					if err != nil {
-						return err
+						return nil, err
 					}
 					targetPort, err = getPodPortByName(pod, portSpec.TargetPort.String())
 					if err != nil {
 						tunnel.logger.Logf(t, "Error selecting port by name: %s", err)
-						return err
+						return nil, err
 					}
 					portFound = true
 					break
@@ -241,7 +308,7 @@ func (tunnel *Tunnel) ForwardPortE(t testing.TestingT) error {
 			}
 		}
 		if !portFound {
-			return errors.New(fmt.Sprintf("Target port %d not found in service %s definition.", targetPort, tunnel.resourceName))
+			return nil, errors.New(fmt.Sprintf("Target port %d not found in service %s definition.", targetPort, tunnel.resourceName))
 		}
 	}
 
@@ -262,39 +329,21 @@ func (tunnel *Tunnel) ForwardPortE(t testing.TestingT) error {
 	transport, upgrader, err := spdy.RoundTripperFor(config)
 	if err != nil {
 		tunnel.logger.Logf(t, "Error creating http client: %s", err)
-		return err
+		return nil, err
 	}
 	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, "POST", portForwardCreateURL)
 
-	// If the localport is 0, get an available port before continuing. We do this here instead of relying on the
-	// underlying portforwarder library, because the portforwarder library does not expose the selected local port in a
-	// machine readable manner.
-	// Synchronize on the global lock to avoid race conditions with concurrently selecting the same available port,
-	// since there is a brief moment between `GetAvailablePort` and `portforwader.ForwardPorts` where the selected port
-	// is available for selection again.
-	if tunnel.localPort == 0 {
-		tunnel.logger.Logf(t, "Requested local port is 0. Selecting an open port on host system")
-		tunnel.localPort, err = GetAvailablePortE(t)
-		if err != nil {
-			tunnel.logger.Logf(t, "Error getting available port: %s", err)
-			return err
-		}
-		tunnel.logger.Logf(t, "Selected port %d", tunnel.localPort)
-		globalMutex.Lock()
-		defer globalMutex.Unlock()
-	}
-
 	// Construct a new PortForwarder struct that manages the instructed port forward tunnel
 	ports := []string{fmt.Sprintf("%d:%d", tunnel.localPort, targetPort)}
-	portforwarder, err := portforward.New(dialer, ports, tunnel.stopChan, tunnel.readyChan, tunnel.out, tunnel.out)
+	portforwarder, err := portforward.New(dialer, ports, tunnel.stopChan, make(chan struct{}, 1), tunnel.out, tunnel.out)
 	if err != nil {
 		tunnel.logger.Logf(t, "Error creating port forwarding tunnel: %s", err)
-		return err
+		return nil, err
 	}
 
 	// Open the tunnel in a goroutine so that it is available in the background. Report errors to the main goroutine via
-	// a new channel.
-	errChan := make(chan error)
+	// a new channel, which is buffered so the goroutine can exit even if nobody waits for the result.
+	errChan := make(chan error, 1)
 	go func() {
 		errChan <- portforwarder.ForwardPorts()
 	}()
@@ -303,10 +352,51 @@ func (tunnel *Tunnel) ForwardPortE(t testing.TestingT) error {
 	select {
 	case err = <-errChan:
 		tunnel.logger.Logf(t, "Error starting port forwarding tunnel: %s", err)
-		return err
+		return nil, err
 	case <-portforwarder.Ready:
 		tunnel.logger.Logf(t, "Successfully created port forwarding tunnel")
-		return nil
+		return errChan, nil
+	}
+}
+
+// keepPortForwardAlive waits for the port forwarding to stop, and reopens the tunnel to another available pod of the
+// resource if it stopped because the connection was lost rather than because the tunnel was closed.
+func (tunnel *Tunnel) keepPortForwardAlive(t testing.TestingT, clientset *kubernetes.Clientset, config *rest.Config, errChan <-chan error) {
+	for {
+		err := <-errChan
+		if tunnel.isClosed() {
+			return
+		}
+		tunnel.logger.Logf(t, "Lost port forwarding tunnel to resource %s/%s: %v", tunnel.resourceType.String(), tunnel.resourceName, err)
+
+		errChan = nil
+		var reconnectErr error
+		for i := 0; i < tunnelReconnectRetries && errChan == nil; i++ {
+			select {
+			case <-tunnel.stopChan:
+				return
+			case <-time.After(tunnelReconnectSleep):
+			}
+			errChan, reconnectErr = tunnel.startPortForwarderE(t, clientset, config)
+		}
+		if errChan == nil {
+			err := fmt.Errorf("gave up reopening port forwarding tunnel to resource %s/%s after %d attempts: %w", tunnel.resourceType.String(), tunnel.resourceName, tunnelReconnectRetries, reconnectErr)
+			tunnel.errMutex.Lock()
+			tunnel.err = err
+			tunnel.errMutex.Unlock()
+			tunnel.logger.Logf(t, "%v", err)
+			return
+		}
+	}
+}
+
+// isClosed returns true if the tunnel was closed with Close.
+func (tunnel *Tunnel) isClosed() bool {
+	select {
+	case <-tunnel.stopChan:
+		return true
+	default:
+		return false
 	}
 }
 
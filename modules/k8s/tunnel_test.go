@@ -18,6 +18,7 @@ import (
 
 	http_helper "github.com/gruntwork-io/terratest/modules/http-helper"
 	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTunnelOpensAPortForwardTunnelToPod(t *testing.T) {
@@ -134,6 +135,32 @@ func TestTunnelOpensAPortForwardTunnelToService(t *testing.T) {
 	}
 }
 
+func TestTunnelReconnectsToDeploymentWhenPodIsDeleted(t *testing.T) {
+	t.Parallel()
+
+	uniqueID := strings.ToLower(random.UniqueId())
+	options := NewKubectlOptions("", "", uniqueID)
+	configData := fmt.Sprintf(ExampleDeploymentYAMLTemplate, uniqueID)
+	KubectlApplyFromString(t, options, configData)
+	defer KubectlDeleteFromString(t, options, configData)
+	WaitUntilDeploymentAvailable(t, options, "nginx-deployment", 60, 1*time.Second)
+
+	tunnel := NewTunnel(options, ResourceTypeDeployment, "nginx-deployment", 0, 80)
+	defer tunnel.Close()
+	tunnel.ForwardPort(t)
+
+	tlsConfig := tls.Config{}
+	url := fmt.Sprintf("http://%s", tunnel.Endpoint())
+	http_helper.HttpGetWithRetryWithCustomValidation(t, url, &tlsConfig, 60, 5*time.Second, verifyNginxWelcomePage)
+
+	// Replace all the pods of the deployment, so the tunnel has to be reopened to a new one
+	RunKubectl(t, options, "delete", "pods", "-l", "app=nginx")
+	WaitUntilDeploymentAvailable(t, options, "nginx-deployment", 60, 1*time.Second)
+
+	http_helper.HttpGetWithRetryWithCustomValidation(t, url, &tlsConfig, 60, 5*time.Second, verifyNginxWelcomePage)
+	require.NoError(t, tunnel.Err())
+}
+
 func verifyNginxWelcomePage(statusCode int, body string) bool {
 	if statusCode != 200 {
 		return false
@@ -0,0 +1,135 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+const (
+	// LogDirEnvVar is the environment variable that, if set, makes Default a PerTestFileLogger writing to that
+	// directory, while still logging to stdout.
+	LogDirEnvVar = "TERRATEST_LOG_DIR"
+
+	// CombinedLogFileName is the name of the file of a PerTestFileLogger with the log output of all the tests.
+	CombinedLogFileName = "combined.log"
+
+	// IndexFileName is the name of the file of a PerTestFileLogger that lists each test with the path of its log file.
+	IndexFileName = "index.txt"
+)
+
+// unsafeFileNameChars matches the characters of the parts of test names that are replaced in the names of their log
+// files.
+var unsafeFileNameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+// PerTestFileLogger logs the output of each test to its own file in a directory, e.g. an artifacts directory of a CI
+// build, so that the output of parallel tests isn't interleaved. Subtests get files in a directory named after their
+// parent test. It also writes the output of all the tests to a combined log file, and lists the log file of each test
+// in an index file. Use it with New, or set the TERRATEST_LOG_DIR environment variable to use it as the Default logger.
+type PerTestFileLogger struct {
+	dir     string
+	stdout  bool
+	mutex   sync.Mutex
+	indexed map[string]bool // the log files listed in the index file
+}
+
+// NewPerTestFileLogger creates a PerTestFileLogger writing to the given directory, which is created if needed. If
+// stdout is set, it also logs to stdout like the Terratest logger.
+func NewPerTestFileLogger(dir string, stdout bool) (*PerTestFileLogger, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &PerTestFileLogger{dir: dir, stdout: stdout, indexed: map[string]bool{}}, nil
+}
+
+// Logf logs the given format and arguments to the log file of the test, the combined log file and, if enabled, stdout,
+// along with a timestamp and information about what test and file is doing the logging.
+func (l *PerTestFileLogger) Logf(t testing.TestingT, format string, args ...interface{}) {
+	var line bytes.Buffer
+	DoLog(t, 3, &line, fmt.Sprintf(format, args...))
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.stdout {
+		os.Stdout.Write(line.Bytes())
+	}
+
+	path := l.LogFilePath(t)
+	if err := appendFile(path, line.Bytes()); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write the log file of test %s: %v\n", t.Name(), err)
+	} else if !l.indexed[path] {
+		l.indexed[path] = true
+		if err := l.addToIndex(t.Name(), path); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write the log index file: %v\n", err)
+		}
+	}
+	if err := appendFile(filepath.Join(l.dir, CombinedLogFileName), line.Bytes()); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write the combined log file: %v\n", err)
+	}
+}
+
+// LogFilePath returns the path of the log file of the given test, in the directory of the logger. Each part of the name
+// of the test, between slashes, becomes a directory or file name, with the characters other than letters, digits, dots,
+// dashes and underscores replaced, so that subtests named e.g. ".." can't write outside of the directory.
+func (l *PerTestFileLogger) LogFilePath(t testing.TestingT) string {
+	name := t.Name()
+	if name == "" {
+		name = "unknown"
+	}
+
+	parts := strings.Split(name, "/")
+	for i, part := range parts {
+		part = unsafeFileNameChars.ReplaceAllString(part, "_")
+		// Neither empty parts nor "." and ".." must be path elements
+		if strings.Trim(part, ".") == "" {
+			part = strings.ReplaceAll(part, ".", "_") + "_"
+		}
+		parts[i] = part
+	}
+	return filepath.Join(append([]string{l.dir}, parts...)...) + ".log"
+}
+
+// addToIndex lists the log file at the given path of the test with the given name in the index file.
+func (l *PerTestFileLogger) addToIndex(testName string, path string) error {
+	relPath, err := filepath.Rel(l.dir, path)
+	if err != nil {
+		return err
+	}
+	line := fmt.Sprintf("%s\t%s\n", testName, filepath.ToSlash(relPath))
+	return appendFile(filepath.Join(l.dir, IndexFileName), []byte(line))
+}
+
+// appendFile appends the given data to the file at the given path, creating it and its directory if needed.
+func appendFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(data)
+	return err
+}
+
+// defaultLogger returns the logger to use as Default: a PerTestFileLogger if the TERRATEST_LOG_DIR environment
+// variable is set, or else the Terratest logger.
+func defaultLogger() *Logger {
+	if dir := os.Getenv(LogDirEnvVar); dir != "" {
+		fileLogger, err := NewPerTestFileLogger(dir, true)
+		if err == nil {
+			return New(fileLogger)
+		}
+		fmt.Fprintf(os.Stderr, "Failed to log to the directory %s of %s, logging to stdout only: %v\n", dir, LogDirEnvVar, err)
+	}
+	return New(terratestLogger{})
+}
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPerTestFileLogger(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	fileLogger, err := NewPerTestFileLogger(dir, false)
+	require.NoError(t, err)
+	l := New(fileLogger)
+
+	l.Logf(t, "parent log %d", 1)
+	t.Run("sub test", func(t *testing.T) {
+		l.Logf(t, "subtest log")
+	})
+	l.Logf(t, "parent log %d", 2)
+
+	parentLog, err := os.ReadFile(filepath.Join(dir, "TestPerTestFileLogger.log"))
+	require.NoError(t, err)
+	assert.Regexp(t, `^TestPerTestFileLogger .+? files_test.go:[0-9]+: parent log 1\nTestPerTestFileLogger .+: parent log 2\n$`, string(parentLog))
+
+	subLog, err := os.ReadFile(filepath.Join(dir, "TestPerTestFileLogger", "sub_test.log"))
+	require.NoError(t, err)
+	assert.Contains(t, string(subLog), "subtest log")
+	assert.NotContains(t, string(subLog), "parent log")
+
+	combinedLog, err := os.ReadFile(filepath.Join(dir, CombinedLogFileName))
+	require.NoError(t, err)
+	assert.Regexp(t, `parent log 1\n.+subtest log\n.+parent log 2\n$`, string(combinedLog))
+
+	index, err := os.ReadFile(filepath.Join(dir, IndexFileName))
+	require.NoError(t, err)
+	assert.Equal(t, "TestPerTestFileLogger\tTestPerTestFileLogger.log\nTestPerTestFileLogger/sub_test\tTestPerTestFileLogger/sub_test.log\n", string(index))
+}
+
+func TestPerTestFileLoggerLogFilePathStaysInDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	fileLogger, err := NewPerTestFileLogger(dir, false)
+	require.NoError(t, err)
+
+	testCases := map[string]string{
+		"..":               "TestPerTestFileLoggerLogFilePathStaysInDir/___.log",
+		"../../etc/passwd": "TestPerTestFileLoggerLogFilePathStaysInDir/___/___/etc/passwd.log",
+		`..\system32`:      "TestPerTestFileLoggerLogFilePathStaysInDir/.._system32.log",
+		"./a//b":           "TestPerTestFileLoggerLogFilePathStaysInDir/__/a/_/b.log",
+	}
+	for subtestName, expectedPath := range testCases {
+		t.Run(subtestName, func(t *testing.T) {
+			assert.Equal(t, filepath.Join(dir, filepath.FromSlash(expectedPath)), fileLogger.LogFilePath(t))
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// LogLevelEnvVar is the environment variable that sets DefaultLevel, e.g. to info to not log the output of commands in
+// CI, while keeping it for debug reruns.
+const LogLevelEnvVar = "TERRATEST_LOG_LEVEL"
+
+// Level is the level of a log message. Loggers only log the messages of at least their minimum level.
+type Level int
+
+const (
+	// LevelUnset is the minimum level of loggers that use DefaultLevel.
+	LevelUnset Level = iota
+	// LevelDebug is the level of detailed messages, such as the output of commands.
+	LevelDebug
+	// LevelInfo is the level of Logf, e.g. the commands that are run.
+	LevelInfo
+	// LevelWarn is the level of unexpected but recoverable conditions, such as errors that are retried.
+	LevelWarn
+	// LevelError is the level of errors.
+	LevelError
+)
+
+// DefaultLevel is the minimum level of the loggers that don't set one. It's set with the TERRATEST_LOG_LEVEL
+// environment variable, and logs all the messages if it's not set.
+var DefaultLevel = levelFromEnv()
+
+// ParseLevel returns the level with the given name: debug, info, warn (or warning) or error.
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return LevelUnset, fmt.Errorf("unknown log level %q, expected one of debug, info, warn or error", name)
+	}
+}
+
+func (level Level) String() string {
+	switch level {
+	case LevelUnset:
+		return "unset"
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return fmt.Sprintf("Level(%d)", int(level))
+	}
+}
+
+// WithMinLevel returns a copy of the logger that only logs the messages of at least the given level, or the logger
+// itself if the level is LevelUnset. A copy of a nil logger logs with the Default logger.
+func (l *Logger) WithMinLevel(level Level) *Logger {
+	if level == LevelUnset {
+		return l
+	}
+	if l == nil {
+		return &Logger{minLevel: level}
+	}
+	return &Logger{l: l.l, minLevel: level}
+}
+
+// Enabled returns true if the logger logs the messages of the given level.
+func (l *Logger) Enabled(level Level) bool {
+	minLevel := DefaultLevel
+	switch {
+	case l != nil && l.minLevel != LevelUnset:
+		minLevel = l.minLevel
+	case (l == nil || l.l == nil) && Default != nil && Default.minLevel != LevelUnset:
+		// Loggers that log with the Default logger use its level too
+		minLevel = Default.minLevel
+	}
+	return level >= minLevel
+}
+
+// Debugf logs the given format and arguments at the debug level.
+func (l *Logger) Debugf(t testing.TestingT, format string, args ...interface{}) {
+	if tt, ok := t.(helper); ok {
+		tt.Helper()
+	}
+
+	if l.Enabled(LevelDebug) {
+		l.testLogger().Logf(t, format, args...)
+	}
+}
+
+// Infof logs the given format and arguments at the info level, like Logf.
+func (l *Logger) Infof(t testing.TestingT, format string, args ...interface{}) {
+	if tt, ok := t.(helper); ok {
+		tt.Helper()
+	}
+
+	if l.Enabled(LevelInfo) {
+		l.testLogger().Logf(t, format, args...)
+	}
+}
+
+// Warnf logs the given format and arguments at the warn level.
+func (l *Logger) Warnf(t testing.TestingT, format string, args ...interface{}) {
+	if tt, ok := t.(helper); ok {
+		tt.Helper()
+	}
+
+	if l.Enabled(LevelWarn) {
+		l.testLogger().Logf(t, format, args...)
+	}
+}
+
+// Errorf logs the given format and arguments at the error level.
+func (l *Logger) Errorf(t testing.TestingT, format string, args ...interface{}) {
+	if tt, ok := t.(helper); ok {
+		tt.Helper()
+	}
+
+	if l.Enabled(LevelError) {
+		l.testLogger().Logf(t, format, args...)
+	}
+}
+
+// levelFromEnv returns the level of the TERRATEST_LOG_LEVEL environment variable, or LevelDebug if it's not set or
+// invalid.
+func levelFromEnv() Level {
+	name := os.Getenv(LogLevelEnvVar)
+	if name == "" {
+		return LevelDebug
+	}
+	level, err := ParseLevel(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid %s, logging all messages: %v\n", LogLevelEnvVar, err)
+		return LevelDebug
+	}
+	return level
+}
@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLevel(t *testing.T) {
+	t.Parallel()
+
+	for name, expected := range map[string]Level{"debug": LevelDebug, "INFO": LevelInfo, "warn": LevelWarn, "warning": LevelWarn, " error ": LevelError} {
+		level, err := ParseLevel(name)
+		require.NoError(t, err)
+		assert.Equal(t, expected, level)
+	}
+
+	_, err := ParseLevel("verbose")
+	assert.Error(t, err)
+}
+
+func TestLoggerWithMinLevel(t *testing.T) {
+	t.Parallel()
+
+	c := &customLogger{}
+	l := New(c)
+	l.Debugf(t, "debug %d", 1)
+	l.Logf(t, "info %d", 1)
+
+	warnLogger := l.WithMinLevel(LevelWarn)
+	warnLogger.Debugf(t, "debug %d", 2)
+	warnLogger.Infof(t, "info %d", 2)
+	warnLogger.Warnf(t, "warn %d", 2)
+	warnLogger.Errorf(t, "error %d", 2)
+
+	assert.Equal(t, []string{"debug 1", "info 1", "warn 2", "error 2"}, c.logs)
+	assert.True(t, warnLogger.Enabled(LevelError))
+	assert.False(t, warnLogger.Enabled(LevelInfo))
+	assert.Same(t, l, l.WithMinLevel(LevelUnset))
+
+	var nilLogger *Logger
+	assert.True(t, nilLogger.WithMinLevel(LevelError).Enabled(LevelError))
+	assert.False(t, nilLogger.WithMinLevel(LevelError).Enabled(LevelWarn))
+}
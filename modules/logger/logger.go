@@ -16,8 +16,9 @@ import (
 
 var (
 	// Default is the default logger that is used for the Logf function, if no one is provided. It uses the
-	// TerratestLogger to log messages. This can be overwritten to change the logging globally.
-	Default = New(terratestLogger{})
+	// TerratestLogger to log messages, or a PerTestFileLogger if the TERRATEST_LOG_DIR environment variable is set. This
+	// can be overwritten to change the logging globally.
+	Default = defaultLogger()
 	// Discard discards all logging.
 	Discard = New(discardLogger{})
 	// Terratest logs the given format and arguments, formatted using fmt.Sprintf, to stdout, along with a timestamp and
@@ -47,28 +48,37 @@ type TestLogger interface {
 }
 
 type Logger struct {
-	l TestLogger
+	l        TestLogger
+	minLevel Level
 }
 
 func New(l TestLogger) *Logger {
 	return &Logger{
-		l,
+		l: l,
 	}
 }
 
+// Logf logs the given format and arguments at the info level.
 func (l *Logger) Logf(t testing.TestingT, format string, args ...interface{}) {
 	if tt, ok := t.(helper); ok {
 		tt.Helper()
 	}
 
-	// methods can be called on (typed) nil pointers. In this case, use the Default function to log. This enables the
-	// caller to do `var l *Logger` and then use the logger already.
-	if l == nil || l.l == nil {
-		Default.Logf(t, format, args...)
-		return
+	if l.Enabled(LevelInfo) {
+		l.testLogger().Logf(t, format, args...)
 	}
+}
 
-	l.l.Logf(t, format, args...)
+// testLogger returns the TestLogger of the logger. Methods can be called on (typed) nil pointers. In this case, use
+// the Default logger. This enables the caller to do `var l *Logger` and then use the logger already.
+func (l *Logger) testLogger() TestLogger {
+	if l != nil && l.l != nil {
+		return l.l
+	}
+	if Default != nil && Default.l != nil {
+		return Default.l
+	}
+	return terratestLogger{}
 }
 
 // helper is used to mark this library as a "helper", and thus not appearing in the line numbers. testing.T implements
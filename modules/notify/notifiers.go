@@ -0,0 +1,85 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// defaultNotifyTimeout is the timeout of the requests sending notifications.
+const defaultNotifyTimeout = 30 * time.Second
+
+// WebhookNotifier posts failures as JSON to a URL.
+type WebhookNotifier struct {
+	Url     string
+	Headers map[string]string // e.g. an Authorization header
+}
+
+// Notify posts the failure as JSON to the URL of the notifier.
+func (notifier WebhookNotifier) Notify(failure Failure) error {
+	return postJsonE(notifier.Url, notifier.Headers, failure)
+}
+
+// SlackNotifier posts failures to Slack, either to an incoming webhook URL, or to a channel with a bot token.
+type SlackNotifier struct {
+	WebhookUrl string // the URL of an incoming webhook to post to, if set
+	Token      string // the bot token to post to the channel with, if WebhookUrl is not set
+	ChannelID  string
+}
+
+// Notify posts the text of the failure to Slack.
+func (notifier SlackNotifier) Notify(failure Failure) error {
+	if notifier.WebhookUrl != "" {
+		return slack.PostWebhookCustomHTTP(notifier.WebhookUrl, &http.Client{Timeout: defaultNotifyTimeout}, &slack.WebhookMessage{Text: failure.Text()})
+	}
+	if notifier.Token == "" || notifier.ChannelID == "" {
+		return fmt.Errorf("a Slack notifier needs either a webhook URL, or a token and a channel ID")
+	}
+	_, _, err := slack.New(notifier.Token).PostMessage(notifier.ChannelID, slack.MsgOptionText(failure.Text(), false))
+	return err
+}
+
+// TeamsNotifier posts failures to a Microsoft Teams incoming webhook URL.
+type TeamsNotifier struct {
+	WebhookUrl string
+}
+
+// Notify posts the text of the failure to the Teams webhook of the notifier.
+func (notifier TeamsNotifier) Notify(failure Failure) error {
+	return postJsonE(notifier.WebhookUrl, nil, map[string]string{"text": failure.Text()})
+}
+
+// postJsonE posts the given value as JSON to the given URL with the given headers, and returns an error if the
+// response status code is not 2xx.
+func postJsonE(url string, headers map[string]string, value interface{}) error {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := (&http.Client{Timeout: defaultNotifyTimeout}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("posting to %s failed with status %d: %s", url, resp.StatusCode, respBody)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+// Package notify sends notifications of failed tests to webhooks, Slack and Microsoft Teams, which is useful for long
+// running nightly infrastructure test suites.
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	test_structure "github.com/gruntwork-io/terratest/modules/test-structure"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// maxErrorSummaryLength is the maximum length of the error summary of a Failure.
+const maxErrorSummaryLength = 2000
+
+// Failure is a failed test.
+type Failure struct {
+	TestName string    `json:"test_name"`
+	Stage    string    `json:"stage,omitempty"`   // the test stage that stopped the test, if any
+	Error    string    `json:"error,omitempty"`   // the summary of the errors of the test, if they were recorded
+	LogUrl   string    `json:"log_url,omitempty"` // a link to the log artifact of the test, if set
+	Time     time.Time `json:"time"`
+}
+
+// Text returns a human readable description of the failure.
+func (failure Failure) Text() string {
+	text := fmt.Sprintf("Test %s failed", failure.TestName)
+	if failure.Stage != "" {
+		text += fmt.Sprintf(" in stage '%s'", failure.Stage)
+	}
+	if failure.Error != "" {
+		text += ":\n" + failure.Error
+	}
+	if failure.LogUrl != "" {
+		text += "\nLogs: " + failure.LogUrl
+	}
+	return text
+}
+
+// Notifier sends notifications of failed tests.
+type Notifier interface {
+	Notify(failure Failure) error
+}
+
+// TestingT is a testing.TestingT that can register cleanup functions and report whether it failed, like *testing.T.
+type TestingT interface {
+	testing.TestingT
+	Cleanup(func())
+	Failed() bool
+}
+
+// Options are the options of NotifyOnFailure.
+type Options struct {
+	Notifiers []Notifier
+	LogUrl    string // a link to the log artifact of the test, e.g. in the artifacts of the CI build, if set
+}
+
+// NotifyOnFailure sends a notification to the notifiers of the given options when the given test ends, if it failed.
+// The notification includes the test stage that stopped the test, if any. Errors sending it are logged, but don't fail
+// the test.
+//
+// It returns a testing.TestingT that records the errors of the test for the error summary of the notification: pass it
+// instead of t to the functions of Terratest and testify whose errors should be included.
+func NotifyOnFailure(t TestingT, options Options) testing.TestingT {
+	recorder := &errorRecorder{TestingT: t}
+	t.Cleanup(func() {
+		if !t.Failed() {
+			return
+		}
+
+		failure := Failure{
+			TestName: t.Name(),
+			Stage:    test_structure.CurrentStage(t),
+			Error:    recorder.summary(),
+			LogUrl:   options.LogUrl,
+			Time:     time.Now(),
+		}
+		for _, notifier := range options.Notifiers {
+			if err := notifier.Notify(failure); err != nil {
+				logger.Default.Logf(t, "Failed to send the notification of the failure of test %s: %v", t.Name(), err)
+			}
+		}
+	})
+	return recorder
+}
+
+// errorRecorder is a testing.TestingT that records the messages of its errors.
+type errorRecorder struct {
+	TestingT
+	mutex  sync.Mutex
+	errors []string
+}
+
+func (recorder *errorRecorder) record(message string) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	recorder.errors = append(recorder.errors, strings.TrimSpace(message))
+}
+
+// summary returns the recorded error messages, truncated to the last maxErrorSummaryLength characters.
+func (recorder *errorRecorder) summary() string {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	summary := strings.Join(recorder.errors, "\n")
+	if len(summary) > maxErrorSummaryLength {
+		summary = "..." + summary[len(summary)-maxErrorSummaryLength:]
+	}
+	return summary
+}
+
+func (recorder *errorRecorder) Error(args ...interface{}) {
+	recorder.record(fmt.Sprint(args...))
+	recorder.TestingT.Error(args...)
+}
+
+func (recorder *errorRecorder) Errorf(format string, args ...interface{}) {
+	recorder.record(fmt.Sprintf(format, args...))
+	recorder.TestingT.Errorf(format, args...)
+}
+
+func (recorder *errorRecorder) Fatal(args ...interface{}) {
+	recorder.record(fmt.Sprint(args...))
+	recorder.TestingT.Fatal(args...)
+}
+
+func (recorder *errorRecorder) Fatalf(format string, args ...interface{}) {
+	recorder.record(fmt.Sprintf(format, args...))
+	recorder.TestingT.Fatalf(format, args...)
+}
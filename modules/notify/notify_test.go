@@ -0,0 +1,116 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	test_structure "github.com/gruntwork-io/terratest/modules/test-structure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeT is a TestingT whose FailNow stops the goroutine running it, and whose cleanup functions run on cleanup.
+type fakeT struct {
+	name     string
+	failed   bool
+	cleanups []func()
+}
+
+func (t *fakeT) Fail()                                     { t.failed = true }
+func (t *fakeT) FailNow()                                  { t.failed = true; runtime.Goexit() }
+func (t *fakeT) Fatal(args ...interface{})                 { t.FailNow() }
+func (t *fakeT) Fatalf(format string, args ...interface{}) { t.FailNow() }
+func (t *fakeT) Error(args ...interface{})                 { t.Fail() }
+func (t *fakeT) Errorf(format string, args ...interface{}) { t.Fail() }
+func (t *fakeT) Name() string                              { return t.name }
+func (t *fakeT) Failed() bool                              { return t.failed }
+func (t *fakeT) Cleanup(cleanup func())                    { t.cleanups = append(t.cleanups, cleanup) }
+
+func (t *fakeT) run(test func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		test()
+	}()
+	<-done
+	for i := len(t.cleanups) - 1; i >= 0; i-- {
+		t.cleanups[i]()
+	}
+}
+
+func TestNotifyOnFailure(t *testing.T) {
+	t.Parallel()
+
+	failures := make(chan Failure, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		var failure Failure
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&failure))
+		failures <- failure
+	}))
+	defer ts.Close()
+
+	fake := &fakeT{name: "TestNotifyOnFailureFake"}
+	fake.run(func() {
+		nt := NotifyOnFailure(fake, Options{
+			Notifiers: []Notifier{WebhookNotifier{Url: ts.URL, Headers: map[string]string{"Authorization": "Bearer token"}}},
+			LogUrl:    "https://ci.example.com/artifacts/TestNotifyOnFailureFake.log",
+		})
+		test_structure.RunTestStage(nt, "setup", func() {})
+		test_structure.RunTestStage(nt, "deploy", func() {
+			require.NoError(nt, fmt.Errorf("apply failed"))
+		})
+	})
+
+	require.Len(t, failures, 1)
+	failure := <-failures
+	assert.Equal(t, "TestNotifyOnFailureFake", failure.TestName)
+	assert.Equal(t, "deploy", failure.Stage)
+	assert.Contains(t, failure.Error, "apply failed")
+	assert.Equal(t, "https://ci.example.com/artifacts/TestNotifyOnFailureFake.log", failure.LogUrl)
+	assert.Contains(t, failure.Text(), "Test TestNotifyOnFailureFake failed in stage 'deploy':")
+}
+
+func TestNotifyOnFailurePassed(t *testing.T) {
+	t.Parallel()
+
+	notified := false
+	fake := &fakeT{name: "TestNotifyOnFailurePassedFake"}
+	fake.run(func() {
+		NotifyOnFailure(fake, Options{Notifiers: []Notifier{notifierFunc(func(Failure) error {
+			notified = true
+			return nil
+		})}})
+	})
+	assert.False(t, notified)
+}
+
+func TestSlackAndTeamsNotifiers(t *testing.T) {
+	t.Parallel()
+
+	texts := make(chan string, 2)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+		texts <- message["text"].(string)
+	}))
+	defer ts.Close()
+
+	failure := Failure{TestName: "TestFoo", Error: "boom"}
+	require.NoError(t, SlackNotifier{WebhookUrl: ts.URL}.Notify(failure))
+	require.NoError(t, TeamsNotifier{WebhookUrl: ts.URL}.Notify(failure))
+	assert.Equal(t, "Test TestFoo failed:\nboom", <-texts)
+	assert.Equal(t, "Test TestFoo failed:\nboom", <-texts)
+
+	assert.Error(t, SlackNotifier{}.Notify(failure))
+}
+
+type notifierFunc func(failure Failure) error
+
+func (notify notifierFunc) Notify(failure Failure) error {
+	return notify(failure)
+}
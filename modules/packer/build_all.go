@@ -0,0 +1,124 @@
+package packer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// BuildResult is the result of one of the builds run by BuildAll.
+type BuildResult struct {
+	// The ID of the artifact generated by the build, if any
+	ArtifactID string
+
+	// The machine-readable output of the build
+	Output string
+
+	// The error of the build, if it failed or was cancelled
+	Err error
+
+	// Whether the build was interrupted because another build failed, in which case Err matches context.Canceled
+	Cancelled bool
+}
+
+// SplitBuilds returns options to run each of the given builds of the template of the options on its own (e.g.
+// amazon-ebs.ubuntu, googlecompute.ubuntu), by build name, to pass to BuildAll.
+func SplitBuilds(options *Options, buildNames ...string) map[string]*Options {
+	builds := map[string]*Options{}
+	for _, buildName := range buildNames {
+		buildOptions := *options
+		buildOptions.Only = ""
+		buildOptions.OnlyBuilds = []string{buildName}
+		// Each build gets its own temporary plugin path in its env
+		buildOptions.Env = map[string]string{}
+		for key, value := range options.Env {
+			buildOptions.Env[key] = value
+		}
+		builds[buildName] = &buildOptions
+	}
+	return builds
+}
+
+// BuildAll runs the given builds in parallel and returns their results, by name. The builds can be the builds of a
+// template split with SplitBuilds, or different templates. This will fail the test if any of the builds fails.
+func BuildAll(t testing.TestingT, builds map[string]*Options, cancelOnFailure bool) map[string]*BuildResult {
+	results, err := BuildAllE(t, builds, cancelOnFailure)
+	require.NoError(t, err)
+	return results
+}
+
+// BuildAllE runs the given builds in parallel and returns their results, by name, along with an error listing the
+// builds that failed. The builds can be the builds of a template split with SplitBuilds, or different templates. If
+// cancelOnFailure is set, the first failure interrupts the builds still running, which get the KillDelay of their
+// options (15 minutes by default) to clean up what they created before Packer is killed, and are marked Cancelled.
+func BuildAllE(t testing.TestingT, builds map[string]*Options, cancelOnFailure bool) (map[string]*BuildResult, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var lock sync.Mutex
+	results := map[string]*BuildResult{}
+	firstFailure := ""
+
+	var wg sync.WaitGroup
+	for name, options := range builds {
+		wg.Add(1)
+		go func(name string, options *Options) {
+			defer wg.Done()
+
+			options.Logger.Logf(t, "Running Packer build %s of template %s", name, options.Template)
+			result := &BuildResult{}
+			result.Output, result.Err = runPackerBuildE(t, ctx, options)
+			if result.Err == nil {
+				// Builds with no artifact, e.g. with the null builder, don't fail
+				result.ArtifactID, _ = extractArtifactID(result.Output)
+			}
+
+			lock.Lock()
+			defer lock.Unlock()
+			switch {
+			case result.Err == nil:
+			case errors.Is(result.Err, context.Canceled):
+				result.Cancelled = true
+			case firstFailure == "":
+				firstFailure = name
+				if cancelOnFailure {
+					cancel()
+				}
+			}
+			results[name] = result
+		}(name, options)
+	}
+	wg.Wait()
+
+	return results, collectBuildErrors(results)
+}
+
+// collectBuildErrors returns an error listing the failed builds, or nil if all of them succeeded.
+func collectBuildErrors(results map[string]*BuildResult) error {
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	failures := []string{}
+	for _, name := range names {
+		result := results[name]
+		switch {
+		case result.Cancelled:
+			failures = append(failures, fmt.Sprintf("%s: cancelled", name))
+		case result.Err != nil:
+			failures = append(failures, fmt.Sprintf("%s: %v", name, result.Err))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%d of %d packer builds failed: %s", len(failures), len(results), strings.Join(failures, "; "))
+	}
+	return nil
+}
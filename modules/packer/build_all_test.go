@@ -0,0 +1,96 @@
+package packer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitBuilds(t *testing.T) {
+	t.Parallel()
+
+	options := &Options{
+		Template: "build.pkr.hcl",
+		Only:     "amazon-ebs.ubuntu",
+		Env:      map[string]string{"AWS_REGION": "us-east-1"},
+	}
+	builds := SplitBuilds(options, "amazon-ebs.ubuntu", "googlecompute.ubuntu")
+	require.Len(t, builds, 2)
+
+	gcp := builds["googlecompute.ubuntu"]
+	assert.Equal(t, "build.pkr.hcl", gcp.Template)
+	assert.Equal(t, "", gcp.Only)
+	assert.Equal(t, []string{"googlecompute.ubuntu"}, gcp.OnlyBuilds)
+	assert.Equal(t, "us-east-1", gcp.Env["AWS_REGION"])
+
+	// The builds don't share their env, which gets their temporary plugin path
+	gcp.Env["PACKER_PLUGIN_PATH"] = "/tmp/plugins"
+	assert.NotContains(t, builds["amazon-ebs.ubuntu"].Env, "PACKER_PLUGIN_PATH")
+	assert.NotContains(t, options.Env, "PACKER_PLUGIN_PATH")
+}
+
+func TestCollectBuildErrors(t *testing.T) {
+	t.Parallel()
+
+	results := map[string]*BuildResult{
+		"amazon-ebs.ubuntu":    {ArtifactID: "ami-0123"},
+		"googlecompute.ubuntu": {ArtifactID: "terratest-image"},
+	}
+	require.NoError(t, collectBuildErrors(results))
+
+	results["azure-arm.ubuntu"] = &BuildResult{Err: errors.New("quota exceeded")}
+	results["googlecompute.ubuntu"] = &BuildResult{Err: errors.New("signal: interrupt"), Cancelled: true}
+	err := collectBuildErrors(results)
+	require.Error(t, err)
+	assert.Equal(t, "2 of 3 packer builds failed: azure-arm.ubuntu: quota exceeded; googlecompute.ubuntu: cancelled", err.Error())
+}
+
+func TestBuildAllCancelsRunningBuilds(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("The fake packer is a shell script")
+	}
+
+	// A fake packer whose builds of the failing template fail once the other builds are ready to be interrupted, while
+	// the other builds run until they are interrupted, and then clean up
+	dir := t.TempDir()
+	fakePacker := `#!/bin/sh
+for template in "$@"; do :; done
+case "$template" in
+  *failing*)
+    while [ "$(ls "$(dirname "$template")" | grep -c '\.started$')" -lt 2 ]; do sleep 0.1; done
+    echo "quota exceeded"; exit 1 ;;
+esac
+trap 'echo "cleaning up"; exit 1' INT
+touch "$template.started"
+while true; do sleep 0.1; done
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "packer"), []byte(fakePacker), 0755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	builds := map[string]*Options{}
+	for _, name := range []string{"failing", "ubuntu", "debian"} {
+		template := filepath.Join(dir, name+".pkr.hcl")
+		require.NoError(t, os.WriteFile(template, []byte{}, 0644))
+		builds[name] = &Options{Template: template, DisableTemporaryPluginPath: true, KillDelay: time.Minute}
+	}
+
+	results, err := BuildAllE(t, builds, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "3 of 3 packer builds failed: debian: cancelled; failing: ")
+	assert.Contains(t, err.Error(), "; ubuntu: cancelled")
+
+	assert.False(t, results["failing"].Cancelled)
+	assert.False(t, errors.Is(results["failing"].Err, context.Canceled))
+	for _, name := range []string{"ubuntu", "debian"} {
+		assert.True(t, results[name].Cancelled)
+		assert.ErrorIs(t, results[name].Err, context.Canceled)
+		assert.Contains(t, results[name].Output, "cleaning up")
+	}
+}
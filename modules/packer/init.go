@@ -0,0 +1,148 @@
+package packer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// requiredPluginsRegexp matches the required_plugins block of the packer block of HCL2 templates.
+var requiredPluginsRegexp = regexp.MustCompile(`(?m)^\s*required_plugins\s*\{`)
+
+// Init runs 'packer init' on the given template to install the plugins listed in its required_plugins block. This
+// will fail the test if there is an error.
+func Init(t testing.TestingT, options *Options) {
+	require.NoError(t, InitE(t, options))
+}
+
+// InitE runs 'packer init' on the given template to install the plugins listed in its required_plugins block, or
+// upgrade them if UpgradePlugins is set. The plugins are installed in the PACKER_PLUGIN_PATH of the Env of the
+// options, if set. Note that BuildArtifactE runs 'packer init' itself when the template has required_plugins.
+func InitE(t testing.TestingT, options *Options) error {
+	options.Logger.Logf(t, "Running 'packer init' for template %s", options.Template)
+
+	cmd := shell.Command{
+		Command:    "packer",
+		Args:       formatPackerInitArgs(options),
+		Env:        options.Env,
+		WorkingDir: options.WorkingDir,
+	}
+
+	description := fmt.Sprintf("%s %v", cmd.Command, cmd.Args)
+	_, err := retry.DoWithRetryableErrorsE(t, description, options.RetryableErrors, options.MaxRetries, options.TimeBetweenRetries, func() (string, error) {
+		return shell.RunCommandAndGetOutputE(t, cmd)
+	})
+	return err
+}
+
+// initIfRequiredE installs the pinned plugins of the options, and runs 'packer init' if the template has
+// required_plugins and the local packer supports it.
+func initIfRequiredE(t testing.TestingT, options *Options) error {
+	if err := installPluginsE(t, options); err != nil {
+		return err
+	}
+
+	requiresPlugins, err := hasRequiredPlugins(options)
+	if err != nil {
+		return err
+	}
+	if !requiresPlugins {
+		options.Logger.Logf(t, "Skipping 'packer init' because the template has no required_plugins")
+		return nil
+	}
+
+	hasInit, err := hasPackerInit(t, options)
+	if err != nil {
+		return err
+	}
+	if !hasInit {
+		options.Logger.Logf(t, "Skipping 'packer init' because it is not present in this version")
+		return nil
+	}
+
+	return InitE(t, options)
+}
+
+// installPluginsE runs 'packer plugins install' for each of the pinned plugins of the options.
+func installPluginsE(t testing.TestingT, options *Options) error {
+	sources := make([]string, 0, len(options.Plugins))
+	for source := range options.Plugins {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	for _, source := range sources {
+		version := options.Plugins[source]
+		if !strings.HasPrefix(version, "v") {
+			version = "v" + version
+		}
+
+		cmd := shell.Command{
+			Command:    "packer",
+			Args:       []string{"plugins", "install", source, version},
+			Env:        options.Env,
+			WorkingDir: options.WorkingDir,
+		}
+		description := fmt.Sprintf("%s %v", cmd.Command, cmd.Args)
+		_, err := retry.DoWithRetryableErrorsE(t, description, options.RetryableErrors, options.MaxRetries, options.TimeBetweenRetries, func() (string, error) {
+			return shell.RunCommandAndGetOutputE(t, cmd)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasRequiredPlugins returns true if the template, which may be an HCL2 file or a directory of HCL2 files, has a
+// required_plugins block. Legacy JSON templates have none.
+func hasRequiredPlugins(options *Options) (bool, error) {
+	templatePath := options.Template
+	if !filepath.IsAbs(templatePath) && options.WorkingDir != "" {
+		templatePath = filepath.Join(options.WorkingDir, templatePath)
+	}
+
+	info, err := os.Stat(templatePath)
+	if err != nil {
+		return false, err
+	}
+
+	paths := []string{templatePath}
+	if info.IsDir() {
+		paths, err = filepath.Glob(filepath.Join(templatePath, "*.pkr.hcl"))
+		if err != nil {
+			return false, err
+		}
+	}
+
+	for _, path := range paths {
+		if filepath.Ext(path) != ".hcl" {
+			continue
+		}
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return false, err
+		}
+		if requiredPluginsRegexp.Match(contents) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// formatPackerInitArgs formats the arguments for the 'packer init' command.
+func formatPackerInitArgs(options *Options) []string {
+	args := []string{"init"}
+	if options.UpgradePlugins {
+		args = append(args, "-upgrade")
+	}
+	return append(args, options.Template)
+}
@@ -0,0 +1,61 @@
+package packer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasRequiredPlugins(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	write := func(name string, contents string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644))
+	}
+	write("build.pkr.hcl", `packer {
+  required_plugins {
+    amazon = {
+      version = ">= 1.2.8"
+      source  = "github.com/hashicorp/amazon"
+    }
+  }
+}
+`)
+	write("sources.pkr.hcl", `source "amazon-ebs" "ubuntu" {}`)
+	write("legacy.json", `{"builders": [{"type": "amazon-ebs"}]}`)
+
+	testCases := []struct {
+		name     string
+		options  *Options
+		expected bool
+	}{
+		{"HCLFile", &Options{Template: filepath.Join(dir, "build.pkr.hcl")}, true},
+		{"HCLFileWithoutPlugins", &Options{Template: "sources.pkr.hcl", WorkingDir: dir}, false},
+		{"Directory", &Options{Template: dir}, true},
+		{"JSONFile", &Options{Template: filepath.Join(dir, "legacy.json")}, false},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			actual, err := hasRequiredPlugins(testCase.options)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, actual)
+		})
+	}
+
+	_, err := hasRequiredPlugins(&Options{Template: filepath.Join(dir, "missing.pkr.hcl")})
+	require.Error(t, err)
+}
+
+func TestFormatPackerInitArgs(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"init", "build.pkr.hcl"}, formatPackerInitArgs(&Options{Template: "build.pkr.hcl"}))
+	assert.Equal(t, []string{"init", "-upgrade", "."}, formatPackerInitArgs(&Options{Template: ".", UpgradePlugins: true}))
+}
@@ -0,0 +1,115 @@
+package packer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// defaultManifestPath is the default output path of the manifest post-processor.
+const defaultManifestPath = "packer-manifest.json"
+
+// Artifact is the artifact of a build, as recorded by the manifest post-processor.
+type Artifact struct {
+	BuilderType string // The type of the builder (e.g. amazon-ebs)
+
+	// The raw artifact ID recorded in the manifest (e.g. us-east-1:ami-0123,us-west-2:ami-4567)
+	ArtifactID string
+
+	// The ID of the artifact in each region. Builders of other clouds than AWS record a single ID, under an empty region.
+	IDs map[string]string
+
+	// The files of the artifact, for the builders producing files
+	Files []string
+}
+
+// BuildAndGetArtifacts builds the given Packer template, which must have a manifest post-processor, and returns the
+// artifacts of the builds of the run, by build name. This will fail the test if there are any errors.
+func BuildAndGetArtifacts(t testing.TestingT, options *Options) map[string]Artifact {
+	artifacts, err := BuildAndGetArtifactsE(t, options)
+	require.NoError(t, err)
+	return artifacts
+}
+
+// BuildAndGetArtifactsE builds the given Packer template, which must have a manifest post-processor, and returns the
+// artifacts of the builds of the run, by build name (e.g. ubuntu for the source amazon-ebs.ubuntu). The manifest is
+// read from the ManifestPath of the options. As the post-processor appends to the manifest, only the builds of this
+// run are returned.
+func BuildAndGetArtifactsE(t testing.TestingT, options *Options) (map[string]Artifact, error) {
+	options.Logger.Logf(t, "Running Packer to generate the artifacts of template %s", options.Template)
+
+	if _, err := runPackerBuildE(t, context.Background(), options); err != nil {
+		return nil, err
+	}
+
+	manifestPath := options.ManifestPath
+	if manifestPath == "" {
+		manifestPath = defaultManifestPath
+	}
+	if !filepath.IsAbs(manifestPath) && options.WorkingDir != "" {
+		manifestPath = filepath.Join(options.WorkingDir, manifestPath)
+	}
+
+	contents, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading manifest file, does the template have a manifest post-processor writing to %s? %w", manifestPath, err)
+	}
+	return parseManifestArtifacts(contents)
+}
+
+// parseManifestArtifacts returns the artifacts of the builds of the last run recorded in the given manifest.
+func parseManifestArtifacts(contents []byte) (map[string]Artifact, error) {
+	var manifest packerManifest
+	if err := json.Unmarshal(contents, &manifest); err != nil {
+		return nil, fmt.Errorf("error unmarshalling manifest file: %w", err)
+	}
+
+	artifacts := map[string]Artifact{}
+	for _, build := range manifest.Builds {
+		if build.PackerRunUUID != manifest.LastRunUUID {
+			continue
+		}
+
+		files := []string{}
+		for _, file := range build.Files {
+			files = append(files, file.Name)
+		}
+		artifacts[build.Name] = Artifact{
+			BuilderType: build.BuilderType,
+			ArtifactID:  build.ArtifactID,
+			IDs:         parseArtifactIDs(build.BuilderType, build.ArtifactID),
+			Files:       files,
+		}
+	}
+	if len(artifacts) == 0 {
+		return nil, fmt.Errorf("no build of the last run %s found in the manifest", manifest.LastRunUUID)
+	}
+	return artifacts, nil
+}
+
+// parseArtifactIDs parses the artifact ID of a build by region. The AWS builders record the ID of the image in each
+// region, formatted as <region>:<id>,<region>:<id>. The other builders record a single ID, which may contain colons
+// (e.g. the sha256: digest of docker images).
+func parseArtifactIDs(builderType string, artifactID string) map[string]string {
+	ids := map[string]string{}
+	if !strings.HasPrefix(builderType, "amazon-") {
+		ids[""] = artifactID
+		return ids
+	}
+
+	for _, regionalID := range strings.Split(artifactID, ",") {
+		region, id, found := strings.Cut(regionalID, ":")
+		if !found {
+			ids[""] = regionalID
+			continue
+		}
+		ids[region] = id
+	}
+	return ids
+}
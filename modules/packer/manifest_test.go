@@ -0,0 +1,35 @@
+package packer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseManifestArtifacts(t *testing.T) {
+	t.Parallel()
+
+	manifest := `{
+  "builds": [
+    {"name": "ubuntu", "builder_type": "amazon-ebs", "artifact_id": "us-east-1:ami-0old", "packer_run_uuid": "run-1"},
+    {"name": "ubuntu", "builder_type": "amazon-ebs", "artifact_id": "us-east-1:ami-0123,us-west-2:ami-4567", "packer_run_uuid": "run-2"},
+    {"name": "image", "builder_type": "googlecompute", "artifact_id": "terratest-image-2024", "packer_run_uuid": "run-2"},
+    {"name": "container", "builder_type": "docker", "artifact_id": "sha256:0a1b2c", "packer_run_uuid": "run-2"},
+    {"name": "archive", "builder_type": "file", "artifact_id": "", "files": [{"name": "out/archive.tar.gz", "size": 1024}], "packer_run_uuid": "run-2"}
+  ],
+  "last_run_uuid": "run-2"
+}`
+	artifacts, err := parseManifestArtifacts([]byte(manifest))
+	require.NoError(t, err)
+	require.Len(t, artifacts, 4)
+
+	assert.Equal(t, map[string]string{"us-east-1": "ami-0123", "us-west-2": "ami-4567"}, artifacts["ubuntu"].IDs)
+	assert.Equal(t, "amazon-ebs", artifacts["ubuntu"].BuilderType)
+	assert.Equal(t, map[string]string{"": "terratest-image-2024"}, artifacts["image"].IDs)
+	assert.Equal(t, map[string]string{"": "sha256:0a1b2c"}, artifacts["container"].IDs)
+	assert.Equal(t, []string{"out/archive.tar.gz"}, artifacts["archive"].Files)
+
+	_, err = parseManifestArtifacts([]byte(`{"builds": [], "last_run_uuid": "run-3"}`))
+	require.Error(t, err)
+}
@@ -2,12 +2,14 @@
 package packer
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 	"regexp"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -23,20 +25,32 @@ import (
 
 // Options are the options for Packer.
 type Options struct {
-	Template                   string            // The path to the Packer template
-	Vars                       map[string]string // The custom vars to pass when running the build command
-	VarFiles                   []string          // Var file paths to pass Packer using -var-file option
-	Only                       string            // If specified, only run the build of this name
-	Except                     string            // Runs the build excluding the specified builds and post-processors
-	Env                        map[string]string // Custom environment variables to set when running Packer
-	RetryableErrors            map[string]string // If packer build fails with one of these (transient) errors, retry. The keys are a regexp to match against the error and the message is what to display to a user if that error is matched.
-	MaxRetries                 int               // Maximum number of times to retry errors matching RetryableErrors
-	TimeBetweenRetries         time.Duration     // The amount of time to wait between retries
-	WorkingDir                 string            // The directory to run packer in
-	Logger                     *logger.Logger    // If set, use a non-default logger
-	DisableTemporaryPluginPath bool              // If set, do not use a temporary directory for Packer plugins.
+	Template                   string                 // The path to the Packer template
+	Vars                       map[string]string      // The custom vars to pass when running the build command
+	ComplexVars                map[string]interface{} // Custom vars of any type (e.g. lists, maps) to pass when running the build command, formatted as HCL2 values
+	VarFiles                   []string               // Var file paths to pass Packer using -var-file option
+	Only                       string                 // If specified, only run the build of this name
+	Except                     string                 // Runs the build excluding the specified builds and post-processors
+	OnlyBuilds                 []string               // If specified, only run the builds of these names, along with Only
+	ExceptBuilds               []string               // Runs the build excluding these builds and post-processors, along with Except
+	OnError                    string                 // What to do when a build fails: cleanup (the default), abort, ask or run-cleanup-provisioner
+	Env                        map[string]string      // Custom environment variables to set when running Packer
+	RetryableErrors            map[string]string      // If packer build fails with one of these (transient) errors, retry. The keys are a regexp to match against the error and the message is what to display to a user if that error is matched.
+	MaxRetries                 int                    // Maximum number of times to retry errors matching RetryableErrors
+	TimeBetweenRetries         time.Duration          // The amount of time to wait between retries
+	WorkingDir                 string                 // The directory to run packer in
+	Logger                     *logger.Logger         // If set, use a non-default logger
+	DisableTemporaryPluginPath bool                   // If set, do not use a temporary directory for Packer plugins.
+	Plugins                    map[string]string      // Plugins to install before the build with 'packer plugins install', by source (e.g. github.com/hashicorp/amazon) to pinned version (e.g. 1.2.8).
+	UpgradePlugins             bool                   // If set, pass -upgrade to 'packer init' to install the latest plugin versions allowed by the required_plugins constraints.
+	ManifestPath               string                 // The output path of the manifest post-processor of the template, read by BuildAndGetArtifacts. Defaults to packer-manifest.json in the WorkingDir, the default of the post-processor.
+	KillDelay                  time.Duration          // How long an interrupted build, e.g. cancelled by BuildAll, has to clean up the resources it created before Packer is killed. Defaults to 15 minutes.
 }
 
+// defaultKillDelay is how long an interrupted build has to clean up before Packer is killed, unless set with
+// Options.KillDelay. Deleting instances and snapshots can take several minutes.
+const defaultKillDelay = 15 * time.Minute
+
 // BuildArtifacts can take a map of identifierName <-> Options and then parallelize
 // the packer builds. Once all the packer builds have completed a map of identifierName <-> generated identifier
 // is returned. The identifierName can be anything you want, it is only used so that you can
@@ -98,6 +112,18 @@ func BuildArtifact(t testing.TestingT, options *Options) string {
 func BuildArtifactE(t testing.TestingT, options *Options) (string, error) {
 	options.Logger.Logf(t, "Running Packer to generate a custom artifact for template %s", options.Template)
 
+	output, err := runPackerBuildE(t, context.Background(), options)
+	if err != nil {
+		return "", err
+	}
+
+	return extractArtifactID(output)
+}
+
+// runPackerBuildE runs 'packer build' on the template of the options, after 'packer init' if needed, and returns the
+// machine-readable output. The build is interrupted when the context is done, and the returned error then matches
+// context.Canceled or context.DeadlineExceeded with errors.Is.
+func runPackerBuildE(t testing.TestingT, ctx context.Context, options *Options) (string, error) {
 	// By default, we download packer plugins to a temporary directory rather than use the global plugin path.
 	// This prevents race conditions when multiple tests are running in parallel and each of them attempt
 	// to download the same plugin at the same time to the global path.
@@ -115,28 +141,39 @@ func BuildArtifactE(t testing.TestingT, options *Options) (string, error) {
 		defer os.RemoveAll(pluginDir)
 	}
 
-	err := packerInit(t, options)
+	err := initIfRequiredE(t, options)
 	if err != nil {
 		return "", err
 	}
 
+	killDelay := options.KillDelay
+	if killDelay == 0 {
+		killDelay = defaultKillDelay
+	}
 	cmd := shell.Command{
 		Command:    "packer",
 		Args:       formatPackerArgs(options),
 		Env:        options.Env,
 		WorkingDir: options.WorkingDir,
+		Context:    ctx,
+		KillDelay:  killDelay,
 	}
 
 	description := fmt.Sprintf("%s %v", cmd.Command, cmd.Args)
 	output, err := retry.DoWithRetryableErrorsE(t, description, options.RetryableErrors, options.MaxRetries, options.TimeBetweenRetries, func() (string, error) {
+		// Don't retry interrupted builds
+		if ctx.Err() != nil {
+			return "", retry.FatalError{Underlying: ctx.Err()}
+		}
 		return shell.RunCommandAndGetOutputE(t, cmd)
 	})
 
-	if err != nil {
-		return "", err
+	// FatalError doesn't unwrap, so return the error of the interrupted build itself, which matches the context error
+	var fatalErr retry.FatalError
+	if err != nil && ctx.Err() != nil && errors.As(err, &fatalErr) {
+		return output, fatalErr.Underlying
 	}
-
-	return extractArtifactID(output)
+	return output, err
 }
 
 // BuildAmi builds the given Packer template and return the generated AMI ID.
@@ -204,42 +241,6 @@ func hasPackerInit(t testing.TestingT, options *Options) (bool, error) {
 	return true, nil
 }
 
-// packerInit runs 'packer init' if it is supported by the local packer
-func packerInit(t testing.TestingT, options *Options) error {
-	hasInit, err := hasPackerInit(t, options)
-	if err != nil {
-		return err
-	}
-	if !hasInit {
-		options.Logger.Logf(t, "Skipping 'packer init' because it is not present in this version")
-		return nil
-	}
-
-	extension := filepath.Ext(options.Template)
-	if extension != ".hcl" {
-		options.Logger.Logf(t, "Skipping 'packer init' because it is only supported for HCL2 templates")
-		return nil
-	}
-
-	cmd := shell.Command{
-		Command:    "packer",
-		Args:       []string{"init", options.Template},
-		Env:        options.Env,
-		WorkingDir: options.WorkingDir,
-	}
-
-	description := "Running Packer init"
-	_, err = retry.DoWithRetryableErrorsE(t, description, options.RetryableErrors, options.MaxRetries, options.TimeBetweenRetries, func() (string, error) {
-		return shell.RunCommandAndGetOutputE(t, cmd)
-	})
-
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
 // Convert the inputs to a format palatable to packer. The build command should have the format:
 //
 // packer build [OPTIONS] template
@@ -250,21 +251,57 @@ func formatPackerArgs(options *Options) []string {
 		args = append(args, "-var", fmt.Sprintf("%s=%s", key, value))
 	}
 
+	complexVarNames := make([]string, 0, len(options.ComplexVars))
+	for key := range options.ComplexVars {
+		complexVarNames = append(complexVarNames, key)
+	}
+	sort.Strings(complexVarNames)
+	for _, key := range complexVarNames {
+		args = append(args, "-var", fmt.Sprintf("%s=%s", key, formatHclValue(options.ComplexVars[key])))
+	}
+
 	for _, filePath := range options.VarFiles {
 		args = append(args, "-var-file", filePath)
 	}
 
-	if options.Only != "" {
-		args = append(args, fmt.Sprintf("-only=%s", options.Only))
+	if only := joinFilters(options.Only, options.OnlyBuilds); only != "" {
+		args = append(args, fmt.Sprintf("-only=%s", only))
+	}
+
+	if except := joinFilters(options.Except, options.ExceptBuilds); except != "" {
+		args = append(args, fmt.Sprintf("-except=%s", except))
 	}
 
-	if options.Except != "" {
-		args = append(args, fmt.Sprintf("-except=%s", options.Except))
+	if options.OnError != "" {
+		args = append(args, fmt.Sprintf("-on-error=%s", options.OnError))
 	}
 
 	return append(args, options.Template)
 }
 
+// formatHclValue formats the value of a var for the command line. Strings are passed as-is, as packer does for string
+// vars, and the other values as JSON, which is valid HCL2 for numbers, bools, lists and maps.
+func formatHclValue(value interface{}) string {
+	if str, ok := value.(string); ok {
+		return str
+	}
+	formatted, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(formatted)
+}
+
+// joinFilters joins the build filters of the options into the comma separated list expected by -only and -except.
+func joinFilters(filter string, builds []string) string {
+	filters := []string{}
+	if filter != "" {
+		filters = append(filters, filter)
+	}
+	filters = append(filters, builds...)
+	return strings.Join(filters, ",")
+}
+
 // From packer 1.10 the -version command output is prefixed with Packer v
 func trimPackerVersion(versionCmdOutput string) string {
 	re := regexp.MustCompile(`(?:Packer v?|)(\d+\.\d+\.\d+)`)
@@ -170,6 +170,25 @@ func TestFormatPackerArgs(t *testing.T) {
 			},
 			expected: "build -machine-readable -var foo=bar -var-file foofile.json packer.json",
 		},
+		{
+			option: &Options{
+				Template: "build.pkr.hcl",
+				ComplexVars: map[string]interface{}{
+					"regions":    []string{"us-east-1", "eu-west-1"},
+					"tags":       map[string]string{"Name": "terratest"},
+					"encrypt":    true,
+					"size":       8,
+					"ami_prefix": "terratest",
+				},
+				VarFiles:     []string{"common.pkrvars.hcl", "prod.pkrvars.hcl"},
+				Only:         "amazon-ebs.ubuntu",
+				OnlyBuilds:   []string{"amazon-ebs.debian"},
+				ExceptBuilds: []string{"manifest"},
+				OnError:      "abort",
+			},
+			expected: `build -machine-readable -var ami_prefix=terratest -var encrypt=true -var regions=["us-east-1","eu-west-1"] -var size=8 -var tags={"Name":"terratest"} ` +
+				"-var-file common.pkrvars.hcl -var-file prod.pkrvars.hcl -only=amazon-ebs.ubuntu,amazon-ebs.debian -except=manifest -on-error=abort build.pkr.hcl",
+		},
 	}
 
 	for _, test := range tests {
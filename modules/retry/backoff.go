@@ -0,0 +1,117 @@
+package retry
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// defaultBackoffMultiplier is the multiplier of an ExponentialBackoff that doesn't set one.
+const defaultBackoffMultiplier = 2
+
+// ExponentialBackoff configures the sleep between retries to grow exponentially, instead of being fixed, so that many
+// tests retrying against a rate-limited API don't all retry at the same time.
+type ExponentialBackoff struct {
+	InitialDelay time.Duration // the sleep before the first retry
+	Multiplier   float64       // the sleep is multiplied by this after each retry, 2 if not set
+	MaxDelay     time.Duration // the maximum sleep before a retry, if set
+	Jitter       float64       // the fraction, between 0 and 1, of each sleep that is randomized, e.g. 0.2 for +/- 20%
+}
+
+// Delay returns the sleep before the given retry, starting at 0.
+func (backoff ExponentialBackoff) Delay(retry int) time.Duration {
+	multiplier := backoff.Multiplier
+	if multiplier <= 0 {
+		multiplier = defaultBackoffMultiplier
+	}
+
+	delay := float64(backoff.InitialDelay) * math.Pow(multiplier, float64(retry))
+	if backoff.MaxDelay > 0 && delay > float64(backoff.MaxDelay) {
+		delay = float64(backoff.MaxDelay)
+	}
+
+	jitter := math.Min(math.Max(backoff.Jitter, 0), 1)
+	delay *= 1 + jitter*(2*rand.Float64()-1)
+
+	if delay >= math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(delay)
+}
+
+// DoWithExponentialBackoff runs the specified action. If it returns a string, return that string. If it returns a
+// FatalError, return that error immediately. If it returns any other type of error, sleep for the delay of the given
+// backoff and try again, up to a maximum of maxRetries retries. If maxRetries is exceeded, fail the test.
+func DoWithExponentialBackoff(t testing.TestingT, actionDescription string, maxRetries int, backoff ExponentialBackoff, action func() (string, error)) string {
+	out, err := DoWithExponentialBackoffE(t, actionDescription, maxRetries, backoff, action)
+	require.NoError(t, err)
+	return out
+}
+
+// DoWithExponentialBackoffE runs the specified action. If it returns a string, return that string. If it returns a
+// FatalError, return that error immediately. If it returns any other type of error, sleep for the delay of the given
+// backoff and try again, up to a maximum of maxRetries retries. If maxRetries is exceeded, return a MaxRetriesExceeded
+// error.
+func DoWithExponentialBackoffE(t testing.TestingT, actionDescription string, maxRetries int, backoff ExponentialBackoff, action func() (string, error)) (string, error) {
+	out, err := doWithRetryInterfaceE(t, context.Background(), actionDescription, maxRetries, backoff.Delay, func(context.Context) (interface{}, error) { return action() })
+	return out.(string), err
+}
+
+// DoWithExponentialBackoffContext runs the specified action with the given context like DoWithExponentialBackoff, but
+// stops retrying as soon as the context is cancelled, its deadline is exceeded or its retry budget is exhausted.
+func DoWithExponentialBackoffContext(t testing.TestingT, ctx context.Context, actionDescription string, maxRetries int, backoff ExponentialBackoff, action func(ctx context.Context) (string, error)) string {
+	out, err := DoWithExponentialBackoffContextE(t, ctx, actionDescription, maxRetries, backoff, action)
+	require.NoError(t, err)
+	return out
+}
+
+// DoWithExponentialBackoffContextE runs the specified action with the given context like DoWithExponentialBackoffE,
+// but stops retrying as soon as the context is cancelled, its deadline is exceeded or its retry budget is exhausted.
+func DoWithExponentialBackoffContextE(t testing.TestingT, ctx context.Context, actionDescription string, maxRetries int, backoff ExponentialBackoff, action func(ctx context.Context) (string, error)) (string, error) {
+	out, err := doWithRetryInterfaceE(t, ctx, actionDescription, maxRetries, backoff.Delay, func(ctx context.Context) (interface{}, error) { return action(ctx) })
+	output, _ := out.(string)
+	return output, err
+}
+
+// DoWithRetryableErrorsAndBackoff runs the specified action like DoWithRetryableErrors, but sleeps for the delay of
+// the given backoff between retries.
+func DoWithRetryableErrorsAndBackoff(t testing.TestingT, actionDescription string, retryableErrors map[string]string, maxRetries int, backoff ExponentialBackoff, action func() (string, error)) string {
+	out, err := DoWithRetryableErrorsAndBackoffE(t, actionDescription, retryableErrors, maxRetries, backoff, action)
+	require.NoError(t, err)
+	return out
+}
+
+// DoWithRetryableErrorsAndBackoffE runs the specified action like DoWithRetryableErrorsE, but sleeps for the delay of
+// the given backoff between retries.
+func DoWithRetryableErrorsAndBackoffE(t testing.TestingT, actionDescription string, retryableErrors map[string]string, maxRetries int, backoff ExponentialBackoff, action func() (string, error)) (string, error) {
+	retryableAction, err := retryableErrorsAction(t, actionDescription, retryableErrors, action)
+	if err != nil {
+		return "", err
+	}
+	return DoWithExponentialBackoffE(t, actionDescription, maxRetries, backoff, retryableAction)
+}
+
+// DoWithRetryableErrorsAndBackoffContext runs the specified action with the given context like
+// DoWithRetryableErrorsAndBackoff, but stops retrying as soon as the context is cancelled, its deadline is exceeded or
+// its retry budget is exhausted.
+func DoWithRetryableErrorsAndBackoffContext(t testing.TestingT, ctx context.Context, actionDescription string, retryableErrors map[string]string, maxRetries int, backoff ExponentialBackoff, action func(ctx context.Context) (string, error)) string {
+	out, err := DoWithRetryableErrorsAndBackoffContextE(t, ctx, actionDescription, retryableErrors, maxRetries, backoff, action)
+	require.NoError(t, err)
+	return out
+}
+
+// DoWithRetryableErrorsAndBackoffContextE runs the specified action with the given context like
+// DoWithRetryableErrorsAndBackoffE, but stops retrying as soon as the context is cancelled, its deadline is exceeded
+// or its retry budget is exhausted.
+func DoWithRetryableErrorsAndBackoffContextE(t testing.TestingT, ctx context.Context, actionDescription string, retryableErrors map[string]string, maxRetries int, backoff ExponentialBackoff, action func(ctx context.Context) (string, error)) (string, error) {
+	retryableAction, err := retryableErrorsAction(t, actionDescription, retryableErrors, func() (string, error) { return action(ctx) })
+	if err != nil {
+		return "", err
+	}
+	return DoWithExponentialBackoffContextE(t, ctx, actionDescription, maxRetries, backoff, func(context.Context) (string, error) { return retryableAction() })
+}
@@ -0,0 +1,96 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExponentialBackoffDelay(t *testing.T) {
+	t.Parallel()
+
+	backoff := ExponentialBackoff{InitialDelay: time.Second, Multiplier: 3, MaxDelay: 20 * time.Second}
+	assert.Equal(t, time.Second, backoff.Delay(0))
+	assert.Equal(t, 3*time.Second, backoff.Delay(1))
+	assert.Equal(t, 9*time.Second, backoff.Delay(2))
+	assert.Equal(t, 20*time.Second, backoff.Delay(3))
+	assert.Equal(t, 20*time.Second, backoff.Delay(1000))
+
+	assert.Equal(t, 4*time.Second, ExponentialBackoff{InitialDelay: time.Second}.Delay(2))
+}
+
+func TestExponentialBackoffDelayJitter(t *testing.T) {
+	t.Parallel()
+
+	backoff := ExponentialBackoff{InitialDelay: 10 * time.Second, Jitter: 0.2}
+	for i := 0; i < 100; i++ {
+		delay := backoff.Delay(0)
+		assert.GreaterOrEqual(t, delay, 8*time.Second)
+		assert.LessOrEqual(t, delay, 12*time.Second)
+	}
+}
+
+func TestDoWithExponentialBackoff(t *testing.T) {
+	t.Parallel()
+
+	count := 0
+	out, err := DoWithExponentialBackoffE(t, "Return value after 3 retries", 5, ExponentialBackoff{InitialDelay: time.Millisecond}, func() (string, error) {
+		count++
+		if count > 3 {
+			return "expected", nil
+		}
+		return "", fmt.Errorf("expected error")
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "expected", out)
+	assert.Equal(t, 4, count)
+
+	_, err = DoWithExponentialBackoffE(t, "Return error on all retries", 2, ExponentialBackoff{InitialDelay: time.Millisecond}, func() (string, error) {
+		return "", fmt.Errorf("expected error")
+	})
+	assert.Equal(t, MaxRetriesExceeded{Description: "Return error on all retries", MaxRetries: 2}, err)
+}
+
+func TestDoWithRetryableErrorsAndBackoff(t *testing.T) {
+	t.Parallel()
+
+	count := 0
+	_, err := DoWithRetryableErrorsAndBackoffE(t, "Return a non-retryable error", map[string]string{"rate exceeded": "throttled"}, 5, ExponentialBackoff{InitialDelay: time.Millisecond}, func() (string, error) {
+		count++
+		if count == 1 {
+			return "", fmt.Errorf("rate exceeded")
+		}
+		return "", fmt.Errorf("access denied")
+	})
+	assert.IsType(t, FatalError{}, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestDoWithRetryableErrorsAndBackoffContext(t *testing.T) {
+	t.Parallel()
+
+	recorder := &AttemptRecorder{}
+	ctx := WithCollector(context.Background(), recorder)
+	count := 0
+	out, err := DoWithRetryableErrorsAndBackoffContextE(t, ctx, "Return a retryable error once", map[string]string{"rate exceeded": "throttled"}, 5, ExponentialBackoff{InitialDelay: time.Millisecond}, func(context.Context) (string, error) {
+		count++
+		if count == 1 {
+			return "", fmt.Errorf("rate exceeded")
+		}
+		return "done", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "done", out)
+	recorder.AssertSucceededWithin(t, "Return a retryable error once", 2)
+
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = DoWithRetryableErrorsAndBackoffContextE(t, cancelledCtx, "Never run", nil, 5, ExponentialBackoff{InitialDelay: time.Millisecond}, func(context.Context) (string, error) {
+		return "", nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,62 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// retryBudgetKey is the key of the retry budget in a context.
+type retryBudgetKey struct{}
+
+// retryBudget is the total time that the retries of a context may take.
+type retryBudget struct {
+	total    time.Duration
+	deadline time.Time
+}
+
+// WithRetryBudget returns a copy of the given context with a retry budget: a maximum total time shared by all the
+// context-aware retry functions it's passed to, including nested ones, so that nested retries can't multiply the
+// runtime of a test. Once the budget is exhausted, the functions stop retrying with a RetryBudgetExhausted error, and
+// don't sleep before a retry that would exceed it. A budget nested in another one can shorten it but not extend it.
+// Call the returned cancel function to release the resources of the context once done.
+//
+// Only the *Context functions of this package, and the helpers given the context, such as the terraform and terragrunt
+// commands through the Context of their options, use the budget. The other functions, and the helpers that retry
+// without a context, retry with context.Background() and are not bounded by it.
+func WithRetryBudget(ctx context.Context, budget time.Duration) (context.Context, context.CancelFunc) {
+	deadline := time.Now().Add(budget)
+	if existing, hasBudget := ctx.Value(retryBudgetKey{}).(*retryBudget); hasBudget && existing.deadline.Before(deadline) {
+		return context.WithCancel(ctx)
+	}
+	ctx = context.WithValue(ctx, retryBudgetKey{}, &retryBudget{total: budget, deadline: deadline})
+	return context.WithDeadline(ctx, deadline)
+}
+
+// RetryBudgetRemaining returns the time left in the retry budget of the given context, and whether it has one.
+func RetryBudgetRemaining(ctx context.Context) (time.Duration, bool) {
+	budget, hasBudget := ctx.Value(retryBudgetKey{}).(*retryBudget)
+	if !hasBudget {
+		return 0, false
+	}
+	if remaining := time.Until(budget.deadline); remaining > 0 {
+		return remaining, true
+	}
+	return 0, true
+}
+
+// contextDoneError returns the error of a retried action whose context is done: a RetryBudgetExhausted error if its
+// retry budget ran out, or else a ContextDone error.
+func contextDoneError(ctx context.Context, actionDescription string) error {
+	if budget, hasBudget := ctx.Value(retryBudgetKey{}).(*retryBudget); hasBudget && errors.Is(ctx.Err(), context.DeadlineExceeded) && !time.Now().Before(budget.deadline) {
+		return RetryBudgetExhausted{Description: actionDescription, Budget: budget.total, Underlying: ctx.Err()}
+	}
+	return ContextDone{Description: actionDescription, Underlying: ctx.Err()}
+}
+
+// budgetExhaustedError returns the RetryBudgetExhausted error of a retried action that failed with the given error
+// and has no time left in the retry budget of its context to retry.
+func budgetExhaustedError(ctx context.Context, actionDescription string, err error) error {
+	budget, _ := ctx.Value(retryBudgetKey{}).(*retryBudget)
+	return RetryBudgetExhausted{Description: actionDescription, Budget: budget.total, Underlying: err}
+}
@@ -0,0 +1,64 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRetryBudgetNested(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := WithRetryBudget(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := DoWithRetryContextE(t, ctx, "Outer retry", 10, time.Millisecond, func(ctx context.Context) (string, error) {
+		return DoWithRetryableErrorsContextE(t, ctx, "Inner retry", map[string]string{"expected": ""}, 10, 10*time.Millisecond, func(ctx context.Context) (string, error) {
+			return "", fmt.Errorf("expected error")
+		})
+	})
+	assert.Less(t, time.Since(start), 10*time.Second)
+
+	var budgetErr RetryBudgetExhausted
+	assert.True(t, errors.As(err, &budgetErr))
+	assert.Equal(t, 300*time.Millisecond, budgetErr.Budget)
+}
+
+func TestWithRetryBudgetSkipsSleepBeyondBudget(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := WithRetryBudget(context.Background(), time.Minute)
+	defer cancel()
+
+	count := 0
+	start := time.Now()
+	_, err := DoWithExponentialBackoffContextE(t, ctx, "Return error", 10, ExponentialBackoff{InitialDelay: 10 * time.Millisecond, Multiplier: 100}, func(ctx context.Context) (string, error) {
+		count++
+		return "", fmt.Errorf("expected error")
+	})
+	assert.Less(t, time.Since(start), 10*time.Second)
+	assert.Equal(t, 3, count)
+	assert.IsType(t, RetryBudgetExhausted{}, err)
+	assert.EqualError(t, errors.Unwrap(err), "expected error")
+}
+
+func TestWithRetryBudgetCannotExtendOuterBudget(t *testing.T) {
+	t.Parallel()
+
+	outer, cancelOuter := WithRetryBudget(context.Background(), time.Second)
+	defer cancelOuter()
+	inner, cancelInner := WithRetryBudget(outer, time.Hour)
+	defer cancelInner()
+
+	remaining, hasBudget := RetryBudgetRemaining(inner)
+	assert.True(t, hasBudget)
+	assert.LessOrEqual(t, remaining, time.Second)
+
+	_, hasBudget = RetryBudgetRemaining(context.Background())
+	assert.False(t, hasBudget)
+}
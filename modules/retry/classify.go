@@ -0,0 +1,78 @@
+package retry
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// RetryableErrorFunc reports whether the given error of an action, returned along with the given output, warrants a
+// retry. Unlike the regular expressions of DoWithRetryableErrors, it can classify errors by type with errors.Is and
+// errors.As, which doesn't break when the messages of the errors change.
+type RetryableErrorFunc func(output string, err error) bool
+
+// RetryableErrorIs returns a RetryableErrorFunc that retries the errors that match any of the given targets with
+// errors.Is.
+func RetryableErrorIs(targets ...error) RetryableErrorFunc {
+	return func(output string, err error) bool {
+		for _, target := range targets {
+			if errors.Is(err, target) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// RetryableErrorAs returns a RetryableErrorFunc that retries the errors that have an error of type T in their chain,
+// as found by errors.As.
+func RetryableErrorAs[T error]() RetryableErrorFunc {
+	return func(output string, err error) bool {
+		var target T
+		return errors.As(err, &target)
+	}
+}
+
+// IsTimeoutError is a RetryableErrorFunc that retries the network errors that are timeouts, such as a dial or read
+// timeout.
+func IsTimeoutError(output string, err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// DoWithRetryableErrorFuncs runs the specified action. If it returns a value, return that value. If it returns an
+// error that any of the given retryableErrors functions reports as retryable, sleep for sleepBetweenRetries and retry
+// the specified action, up to a maximum of maxRetries retries. If the error isn't retryable, fail the test immediately.
+// If maxRetries is exceeded, fail the test.
+func DoWithRetryableErrorFuncs(t testing.TestingT, actionDescription string, retryableErrors []RetryableErrorFunc, maxRetries int, sleepBetweenRetries time.Duration, action func() (string, error)) string {
+	out, err := DoWithRetryableErrorFuncsE(t, actionDescription, retryableErrors, maxRetries, sleepBetweenRetries, action)
+	require.NoError(t, err)
+	return out
+}
+
+// DoWithRetryableErrorFuncsE runs the specified action. If it returns a value, return that value. If it returns an
+// error that any of the given retryableErrors functions reports as retryable, sleep for sleepBetweenRetries and retry
+// the specified action, up to a maximum of maxRetries retries. If the error isn't retryable, return that error
+// immediately, wrapped in a FatalError. If maxRetries is exceeded, return a MaxRetriesExceeded error.
+func DoWithRetryableErrorFuncsE(t testing.TestingT, actionDescription string, retryableErrors []RetryableErrorFunc, maxRetries int, sleepBetweenRetries time.Duration, action func() (string, error)) (string, error) {
+	return DoWithRetryE(t, actionDescription, maxRetries, sleepBetweenRetries, func() (string, error) {
+		output, err := action()
+		if err == nil {
+			return output, nil
+		}
+
+		for _, isRetryable := range retryableErrors {
+			if isRetryable(output, err) {
+				logger.Default.Logf(t, "'%s' failed with the error '%s' but this error warrants a retry.", actionDescription, err.Error())
+				return output, err
+			}
+		}
+
+		return output, FatalError{Underlying: err}
+	})
+}
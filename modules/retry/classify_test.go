@@ -0,0 +1,49 @@
+package retry
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryableErrorFuncs(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, RetryableErrorIs(io.ErrUnexpectedEOF)("", fmt.Errorf("reading body: %w", io.ErrUnexpectedEOF)))
+	assert.False(t, RetryableErrorIs(io.ErrUnexpectedEOF)("", io.EOF))
+
+	assert.True(t, RetryableErrorAs[*net.OpError]()("", fmt.Errorf("dialing: %w", &net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded})))
+	assert.False(t, RetryableErrorAs[*net.OpError]()("", io.EOF))
+
+	assert.True(t, IsTimeoutError("", &net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded}))
+	assert.False(t, IsTimeoutError("", &net.OpError{Op: "dial", Err: io.EOF}))
+}
+
+func TestDoWithRetryableErrorFuncs(t *testing.T) {
+	t.Parallel()
+
+	count := 0
+	_, err := DoWithRetryableErrorFuncsE(t, "Return a non-retryable error", []RetryableErrorFunc{RetryableErrorIs(io.ErrUnexpectedEOF)}, 5, time.Millisecond, func() (string, error) {
+		count++
+		if count < 3 {
+			return "", fmt.Errorf("reading body: %w", io.ErrUnexpectedEOF)
+		}
+		return "", io.EOF
+	})
+	assert.IsType(t, FatalError{}, err)
+	assert.Equal(t, 3, count)
+
+	out := DoWithRetryableErrorFuncs(t, "Return value after a retry", []RetryableErrorFunc{IsTimeoutError}, 5, time.Millisecond, func() (string, error) {
+		count++
+		if count < 5 {
+			return "", &net.OpError{Op: "read", Err: os.ErrDeadlineExceeded}
+		}
+		return "expected", nil
+	})
+	assert.Equal(t, "expected", out)
+}
@@ -0,0 +1,128 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// collectorsKey is the key of the attempt collectors in a context.
+type collectorsKey struct{}
+
+// Attempt is an attempt of a retried action, passed to the collectors of its context.
+type Attempt struct {
+	Description string        // the description of the action
+	Number      int           // the number of the attempt, starting at 1
+	Err         error         // the error of the attempt, or nil if it succeeded
+	Elapsed     time.Duration // the time since the start of the first attempt
+	WillRetry   bool          // whether the action will be retried after this attempt
+}
+
+// Collector collects the attempts of the context-aware retry functions, e.g. to log structured retry telemetry or to
+// alert on excessive retries. Collect may be called concurrently by retries running in parallel.
+type Collector interface {
+	Collect(attempt Attempt)
+}
+
+// CollectorFunc is a function that is a Collector.
+type CollectorFunc func(attempt Attempt)
+
+// Collect calls the function with the attempt.
+func (collect CollectorFunc) Collect(attempt Attempt) {
+	collect(attempt)
+}
+
+// WithCollector returns a copy of the given context with the given collector, which gets every attempt of the
+// context-aware retry functions the context is passed to, along with the collectors the context already has. Like the
+// retry budget of WithRetryBudget, the collector doesn't get the attempts of the functions that retry without the
+// context: pass it to the terraform and terragrunt commands through the Context of their options.
+func WithCollector(ctx context.Context, collector Collector) context.Context {
+	existing, _ := ctx.Value(collectorsKey{}).([]Collector)
+	collectors := append(append([]Collector{}, existing...), collector)
+	return context.WithValue(ctx, collectorsKey{}, collectors)
+}
+
+// WithOnRetry returns a copy of the given context with the given callback, which is called with every failed attempt
+// of the context-aware retry functions the context is passed to, before the action is retried.
+func WithOnRetry(ctx context.Context, onRetry func(attempt Attempt)) context.Context {
+	return WithCollector(ctx, CollectorFunc(func(attempt Attempt) {
+		if attempt.WillRetry {
+			onRetry(attempt)
+		}
+	}))
+}
+
+// collectAttempt passes the given attempt to the collectors of the given context, and adds it to its span.
+func collectAttempt(ctx context.Context, attempt Attempt) {
+	eventAttributes := []attribute.KeyValue{attribute.Int("retry.attempt", attempt.Number), attribute.Bool("retry.will_retry", attempt.WillRetry)}
+	if attempt.Err != nil {
+		eventAttributes = append(eventAttributes, attribute.String("error", attempt.Err.Error()))
+	}
+	span := trace.SpanFromContext(ctx)
+	span.AddEvent("attempt", trace.WithAttributes(eventAttributes...))
+	span.SetAttributes(attribute.Int("retry.attempts", attempt.Number))
+
+	collectors, _ := ctx.Value(collectorsKey{}).([]Collector)
+	for _, collector := range collectors {
+		collector.Collect(attempt)
+	}
+}
+
+// AttemptRecorder is a Collector that records attempts, to assert how many attempts an action took. The zero value is
+// ready to use.
+type AttemptRecorder struct {
+	mutex    sync.Mutex
+	attempts []Attempt
+}
+
+// Collect records the attempt.
+func (recorder *AttemptRecorder) Collect(attempt Attempt) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	recorder.attempts = append(recorder.attempts, attempt)
+}
+
+// Attempts returns the recorded attempts of the action with the given description, or of all actions if it's empty.
+func (recorder *AttemptRecorder) Attempts(actionDescription string) []Attempt {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	attempts := []Attempt{}
+	for _, attempt := range recorder.attempts {
+		if actionDescription == "" || attempt.Description == actionDescription {
+			attempts = append(attempts, attempt)
+		}
+	}
+	return attempts
+}
+
+// AssertSucceededWithin asserts that the last recorded attempt of the action with the given description succeeded,
+// after at most maxAttempts attempts. If not, fail the test.
+func (recorder *AttemptRecorder) AssertSucceededWithin(t testing.TestingT, actionDescription string, maxAttempts int) {
+	require.NoError(t, recorder.SucceededWithinE(actionDescription, maxAttempts))
+}
+
+// SucceededWithinE returns an error unless the last recorded attempt of the action with the given description
+// succeeded, after at most maxAttempts attempts.
+func (recorder *AttemptRecorder) SucceededWithinE(actionDescription string, maxAttempts int) error {
+	attempts := recorder.Attempts(actionDescription)
+	if len(attempts) == 0 {
+		return fmt.Errorf("no attempts of '%s' were recorded", actionDescription)
+	}
+
+	last := attempts[len(attempts)-1]
+	if last.Err != nil {
+		return fmt.Errorf("'%s' did not succeed after %d attempts: %v", actionDescription, last.Number, last.Err)
+	}
+	if last.Number > maxAttempts {
+		return fmt.Errorf("'%s' succeeded after %d attempts, expected at most %d", actionDescription, last.Number, maxAttempts)
+	}
+	return nil
+}
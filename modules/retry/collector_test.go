@@ -0,0 +1,60 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithCollector(t *testing.T) {
+	t.Parallel()
+
+	recorder := &AttemptRecorder{}
+	retries := []Attempt{}
+	ctx := WithOnRetry(WithCollector(context.Background(), recorder), func(attempt Attempt) {
+		retries = append(retries, attempt)
+	})
+
+	count := 0
+	_, err := DoWithRetryContextE(t, ctx, "Return value after 2 retries", 5, time.Millisecond, func(ctx context.Context) (string, error) {
+		count++
+		if count > 2 {
+			return "expected", nil
+		}
+		return "", fmt.Errorf("expected error")
+	})
+	assert.NoError(t, err)
+
+	attempts := recorder.Attempts("Return value after 2 retries")
+	assert.Len(t, attempts, 3)
+	assert.Equal(t, 3, attempts[2].Number)
+	assert.NoError(t, attempts[2].Err)
+	assert.False(t, attempts[2].WillRetry)
+	assert.GreaterOrEqual(t, attempts[2].Elapsed, 2*time.Millisecond)
+
+	assert.Len(t, retries, 2)
+	assert.EqualError(t, retries[0].Err, "expected error")
+	assert.True(t, retries[0].WillRetry)
+
+	recorder.AssertSucceededWithin(t, "Return value after 2 retries", 3)
+	assert.Error(t, recorder.SucceededWithinE("Return value after 2 retries", 2))
+	assert.Error(t, recorder.SucceededWithinE("Unknown", 2))
+}
+
+func TestAttemptRecorderFailed(t *testing.T) {
+	t.Parallel()
+
+	recorder := &AttemptRecorder{}
+	_, err := DoWithRetryContextE(t, WithCollector(context.Background(), recorder), "Return error on all retries", 2, time.Millisecond, func(ctx context.Context) (string, error) {
+		return "", fmt.Errorf("expected error")
+	})
+	assert.Error(t, err)
+
+	attempts := recorder.Attempts("")
+	assert.Len(t, attempts, 3)
+	assert.False(t, attempts[2].WillRetry)
+	assert.Error(t, recorder.SucceededWithinE("Return error on all retries", 5))
+}
@@ -10,6 +10,9 @@ import (
 
 	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/gruntwork-io/terratest/modules/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/net/context"
 )
 
@@ -84,24 +87,103 @@ func DoWithRetryInterface(t testing.TestingT, actionDescription string, maxRetri
 // immediately. If it returns any other type of error, sleep for sleepBetweenRetries and try again, up to a maximum of
 // maxRetries retries. If maxRetries is exceeded, return a MaxRetriesExceeded error.
 func DoWithRetryInterfaceE(t testing.TestingT, actionDescription string, maxRetries int, sleepBetweenRetries time.Duration, action func() (interface{}, error)) (interface{}, error) {
+	return doWithRetryInterfaceE(t, context.Background(), actionDescription, maxRetries, func(int) time.Duration { return sleepBetweenRetries }, func(context.Context) (interface{}, error) { return action() })
+}
+
+// DoWithRetryContext runs the specified action with the given context like DoWithRetry, but stops retrying as soon as
+// the context is cancelled or its deadline is exceeded. If there are any errors, fail the test.
+func DoWithRetryContext(t testing.TestingT, ctx context.Context, actionDescription string, maxRetries int, sleepBetweenRetries time.Duration, action func(ctx context.Context) (string, error)) string {
+	out, err := DoWithRetryContextE(t, ctx, actionDescription, maxRetries, sleepBetweenRetries, action)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// DoWithRetryContextE runs the specified action with the given context. If it returns a string, return that string. If
+// it returns a FatalError, return that error immediately. If it returns any other type of error, sleep for
+// sleepBetweenRetries and try again, up to a maximum of maxRetries retries. If maxRetries is exceeded, return a
+// MaxRetriesExceeded error. If the context is cancelled or its deadline is exceeded before the action succeeds, including
+// while sleeping, return a ContextDone error immediately. If the retry budget of the context (see WithRetryBudget) is
+// exhausted, or has less time left than the sleep before the next retry, return a RetryBudgetExhausted error.
+func DoWithRetryContextE(t testing.TestingT, ctx context.Context, actionDescription string, maxRetries int, sleepBetweenRetries time.Duration, action func(ctx context.Context) (string, error)) (string, error) {
+	out, err := DoWithRetryInterfaceContextE(t, ctx, actionDescription, maxRetries, sleepBetweenRetries, func(ctx context.Context) (interface{}, error) { return action(ctx) })
+	output, _ := out.(string)
+	return output, err
+}
+
+// DoWithRetryInterfaceContext runs the specified action with the given context like DoWithRetryInterface, but stops
+// retrying as soon as the context is cancelled or its deadline is exceeded. If there are any errors, fail the test.
+func DoWithRetryInterfaceContext(t testing.TestingT, ctx context.Context, actionDescription string, maxRetries int, sleepBetweenRetries time.Duration, action func(ctx context.Context) (interface{}, error)) interface{} {
+	out, err := DoWithRetryInterfaceContextE(t, ctx, actionDescription, maxRetries, sleepBetweenRetries, action)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// DoWithRetryInterfaceContextE runs the specified action with the given context like DoWithRetryInterfaceE, but
+// returns a ContextDone error as soon as the context is cancelled or its deadline is exceeded.
+func DoWithRetryInterfaceContextE(t testing.TestingT, ctx context.Context, actionDescription string, maxRetries int, sleepBetweenRetries time.Duration, action func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	return doWithRetryInterfaceE(t, ctx, actionDescription, maxRetries, func(int) time.Duration { return sleepBetweenRetries }, action)
+}
+
+// doWithRetryInterfaceE runs the specified action with the given context like DoWithRetryInterfaceContextE, sleeping
+// for the duration returned by sleepBeforeRetry for each retry, starting at 0.
+func doWithRetryInterfaceE(t testing.TestingT, ctx context.Context, actionDescription string, maxRetries int, sleepBeforeRetry func(retry int) time.Duration, action func(ctx context.Context) (interface{}, error)) (output interface{}, err error) {
+	spanCtx, endSpan := tracing.StartSpan(t, "retry", attribute.String("retry.description", actionDescription), attribute.Int("retry.max_retries", maxRetries))
+	defer func() { endSpan(err) }()
+
+	return retryLoopE(t, trace.ContextWithSpan(ctx, trace.SpanFromContext(spanCtx)), actionDescription, maxRetries, sleepBeforeRetry, action)
+}
+
+// retryLoopE runs the specified action with the given context, retrying it like doWithRetryInterfaceE.
+func retryLoopE(t testing.TestingT, ctx context.Context, actionDescription string, maxRetries int, sleepBeforeRetry func(retry int) time.Duration, action func(ctx context.Context) (interface{}, error)) (interface{}, error) {
 	var output interface{}
 	var err error
 
+	start := time.Now()
 	for i := 0; i <= maxRetries; i++ {
+		if ctx.Err() != nil {
+			return output, contextDoneError(ctx, actionDescription)
+		}
+
 		logger.Default.Logf(t, "%s", actionDescription)
 
-		output, err = action()
+		output, err = action(ctx)
+		attempt := Attempt{Description: actionDescription, Number: i + 1, Err: err, Elapsed: time.Since(start)}
 		if err == nil {
+			collectAttempt(ctx, attempt)
 			return output, nil
 		}
 
 		if _, isFatalErr := err.(FatalError); isFatalErr {
+			collectAttempt(ctx, attempt)
 			logger.Default.Logf(t, "Returning due to fatal error: %v", err)
 			return output, err
 		}
 
-		logger.Default.Logf(t, "%s returned an error: %s. Sleeping for %s and will try again.", actionDescription, err.Error(), sleepBetweenRetries)
-		time.Sleep(sleepBetweenRetries)
+		if ctx.Err() != nil {
+			collectAttempt(ctx, attempt)
+			return output, contextDoneError(ctx, actionDescription)
+		}
+
+		sleep := sleepBeforeRetry(i)
+		if remaining, hasBudget := RetryBudgetRemaining(ctx); hasBudget && remaining < sleep {
+			collectAttempt(ctx, attempt)
+			logger.Default.Warnf(t, "%s returned an error: %s. Not retrying as the retry budget has %s left.", actionDescription, err.Error(), remaining)
+			return output, budgetExhaustedError(ctx, actionDescription, err)
+		}
+		attempt.WillRetry = i < maxRetries
+		collectAttempt(ctx, attempt)
+		logger.Default.Warnf(t, "%s returned an error: %s. Sleeping for %s and will try again.", actionDescription, err.Error(), sleep)
+		timer := time.NewTimer(sleep)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return output, contextDoneError(ctx, actionDescription)
+		}
 	}
 
 	return output, MaxRetriesExceeded{Description: actionDescription, MaxRetries: maxRetries}
@@ -124,16 +206,44 @@ func DoWithRetryableErrors(t testing.TestingT, actionDescription string, retryab
 // sleepBetweenRetries, and retry the specified action, up to a maximum of maxRetries retries. If there is no match,
 // return that error immediately, wrapped in a FatalError. If maxRetries is exceeded, return a MaxRetriesExceeded error.
 func DoWithRetryableErrorsE(t testing.TestingT, actionDescription string, retryableErrors map[string]string, maxRetries int, sleepBetweenRetries time.Duration, action func() (string, error)) (string, error) {
+	retryableAction, err := retryableErrorsAction(t, actionDescription, retryableErrors, action)
+	if err != nil {
+		return "", err
+	}
+	return DoWithRetryE(t, actionDescription, maxRetries, sleepBetweenRetries, retryableAction)
+}
+
+// DoWithRetryableErrorsContext runs the specified action with the given context like DoWithRetryableErrors, but stops
+// retrying as soon as the context is cancelled, its deadline is exceeded or its retry budget is exhausted.
+func DoWithRetryableErrorsContext(t testing.TestingT, ctx context.Context, actionDescription string, retryableErrors map[string]string, maxRetries int, sleepBetweenRetries time.Duration, action func(ctx context.Context) (string, error)) string {
+	out, err := DoWithRetryableErrorsContextE(t, ctx, actionDescription, retryableErrors, maxRetries, sleepBetweenRetries, action)
+	require.NoError(t, err)
+	return out
+}
+
+// DoWithRetryableErrorsContextE runs the specified action with the given context like DoWithRetryableErrorsE, but
+// stops retrying as soon as the context is cancelled, its deadline is exceeded or its retry budget is exhausted.
+func DoWithRetryableErrorsContextE(t testing.TestingT, ctx context.Context, actionDescription string, retryableErrors map[string]string, maxRetries int, sleepBetweenRetries time.Duration, action func(ctx context.Context) (string, error)) (string, error) {
+	retryableAction, err := retryableErrorsAction(t, actionDescription, retryableErrors, func() (string, error) { return action(ctx) })
+	if err != nil {
+		return "", err
+	}
+	return DoWithRetryContextE(t, ctx, actionDescription, maxRetries, sleepBetweenRetries, func(context.Context) (string, error) { return retryableAction() })
+}
+
+// retryableErrorsAction returns the specified action with its errors wrapped in a FatalError, unless the error message
+// or the string output from the action matches any of the regular expressions in the specified retryableErrors map.
+func retryableErrorsAction(t testing.TestingT, actionDescription string, retryableErrors map[string]string, action func() (string, error)) (func() (string, error), error) {
 	retryableErrorsRegexp := map[*regexp.Regexp]string{}
 	for errorStr, errorMessage := range retryableErrors {
 		errorRegex, err := regexp.Compile(errorStr)
 		if err != nil {
-			return "", FatalError{Underlying: err}
+			return nil, FatalError{Underlying: err}
 		}
 		retryableErrorsRegexp[errorRegex] = errorMessage
 	}
 
-	return DoWithRetryE(t, actionDescription, maxRetries, sleepBetweenRetries, func() (string, error) {
+	return func() (string, error) {
 		output, err := action()
 		if err == nil {
 			return output, nil
@@ -147,7 +257,7 @@ func DoWithRetryableErrorsE(t testing.TestingT, actionDescription string, retrya
 		}
 
 		return output, FatalError{Underlying: err}
-	})
+	}, nil
 }
 
 // Done can be stopped.
@@ -208,6 +318,37 @@ func (err MaxRetriesExceeded) Error() string {
 	return fmt.Sprintf("'%s' unsuccessful after %d retries", err.Description, err.MaxRetries)
 }
 
+// ContextDone is an error that occurs when the context of a retried action is cancelled or its deadline is exceeded.
+type ContextDone struct {
+	Description string
+	Underlying  error
+}
+
+func (err ContextDone) Error() string {
+	return fmt.Sprintf("'%s' stopped: %v", err.Description, err.Underlying)
+}
+
+// Unwrap returns the error of the context, so that errors.Is(err, context.DeadlineExceeded) works.
+func (err ContextDone) Unwrap() error {
+	return err.Underlying
+}
+
+// RetryBudgetExhausted is an error that occurs when the retry budget of the context, set with WithRetryBudget, runs
+// out before a retried action succeeds.
+type RetryBudgetExhausted struct {
+	Description string
+	Budget      time.Duration
+	Underlying  error // the last error of the action, or the error of the context
+}
+
+func (err RetryBudgetExhausted) Error() string {
+	return fmt.Sprintf("'%s' unsuccessful within the retry budget of %s: %v", err.Description, err.Budget, err.Underlying)
+}
+
+func (err RetryBudgetExhausted) Unwrap() error {
+	return err.Underlying
+}
+
 // FatalError is a marker interface for errors that should not be retried.
 type FatalError struct {
 	Underlying error
@@ -1,6 +1,8 @@
 package retry
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -262,3 +264,54 @@ type ErrorCounter int
 func (count ErrorCounter) Error() string {
 	return fmt.Sprintf("%d", int(count))
 }
+
+func TestDoWithRetryContext(t *testing.T) {
+	t.Parallel()
+
+	type testKey struct{}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), testKey{}, "value"))
+	defer cancel()
+
+	count := 0
+	out, err := DoWithRetryContextE(t, ctx, "Return value after 2 retries", 5, time.Millisecond, func(actionCtx context.Context) (string, error) {
+		assert.Equal(t, "value", actionCtx.Value(testKey{}))
+		assert.Equal(t, ctx.Done(), actionCtx.Done())
+		count++
+		if count > 2 {
+			return "expected", nil
+		}
+		return "", fmt.Errorf("expected error")
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "expected", out)
+}
+
+func TestDoWithRetryContextDeadline(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := DoWithRetryContextE(t, ctx, "Return error until the deadline", 5, time.Minute, func(ctx context.Context) (string, error) {
+		return "", fmt.Errorf("expected error")
+	})
+	assert.Less(t, time.Since(start), 10*time.Second)
+	assert.IsType(t, ContextDone{}, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestDoWithRetryContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	count := 0
+	_, err := DoWithRetryContextE(t, ctx, "Never run", 5, time.Millisecond, func(ctx context.Context) (string, error) {
+		count++
+		return "", nil
+	})
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Zero(t, count)
+}
@@ -2,14 +2,17 @@ package shell
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"regexp"
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/testing"
@@ -22,10 +25,34 @@ type Command struct {
 	Args       []string          // The args to pass to the command
 	WorkingDir string            // The working directory
 	Env        map[string]string // Additional environment variables to set
+	// If true, the command doesn't get the environment variables of this Go program, except those in EnvAllowlist.
+	CleanEnv     bool
+	EnvAllowlist []string
 	// Use the specified logger for the command's output. Use logger.Discard to not print the output while executing the command.
 	Logger *logger.Logger
+	// If set, the command is interrupted when the context is done, with an interrupt signal so it can clean up.
+	Context context.Context
+	// If set, the command is interrupted when it runs for longer than this.
+	Timeout time.Duration
+	// How long an interrupted command has to exit before its whole process group is killed, so that its child
+	// processes can't keep it running (10 seconds if unset).
+	KillDelay time.Duration
+	// If set, called with each line of stdout or stderr as soon as the command outputs it, e.g. to react to specific
+	// lines mid-run. The output is still captured. The calls are never concurrent, and block reading the output.
+	OnStdoutLine func(line string)
+	OnStderrLine func(line string)
+	// Values masked in the logged command line and output, e.g. tokens and passwords passed as args. The captured
+	// output is not masked.
+	SensitiveArgs []string
+	// Names of environment variables, of Env or of this Go program, whose values are masked like SensitiveArgs.
+	SensitiveEnvVars []string
+	// Regular expressions whose matches are masked like SensitiveArgs, e.g. `password=\S+`.
+	SensitivePatterns []*regexp.Regexp
 }
 
+// defaultKillDelay is how long an interrupted command has to exit before it is killed, unless set with KillDelay.
+const defaultKillDelay = 10 * time.Second
+
 // RunCommand runs a shell command and redirects its stdout and stderr to the stdout of the atomic script itself. If
 // there are any errors, fail the test.
 func RunCommand(t testing.TestingT, command Command) {
@@ -43,6 +70,21 @@ func RunCommandE(t testing.TestingT, command Command) error {
 	return nil
 }
 
+// RunCommandWithContext runs a shell command like RunCommand, interrupting it when the given context is done. If there
+// are any errors, fail the test.
+func RunCommandWithContext(t testing.TestingT, ctx context.Context, command Command) {
+	err := RunCommandWithContextE(t, ctx, command)
+	require.NoError(t, err)
+}
+
+// RunCommandWithContextE runs a shell command like RunCommandE, interrupting it when the given context is done. Any
+// returned error will be of type ErrWithCmdOutput, containing the output streams up to the interruption and the
+// underlying error.
+func RunCommandWithContextE(t testing.TestingT, ctx context.Context, command Command) error {
+	command.Context = ctx
+	return RunCommandE(t, command)
+}
+
 // RunCommandAndGetOutput runs a shell command and returns its stdout and stderr as a string. The stdout and stderr of
 // that command will also be logged with Command.Log to make debugging easier. If there are any errors, fail the test.
 func RunCommandAndGetOutput(t testing.TestingT, command Command) string {
@@ -63,6 +105,21 @@ func RunCommandAndGetOutputE(t testing.TestingT, command Command) (string, error
 	return output.Combined(), nil
 }
 
+// RunCommandAndGetOutputWithContext runs a shell command like RunCommandAndGetOutput, interrupting it when the given
+// context is done. If there are any errors, fail the test.
+func RunCommandAndGetOutputWithContext(t testing.TestingT, ctx context.Context, command Command) string {
+	out, err := RunCommandAndGetOutputWithContextE(t, ctx, command)
+	require.NoError(t, err)
+	return out
+}
+
+// RunCommandAndGetOutputWithContextE runs a shell command like RunCommandAndGetOutputE, interrupting it when the given
+// context is done. The output up to the interruption is returned along with the error.
+func RunCommandAndGetOutputWithContextE(t testing.TestingT, ctx context.Context, command Command) (string, error) {
+	command.Context = ctx
+	return RunCommandAndGetOutputE(t, command)
+}
+
 // RunCommandAndGetStdOut runs a shell command and returns solely its stdout (but not stderr) as a string. The stdout and
 // stderr of that command will also be logged with Command.Log to make debugging easier. If there are any errors, fail
 // the test.
@@ -84,6 +141,21 @@ func RunCommandAndGetStdOutE(t testing.TestingT, command Command) (string, error
 	return output.Stdout(), nil
 }
 
+// RunCommandAndGetStdOutWithContext runs a shell command like RunCommandAndGetStdOut, interrupting it when the given
+// context is done. If there are any errors, fail the test.
+func RunCommandAndGetStdOutWithContext(t testing.TestingT, ctx context.Context, command Command) string {
+	output, err := RunCommandAndGetStdOutWithContextE(t, ctx, command)
+	require.NoError(t, err)
+	return output
+}
+
+// RunCommandAndGetStdOutWithContextE runs a shell command like RunCommandAndGetStdOutE, interrupting it when the given
+// context is done. The stdout up to the interruption is returned along with the error.
+func RunCommandAndGetStdOutWithContextE(t testing.TestingT, ctx context.Context, command Command) (string, error) {
+	command.Context = ctx
+	return RunCommandAndGetStdOutE(t, command)
+}
+
 // RunCommandAndGetStdOutErr runs a shell command and returns solely its stdout and stderr as a string. The stdout and
 // stderr of that command will also be logged with Command.Log to make debugging easier. If there are any errors, fail
 // the test.
@@ -105,22 +177,140 @@ func RunCommandAndGetStdOutErrE(t testing.TestingT, command Command) (stdout str
 	return output.Stdout(), output.Stderr(), nil
 }
 
+// RunCommandAndGetStdOutErrWithContext runs a shell command like RunCommandAndGetStdOutErr, interrupting it when the
+// given context is done. If there are any errors, fail the test.
+func RunCommandAndGetStdOutErrWithContext(t testing.TestingT, ctx context.Context, command Command) (stdout string, stderr string) {
+	stdout, stderr, err := RunCommandAndGetStdOutErrWithContextE(t, ctx, command)
+	require.NoError(t, err)
+	return stdout, stderr
+}
+
+// RunCommandAndGetStdOutErrWithContextE runs a shell command like RunCommandAndGetStdOutErrE, interrupting it when the
+// given context is done. The stdout and stderr up to the interruption are returned along with the error.
+func RunCommandAndGetStdOutErrWithContextE(t testing.TestingT, ctx context.Context, command Command) (stdout string, stderr string, err error) {
+	command.Context = ctx
+	return RunCommandAndGetStdOutErrE(t, command)
+}
+
+// Result is the result of a command run by RunCommandAndGetResult.
+type Result struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int // the exit code of the command, or -1 if it couldn't be run or was killed by a signal
+	Duration time.Duration
+}
+
+// RunCommandAndGetResult runs a shell command and returns its stdout, stderr, exit code and duration. The stdout and
+// stderr of that command will also be logged with Command.Log to make debugging easier. If there are any errors,
+// including a non-zero exit code, fail the test.
+func RunCommandAndGetResult(t testing.TestingT, command Command) *Result {
+	result, err := RunCommandAndGetResultE(t, command)
+	require.NoError(t, err)
+	return result
+}
+
+// RunCommandAndGetResultE runs a shell command and returns its stdout, stderr, exit code and duration. The stdout and
+// stderr of that command will also be logged with Command.Log to make debugging easier. The result is returned even if
+// the command fails, so tests can assert on the exit code and stderr of failures. Any returned error will be of type
+// ErrWithCmdOutput, containing the output streams and the underlying error.
+func RunCommandAndGetResultE(t testing.TestingT, command Command) (*Result, error) {
+	start := time.Now()
+	output, err := runCommand(t, command)
+	result := &Result{
+		Stdout:   output.Stdout(),
+		Stderr:   output.Stderr(),
+		Duration: time.Since(start),
+	}
+	if err != nil {
+		result.ExitCode = -1
+		if exitCode, exitCodeErr := GetExitCodeForRunCommandError(err); exitCodeErr == nil && exitCode != 0 {
+			result.ExitCode = exitCode
+		}
+		return result, &ErrWithCmdOutput{err, output}
+	}
+	return result, nil
+}
+
 type ErrWithCmdOutput struct {
 	Underlying error
 	Output     *output
 }
 
 func (e *ErrWithCmdOutput) Error() string {
-	return fmt.Sprintf("error while running command: %v; %s", e.Underlying, e.Output.Stderr())
+	stderr := e.Output.Stderr()
+	if e.Output != nil && e.Output.redact != nil {
+		stderr = e.Output.redact(stderr)
+	}
+	return fmt.Sprintf("error while running command: %v; %s", e.Underlying, stderr)
+}
+
+func (e *ErrWithCmdOutput) Unwrap() error {
+	return e.Underlying
+}
+
+// CommandStoppedError is an error that occurs if a command is interrupted because its context is done or it timed out.
+// It matches context.Canceled or context.DeadlineExceeded with errors.Is.
+type CommandStoppedError struct {
+	Command    string
+	Timeout    time.Duration
+	Cause      error // the error of the context
+	Underlying error // the error the command exited with
+}
+
+func (e *CommandStoppedError) Error() string {
+	if errors.Is(e.Cause, context.DeadlineExceeded) && e.Timeout > 0 {
+		return fmt.Sprintf("command %s was stopped after timing out after %s: %v", e.Command, e.Timeout, e.Underlying)
+	}
+	return fmt.Sprintf("command %s was stopped: %v: %v", e.Command, e.Cause, e.Underlying)
+}
+
+func (e *CommandStoppedError) Unwrap() []error {
+	return []error{e.Cause, e.Underlying}
 }
 
 // runCommand runs a shell command and stores each line from stdout and stderr in Output. Depending on the logger, the
 // stdout and stderr of that command will also be printed to the stdout and stderr of this Go program to make debugging
-// easier.
+// easier. If the context of the command is done or it times out, the command is interrupted, and its whole process
+// group is killed if it doesn't exit within the kill delay.
 func runCommand(t testing.TestingT, command Command) (*output, error) {
-	command.Logger.Logf(t, "Running command %s with args %s", command.Command, command.Args)
+	redact := newRedactor(command)
+	command.Logger.Logf(t, "%s", redact(fmt.Sprintf("Running command %s with args %s", command.Command, command.Args)))
+
+	ctx := command.Context
+	if command.Timeout > 0 {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, command.Timeout)
+		defer cancel()
+	}
 
 	cmd := exec.Command(command.Command, command.Args...)
+	if ctx != nil {
+		killDelay := command.KillDelay
+		if killDelay == 0 {
+			killDelay = defaultKillDelay
+		}
+
+		// Don't kill the process group once the command is done, as its ID could be reused
+		done := make(chan struct{})
+		defer close(done)
+
+		cmd = exec.CommandContext(ctx, command.Command, command.Args...)
+		cmd.Stdin = os.Stdin
+		setProcessGroup(cmd)
+		cmd.Cancel = func() error {
+			go func() {
+				select {
+				case <-time.After(killDelay):
+					killProcessGroup(cmd)
+				case <-done:
+				}
+			}()
+			return interruptProcessGroup(cmd)
+		}
+	}
 	cmd.Dir = command.WorkingDir
 	cmd.Stdin = os.Stdin
 	cmd.Env = formatEnvVars(command)
@@ -140,32 +330,40 @@ func runCommand(t testing.TestingT, command Command) (*output, error) {
 		return nil, err
 	}
 
-	output, err := readStdoutAndStderr(t, command.Logger, stdout, stderr)
+	output, err := readStdoutAndStderr(t, command.Logger, redact, stdout, stderr, command.OnStdoutLine, command.OnStderrLine)
 	if err != nil {
 		return output, err
 	}
 
-	return output, cmd.Wait()
+	err = cmd.Wait()
+	if err != nil && ctx != nil && ctx.Err() != nil {
+		return output, &CommandStoppedError{Command: command.Command, Timeout: command.Timeout, Cause: ctx.Err(), Underlying: err}
+	}
+	return output, err
 }
 
 // This function captures stdout and stderr into the given variables while still printing it to the stdout and stderr
-// of this Go program
-func readStdoutAndStderr(t testing.TestingT, log *logger.Logger, stdout, stderr io.ReadCloser) (*output, error) {
+// of this Go program, masked with the given redact function, and calls the given line handlers, if any, with each line
+func readStdoutAndStderr(t testing.TestingT, log *logger.Logger, redact func(string) string, stdout, stderr io.ReadCloser, onStdoutLine, onStderrLine func(string)) (*output, error) {
 	out := newOutput()
+	out.redact = redact
 	stdoutReader := bufio.NewReader(stdout)
 	stderrReader := bufio.NewReader(stderr)
 
 	wg := &sync.WaitGroup{}
 
+	// The same mutex serializes the calls to both handlers
+	handlersMutex := &sync.Mutex{}
+
 	wg.Add(2)
 	var stdoutErr, stderrErr error
 	go func() {
 		defer wg.Done()
-		stdoutErr = readData(t, log, stdoutReader, out.stdout)
+		stdoutErr = readData(t, log, redact, stdoutReader, out.stdout, lockedLineHandler(handlersMutex, onStdoutLine))
 	}()
 	go func() {
 		defer wg.Done()
-		stderrErr = readData(t, log, stderrReader, out.stderr)
+		stderrErr = readData(t, log, redact, stderrReader, out.stderr, lockedLineHandler(handlersMutex, onStderrLine))
 	}()
 	wg.Wait()
 
@@ -179,7 +377,19 @@ func readStdoutAndStderr(t testing.TestingT, log *logger.Logger, stdout, stderr
 	return out, nil
 }
 
-func readData(t testing.TestingT, log *logger.Logger, reader *bufio.Reader, writer io.StringWriter) error {
+// lockedLineHandler returns the given line handler, called with the given mutex locked, or nil if there's no handler.
+func lockedLineHandler(mutex *sync.Mutex, onLine func(string)) func(string) {
+	if onLine == nil {
+		return nil
+	}
+	return func(line string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		onLine(line)
+	}
+}
+
+func readData(t testing.TestingT, log *logger.Logger, redact func(string) string, reader *bufio.Reader, writer io.StringWriter, onLine func(string)) error {
 	var line string
 	var readErr error
 	for {
@@ -203,12 +413,16 @@ func readData(t testing.TestingT, log *logger.Logger, reader *bufio.Reader, writ
 		// the line.
 		//
 		// See https://github.com/gruntwork-io/terratest/issues/982.
-		log.Logf(t, "%s", line)
+		log.Debugf(t, "%s", redact(line))
 
 		if _, err := writer.WriteString(line); err != nil {
 			return err
 		}
 
+		if onLine != nil {
+			onLine(line)
+		}
+
 		if readErr != nil {
 			break
 		}
@@ -222,12 +436,9 @@ func readData(t testing.TestingT, log *logger.Logger, reader *bufio.Reader, writ
 // GetExitCodeForRunCommandError tries to read the exit code for the error object returned from running a shell command. This is a bit tricky to do
 // in a way that works across platforms.
 func GetExitCodeForRunCommandError(err error) (int, error) {
-	if errWithOutput, ok := err.(*ErrWithCmdOutput); ok {
-		err = errWithOutput.Underlying
-	}
-
 	// http://stackoverflow.com/a/10385867/483528
-	if exitErr, ok := err.(*exec.ExitError); ok {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
 		// The program has exited with an exit code != 0
 
 		// This works on both Unix and Windows. Although package
@@ -245,6 +456,14 @@ func GetExitCodeForRunCommandError(err error) (int, error) {
 
 func formatEnvVars(command Command) []string {
 	env := os.Environ()
+	if command.CleanEnv {
+		env = []string{}
+		for _, key := range command.EnvAllowlist {
+			if value, ok := os.LookupEnv(key); ok {
+				env = append(env, fmt.Sprintf("%s=%s", key, value))
+			}
+		}
+	}
 	for key, value := range command.Env {
 		env = append(env, fmt.Sprintf("%s=%s", key, value))
 	}
@@ -2,10 +2,12 @@ package shell
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -27,6 +29,67 @@ func TestRunCommandAndGetOutput(t *testing.T) {
 	assert.Equal(t, text, strings.TrimSpace(out))
 }
 
+func TestRunCommandWithCancelledContext(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	cmd := Command{
+		Command: "bash",
+		Args:    []string{"-c", "trap 'echo interrupted; exit 1' INT; while true; do sleep 0.1; done"},
+		Logger:  logger.Discard,
+		Context: ctx,
+	}
+
+	start := time.Now()
+	out, err := RunCommandAndGetOutputE(t, cmd)
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 10*time.Second)
+	assert.Contains(t, out, "interrupted")
+}
+
+func TestRunCommandWithTimeout(t *testing.T) {
+	t.Parallel()
+
+	cmd := Command{
+		Command: "bash",
+		Args:    []string{"-c", "echo started; sleep 60"},
+		Logger:  logger.Discard,
+		Timeout: 500 * time.Millisecond,
+	}
+
+	start := time.Now()
+	out, err := RunCommandAndGetOutputE(t, cmd)
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 10*time.Second)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "timing out after 500ms")
+	assert.Equal(t, "started", out)
+}
+
+func TestRunCommandWithContextKillsProcessGroup(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	// The child ignores the interrupt and keeps the output open, so only killing the process group stops it
+	cmd := Command{
+		Command:   "bash",
+		Args:      []string{"-c", "trap '' INT; bash -c \"trap '' INT; echo child; sleep 60\"; echo parent"},
+		Logger:    logger.Discard,
+		KillDelay: 200 * time.Millisecond,
+	}
+
+	start := time.Now()
+	stdout, _, err := RunCommandAndGetStdOutErrWithContextE(t, ctx, cmd)
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 10*time.Second)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, "child", stdout)
+}
+
 func TestRunCommandAndGetOutputOrder(t *testing.T) {
 	t.Parallel()
 
@@ -80,6 +143,76 @@ func TestRunCommandGetExitCode(t *testing.T) {
 	assert.Equal(t, code, 42)
 }
 
+func TestRunCommandAndGetResult(t *testing.T) {
+	t.Parallel()
+
+	result := RunCommandAndGetResult(t, Command{
+		Command: "sh",
+		Args:    []string{"-c", "echo out && echo warning >&2"},
+		Logger:  logger.Discard,
+	})
+	assert.Equal(t, "out", result.Stdout)
+	assert.Equal(t, "warning", result.Stderr)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Greater(t, result.Duration, time.Duration(0))
+}
+
+func TestRunCommandAndGetResultEExitCode(t *testing.T) {
+	t.Parallel()
+
+	result, err := RunCommandAndGetResultE(t, Command{
+		Command: "sh",
+		Args:    []string{"-c", "echo out && echo failed >&2 && exit 3"},
+		Logger:  logger.Discard,
+	})
+	require.Error(t, err)
+	assert.Equal(t, "out", result.Stdout)
+	assert.Equal(t, "failed", result.Stderr)
+	assert.Equal(t, 3, result.ExitCode)
+}
+
+func TestRunCommandAndGetResultENotFound(t *testing.T) {
+	t.Parallel()
+
+	result, err := RunCommandAndGetResultE(t, Command{
+		Command: "thisbinarydoesnotexistbecausenobodyusesnamesthatlong",
+		Logger:  logger.Discard,
+	})
+	require.Error(t, err)
+	assert.Equal(t, -1, result.ExitCode)
+}
+
+func TestRunCommandWithOutputLineHandlers(t *testing.T) {
+	t.Parallel()
+
+	stdoutLines := []string{}
+	stderrLines := []string{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Stop the command as soon as it's ready, from the line handler
+	out, err := RunCommandAndGetOutputE(t, Command{
+		Command: "bash",
+		Args:    []string{"-c", "echo starting; echo warming up >&2; echo ready; sleep 60"},
+		Logger:  logger.Discard,
+		Context: ctx,
+		OnStdoutLine: func(line string) {
+			stdoutLines = append(stdoutLines, line)
+			if line == "ready" {
+				cancel()
+			}
+		},
+		OnStderrLine: func(line string) {
+			stderrLines = append(stderrLines, line)
+		},
+	})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []string{"starting", "ready"}, stdoutLines)
+	assert.Equal(t, []string{"warming up"}, stderrLines)
+	assert.Contains(t, out, "ready")
+}
+
 func TestRunCommandAndGetOutputConcurrency(t *testing.T) {
 	t.Parallel()
 
@@ -11,6 +11,8 @@ type output struct {
 	stderr *outputStream
 	// merged contains stdout  and stderr merged into one stream.
 	merged *merged
+	// redact masks the sensitive values of the command in the output, for error messages.
+	redact func(string) string
 }
 
 func newOutput() *output {
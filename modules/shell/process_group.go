@@ -0,0 +1,45 @@
+//go:build !windows
+
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/term"
+)
+
+// setProcessGroup makes the given command run in its own process group, so that it can be stopped along with its child
+// processes. This is skipped when the stdin of the command is a terminal: a command in a background process group gets
+// SIGTTIN when it reads from the terminal, and doesn't get the interrupt signal of Ctrl-C.
+func setProcessGroup(cmd *exec.Cmd) {
+	if stdin, ok := cmd.Stdin.(*os.File); ok && term.IsTerminal(int(stdin.Fd())) {
+		return
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// hasProcessGroup returns true if the given command runs in its own process group.
+func hasProcessGroup(cmd *exec.Cmd) bool {
+	return cmd.SysProcAttr != nil && cmd.SysProcAttr.Setpgid
+}
+
+// interruptProcessGroup sends an interrupt signal to the process group of the given command, or to the command only if
+// it has no process group of its own, so it can clean up.
+func interruptProcessGroup(cmd *exec.Cmd) error {
+	if !hasProcessGroup(cmd) {
+		return cmd.Process.Signal(os.Interrupt)
+	}
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGINT)
+}
+
+// killProcessGroup kills the process group of the given command, or the command only if it has no process group of
+// its own.
+func killProcessGroup(cmd *exec.Cmd) {
+	if !hasProcessGroup(cmd) {
+		cmd.Process.Kill()
+		return
+	}
+	syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,39 @@
+//go:build !windows
+
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetProcessGroupWithoutTerminal(t *testing.T) {
+	t.Parallel()
+
+	stdin, stdinWriter, err := os.Pipe()
+	require.NoError(t, err)
+	defer stdin.Close()
+	defer stdinWriter.Close()
+
+	cmd := exec.Command("true")
+	cmd.Stdin = stdin
+	setProcessGroup(cmd)
+	assert.True(t, hasProcessGroup(cmd))
+}
+
+func TestKillProcessGroupWithoutProcessGroup(t *testing.T) {
+	t.Parallel()
+
+	// Without a process group of its own, the command itself is killed
+	cmd := exec.Command("sleep", "60")
+	require.NoError(t, cmd.Start())
+	assert.False(t, hasProcessGroup(cmd))
+
+	killProcessGroup(cmd)
+	assert.Error(t, cmd.Wait())
+	assert.False(t, cmd.ProcessState.Exited())
+}
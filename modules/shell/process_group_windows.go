@@ -0,0 +1,20 @@
+//go:build windows
+
+package shell
+
+import (
+	"os/exec"
+)
+
+// setProcessGroup does nothing on Windows, which has no process groups like Unix.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// interruptProcessGroup kills the given command, as Windows doesn't support sending interrupt signals.
+func interruptProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+// killProcessGroup kills the given command.
+func killProcessGroup(cmd *exec.Cmd) {
+	cmd.Process.Kill()
+}
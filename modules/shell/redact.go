@@ -0,0 +1,57 @@
+package shell
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// redactedValue replaces the sensitive values of commands in their logs.
+const redactedValue = "***"
+
+// newRedactor returns a function that masks the sensitive args, the values of the sensitive environment variables and
+// the matches of the sensitive patterns of the given command in a string.
+func newRedactor(command Command) func(string) string {
+	values := []string{}
+	for _, value := range command.SensitiveArgs {
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	for _, name := range command.SensitiveEnvVars {
+		value, ok := command.Env[name]
+		if !ok {
+			value = os.Getenv(name)
+		}
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	// Mask the longest values first, so a value containing another is masked entirely
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+
+	if len(values) == 0 && len(command.SensitivePatterns) == 0 {
+		return func(s string) string { return s }
+	}
+
+	oldNew := []string{}
+	for _, value := range values {
+		oldNew = append(oldNew, value, redactedValue)
+	}
+	replacer := strings.NewReplacer(oldNew...)
+
+	return func(s string) string {
+		s = replacer.Replace(s)
+		for _, pattern := range command.SensitivePatterns {
+			s = pattern.ReplaceAllString(s, redactedValue)
+		}
+		return s
+	}
+}
+
+// Redact masks the sensitive args, the values of the sensitive environment variables and the matches of the sensitive
+// patterns of the command in the given string, as they are masked in the logs of the command, e.g. to describe the
+// command in other logs and errors.
+func (command Command) Redact(s string) string {
+	return newRedactor(command)(s)
+}
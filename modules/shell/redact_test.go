@@ -0,0 +1,52 @@
+package shell
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	tftesting "github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// bufferLogger logs to a buffer, to check what is logged.
+type bufferLogger struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (l *bufferLogger) Logf(t tftesting.TestingT, format string, args ...interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.buffer.WriteString(fmt.Sprintf(format, args...) + "\n")
+}
+
+func TestRunCommandRedactsSensitiveValues(t *testing.T) {
+	t.Setenv("TERRATEST_REDACT_TOKEN", "env-token-value")
+
+	logs := &bufferLogger{}
+	out, err := RunCommandAndGetOutputE(t, Command{
+		Command:           "sh",
+		Args:              []string{"-c", `echo "arg: $0 env: $TERRATEST_REDACT_PASSWORD $TERRATEST_REDACT_TOKEN pattern: password=hunter2" | tee /dev/stderr; exit 1`, "--token=arg-token-value"},
+		Env:               map[string]string{"TERRATEST_REDACT_PASSWORD": "env-password-value"},
+		Logger:            logger.New(logs),
+		SensitiveArgs:     []string{"arg-token-value"},
+		SensitiveEnvVars:  []string{"TERRATEST_REDACT_PASSWORD", "TERRATEST_REDACT_TOKEN"},
+		SensitivePatterns: []*regexp.Regexp{regexp.MustCompile(`password=\S+`)},
+	})
+	require.Error(t, err)
+
+	for _, secret := range []string{"arg-token-value", "env-password-value", "env-token-value", "hunter2"} {
+		assert.NotContains(t, logs.buffer.String(), secret)
+		assert.NotContains(t, err.Error(), secret)
+		// The captured output is not masked
+		assert.Contains(t, out, secret)
+	}
+	assert.Contains(t, logs.buffer.String(), "--token=***")
+	assert.Contains(t, logs.buffer.String(), "env: *** *** pattern: ***")
+}
@@ -0,0 +1,96 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// DefaultSandboxEnvVars are the environment variables of this Go program that a Sandbox passes to commands in addition
+// to the allowed ones, as most commands need them to run.
+var DefaultSandboxEnvVars = []string{
+	"PATH", "LANG", "LC_ALL", "TERM", "TZ", "USER",
+	// Needed to run anything on Windows
+	"SYSTEMROOT", "COMSPEC", "PATHEXT", "WINDIR",
+}
+
+// Sandbox is an isolated environment to run commands in: they only get the allowed environment variables of this Go
+// program, and have their own empty home, temp and working directories. This stops tests from picking up the
+// kubeconfig, AWS profiles, Terraform plugin cache and other configuration of the developer running them. Create one
+// with NewSandbox, run commands in it with Command, and remove its directories with Cleanup.
+type Sandbox struct {
+	RootDir        string // the directory of the home, temp and working directories
+	HomeDir        string
+	TmpDir         string
+	WorkingDir     string   // the working directory of commands that don't set one
+	AllowedEnvVars []string // the environment variables of this Go program passed to commands, with DefaultSandboxEnvVars
+}
+
+// NewSandbox creates a sandbox with new home, temp and working directories, passing the given environment variables
+// of this Go program to commands along with DefaultSandboxEnvVars. If there are any errors, fail the test.
+func NewSandbox(t testing.TestingT, allowedEnvVars ...string) *Sandbox {
+	sandbox, err := NewSandboxE(t, allowedEnvVars...)
+	require.NoError(t, err)
+	return sandbox
+}
+
+// NewSandboxE creates a sandbox with new home, temp and working directories, passing the given environment variables
+// of this Go program to commands along with DefaultSandboxEnvVars.
+func NewSandboxE(t testing.TestingT, allowedEnvVars ...string) (*Sandbox, error) {
+	rootDir, err := os.MkdirTemp("", "terratest-sandbox-")
+	if err != nil {
+		return nil, err
+	}
+
+	sandbox := &Sandbox{
+		RootDir:        rootDir,
+		HomeDir:        filepath.Join(rootDir, "home"),
+		TmpDir:         filepath.Join(rootDir, "tmp"),
+		WorkingDir:     filepath.Join(rootDir, "work"),
+		AllowedEnvVars: append(append([]string{}, DefaultSandboxEnvVars...), allowedEnvVars...),
+	}
+	for _, dir := range []string{sandbox.HomeDir, sandbox.TmpDir, sandbox.WorkingDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			os.RemoveAll(rootDir)
+			return nil, err
+		}
+	}
+
+	logger.Default.Logf(t, "Created sandbox in %s", rootDir)
+	return sandbox, nil
+}
+
+// Command returns the given command set up to run in the sandbox: with a clean environment of the allowed variables,
+// HOME, TMPDIR and the XDG base directories in the sandbox, and the working directory of the sandbox unless it has
+// one. The Env of the command is kept and takes precedence.
+func (sandbox *Sandbox) Command(command Command) Command {
+	env := map[string]string{
+		"HOME":            sandbox.HomeDir,
+		"USERPROFILE":     sandbox.HomeDir,
+		"TMPDIR":          sandbox.TmpDir,
+		"TMP":             sandbox.TmpDir,
+		"TEMP":            sandbox.TmpDir,
+		"XDG_CONFIG_HOME": filepath.Join(sandbox.HomeDir, ".config"),
+		"XDG_CACHE_HOME":  filepath.Join(sandbox.HomeDir, ".cache"),
+		"XDG_DATA_HOME":   filepath.Join(sandbox.HomeDir, ".local", "share"),
+	}
+	for key, value := range command.Env {
+		env[key] = value
+	}
+
+	command.Env = env
+	command.CleanEnv = true
+	command.EnvAllowlist = append(append([]string{}, sandbox.AllowedEnvVars...), command.EnvAllowlist...)
+	if command.WorkingDir == "" {
+		command.WorkingDir = sandbox.WorkingDir
+	}
+	return command
+}
+
+// Cleanup removes the directories of the sandbox. If there are any errors, fail the test.
+func (sandbox *Sandbox) Cleanup(t testing.TestingT) {
+	require.NoError(t, os.RemoveAll(sandbox.RootDir))
+}
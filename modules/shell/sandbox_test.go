@@ -0,0 +1,51 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+)
+
+func TestSandboxCommand(t *testing.T) {
+	t.Setenv("TERRATEST_SANDBOX_ALLOWED", "allowed")
+	t.Setenv("TERRATEST_SANDBOX_SECRET", "secret")
+	t.Setenv("AWS_PROFILE", "developer")
+
+	sandbox := NewSandbox(t, "TERRATEST_SANDBOX_ALLOWED")
+	defer sandbox.Cleanup(t)
+
+	out := RunCommandAndGetStdOut(t, sandbox.Command(Command{
+		Command: "sh",
+		Args:    []string{"-c", "env; echo pwd=$(pwd)"},
+		Env:     map[string]string{"TERRATEST_SANDBOX_EXTRA": "extra"},
+		Logger:  logger.Discard,
+	}))
+	lines := strings.Split(out, "\n")
+
+	workingDir, err := filepath.EvalSymlinks(sandbox.WorkingDir)
+	require.NoError(t, err)
+	assert.Contains(t, lines, "TERRATEST_SANDBOX_ALLOWED=allowed")
+	assert.Contains(t, lines, "TERRATEST_SANDBOX_EXTRA=extra")
+	assert.Contains(t, lines, "HOME="+sandbox.HomeDir)
+	assert.Contains(t, lines, "TMPDIR="+sandbox.TmpDir)
+	assert.Contains(t, lines, "pwd="+workingDir)
+	assert.NotContains(t, out, "TERRATEST_SANDBOX_SECRET")
+	assert.NotContains(t, out, "AWS_PROFILE")
+}
+
+func TestSandboxCleanup(t *testing.T) {
+	t.Parallel()
+
+	sandbox := NewSandbox(t)
+	require.DirExists(t, sandbox.HomeDir)
+
+	sandbox.Cleanup(t)
+	_, err := os.Stat(sandbox.RootDir)
+	assert.True(t, os.IsNotExist(err))
+}
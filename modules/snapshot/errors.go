@@ -0,0 +1,22 @@
+package snapshot
+
+import "fmt"
+
+// SnapshotNotFound is returned when a snapshot doesn't exist yet and snapshots are not being updated.
+type SnapshotNotFound struct {
+	Path string
+}
+
+func (err SnapshotNotFound) Error() string {
+	return fmt.Sprintf("Snapshot %s does not exist. Run the test with %s=true to create it.", err.Path, UpdateSnapshotsEnvVar)
+}
+
+// SnapshotMismatch is returned when the data doesn't match its snapshot.
+type SnapshotMismatch struct {
+	Path string
+	Diff string // the unified diff from the snapshot to the data
+}
+
+func (err SnapshotMismatch) Error() string {
+	return fmt.Sprintf("Data does not match snapshot %s. Run the test with %s=true to update it.\n%s", err.Path, UpdateSnapshotsEnvVar, err.Diff)
+}
@@ -0,0 +1,364 @@
+// Package snapshot compares data, such as Terraform plan JSON, rendered Helm templates, kubectl output or API
+// responses, to golden files stored with the tests, normalizing it and redacting the values that change between runs.
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+)
+
+// UpdateSnapshotsEnvVar is the environment variable that, when set to true, writes the data to the snapshots instead
+// of comparing it to them.
+const UpdateSnapshotsEnvVar = "UPDATE_SNAPSHOTS"
+
+// DefaultDir is the folder, relative to the folder of the test, where snapshots are stored by default.
+const DefaultDir = "testdata/snapshots"
+
+// DefaultReplacement replaces the redacted values, if a redaction has no replacement.
+const DefaultReplacement = "<redacted>"
+
+// Format is the format data is normalized in before it's compared to its snapshot.
+type Format string
+
+const (
+	// FormatText only normalizes the line endings of data.
+	FormatText Format = "text"
+	// FormatJSON indents data and sorts the object keys.
+	FormatJSON Format = "json"
+	// FormatYAML formats each document of data and sorts the mapping keys, e.g. for rendered Helm templates.
+	FormatYAML Format = "yaml"
+)
+
+// Redaction replaces values that change between runs, such as IDs and timestamps, before data is compared to its
+// snapshot.
+type Redaction struct {
+	Keys        []string       // the values of the JSON or YAML fields with these names, at any depth, are replaced
+	Pattern     *regexp.Regexp // the matches of the pattern in the normalized data are replaced
+	Replacement string         // defaults to DefaultReplacement
+}
+
+// RedactKeys returns a redaction replacing the values of the JSON or YAML fields with the given names, at any depth.
+func RedactKeys(keys ...string) Redaction {
+	return Redaction{Keys: keys}
+}
+
+// RedactPattern returns a redaction replacing the matches of the given regular expression in the normalized data.
+func RedactPattern(pattern string) Redaction {
+	return Redaction{Pattern: regexp.MustCompile(pattern)}
+}
+
+var (
+	// RedactTimestamps replaces RFC 3339 timestamps, e.g. the timestamp of Terraform plans.
+	RedactTimestamps = Redaction{
+		Pattern:     regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})`),
+		Replacement: "<timestamp>",
+	}
+	// RedactUUIDs replaces UUIDs.
+	RedactUUIDs = Redaction{
+		Pattern:     regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`),
+		Replacement: "<uuid>",
+	}
+	// RedactKubernetesMetadata replaces the metadata that Kubernetes generates for every object, e.g. in the output of
+	// kubectl get -o yaml.
+	RedactKubernetesMetadata = RedactKeys("uid", "resourceVersion", "creationTimestamp", "generation", "managedFields")
+)
+
+// Options are the options to compare data to snapshots.
+type Options struct {
+	Dir        string // the folder where the snapshots are stored, defaults to DefaultDir
+	Format     Format // defaults to FormatJSON for values other than strings and byte slices, and FormatText otherwise
+	Redactions []Redaction
+	Update     bool // if true, the snapshots are written instead of compared, as when UPDATE_SNAPSHOTS is true
+}
+
+// MatchSnapshot compares the given data, normalized in its default format, to its snapshot with the given name in
+// DefaultDir, and fails the test if they differ.
+func MatchSnapshot(t testing.TestingT, name string, data interface{}) {
+	MatchSnapshotWithOptions(t, &Options{}, name, data)
+}
+
+// MatchSnapshotE compares the given data, normalized in its default format, to its snapshot with the given name in
+// DefaultDir, and returns a SnapshotMismatch error if they differ.
+func MatchSnapshotE(t testing.TestingT, name string, data interface{}) error {
+	return MatchSnapshotWithOptionsE(t, &Options{}, name, data)
+}
+
+// MatchSnapshotWithOptions compares the given data, normalized and redacted with the given options, to its snapshot
+// with the given name, and fails the test if they differ.
+func MatchSnapshotWithOptions(t testing.TestingT, options *Options, name string, data interface{}) {
+	if err := MatchSnapshotWithOptionsE(t, options, name, data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// MatchSnapshotWithOptionsE compares the given data, normalized and redacted with the given options, to its snapshot
+// with the given name, and returns a SnapshotMismatch error if they differ, or a SnapshotNotFound error if there's no
+// such snapshot. The snapshots of a test are stored in a folder named after the test, e.g.
+// testdata/snapshots/TestPlan/plan.json. If UPDATE_SNAPSHOTS is true, or options.Update is set, the snapshot is written
+// instead.
+func MatchSnapshotWithOptionsE(t testing.TestingT, options *Options, name string, data interface{}) error {
+	format := options.Format
+	if format == "" {
+		format = defaultFormat(data)
+	}
+
+	normalized, err := Normalize(format, data, options.Redactions...)
+	if err != nil {
+		return err
+	}
+
+	path, err := snapshotPath(t, options, name, format)
+	if err != nil {
+		return err
+	}
+	if options.Update || updateSnapshotsFromEnv() {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(normalized), 0644); err != nil {
+			return err
+		}
+		logger.Default.Logf(t, "Updated snapshot %s", path)
+		return nil
+	}
+
+	expected, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return SnapshotNotFound{Path: path}
+	}
+	if err != nil {
+		return err
+	}
+
+	if string(expected) == normalized {
+		return nil
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(expected)),
+		B:        difflib.SplitLines(normalized),
+		FromFile: path,
+		ToFile:   "actual",
+		Context:  3,
+	})
+	if err != nil {
+		return err
+	}
+	return SnapshotMismatch{Path: path, Diff: diff}
+}
+
+// Normalize returns the given data normalized in the given format, with the given redactions applied, as it's compared
+// to snapshots. Strings and byte slices are parsed in the format, and other values are converted to JSON first.
+func Normalize(format Format, data interface{}, redactions ...Redaction) (string, error) {
+	raw, err := dataBytes(data)
+	if err != nil {
+		return "", err
+	}
+
+	var normalized string
+	switch format {
+	case FormatText:
+		normalized = string(raw)
+	case FormatJSON:
+		normalized, err = normalizeJson(raw, redactions)
+	case FormatYAML:
+		normalized, err = normalizeYaml(raw, redactions)
+	default:
+		return "", fmt.Errorf("unknown snapshot format %q", format)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	normalized = strings.ReplaceAll(normalized, "\r\n", "\n")
+	for _, redaction := range redactions {
+		if redaction.Pattern != nil {
+			normalized = redaction.Pattern.ReplaceAllLiteralString(normalized, redaction.replacement())
+		}
+	}
+	return strings.TrimRight(normalized, "\n") + "\n", nil
+}
+
+// normalizeJson indents the given JSON with sorted object keys, after redacting the values of the keys of the given
+// redactions.
+func normalizeJson(raw []byte, redactions []Redaction) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return "", fmt.Errorf("failed to parse JSON for snapshot: %w", err)
+	}
+
+	// Not escaping HTML characters keeps the snapshots readable, e.g. for redacted values and Terraform expressions
+	var normalized bytes.Buffer
+	encoder := json.NewEncoder(&normalized)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(redactKeys(value, redactions)); err != nil {
+		return "", err
+	}
+	return normalized.String(), nil
+}
+
+// normalizeYaml formats each document of the given YAML with sorted mapping keys, after redacting the values of the
+// keys of the given redactions. Empty documents are dropped.
+func normalizeYaml(raw []byte, redactions []Redaction) (string, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(raw))
+	var documents []string
+	for {
+		var value interface{}
+		err := decoder.Decode(&value)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to parse YAML for snapshot: %w", err)
+		}
+		if value == nil {
+			continue
+		}
+
+		var document bytes.Buffer
+		encoder := yaml.NewEncoder(&document)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(redactKeys(value, redactions)); err != nil {
+			return "", err
+		}
+		if err := encoder.Close(); err != nil {
+			return "", err
+		}
+		documents = append(documents, document.String())
+	}
+	return strings.Join(documents, "---\n"), nil
+}
+
+// redactKeys returns the given parsed JSON or YAML value, with the values of the keys of the given redactions
+// replaced, at any depth.
+func redactKeys(value interface{}, redactions []Redaction) interface{} {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		for key, keyValue := range typedValue {
+			typedValue[key] = redactKey(key, keyValue, redactions)
+		}
+	case map[interface{}]interface{}:
+		for key, keyValue := range typedValue {
+			typedValue[key] = redactKey(fmt.Sprint(key), keyValue, redactions)
+		}
+	case []interface{}:
+		for i, item := range typedValue {
+			typedValue[i] = redactKeys(item, redactions)
+		}
+	}
+	return value
+}
+
+// redactKey returns the replacement of the first of the given redactions with the given key, or the given value with
+// the keys of its own fields redacted.
+func redactKey(key string, value interface{}, redactions []Redaction) interface{} {
+	for _, redaction := range redactions {
+		for _, redactedKey := range redaction.Keys {
+			if key == redactedKey {
+				return redaction.replacement()
+			}
+		}
+	}
+	return redactKeys(value, redactions)
+}
+
+func (redaction Redaction) replacement() string {
+	if redaction.Replacement == "" {
+		return DefaultReplacement
+	}
+	return redaction.Replacement
+}
+
+// dataBytes returns the given strings and byte slices as they are, and the JSON of other values.
+func dataBytes(data interface{}) ([]byte, error) {
+	switch typedData := data.(type) {
+	case string:
+		return []byte(typedData), nil
+	case []byte:
+		return typedData, nil
+	default:
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %T to JSON for snapshot: %w", data, err)
+		}
+		return raw, nil
+	}
+}
+
+// defaultFormat returns FormatText for strings and byte slices, and FormatJSON for other values.
+func defaultFormat(data interface{}) Format {
+	switch data.(type) {
+	case string, []byte:
+		return FormatText
+	default:
+		return FormatJSON
+	}
+}
+
+// unsafeFileNameChars matches the characters of the parts of test and snapshot names that are replaced in the paths
+// of the snapshots.
+var unsafeFileNameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+// snapshotPath returns the path of the snapshot with the given name of the given test, e.g.
+// testdata/snapshots/TestPlan/plan.json. The parts of the test and snapshot names separated by slashes are sanitized,
+// so that the snapshot stays in the snapshot folder.
+func snapshotPath(t testing.TestingT, options *Options, name string, format Format) (string, error) {
+	dir := options.Dir
+	if dir == "" {
+		dir = DefaultDir
+	}
+	if name == "" {
+		return "", errors.New("the snapshot name must not be empty")
+	}
+
+	extension := ".txt"
+	switch format {
+	case FormatJSON:
+		extension = ".json"
+	case FormatYAML:
+		extension = ".yaml"
+	}
+	parts := append(sanitizePathParts(t.Name()), sanitizePathParts(name+extension)...)
+	path := filepath.Join(append([]string{dir}, parts...)...)
+	if relPath, err := filepath.Rel(dir, path); err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("snapshot %s of test %s is not in the snapshot folder %s", name, t.Name(), dir)
+	}
+	return path, nil
+}
+
+// sanitizePathParts returns the parts of the given name separated by slashes, with the characters that are unsafe in
+// file names replaced, and no empty, "." or ".." parts.
+func sanitizePathParts(name string) []string {
+	parts := strings.Split(name, "/")
+	for i, part := range parts {
+		part = unsafeFileNameChars.ReplaceAllString(part, "_")
+		// Neither empty parts nor "." and ".." must be path elements
+		if strings.Trim(part, ".") == "" {
+			part = strings.ReplaceAll(part, ".", "_") + "_"
+		}
+		parts[i] = part
+	}
+	return parts
+}
+
+// updateSnapshotsFromEnv returns true if the UPDATE_SNAPSHOTS environment variable is set to true.
+func updateSnapshotsFromEnv() bool {
+	update, err := strconv.ParseBool(os.Getenv(UpdateSnapshotsEnvVar))
+	return err == nil && update
+}
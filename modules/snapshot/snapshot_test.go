@@ -0,0 +1,134 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeJson(t *testing.T) {
+	t.Parallel()
+
+	normalized, err := Normalize(FormatJSON, `{"resource_changes": [{"id": "i-0123", "address": "aws_instance.web"}], "format_version": "1.2", "timestamp": "2024-05-01T10:11:12Z", "big": 12345678901234567890}`,
+		RedactKeys("id"), RedactTimestamps)
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "big": 12345678901234567890,
+  "format_version": "1.2",
+  "resource_changes": [
+    {
+      "address": "aws_instance.web",
+      "id": "<redacted>"
+    }
+  ],
+  "timestamp": "<timestamp>"
+}
+`, normalized)
+
+	normalized, err = Normalize(FormatJSON, map[string]interface{}{"b": 1, "a": []string{"x"}})
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"a\": [\n    \"x\"\n  ],\n  \"b\": 1\n}\n", normalized)
+
+	_, err = Normalize(FormatJSON, "not json")
+	assert.Error(t, err)
+}
+
+func TestNormalizeYaml(t *testing.T) {
+	t.Parallel()
+
+	rendered := `---
+# Source: chart/templates/service.yaml
+kind: Service
+metadata:
+  name: web
+  uid: 0b5c1f6e-8f52-4a57-9e1c-8f0f4c1c2b3a
+apiVersion: v1
+---
+---
+kind: Deployment
+apiVersion: apps/v1
+spec:
+  replicas: 2
+`
+	normalized, err := Normalize(FormatYAML, rendered, RedactKubernetesMetadata)
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  uid: <redacted>
+---
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  replicas: 2
+`, normalized)
+}
+
+func TestNormalizeText(t *testing.T) {
+	t.Parallel()
+
+	normalized, err := Normalize(FormatText, []byte("NAME   READY\r\nweb-7f9c 1/1\r\n\r\n"), RedactPattern(`web-[0-9a-f]+`))
+	require.NoError(t, err)
+	assert.Equal(t, "NAME   READY\n<redacted> 1/1\n", normalized)
+}
+
+func TestMatchSnapshot(t *testing.T) {
+	t.Parallel()
+
+	options := &Options{Dir: t.TempDir(), Redactions: []Redaction{RedactUUIDs}}
+	response := map[string]interface{}{"id": "0b5c1f6e-8f52-4a57-9e1c-8f0f4c1c2b3a", "status": "ok"}
+
+	var notFound SnapshotNotFound
+	require.ErrorAs(t, MatchSnapshotWithOptionsE(t, options, "response", response), &notFound)
+	assert.Equal(t, filepath.Join(options.Dir, "TestMatchSnapshot", "response.json"), notFound.Path)
+
+	options.Update = true
+	MatchSnapshotWithOptions(t, options, "response", response)
+	snapshot, err := os.ReadFile(notFound.Path)
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"id\": \"<uuid>\",\n  \"status\": \"ok\"\n}\n", string(snapshot))
+
+	options.Update = false
+	response["id"] = "5d2e9a77-3c41-4c3e-b6a4-2f1d0c9e8b7a"
+	MatchSnapshotWithOptions(t, options, "response", response)
+
+	response["status"] = "failed"
+	var mismatch SnapshotMismatch
+	require.ErrorAs(t, MatchSnapshotWithOptionsE(t, options, "response", response), &mismatch)
+	assert.Contains(t, mismatch.Diff, `-  "status": "ok"`)
+	assert.Contains(t, mismatch.Diff, `+  "status": "failed"`)
+}
+
+func TestMatchSnapshotUpdateFromEnv(t *testing.T) {
+	t.Setenv(UpdateSnapshotsEnvVar, "true")
+
+	options := &Options{Dir: t.TempDir(), Format: FormatYAML}
+	MatchSnapshotWithOptions(t, options, "deployment", "kind: Deployment\n")
+	assert.FileExists(t, filepath.Join(options.Dir, "TestMatchSnapshotUpdateFromEnv", "deployment.yaml"))
+
+	t.Setenv(UpdateSnapshotsEnvVar, "false")
+	MatchSnapshotWithOptions(t, options, "deployment", "kind: Deployment")
+	assert.Error(t, MatchSnapshotWithOptionsE(t, options, "deployment", "kind: Service"))
+}
+
+func TestMatchSnapshotStaysInDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	options := &Options{Dir: filepath.Join(dir, "snapshots"), Update: true}
+
+	t.Run("..", func(t *testing.T) {
+		MatchSnapshotWithOptions(t, options, "../../escaped", "data")
+	})
+	MatchSnapshotWithOptions(t, options, `..\plans/./web`, "data")
+
+	assert.FileExists(t, filepath.Join(options.Dir, "TestMatchSnapshotStaysInDir", "___", "___", "___", "escaped.txt"))
+	assert.FileExists(t, filepath.Join(options.Dir, "TestMatchSnapshotStaysInDir", ".._plans", "__", "web.txt"))
+	assert.NoFileExists(t, filepath.Join(dir, "escaped.txt"))
+
+	assert.Error(t, MatchSnapshotWithOptionsE(t, options, "", "data"))
+}
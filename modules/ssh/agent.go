@@ -1,8 +1,7 @@
 package ssh
 
 import (
-	"crypto/x509"
-	"encoding/pem"
+	"crypto/ed25519"
 	"io"
 	"net"
 	"os"
@@ -10,6 +9,7 @@ import (
 
 	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/testing"
+	"golang.org/x/crypto/ssh"
 	"golang.org/x/crypto/ssh/agent"
 )
 
@@ -126,12 +126,15 @@ func SshAgentWithKeyPairsE(t testing.TestingT, keyPairs []*KeyPair) (*SshAgent,
 
 	// add given ssh keys to the newly created agent
 	for _, keyPair := range keyPairs {
-		// Create SSH key for the agent using the given SSH key pair(s)
-		block, _ := pem.Decode([]byte(keyPair.PrivateKey))
-		privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		// Create SSH key for the agent using the given SSH key pair(s), which may be RSA, ECDSA or ED25519 keys
+		privateKey, err := ssh.ParseRawPrivateKey([]byte(keyPair.PrivateKey))
 		if err != nil {
 			return nil, err
 		}
+		// The agent only supports ED25519 keys by value
+		if ed25519Key, ok := privateKey.(*ed25519.PrivateKey); ok {
+			privateKey = *ed25519Key
+		}
 		key := agent.AddedKey{PrivateKey: privateKey}
 		sshAgent.agent.Add(key)
 	}
@@ -2,6 +2,7 @@ package ssh
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -69,3 +70,75 @@ func TestMultipleSshAgents(t *testing.T) {
 	assert.Equal(t, strings.TrimSpace(keyPair2.PublicKey), keys2[0].String())
 
 }
+
+func TestSshAgentWithED25519AndECDSAKeyPairs(t *testing.T) {
+	t.Parallel()
+
+	keyPair := GenerateED25519KeyPair(t)
+	keyPair2 := GenerateECDSAKeyPair(t, 256)
+	sshAgent := SshAgentWithKeyPairs(t, []*KeyPair{keyPair, keyPair2})
+	defer sshAgent.Stop()
+
+	keys, err := sshAgent.agent.List()
+	assert.NoError(t, err)
+	assert.Len(t, keys, 2)
+	assert.Equal(t, strings.TrimSpace(keyPair.PublicKey), keys[0].String())
+	assert.Equal(t, strings.TrimSpace(keyPair2.PublicKey), keys[1].String())
+}
+
+func TestForwardSshAgent(t *testing.T) {
+	t.Parallel()
+	requireSshAdd(t)
+
+	keyPair := GenerateED25519KeyPair(t)
+	sshAgent := SshAgentWithKeyPair(t, keyPair)
+	defer sshAgent.Stop()
+
+	host := startTestSshServer(t)
+	host.Password = ""
+	host.OverrideSshAgent = sshAgent
+	host.ForwardSshAgent = true
+
+	// The key of the agent is listed on the host through the forwarded agent
+	out := CheckSshCommand(t, host, "ssh-add -L")
+	assert.Equal(t, strings.TrimSpace(keyPair.PublicKey), strings.TrimSpace(out))
+}
+
+func TestForwardSshAgentWithoutLocalAgent(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	host := Host{Hostname: "10.0.1.10", Password: "password", ForwardSshAgent: true}
+	_, err := CheckSshCommandE(t, host, "ssh-add -L")
+	assert.EqualError(t, err, "SSH_AUTH_SOCK is not set, start an SSH agent or use OverrideSshAgent")
+}
+
+func TestForwardSshAgentThroughJumpHosts(t *testing.T) {
+	t.Parallel()
+	requireSshAdd(t)
+
+	keyPair := GenerateED25519KeyPair(t)
+	sshAgent := SshAgentWithKeyPair(t, keyPair)
+	defer sshAgent.Stop()
+
+	// Every hop authenticates with the keys of the agent
+	host := startTestSshServer(t)
+	host.Password = ""
+	host.OverrideSshAgent = sshAgent
+	host.ForwardSshAgent = true
+	for i := 0; i < 2; i++ {
+		jumpHost := startTestSshServer(t)
+		jumpHost.Password = ""
+		jumpHost.OverrideSshAgent = sshAgent
+		host.JumpHosts = append(host.JumpHosts, jumpHost)
+	}
+
+	out := CheckSshCommand(t, host, "ssh-add -L")
+	assert.Equal(t, strings.TrimSpace(keyPair.PublicKey), strings.TrimSpace(out))
+}
+
+// requireSshAdd skips the test if ssh-add, which lists the keys of the forwarded agent, is not installed.
+func requireSshAdd(t *testing.T) {
+	if _, err := exec.LookPath("ssh-add"); err != nil {
+		t.Skip("ssh-add is not installed")
+	}
+}
@@ -0,0 +1,199 @@
+package ssh
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"golang.org/x/crypto/ssh"
+)
+
+// HostCommandResult is the result of a command run on one of the hosts by RunCommandOnHosts.
+type HostCommandResult struct {
+	Host     Host
+	Stdout   string
+	Stderr   string
+	ExitCode int   // the exit status of the command, or -1 if it could not be run
+	Err      error // the error connecting to the host, or running the command, if any
+}
+
+// HostsCommandResult is the result of a command run on many hosts by RunCommandOnHosts.
+type HostsCommandResult struct {
+	Command string
+	Results []*HostCommandResult // in the order of the hosts
+}
+
+// RunCommandOnHosts connects to each of the given hosts via SSH, with up to concurrency connections at once, and runs
+// the given command. This will fail the test if the command fails on any of the hosts.
+func RunCommandOnHosts(t testing.TestingT, hosts []Host, command string, concurrency int) *HostsCommandResult {
+	result, err := RunCommandOnHostsE(t, hosts, command, concurrency)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+// RunCommandOnHostsE connects to each of the given hosts via SSH, with up to concurrency connections at once (all
+// of them if concurrency is zero), and runs the given command. This returns the stdout, stderr and exit status of the
+// command on every host, along with an error listing the hosts it failed on, if any.
+func RunCommandOnHostsE(t testing.TestingT, hosts []Host, command string, concurrency int) (*HostsCommandResult, error) {
+	if concurrency <= 0 {
+		concurrency = len(hosts)
+	}
+
+	result := &HostsCommandResult{Command: command, Results: make([]*HostCommandResult, len(hosts))}
+	semaphore := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func(i int, host Host) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			result.Results[i] = runCommandOnHost(t, host, command)
+		}(i, host)
+	}
+	wg.Wait()
+
+	return result, result.AllSucceededE()
+}
+
+// runCommandOnHost connects to the given host via SSH and runs the given command, keeping its stdout and stderr apart.
+func runCommandOnHost(t testing.TestingT, host Host, command string) *HostCommandResult {
+	result := &HostCommandResult{Host: host, ExitCode: -1}
+
+	hostOptions, err := createSshConnectionOptionsForHost(host, command)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	sshSession := &SshSession{
+		Options:  hostOptions,
+		JumpHost: &JumpHostSession{},
+	}
+
+	defer sshSession.Cleanup(t)
+
+	logger.Default.Logf(t, "Running command %s on %s@%s", command, host.SshUserName, host.Hostname)
+	if err := setUpSSHClient(sshSession); err != nil {
+		result.Err = err
+		return result
+	}
+
+	if err := setUpSSHSession(sshSession); err != nil {
+		result.Err = err
+		return result
+	}
+
+	var stdout, stderr bytes.Buffer
+	sshSession.Session.Stdout = &stdout
+	sshSession.Session.Stderr = &stderr
+
+	err = sshSession.Session.Run(command)
+	result.Stdout = stdout.String()
+	result.Stderr = stderr.String()
+	result.Err = err
+
+	var exitErr *ssh.ExitError
+	switch {
+	case err == nil:
+		result.ExitCode = 0
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitStatus()
+	}
+	return result
+}
+
+// Failed returns the results of the hosts the command failed on.
+func (result *HostsCommandResult) Failed() []*HostCommandResult {
+	failed := []*HostCommandResult{}
+	for _, hostResult := range result.Results {
+		if hostResult.Err != nil {
+			failed = append(failed, hostResult)
+		}
+	}
+	return failed
+}
+
+// AssertAllSucceeded fails the test if the command failed on any of the hosts.
+func (result *HostsCommandResult) AssertAllSucceeded(t testing.TestingT) {
+	if err := result.AllSucceededE(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// AllSucceededE returns an error listing the hosts the command failed on, with its stderr, if any.
+func (result *HostsCommandResult) AllSucceededE() error {
+	failed := result.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+
+	messages := []string{}
+	for _, hostResult := range failed {
+		message := fmt.Sprintf("%s: %v", hostResult.Host.Hostname, hostResult.Err)
+		if stderr := strings.TrimSpace(hostResult.Stderr); stderr != "" {
+			message = fmt.Sprintf("%s: %s", message, stderr)
+		}
+		messages = append(messages, message)
+	}
+	return fmt.Errorf("command %s failed on %d of %d hosts: %s", result.Command, len(failed), len(result.Results), strings.Join(messages, "; "))
+}
+
+// AssertAllStdoutContains fails the test if the stdout of the command doesn't contain the given text on any of the hosts.
+func (result *HostsCommandResult) AssertAllStdoutContains(t testing.TestingT, text string) {
+	if err := result.AllStdoutContainsE(text); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// AllStdoutContainsE returns an error listing the hosts on which the stdout of the command doesn't contain the given
+// text, if any.
+func (result *HostsCommandResult) AllStdoutContainsE(text string) error {
+	hostnames := []string{}
+	for _, hostResult := range result.Results {
+		if !strings.Contains(hostResult.Stdout, text) {
+			hostnames = append(hostnames, hostResult.Host.Hostname)
+		}
+	}
+	if len(hostnames) > 0 {
+		return fmt.Errorf("stdout of command %s does not contain %q on %d of %d hosts: %s", result.Command, text, len(hostnames), len(result.Results), strings.Join(hostnames, ", "))
+	}
+	return nil
+}
+
+// AssertSameStdout fails the test if the stdout of the command differs between the hosts, e.g. to check that all the
+// instances of an auto scaling group run the same version of a package.
+func (result *HostsCommandResult) AssertSameStdout(t testing.TestingT) {
+	if err := result.SameStdoutE(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// SameStdoutE returns an error listing the hosts by stdout of the command, if it differs between the hosts.
+func (result *HostsCommandResult) SameStdoutE() error {
+	hostnamesByStdout := map[string][]string{}
+	stdouts := []string{}
+	for _, hostResult := range result.Results {
+		if _, ok := hostnamesByStdout[hostResult.Stdout]; !ok {
+			stdouts = append(stdouts, hostResult.Stdout)
+		}
+		hostnamesByStdout[hostResult.Stdout] = append(hostnamesByStdout[hostResult.Stdout], hostResult.Host.Hostname)
+	}
+	if len(stdouts) <= 1 {
+		return nil
+	}
+
+	messages := []string{}
+	for _, stdout := range stdouts {
+		messages = append(messages, fmt.Sprintf("%q on %s", strings.TrimSpace(stdout), strings.Join(hostnamesByStdout[stdout], ", ")))
+	}
+	return fmt.Errorf("stdout of command %s differs between hosts: %s", result.Command, strings.Join(messages, "; "))
+}
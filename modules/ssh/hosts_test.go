@@ -0,0 +1,59 @@
+package ssh
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCommandOnHosts(t *testing.T) {
+	t.Parallel()
+
+	hosts := []Host{startTestSshServer(t), startTestSshServer(t), startTestSshServer(t)}
+
+	result := RunCommandOnHosts(t, hosts, "echo configured; echo warning >&2", 2)
+	require.Len(t, result.Results, 3)
+	for i, hostResult := range result.Results {
+		assert.Equal(t, hosts[i].CustomPort, hostResult.Host.CustomPort)
+		assert.Equal(t, "configured\n", hostResult.Stdout)
+		assert.Equal(t, "warning\n", hostResult.Stderr)
+		assert.Equal(t, 0, hostResult.ExitCode)
+	}
+	result.AssertAllSucceeded(t)
+	result.AssertAllStdoutContains(t, "configured")
+	result.AssertSameStdout(t)
+}
+
+func TestRunCommandOnHostsEWithFailures(t *testing.T) {
+	t.Parallel()
+
+	unreachable := Host{Hostname: "127.0.0.1", CustomPort: 1, Password: "password"}
+	hosts := []Host{startTestSshServer(t), unreachable}
+
+	result, err := RunCommandOnHostsE(t, hosts, "echo not configured >&2; exit 2", 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed on 2 of 2 hosts")
+	assert.Contains(t, err.Error(), "not configured")
+
+	assert.Equal(t, 2, result.Results[0].ExitCode)
+	assert.Equal(t, "not configured\n", result.Results[0].Stderr)
+	assert.Equal(t, -1, result.Results[1].ExitCode)
+	assert.Len(t, result.Failed(), 2)
+}
+
+func TestHostsCommandResultSameStdoutE(t *testing.T) {
+	t.Parallel()
+
+	result := &HostsCommandResult{
+		Command: "cat /etc/app/version",
+		Results: []*HostCommandResult{
+			{Host: Host{Hostname: "10.0.1.10"}, Stdout: "1.2.0\n"},
+			{Host: Host{Hostname: "10.0.1.11"}, Stdout: "1.1.0\n"},
+			{Host: Host{Hostname: "10.0.1.12"}, Stdout: "1.2.0\n"},
+		},
+	}
+
+	assert.EqualError(t, result.SameStdoutE(), `stdout of command cat /etc/app/version differs between hosts: "1.2.0" on 10.0.1.10, 10.0.1.12; "1.1.0" on 10.0.1.11`)
+	assert.EqualError(t, result.AllStdoutContainsE("1.2.0"), `stdout of command cat /etc/app/version does not contain "1.2.0" on 1 of 3 hosts: 10.0.1.11`)
+}
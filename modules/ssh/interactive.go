@@ -0,0 +1,262 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// The size of the terminal of interactive sessions
+	interactiveTerminalRows    = 40
+	interactiveTerminalColumns = 120
+)
+
+// InteractiveSession is an SSH session with a pseudo terminal, to drive interactive commands (e.g. installers, sudo
+// password prompts, CLI wizards) by waiting for their output with Expect and answering with Send.
+type InteractiveSession struct {
+	sshSession *SshSession
+	stdin      io.WriteCloser
+	output     *interactiveOutput
+	// The position in the output up to which the output has been matched by Expect
+	offset int
+}
+
+// StartInteractiveSession connects to the given host via SSH and starts the given command in a pseudo terminal, or the
+// login shell of the user if the command is empty. This will fail the test if there is an error. You should close the
+// session afterwards by calling `defer session.Close(t)`.
+func StartInteractiveSession(t testing.TestingT, host Host, command string) *InteractiveSession {
+	session, err := StartInteractiveSessionE(t, host, command)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return session
+}
+
+// StartInteractiveSessionE connects to the given host via SSH and starts the given command in a pseudo terminal, or the
+// login shell of the user if the command is empty. The terminal doesn't echo the input, so the output only contains
+// what the command writes. You should close the session afterwards by calling `defer session.Close(t)`.
+func StartInteractiveSessionE(t testing.TestingT, host Host, command string) (*InteractiveSession, error) {
+	hostOptions, err := createSshConnectionOptionsForHost(host, command)
+	if err != nil {
+		return nil, err
+	}
+
+	sshSession := &SshSession{
+		Options:  hostOptions,
+		JumpHost: &JumpHostSession{},
+	}
+
+	session, err := startInteractiveSessionE(t, sshSession)
+	if err != nil {
+		sshSession.Cleanup(t)
+		return nil, err
+	}
+	return session, nil
+}
+
+func startInteractiveSessionE(t testing.TestingT, sshSession *SshSession) (*InteractiveSession, error) {
+	logger.Default.Logf(t, "Starting interactive session with command %s on %s@%s", sshSession.Options.Command, sshSession.Options.Username, sshSession.Options.Address)
+	if err := setUpSSHClient(sshSession); err != nil {
+		return nil, err
+	}
+
+	if err := setUpSSHSession(sshSession); err != nil {
+		return nil, err
+	}
+
+	modes := ssh.TerminalModes{
+		ssh.ECHO:          0,
+		ssh.TTY_OP_ISPEED: 14400,
+		ssh.TTY_OP_OSPEED: 14400,
+	}
+	if err := sshSession.Session.RequestPty("xterm", interactiveTerminalRows, interactiveTerminalColumns, modes); err != nil {
+		return nil, err
+	}
+
+	stdin, err := sshSession.Session.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	// The pseudo terminal merges stderr into stdout
+	output := newInteractiveOutput()
+	sshSession.Session.Stdout = output
+	sshSession.Session.Stderr = output
+
+	if sshSession.Options.Command == "" {
+		err = sshSession.Session.Shell()
+	} else {
+		err = sshSession.Session.Start(sshSession.Options.Command)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		output.close(sshSession.Session.Wait())
+	}()
+
+	return &InteractiveSession{
+		sshSession: sshSession,
+		stdin:      stdin,
+		output:     output,
+	}, nil
+}
+
+// Expect waits until the output of the command that follows the previous match matches the given regex, and returns
+// the matching text. This will fail the test if the output doesn't match before the timeout.
+func (session *InteractiveSession) Expect(t testing.TestingT, regex string, timeout time.Duration) string {
+	match, err := session.ExpectE(regex, timeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return match
+}
+
+// ExpectE waits until the output of the command that follows the previous match matches the given regex, and returns
+// the matching text. Subsequent calls only look at the output after the match. This returns an error, with the
+// unmatched output, if the output doesn't match before the timeout or the command exits.
+func (session *InteractiveSession) ExpectE(regex string, timeout time.Duration) (string, error) {
+	re, err := regexp.Compile(regex)
+	if err != nil {
+		return "", err
+	}
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		output, updated, done := session.output.snapshot()
+		unmatched := output[session.offset:]
+		if location := re.FindStringIndex(unmatched); location != nil {
+			session.offset += location[1]
+			return unmatched[location[0]:location[1]], nil
+		}
+		if done {
+			return "", fmt.Errorf("command exited before its output matched %s. Unmatched output:\n%s", regex, unmatched)
+		}
+
+		select {
+		case <-updated:
+		case <-deadline.C:
+			return "", fmt.Errorf("timed out after %s waiting for output matching %s. Unmatched output:\n%s", timeout, regex, unmatched)
+		}
+	}
+}
+
+// Send writes the given text to the input of the command. This will fail the test if there is an error.
+func (session *InteractiveSession) Send(t testing.TestingT, text string) {
+	if err := session.SendE(text); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// SendE writes the given text to the input of the command.
+func (session *InteractiveSession) SendE(text string) error {
+	_, err := io.WriteString(session.stdin, text)
+	return err
+}
+
+// SendLine writes the given text followed by a newline to the input of the command, e.g. to answer a prompt. This will
+// fail the test if there is an error.
+func (session *InteractiveSession) SendLine(t testing.TestingT, text string) {
+	session.Send(t, text+"\n")
+}
+
+// Output returns all the output of the command so far.
+func (session *InteractiveSession) Output() string {
+	output, _, _ := session.output.snapshot()
+	return output
+}
+
+// Wait waits until the command exits, up to the given timeout. This will fail the test if the command fails or doesn't
+// exit before the timeout.
+func (session *InteractiveSession) Wait(t testing.TestingT, timeout time.Duration) {
+	if err := session.WaitE(timeout); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// WaitE waits until the command exits, up to the given timeout, and returns an error if the command fails (which is an
+// *ssh.ExitError for a non zero exit status) or doesn't exit before the timeout.
+func (session *InteractiveSession) WaitE(timeout time.Duration) error {
+	select {
+	case <-session.output.done:
+		return session.output.err
+	case <-time.After(timeout):
+		return fmt.Errorf("timed out after %s waiting for the command to exit", timeout)
+	}
+}
+
+// Close closes the input of the command and the SSH session.
+func (session *InteractiveSession) Close(t testing.TestingT) {
+	if session == nil {
+		return
+	}
+
+	Close(t, session.stdin, io.EOF.Error())
+	session.sshSession.Cleanup(t)
+}
+
+// interactiveOutput collects the output of an interactive session and notifies the readers waiting for more.
+type interactiveOutput struct {
+	lock    sync.Mutex
+	buffer  []byte
+	updated chan struct{}
+	done    chan struct{}
+	err     error
+}
+
+func newInteractiveOutput() *interactiveOutput {
+	return &interactiveOutput{
+		updated: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+func (output *interactiveOutput) Write(data []byte) (int, error) {
+	output.lock.Lock()
+	defer output.lock.Unlock()
+
+	output.buffer = append(output.buffer, data...)
+	close(output.updated)
+	output.updated = make(chan struct{})
+	return len(data), nil
+}
+
+// close records that the command exited with the given error, which is ignored if the session was closed first.
+func (output *interactiveOutput) close(err error) {
+	var missingErr *ssh.ExitMissingError
+	if errors.As(err, &missingErr) || errors.Is(err, io.EOF) {
+		err = nil
+	}
+
+	output.lock.Lock()
+	defer output.lock.Unlock()
+
+	output.err = err
+	close(output.done)
+	close(output.updated)
+	output.updated = make(chan struct{})
+}
+
+// snapshot returns the output so far, a channel closed when there is more output, and whether the command exited.
+func (output *interactiveOutput) snapshot() (string, <-chan struct{}, bool) {
+	output.lock.Lock()
+	defer output.lock.Unlock()
+
+	select {
+	case <-output.done:
+		return string(output.buffer), output.updated, true
+	default:
+		return string(output.buffer), output.updated, false
+	}
+}
@@ -0,0 +1,58 @@
+package ssh
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestInteractiveSessionExpectAndSend(t *testing.T) {
+	t.Parallel()
+
+	host := startTestSshServer(t)
+	session := StartInteractiveSession(t, host, `printf 'Password: '; read password; printf 'Continue? [y/n] '; read answer; echo "done $password $answer"`)
+	defer session.Close(t)
+
+	session.Expect(t, `Password: $`, 10*time.Second)
+	session.SendLine(t, "s3cr3t")
+	session.Expect(t, `\[y/n\]`, 10*time.Second)
+	session.SendLine(t, "y")
+	assert.Equal(t, "done s3cr3t y", session.Expect(t, `done \S+ \S+`, 10*time.Second))
+
+	session.Wait(t, 10*time.Second)
+	assert.Equal(t, "Password: Continue? [y/n] done s3cr3t y\n", session.Output())
+}
+
+func TestInteractiveSessionExpectETimeout(t *testing.T) {
+	t.Parallel()
+
+	host := startTestSshServer(t)
+	session := StartInteractiveSession(t, host, `printf 'Proceed? '; read answer`)
+	defer session.Close(t)
+
+	session.Expect(t, `Proceed\?`, 10*time.Second)
+
+	// The previous match is consumed, so the prompt doesn't match again
+	_, err := session.ExpectE(`Proceed\?`, 100*time.Millisecond)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out after 100ms")
+}
+
+func TestInteractiveSessionExpectEAfterExit(t *testing.T) {
+	t.Parallel()
+
+	host := startTestSshServer(t)
+	session := StartInteractiveSession(t, host, `echo installing; exit 3`)
+	defer session.Close(t)
+
+	_, err := session.ExpectE(`installed`, 10*time.Second)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "command exited before its output matched installed. Unmatched output:\ninstalling\n")
+
+	var exitErr *ssh.ExitError
+	require.ErrorAs(t, session.WaitE(10*time.Second), &exitErr)
+	assert.Equal(t, 3, exitErr.ExitStatus())
+}
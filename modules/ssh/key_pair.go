@@ -1,10 +1,15 @@
 package ssh
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
+	"fmt"
 
 	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/testing"
@@ -55,3 +60,87 @@ func GenerateRSAKeyPairE(t testing.TestingT, keySize int) (*KeyPair, error) {
 	// Return
 	return &KeyPair{PublicKey: sshPubKeyStr, PrivateKey: keyPem}, nil
 }
+
+// GenerateED25519KeyPair generates an ED25519 Keypair and return the public and private keys.
+func GenerateED25519KeyPair(t testing.TestingT) *KeyPair {
+	keyPair, err := GenerateED25519KeyPairE(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyPair
+}
+
+// GenerateED25519KeyPairE generates an ED25519 Keypair and return the public key in the OpenSSH authorized_keys
+// format and the private key in the OpenSSH format.
+func GenerateED25519KeyPairE(t testing.TestingT) (*KeyPair, error) {
+	logger.Default.Logf(t, "Generating new ED25519 public/private key")
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	// ED25519 keys have no PEM encoding other than the OpenSSH one
+	keyPemBlock, err := ssh.MarshalPrivateKey(privateKey, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return newKeyPair(publicKey, keyPemBlock)
+}
+
+// GenerateECDSAKeyPair generates an ECDSA Keypair on the curve of the given size (256, 384 or 521 bits) and return the
+// public and private keys.
+func GenerateECDSAKeyPair(t testing.TestingT, curveSize int) *KeyPair {
+	keyPair, err := GenerateECDSAKeyPairE(t, curveSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyPair
+}
+
+// GenerateECDSAKeyPairE generates an ECDSA Keypair on the curve of the given size (256, 384 or 521 bits) and return
+// the public key in the OpenSSH authorized_keys format and the private key in the PEM format.
+func GenerateECDSAKeyPairE(t testing.TestingT, curveSize int) (*KeyPair, error) {
+	logger.Default.Logf(t, "Generating new ECDSA public/private key of size %d", curveSize)
+
+	var curve elliptic.Curve
+	switch curveSize {
+	case 256:
+		curve = elliptic.P256()
+	case 384:
+		curve = elliptic.P384()
+	case 521:
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported ECDSA curve size %d, must be 256, 384 or 521", curveSize)
+	}
+
+	ecdsaKeyPair, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	keyBytes, err := x509.MarshalECPrivateKey(ecdsaKeyPair)
+	if err != nil {
+		return nil, err
+	}
+
+	keyPemBlock := &pem.Block{
+		Type:  "EC PRIVATE KEY",
+		Bytes: keyBytes,
+	}
+
+	return newKeyPair(ecdsaKeyPair.Public(), keyPemBlock)
+}
+
+// newKeyPair returns a KeyPair with the given public key in the OpenSSH authorized_keys format and the given PEM
+// encoded private key.
+func newKeyPair(publicKey crypto.PublicKey, keyPemBlock *pem.Block) (*KeyPair, error) {
+	sshPubKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeyPair{PublicKey: string(ssh.MarshalAuthorizedKey(sshPubKey)), PrivateKey: string(pem.EncodeToMemory(keyPemBlock))}, nil
+}
@@ -0,0 +1,217 @@
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// startTestSshServer starts an SSH server on localhost, which runs the commands of exec requests with the local shell
+// and serves the sftp subsystem on the local filesystem, and returns a host to connect to it with a password. Any password or key is accepted.
+func startTestSshServer(t *testing.T) Host {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hostKey, err := ssh.NewSignerFromKey(privateKey)
+	require.NoError(t, err)
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			return nil, nil
+		},
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestSshConn(conn, config)
+		}
+	}()
+
+	return Host{
+		Hostname:    "127.0.0.1",
+		CustomPort:  listener.Addr().(*net.TCPAddr).Port,
+		SshUserName: "terratest",
+		Password:    "terratest",
+	}
+}
+
+func serveTestSshConn(conn net.Conn, config *ssh.ServerConfig) {
+	serverConn, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() == "direct-tcpip" {
+			go serveTestDirectTcpip(newChannel)
+			continue
+		}
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go serveTestSshChannel(serverConn, channel, channelRequests)
+	}
+}
+
+// serveTestDirectTcpip forwards a connection to the address requested by the client, e.g. when the server is a jump
+// host.
+func serveTestDirectTcpip(newChannel ssh.NewChannel) {
+	var target struct {
+		Host       string
+		Port       uint32
+		OriginHost string
+		OriginPort uint32
+	}
+	if err := ssh.Unmarshal(newChannel.ExtraData(), &target); err != nil {
+		newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	conn, err := net.Dial("tcp", net.JoinHostPort(target.Host, strconv.Itoa(int(target.Port))))
+	if err != nil {
+		newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(requests)
+
+	defer conn.Close()
+	defer channel.Close()
+	go io.Copy(channel, conn)
+	io.Copy(conn, channel)
+}
+
+func serveTestSshChannel(serverConn *ssh.ServerConn, channel ssh.Channel, requests <-chan *ssh.Request) {
+	forwardAgent := false
+	for request := range requests {
+		switch request.Type {
+		case "pty-req":
+			request.Reply(true, nil)
+		case "auth-agent-req@openssh.com":
+			forwardAgent = true
+			request.Reply(true, nil)
+		case "subsystem":
+			isSftp := string(request.Payload[4:]) == "sftp"
+			request.Reply(isSftp, nil)
+			if isSftp {
+				go func() {
+					defer channel.Close()
+					server, err := sftp.NewServer(channel)
+					if err == nil {
+						server.Serve()
+					}
+				}()
+			}
+		case "exec":
+			request.Reply(true, nil)
+			go runTestSshCommand(serverConn, channel, string(request.Payload[4:]), forwardAgent)
+		default:
+			request.Reply(false, nil)
+		}
+	}
+}
+
+func runTestSshCommand(serverConn *ssh.ServerConn, channel ssh.Channel, command string, forwardAgent bool) {
+	defer channel.Close()
+
+	cmd := exec.Command("sh", "-c", command)
+	if forwardAgent {
+		socket, err := serveTestForwardedAgent(serverConn)
+		if err != nil {
+			return
+		}
+		defer os.RemoveAll(filepath.Dir(socket))
+		cmd.Env = append(os.Environ(), "SSH_AUTH_SOCK="+socket)
+	}
+	cmd.Stdout = channel
+	cmd.Stderr = channel.Stderr()
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return
+	}
+	go func() {
+		defer stdin.Close()
+		io.Copy(stdin, channel)
+	}()
+
+	exitStatus := uint32(0)
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return
+		}
+		exitStatus = uint32(exitErr.ExitCode())
+	}
+
+	payload := make([]byte, 4)
+	binary.BigEndian.PutUint32(payload, exitStatus)
+	channel.SendRequest("exit-status", false, payload)
+}
+
+// serveTestForwardedAgent listens on a local socket, like sshd, and relays its connections to the agent forwarded by
+// the client, and returns the socket.
+func serveTestForwardedAgent(serverConn *ssh.ServerConn) (string, error) {
+	dir, err := os.MkdirTemp("", "ssh-agent-forward-")
+	if err != nil {
+		return "", err
+	}
+	socket := filepath.Join(dir, "agent.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return "", err
+	}
+	go func() {
+		defer listener.Close()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			agentChannel, agentRequests, err := serverConn.OpenChannel("auth-agent@openssh.com", nil)
+			if err != nil {
+				conn.Close()
+				return
+			}
+			go ssh.DiscardRequests(agentRequests)
+			go func() {
+				defer conn.Close()
+				defer agentChannel.Close()
+				go io.Copy(agentChannel, conn)
+				io.Copy(conn, agentChannel)
+			}()
+		}
+	}()
+	return socket, nil
+}
@@ -20,6 +20,9 @@ type SshConnectionOptions struct {
 	AuthMethods []ssh.AuthMethod
 	Command     string
 	JumpHost    *SshConnectionOptions
+	Transport   Transport // connects to the host instead of TCP, if this is not connected to through a jump host
+	// The socket of the SSH agent to forward to the host, if any
+	ForwardAgentSocket string
 }
 
 // ConnectionString returns the connection string for an SSH connection.
@@ -36,6 +39,8 @@ type SshSession struct {
 	Session  *ssh.Session
 	JumpHost *JumpHostSession
 	Input    *func(io.WriteCloser)
+	// The client the SSH agent is forwarded by, if any
+	agentForwardedClient *ssh.Client
 }
 
 // Cleanup cleans up an existing SSH session.
@@ -56,6 +61,8 @@ type JumpHostSession struct {
 	JumpHostClient        *ssh.Client
 	HostVirtualConnection net.Conn
 	HostConnection        ssh.Conn
+	// The session with the jump host the jump host is reached through, in a chain of jump hosts
+	JumpHost *JumpHostSession
 }
 
 // Cleanup cleans the jump host session up.
@@ -69,6 +76,7 @@ func (jumpHost *JumpHostSession) Cleanup(t testing.TestingT) {
 	Close(t, jumpHost.HostConnection, io.EOF.Error())
 	Close(t, jumpHost.HostVirtualConnection, io.EOF.Error())
 	Close(t, jumpHost.JumpHostClient)
+	jumpHost.JumpHost.Cleanup(t)
 }
 
 // Closeable can be closed.
@@ -0,0 +1,223 @@
+package ssh
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/pkg/sftp"
+)
+
+// UploadFile uploads the local file at localPath to remotePath on the given host using SFTP, with the given
+// permissions, and fails the test if the upload fails.
+func UploadFile(t testing.TestingT, host Host, localPath string, remotePath string, mode os.FileMode) {
+	err := UploadFileE(t, host, localPath, remotePath, mode)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// UploadFileE uploads the local file at localPath to remotePath on the given host using SFTP, with the given
+// permissions, and returns an error if the upload fails. The parent directories of remotePath are created if they don't
+// exist. The uploaded file is read back to verify that its checksum matches the checksum of the local file.
+func UploadFileE(t testing.TestingT, host Host, localPath string, remotePath string, mode os.FileMode) error {
+	return withSftpClientE(t, host, func(client *sftp.Client) error {
+		if err := client.MkdirAll(path.Dir(remotePath)); err != nil {
+			return err
+		}
+		return uploadFileE(t, client, host, localPath, remotePath, mode)
+	})
+}
+
+// DownloadFile downloads the file at remotePath on the given host to localPath using SFTP, and fails the test if the
+// download fails.
+func DownloadFile(t testing.TestingT, host Host, remotePath string, localPath string) {
+	err := DownloadFileE(t, host, remotePath, localPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// DownloadFileE downloads the file at remotePath on the given host to localPath using SFTP, and returns an error if
+// the download fails. The local file gets the permissions of the remote file, and its checksum is verified against the
+// checksum of the remote file.
+func DownloadFileE(t testing.TestingT, host Host, remotePath string, localPath string) error {
+	return withSftpClientE(t, host, func(client *sftp.Client) error {
+		logger.Default.Logf(t, "Downloading %s@%s:%s to %s", host.SshUserName, host.Hostname, remotePath, localPath)
+
+		remoteFile, err := client.Open(remotePath)
+		if err != nil {
+			return err
+		}
+		defer remoteFile.Close()
+
+		info, err := remoteFile.Stat()
+		if err != nil {
+			return err
+		}
+
+		localFile, err := os.OpenFile(localPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		defer localFile.Close()
+
+		if _, err := io.Copy(localFile, remoteFile); err != nil {
+			return err
+		}
+		// The permissions of OpenFile don't apply to existing files, and are subject to the umask
+		if err := localFile.Chmod(info.Mode().Perm()); err != nil {
+			return err
+		}
+
+		return verifyChecksumE(client, localPath, remotePath)
+	})
+}
+
+// UploadDir uploads the files of the local directory localDir, recursively, to remoteDir on the given host using
+// SFTP, and fails the test if the upload fails.
+func UploadDir(t testing.TestingT, host Host, localDir string, remoteDir string) {
+	err := UploadDirE(t, host, localDir, remoteDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// UploadDirE uploads the files of the local directory localDir, recursively, to remoteDir on the given host using
+// SFTP, and returns an error if the upload fails. The files and directories keep their permissions, and the checksum
+// of each file is verified after its upload. NOTE: symlinks are not followed and are skipped.
+func UploadDirE(t testing.TestingT, host Host, localDir string, remoteDir string) error {
+	return withSftpClientE(t, host, func(client *sftp.Client) error {
+		return filepath.WalkDir(localDir, func(localPath string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			relPath, err := filepath.Rel(localDir, localPath)
+			if err != nil {
+				return err
+			}
+			remotePath := path.Join(remoteDir, filepath.ToSlash(relPath))
+
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case entry.IsDir():
+				if err := client.MkdirAll(remotePath); err != nil {
+					return err
+				}
+				return client.Chmod(remotePath, info.Mode().Perm())
+			case info.Mode().IsRegular():
+				return uploadFileE(t, client, host, localPath, remotePath, info.Mode().Perm())
+			default:
+				logger.Default.Logf(t, "Skipping %s, which is not a regular file or directory", localPath)
+				return nil
+			}
+		})
+	})
+}
+
+// withSftpClientE connects to the given host, with its chain of jump hosts if any, and calls the given function with
+// an SFTP client over the connection.
+func withSftpClientE(t testing.TestingT, host Host, f func(client *sftp.Client) error) error {
+	hostOptions, err := createSshConnectionOptionsForHost(host, "")
+	if err != nil {
+		return err
+	}
+
+	sshSession := &SshSession{
+		Options:  hostOptions,
+		JumpHost: &JumpHostSession{},
+	}
+
+	defer sshSession.Cleanup(t)
+
+	if err := setUpSSHClient(sshSession); err != nil {
+		return err
+	}
+
+	client, err := sftp.NewClient(sshSession.Client)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	return f(client)
+}
+
+// uploadFileE uploads the local file at localPath to remotePath with the given SFTP client, sets its permissions and
+// verifies its checksum.
+func uploadFileE(t testing.TestingT, client *sftp.Client, host Host, localPath string, remotePath string, mode os.FileMode) error {
+	logger.Default.Logf(t, "Uploading %s to %s@%s:%s", localPath, host.SshUserName, host.Hostname, remotePath)
+
+	localFile, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer localFile.Close()
+
+	remoteFile, err := client.OpenFile(remotePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return err
+	}
+	defer remoteFile.Close()
+
+	if _, err := io.Copy(remoteFile, localFile); err != nil {
+		return err
+	}
+	if err := remoteFile.Chmod(mode); err != nil {
+		return err
+	}
+
+	return verifyChecksumE(client, localPath, remotePath)
+}
+
+// verifyChecksumE returns an error if the SHA-256 checksum of the local file doesn't match the checksum of the remote
+// file, which is read back with the given SFTP client.
+func verifyChecksumE(client *sftp.Client, localPath string, remotePath string) error {
+	localFile, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer localFile.Close()
+
+	localChecksum, err := sha256Checksum(localFile)
+	if err != nil {
+		return err
+	}
+
+	remoteFile, err := client.Open(remotePath)
+	if err != nil {
+		return err
+	}
+	defer remoteFile.Close()
+
+	remoteChecksum, err := sha256Checksum(remoteFile)
+	if err != nil {
+		return err
+	}
+
+	if localChecksum != remoteChecksum {
+		return fmt.Errorf("checksum of %s (%s) does not match checksum of remote file %s (%s)", localPath, localChecksum, remotePath, remoteChecksum)
+	}
+	return nil
+}
+
+// sha256Checksum returns the hex encoded SHA-256 checksum of the contents of the given reader.
+func sha256Checksum(reader io.Reader) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, reader); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,72 @@
+package ssh
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadAndDownloadFile(t *testing.T) {
+	t.Parallel()
+
+	host := startTestSshServer(t)
+	localDir := t.TempDir()
+	remoteDir := t.TempDir()
+
+	localPath := filepath.Join(localDir, "fixture.txt")
+	require.NoError(t, os.WriteFile(localPath, []byte("hello sftp"), 0644))
+
+	remotePath := filepath.Join(remoteDir, "nested", "fixture.sh")
+	UploadFile(t, host, localPath, remotePath, 0750)
+
+	info, err := os.Stat(remotePath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0750), info.Mode().Perm())
+
+	downloadedPath := filepath.Join(localDir, "downloaded.sh")
+	DownloadFile(t, host, remotePath, downloadedPath)
+
+	contents, err := os.ReadFile(downloadedPath)
+	require.NoError(t, err)
+	assert.Equal(t, "hello sftp", string(contents))
+	info, err = os.Stat(downloadedPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0750), info.Mode().Perm())
+}
+
+func TestUploadDir(t *testing.T) {
+	t.Parallel()
+
+	host := startTestSshServer(t)
+	localDir := t.TempDir()
+	remoteDir := filepath.Join(t.TempDir(), "fixtures")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(localDir, "config"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, "run.sh"), []byte("#!/bin/sh"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, "config", "app.conf"), []byte("port=8080"), 0600))
+
+	UploadDir(t, host, localDir, remoteDir)
+
+	contents, err := os.ReadFile(filepath.Join(remoteDir, "config", "app.conf"))
+	require.NoError(t, err)
+	assert.Equal(t, "port=8080", string(contents))
+
+	info, err := os.Stat(filepath.Join(remoteDir, "config", "app.conf"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	info, err = os.Stat(filepath.Join(remoteDir, "run.sh"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+}
+
+func TestDownloadFileEMissingFile(t *testing.T) {
+	t.Parallel()
+
+	host := startTestSshServer(t)
+
+	err := DownloadFileE(t, host, filepath.Join(t.TempDir(), "missing.log"), filepath.Join(t.TempDir(), "missing.log"))
+	assert.Error(t, err)
+}
@@ -32,6 +32,15 @@ type Host struct {
 	OverrideSshAgent *SshAgent // enable an in process `SshAgent` for connections to this host (disabled by default)
 	Password         string    // plain text password (blank by default)
 	CustomPort       int       // port number to use to connect to the host (port 22 will be used if unset)
+	// ordered chain of jump hosts (bastions) to connect to the host through, each with its own credentials: the first
+	// one is connected to directly, each of the others through the previous one, and the host through the last one
+	JumpHosts []Host
+	// transport to connect to the host, or to the first of its jump hosts if that one has no transport of its own,
+	// instead of TCP (e.g. an AWS SSM session)
+	Transport Transport
+	// forward the SSH agent (OverrideSshAgent if set, your existing local SSH agent otherwise) to the host, so commands
+	// on the host can use its keys, e.g. to SSH to other hosts or clone private repos (disabled by default)
+	ForwardSshAgent bool
 }
 
 type ScpDownloadOptions struct {
@@ -52,24 +61,17 @@ func ScpFileTo(t testing.TestingT, host Host, mode os.FileMode, remotePath, cont
 
 // ScpFileToE uploads the contents using SCP to the given host and return an error if the process fails.
 func ScpFileToE(t testing.TestingT, host Host, mode os.FileMode, remotePath, contents string) error {
-	authMethods, err := createAuthMethodsForHost(host)
-	if err != nil {
-		return err
-	}
 	dir, file := filepath.Split(remotePath)
 
-	hostOptions := SshConnectionOptions{
-		Username:    host.SshUserName,
-		Address:     host.Hostname,
-		Port:        host.getPort(),
-		Command:     "/usr/bin/scp -t " + dir,
-		AuthMethods: authMethods,
+	hostOptions, err := createSshConnectionOptionsForHost(host, "/usr/bin/scp -t "+dir)
+	if err != nil {
+		return err
 	}
 
 	scp := sendScpCommandsToCopyFile(mode, file, contents)
 
 	sshSession := &SshSession{
-		Options:  &hostOptions,
+		Options:  hostOptions,
 		JumpHost: &JumpHostSession{},
 		Input:    &scp,
 	}
@@ -91,24 +93,15 @@ func ScpFileFrom(t testing.TestingT, host Host, remotePath string, localDestinat
 
 // ScpFileFromE downloads the file from remotePath on the given host using SCP and returns an error if the process fails.
 func ScpFileFromE(t testing.TestingT, host Host, remotePath string, localDestination *os.File, useSudo bool) error {
-	authMethods, err := createAuthMethodsForHost(host)
+	dir := filepath.Dir(remotePath)
 
+	hostOptions, err := createSshConnectionOptionsForHost(host, "/usr/bin/scp -t "+dir)
 	if err != nil {
 		return err
 	}
 
-	dir := filepath.Dir(remotePath)
-
-	hostOptions := SshConnectionOptions{
-		Username:    host.SshUserName,
-		Address:     host.Hostname,
-		Port:        host.getPort(),
-		Command:     "/usr/bin/scp -t " + dir,
-		AuthMethods: authMethods,
-	}
-
 	sshSession := &SshSession{
-		Options:  &hostOptions,
+		Options:  hostOptions,
 		JumpHost: &JumpHostSession{},
 	}
 
@@ -131,21 +124,13 @@ func ScpDirFrom(t testing.TestingT, options ScpDownloadOptions, useSudo bool) {
 // be downloaded. This function will not recursively download subdirectories or follow
 // symlinks.
 func ScpDirFromE(t testing.TestingT, options ScpDownloadOptions, useSudo bool) error {
-	authMethods, err := createAuthMethodsForHost(options.RemoteHost)
+	hostOptions, err := createSshConnectionOptionsForHost(options.RemoteHost, "/usr/bin/scp -t "+options.RemoteDir)
 	if err != nil {
 		return err
 	}
 
-	hostOptions := SshConnectionOptions{
-		Username:    options.RemoteHost.SshUserName,
-		Address:     options.RemoteHost.Hostname,
-		Port:        options.RemoteHost.getPort(),
-		Command:     "/usr/bin/scp -t " + options.RemoteDir,
-		AuthMethods: authMethods,
-	}
-
 	sshSession := &SshSession{
-		Options:  &hostOptions,
+		Options:  hostOptions,
 		JumpHost: &JumpHostSession{},
 	}
 
@@ -238,21 +223,13 @@ func CheckSshCommand(t testing.TestingT, host Host, command string) string {
 
 // CheckSshCommandE checks that you can connect via SSH to the given host and run the given command. Returns the stdout/stderr.
 func CheckSshCommandE(t testing.TestingT, host Host, command string) (string, error) {
-	authMethods, err := createAuthMethodsForHost(host)
+	hostOptions, err := createSshConnectionOptionsForHost(host, command)
 	if err != nil {
 		return "", err
 	}
 
-	hostOptions := SshConnectionOptions{
-		Username:    host.SshUserName,
-		Address:     host.Hostname,
-		Port:        host.getPort(),
-		Command:     command,
-		AuthMethods: authMethods,
-	}
-
 	sshSession := &SshSession{
-		Options:  &hostOptions,
+		Options:  hostOptions,
 		JumpHost: &JumpHostSession{},
 	}
 
@@ -312,6 +289,7 @@ func CheckPrivateSshConnectionE(t testing.TestingT, publicHost Host, privateHost
 		Address:     publicHost.Hostname,
 		Port:        publicHost.getPort(),
 		AuthMethods: jumpHostAuthMethods,
+		Transport:   publicHost.Transport,
 	}
 
 	hostAuthMethods, err := createAuthMethodsForHost(privateHost)
@@ -319,13 +297,19 @@ func CheckPrivateSshConnectionE(t testing.TestingT, publicHost Host, privateHost
 		return "", err
 	}
 
+	forwardAgentSocket, err := getForwardAgentSocketForHost(privateHost)
+	if err != nil {
+		return "", err
+	}
+
 	hostOptions := SshConnectionOptions{
-		Username:    privateHost.SshUserName,
-		Address:     privateHost.Hostname,
-		Port:        privateHost.getPort(),
-		Command:     command,
-		AuthMethods: hostAuthMethods,
-		JumpHost:    &jumpHostOptions,
+		Username:           privateHost.SshUserName,
+		Address:            privateHost.Hostname,
+		Port:               privateHost.getPort(),
+		Command:            command,
+		AuthMethods:        hostAuthMethods,
+		JumpHost:           &jumpHostOptions,
+		ForwardAgentSocket: forwardAgentSocket,
 	}
 
 	sshSession := &SshSession{
@@ -515,26 +499,44 @@ func fillSSHClientForHost(sshSession *SshSession) error {
 }
 
 func fillSSHClientForJumpHost(sshSession *SshSession) error {
-	jumpHostClient, err := createSSHClient(sshSession.Options.JumpHost)
+	client, err := createSSHClientThroughJumpHost(sshSession.Options, sshSession.JumpHost)
 	if err != nil {
 		return err
 	}
-	sshSession.JumpHost.JumpHostClient = jumpHostClient
 
-	hostVirtualConn, err := jumpHostClient.Dial("tcp", sshSession.Options.ConnectionString())
+	sshSession.Client = client
+	return nil
+}
+
+// createSSHClientThroughJumpHost connects to the host of the given options through its jump host, which may itself be
+// reached through a chain of jump hosts. The connections are stored in the given jump host session to be cleaned up.
+func createSSHClientThroughJumpHost(options *SshConnectionOptions, jumpHost *JumpHostSession) (*ssh.Client, error) {
+	var jumpHostClient *ssh.Client
+	var err error
+	if options.JumpHost.JumpHost == nil {
+		jumpHostClient, err = createSSHClient(options.JumpHost)
+	} else {
+		jumpHost.JumpHost = &JumpHostSession{}
+		jumpHostClient, err = createSSHClientThroughJumpHost(options.JumpHost, jumpHost.JumpHost)
+	}
+	if err != nil {
+		return nil, err
+	}
+	jumpHost.JumpHostClient = jumpHostClient
+
+	hostVirtualConn, err := jumpHostClient.Dial("tcp", options.ConnectionString())
 	if err != nil {
-		return err
+		return nil, err
 	}
-	sshSession.JumpHost.HostVirtualConnection = hostVirtualConn
+	jumpHost.HostVirtualConnection = hostVirtualConn
 
-	hostConn, hostIncomingChannels, hostIncomingRequests, err := ssh.NewClientConn(hostVirtualConn, sshSession.Options.ConnectionString(), createSSHClientConfig(sshSession.Options))
+	hostConn, hostIncomingChannels, hostIncomingRequests, err := ssh.NewClientConn(hostVirtualConn, options.ConnectionString(), createSSHClientConfig(options))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	sshSession.JumpHost.HostConnection = hostConn
+	jumpHost.HostConnection = hostConn
 
-	sshSession.Client = ssh.NewClient(hostConn, hostIncomingChannels, hostIncomingRequests)
-	return nil
+	return ssh.NewClient(hostConn, hostIncomingChannels, hostIncomingRequests), nil
 }
 
 func setUpSSHSession(sshSession *SshSession) error {
@@ -544,12 +546,74 @@ func setUpSSHSession(sshSession *SshSession) error {
 	}
 
 	sshSession.Session = session
-	return nil
+
+	if sshSession.Options.ForwardAgentSocket == "" {
+		return nil
+	}
+	return forwardSSHAgent(sshSession)
+}
+
+// forwardSSHAgent forwards the SSH agent of the ForwardAgentSocket of the options to the session. The agent
+// connections the host opens are handled by the client, so this works the same through jump hosts.
+func forwardSSHAgent(sshSession *SshSession) error {
+	// Multiple sessions of the same client share the same agent forwarding handler
+	if sshSession.agentForwardedClient != sshSession.Client {
+		if err := agent.ForwardToRemote(sshSession.Client, sshSession.Options.ForwardAgentSocket); err != nil {
+			return err
+		}
+		sshSession.agentForwardedClient = sshSession.Client
+	}
+	return agent.RequestAgentForwarding(sshSession.Session)
 }
 
 func createSSHClient(options *SshConnectionOptions) (*ssh.Client, error) {
 	sshClientConfig := createSSHClientConfig(options)
-	return ssh.Dial("tcp", options.ConnectionString(), sshClientConfig)
+	if options.Transport == nil {
+		return ssh.Dial("tcp", options.ConnectionString(), sshClientConfig)
+	}
+
+	conn, err := options.Transport.Dial(options.ConnectionString())
+	if err != nil {
+		return nil, err
+	}
+
+	clientConn, incomingChannels, incomingRequests, err := newSSHClientConnWithTimeout(conn, options.ConnectionString(), sshClientConfig)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return ssh.NewClient(clientConn, incomingChannels, incomingRequests), nil
+}
+
+// newSSHClientConnWithTimeout runs the SSH handshake over the given connection, like ssh.NewClientConn, but gives up
+// after the timeout of the client config. The connection of a Transport may not support deadlines, e.g. the pipes of a
+// proxy command, so the connection is closed to stop a handshake that takes too long.
+func newSSHClientConnWithTimeout(conn net.Conn, address string, config *ssh.ClientConfig) (ssh.Conn, <-chan ssh.NewChannel, <-chan *ssh.Request, error) {
+	if config.Timeout <= 0 {
+		return ssh.NewClientConn(conn, address, config)
+	}
+
+	type handshakeResult struct {
+		clientConn       ssh.Conn
+		incomingChannels <-chan ssh.NewChannel
+		incomingRequests <-chan *ssh.Request
+		err              error
+	}
+	done := make(chan handshakeResult, 1)
+	go func() {
+		clientConn, incomingChannels, incomingRequests, err := ssh.NewClientConn(conn, address, config)
+		done <- handshakeResult{clientConn, incomingChannels, incomingRequests, err}
+	}()
+
+	timer := time.NewTimer(config.Timeout)
+	defer timer.Stop()
+	select {
+	case result := <-done:
+		return result.clientConn, result.incomingChannels, result.incomingRequests, result.err
+	case <-timer.C:
+		conn.Close()
+		return nil, nil, nil, fmt.Errorf("timed out after %s waiting for the SSH handshake with %s", config.Timeout, address)
+	}
 }
 
 func createSSHClientConfig(hostOptions *SshConnectionOptions) *ssh.ClientConfig {
@@ -571,6 +635,75 @@ func NoOpHostKeyCallback(hostname string, remote net.Addr, key ssh.PublicKey) er
 	return nil
 }
 
+// Returns the connection options for the given host to run the given command, with the options of its chain of jump
+// hosts, if any. The jump hosts of the jump hosts themselves are ignored. The transport of the host is used for the
+// first jump host, unless that one has its own.
+func createSshConnectionOptionsForHost(host Host, command string) (*SshConnectionOptions, error) {
+	var jumpHostOptions *SshConnectionOptions
+	transport := host.Transport
+	for _, jumpHost := range host.JumpHosts {
+		authMethods, err := createAuthMethodsForHost(jumpHost)
+		if err != nil {
+			return nil, fmt.Errorf("jump host %s: %w", jumpHost.Hostname, err)
+		}
+
+		// Only the first jump host is dialed, the others are reached through the previous one
+		if jumpHostOptions == nil && jumpHost.Transport != nil {
+			transport = jumpHost.Transport
+		}
+		jumpHostOptions = &SshConnectionOptions{
+			Username:    jumpHost.SshUserName,
+			Address:     jumpHost.Hostname,
+			Port:        jumpHost.getPort(),
+			AuthMethods: authMethods,
+			JumpHost:    jumpHostOptions,
+			Transport:   transport,
+		}
+		transport = nil
+	}
+
+	authMethods, err := createAuthMethodsForHost(host)
+	if err != nil {
+		return nil, err
+	}
+
+	forwardAgentSocket, err := getForwardAgentSocketForHost(host)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SshConnectionOptions{
+		Username:           host.SshUserName,
+		Address:            host.Hostname,
+		Port:               host.getPort(),
+		Command:            command,
+		AuthMethods:        authMethods,
+		JumpHost:           jumpHostOptions,
+		Transport:          transport,
+		ForwardAgentSocket: forwardAgentSocket,
+	}, nil
+}
+
+// Returns the socket of the SSH agent to forward to the given host, or an empty string if agent forwarding is disabled
+func getForwardAgentSocketForHost(host Host) (string, error) {
+	if !host.ForwardSshAgent {
+		return "", nil
+	}
+	if host.OverrideSshAgent != nil {
+		return host.OverrideSshAgent.socketFile, nil
+	}
+	return getLocalSshAgentSocket()
+}
+
+// Returns the socket of your existing local SSH agent
+func getLocalSshAgentSocket() (string, error) {
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if socket == "" {
+		return "", errors.New("SSH_AUTH_SOCK is not set, start an SSH agent or use OverrideSshAgent")
+	}
+	return socket, nil
+}
+
 // Returns an array of authentication methods
 func createAuthMethodsForHost(host Host) ([]ssh.AuthMethod, error) {
 	var methods []ssh.AuthMethod
@@ -589,7 +722,10 @@ func createAuthMethodsForHost(host Host) ([]ssh.AuthMethod, error) {
 	// use existing ssh agent socket
 	// if agent authentication is enabled and no agent is set up, returns an error
 	if host.SshAgent {
-		socket := os.Getenv("SSH_AUTH_SOCK")
+		socket, err := getLocalSshAgentSocket()
+		if err != nil {
+			return methods, err
+		}
 		conn, err := net.Dial("unix", socket)
 		if err != nil {
 			return methods, err
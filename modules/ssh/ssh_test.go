@@ -7,6 +7,7 @@ import (
 
 	grunttest "github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHostWithDefaultPort(t *testing.T) {
@@ -26,6 +27,86 @@ func TestHostWithCustomPort(t *testing.T) {
 	assert.Equal(t, customPort, host.getPort(), "host.getPort() did not return the custom port number")
 }
 
+func TestCreateSshConnectionOptionsForHostWithJumpHosts(t *testing.T) {
+	t.Parallel()
+
+	host := Host{
+		Hostname:    "10.0.2.10",
+		SshUserName: "app",
+		Password:    "app-password",
+		JumpHosts: []Host{
+			{Hostname: "bastion.example.com", SshUserName: "ubuntu", Password: "bastion-password"},
+			{Hostname: "10.0.1.10", SshUserName: "ec2-user", Password: "inner-password", CustomPort: 2222},
+		},
+	}
+
+	options, err := createSshConnectionOptionsForHost(host, "hostname")
+	require.NoError(t, err)
+
+	assert.Equal(t, "10.0.2.10:22", options.ConnectionString())
+	assert.Equal(t, "app", options.Username)
+	assert.Equal(t, "hostname", options.Command)
+
+	// The host is reached through the last jump host, which is reached through the first one
+	require.NotNil(t, options.JumpHost)
+	assert.Equal(t, "10.0.1.10:2222", options.JumpHost.ConnectionString())
+	assert.Equal(t, "ec2-user", options.JumpHost.Username)
+	require.NotNil(t, options.JumpHost.JumpHost)
+	assert.Equal(t, "bastion.example.com:22", options.JumpHost.JumpHost.ConnectionString())
+	assert.Equal(t, "ubuntu", options.JumpHost.JumpHost.Username)
+	assert.Nil(t, options.JumpHost.JumpHost.JumpHost)
+}
+
+func TestCreateSshConnectionOptionsForHostWithJumpHostsAndTransport(t *testing.T) {
+	t.Parallel()
+
+	hostTransport := &ProxyCommandTransport{Command: "aws", Args: []string{"ssm", "start-session", "--target", "%h"}}
+	jumpHostTransport := &ProxyCommandTransport{Command: "cloudflared", Args: []string{"access", "ssh", "--hostname", "%h"}}
+	host := Host{
+		Hostname:  "10.0.2.10",
+		Password:  "app-password",
+		Transport: hostTransport,
+		JumpHosts: []Host{
+			{Hostname: "i-0123456789abcdef0", Password: "bastion-password"},
+			{Hostname: "10.0.1.10", Password: "inner-password"},
+		},
+	}
+
+	// The first jump host is dialed with the transport of the host, and the others through the previous jump host
+	options, err := createSshConnectionOptionsForHost(host, "hostname")
+	require.NoError(t, err)
+	assert.Nil(t, options.Transport)
+	assert.Nil(t, options.JumpHost.Transport)
+	assert.Equal(t, hostTransport, options.JumpHost.JumpHost.Transport)
+
+	// Unless it has its own transport
+	host.JumpHosts[0].Transport = jumpHostTransport
+	options, err = createSshConnectionOptionsForHost(host, "hostname")
+	require.NoError(t, err)
+	assert.Equal(t, jumpHostTransport, options.JumpHost.JumpHost.Transport)
+
+	// Without jump hosts, the host itself is dialed with it
+	host.JumpHosts = nil
+	options, err = createSshConnectionOptionsForHost(host, "hostname")
+	require.NoError(t, err)
+	assert.Equal(t, hostTransport, options.Transport)
+}
+
+func TestCreateSshConnectionOptionsForHostWithJumpHostWithoutAuth(t *testing.T) {
+	t.Parallel()
+
+	host := Host{
+		Hostname: "10.0.2.10",
+		Password: "app-password",
+		JumpHosts: []Host{
+			{Hostname: "bastion.example.com", SshUserName: "ubuntu"},
+		},
+	}
+
+	_, err := createSshConnectionOptionsForHost(host, "hostname")
+	assert.EqualError(t, err, "jump host bastion.example.com: no authentication method defined")
+}
+
 // global var for use in mock callback
 var timesCalled int
 
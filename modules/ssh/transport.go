@@ -0,0 +1,265 @@
+package ssh
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultTunnelStartTimeout is how long to wait for the local port of a LocalTunnelTransport to accept connections.
+const defaultTunnelStartTimeout = 30 * time.Second
+
+// Transport opens the connection to a host over which SSH runs. By default, Terratest connects to hosts with TCP, but
+// a Transport can connect to hosts that have no public SSH at all, e.g. through an AWS SSM session, an Azure Bastion
+// tunnel or a GCP IAP tunnel.
+type Transport interface {
+	// Dial returns a connection to the given address (host:port) of the host.
+	Dial(address string) (net.Conn, error)
+}
+
+// ProxyCommandTransport is a Transport that runs a command, like the ProxyCommand of OpenSSH, and uses its stdin and
+// stdout as the connection to the host. In the arguments, %h is replaced with the host and %p with the port to connect
+// to.
+type ProxyCommandTransport struct {
+	Command string
+	Args    []string
+	Env     map[string]string // additional environment variables to run the command with
+}
+
+// Dial runs the proxy command for the given address (host:port) and returns a connection over its stdin and stdout.
+// Closing the connection stops the command.
+func (transport *ProxyCommandTransport) Dial(address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(transport.Command, formatTransportArgs(transport.Args, host, port, "")...)
+	cmd.Env = formatTransportEnv(transport.Env)
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	return &proxyCommandConn{cmd: cmd, stdin: stdin, stdout: stdout, address: address}, nil
+}
+
+// LocalTunnelTransport is a Transport that runs a command which opens a tunnel to the host on a local port, and
+// connects to the host through that port. In the arguments, %h is replaced with the host, %p with the port to connect
+// to, and %l with the local port of the tunnel, which is a random free port.
+type LocalTunnelTransport struct {
+	Command      string
+	Args         []string
+	Env          map[string]string // additional environment variables to run the command with
+	StartTimeout time.Duration     // how long to wait for the tunnel to accept connections (30 seconds if unset)
+}
+
+// Dial runs the tunnel command for the given address (host:port), waits until the local port of the tunnel accepts
+// connections, and returns a connection through it. Closing the connection stops the command.
+func (transport *LocalTunnelTransport) Dial(address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	localPort, err := getFreeLocalPort()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(transport.Command, formatTransportArgs(transport.Args, host, port, strconv.Itoa(localPort))...)
+	cmd.Env = formatTransportEnv(transport.Env)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	startTimeout := transport.StartTimeout
+	if startTimeout == 0 {
+		startTimeout = defaultTunnelStartTimeout
+	}
+
+	localAddress := net.JoinHostPort("127.0.0.1", strconv.Itoa(localPort))
+	deadline := time.Now().Add(startTimeout)
+	for {
+		conn, err := net.DialTimeout("tcp", localAddress, time.Second)
+		if err == nil {
+			return &localTunnelConn{Conn: conn, cmd: cmd, exited: exited}, nil
+		}
+
+		select {
+		case exitErr := <-exited:
+			return nil, fmt.Errorf("tunnel command %s exited before accepting connections on %s: %v", transport.Command, localAddress, exitErr)
+		case <-time.After(500 * time.Millisecond):
+		}
+
+		if time.Now().After(deadline) {
+			cmd.Process.Kill()
+			return nil, fmt.Errorf("timed out after %s waiting for tunnel command %s to accept connections on %s", startTimeout, transport.Command, localAddress)
+		}
+	}
+}
+
+// NewSsmTransport returns a Transport that connects to the given EC2 instance through an AWS Systems Manager session,
+// so the instance needs no inbound SSH access. It requires the AWS CLI with the Session Manager plugin, and the
+// instance to run the SSM agent. The hostname of the host is not used to connect, and can be the instance ID.
+func NewSsmTransport(instanceID string, region string) *ProxyCommandTransport {
+	return &ProxyCommandTransport{
+		Command: "aws",
+		Args: []string{
+			"ssm", "start-session",
+			"--target", instanceID,
+			"--document-name", "AWS-StartSSHSession",
+			"--parameters", "portNumber=%p",
+			"--region", region,
+		},
+	}
+}
+
+// NewGcpIapTransport returns a Transport that connects to the given Compute Engine instance through an Identity-Aware
+// Proxy TCP forwarding tunnel, so the instance needs no external IP. It requires the gcloud CLI, and a firewall rule
+// allowing IAP to reach the instance. The hostname of the host is not used to connect, and can be the instance name.
+func NewGcpIapTransport(projectID string, zone string, instanceName string) *ProxyCommandTransport {
+	return &ProxyCommandTransport{
+		Command: "gcloud",
+		Args: []string{
+			"compute", "start-iap-tunnel", instanceName, "%p",
+			"--listen-on-stdin",
+			"--project", projectID,
+			"--zone", zone,
+		},
+	}
+}
+
+// NewAzureBastionTransport returns a Transport that connects to the given Azure VM, by resource ID, through a tunnel of
+// the given Azure Bastion, so the VM needs no public IP. It requires the Azure CLI with the bastion extension, and a
+// Standard SKU Bastion with native client support enabled. The hostname of the host is not used to connect.
+func NewAzureBastionTransport(bastionName string, resourceGroupName string, targetResourceID string) *LocalTunnelTransport {
+	return &LocalTunnelTransport{
+		Command: "az",
+		Args: []string{
+			"network", "bastion", "tunnel",
+			"--name", bastionName,
+			"--resource-group", resourceGroupName,
+			"--target-resource-id", targetResourceID,
+			"--resource-port", "%p",
+			"--port", "%l",
+		},
+	}
+}
+
+// formatTransportArgs replaces the %h, %p and %l placeholders of the given arguments with the host, the port and the
+// local port.
+func formatTransportArgs(args []string, host string, port string, localPort string) []string {
+	replacer := strings.NewReplacer("%h", host, "%p", port, "%l", localPort, "%%", "%")
+	formatted := make([]string, 0, len(args))
+	for _, arg := range args {
+		formatted = append(formatted, replacer.Replace(arg))
+	}
+	return formatted
+}
+
+// formatTransportEnv returns the environment of the current process with the given additional variables.
+func formatTransportEnv(env map[string]string) []string {
+	formatted := os.Environ()
+	for key, value := range env {
+		formatted = append(formatted, fmt.Sprintf("%s=%s", key, value))
+	}
+	return formatted
+}
+
+// getFreeLocalPort returns a random port that is free on localhost.
+func getFreeLocalPort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// proxyCommandConn is a connection over the stdin and stdout of a proxy command.
+type proxyCommandConn struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	stdout  io.ReadCloser
+	address string
+}
+
+func (conn *proxyCommandConn) Read(data []byte) (int, error) {
+	return conn.stdout.Read(data)
+}
+
+func (conn *proxyCommandConn) Write(data []byte) (int, error) {
+	return conn.stdin.Write(data)
+}
+
+// Close closes the stdin of the proxy command and stops it.
+func (conn *proxyCommandConn) Close() error {
+	conn.stdin.Close()
+	conn.cmd.Process.Kill()
+	conn.cmd.Wait()
+	return nil
+}
+
+func (conn *proxyCommandConn) LocalAddr() net.Addr {
+	return proxyCommandAddr{address: "proxy-command"}
+}
+
+func (conn *proxyCommandConn) RemoteAddr() net.Addr {
+	return proxyCommandAddr{address: conn.address}
+}
+
+// The deadlines aren't supported on pipes. The timeout of the SSH handshake is applied by closing the connection
+// instead, see newSSHClientConnWithTimeout.
+func (conn *proxyCommandConn) SetDeadline(t time.Time) error      { return nil }
+func (conn *proxyCommandConn) SetReadDeadline(t time.Time) error  { return nil }
+func (conn *proxyCommandConn) SetWriteDeadline(t time.Time) error { return nil }
+
+// proxyCommandAddr is the address of one end of a proxyCommandConn.
+type proxyCommandAddr struct {
+	address string
+}
+
+func (addr proxyCommandAddr) Network() string {
+	return "proxy-command"
+}
+
+func (addr proxyCommandAddr) String() string {
+	return addr.address
+}
+
+// localTunnelConn is a connection through the local port of a tunnel command.
+type localTunnelConn struct {
+	net.Conn
+	cmd    *exec.Cmd
+	exited chan error
+}
+
+// Close closes the connection and stops the tunnel command.
+func (conn *localTunnelConn) Close() error {
+	err := conn.Conn.Close()
+	conn.cmd.Process.Kill()
+	<-conn.exited
+	return err
+}
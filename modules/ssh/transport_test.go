@@ -0,0 +1,97 @@
+package ssh
+
+import (
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestFormatTransportArgs(t *testing.T) {
+	t.Parallel()
+
+	args := formatTransportArgs([]string{"tunnel", "%h", "--resource-port", "%p", "--port", "%l", "100%%"}, "10.0.1.10", "22", "50022")
+	assert.Equal(t, []string{"tunnel", "10.0.1.10", "--resource-port", "22", "--port", "50022", "100%"}, args)
+}
+
+func TestNewSsmTransport(t *testing.T) {
+	t.Parallel()
+
+	transport := NewSsmTransport("i-0123456789abcdef0", "us-east-1")
+	assert.Equal(t, "aws", transport.Command)
+	assert.Equal(t, []string{"ssm", "start-session", "--target", "i-0123456789abcdef0", "--document-name", "AWS-StartSSHSession", "--parameters", "portNumber=22", "--region", "us-east-1"}, formatTransportArgs(transport.Args, "i-0123456789abcdef0", "22", ""))
+}
+
+func TestProxyCommandTransport(t *testing.T) {
+	t.Parallel()
+
+	// cat echoes what is written to the connection
+	transport := &ProxyCommandTransport{Command: "cat"}
+	conn, err := transport.Dial("10.0.1.10:22")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	response := make([]byte, 4)
+	_, err = io.ReadFull(conn, response)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(response))
+	assert.Equal(t, "10.0.1.10:22", conn.RemoteAddr().String())
+}
+
+func TestSshHandshakeTimesOutOverProxyCommand(t *testing.T) {
+	t.Parallel()
+
+	// sleep never answers the handshake, and its pipes don't support deadlines
+	transport := &ProxyCommandTransport{Command: "sleep", Args: []string{"60"}}
+	conn, err := transport.Dial("10.0.1.10:22")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	start := time.Now()
+	_, _, _, err = newSSHClientConnWithTimeout(conn, "10.0.1.10:22", &ssh.ClientConfig{HostKeyCallback: NoOpHostKeyCallback, Timeout: 500 * time.Millisecond})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out after 500ms waiting for the SSH handshake with 10.0.1.10:22")
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
+
+func TestLocalTunnelTransportCommandExits(t *testing.T) {
+	t.Parallel()
+
+	transport := &LocalTunnelTransport{Command: "sh", Args: []string{"-c", "exit 1"}, StartTimeout: 10 * time.Second}
+	_, err := transport.Dial("10.0.1.10:22")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tunnel command sh exited before accepting connections")
+}
+
+func TestCheckSshCommandEWithTransport(t *testing.T) {
+	t.Parallel()
+
+	host := startTestSshServer(t)
+	transport := &testTransport{address: net.JoinHostPort(host.Hostname, strconv.Itoa(host.CustomPort))}
+	host.Hostname = "i-0123456789abcdef0"
+	host.CustomPort = 0
+	host.Transport = transport
+
+	out, err := CheckSshCommandE(t, host, "echo hello")
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", out)
+	assert.Equal(t, []string{"i-0123456789abcdef0:22"}, transport.dialed)
+}
+
+// testTransport is a Transport which connects to a fixed address, whatever the address of the host.
+type testTransport struct {
+	address string
+	dialed  []string
+}
+
+func (transport *testTransport) Dial(address string) (net.Conn, error) {
+	transport.dialed = append(transport.dialed, address)
+	return net.Dial("tcp", transport.address)
+}
@@ -1,9 +1,11 @@
 package terraform
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strings"
 
@@ -11,20 +13,38 @@ import (
 	"github.com/gruntwork-io/terratest/modules/retry"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/gruntwork-io/terratest/modules/tracing"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 func generateCommand(options *Options, args ...string) shell.Command {
 	cmd := shell.Command{
-		Command:    options.TerraformBinary,
-		Args:       args,
-		WorkingDir: options.TerraformDir,
-		Env:        options.EnvVars,
-		Logger:     options.Logger,
+		Command:          options.TerraformBinary,
+		Args:             args,
+		WorkingDir:       options.TerraformDir,
+		Env:              options.EnvVars,
+		Logger:           options.Logger.WithMinLevel(options.LogLevel),
+		SensitiveArgs:    getSensitiveArgs(options),
+		SensitiveEnvVars: options.SensitiveEnvVars,
 	}
 	return cmd
 }
 
+// getSensitiveArgs returns the values of the sensitive vars of the given options, as they are passed to Terraform.
+func getSensitiveArgs(options *Options) []string {
+	sensitiveArgs := []string{}
+	for _, name := range options.SensitiveVars {
+		if value, ok := options.Vars[name]; ok && value != nil {
+			sensitiveArgs = append(sensitiveArgs, toHclString(value, false))
+		}
+		if value, ok := options.BackendConfig[name]; ok && value != nil {
+			sensitiveArgs = append(sensitiveArgs, toHclString(value, false))
+		}
+	}
+	return sensitiveArgs
+}
+
 var commandsWithParallelism = []string{
 	"plan",
 	"apply",
@@ -90,9 +110,8 @@ func RunTerraformCommandE(t testing.TestingT, additionalOptions *Options, additi
 	options, args := GetCommonOptions(additionalOptions, additionalArgs...)
 
 	cmd := generateCommand(options, args...)
-	description := fmt.Sprintf("%s %v", options.TerraformBinary, args)
 
-	return retry.DoWithRetryableErrorsE(t, description, options.RetryableTerraformErrors, options.MaxRetries, options.TimeBetweenRetries, func() (string, error) {
+	return doWithRetryableErrorsE(t, options, args, func() (string, error) {
 		s, err := shell.RunCommandAndGetOutputE(t, cmd)
 		if err != nil {
 			return s, err
@@ -132,10 +151,9 @@ func RunTerraformCommandAndGetStdOutErrCodeE(t testing.TestingT, additionalOptio
 	options, args := GetCommonOptions(additionalOptions, additionalArgs...)
 
 	cmd := generateCommand(options, args...)
-	description := fmt.Sprintf("%s %v", options.TerraformBinary, args)
 
 	exit = DefaultErrorExitCode
-	_, err = retry.DoWithRetryableErrorsE(t, description, options.RetryableTerraformErrors, options.MaxRetries, options.TimeBetweenRetries, func() (string, error) {
+	_, err = doWithRetryableErrorsE(t, options, args, func() (string, error) {
 		stdout, stderr, err = shell.RunCommandAndGetStdOutErrE(t, cmd)
 		if err != nil {
 			exitCode, getExitCodeErr := shell.GetExitCodeForRunCommandError(err)
@@ -169,8 +187,8 @@ func GetExitCodeForTerraformCommand(t testing.TestingT, additionalOptions *Optio
 func GetExitCodeForTerraformCommandE(t testing.TestingT, additionalOptions *Options, additionalArgs ...string) (int, error) {
 	options, args := GetCommonOptions(additionalOptions, additionalArgs...)
 
-	additionalOptions.Logger.Logf(t, "Running %s with args %v", options.TerraformBinary, args)
 	cmd := generateCommand(options, args...)
+	additionalOptions.Logger.Logf(t, "%s", cmd.Redact(fmt.Sprintf("Running %s with args %v", options.TerraformBinary, args)))
 	_, err := shell.RunCommandAndGetOutputE(t, cmd)
 	if err == nil {
 		return DefaultSuccessExitCode, nil
@@ -242,3 +260,28 @@ func setTerragruntLogFormatting(options *Options) {
 		}
 	}
 }
+
+// doWithRetryableErrorsE runs the given action of the Terraform command with the given args in a tracing span,
+// retrying the RetryableTerraformErrors of the given options up to MaxRetries times, with their RetryBackoff if set, or
+// else TimeBetweenRetries between retries. The sensitive values of the options are masked in the description of the
+// command that the retries log and their errors include.
+func doWithRetryableErrorsE(t testing.TestingT, options *Options, args []string, action func() (string, error)) (out string, err error) {
+	description := generateCommand(options, args...).Redact(fmt.Sprintf("%s %v", options.TerraformBinary, args))
+
+	command := ""
+	if len(args) > 0 {
+		command = args[0]
+	}
+	_, endSpan := tracing.StartSpan(t, fmt.Sprintf("%s %s", filepath.Base(options.TerraformBinary), command), attribute.String("terraform.command", command), attribute.String("terraform.dir", options.TerraformDir))
+	defer func() { endSpan(err) }()
+
+	ctx := options.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	contextAction := func(context.Context) (string, error) { return action() }
+	if options.RetryBackoff != nil {
+		return retry.DoWithRetryableErrorsAndBackoffContextE(t, ctx, description, options.RetryableTerraformErrors, options.MaxRetries, *options.RetryBackoff, contextAction)
+	}
+	return retry.DoWithRetryableErrorsContextE(t, ctx, description, options.RetryableTerraformErrors, options.MaxRetries, options.TimeBetweenRetries, contextAction)
+}
@@ -1,10 +1,20 @@
 package terraform
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gruntwork-io/terratest/modules/files"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	tftesting "github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -65,3 +75,110 @@ func TestTerraformCommand(t *testing.T) {
 		}
 	})
 }
+
+func TestGenerateCommandSensitiveVars(t *testing.T) {
+	t.Parallel()
+
+	options := &Options{
+		Vars:             map[string]interface{}{"db_password": "hunter2", "region": "us-east-1", "api_keys": []string{"key1", "key2"}},
+		BackendConfig:    map[string]interface{}{"access_key": "AKIAEXAMPLE"},
+		SensitiveVars:    []string{"db_password", "api_keys", "access_key", "missing"},
+		SensitiveEnvVars: []string{"TF_VAR_token"},
+	}
+
+	cmd := generateCommand(options, "plan")
+	assert.ElementsMatch(t, []string{"hunter2", `["key1", "key2"]`, "AKIAEXAMPLE"}, cmd.SensitiveArgs)
+	assert.Equal(t, []string{"TF_VAR_token"}, cmd.SensitiveEnvVars)
+}
+
+func TestRetriesMaskSensitiveVars(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("The fake terraform is a shell script")
+	}
+
+	// A fake terraform that always fails with a retryable error
+	dir := t.TempDir()
+	fakeTerraform := filepath.Join(dir, "terraform")
+	require.NoError(t, os.WriteFile(fakeTerraform, []byte("#!/bin/sh\necho 'Error: connection reset' >&2\nexit 1\n"), 0755))
+
+	logs := &stringLogger{}
+	defaultLogger := logger.Default
+	logger.Default = logger.New(logs)
+	t.Cleanup(func() { logger.Default = defaultLogger })
+
+	options := &Options{
+		TerraformBinary:          fakeTerraform,
+		TerraformDir:             dir,
+		Vars:                     map[string]interface{}{"db_password": "hunter2"},
+		SensitiveVars:            []string{"db_password"},
+		RetryableTerraformErrors: map[string]string{"connection reset": "transient error"},
+		MaxRetries:               1,
+		Logger:                   logger.New(logs),
+	}
+	_, err := ApplyE(t, options)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsuccessful after 1 retries")
+	assert.NotContains(t, err.Error(), "hunter2")
+
+	_, err = GetExitCodeForTerraformCommandE(t, options, FormatArgs(options, "plan", "-input=false")...)
+	require.NoError(t, err)
+
+	assert.Contains(t, logs.String(), "db_password=***")
+	assert.NotContains(t, logs.String(), "hunter2")
+}
+
+// stringLogger logs to a string, to check what is logged.
+type stringLogger struct {
+	mutex sync.Mutex
+	logs  strings.Builder
+}
+
+func (l *stringLogger) Logf(t tftesting.TestingT, format string, args ...interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.logs.WriteString(fmt.Sprintf(format, args...) + "\n")
+}
+
+func (l *stringLogger) String() string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.logs.String()
+}
+
+func TestRetriesUseOptionsContext(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("The fake terraform is a shell script")
+	}
+
+	// A fake terraform that always fails with a retryable error
+	dir := t.TempDir()
+	fakeTerraform := filepath.Join(dir, "terraform")
+	require.NoError(t, os.WriteFile(fakeTerraform, []byte("#!/bin/sh\necho 'Error: connection reset' >&2\nexit 1\n"), 0755))
+
+	recorder := &retry.AttemptRecorder{}
+	options := &Options{
+		TerraformBinary:          fakeTerraform,
+		TerraformDir:             dir,
+		RetryableTerraformErrors: map[string]string{"connection reset": "transient error"},
+		MaxRetries:               2,
+		Context:                  retry.WithCollector(context.Background(), recorder),
+	}
+	_, err := RunTerraformCommandE(t, options, "apply")
+	require.Error(t, err)
+	assert.Len(t, recorder.Attempts(""), 3)
+
+	// The retry budget of the context stops the retries
+	ctx, cancel := retry.WithRetryBudget(context.Background(), time.Millisecond)
+	defer cancel()
+	options.Context = ctx
+	options.TimeBetweenRetries = time.Minute
+	_, err = RunTerraformCommandE(t, options, "apply")
+	var exhausted retry.RetryBudgetExhausted
+	require.ErrorAs(t, err, &exhausted)
+
+	clone, err := options.Clone()
+	require.NoError(t, err)
+	assert.Equal(t, ctx, clone.Context)
+}
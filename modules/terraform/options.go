@@ -1,9 +1,11 @@
 package terraform
 
 import (
+	"context"
 	"time"
 
 	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
 	"github.com/gruntwork-io/terratest/modules/ssh"
 	"github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/jinzhu/copier"
@@ -50,30 +52,35 @@ type Options struct {
 	// }
 	Vars map[string]interface{}
 
-	VarFiles                 []string               // The var file paths to pass to Terraform commands using -var-file option.
-	MixedVars                []Var                  // Mix of `-var` and `-var-file` in arbritrary order, use `VarInline()` `VarFile()` to set the value.
-	Targets                  []string               // The target resources to pass to the terraform command with -target
-	Lock                     bool                   // The lock option to pass to the terraform command with -lock
-	LockTimeout              string                 // The lock timeout option to pass to the terraform command with -lock-timeout
-	EnvVars                  map[string]string      // Environment variables to set when running Terraform
-	BackendConfig            map[string]interface{} // The vars to pass to the terraform init command for extra configuration for the backend. If a var is nil, it will be formated as `--backend-config=var` instead of `--backend-config=var=null`
-	RetryableTerraformErrors map[string]string      // If Terraform apply fails with one of these (transient) errors, retry. The keys are a regexp to match against the error and the message is what to display to a user if that error is matched.
-	MaxRetries               int                    // Maximum number of times to retry errors matching RetryableTerraformErrors
-	TimeBetweenRetries       time.Duration          // The amount of time to wait between retries
-	Upgrade                  bool                   // Whether the -upgrade flag of the terraform init command should be set to true or not
-	Reconfigure              bool                   // Set the -reconfigure flag to the terraform init command
-	MigrateState             bool                   // Set the -migrate-state and -force-copy (suppress 'yes' answer prompt) flag to the terraform init command
-	NoColor                  bool                   // Whether the -no-color flag will be set for any Terraform command or not
-	SshAgent                 *ssh.SshAgent          // Overrides local SSH agent with the given in-process agent
-	NoStderr                 bool                   // Disable stderr redirection
-	OutputMaxLineSize        int                    // The max size of one line in stdout and stderr (in bytes)
-	Logger                   *logger.Logger         // Set a non-default logger that should be used. See the logger package for more info.
-	Parallelism              int                    // Set the parallelism setting for Terraform
-	PlanFilePath             string                 // The path to output a plan file to (for the plan command) or read one from (for the apply command)
-	PluginDir                string                 // The path of downloaded plugins to pass to the terraform init command (-plugin-dir)
-	SetVarsAfterVarFiles     bool                   // Pass -var options after -var-file options to Terraform commands
-	WarningsAsErrors         map[string]string      // Terraform warning messages that should be treated as errors. The keys are a regexp to match against the warning and the value is what to display to a user if that warning is matched.
-	ExtraArgs                ExtraArgs              // Extra arguments passed to Terraform commands
+	VarFiles                 []string                  // The var file paths to pass to Terraform commands using -var-file option.
+	MixedVars                []Var                     // Mix of `-var` and `-var-file` in arbritrary order, use `VarInline()` `VarFile()` to set the value.
+	Targets                  []string                  // The target resources to pass to the terraform command with -target
+	Lock                     bool                      // The lock option to pass to the terraform command with -lock
+	LockTimeout              string                    // The lock timeout option to pass to the terraform command with -lock-timeout
+	EnvVars                  map[string]string         // Environment variables to set when running Terraform
+	BackendConfig            map[string]interface{}    // The vars to pass to the terraform init command for extra configuration for the backend. If a var is nil, it will be formated as `--backend-config=var` instead of `--backend-config=var=null`
+	RetryableTerraformErrors map[string]string         // If Terraform apply fails with one of these (transient) errors, retry. The keys are a regexp to match against the error and the message is what to display to a user if that error is matched.
+	MaxRetries               int                       // Maximum number of times to retry errors matching RetryableTerraformErrors
+	TimeBetweenRetries       time.Duration             // The amount of time to wait between retries
+	RetryBackoff             *retry.ExponentialBackoff // If set, wait an exponentially growing amount of time between retries instead of TimeBetweenRetries
+	Context                  context.Context           // If set, the context of the retries of Terraform commands, e.g. with a retry budget (see retry.WithRetryBudget) or collectors (see retry.WithCollector). It doesn't interrupt running commands.
+	Upgrade                  bool                      // Whether the -upgrade flag of the terraform init command should be set to true or not
+	Reconfigure              bool                      // Set the -reconfigure flag to the terraform init command
+	MigrateState             bool                      // Set the -migrate-state and -force-copy (suppress 'yes' answer prompt) flag to the terraform init command
+	NoColor                  bool                      // Whether the -no-color flag will be set for any Terraform command or not
+	SshAgent                 *ssh.SshAgent             // Overrides local SSH agent with the given in-process agent
+	NoStderr                 bool                      // Disable stderr redirection
+	OutputMaxLineSize        int                       // The max size of one line in stdout and stderr (in bytes)
+	Logger                   *logger.Logger            // Set a non-default logger that should be used. See the logger package for more info.
+	LogLevel                 logger.Level              // The minimum level of the messages to log, e.g. logger.LevelInfo to not log the output of Terraform. Defaults to logger.DefaultLevel.
+	Parallelism              int                       // Set the parallelism setting for Terraform
+	PlanFilePath             string                    // The path to output a plan file to (for the plan command) or read one from (for the apply command)
+	PluginDir                string                    // The path of downloaded plugins to pass to the terraform init command (-plugin-dir)
+	SetVarsAfterVarFiles     bool                      // Pass -var options after -var-file options to Terraform commands
+	WarningsAsErrors         map[string]string         // Terraform warning messages that should be treated as errors. The keys are a regexp to match against the warning and the value is what to display to a user if that warning is matched.
+	ExtraArgs                ExtraArgs                 // Extra arguments passed to Terraform commands
+	SensitiveVars            []string                  // The names of Vars and BackendConfig entries whose values are masked in the logs of Terraform commands
+	SensitiveEnvVars         []string                  // The names of environment variables whose values are masked in the logs of Terraform commands
 }
 
 type ExtraArgs struct {
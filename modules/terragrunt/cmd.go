@@ -1,6 +1,7 @@
 package terragrunt
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"slices"
@@ -9,6 +10,8 @@ import (
 	"github.com/gruntwork-io/terratest/modules/retry"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/gruntwork-io/terratest/modules/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // terragruntStackCommandE executes a terragrunt stack command without any subcommand
@@ -44,12 +47,11 @@ func runTerragruntStackCommandE(t testing.TestingT, opts *Options, subCommand st
 	commandDescription := fmt.Sprintf("%s %v", terragruntOptions.TerragruntBinary, finalArgs)
 
 	// Execute the command with retry logic and error handling
-	return retry.DoWithRetryableErrorsE(
+	return doWithRetryableErrorsE(
 		t,
+		terragruntOptions,
+		strings.Join(commandArgs, " "),
 		commandDescription,
-		terragruntOptions.RetryableTerraformErrors,
-		terragruntOptions.MaxRetries,
-		terragruntOptions.TimeBetweenRetries,
 		func() (string, error) {
 			output, err := shell.RunCommandAndGetOutputE(t, execCommand)
 			if err != nil {
@@ -88,12 +90,11 @@ func runTerragruntCommandE(t testing.TestingT, opts *Options, command string, ad
 	commandDescription := fmt.Sprintf("%s %v", terragruntOptions.TerragruntBinary, finalArgs)
 
 	// Execute the command with retry logic and error handling
-	return retry.DoWithRetryableErrorsE(
+	return doWithRetryableErrorsE(
 		t,
+		terragruntOptions,
+		strings.Join(commandArgs, " "),
 		commandDescription,
-		terragruntOptions.RetryableTerraformErrors,
-		terragruntOptions.MaxRetries,
-		terragruntOptions.TimeBetweenRetries,
 		func() (string, error) {
 			output, err := shell.RunCommandAndGetOutputE(t, execCommand)
 			if err != nil {
@@ -148,10 +149,30 @@ func validateOptions(opts *Options) error {
 // This function encapsulates the command creation logic for consistency
 func generateCommand(terragruntOptions *Options, commandArgs ...string) shell.Command {
 	return shell.Command{
-		Command:    terragruntOptions.TerragruntBinary,
-		Args:       commandArgs,
-		WorkingDir: terragruntOptions.TerragruntDir,
-		Env:        terragruntOptions.EnvVars,
-		Logger:     terragruntOptions.Logger,
+		Command:          terragruntOptions.TerragruntBinary,
+		Args:             commandArgs,
+		WorkingDir:       terragruntOptions.TerragruntDir,
+		Env:              terragruntOptions.EnvVars,
+		Logger:           terragruntOptions.Logger.WithMinLevel(terragruntOptions.LogLevel),
+		SensitiveArgs:    terragruntOptions.SensitiveArgs,
+		SensitiveEnvVars: terragruntOptions.SensitiveEnvVars,
 	}
 }
+
+// doWithRetryableErrorsE executes the action of the given terragrunt command in a tracing span, with retry logic for
+// the retryable errors of the options
+// It waits with the RetryBackoff of the options between retries if set, or else TimeBetweenRetries
+func doWithRetryableErrorsE(t testing.TestingT, terragruntOptions *Options, command string, commandDescription string, action func() (string, error)) (out string, err error) {
+	_, endSpan := tracing.StartSpan(t, "terragrunt "+command, attribute.String("terragrunt.command", command), attribute.String("terragrunt.dir", terragruntOptions.TerragruntDir))
+	defer func() { endSpan(err) }()
+
+	ctx := terragruntOptions.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	contextAction := func(context.Context) (string, error) { return action() }
+	if terragruntOptions.RetryBackoff != nil {
+		return retry.DoWithRetryableErrorsAndBackoffContextE(t, ctx, commandDescription, terragruntOptions.RetryableTerraformErrors, terragruntOptions.MaxRetries, *terragruntOptions.RetryBackoff, contextAction)
+	}
+	return retry.DoWithRetryableErrorsContextE(t, ctx, commandDescription, terragruntOptions.RetryableTerraformErrors, terragruntOptions.MaxRetries, terragruntOptions.TimeBetweenRetries, contextAction)
+}
@@ -1,10 +1,12 @@
 package terragrunt
 
 import (
+	"context"
 	"os"
 	"time"
 
 	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
 )
 
 // Key concepts:
@@ -60,12 +62,17 @@ type Options struct {
 	TerragruntDir    string            // The directory containing the terragrunt configuration
 	EnvVars          map[string]string // Environment variables for command execution
 	Logger           *logger.Logger    // Logger for command output
+	LogLevel         logger.Level      // Minimum level of logged messages, e.g. logger.LevelInfo to not log command output
+	SensitiveArgs    []string          // Values masked in the logs of commands, e.g. secrets passed in ExtraArgs
+	SensitiveEnvVars []string          // Names of environment variables whose values are masked in the logs of commands
 
 	// Test framework retry and error handling (NOT passed to terragrunt command line)
-	MaxRetries               int               // Maximum number of retries
-	TimeBetweenRetries       time.Duration     // Time between retries
-	RetryableTerraformErrors map[string]string // Retryable error patterns
-	WarningsAsErrors         map[string]string // Warnings to treat as errors
+	MaxRetries               int                       // Maximum number of retries
+	TimeBetweenRetries       time.Duration             // Time between retries
+	RetryBackoff             *retry.ExponentialBackoff // If set, exponentially growing time between retries instead of TimeBetweenRetries
+	Context                  context.Context           // If set, context of the retries, e.g. with a retry budget; doesn't interrupt running commands
+	RetryableTerraformErrors map[string]string         // Retryable error patterns
+	WarningsAsErrors         map[string]string         // Warnings to treat as errors
 
 	// Complex configuration that requires special formatting (NOT raw command-line args)
 	BackendConfig map[string]interface{} // Backend configuration (formatted specially)
@@ -0,0 +1,234 @@
+package test_structure
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/gruntwork-io/terratest/modules/aws"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+const (
+	// TestDataAgeKeyEnvVar is the environment variable that sets DefaultEncryptor to an AgeEncryptor with the given age
+	// secret key (AGE-SECRET-KEY-1...), e.g. as generated by age-keygen.
+	TestDataAgeKeyEnvVar = "TERRATEST_TEST_DATA_AGE_KEY"
+	// TestDataKmsKeyEnvVar is the environment variable that sets DefaultEncryptor to a KmsEncryptor with the given KMS
+	// key ID, ARN or alias, if TERRATEST_TEST_DATA_AGE_KEY is not set.
+	TestDataKmsKeyEnvVar = "TERRATEST_TEST_DATA_KMS_KEY"
+	// TestDataKmsRegionEnvVar is the environment variable with the region of the KMS key of TERRATEST_TEST_DATA_KMS_KEY.
+	// It defaults to the region in the key ARN.
+	TestDataKmsRegionEnvVar = "TERRATEST_TEST_DATA_KMS_REGION"
+)
+
+// Encryptor encrypts the test data saved with SaveTestData, SaveValue and the functions using them, such as
+// SaveString and SaveTerraformOptions, which often contain credentials, kubeconfigs and key material.
+type Encryptor interface {
+	Encrypt(t testing.TestingT, plaintext []byte) ([]byte, error)
+	Decrypt(t testing.TestingT, ciphertext []byte) ([]byte, error)
+}
+
+// DefaultEncryptor encrypts the test data that's saved, and decrypts the encrypted test data that's loaded, if set. It's
+// set with the TERRATEST_TEST_DATA_AGE_KEY or TERRATEST_TEST_DATA_KMS_KEY environment variables, and is nil, saving the
+// test data as plaintext, if neither is set.
+var DefaultEncryptor = encryptorFromEnv()
+
+// encryptedTestData is the JSON the test data encrypted with DefaultEncryptor is saved as.
+type encryptedTestData struct {
+	Ciphertext []byte `json:"terratestEncryptedTestData"`
+}
+
+// AgeEncryptor encrypts test data with age (https://age-encryption.org), for the given X25519 identity.
+type AgeEncryptor struct {
+	Identity *age.X25519Identity
+}
+
+// Encrypt encrypts the given plaintext for the identity of the encryptor.
+func (encryptor AgeEncryptor) Encrypt(t testing.TestingT, plaintext []byte) ([]byte, error) {
+	var ciphertext bytes.Buffer
+	writer, err := age.Encrypt(&ciphertext, encryptor.Identity.Recipient())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return ciphertext.Bytes(), nil
+}
+
+// Decrypt decrypts the given ciphertext with the identity of the encryptor.
+func (encryptor AgeEncryptor) Decrypt(t testing.TestingT, ciphertext []byte) ([]byte, error) {
+	reader, err := age.Decrypt(bytes.NewReader(ciphertext), encryptor.Identity)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(reader)
+}
+
+// KmsEncryptor encrypts test data with a data key generated by the given AWS KMS key, so that test data larger than the
+// 4 KB that KMS encrypts directly can be encrypted.
+type KmsEncryptor struct {
+	Region string
+	KeyId  string // the ID, ARN or alias of the KMS key
+}
+
+// kmsEncryptedTestData is the test data encrypted by a KmsEncryptor: the data key encrypted by KMS, and the test data
+// encrypted with AES-GCM by the data key.
+type kmsEncryptedTestData struct {
+	EncryptedDataKey []byte
+	Nonce            []byte
+	Ciphertext       []byte
+}
+
+// Encrypt encrypts the given plaintext with a new data key of the KMS key of the encryptor.
+func (encryptor KmsEncryptor) Encrypt(t testing.TestingT, plaintext []byte) ([]byte, error) {
+	client, err := aws.NewKmsClientE(t, encryptor.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := client.GenerateDataKey(context.Background(), &kms.GenerateDataKeyInput{
+		KeyId:   &encryptor.KeyId,
+		KeySpec: types.DataKeySpecAes256,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGcm(dataKey.Plaintext)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(kmsEncryptedTestData{
+		EncryptedDataKey: dataKey.CiphertextBlob,
+		Nonce:            nonce,
+		Ciphertext:       gcm.Seal(nil, nonce, plaintext, nil),
+	})
+}
+
+// Decrypt decrypts the given ciphertext with its data key, decrypted by the KMS key of the encryptor.
+func (encryptor KmsEncryptor) Decrypt(t testing.TestingT, ciphertext []byte) ([]byte, error) {
+	var encrypted kmsEncryptedTestData
+	if err := json.Unmarshal(ciphertext, &encrypted); err != nil {
+		return nil, err
+	}
+
+	client, err := aws.NewKmsClientE(t, encryptor.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := client.Decrypt(context.Background(), &kms.DecryptInput{
+		KeyId:          &encryptor.KeyId,
+		CiphertextBlob: encrypted.EncryptedDataKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGcm(dataKey.Plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Open(nil, encrypted.Nonce, encrypted.Ciphertext, nil)
+}
+
+// newGcm returns an AES-GCM cipher with the given key.
+func newGcm(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptTestData encrypts the given JSON of test data with DefaultEncryptor, if set.
+func encryptTestData(t testing.TestingT, data []byte) ([]byte, error) {
+	if DefaultEncryptor == nil {
+		return data, nil
+	}
+	ciphertext, err := DefaultEncryptor.Encrypt(t, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt test data: %w", err)
+	}
+	return json.Marshal(encryptedTestData{Ciphertext: ciphertext})
+}
+
+// readTestData reads the test data at the given path, decrypting it with DefaultEncryptor if it was saved encrypted.
+func readTestData(t testing.TestingT, path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var encrypted encryptedTestData
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) || json.Unmarshal(data, &encrypted) != nil || encrypted.Ciphertext == nil {
+		return data, nil
+	}
+	if DefaultEncryptor == nil {
+		return nil, fmt.Errorf("test data at %s is encrypted, but no encryptor is set, see %s and %s", path, TestDataAgeKeyEnvVar, TestDataKmsKeyEnvVar)
+	}
+	plaintext, err := DefaultEncryptor.Decrypt(t, encrypted.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt test data at %s: %w", path, err)
+	}
+	return plaintext, nil
+}
+
+// invalidEncryptor is the DefaultEncryptor when the environment variables setting it are invalid, so that saving and
+// loading test data fails instead of saving it as plaintext.
+type invalidEncryptor struct {
+	err error
+}
+
+func (encryptor invalidEncryptor) Encrypt(t testing.TestingT, plaintext []byte) ([]byte, error) {
+	return nil, encryptor.err
+}
+
+func (encryptor invalidEncryptor) Decrypt(t testing.TestingT, ciphertext []byte) ([]byte, error) {
+	return nil, encryptor.err
+}
+
+// encryptorFromEnv returns the encryptor set with the TERRATEST_TEST_DATA_AGE_KEY or TERRATEST_TEST_DATA_KMS_KEY
+// environment variables, or nil if neither is set.
+func encryptorFromEnv() Encryptor {
+	if secretKey := os.Getenv(TestDataAgeKeyEnvVar); secretKey != "" {
+		identity, err := age.ParseX25519Identity(strings.TrimSpace(secretKey))
+		if err != nil {
+			return invalidEncryptor{fmt.Errorf("invalid %s: %w", TestDataAgeKeyEnvVar, err)}
+		}
+		return AgeEncryptor{Identity: identity}
+	}
+
+	if keyId := os.Getenv(TestDataKmsKeyEnvVar); keyId != "" {
+		region := os.Getenv(TestDataKmsRegionEnvVar)
+		// arn:aws:kms:<region>:<account>:key/<id>
+		if arnParts := strings.Split(keyId, ":"); region == "" && len(arnParts) > 3 && arnParts[0] == "arn" {
+			region = arnParts[3]
+		}
+		if region == "" {
+			return invalidEncryptor{fmt.Errorf("%s must be set if %s is not a key ARN", TestDataKmsRegionEnvVar, TestDataKmsKeyEnvVar)}
+		}
+		return KmsEncryptor{Region: region, KeyId: keyId}
+	}
+
+	return nil
+}
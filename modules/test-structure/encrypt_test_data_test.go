@@ -0,0 +1,80 @@
+package test_structure
+
+import (
+	"os"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveAndLoadEncryptedTestData(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	def, defLogger, slogger := DefaultEncryptor, logger.Default, &tStringLogger{}
+	DefaultEncryptor = AgeEncryptor{Identity: identity}
+	logger.Default = logger.New(slogger)
+	t.Cleanup(func() {
+		DefaultEncryptor = def
+		logger.Default = defLogger
+	})
+
+	tmpFolder := t.TempDir()
+	SaveString(t, tmpFolder, "password", "hunter2")
+	SaveValue(t, tmpFolder, "data", testData{Foo: "secret"})
+
+	saved, err := os.ReadFile(formatNamedTestDataPath(tmpFolder, "password"))
+	require.NoError(t, err)
+	assert.Contains(t, string(saved), "terratestEncryptedTestData")
+	assert.NotContains(t, string(saved), "hunter2")
+	assert.NotContains(t, slogger.sb.String(), "hunter2", "encrypted test data should not be logged")
+	assert.NotContains(t, slogger.sb.String(), "secret", "encrypted test data should not be logged")
+
+	assert.Equal(t, "hunter2", LoadString(t, tmpFolder, "password"))
+	assert.Equal(t, testData{Foo: "secret"}, LoadValue[testData](t, tmpFolder, "data"))
+
+	// Empty encrypted test data is not present, so it is overwritten by SaveTestData without overwrite
+	emptyPath := formatNamedTestDataPath(tmpFolder, "empty")
+	SaveTestData(t, emptyPath, true, "")
+	assert.False(t, IsTestDataPresent(t, emptyPath))
+
+	// Test data saved as plaintext is still loaded
+	require.NoError(t, os.WriteFile(formatNamedTestDataPath(tmpFolder, "plaintext"), []byte(`"foo"`), 0644))
+	assert.Equal(t, "foo", LoadString(t, tmpFolder, "plaintext"))
+
+	// Encrypted test data is not loaded with another key, or without one
+	otherIdentity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	DefaultEncryptor = AgeEncryptor{Identity: otherIdentity}
+	_, err = readTestData(t, formatNamedTestDataPath(tmpFolder, "password"))
+	assert.ErrorContains(t, err, "failed to decrypt test data")
+
+	DefaultEncryptor = nil
+	_, err = readTestData(t, formatNamedTestDataPath(tmpFolder, "password"))
+	assert.ErrorContains(t, err, "is encrypted, but no encryptor is set")
+}
+
+func TestEncryptorFromEnv(t *testing.T) {
+	assert.Nil(t, encryptorFromEnv())
+
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	t.Setenv(TestDataAgeKeyEnvVar, identity.String())
+	assert.Equal(t, AgeEncryptor{Identity: identity}, encryptorFromEnv())
+
+	t.Setenv(TestDataAgeKeyEnvVar, "not-a-key")
+	assert.IsType(t, invalidEncryptor{}, encryptorFromEnv())
+
+	t.Setenv(TestDataAgeKeyEnvVar, "")
+	t.Setenv(TestDataKmsKeyEnvVar, "arn:aws:kms:eu-west-1:123456789012:key/1234abcd")
+	assert.Equal(t, KmsEncryptor{Region: "eu-west-1", KeyId: "arn:aws:kms:eu-west-1:123456789012:key/1234abcd"}, encryptorFromEnv())
+
+	t.Setenv(TestDataKmsKeyEnvVar, "alias/terratest")
+	assert.IsType(t, invalidEncryptor{}, encryptorFromEnv())
+
+	t.Setenv(TestDataKmsRegionEnvVar, "us-east-2")
+	assert.Equal(t, KmsEncryptor{Region: "us-east-2", KeyId: "alias/terratest"}, encryptorFromEnv())
+}
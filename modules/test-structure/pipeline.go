@@ -0,0 +1,487 @@
+package test_structure
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// Stage is a test stage (e.g., setup, deploy, validation, teardown) of a Pipeline.
+type Stage struct {
+	Name string
+	// The names of the stages that must run before this stage. The stage doesn't run if one of them fails, unless
+	// Always is set. Stages that are skipped, e.g. because they ran in an earlier run of the test, don't prevent the
+	// stage from running.
+	DependsOn []string
+	// The conditions to skip the stage on, e.g. SkipIfEnvVarSet("SKIP_teardown"). The stage is skipped if any of them
+	// is true.
+	SkipIf []SkipCondition
+	// If true, the stage runs even if some of its dependencies failed, e.g. to undeploy what was deployed.
+	Always bool
+	// If true, the stage runs concurrently with the other parallel stages, once its dependencies are done, e.g. to
+	// build an AMI while creating a network. Stages that are not parallel run alone, after all the stages before them.
+	Parallel bool
+	// The function that runs the stage. It can fail the test, e.g. with t.Fatal, without preventing the other stages
+	// from running.
+	Run func(stage *StageContext)
+}
+
+// Pipeline is a set of test stages that run in the order of their dependencies, and pass values to each other with
+// SetStageValue and GetStageValue.
+type Pipeline struct {
+	// The folder where the values of the stages are saved, so that a stage can get the values of a stage that ran in an
+	// earlier run of the test and is skipped in this one. If empty, the values are only kept in memory.
+	TestFolder string
+	Stages     []Stage
+	// The maximum number of parallel stages that run at the same time. If 0, there is no limit.
+	MaxParallelStages int
+}
+
+// StageStatus is the status of a stage after a pipeline ran.
+type StageStatus string
+
+const (
+	// StageSucceeded is the status of the stages that ran without failing the test.
+	StageSucceeded StageStatus = "succeeded"
+	// StageFailed is the status of the stages that failed the test.
+	StageFailed StageStatus = "failed"
+	// StageSkipped is the status of the stages skipped because of one of their skip conditions.
+	StageSkipped StageStatus = "skipped"
+	// StageBlocked is the status of the stages that didn't run because one of their dependencies failed or was blocked.
+	StageBlocked StageStatus = "blocked"
+)
+
+// StageResult is the result of a stage after a pipeline ran.
+type StageResult struct {
+	Status   StageStatus
+	Reason   string   // why the stage was skipped or blocked
+	Errors   []string // the errors the stage failed the test with
+	Duration time.Duration
+}
+
+// PipelineResults are the results of the stages of a pipeline that ran so far, by stage name.
+type PipelineResults map[string]StageResult
+
+// Failed returns the names of the stages that failed, sorted.
+func (results PipelineResults) Failed() []string {
+	var failed []string
+	for stageName, result := range results {
+		if result.Status == StageFailed {
+			failed = append(failed, stageName)
+		}
+	}
+	sort.Strings(failed)
+	return failed
+}
+
+// SkipCondition returns true, with the reason, if a stage should be skipped, given the results of the stages that ran
+// before it.
+type SkipCondition func(results PipelineResults) (bool, string)
+
+// SkipIfEnvVarSet returns a condition skipping a stage if the given environment variable is set, e.g. SKIP_deploy to
+// reuse what an earlier run of the test deployed.
+func SkipIfEnvVarSet(envVarName string) SkipCondition {
+	return func(PipelineResults) (bool, string) {
+		if os.Getenv(envVarName) != "" {
+			return true, fmt.Sprintf("the '%s' environment variable is set", envVarName)
+		}
+		return false, ""
+	}
+}
+
+// SkipIfStageFailed returns a condition skipping a stage if the given stage failed, e.g. to not undeploy what a failed
+// deployment created so it can be debugged.
+func SkipIfStageFailed(stageName string) SkipCondition {
+	return func(results PipelineResults) (bool, string) {
+		if results[stageName].Status == StageFailed {
+			return true, fmt.Sprintf("stage '%s' failed", stageName)
+		}
+		return false, ""
+	}
+}
+
+// SkipIfStageSkipped returns a condition skipping a stage if the given stage was skipped.
+func SkipIfStageSkipped(stageName string) SkipCondition {
+	return func(results PipelineResults) (bool, string) {
+		if results[stageName].Status == StageSkipped {
+			return true, fmt.Sprintf("stage '%s' was skipped", stageName)
+		}
+		return false, ""
+	}
+}
+
+// SkipIfAnyStageFailed returns a condition skipping a stage if any stage that ran before it failed.
+func SkipIfAnyStageFailed() SkipCondition {
+	return func(results PipelineResults) (bool, string) {
+		for stageName, result := range results {
+			if result.Status == StageFailed {
+				return true, fmt.Sprintf("stage '%s' failed", stageName)
+			}
+		}
+		return false, ""
+	}
+}
+
+// StageContext is passed to the function running a stage.
+type StageContext struct {
+	// T fails the stage, without preventing the other stages from running, if the test fails with it.
+	T testing.TestingT
+	// The name of the stage.
+	Name string
+	// The folder where the values of the stages are saved, if any.
+	TestFolder string
+
+	values *stageValues
+}
+
+// stageValues holds the values the stages of a pipeline set, by name.
+type stageValues struct {
+	mutex  sync.Mutex
+	values map[string]interface{}
+}
+
+// SetStageValue sets a uniquely named value that the stages that run after this one can get with GetStageValue. If the
+// pipeline has a test folder, the value is also saved with SaveValue, so it can be reused by later runs of the test.
+func SetStageValue[T any](stage *StageContext, name string, val T) {
+	stage.values.mutex.Lock()
+	stage.values.values[name] = val
+	stage.values.mutex.Unlock()
+
+	if stage.TestFolder != "" {
+		SaveValue(stage.T, stage.TestFolder, name, val)
+	}
+}
+
+// GetStageValue returns a uniquely named value set by a stage that ran before this one. If no stage set it in this run
+// of the pipeline, the value is loaded with LoadValue from the test folder of the pipeline, where an earlier run of the
+// test saved it.
+func GetStageValue[T any](stage *StageContext, name string) T {
+	stage.values.mutex.Lock()
+	val, ok := stage.values.values[name]
+	stage.values.mutex.Unlock()
+
+	if ok {
+		typedVal, ok := val.(T)
+		if !ok {
+			stage.T.Fatalf("Stage value '%s' is a %T, not a %s", name, val, savedValueType[T]())
+		}
+		return typedVal
+	}
+
+	if stage.TestFolder == "" {
+		stage.T.Fatalf("No stage set the value '%s' before stage '%s'", name, stage.Name)
+	}
+	return LoadValue[T](stage.T, stage.TestFolder, name)
+}
+
+// Validate returns an error if the stages of the pipeline are invalid, e.g. have duplicate names, or depend on stages
+// that don't exist or on each other.
+func (pipeline *Pipeline) Validate() error {
+	_, err := pipeline.orderedStages()
+	return err
+}
+
+// Run runs the stages of the pipeline in the order of their dependencies, or in the order they're declared in if they
+// don't depend on each other, and returns their results. Parallel stages run concurrently. The test fails if the stages
+// are invalid.
+func (pipeline *Pipeline) Run(t testing.TestingT) PipelineResults {
+	stages, err := pipeline.orderedStages()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run := &pipelineRun{
+		pipeline: pipeline,
+		t:        t,
+		values:   &stageValues{values: map[string]interface{}{}},
+		results:  PipelineResults{},
+		done:     map[string]chan struct{}{},
+	}
+	for _, stage := range stages {
+		run.done[stage.Name] = make(chan struct{})
+	}
+
+	var workers chan struct{}
+	if pipeline.MaxParallelStages > 0 {
+		workers = make(chan struct{}, pipeline.MaxParallelStages)
+	}
+
+	var parallelStages sync.WaitGroup
+	for _, stage := range stages {
+		if !stage.Parallel {
+			parallelStages.Wait()
+			run.runStage(stage)
+			continue
+		}
+
+		parallelStages.Add(1)
+		go func(stage Stage) {
+			defer parallelStages.Done()
+			for _, dependency := range stage.DependsOn {
+				<-run.done[dependency]
+			}
+			if workers != nil {
+				workers <- struct{}{}
+				defer func() { <-workers }()
+			}
+			run.runStage(stage)
+		}(stage)
+	}
+	parallelStages.Wait()
+
+	run.reportFailures(stages)
+	return run.results
+}
+
+// pipelineRun is the state of a pipeline while it runs.
+type pipelineRun struct {
+	pipeline *Pipeline
+	t        testing.TestingT
+	values   *stageValues
+
+	mutex   sync.Mutex
+	results PipelineResults
+	done    map[string]chan struct{} // closed once the stage with the name has its result
+}
+
+// runStage runs the given stage unless it's blocked by its dependencies or skipped by its conditions, and records its
+// result.
+func (run *pipelineRun) runStage(stage Stage) {
+	result := run.stageResult(stage)
+
+	run.mutex.Lock()
+	run.results[stage.Name] = result
+	run.mutex.Unlock()
+	close(run.done[stage.Name])
+}
+
+// stageResult runs the given stage unless it's blocked by its dependencies or skipped by its conditions, and returns
+// its result.
+func (run *pipelineRun) stageResult(stage Stage) StageResult {
+	t := run.t
+
+	run.mutex.Lock()
+	results := make(PipelineResults, len(run.results))
+	for stageName, result := range run.results {
+		results[stageName] = result
+	}
+	run.mutex.Unlock()
+
+	if !stage.Always {
+		for _, dependency := range stage.DependsOn {
+			if status := results[dependency].Status; status == StageFailed || status == StageBlocked {
+				reason := fmt.Sprintf("stage '%s' %s", dependency, status)
+				logger.Default.Logf(t, "Not executing stage '%s', as %s.", stage.Name, reason)
+				return StageResult{Status: StageBlocked, Reason: reason}
+			}
+		}
+	}
+
+	for _, condition := range stage.SkipIf {
+		if skip, reason := condition(results); skip {
+			logger.Default.Logf(t, "Skipping stage '%s', as %s.", stage.Name, reason)
+			return StageResult{Status: StageSkipped, Reason: reason}
+		}
+	}
+
+	logger.Default.Logf(t, "Executing stage '%s'.", stage.Name)
+	start := time.Now()
+	stageT := &stageT{TestingT: t, name: stage.Name}
+	stageT.run(func() {
+		runTracedStage(stageT, stage.Name, func() {
+			stage.Run(&StageContext{T: stageT, Name: stage.Name, TestFolder: run.pipeline.TestFolder, values: run.values})
+		})
+	})
+	// The current stage of the pipeline test is set from the results once all the stages ran
+	setCurrentStage(stageT, "")
+
+	result := StageResult{Status: StageSucceeded, Duration: time.Since(start)}
+	if errors, failed := stageT.failures(); failed {
+		result.Status = StageFailed
+		result.Errors = errors
+	}
+	return result
+}
+
+// reportFailures logs the errors of all the stages that failed, in the order of the given stages, so that the
+// failures of parallel stages are not lost in their interleaved logs, and sets the first of them as the current stage.
+func (run *pipelineRun) reportFailures(stages []Stage) {
+	var failed []string
+	var report strings.Builder
+	for _, stage := range stages {
+		result := run.results[stage.Name]
+		if result.Status != StageFailed {
+			continue
+		}
+		failed = append(failed, stage.Name)
+		fmt.Fprintf(&report, "\n- %s: %s", stage.Name, strings.Join(result.Errors, "\n"))
+	}
+
+	// The stages that ran after the first failure, e.g. teardown, reset the current stage
+	if len(failed) == 0 {
+		setCurrentStage(run.t, "")
+		return
+	}
+	setCurrentStage(run.t, failed[0])
+	logger.Default.Logf(run.t, "%d of %d stages failed:%s", len(failed), len(stages), report.String())
+}
+
+// orderedStages returns the stages of the pipeline in the order of their dependencies, keeping the order they're
+// declared in otherwise, or an error if the stages are invalid.
+func (pipeline *Pipeline) orderedStages() ([]Stage, error) {
+	stagesByName := map[string]bool{}
+	for _, stage := range pipeline.Stages {
+		if stage.Name == "" {
+			return nil, fmt.Errorf("a stage of the pipeline has no name")
+		}
+		if stage.Run == nil {
+			return nil, fmt.Errorf("stage '%s' has no Run function", stage.Name)
+		}
+		if stagesByName[stage.Name] {
+			return nil, fmt.Errorf("there are several stages named '%s'", stage.Name)
+		}
+		stagesByName[stage.Name] = true
+	}
+	for _, stage := range pipeline.Stages {
+		for _, dependency := range stage.DependsOn {
+			if !stagesByName[dependency] {
+				return nil, fmt.Errorf("stage '%s' depends on stage '%s', which doesn't exist", stage.Name, dependency)
+			}
+		}
+	}
+
+	ordered := make([]Stage, 0, len(pipeline.Stages))
+	isOrdered := map[string]bool{}
+	for len(ordered) < len(pipeline.Stages) {
+		progressed := false
+		for _, stage := range pipeline.Stages {
+			if !isOrdered[stage.Name] && allOrdered(stage.DependsOn, isOrdered) {
+				ordered = append(ordered, stage)
+				isOrdered[stage.Name] = true
+				progressed = true
+				break
+			}
+		}
+		if !progressed {
+			var cyclic []string
+			for _, stage := range pipeline.Stages {
+				if !isOrdered[stage.Name] {
+					cyclic = append(cyclic, stage.Name)
+				}
+			}
+			return nil, fmt.Errorf("stages %s depend on each other", strings.Join(cyclic, ", "))
+		}
+	}
+	return ordered, nil
+}
+
+// allOrdered returns true if all the given stages are ordered.
+func allOrdered(stageNames []string, isOrdered map[string]bool) bool {
+	for _, stageName := range stageNames {
+		if !isOrdered[stageName] {
+			return false
+		}
+	}
+	return true
+}
+
+// stageT is the TestingT of a stage, which fails the test without stopping it, so that the other stages can still
+// run: a stage stopped with FailNow, e.g. by t.Fatal, only stops the goroutine running the stage. It's named like a
+// subtest of the pipeline test, so that the state kept by test name, such as the current stage and the open tracing
+// spans, is kept apart for the stages running in parallel.
+type stageT struct {
+	testing.TestingT
+	name   string
+	mutex  sync.Mutex
+	failed bool
+	errors []string
+}
+
+// run runs the given function in a goroutine, waits for it to return or to be stopped with FailNow, and fails the
+// stage if it panics.
+func (t *stageT) run(stage func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			if r := recover(); r != nil {
+				t.Errorf("Stage panicked: %v\n%s", r, debug.Stack())
+			}
+		}()
+		stage()
+	}()
+	<-done
+}
+
+// Name returns the name of the pipeline test followed by the name of the stage, with spaces replaced by underscores
+// like the names of subtests.
+func (t *stageT) Name() string {
+	return t.TestingT.Name() + "/" + strings.ReplaceAll(t.name, " ", "_")
+}
+
+func (t *stageT) Helper() {
+	if tt, ok := t.TestingT.(interface{ Helper() }); ok {
+		tt.Helper()
+	}
+}
+
+func (t *stageT) Fail() {
+	t.Helper()
+	t.setFailed("")
+	t.TestingT.Fail()
+}
+
+func (t *stageT) FailNow() {
+	t.Helper()
+	t.Fail()
+	runtime.Goexit()
+}
+
+func (t *stageT) Fatal(args ...interface{}) {
+	t.Helper()
+	t.Error(args...)
+	runtime.Goexit()
+}
+
+func (t *stageT) Fatalf(format string, args ...interface{}) {
+	t.Helper()
+	t.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+func (t *stageT) Error(args ...interface{}) {
+	t.Helper()
+	t.setFailed(fmt.Sprint(args...))
+	t.TestingT.Error(args...)
+}
+
+func (t *stageT) Errorf(format string, args ...interface{}) {
+	t.Helper()
+	t.setFailed(fmt.Sprintf(format, args...))
+	t.TestingT.Errorf(format, args...)
+}
+
+// failures returns the errors the stage failed the test with, and true if it failed it.
+func (t *stageT) failures() ([]string, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.errors, t.failed
+}
+
+// setFailed records that the stage failed the test, with the given error, if any.
+func (t *stageT) setFailed(err string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.failed = true
+	if err != "" {
+		t.errors = append(t.errors, err)
+	}
+}
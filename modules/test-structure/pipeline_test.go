@@ -0,0 +1,239 @@
+package test_structure
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingT is a TestingT that records whether the test failed, for the tests of pipelines whose stages fail.
+type recordingT struct {
+	name   string
+	mutex  sync.Mutex
+	failed bool
+	errors []string
+}
+
+func (t *recordingT) Fail() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.failed = true
+}
+func (t *recordingT) FailNow()                                  { panic("FailNow should not be called by a pipeline") }
+func (t *recordingT) Fatal(args ...interface{})                 { t.FailNow() }
+func (t *recordingT) Fatalf(format string, args ...interface{}) { t.FailNow() }
+func (t *recordingT) Error(args ...interface{})                 { t.Errorf("%s", fmt.Sprint(args...)) }
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.Fail()
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+func (t *recordingT) Name() string { return t.name }
+
+func TestPipelineRunsStagesInDependencyOrder(t *testing.T) {
+	t.Parallel()
+
+	var ran []string
+	stage := func(name string, dependsOn ...string) Stage {
+		return Stage{Name: name, DependsOn: dependsOn, Run: func(stage *StageContext) {
+			ran = append(ran, stage.Name)
+		}}
+	}
+
+	pipeline := &Pipeline{Stages: []Stage{
+		stage("teardown", "validate"),
+		stage("validate", "deploy"),
+		stage("setup"),
+		stage("deploy", "setup"),
+	}}
+	results := pipeline.Run(t)
+
+	assert.Equal(t, []string{"setup", "deploy", "validate", "teardown"}, ran)
+	for _, name := range ran {
+		assert.Equal(t, StageSucceeded, results[name].Status)
+	}
+}
+
+func TestPipelineSkipsAndBlocksStages(t *testing.T) {
+	t.Setenv("SKIP_TestPipelineSkipsAndBlocksStages_setup", "true")
+
+	var ran []string
+	record := func(stage *StageContext) { ran = append(ran, stage.Name) }
+
+	fakeT := &recordingT{name: "TestPipelineSkipsAndBlocksStagesFake"}
+	pipeline := &Pipeline{Stages: []Stage{
+		{Name: "setup", SkipIf: []SkipCondition{SkipIfEnvVarSet("SKIP_TestPipelineSkipsAndBlocksStages_setup")}, Run: record},
+		{Name: "deploy", DependsOn: []string{"setup"}, Run: func(stage *StageContext) {
+			record(stage)
+			require.NoError(stage.T, fmt.Errorf("apply failed"))
+			ran = append(ran, "unreachable")
+		}},
+		{Name: "validate", DependsOn: []string{"deploy"}, Run: record},
+		{Name: "report", DependsOn: []string{"validate"}, Run: record},
+		{Name: "teardown", DependsOn: []string{"deploy"}, Always: true, Run: record},
+		{Name: "keep", SkipIf: []SkipCondition{SkipIfAnyStageFailed()}, Run: record},
+	}}
+	results := pipeline.Run(fakeT)
+
+	assert.Equal(t, []string{"deploy", "teardown"}, ran)
+	assert.True(t, fakeT.failed)
+	require.Len(t, fakeT.errors, 1)
+	assert.Contains(t, fakeT.errors[0], "apply failed")
+
+	assert.Equal(t, StageSkipped, results["setup"].Status)
+	assert.Equal(t, StageFailed, results["deploy"].Status)
+	assert.Equal(t, StageResult{Status: StageBlocked, Reason: "stage 'deploy' failed"}, results["validate"])
+	assert.Equal(t, StageResult{Status: StageBlocked, Reason: "stage 'validate' blocked"}, results["report"])
+	assert.Equal(t, StageSucceeded, results["teardown"].Status)
+	assert.Equal(t, StageResult{Status: StageSkipped, Reason: "stage 'deploy' failed"}, results["keep"])
+	assert.Equal(t, "deploy", CurrentStage(fakeT))
+}
+
+func TestPipelinePassesStageValues(t *testing.T) {
+	t.Parallel()
+
+	tmpFolder := t.TempDir()
+	setup := Stage{Name: "setup", Run: func(stage *StageContext) {
+		SetStageValue(stage, "data", testData{Foo: "foo"})
+	}}
+	var loaded testData
+	validate := Stage{Name: "validate", DependsOn: []string{"setup"}, Run: func(stage *StageContext) {
+		loaded = GetStageValue[testData](stage, "data")
+	}}
+
+	(&Pipeline{TestFolder: tmpFolder, Stages: []Stage{setup, validate}}).Run(t)
+	assert.Equal(t, testData{Foo: "foo"}, loaded)
+
+	// A later run of the test that skips the setup loads the value it saved
+	loaded = testData{}
+	setup.SkipIf = []SkipCondition{func(PipelineResults) (bool, string) { return true, "it already ran" }}
+	(&Pipeline{TestFolder: tmpFolder, Stages: []Stage{setup, validate}}).Run(t)
+	assert.Equal(t, testData{Foo: "foo"}, loaded)
+
+	// Without a test folder, the value of a skipped stage is missing
+	fakeT := &recordingT{name: "TestPipelinePassesStageValuesFake"}
+	results := (&Pipeline{Stages: []Stage{setup, validate}}).Run(fakeT)
+	assert.Equal(t, StageFailed, results["validate"].Status)
+	assert.Contains(t, fakeT.errors[0], "No stage set the value 'data' before stage 'validate'")
+}
+
+func TestPipelineValidate(t *testing.T) {
+	t.Parallel()
+
+	run := func(*StageContext) {}
+	testCases := []struct {
+		name          string
+		stages        []Stage
+		expectedError string
+	}{
+		{"valid", []Stage{{Name: "a", Run: run}, {Name: "b", DependsOn: []string{"a"}, Run: run}}, ""},
+		{"no name", []Stage{{Run: run}}, "a stage of the pipeline has no name"},
+		{"no run", []Stage{{Name: "a"}}, "stage 'a' has no Run function"},
+		{"duplicate", []Stage{{Name: "a", Run: run}, {Name: "a", Run: run}}, "there are several stages named 'a'"},
+		{"unknown dependency", []Stage{{Name: "a", DependsOn: []string{"b"}, Run: run}}, "stage 'a' depends on stage 'b', which doesn't exist"},
+		{"cycle", []Stage{
+			{Name: "a", Run: run},
+			{Name: "b", DependsOn: []string{"c"}, Run: run},
+			{Name: "c", DependsOn: []string{"b"}, Run: run},
+		}, "stages b, c depend on each other"},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			err := (&Pipeline{Stages: testCase.stages}).Validate()
+			if testCase.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, testCase.expectedError)
+			}
+		})
+	}
+}
+
+func TestPipelineRunsParallelStagesConcurrently(t *testing.T) {
+	t.Parallel()
+
+	// Both stages only return once the other one started, so they must run at the same time, each with its own current
+	// stage
+	var started sync.WaitGroup
+	started.Add(2)
+	parallelStage := func(name string) Stage {
+		return Stage{Name: name, Parallel: true, Run: func(stage *StageContext) {
+			started.Done()
+			started.Wait()
+			assert.Equal(t, name, CurrentStage(stage.T))
+			SetStageValue(stage, name, name+"-id")
+		}}
+	}
+
+	var ran []string
+	pipeline := &Pipeline{Stages: []Stage{
+		parallelStage("build_ami"),
+		parallelStage("create_network"),
+		{Name: "deploy", DependsOn: []string{"build_ami", "create_network"}, Run: func(stage *StageContext) {
+			ran = append(ran, GetStageValue[string](stage, "build_ami"), GetStageValue[string](stage, "create_network"))
+		}},
+	}}
+	results := pipeline.Run(t)
+
+	assert.Equal(t, []string{"build_ami-id", "create_network-id"}, ran)
+	assert.Empty(t, results.Failed())
+}
+
+func TestPipelineLimitsParallelStages(t *testing.T) {
+	t.Parallel()
+
+	var mutex sync.Mutex
+	running, maxRunning := 0, 0
+	stages := make([]Stage, 0, 6)
+	for i := 0; i < cap(stages); i++ {
+		stages = append(stages, Stage{Name: fmt.Sprintf("stage-%d", i), Parallel: true, Run: func(*StageContext) {
+			mutex.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mutex.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mutex.Lock()
+			running--
+			mutex.Unlock()
+		}})
+	}
+
+	(&Pipeline{Stages: stages, MaxParallelStages: 2}).Run(t)
+	assert.Equal(t, 2, maxRunning)
+}
+
+func TestPipelineAggregatesParallelFailures(t *testing.T) {
+	t.Parallel()
+
+	fakeT := &recordingT{name: "TestPipelineAggregatesParallelFailuresFake"}
+	failingStage := func(name string) Stage {
+		return Stage{Name: name, Parallel: true, Run: func(stage *StageContext) {
+			stage.T.Fatalf("%s failed", name)
+		}}
+	}
+	pipeline := &Pipeline{Stages: []Stage{
+		failingStage("build_ami"),
+		failingStage("create_network"),
+		{Name: "create_bucket", Parallel: true, Run: func(*StageContext) {}},
+		{Name: "deploy", DependsOn: []string{"build_ami", "create_network", "create_bucket"}, Run: func(*StageContext) {}},
+	}}
+	results := pipeline.Run(fakeT)
+
+	assert.True(t, fakeT.failed)
+	assert.Equal(t, []string{"build_ami", "create_network"}, results.Failed())
+	assert.Equal(t, []string{"build_ami failed"}, results["build_ami"].Errors)
+	assert.Equal(t, []string{"create_network failed"}, results["create_network"].Errors)
+	assert.Equal(t, StageSucceeded, results["create_bucket"].Status)
+	assert.Equal(t, StageBlocked, results["deploy"].Status)
+	assert.Equal(t, "build_ami", CurrentStage(fakeT))
+}
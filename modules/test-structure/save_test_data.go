@@ -13,6 +13,7 @@ import (
 	"github.com/gruntwork-io/terratest/modules/packer"
 	"github.com/gruntwork-io/terratest/modules/ssh"
 	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/gruntwork-io/terratest/modules/terragrunt"
 	"github.com/gruntwork-io/terratest/modules/testing"
 	"github.com/stretchr/testify/require"
 )
@@ -43,6 +44,23 @@ func formatTerraformOptionsPath(testFolder string) string {
 	return FormatTestDataPath(testFolder, "TerraformOptions.json")
 }
 
+// SaveTerragruntOptions serializes and saves terragrunt Options into the given folder, tagged with their type and schema
+// version. This allows you to create terragrunt Options during setup and to reuse them later during validation and
+// teardown.
+func SaveTerragruntOptions(t testing.TestingT, testFolder string, terragruntOptions *terragrunt.Options) {
+	SaveValue(t, testFolder, terragruntOptionsName, terragruntOptions)
+}
+
+// LoadTerragruntOptions loads and unserializes terragrunt Options from the given folder. This allows you to reuse
+// terragrunt Options that were created during an earlier setup step in later validation and teardown steps. The test
+// fails if the saved data are not terragrunt Options.
+func LoadTerragruntOptions(t testing.TestingT, testFolder string) *terragrunt.Options {
+	return LoadValue[*terragrunt.Options](t, testFolder, terragruntOptionsName)
+}
+
+// terragruntOptionsName is the name terragrunt Options are saved with in the given folder.
+const terragruntOptionsName = "TerragruntOptions"
+
 // SavePackerOptions serializes and saves PackerOptions into the given folder. This allows you to create PackerOptions during setup
 // and to reuse that PackerOptions later during validation and teardown.
 func SavePackerOptions(t testing.TestingT, testFolder string, packerOptions *packer.Options) {
@@ -204,11 +222,18 @@ func SaveTestData(t testing.TestingT, path string, overwrite bool, value interfa
 func saveTestData(t testing.TestingT, path string, overwrite bool, value interface{}, loggedVal bool) {
 	logger.Default.Logf(t, "Storing test data in %s so it can be reused later", path)
 
+	// The values of encrypted test data are not logged, as they're encrypted to keep them secret
+	loggedVal = loggedVal && DefaultEncryptor == nil
+	var loggedValue interface{} = "<not logged>"
+	if loggedVal {
+		loggedValue = value
+	}
+
 	if IsTestDataPresent(t, path) {
 		if overwrite {
-			logger.Default.Logf(t, "[WARNING] The named test data at path %s is non-empty. Save operation will overwrite existing value with \"%v\".\n.", path, value)
+			logger.Default.Logf(t, "[WARNING] The named test data at path %s is non-empty. Save operation will overwrite existing value with \"%v\".\n.", path, loggedValue)
 		} else {
-			logger.Default.Logf(t, "[WARNING] The named test data at path %s is non-empty. Skipping save operation to prevent overwriting existing value with \"%v\".\n.", path, value)
+			logger.Default.Logf(t, "[WARNING] The named test data at path %s is non-empty. Skipping save operation to prevent overwriting existing value with \"%v\".\n.", path, loggedValue)
 			return
 		}
 	}
@@ -222,6 +247,11 @@ func saveTestData(t testing.TestingT, path string, overwrite bool, value interfa
 		logger.Default.Logf(t, "Marshalled JSON: %s", string(bytes))
 	}
 
+	bytes, err = encryptTestData(t, bytes)
+	if err != nil {
+		t.Fatalf("Failed to save value %s: %v", path, err)
+	}
+
 	parentDir := filepath.Dir(path)
 	if err := os.MkdirAll(parentDir, 0777); err != nil {
 		t.Fatalf("Failed to create folder %s: %v", parentDir, err)
@@ -238,7 +268,7 @@ func saveTestData(t testing.TestingT, path string, overwrite bool, value interfa
 func LoadTestData(t testing.TestingT, path string, value interface{}) {
 	logger.Default.Logf(t, "Loading test data from %s", path)
 
-	bytes, err := os.ReadFile(path)
+	bytes, err := readTestData(t, path)
 	if err != nil {
 		t.Fatalf("Failed to load value from %s: %v", path, err)
 	}
@@ -258,7 +288,7 @@ func IsTestDataPresent(t testing.TestingT, path string) bool {
 		return false
 	}
 
-	bytes, err := os.ReadFile(path)
+	bytes, err := readTestData(t, path)
 
 	if err != nil {
 		t.Fatalf("Failed to load test data from %s due to unexpected error: %v", path, err)
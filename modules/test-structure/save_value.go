@@ -0,0 +1,146 @@
+package test_structure
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// DefaultSchemaVersion is the schema version of the values saved with SaveValue whose type doesn't implement
+// SchemaVersioner.
+const DefaultSchemaVersion = 1
+
+// SchemaVersioner is implemented by the types whose saved shape changes over time. Bump the version when changing the
+// fields of the type, so that LoadValue fails on data saved by an earlier version of the test, instead of silently
+// decoding it into the new shape.
+type SchemaVersioner interface {
+	SchemaVersion() int
+}
+
+// savedValue is the JSON envelope SaveValue stores values in, tagging them with their type and schema version.
+type savedValue struct {
+	Type          string          `json:"type"`
+	SchemaVersion int             `json:"schemaVersion"`
+	Value         json.RawMessage `json:"value"`
+}
+
+// String returns the JSON of the saved value, which is how saveTestData logs the value it overwrites.
+func (saved savedValue) String() string {
+	return string(saved.Value)
+}
+
+// SavedValueMismatch is returned when the data loaded with LoadValue was saved with another type or schema version.
+type SavedValueMismatch struct {
+	Path            string
+	ExpectedType    string
+	ActualType      string
+	ExpectedVersion int
+	ActualVersion   int
+}
+
+func (err SavedValueMismatch) Error() string {
+	if err.ActualType == "" {
+		return fmt.Sprintf("Test data at %s was not saved with SaveValue, expected a %s value", err.Path, err.ExpectedType)
+	}
+	return fmt.Sprintf(
+		"Test data at %s is a %s value with schema version %d, expected a %s value with schema version %d",
+		err.Path, err.ActualType, err.ActualVersion, err.ExpectedType, err.ExpectedVersion,
+	)
+}
+
+// SaveValue serializes and saves a uniquely named value of any type into the given folder, tagged with the type and
+// schema version of the value. This allows you to create one or more values during one stage -- each with a unique
+// name -- and to reuse those values with LoadValue during later stages.
+func SaveValue[T any](t testing.TestingT, testFolder string, name string, val T) {
+	path := formatNamedTestDataPath(testFolder, name)
+
+	valueJson, err := json.Marshal(val)
+	if err != nil {
+		t.Fatalf("Failed to convert value %s to JSON: %v", path, err)
+	}
+
+	saveTestData(t, path, true, savedValue{
+		Type:          savedValueType[T](),
+		SchemaVersion: savedValueSchemaVersion[T](),
+		Value:         valueJson,
+	}, true)
+}
+
+// LoadValue loads and unserializes a uniquely named value saved with SaveValue from the given folder. This allows you
+// to reuse one or more values that were created during an earlier setup step in later steps. The test fails if the
+// value was saved with another type or schema version, or has fields that T doesn't have.
+func LoadValue[T any](t testing.TestingT, testFolder string, name string) T {
+	val, err := LoadValueE[T](t, testFolder, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return val
+}
+
+// LoadValueE loads and unserializes a uniquely named value saved with SaveValue from the given folder. It returns a
+// SavedValueMismatch error if the value was saved with another type or schema version.
+func LoadValueE[T any](t testing.TestingT, testFolder string, name string) (T, error) {
+	var val T
+	path := formatNamedTestDataPath(testFolder, name)
+	logger.Default.Logf(t, "Loading test data from %s", path)
+
+	data, err := readTestData(t, path)
+	if err != nil {
+		return val, fmt.Errorf("failed to load value from %s: %w", path, err)
+	}
+
+	var saved savedValue
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return val, fmt.Errorf("failed to parse JSON for value %s: %w", path, err)
+	}
+
+	expectedType, expectedVersion := savedValueType[T](), savedValueSchemaVersion[T]()
+	if saved.Type != expectedType || saved.SchemaVersion != expectedVersion {
+		return val, SavedValueMismatch{
+			Path:            path,
+			ExpectedType:    expectedType,
+			ActualType:      saved.Type,
+			ExpectedVersion: expectedVersion,
+			ActualVersion:   saved.SchemaVersion,
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(saved.Value))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&val); err != nil {
+		return val, fmt.Errorf("failed to parse JSON for %s value %s: %w", expectedType, path, err)
+	}
+	return val, nil
+}
+
+// savedValueType returns the name of type T that values are tagged with.
+func savedValueType[T any]() string {
+	valueType := dereferencedType[T]()
+	if valueType.PkgPath() == "" {
+		return valueType.String()
+	}
+	return valueType.PkgPath() + "." + valueType.Name()
+}
+
+// savedValueSchemaVersion returns the schema version of type T, or DefaultSchemaVersion if it doesn't implement
+// SchemaVersioner.
+func savedValueSchemaVersion[T any]() int {
+	if versioner, ok := reflect.New(dereferencedType[T]()).Interface().(SchemaVersioner); ok {
+		return versioner.SchemaVersion()
+	}
+	return DefaultSchemaVersion
+}
+
+// dereferencedType returns type T, or the type it points to if it's a pointer, as a value and a pointer to it have the
+// same JSON shape.
+func dereferencedType[T any]() reflect.Type {
+	valueType := reflect.TypeFor[T]()
+	for valueType.Kind() == reflect.Pointer {
+		valueType = valueType.Elem()
+	}
+	return valueType
+}
@@ -0,0 +1,96 @@
+package test_structure
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/terragrunt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type versionedTestData struct {
+	Foo string
+}
+
+func (versionedTestData) SchemaVersion() int {
+	return 2
+}
+
+type otherTestData struct {
+	Foo string
+	Qux int
+}
+
+func TestSaveAndLoadValue(t *testing.T) {
+	t.Parallel()
+
+	tmpFolder := t.TempDir()
+
+	expectedData := testData{
+		Foo: "foo",
+		Bar: true,
+		Baz: map[string]interface{}{"abc": "def", "ghi": 1.0, "klm": false},
+	}
+	SaveValue(t, tmpFolder, "data", expectedData)
+	assert.Equal(t, expectedData, LoadValue[testData](t, tmpFolder, "data"))
+	assert.Equal(t, &expectedData, LoadValue[*testData](t, tmpFolder, "data"))
+
+	SaveValue(t, tmpFolder, "durations", []time.Duration{time.Second, time.Minute})
+	assert.Equal(t, []time.Duration{time.Second, time.Minute}, LoadValue[[]time.Duration](t, tmpFolder, "durations"))
+}
+
+func TestLoadValueMismatch(t *testing.T) {
+	t.Parallel()
+
+	tmpFolder := t.TempDir()
+
+	SaveValue(t, tmpFolder, "data", otherTestData{Foo: "foo", Qux: 1})
+	_, err := LoadValueE[testData](t, tmpFolder, "data")
+	var mismatch SavedValueMismatch
+	require.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, "github.com/gruntwork-io/terratest/modules/test-structure.testData", mismatch.ExpectedType)
+	assert.Equal(t, "github.com/gruntwork-io/terratest/modules/test-structure.otherTestData", mismatch.ActualType)
+
+	SaveValue(t, tmpFolder, "versioned", versionedTestData{Foo: "foo"})
+	assert.Equal(t, versionedTestData{Foo: "foo"}, LoadValue[versionedTestData](t, tmpFolder, "versioned"))
+
+	// Data saved by an earlier version of the test whose schema has changed since
+	path := formatNamedTestDataPath(tmpFolder, "versioned")
+	require.NoError(t, os.WriteFile(path, []byte(`{"type": "github.com/gruntwork-io/terratest/modules/test-structure.versionedTestData", "schemaVersion": 1, "value": {"Foo": "foo"}}`), 0644))
+	_, err = LoadValueE[versionedTestData](t, tmpFolder, "versioned")
+	require.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, 2, mismatch.ExpectedVersion)
+	assert.Equal(t, 1, mismatch.ActualVersion)
+
+	// Data with fields the type doesn't have
+	require.NoError(t, os.WriteFile(path, []byte(`{"type": "github.com/gruntwork-io/terratest/modules/test-structure.versionedTestData", "schemaVersion": 2, "value": {"Foo": "foo", "Bar": true}}`), 0644))
+	_, err = LoadValueE[versionedTestData](t, tmpFolder, "versioned")
+	assert.ErrorContains(t, err, `unknown field "Bar"`)
+
+	// Data saved without SaveValue
+	SaveString(t, tmpFolder, "string", "foo")
+	_, err = LoadValueE[string](t, tmpFolder, "string")
+	assert.Error(t, err)
+}
+
+func TestSaveAndLoadTerragruntOptions(t *testing.T) {
+	t.Parallel()
+
+	tmpFolder := t.TempDir()
+
+	expectedData := &terragrunt.Options{
+		TerragruntDir:      "/abc/def/ghi",
+		EnvVars:            map[string]string{"TF_VAR_foo": "bar"},
+		MaxRetries:         3,
+		TimeBetweenRetries: 5 * time.Second,
+		RetryBackoff:       &retry.ExponentialBackoff{InitialDelay: time.Second, MaxDelay: time.Minute},
+		ExtraArgs:          []string{"-no-color"},
+	}
+	SaveTerragruntOptions(t, tmpFolder, expectedData)
+
+	actualData := LoadTerragruntOptions(t, tmpFolder)
+	assert.Equal(t, expectedData, actualData)
+}
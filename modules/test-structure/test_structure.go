@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/gruntwork-io/terratest/modules/git"
 
@@ -15,7 +16,9 @@ import (
 	"github.com/gruntwork-io/terratest/modules/opa"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/gruntwork-io/terratest/modules/tracing"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // SKIP_STAGE_ENV_VAR_PREFIX is the prefix used for skipping stage environment variables.
@@ -27,12 +30,52 @@ func RunTestStage(t testing.TestingT, stageName string, stage func()) {
 	envVarName := fmt.Sprintf("%s%s", SKIP_STAGE_ENV_VAR_PREFIX, stageName)
 	if os.Getenv(envVarName) == "" {
 		logger.Default.Logf(t, "The '%s' environment variable is not set, so executing stage '%s'.", envVarName, stageName)
-		stage()
+		runTracedStage(t, stageName, stage)
 	} else {
 		logger.Default.Logf(t, "The '%s' environment variable is set, so skipping stage '%s'.", envVarName, stageName)
 	}
 }
 
+// runTracedStage executes the given test stage in a tracing span, which has an error status if the stage stops the
+// test, e.g. with t.Fatal, or panics.
+func runTracedStage(t testing.TestingT, stageName string, stage func()) {
+	_, endSpan := tracing.StartSpan(t, "stage "+stageName, attribute.String("terratest.stage", stageName))
+	setCurrentStage(t, stageName)
+	completed := false
+	defer func() {
+		if completed {
+			setCurrentStage(t, "")
+			endSpan(nil)
+		} else {
+			endSpan(fmt.Errorf("stage '%s' did not complete", stageName))
+		}
+	}()
+
+	stage()
+	completed = true
+}
+
+// currentStages holds the stage that is running, or that stopped the test, by test name.
+var currentStages sync.Map
+
+// CurrentStage returns the name of the test stage that is running for the given test, or that stopped it, e.g. with
+// t.Fatal, or an empty string if there's none.
+func CurrentStage(t testing.TestingT) string {
+	if stageName, ok := currentStages.Load(t.Name()); ok {
+		return stageName.(string)
+	}
+	return ""
+}
+
+// setCurrentStage sets the test stage that is running for the given test, or none if stageName is empty.
+func setCurrentStage(t testing.TestingT, stageName string) {
+	if stageName == "" {
+		currentStages.Delete(t.Name())
+	} else {
+		currentStages.Store(t.Name(), stageName)
+	}
+}
+
 // SkipStageEnvVarSet returns true if an environment variable is set instructing Terratest to skip a test stage. This can be an easy way
 // to tell if the tests are running in a local dev environment vs a CI server.
 func SkipStageEnvVarSet() bool {
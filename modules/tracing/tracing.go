@@ -0,0 +1,87 @@
+// Package tracing emits OpenTelemetry spans for test stages, Terraform and Terragrunt commands and retries, so that
+// long test pipelines can be analyzed in a tracing backend to find their slow phases.
+//
+// Spans are only recorded once a tracer provider is set with otel.SetTracerProvider, e.g. in TestMain. Until then they
+// are no-ops. The spans of a test are nested in the span that was started last for that test, or for its parent test,
+// and still open, so stages contain the commands and retries they run.
+package tracing
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gruntwork-io/terratest/modules/testing"
+)
+
+// TracerName is the name of the OpenTelemetry tracer of Terratest.
+const TracerName = "github.com/gruntwork-io/terratest"
+
+// openSpans holds the contexts of the open spans of each test, by test name, in the order they were started.
+var openSpans = struct {
+	sync.Mutex
+	byTest map[string][]context.Context
+}{byTest: map[string][]context.Context{}}
+
+// StartSpan starts a span with the given name and attributes, nested in the current span of the given test, and
+// returns a context with the span, and a function to end it, with the error status of the given error if it's not nil.
+// The span is the current span of the test until it ends.
+func StartSpan(t testing.TestingT, name string, attributes ...attribute.KeyValue) (context.Context, func(err error)) {
+	testName := t.Name()
+	ctx, span := otel.Tracer(TracerName).Start(Context(t), name, trace.WithAttributes(attributes...))
+
+	openSpans.Lock()
+	openSpans.byTest[testName] = append(openSpans.byTest[testName], ctx)
+	openSpans.Unlock()
+
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+		removeSpan(testName, ctx)
+	}
+}
+
+// Context returns a context with the current span of the given test, or of its closest parent test with one, to pass
+// to context-aware functions or to start custom spans in.
+func Context(t testing.TestingT) context.Context {
+	openSpans.Lock()
+	defer openSpans.Unlock()
+
+	for name := t.Name(); ; {
+		if contexts := openSpans.byTest[name]; len(contexts) > 0 {
+			return contexts[len(contexts)-1]
+		}
+		i := strings.LastIndex(name, "/")
+		if i < 0 {
+			return context.Background()
+		}
+		name = name[:i]
+	}
+}
+
+// removeSpan removes the span of the given context from the open spans of the given test.
+func removeSpan(testName string, ctx context.Context) {
+	openSpans.Lock()
+	defer openSpans.Unlock()
+
+	contexts := openSpans.byTest[testName]
+	for i := len(contexts) - 1; i >= 0; i-- {
+		if contexts[i] == ctx {
+			contexts = append(contexts[:i], contexts[i+1:]...)
+			break
+		}
+	}
+	if len(contexts) == 0 {
+		delete(openSpans.byTest, testName)
+	} else {
+		openSpans.byTest[testName] = contexts
+	}
+}
@@ -0,0 +1,48 @@
+package tracing
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Not parallel, as it sets the global tracer provider
+func TestStartSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	assert.False(t, trace.SpanFromContext(Context(t)).SpanContext().IsValid())
+
+	stageCtx, endStage := StartSpan(t, "stage deploy", attribute.String("terratest.stage", "deploy"))
+	assert.Equal(t, stageCtx, Context(t))
+	t.Run("subtest", func(t *testing.T) {
+		_, endCommand := StartSpan(t, "terraform apply")
+		endCommand(errors.New("apply failed"))
+	})
+	endStage(nil)
+
+	assert.False(t, trace.SpanFromContext(Context(t)).SpanContext().IsValid())
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	command, stage := spans[0], spans[1]
+
+	assert.Equal(t, "terraform apply", command.Name())
+	assert.Equal(t, codes.Error, command.Status().Code)
+	assert.Equal(t, "apply failed", command.Status().Description)
+	assert.Equal(t, stage.SpanContext().SpanID(), command.Parent().SpanID())
+
+	assert.Equal(t, "stage deploy", stage.Name())
+	assert.Equal(t, codes.Unset, stage.Status().Code)
+	assert.Contains(t, stage.Attributes(), attribute.String("terratest.stage", "deploy"))
+}